@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -20,6 +21,8 @@ import (
 	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/logger"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/signature"
+	httptransport "github.com/ijalalfrz/go-event-source/internal/pkg/transport/http"
 	"github.com/spf13/cobra"
 )
 
@@ -96,11 +99,16 @@ func startMainApp(ctx context.Context, cfg config.Config) {
 	lang.SetSupportedLanguages(cfg.Locales.SupportedLanguages)
 	lang.SetBasePath(cfg.Locales.BasePath)
 
-	endpts := makeEndpoints(cfg)
+	dbConn := db.InitDB(cfg)
+	idempotencyKeyRepository := repository.NewIdempotencyKeyRepository(dbConn)
+	endpts := makeEndpoints(ctx, cfg, dbConn, idempotencyKeyRepository)
 
 	router := router.MakeHTTPRouter(
 		endpts,
 		cfg,
+		idempotencyKeyRepository,
+		mustLoadSignatureKeyring(cfg),
+		mustLoadServiceTokens(cfg),
 	)
 
 	server := &http.Server{
@@ -131,35 +139,129 @@ func startMainApp(ctx context.Context, cfg config.Config) {
 	slog.Info("HTTP server gracefully stopped")
 }
 
-func makeEndpoints(cfg config.Config) endpoint.Endpoint {
-	dbConn := db.InitDB(cfg)
+func makeEndpoints(ctx context.Context, cfg config.Config, dbConn *sql.DB,
+	idempotencyKeyRepository *repository.IdempotencyKeyRepository,
+) endpoint.Endpoint {
+	txPolicy := mustTransactionPolicy(cfg)
 
 	// init all repo
-	accountRepository := repository.NewAccountRepository(dbConn)
-	eventRepository := repository.NewEventRepository(dbConn)
+	accountRepository := repository.NewAccountRepository(dbConn, txPolicy)
+	accountShardRepository := repository.NewAccountShardRepository(dbConn, txPolicy)
+	eventRepository := repository.NewEventRepository(dbConn, txPolicy)
+	ledgerRepository := repository.NewLedgerRepository(dbConn, txPolicy)
+	transferApprovalRepository := repository.NewTransferApprovalRepository(dbConn, txPolicy)
+
+	scheduledTransferRepository := repository.NewScheduledTransferRepository(dbConn)
+	standingOrderRepository := repository.NewStandingOrderRepository(dbConn, txPolicy)
+	transferLimitRepository := repository.NewTransferLimitRepository(dbConn)
+	interestRepository := repository.NewInterestRepository(dbConn, txPolicy)
+	reconciliationRepository := repository.NewReconciliationRepository(dbConn, txPolicy)
+	paymentBatchRepository := repository.NewPaymentBatchRepository(dbConn, txPolicy)
+	webhookRepository := repository.NewWebhookRepository(dbConn, txPolicy)
+	riskDecisionRepository := repository.NewRiskDecisionRepository(dbConn, txPolicy)
+	sanctionsRepository := repository.NewSanctionsRepository(dbConn, txPolicy)
+	monitoringAlertRepository := repository.NewMonitoringAlertRepository(dbConn, txPolicy)
+
+	feeSchedule := mustLoadFeeSchedule(cfg)
+	ledgerAccounts := newLedgerAccounts(cfg, feeSchedule)
+	riskEngine := mustLoadRiskEngine(ctx, cfg)
+	sanctionsScreener := mustLoadSanctionsScreener(ctx, cfg, sanctionsRepository)
+
+	ledgerSvc := service.NewLedgerService(ledgerRepository, accountRepository, eventRepository,
+		ledgerAccounts, cfg.EventVersion)
+	mustEnsureSystemAccounts(ctx, ledgerSvc)
+
+	transferLimitSvc := newTransferLimitService(transferLimitRepository, eventRepository, accountRepository, cfg)
+	transactionSvc := newTransactionService(accountRepository, accountShardRepository, eventRepository,
+		ledgerRepository, transferApprovalRepository, riskDecisionRepository, sanctionsRepository,
+		idempotencyKeyRepository, transferLimitSvc, feeSchedule, riskEngine, sanctionsScreener, cfg)
 
 	return endpoint.Endpoint{
-		Account:     makeAccountEndpoints(accountRepository, eventRepository, cfg),
-		Transaction: makeTransactionEndpoints(accountRepository, eventRepository, cfg),
+		Account: makeAccountEndpoints(accountRepository, accountShardRepository, eventRepository,
+			ledgerRepository, idempotencyKeyRepository, ledgerAccounts, cfg),
+		Transaction: endpoint.NewTransactionEndpoint(transactionSvc),
+		ScheduledTransfer: makeScheduledTransferEndpoints(scheduledTransferRepository,
+			accountRepository, eventRepository, transferApprovalRepository, transactionSvc, cfg),
+		StandingOrder: makeStandingOrderEndpoints(standingOrderRepository,
+			accountRepository, eventRepository, transferApprovalRepository, transactionSvc, cfg),
+		TransferLimit: endpoint.NewTransferLimitEndpoint(transferLimitSvc),
+		Interest: endpoint.NewInterestEndpoint(newInterestService(interestRepository,
+			accountRepository, eventRepository, ledgerRepository, ledgerAccounts, cfg)),
+		Statement: endpoint.NewStatementEndpoint(service.NewStatementService(eventRepository, accountRepository)),
+		Ledger:    endpoint.NewLedgerEndpoint(ledgerSvc),
+		Reconciliation: endpoint.NewReconciliationEndpoint(service.NewReconciliationService(reconciliationRepository,
+			eventRepository, cfg.Ledger.FundingAccountID, cfg.Reconciliation.DateTolerance)),
+		PaymentBatch: endpoint.NewPaymentBatchEndpoint(newPaymentBatchService(paymentBatchRepository,
+			accountRepository, eventRepository, transactionSvc, cfg)),
+		Webhook: endpoint.NewWebhookEndpoint(newWebhookService(webhookRepository, cfg)),
+		Monitoring: endpoint.NewMonitoringEndpoint(newMonitoringService(monitoringAlertRepository,
+			eventRepository, cfg)),
 	}
 }
 
+// mustLoadSignatureKeyring loads the keys of the API clients, the signature verification is disabled when no key is
+// configured.
+func mustLoadSignatureKeyring(cfg config.Config) httptransport.SignatureVerifier {
+	if len(cfg.Signature.Keys) == 0 {
+		return nil
+	}
+
+	keyring, err := signature.NewKeyring(cfg.Signature.Keys)
+	if err != nil {
+		panic(fmt.Errorf("failed to load signature keys: %w", err))
+	}
+
+	return keyring
+}
+
+// mustLoadServiceTokens loads the bearer tokens of the API clients, the authentication is disabled when no token is
+// configured.
+func mustLoadServiceTokens(cfg config.Config) httptransport.Authenticator {
+	if len(cfg.ServiceTokens) == 0 {
+		return nil
+	}
+
+	serviceTokens, err := httptransport.ParseServiceTokens(cfg.ServiceTokens)
+	if err != nil {
+		panic(fmt.Errorf("failed to load service tokens: %w", err))
+	}
+
+	return serviceTokens
+}
+
 func makeAccountEndpoints(accountRepository *repository.AccountRepository,
-	eventRepository *repository.EventRepository, cfg config.Config,
+	accountShardRepository *repository.AccountShardRepository, eventRepository *repository.EventRepository,
+	ledgerRepository *repository.LedgerRepository,
+	idempotencyKeyRepository *repository.IdempotencyKeyRepository, ledgerAccounts service.LedgerAccounts,
+	cfg config.Config,
 ) endpoint.Account {
-	accountSvc := service.NewAccountService(accountRepository, eventRepository,
-		cfg.RequestTimeThreshold, cfg.EventVersion)
+	accountSvc := service.NewAccountService(accountRepository, accountShardRepository, eventRepository,
+		ledgerRepository, idempotencyKeyRepository, ledgerAccounts, cfg.RequestTimeThreshold, cfg.EventVersion)
 
 	return endpoint.NewAccountEndpoint(accountSvc)
 }
 
-func makeTransactionEndpoints(accountRepository *repository.AccountRepository,
-	eventRepository *repository.EventRepository, cfg config.Config,
-) endpoint.Transaction {
-	transactionSvc := service.NewTransactionService(accountRepository, eventRepository,
-		cfg.RequestTimeThreshold, cfg.EventVersion)
+func makeScheduledTransferEndpoints(scheduledTransferRepository *repository.ScheduledTransferRepository,
+	accountRepository *repository.AccountRepository, eventRepository *repository.EventRepository,
+	transferApprovalRepository *repository.TransferApprovalRepository, transactionSvc service.Transferer,
+	cfg config.Config,
+) endpoint.ScheduledTransfer {
+	scheduledTransferSvc := service.NewScheduledTransferService(scheduledTransferRepository,
+		accountRepository, transactionSvc, transferApprovalRepository, eventRepository, cfg.RequestTimeThreshold,
+		cfg.Scheduler.ClaimTimeout, cfg.Scheduler.BatchSize)
+
+	return endpoint.NewScheduledTransferEndpoint(scheduledTransferSvc)
+}
+
+func makeStandingOrderEndpoints(standingOrderRepository *repository.StandingOrderRepository,
+	accountRepository *repository.AccountRepository, eventRepository *repository.EventRepository,
+	transferApprovalRepository *repository.TransferApprovalRepository, transactionSvc service.Transferer,
+	cfg config.Config,
+) endpoint.StandingOrder {
+	standingOrderSvc := newStandingOrderService(standingOrderRepository, accountRepository,
+		eventRepository, transferApprovalRepository, transactionSvc, cfg)
 
-	return endpoint.NewTransactionEndpoint(transactionSvc)
+	return endpoint.NewStandingOrderEndpoint(standingOrderSvc)
 }
 
 func startPprof(ctx context.Context, cfg config.Config) {
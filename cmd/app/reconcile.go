@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/config"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/repository"
+	"github.com/ijalalfrz/go-event-source/internal/app/service"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/bankstatement"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileCmd = &cobra.Command{
+		Use:   "reconcile",
+		Short: "Reconcile the ledger against bank statements",
+	}
+	reconcileImportCmd = &cobra.Command{
+		Use:   "import FILE",
+		Short: "Match a CSV, MT940 or CAMT.053 bank statement against the ledger and store the result",
+		Args:  cobra.ExactArgs(1),
+		// a failed import is logged, the usage is not printed
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			slog.Debug("command line flags", slog.String("config_path", cfgFilePath))
+			cfg := config.MustInitConfig(cfgFilePath)
+
+			logger.InitStructuredLogger(cfg.LogLevel)
+
+			return runReconcileImport(cmd.Context(), cfg, args[0], statementFormat)
+		},
+	}
+	statementFormat string
+)
+
+func init() { //nolint:gochecknoinits
+	reconcileImportCmd.Flags().StringVarP(&statementFormat, "format", "f", "",
+		"csv, mt940 or camt053, detected from the file when omitted")
+	reconcileCmd.AddCommand(reconcileImportCmd)
+}
+
+// runReconcileImport imports a bank statement file and prints the reconciliation as JSON.
+func runReconcileImport(ctx context.Context, cfg config.Config, path, format string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read bank statement: %w", err)
+	}
+
+	req := dto.ImportReconciliationRequest{
+		FileName: filepath.Base(path),
+		Format:   bankstatement.DetectFormat(path, content),
+		Content:  content,
+	}
+
+	if format != "" {
+		req.Format, err = bankstatement.ParseFormat(format)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	dbConn := db.InitDB(cfg)
+	defer dbConn.Close()
+
+	txPolicy := mustTransactionPolicy(cfg)
+
+	reconciliationSvc := service.NewReconciliationService(repository.NewReconciliationRepository(dbConn, txPolicy),
+		repository.NewEventRepository(dbConn, txPolicy), cfg.Ledger.FundingAccountID, cfg.Reconciliation.DateTolerance)
+
+	reconciliation, err := reconciliationSvc.ImportReconciliation(ctx, req)
+	if err != nil {
+		return fmt.Errorf("import bank statement: %w", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(reconciliation) //nolint:wrapcheck
+}
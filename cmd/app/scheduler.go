@@ -0,0 +1,478 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/config"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/app/repository"
+	"github.com/ijalalfrz/go-event-source/internal/app/service"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/fee"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/logger"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/monitoring"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/risk"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/sanctions"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/webhook"
+	"github.com/spf13/cobra"
+)
+
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Run due transfers, standing orders, batches, interest, webhooks and monitoring, expire transfers and keys",
+	Run: func(_ *cobra.Command, _ []string) {
+		slog.Debug("command line flags", slog.String("config_path", cfgFilePath))
+		cfg := config.MustInitConfig(cfgFilePath)
+
+		logger.InitStructuredLogger(cfg.LogLevel)
+
+		runScheduler(cfg)
+	},
+}
+
+func runScheduler(cfg config.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var waitGroup sync.WaitGroup
+
+	slog.InfoContext(ctx, "starting scheduler...", slog.String("log_level", string(cfg.LogLevel)))
+
+	dbConn := db.InitDB(cfg)
+	txPolicy := mustTransactionPolicy(cfg)
+
+	accountRepository := repository.NewAccountRepository(dbConn, txPolicy)
+	accountShardRepository := repository.NewAccountShardRepository(dbConn, txPolicy)
+	eventRepository := repository.NewEventRepository(dbConn, txPolicy)
+	ledgerRepository := repository.NewLedgerRepository(dbConn, txPolicy)
+	transferApprovalRepository := repository.NewTransferApprovalRepository(dbConn, txPolicy)
+	idempotencyKeyRepository := repository.NewIdempotencyKeyRepository(dbConn)
+	scheduledTransferRepository := repository.NewScheduledTransferRepository(dbConn)
+	standingOrderRepository := repository.NewStandingOrderRepository(dbConn, txPolicy)
+	transferLimitRepository := repository.NewTransferLimitRepository(dbConn)
+	interestRepository := repository.NewInterestRepository(dbConn, txPolicy)
+	paymentBatchRepository := repository.NewPaymentBatchRepository(dbConn, txPolicy)
+	webhookRepository := repository.NewWebhookRepository(dbConn, txPolicy)
+	riskDecisionRepository := repository.NewRiskDecisionRepository(dbConn, txPolicy)
+	sanctionsRepository := repository.NewSanctionsRepository(dbConn, txPolicy)
+	monitoringAlertRepository := repository.NewMonitoringAlertRepository(dbConn, txPolicy)
+
+	feeSchedule := mustLoadFeeSchedule(cfg)
+	ledgerAccounts := newLedgerAccounts(cfg, feeSchedule)
+	riskEngine := mustLoadRiskEngine(ctx, cfg)
+	sanctionsScreener := mustLoadSanctionsScreener(ctx, cfg, sanctionsRepository)
+
+	mustEnsureSystemAccounts(ctx, service.NewLedgerService(ledgerRepository, accountRepository, eventRepository,
+		ledgerAccounts, cfg.EventVersion))
+
+	transferLimitSvc := newTransferLimitService(transferLimitRepository, eventRepository, accountRepository, cfg)
+	transactionSvc := newTransactionService(accountRepository, accountShardRepository, eventRepository,
+		ledgerRepository, transferApprovalRepository, riskDecisionRepository, sanctionsRepository,
+		idempotencyKeyRepository, transferLimitSvc, feeSchedule, riskEngine, sanctionsScreener, cfg)
+	scheduledTransferSvc := service.NewScheduledTransferService(scheduledTransferRepository,
+		accountRepository, transactionSvc, transferApprovalRepository, eventRepository, cfg.RequestTimeThreshold,
+		cfg.Scheduler.ClaimTimeout, cfg.Scheduler.BatchSize)
+	standingOrderSvc := newStandingOrderService(standingOrderRepository, accountRepository,
+		eventRepository, transferApprovalRepository, transactionSvc, cfg)
+	interestSvc := newInterestService(interestRepository, accountRepository, eventRepository, ledgerRepository,
+		ledgerAccounts, cfg)
+	paymentBatchSvc := newPaymentBatchService(paymentBatchRepository, accountRepository, eventRepository,
+		transactionSvc, cfg)
+	idempotencyKeySvc := service.NewIdempotencyKeyService(idempotencyKeyRepository, cfg.IdempotencyKey.Retention,
+		cfg.Scheduler.BatchSize)
+	webhookSvc := newWebhookService(webhookRepository, cfg)
+	monitoringSvc := newMonitoringService(monitoringAlertRepository, eventRepository, cfg)
+
+	waitGroup.Add(8)
+
+	go func() {
+		defer waitGroup.Done()
+		runWorker(ctx, "scheduled_transfer", cfg.Scheduler.Interval, func(ctx context.Context) error {
+			executed, err := scheduledTransferSvc.ExecuteDue(ctx)
+			if executed > 0 {
+				slog.InfoContext(ctx, "scheduled transfers executed", slog.Int("count", executed))
+			}
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	go func() {
+		defer waitGroup.Done()
+		runWorker(ctx, "standing_order", cfg.Scheduler.Interval, func(ctx context.Context) error {
+			processed, err := standingOrderSvc.ExecuteDue(ctx)
+			if processed > 0 {
+				slog.InfoContext(ctx, "standing orders processed", slog.Int("count", processed))
+			}
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	go func() {
+		defer waitGroup.Done()
+		runWorker(ctx, "payment_batch", cfg.Scheduler.Interval, func(ctx context.Context) error {
+			processed, err := paymentBatchSvc.ExecuteDue(ctx)
+			if processed > 0 {
+				slog.InfoContext(ctx, "payment instructions processed", slog.Int("count", processed))
+			}
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	go func() {
+		defer waitGroup.Done()
+		runWorker(ctx, "webhook", cfg.Webhook.Interval, func(ctx context.Context) error {
+			dispatched, err := webhookSvc.Dispatch(ctx)
+			if dispatched > 0 {
+				slog.InfoContext(ctx, "webhook deliveries created", slog.Int("count", dispatched))
+			}
+
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			delivered, err := webhookSvc.DeliverDue(ctx)
+			if delivered > 0 {
+				slog.InfoContext(ctx, "webhook deliveries attempted", slog.Int("count", delivered))
+			}
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	go func() {
+		defer waitGroup.Done()
+		runWorker(ctx, "transfer_approval", cfg.Scheduler.Interval, func(ctx context.Context) error {
+			expired, err := transactionSvc.ExpireDue(ctx)
+			if expired > 0 {
+				slog.InfoContext(ctx, "pending transfers expired", slog.Int("count", expired))
+			}
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	go func() {
+		defer waitGroup.Done()
+		runWorker(ctx, "idempotency_key", cfg.Scheduler.Interval, func(ctx context.Context) error {
+			expired, err := idempotencyKeySvc.ExpireDue(ctx)
+			if expired > 0 {
+				slog.InfoContext(ctx, "idempotency keys expired", slog.Int("count", expired))
+			}
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	go func() {
+		defer waitGroup.Done()
+		runWorker(ctx, "interest", cfg.Interest.Interval, func(ctx context.Context) error {
+			accrued, err := interestSvc.AccrueDue(ctx)
+			if accrued > 0 {
+				slog.InfoContext(ctx, "interest accrued", slog.Int("count", accrued))
+			}
+
+			if err != nil {
+				return err //nolint:wrapcheck
+			}
+
+			// post after accruing, so the last date of the previous month is included
+			posted, err := interestSvc.PostDue(ctx)
+			if posted > 0 {
+				slog.InfoContext(ctx, "interest posted", slog.Int("count", posted))
+			}
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	go func() {
+		defer waitGroup.Done()
+		runWorker(ctx, "monitoring", cfg.Monitoring.Interval, func(ctx context.Context) error {
+			raised, err := monitoringSvc.Scan(ctx)
+			if raised > 0 {
+				slog.InfoContext(ctx, "monitoring alerts raised", slog.Int("count", raised))
+			}
+
+			return err //nolint:wrapcheck
+		})
+	}()
+
+	sigChannel := make(chan os.Signal, 1)
+	signal.Notify(sigChannel, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+
+	sig := <-sigChannel
+	cancel()
+	slog.InfoContext(ctx, "received OS signal. Exiting...", slog.String("signal", sig.String()))
+
+	waitGroup.Wait()
+	slog.Info("scheduler stopped")
+}
+
+func newStandingOrderService(standingOrderRepository *repository.StandingOrderRepository,
+	accountRepository *repository.AccountRepository, eventRepository *repository.EventRepository,
+	transferApprovalRepository *repository.TransferApprovalRepository, transferer service.Transferer,
+	cfg config.Config,
+) *service.StandingOrderService {
+	retryPolicy := service.RetryPolicy{
+		MaxRetries: cfg.StandingOrder.MaxRetries,
+		Interval:   cfg.StandingOrder.RetryInterval,
+	}
+
+	return service.NewStandingOrderService(standingOrderRepository, accountRepository, eventRepository,
+		transferer, transferApprovalRepository, retryPolicy, cfg.RequestTimeThreshold, cfg.EventVersion,
+		cfg.Scheduler.BatchSize)
+}
+
+func newPaymentBatchService(paymentBatchRepository *repository.PaymentBatchRepository,
+	accountRepository *repository.AccountRepository, eventRepository *repository.EventRepository,
+	transferer service.Transferer, cfg config.Config,
+) *service.PaymentBatchService {
+	return service.NewPaymentBatchService(paymentBatchRepository, accountRepository, eventRepository, transferer,
+		cfg.RequestTimeThreshold, cfg.EventVersion, cfg.PaymentBatch.MaxTransactions, cfg.Scheduler.BatchSize)
+}
+
+func newWebhookService(webhookRepository *repository.WebhookRepository, cfg config.Config) *service.WebhookService {
+	retryPolicy := service.WebhookRetryPolicy{
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+		BackoffBase: cfg.Webhook.BackoffBase,
+		BackoffMax:  cfg.Webhook.BackoffMax,
+	}
+
+	return service.NewWebhookService(webhookRepository, webhook.NewClient(cfg.Webhook.Timeout), retryPolicy,
+		cfg.Scheduler.BatchSize)
+}
+
+// newMonitoringService builds the detectors enabled in the configuration, they all look at the same window.
+func newMonitoringService(monitoringAlertRepository *repository.MonitoringAlertRepository,
+	eventRepository *repository.EventRepository, cfg config.Config,
+) *service.MonitoringService {
+	monitoringCfg := cfg.Monitoring
+
+	var detectors []monitoring.Detector
+
+	if monitoringCfg.StructuringThreshold.IsPositive() && monitoringCfg.StructuringMinCount > 0 {
+		detectors = append(detectors, monitoring.Structuring{
+			Threshold: monitoringCfg.StructuringThreshold,
+			Margin:    monitoringCfg.StructuringMargin,
+			MinCount:  monitoringCfg.StructuringMinCount,
+			Period:    monitoringCfg.Window,
+		})
+	}
+
+	if monitoringCfg.RapidMovementMinAmount.IsPositive() && monitoringCfg.RapidMovementRatio.IsPositive() {
+		detectors = append(detectors, monitoring.RapidMovement{
+			MinAmount: monitoringCfg.RapidMovementMinAmount,
+			Ratio:     monitoringCfg.RapidMovementRatio,
+			Period:    monitoringCfg.Window,
+		})
+	}
+
+	if monitoringCfg.FanInMinCounterparties > 0 {
+		detectors = append(detectors, monitoring.Fan{
+			Direction:         monitoring.DirectionIn,
+			MinCounterparties: monitoringCfg.FanInMinCounterparties,
+			Period:            monitoringCfg.Window,
+		})
+	}
+
+	if monitoringCfg.FanOutMinCounterparties > 0 {
+		detectors = append(detectors, monitoring.Fan{
+			Direction:         monitoring.DirectionOut,
+			MinCounterparties: monitoringCfg.FanOutMinCounterparties,
+			Period:            monitoringCfg.Window,
+		})
+	}
+
+	return service.NewMonitoringService(monitoringAlertRepository, eventRepository, detectors)
+}
+
+func newTransferLimitService(transferLimitRepository *repository.TransferLimitRepository,
+	eventRepository *repository.EventRepository, accountRepository *repository.AccountRepository,
+	cfg config.Config,
+) *service.TransferLimitService {
+	limits := model.TransferLimits{
+		PerTransaction: cfg.TransferLimit.PerTransaction,
+		Daily:          cfg.TransferLimit.Daily,
+		Monthly:        cfg.TransferLimit.Monthly,
+		HourlyCount:    cfg.TransferLimit.HourlyCount,
+	}
+
+	return service.NewTransferLimitService(transferLimitRepository, eventRepository, accountRepository, limits)
+}
+
+func newInterestService(interestRepository *repository.InterestRepository,
+	accountRepository *repository.AccountRepository, eventRepository *repository.EventRepository,
+	ledgerRepository *repository.LedgerRepository, ledgerAccounts service.LedgerAccounts, cfg config.Config,
+) *service.InterestService {
+	policy := service.InterestPolicy{
+		Rates: model.InterestRates{
+			model.AccountTypePersonal: cfg.Interest.PersonalRate,
+			model.AccountTypeBusiness: cfg.Interest.BusinessRate,
+			model.AccountTypeSavings:  cfg.Interest.SavingsRate,
+		},
+		DayCount:    cfg.Interest.DayCount,
+		CatchUpDays: cfg.Interest.CatchUpDays,
+	}
+
+	return service.NewInterestService(interestRepository, eventRepository, accountRepository, eventRepository,
+		ledgerRepository, ledgerAccounts, policy, cfg.EventVersion, cfg.Scheduler.BatchSize)
+}
+
+func newTransactionService(accountRepository *repository.AccountRepository,
+	accountShardRepository *repository.AccountShardRepository,
+	eventRepository *repository.EventRepository, ledgerRepository *repository.LedgerRepository,
+	transferApprovalRepository *repository.TransferApprovalRepository,
+	riskDecisionRepository *repository.RiskDecisionRepository, sanctionsRepository *repository.SanctionsRepository,
+	idempotencyKeyRepository *repository.IdempotencyKeyRepository, transferLimiter service.TransferLimiter,
+	feeSchedule *fee.Schedule, riskEngine *risk.Engine, sanctionsScreener *sanctions.Screener, cfg config.Config,
+) *service.TransactionService {
+	approvalPolicy := service.TransferApprovalPolicy{
+		Threshold: cfg.TransferApproval.Threshold,
+		TTL:       cfg.TransferApproval.TTL,
+	}
+
+	hotAccountPolicy := service.HotAccountPolicy{
+		AccountIDs: cfg.HotAccount.AccountIDs,
+		Shards:     cfg.HotAccount.Shards,
+	}
+
+	// a nil engine must not become a non-nil evaluator
+	var riskEvaluator service.RiskEvaluator
+	if riskEngine != nil {
+		riskEvaluator = riskEngine
+	}
+
+	// a nil screener must not become a non-nil screener
+	var screener service.SanctionsScreener
+	if sanctionsScreener != nil {
+		screener = sanctionsScreener
+	}
+
+	sanctionsPolicy := service.SanctionsPolicy{
+		HoldScore:        cfg.Sanctions.HoldScore,
+		BlockScore:       cfg.Sanctions.BlockScore,
+		ExemptAccountIDs: newLedgerAccounts(cfg, feeSchedule).IDs(),
+	}
+
+	return service.NewTransactionService(accountRepository, accountShardRepository, eventRepository,
+		ledgerRepository, transferApprovalRepository, riskDecisionRepository, sanctionsRepository,
+		idempotencyKeyRepository, transferLimiter, feeSchedule, approvalPolicy, hotAccountPolicy, riskEvaluator,
+		screener, sanctionsPolicy, cfg.RequestTimeThreshold, cfg.EventVersion, cfg.Scheduler.BatchSize)
+}
+
+// newLedgerAccounts returns the system accounts, the fee revenue account is the one of the fee schedule.
+func newLedgerAccounts(cfg config.Config, feeSchedule *fee.Schedule) service.LedgerAccounts {
+	accounts := service.LedgerAccounts{
+		FundingAccountID:  cfg.Ledger.FundingAccountID,
+		InterestAccountID: cfg.Ledger.InterestAccountID,
+	}
+
+	if feeSchedule != nil {
+		accounts.FeeAccountID = feeSchedule.RevenueAccountID
+	}
+
+	return accounts
+}
+
+// mustEnsureSystemAccounts creates the missing system accounts, the ledger cannot post without them.
+func mustEnsureSystemAccounts(ctx context.Context, ledgerSvc *service.LedgerService) {
+	if err := ledgerSvc.EnsureSystemAccounts(ctx); err != nil {
+		panic(fmt.Errorf("failed to ensure system accounts: %w", err))
+	}
+}
+
+// mustTransactionPolicy returns the isolation level and the retries of the transactions.
+func mustTransactionPolicy(cfg config.Config) db.TransactionPolicy {
+	policy, err := db.NewTransactionPolicy(cfg)
+	if err != nil {
+		panic(fmt.Errorf("failed to init transaction policy: %w", err))
+	}
+
+	return policy
+}
+
+// mustLoadFeeSchedule loads the fee schedule, fees are disabled when no schedule is configured.
+func mustLoadFeeSchedule(cfg config.Config) *fee.Schedule {
+	if cfg.Fee.SchedulePath == "" {
+		return nil
+	}
+
+	schedule, err := fee.Load(cfg.Fee.SchedulePath)
+	if err != nil {
+		panic(fmt.Errorf("failed to load fee schedule: %w", err))
+	}
+
+	return schedule
+}
+
+// mustLoadRiskEngine loads the risk rules and reloads them when their file changes until the context is cancelled,
+// the risk rules are disabled when no file is configured.
+func mustLoadRiskEngine(ctx context.Context, cfg config.Config) *risk.Engine {
+	if cfg.Risk.RulesPath == "" {
+		return nil
+	}
+
+	engine, err := risk.NewEngine(cfg.Risk.RulesPath)
+	if err != nil {
+		panic(fmt.Errorf("failed to load risk rules: %w", err))
+	}
+
+	go engine.Watch(ctx, cfg.Risk.ReloadInterval)
+
+	return engine
+}
+
+// mustLoadSanctionsScreener loads the sanctions list and reloads it when its file changes until the context is
+// cancelled, every version of the list is recorded. The screening is disabled when no file is configured.
+func mustLoadSanctionsScreener(ctx context.Context, cfg config.Config,
+	sanctionsRepository *repository.SanctionsRepository,
+) *sanctions.Screener {
+	if cfg.Sanctions.ListPath == "" {
+		return nil
+	}
+
+	screener, err := sanctions.NewScreener(cfg.Sanctions.ListPath)
+	if err != nil {
+		panic(fmt.Errorf("failed to load sanctions list: %w", err))
+	}
+
+	if err := recordSanctionsList(ctx, sanctionsRepository, cfg.Sanctions.ListPath, screener.List()); err != nil {
+		panic(err)
+	}
+
+	go screener.Watch(ctx, cfg.Sanctions.ReloadInterval, func(ctx context.Context, list *sanctions.List) {
+		if err := recordSanctionsList(ctx, sanctionsRepository, cfg.Sanctions.ListPath, list); err != nil {
+			slog.ErrorContext(ctx, "failed to record sanctions list", slog.String("error", err.Error()))
+		}
+	})
+
+	return screener
+}
+
+func recordSanctionsList(ctx context.Context, sanctionsRepository *repository.SanctionsRepository, path string,
+	list *sanctions.List,
+) error {
+	err := sanctionsRepository.CreateList(ctx, &model.SanctionsList{
+		Version:  list.Version,
+		Checksum: list.Checksum,
+		Format:   string(list.Format),
+		Source:   path,
+		Entries:  len(list.Entries),
+		LoadedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record sanctions list: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// workerJob is a unit of background work executed on every tick of a worker.
+type workerJob func(ctx context.Context) error
+
+// runWorker runs job immediately and then on every interval until ctx is cancelled.
+func runWorker(ctx context.Context, name string, interval time.Duration, job workerJob) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	slog.InfoContext(ctx, "running worker...", slog.String("worker", name), slog.Duration("interval", interval))
+
+	for {
+		if err := job(ctx); err != nil {
+			slog.ErrorContext(ctx, "worker job failed", slog.String("worker", name), slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "worker stopped", slog.String("worker", name))
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
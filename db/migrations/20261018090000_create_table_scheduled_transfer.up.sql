@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transaction_id varchar(100) NOT NULL,
+    source_account_id bigint NOT NULL,
+    destination_account_id bigint NOT NULL,
+    amount decimal(10, 5) NOT NULL,
+    execute_at timestamp NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    failure_reason text NOT NULL DEFAULT '',
+    executed_at timestamp NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE scheduled_transfers ADD CONSTRAINT scheduled_transfers_transaction_id_unique UNIQUE (transaction_id);
+CREATE INDEX scheduled_transfers_status_execute_at_idx ON scheduled_transfers (status, execute_at);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS standing_orders (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transaction_id varchar(100) NOT NULL,
+    source_account_id bigint NOT NULL,
+    destination_account_id bigint NOT NULL,
+    amount decimal(10, 5) NOT NULL,
+    recurrence varchar(255) NOT NULL,
+    start_at timestamp NOT NULL,
+    end_at timestamp NULL,
+    max_occurrences int NOT NULL DEFAULT 0,
+    occurrence int NOT NULL DEFAULT 1,
+    executed_count int NOT NULL DEFAULT 0,
+    attempt int NOT NULL DEFAULT 0,
+    occurrence_at timestamp NOT NULL,
+    next_run_at timestamp NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'active',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE standing_orders ADD CONSTRAINT standing_orders_transaction_id_unique UNIQUE (transaction_id);
+CREATE INDEX standing_orders_status_next_run_at_idx ON standing_orders (status, next_run_at);
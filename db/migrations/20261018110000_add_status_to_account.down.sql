@@ -0,0 +1,2 @@
+ALTER TABLE accounts DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE accounts DROP COLUMN IF EXISTS status;
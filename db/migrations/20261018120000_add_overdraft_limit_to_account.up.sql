@@ -0,0 +1 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_limit decimal(10, 5) NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS events_aggregate_event_type_created_at_idx;
+DROP TABLE IF EXISTS account_transfer_limits;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS account_transfer_limits (
+    account_id bigint PRIMARY KEY,
+    per_transaction decimal(10, 5) NULL,
+    daily decimal(10, 5) NULL,
+    monthly decimal(10, 5) NULL,
+    hourly_count int NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- outgoing usage is summed from balance_debited events within the limit windows
+CREATE INDEX IF NOT EXISTS events_aggregate_event_type_created_at_idx
+    ON events (aggregate_id, aggregate_type, event_type, created_at);
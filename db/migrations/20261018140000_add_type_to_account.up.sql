@@ -0,0 +1 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS type varchar(20) NOT NULL DEFAULT 'personal';
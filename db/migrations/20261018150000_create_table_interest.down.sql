@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS interest_accruals_unposted_idx;
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS account_interest_rates;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS account_interest_rates (
+    account_id bigint PRIMARY KEY,
+    rate decimal(7, 4) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- one accrual per account and date keeps the accrual job idempotent
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    account_id bigint NOT NULL,
+    accrual_date date NOT NULL,
+    balance decimal(10, 5) NOT NULL,
+    rate decimal(7, 4) NOT NULL,
+    amount decimal(10, 5) NOT NULL,
+    transaction_id varchar(100) NOT NULL,
+    posting_transaction_id varchar(100) NULL,
+    posted_at timestamp NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id, accrual_date)
+);
+
+CREATE INDEX IF NOT EXISTS interest_accruals_unposted_idx
+    ON interest_accruals (accrual_date, account_id) WHERE posted_at IS NULL;
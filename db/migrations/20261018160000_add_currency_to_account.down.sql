@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS accounts_status_currency_idx;
+DROP INDEX IF EXISTS accounts_created_at_id_idx;
+DROP INDEX IF EXISTS accounts_balance_id_idx;
+ALTER TABLE accounts DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,6 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'USD';
+
+-- keyset pagination of the account listing, every sort key ends with the id to make it unique
+CREATE INDEX IF NOT EXISTS accounts_balance_id_idx ON accounts (balance, id);
+CREATE INDEX IF NOT EXISTS accounts_created_at_id_idx ON accounts (created_at, id);
+CREATE INDEX IF NOT EXISTS accounts_status_currency_idx ON accounts (status, currency);
@@ -0,0 +1,3 @@
+ALTER TABLE accounts DROP COLUMN IF EXISTS labels;
+ALTER TABLE accounts DROP COLUMN IF EXISTS owner_reference;
+ALTER TABLE accounts DROP COLUMN IF EXISTS display_name;
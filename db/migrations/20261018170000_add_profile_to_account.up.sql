@@ -0,0 +1,3 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS display_name varchar(100) NULL;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS owner_reference varchar(100) NULL;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS labels jsonb NOT NULL DEFAULT '{}';
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS postings_balanced_trigger ON postings;
+DROP FUNCTION IF EXISTS check_journal_entry_balanced();
+DROP INDEX IF EXISTS postings_account_id_idx;
+DROP INDEX IF EXISTS postings_journal_entry_id_idx;
+DROP TABLE IF EXISTS postings;
+DROP INDEX IF EXISTS journal_entries_transaction_id_idx;
+DROP TABLE IF EXISTS journal_entries;
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transaction_id varchar(100) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS journal_entries_transaction_id_idx ON journal_entries (transaction_id);
+
+CREATE TABLE IF NOT EXISTS postings (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    journal_entry_id bigint NOT NULL REFERENCES journal_entries (id),
+    account_id bigint NOT NULL,
+    direction varchar(6) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount decimal(10, 5) NOT NULL CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS postings_journal_entry_id_idx ON postings (journal_entry_id);
+CREATE INDEX IF NOT EXISTS postings_account_id_idx ON postings (account_id);
+
+-- the postings of an entry are inserted one by one, the balance is checked when the transaction commits
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+DECLARE
+    difference decimal;
+BEGIN
+    SELECT COALESCE(SUM(CASE direction WHEN 'debit' THEN amount ELSE -amount END), 0)
+    INTO difference
+    FROM postings
+    WHERE journal_entry_id = NEW.journal_entry_id;
+
+    IF difference <> 0 THEN
+        RAISE EXCEPTION 'journal entry % is not balanced, debits minus credits is %', NEW.journal_entry_id, difference
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER postings_balanced_trigger
+    AFTER INSERT OR UPDATE ON postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS transfer_approvals (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transaction_id varchar(100) NOT NULL,
+    source_account_id bigint NOT NULL,
+    destination_account_id bigint NOT NULL,
+    amount decimal(10, 5) NOT NULL,
+    initiated_by varchar(100) NOT NULL DEFAULT '',
+    reviewed_by varchar(100) NULL,
+    rejection_reason varchar(255) NOT NULL DEFAULT '',
+    status varchar(20) NOT NULL DEFAULT 'pending_approval',
+    expires_at timestamp NOT NULL,
+    reviewed_at timestamp NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE transfer_approvals ADD CONSTRAINT transfer_approvals_transaction_id_unique UNIQUE (transaction_id);
+CREATE INDEX transfer_approvals_status_expires_at_idx ON transfer_approvals (status, expires_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    transaction_id varchar(100) PRIMARY KEY,
+    fingerprint char(64) NOT NULL,
+    status_code int NOT NULL,
+    content_type varchar(100) NOT NULL DEFAULT '',
+    response_body bytea NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idempotency_keys_created_at_idx;
+DELETE FROM idempotency_keys WHERE status_code IS NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN response_body SET NOT NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN status_code SET NOT NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN fingerprint SET NOT NULL;
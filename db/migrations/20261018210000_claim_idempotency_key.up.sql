@@ -0,0 +1,4 @@
+ALTER TABLE idempotency_keys ALTER COLUMN fingerprint DROP NOT NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN status_code DROP NOT NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN response_body DROP NOT NULL;
+CREATE INDEX idempotency_keys_created_at_idx ON idempotency_keys (created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS account_shards (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    account_id bigint NOT NULL REFERENCES accounts (id),
+    shard_no int NOT NULL CHECK (shard_no >= 0),
+    balance decimal(10, 5) NOT NULL DEFAULT 0,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE account_shards ADD CONSTRAINT account_shards_account_id_shard_no_unique UNIQUE (account_id, shard_no);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    file_name varchar(255) NOT NULL DEFAULT '',
+    format varchar(20) NOT NULL,
+    statement_from date NOT NULL,
+    statement_to date NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- an item is a matched pair, or a bank line or a ledger movement left unmatched
+CREATE TABLE IF NOT EXISTS reconciliation_items (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    reconciliation_id bigint NOT NULL REFERENCES reconciliations (id),
+    status varchar(20) NOT NULL CHECK (status IN ('matched', 'unmatched_in_bank', 'unmatched_in_ledger')),
+    match_rule varchar(20) NOT NULL DEFAULT '',
+    bank_booking_date date NULL,
+    bank_amount decimal(10, 5) NULL,
+    bank_reference varchar(255) NULL,
+    bank_description varchar(500) NULL,
+    event_id bigint NULL,
+    transaction_id varchar(100) NULL,
+    account_id bigint NULL,
+    ledger_amount decimal(10, 5) NULL,
+    ledger_created_at timestamp NULL
+);
+
+CREATE INDEX IF NOT EXISTS reconciliation_items_reconciliation_id_idx ON reconciliation_items (reconciliation_id);
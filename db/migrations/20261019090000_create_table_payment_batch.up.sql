@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS payment_batches (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transaction_id varchar(100) NOT NULL,
+    message_id varchar(35) NOT NULL,
+    initiating_party varchar(140) NOT NULL DEFAULT '',
+    number_of_transactions int NOT NULL,
+    control_sum decimal(18, 5) NULL,
+    status varchar(20) NOT NULL CHECK (status IN ('processing', 'completed', 'rejected')),
+    reason_code varchar(4) NOT NULL DEFAULT '',
+    reason varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE payment_batches ADD CONSTRAINT payment_batches_transaction_id_unique UNIQUE (transaction_id);
+ALTER TABLE payment_batches ADD CONSTRAINT payment_batches_message_id_unique UNIQUE (message_id);
+
+-- an instruction is a credit transfer transaction of a batch, the account ids are 0 when not in the ledger
+CREATE TABLE IF NOT EXISTS payment_instructions (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    batch_id bigint NOT NULL REFERENCES payment_batches (id),
+    sequence int NOT NULL,
+    payment_information_id varchar(35) NOT NULL,
+    instruction_id varchar(35) NOT NULL DEFAULT '',
+    end_to_end_id varchar(35) NOT NULL,
+    source_account_id bigint NOT NULL,
+    destination_account_id bigint NOT NULL,
+    amount decimal(10, 5) NOT NULL,
+    currency varchar(3) NOT NULL,
+    execute_at timestamp NOT NULL,
+    status varchar(20) NOT NULL CHECK (status IN ('pending', 'accepted', 'pending_approval', 'rejected')),
+    reason_code varchar(4) NOT NULL DEFAULT '',
+    reason varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE payment_instructions ADD CONSTRAINT payment_instructions_batch_id_sequence_unique UNIQUE (batch_id, sequence);
+CREATE INDEX payment_instructions_status_execute_at_idx ON payment_instructions (status, execute_at);
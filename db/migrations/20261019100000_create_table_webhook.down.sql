@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TRIGGER IF EXISTS events_webhook_outbox ON events;
+DROP FUNCTION IF EXISTS enqueue_webhook_event;
+DROP TABLE IF EXISTS webhook_outbox;
+DROP TABLE IF EXISTS webhooks;
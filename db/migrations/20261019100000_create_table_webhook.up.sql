@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    url varchar(2048) NOT NULL,
+    secret varchar(255) NOT NULL,
+    event_types varchar(100)[] NOT NULL DEFAULT '{}',
+    account_ids bigint[] NOT NULL DEFAULT '{}',
+    status varchar(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'disabled')),
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- the account events waiting to be fanned out to the subscriptions, enqueued in the transaction of the event so that
+-- an event is only sent once committed and no event is missed
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    event_id bigint PRIMARY KEY
+);
+
+CREATE OR REPLACE FUNCTION enqueue_webhook_event() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO webhook_outbox (event_id) VALUES (NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER events_webhook_outbox AFTER INSERT ON events
+    FOR EACH ROW WHEN (NEW.aggregate_type IN ('account', 'account_shard'))
+    EXECUTE FUNCTION enqueue_webhook_event();
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    webhook_id bigint NOT NULL REFERENCES webhooks (id),
+    event_id bigint NOT NULL,
+    event_type varchar(100) NOT NULL,
+    payload jsonb NOT NULL,
+    status varchar(20) NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts int NOT NULL DEFAULT 0,
+    next_attempt_at timestamp NOT NULL,
+    last_status_code int NULL,
+    last_error varchar(255) NOT NULL DEFAULT '',
+    delivered_at timestamp NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT webhook_deliveries_webhook_event_key UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_status_next_attempt_at_idx ON webhook_deliveries (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    delivery_id bigint NOT NULL REFERENCES webhook_deliveries (id),
+    attempt int NOT NULL,
+    status_code int NULL,
+    error varchar(255) NOT NULL DEFAULT '',
+    duration_ms bigint NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_id_idx ON webhook_delivery_attempts (delivery_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS risk_decisions (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transaction_id varchar(100) NOT NULL,
+    source_account_id bigint NOT NULL,
+    destination_account_id bigint NOT NULL,
+    amount decimal(10, 5) NOT NULL,
+    action varchar(20) NOT NULL CHECK (action IN ('allow', 'deny', 'review')),
+    rule varchar(100) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE risk_decisions ADD CONSTRAINT risk_decisions_transaction_id_unique UNIQUE (transaction_id);
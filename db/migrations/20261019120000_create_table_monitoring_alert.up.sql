@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS monitoring_alerts (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    detector varchar(50) NOT NULL,
+    account_id bigint NOT NULL,
+    reason varchar(255) NOT NULL,
+    amount decimal(18, 5) NOT NULL,
+    event_ids bigint[] NOT NULL,
+    window_start timestamp NOT NULL,
+    window_end timestamp NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'escalated')),
+    note varchar(255) NOT NULL DEFAULT '',
+    reviewed_by varchar(100) NULL,
+    reviewed_at timestamp NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS monitoring_alerts_status_idx ON monitoring_alerts (status, id);
+
+-- a pattern is reported once, two scans running at the same time find it with the same first movement
+CREATE UNIQUE INDEX IF NOT EXISTS monitoring_alerts_detector_account_first_event_key
+    ON monitoring_alerts (detector, account_id, (event_ids[1]));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sanctions_screenings;
+DROP TABLE IF EXISTS sanctions_lists;
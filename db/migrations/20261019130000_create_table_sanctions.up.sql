@@ -0,0 +1,26 @@
+-- every version of the sanctions list loaded by the application, the screenings refer to the version they used
+CREATE TABLE IF NOT EXISTS sanctions_lists (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    version varchar(100) NOT NULL,
+    checksum varchar(64) NOT NULL,
+    format varchar(20) NOT NULL CHECK (format IN ('csv', 'sdn_xml')),
+    source varchar(1024) NOT NULL,
+    entries integer NOT NULL,
+    loaded_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE sanctions_lists ADD CONSTRAINT sanctions_lists_checksum_unique UNIQUE (checksum);
+
+CREATE TABLE IF NOT EXISTS sanctions_screenings (
+    id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    transaction_id varchar(100) NOT NULL,
+    source_account_id bigint NOT NULL,
+    destination_account_id bigint NOT NULL,
+    amount decimal(10, 5) NOT NULL,
+    action varchar(20) NOT NULL CHECK (action IN ('block', 'hold')),
+    list_version varchar(100) NOT NULL,
+    hits jsonb NOT NULL DEFAULT '[]',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE sanctions_screenings ADD CONSTRAINT sanctions_screenings_transaction_id_unique UNIQUE (transaction_id);
@@ -0,0 +1,4 @@
+ALTER TABLE postings ALTER COLUMN amount TYPE decimal(10, 5);
+ALTER TABLE interest_accruals ALTER COLUMN balance TYPE decimal(10, 5);
+ALTER TABLE account_shards ALTER COLUMN balance TYPE decimal(10, 5);
+ALTER TABLE accounts ALTER COLUMN balance TYPE decimal(10, 5);
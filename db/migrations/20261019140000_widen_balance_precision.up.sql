@@ -0,0 +1,6 @@
+-- the system accounts accumulate every movement with a single customer account, a running balance outgrows
+-- decimal(10, 5) long before a single amount does
+ALTER TABLE accounts ALTER COLUMN balance TYPE numeric(20, 5);
+ALTER TABLE account_shards ALTER COLUMN balance TYPE numeric(20, 5);
+ALTER TABLE interest_accruals ALTER COLUMN balance TYPE numeric(20, 5);
+ALTER TABLE postings ALTER COLUMN amount TYPE numeric(20, 5);
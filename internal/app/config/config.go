@@ -2,10 +2,40 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+// IDList is a comma separated list of ids, e.g. 900004,900005.
+type IDList []int64
+
+func (l *IDList) UnmarshalText(text []byte) error {
+	ids := IDList{}
+
+	for _, field := range strings.Split(string(text), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse id %q: %w", field, err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	*l = ids
+
+	return nil
+}
+
 type LogLeveler string
 
 func (l LogLeveler) Level() slog.Level {
@@ -18,16 +48,32 @@ func (l LogLeveler) Level() slog.Level {
 
 // Config holds the server configuration.
 type Config struct {
-	LogLevel             LogLeveler    `mapstructure:"LOG_LEVEL"`
-	ServiceTokens        string        `mapstructure:"SERVICE_TOKENS"`
-	TracingEnabled       bool          `mapstructure:"TRACING_ENABLED"`
-	ProfilingEnabled     bool          `mapstructure:"PROFILING_ENABLED"`
-	RequestTimeThreshold time.Duration `mapstructure:"REQUEST_TIME_THRESHOLD"`
-	EventVersion         string        `mapstructure:"EVENT_VERSION"`
-	DB                   DB            `mapstructure:",squash"`
-	HTTP                 HTTP          `mapstructure:",squash"`
-	HTTPCaller           HTTPCaller    `mapstructure:",squash"`
-	Locales              Locales       `mapstructure:",squash"`
+	LogLevel             LogLeveler       `mapstructure:"LOG_LEVEL"`
+	ServiceTokens        []string         `mapstructure:"SERVICE_TOKENS"`
+	TracingEnabled       bool             `mapstructure:"TRACING_ENABLED"`
+	ProfilingEnabled     bool             `mapstructure:"PROFILING_ENABLED"`
+	RequestTimeThreshold time.Duration    `mapstructure:"REQUEST_TIME_THRESHOLD"`
+	EventVersion         string           `mapstructure:"EVENT_VERSION"`
+	DB                   DB               `mapstructure:",squash"`
+	HTTP                 HTTP             `mapstructure:",squash"`
+	HTTPCaller           HTTPCaller       `mapstructure:",squash"`
+	Locales              Locales          `mapstructure:",squash"`
+	Scheduler            Scheduler        `mapstructure:",squash"`
+	StandingOrder        StandingOrder    `mapstructure:",squash"`
+	TransferLimit        TransferLimit    `mapstructure:",squash"`
+	TransferApproval     TransferApproval `mapstructure:",squash"`
+	IdempotencyKey       IdempotencyKey   `mapstructure:",squash"`
+	Fee                  Fee              `mapstructure:",squash"`
+	Interest             Interest         `mapstructure:",squash"`
+	Ledger               Ledger           `mapstructure:",squash"`
+	HotAccount           HotAccount       `mapstructure:",squash"`
+	Reconciliation       Reconciliation   `mapstructure:",squash"`
+	PaymentBatch         PaymentBatch     `mapstructure:",squash"`
+	Webhook              Webhook          `mapstructure:",squash"`
+	Risk                 Risk             `mapstructure:",squash"`
+	Monitoring           Monitoring       `mapstructure:",squash"`
+	Sanctions            Sanctions        `mapstructure:",squash"`
+	Signature            Signature        `mapstructure:",squash"`
 }
 
 type DB struct {
@@ -36,6 +82,11 @@ type DB struct {
 	MaxIdleConnections    int           `mapstructure:"DB_MAX_IDLE_CONNECTIONS"`
 	MaxConnectionLifetime time.Duration `mapstructure:"DB_MAX_CONNECTIONS_LIFETIME"`
 	MaxIdleConnectionTime time.Duration `mapstructure:"DB_MAX_IDLE_CONNECTIONS_TIME"`
+	// IsolationLevel is the isolation level of the transactions, e.g. read_committed or serializable.
+	IsolationLevel string `mapstructure:"DB_ISOLATION_LEVEL"`
+	// TxMaxRetries is how often a transaction failing with a serialization failure or a deadlock is retried.
+	TxMaxRetries int           `mapstructure:"DB_TX_MAX_RETRIES"`
+	TxRetryDelay time.Duration `mapstructure:"DB_TX_RETRY_DELAY"`
 }
 
 type HTTP struct {
@@ -54,3 +105,124 @@ type Locales struct {
 	BasePath           string `mapstructure:"LOCALES_BASE_PATH"`
 	SupportedLanguages string `mapstructure:"LOCALES_SUPPORTED_LANGUAGES"`
 }
+
+type Scheduler struct {
+	Interval     time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	BatchSize    int           `mapstructure:"SCHEDULER_BATCH_SIZE"`
+	ClaimTimeout time.Duration `mapstructure:"SCHEDULER_CLAIM_TIMEOUT"`
+}
+
+type StandingOrder struct {
+	MaxRetries    int           `mapstructure:"STANDING_ORDER_MAX_RETRIES"`
+	RetryInterval time.Duration `mapstructure:"STANDING_ORDER_RETRY_INTERVAL"`
+}
+
+// TransferLimit holds the global outgoing transfer limits, a zero value disables the limit.
+type TransferLimit struct {
+	PerTransaction decimal.Decimal `mapstructure:"TRANSFER_LIMIT_PER_TRANSACTION"`
+	Daily          decimal.Decimal `mapstructure:"TRANSFER_LIMIT_DAILY"`
+	Monthly        decimal.Decimal `mapstructure:"TRANSFER_LIMIT_MONTHLY"`
+	HourlyCount    int             `mapstructure:"TRANSFER_LIMIT_HOURLY_COUNT"`
+}
+
+// TransferApproval holds the amount above which a transfer waits for a second person to approve it and how long
+// it can be approved, a zero threshold disables the approval.
+type TransferApproval struct {
+	Threshold decimal.Decimal `mapstructure:"TRANSFER_APPROVAL_THRESHOLD"`
+	TTL       time.Duration   `mapstructure:"TRANSFER_APPROVAL_TTL"`
+}
+
+// IdempotencyKey holds how long a transaction id is kept, its stored response is replayed until then.
+type IdempotencyKey struct {
+	Retention time.Duration `mapstructure:"IDEMPOTENCY_KEY_RETENTION"`
+}
+
+// Fee holds the transfer fee configuration, an empty schedule path disables transfer fees.
+type Fee struct {
+	SchedulePath string `mapstructure:"FEE_SCHEDULE_PATH"`
+}
+
+// Interest holds the annual interest rates, in percent, of each account type and the accrual job settings.
+type Interest struct {
+	Interval     time.Duration   `mapstructure:"INTEREST_INTERVAL"`
+	PersonalRate decimal.Decimal `mapstructure:"INTEREST_RATE_PERSONAL"`
+	BusinessRate decimal.Decimal `mapstructure:"INTEREST_RATE_BUSINESS"`
+	SavingsRate  decimal.Decimal `mapstructure:"INTEREST_RATE_SAVINGS"`
+	DayCount     int             `mapstructure:"INTEREST_DAY_COUNT"`
+	CatchUpDays  int             `mapstructure:"INTEREST_CATCH_UP_DAYS"`
+}
+
+// Ledger holds the system accounts on the other side of the movements that do not involve two customer accounts,
+// they are created on start when missing.
+type Ledger struct {
+	FundingAccountID  int64 `mapstructure:"LEDGER_FUNDING_ACCOUNT_ID"`
+	InterestAccountID int64 `mapstructure:"LEDGER_INTEREST_ACCOUNT_ID"`
+}
+
+// HotAccount holds the accounts whose credits are spread over shards and their number of shards, no account is
+// sharded by default.
+type HotAccount struct {
+	AccountIDs IDList `mapstructure:"HOT_ACCOUNT_IDS"`
+	Shards     int    `mapstructure:"HOT_ACCOUNT_SHARDS"`
+}
+
+// Reconciliation holds how far apart the booking date of a bank line and the date of a ledger movement may be for
+// them to match.
+type Reconciliation struct {
+	DateTolerance time.Duration `mapstructure:"RECONCILIATION_DATE_TOLERANCE"`
+}
+
+// PaymentBatch holds the number of transactions above which a payment file is refused.
+type PaymentBatch struct {
+	MaxTransactions int `mapstructure:"PAYMENT_BATCH_MAX_TRANSACTIONS"`
+}
+
+// Webhook holds how often the events are sent to the webhook subscriptions, the timeout of a callback and the
+// backoff of the retries of a failed callback.
+type Webhook struct {
+	Interval    time.Duration `mapstructure:"WEBHOOK_INTERVAL"`
+	Timeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	MaxAttempts int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	BackoffBase time.Duration `mapstructure:"WEBHOOK_BACKOFF_BASE"`
+	BackoffMax  time.Duration `mapstructure:"WEBHOOK_BACKOFF_MAX"`
+}
+
+// Risk holds the file of the risk rules evaluated before a transfer and how often it is checked for changes, an
+// empty rules path disables the risk rules.
+type Risk struct {
+	RulesPath      string        `mapstructure:"RISK_RULES_PATH"`
+	ReloadInterval time.Duration `mapstructure:"RISK_RULES_RELOAD_INTERVAL"`
+}
+
+// Monitoring holds how often the account movements are scanned for suspicious patterns, the sliding window the
+// patterns are looked for in and the settings of each detector. A zero threshold, amount or count disables the
+// detector.
+type Monitoring struct {
+	Interval                time.Duration   `mapstructure:"MONITORING_INTERVAL"`
+	Window                  time.Duration   `mapstructure:"MONITORING_WINDOW"`
+	StructuringThreshold    decimal.Decimal `mapstructure:"MONITORING_STRUCTURING_THRESHOLD"`
+	StructuringMargin       decimal.Decimal `mapstructure:"MONITORING_STRUCTURING_MARGIN"`
+	StructuringMinCount     int             `mapstructure:"MONITORING_STRUCTURING_MIN_COUNT"`
+	RapidMovementMinAmount  decimal.Decimal `mapstructure:"MONITORING_RAPID_MOVEMENT_MIN_AMOUNT"`
+	RapidMovementRatio      decimal.Decimal `mapstructure:"MONITORING_RAPID_MOVEMENT_RATIO"`
+	FanInMinCounterparties  int             `mapstructure:"MONITORING_FAN_IN_MIN_COUNTERPARTIES"`
+	FanOutMinCounterparties int             `mapstructure:"MONITORING_FAN_OUT_MIN_COUNTERPARTIES"`
+}
+
+// Sanctions holds the sanctions list file, a CSV file or an OFAC SDN XML file, the parties of a transfer are screened
+// against, how often it is checked for changes and the scores of a match holding or blocking the transfer. An empty
+// list path disables the screening.
+type Sanctions struct {
+	ListPath       string        `mapstructure:"SANCTIONS_LIST_PATH"`
+	ReloadInterval time.Duration `mapstructure:"SANCTIONS_RELOAD_INTERVAL"`
+	HoldScore      float64       `mapstructure:"SANCTIONS_HOLD_SCORE"`
+	BlockScore     float64       `mapstructure:"SANCTIONS_BLOCK_SCORE"`
+}
+
+// Signature holds the keys of the API clients, each written as "client:algorithm:key" with an hmac-sha256 secret or
+// an ed25519 public key in base64, and how far the X-Timestamp of a signed request may be from now. No keys disables
+// the signature verification.
+type Signature struct {
+	Keys      []string      `mapstructure:"SIGNATURE_KEYS"`
+	Tolerance time.Duration `mapstructure:"SIGNATURE_TOLERANCE"`
+}
@@ -3,9 +3,12 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,4 +31,75 @@ func TestLoadConfig(t *testing.T) {
 func TestDefaultValues(t *testing.T) {
 	config := MustInitConfig("../../../test/api/fixtures/.env.dummy")
 	assert.Equal(t, LogLeveler("info"), config.LogLevel)
+	assert.Equal(t, "read_committed", config.DB.IsolationLevel)
+	assert.Equal(t, 3, config.DB.TxMaxRetries)
+	assert.Equal(t, 50*time.Millisecond, config.DB.TxRetryDelay)
+	assert.Equal(t, time.Minute, config.Scheduler.Interval)
+	assert.Equal(t, 100, config.Scheduler.BatchSize)
+	assert.Equal(t, 5*time.Minute, config.Scheduler.ClaimTimeout)
+	assert.Equal(t, 3, config.StandingOrder.MaxRetries)
+	assert.Equal(t, time.Hour, config.StandingOrder.RetryInterval)
+	assert.True(t, config.TransferLimit.PerTransaction.IsZero())
+	assert.True(t, config.TransferLimit.Daily.IsZero())
+	assert.True(t, config.TransferLimit.Monthly.IsZero())
+	assert.Equal(t, 0, config.TransferLimit.HourlyCount)
+	assert.True(t, config.TransferApproval.Threshold.IsZero())
+	assert.Equal(t, 24*time.Hour, config.TransferApproval.TTL)
+	assert.Equal(t, 72*time.Hour, config.IdempotencyKey.Retention)
+	assert.Empty(t, config.Fee.SchedulePath)
+	assert.Equal(t, time.Hour, config.Interest.Interval)
+	assert.True(t, config.Interest.SavingsRate.IsZero())
+	assert.Equal(t, 365, config.Interest.DayCount)
+	assert.Equal(t, 7, config.Interest.CatchUpDays)
+	assert.Equal(t, int64(900001), config.Ledger.FundingAccountID)
+	assert.Equal(t, int64(900002), config.Ledger.InterestAccountID)
+	assert.Empty(t, config.HotAccount.AccountIDs)
+	assert.Equal(t, 8, config.HotAccount.Shards)
+	assert.Equal(t, 48*time.Hour, config.Reconciliation.DateTolerance)
+	assert.Equal(t, 1000, config.PaymentBatch.MaxTransactions)
+	assert.Equal(t, 5*time.Second, config.Webhook.Interval)
+	assert.Equal(t, 10*time.Second, config.Webhook.Timeout)
+	assert.Equal(t, 8, config.Webhook.MaxAttempts)
+	assert.Equal(t, 30*time.Second, config.Webhook.BackoffBase)
+	assert.Equal(t, 6*time.Hour, config.Webhook.BackoffMax)
+	assert.Empty(t, config.Risk.RulesPath)
+	assert.Equal(t, 30*time.Second, config.Risk.ReloadInterval)
+	assert.Equal(t, time.Hour, config.Monitoring.Interval)
+	assert.Equal(t, 24*time.Hour, config.Monitoring.Window)
+	assert.Equal(t, "10000", config.Monitoring.StructuringThreshold.String())
+	assert.Equal(t, "0.1", config.Monitoring.StructuringMargin.String())
+	assert.Equal(t, 3, config.Monitoring.StructuringMinCount)
+	assert.Equal(t, "5000", config.Monitoring.RapidMovementMinAmount.String())
+	assert.Equal(t, "0.9", config.Monitoring.RapidMovementRatio.String())
+	assert.Equal(t, 10, config.Monitoring.FanInMinCounterparties)
+	assert.Equal(t, 10, config.Monitoring.FanOutMinCounterparties)
+	assert.Empty(t, config.Sanctions.ListPath)
+	assert.Equal(t, time.Minute, config.Sanctions.ReloadInterval)
+	assert.InDelta(t, 0.85, config.Sanctions.HoldScore, 1e-9)
+	assert.InDelta(t, 0.95, config.Sanctions.BlockScore, 1e-9)
+	assert.Empty(t, config.ServiceTokens)
+	assert.Empty(t, config.Signature.Keys)
+	assert.Equal(t, 5*time.Minute, config.Signature.Tolerance)
+}
+
+func TestDecimalValues(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".env")
+	content := "TRANSFER_LIMIT_PER_TRANSACTION=2500.50\nTRANSFER_LIMIT_DAILY=10000\nTRANSFER_LIMIT_HOURLY_COUNT=20\n"
+	assert.NoError(t, os.WriteFile(configFile, []byte(content), 0o600))
+
+	config := MustInitConfig(configFile)
+	assert.True(t, decimal.RequireFromString("2500.50").Equal(config.TransferLimit.PerTransaction))
+	assert.True(t, decimal.NewFromInt(10000).Equal(config.TransferLimit.Daily))
+	assert.True(t, config.TransferLimit.Monthly.IsZero())
+	assert.Equal(t, 20, config.TransferLimit.HourlyCount)
+}
+
+func TestHotAccountValues(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".env")
+	content := "HOT_ACCOUNT_IDS=900004,42\nHOT_ACCOUNT_SHARDS=16\n"
+	assert.NoError(t, os.WriteFile(configFile, []byte(content), 0o600))
+
+	config := MustInitConfig(configFile)
+	assert.Equal(t, IDList{900004, 42}, config.HotAccount.AccountIDs)
+	assert.Equal(t, 16, config.HotAccount.Shards)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
@@ -20,6 +21,57 @@ func MustInitConfig(configFile string) Config {
 
 	// default values
 	vpr.SetDefault("LOG_LEVEL", "info")
+	vpr.SetDefault("DB_ISOLATION_LEVEL", "read_committed")
+	vpr.SetDefault("DB_TX_MAX_RETRIES", 3)
+	vpr.SetDefault("DB_TX_RETRY_DELAY", "50ms")
+	vpr.SetDefault("SCHEDULER_INTERVAL", "1m")
+	vpr.SetDefault("SCHEDULER_BATCH_SIZE", 100)
+	vpr.SetDefault("SCHEDULER_CLAIM_TIMEOUT", "5m")
+	vpr.SetDefault("STANDING_ORDER_MAX_RETRIES", 3)
+	vpr.SetDefault("STANDING_ORDER_RETRY_INTERVAL", "1h")
+	vpr.SetDefault("TRANSFER_LIMIT_PER_TRANSACTION", "0")
+	vpr.SetDefault("TRANSFER_LIMIT_DAILY", "0")
+	vpr.SetDefault("TRANSFER_LIMIT_MONTHLY", "0")
+	vpr.SetDefault("TRANSFER_LIMIT_HOURLY_COUNT", 0)
+	vpr.SetDefault("TRANSFER_APPROVAL_THRESHOLD", "0")
+	vpr.SetDefault("TRANSFER_APPROVAL_TTL", "24h")
+	vpr.SetDefault("IDEMPOTENCY_KEY_RETENTION", "72h")
+	vpr.SetDefault("FEE_SCHEDULE_PATH", "")
+	vpr.SetDefault("INTEREST_INTERVAL", "1h")
+	vpr.SetDefault("INTEREST_RATE_PERSONAL", "0")
+	vpr.SetDefault("INTEREST_RATE_BUSINESS", "0")
+	vpr.SetDefault("INTEREST_RATE_SAVINGS", "0")
+	vpr.SetDefault("INTEREST_DAY_COUNT", 365)
+	vpr.SetDefault("INTEREST_CATCH_UP_DAYS", 7)
+	vpr.SetDefault("LEDGER_FUNDING_ACCOUNT_ID", 900001)
+	vpr.SetDefault("LEDGER_INTEREST_ACCOUNT_ID", 900002)
+	vpr.SetDefault("HOT_ACCOUNT_IDS", "")
+	vpr.SetDefault("HOT_ACCOUNT_SHARDS", 8)
+	vpr.SetDefault("RECONCILIATION_DATE_TOLERANCE", "48h")
+	vpr.SetDefault("PAYMENT_BATCH_MAX_TRANSACTIONS", 1000)
+	vpr.SetDefault("WEBHOOK_INTERVAL", "5s")
+	vpr.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	vpr.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	vpr.SetDefault("WEBHOOK_BACKOFF_BASE", "30s")
+	vpr.SetDefault("WEBHOOK_BACKOFF_MAX", "6h")
+	vpr.SetDefault("RISK_RULES_PATH", "")
+	vpr.SetDefault("RISK_RULES_RELOAD_INTERVAL", "30s")
+	vpr.SetDefault("MONITORING_INTERVAL", "1h")
+	vpr.SetDefault("MONITORING_WINDOW", "24h")
+	vpr.SetDefault("MONITORING_STRUCTURING_THRESHOLD", "10000")
+	vpr.SetDefault("MONITORING_STRUCTURING_MARGIN", "0.1")
+	vpr.SetDefault("MONITORING_STRUCTURING_MIN_COUNT", 3)
+	vpr.SetDefault("MONITORING_RAPID_MOVEMENT_MIN_AMOUNT", "5000")
+	vpr.SetDefault("MONITORING_RAPID_MOVEMENT_RATIO", "0.9")
+	vpr.SetDefault("MONITORING_FAN_IN_MIN_COUNTERPARTIES", 10)
+	vpr.SetDefault("MONITORING_FAN_OUT_MIN_COUNTERPARTIES", 10)
+	vpr.SetDefault("SANCTIONS_LIST_PATH", "")
+	vpr.SetDefault("SANCTIONS_RELOAD_INTERVAL", "1m")
+	vpr.SetDefault("SANCTIONS_HOLD_SCORE", 0.85)
+	vpr.SetDefault("SANCTIONS_BLOCK_SCORE", 0.95)
+	vpr.SetDefault("SERVICE_TOKENS", "")
+	vpr.SetDefault("SIGNATURE_KEYS", "")
+	vpr.SetDefault("SIGNATURE_TOLERANCE", "5m")
 
 	if err := vpr.ReadInConfig(); err != nil {
 		slog.Error("cannot read local config file", slog.String("error", err.Error()))
@@ -27,7 +79,14 @@ func MustInitConfig(configFile string) Config {
 		panic(err)
 	}
 
-	if err := vpr.Unmarshal(&cfg); err != nil {
+	// decimal values such as transfer limits and id lists are decoded through encoding.TextUnmarshaler
+	decodeHook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.TextUnmarshallerHookFunc(),
+	))
+
+	if err := vpr.Unmarshal(&cfg, decodeHook); err != nil {
 		slog.Error("cannot unmarshal config file", slog.String("error", err.Error()))
 
 		panic(err)
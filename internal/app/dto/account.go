@@ -5,14 +5,25 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
 	"github.com/shopspring/decimal"
 )
 
 type CreateAccountRequest struct {
 	AccountID      int64           `json:"account_id"      validate:"required"`
 	InitialBalance decimal.Decimal `json:"initial_balance" validate:"required,decimal_gt_zero"`
+	// AccountType defaults to personal, the system accounts are created by the application.
+	AccountType string `json:"account_type" validate:"omitempty,oneof=personal business savings"`
+	// Currency is an ISO 4217 code, it defaults to USD.
+	Currency       string            `json:"currency"        validate:"omitempty,iso4217"`
+	DisplayName    string            `json:"display_name"    validate:"max=100"`
+	OwnerReference string            `json:"owner_reference" validate:"max=100"`
+	Labels         map[string]string `json:"labels"          validate:"max=20,dive,keys,min=1,max=63,endkeys,max=255"`
 }
 
 func (req *CreateAccountRequest) Bind(_ *http.Request) error {
@@ -49,7 +60,245 @@ func (req *GetAccountRequest) Bind(r *http.Request) error {
 	return nil
 }
 
+type AccountStatusRequest struct {
+	ID     int64  `json:"-"      validate:"required"`
+	Reason string `json:"reason" validate:"required,account_status_reason"`
+}
+
+func (req *AccountStatusRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate account status request: %w", err)
+	}
+
+	return nil
+}
+
+type CloseAccountRequest struct {
+	ID     int64  `json:"-"      validate:"required"`
+	Reason string `json:"reason" validate:"required,account_status_reason"`
+	// SweepAccountID receives the remaining balance, it is required when the balance is not zero.
+	SweepAccountID *int64 `json:"sweep_account_id"`
+}
+
+func (req *CloseAccountRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate account close request: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAccountProfileRequest changes the profile of an account, the omitted fields are left unchanged and
+// labels replace the current labels.
+type UpdateAccountProfileRequest struct {
+	ID             int64             `json:"-"               validate:"required"`
+	DisplayName    *string           `json:"display_name"    validate:"omitempty,max=100"`
+	AccountType    *string           `json:"account_type"    validate:"omitempty,oneof=personal business savings"`
+	OwnerReference *string           `json:"owner_reference" validate:"omitempty,max=100"`
+	Labels         map[string]string `json:"labels"          validate:"max=20,dive,keys,min=1,max=63,endkeys,max=255"`
+}
+
+func (req *UpdateAccountProfileRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate update account profile request: %w", err)
+	}
+
+	return nil
+}
+
+type SetOverdraftLimitRequest struct {
+	ID    int64            `json:"-"     validate:"required"`
+	Limit *decimal.Decimal `json:"limit" validate:"required,decimal_gte_zero"`
+}
+
+func (req *SetOverdraftLimitRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate set overdraft limit request: %w", err)
+	}
+
+	return nil
+}
+
+type SetTransferLimitRequest struct {
+	ID             int64            `json:"-"               validate:"required"`
+	PerTransaction *decimal.Decimal `json:"per_transaction" validate:"omitempty,decimal_gte_zero"`
+	Daily          *decimal.Decimal `json:"daily"           validate:"omitempty,decimal_gte_zero"`
+	Monthly        *decimal.Decimal `json:"monthly"         validate:"omitempty,decimal_gte_zero"`
+	HourlyCount    *int             `json:"hourly_count"    validate:"omitempty,gte=0"`
+}
+
+func (req *SetTransferLimitRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate set transfer limit request: %w", err)
+	}
+
+	return nil
+}
+
+// TransferLimitResponse holds the effective limits, a zero limit is disabled.
+type TransferLimitResponse struct {
+	AccountID      int64                 `json:"account_id"`
+	PerTransaction decimal.Decimal       `json:"per_transaction"`
+	Daily          decimal.Decimal       `json:"daily"`
+	Monthly        decimal.Decimal       `json:"monthly"`
+	HourlyCount    int                   `json:"hourly_count"`
+	Override       TransferLimitOverride `json:"override"`
+}
+
+type TransferLimitOverride struct {
+	PerTransaction *decimal.Decimal `json:"per_transaction"`
+	Daily          *decimal.Decimal `json:"daily"`
+	Monthly        *decimal.Decimal `json:"monthly"`
+	HourlyCount    *int             `json:"hourly_count"`
+}
+
+type SetInterestRateRequest struct {
+	ID   int64            `json:"-"    validate:"required"`
+	Rate *decimal.Decimal `json:"rate" validate:"omitempty,decimal_gte_zero"`
+}
+
+func (req *SetInterestRateRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate set interest rate request: %w", err)
+	}
+
+	return nil
+}
+
+// InterestRateResponse holds the effective annual interest rate in percent.
+type InterestRateResponse struct {
+	AccountID   int64            `json:"account_id"`
+	AccountType string           `json:"account_type"`
+	Rate        decimal.Decimal  `json:"rate"`
+	Override    *decimal.Decimal `json:"override"`
+}
+
 type AccountResponse struct {
-	AccountID int64           `json:"account_id"`
-	Balance   decimal.Decimal `json:"balance"`
+	AccountID          int64             `json:"account_id"`
+	AccountType        string            `json:"account_type"`
+	Currency           string            `json:"currency"`
+	DisplayName        string            `json:"display_name"`
+	OwnerReference     string            `json:"owner_reference"`
+	Labels             map[string]string `json:"labels"`
+	Balance            decimal.Decimal   `json:"balance"`
+	AvailableBalance   decimal.Decimal   `json:"available_balance"`
+	OverdraftLimit     decimal.Decimal   `json:"overdraft_limit"`
+	OverdraftUsed      decimal.Decimal   `json:"overdraft_used"`
+	OverdraftRemaining decimal.Decimal   `json:"overdraft_remaining"`
+	Status             string            `json:"status"`
+	CreatedAt          time.Time         `json:"created_at"`
+}
+
+type ListAccountsRequest struct {
+	MinBalance  *decimal.Decimal `json:"min_balance"`
+	MaxBalance  *decimal.Decimal `json:"max_balance"`
+	CreatedFrom *time.Time       `json:"created_from"`
+	CreatedTo   *time.Time       `json:"created_to"`
+	Status      string           `json:"status"       validate:"omitempty,oneof=active frozen closed"`
+	Currency    string           `json:"currency"     validate:"omitempty,iso4217"`
+	// Sort is the sort field, prefixed with - for the descending order.
+	Sort   string `json:"sort"   validate:"oneof=id -id balance -balance created_at -created_at"`
+	Limit  int    `json:"limit"  validate:"min=1,max=100"`
+	Cursor string `json:"cursor"`
+}
+
+func (req *ListAccountsRequest) Bind(r *http.Request) error {
+	query := r.URL.Query()
+
+	var err error
+
+	if req.MinBalance, err = decimalQueryParam(query, "min_balance"); err != nil {
+		return err
+	}
+
+	if req.MaxBalance, err = decimalQueryParam(query, "max_balance"); err != nil {
+		return err
+	}
+
+	if req.CreatedFrom, err = timeQueryParam(query, "created_from"); err != nil {
+		return err
+	}
+
+	if req.CreatedTo, err = timeQueryParam(query, "created_to"); err != nil {
+		return err
+	}
+
+	req.Status = query.Get("status")
+	req.Currency = strings.ToUpper(query.Get("currency"))
+	req.Cursor = query.Get("cursor")
+
+	req.Sort = query.Get("sort")
+	if req.Sort == "" {
+		req.Sort = string(model.AccountSortFieldID)
+	}
+
+	req.Limit = pagination.DefaultLimit
+
+	if limit := query.Get("limit"); limit != "" {
+		if req.Limit, err = strconv.Atoi(limit); err != nil {
+			return fmt.Errorf("invalid limit format: %w", err)
+		}
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate list accounts request: %w", err)
+	}
+
+	return nil
+}
+
+// SortField returns the sort field and whether the order is descending.
+func (req ListAccountsRequest) SortField() (model.AccountSortField, bool) {
+	field, descending := strings.CutPrefix(req.Sort, "-")
+
+	return model.AccountSortField(field), descending
 }
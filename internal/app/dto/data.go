@@ -1,7 +1,16 @@
 package dto
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
 	"github.com/shopspring/decimal"
 )
 
@@ -12,6 +21,12 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// ListResponse is a page of a list endpoint, NextCursor is null on the last page.
+type ListResponse[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+}
+
 func decimalGreaterThanZero(fl validator.FieldLevel) bool {
 	val, ok := fl.Field().Interface().(decimal.Decimal)
 	if !ok {
@@ -21,6 +36,86 @@ func decimalGreaterThanZero(fl validator.FieldLevel) bool {
 	return val.GreaterThan(decimal.Zero)
 }
 
+func decimalGreaterThanOrEqualZero(fl validator.FieldLevel) bool {
+	val, ok := fl.Field().Interface().(decimal.Decimal)
+	if !ok {
+		return false
+	}
+
+	return !val.IsNegative()
+}
+
+// accountStatusReason accepts the reason codes of an account lifecycle change.
+func accountStatusReason(fl validator.FieldLevel) bool {
+	switch model.AccountStatusReason(fl.Field().String()) {
+	case model.AccountStatusReasonCustomerRequest, model.AccountStatusReasonComplianceReview,
+		model.AccountStatusReasonFraudSuspected, model.AccountStatusReasonCourtOrder,
+		model.AccountStatusReasonReviewCleared, model.AccountStatusReasonDormant:
+		return true
+	}
+
+	return false
+}
+
+// webhookEventType accepts the account events a webhook can receive.
+func webhookEventType(fl validator.FieldLevel) bool {
+	return slices.Contains(model.WebhookEventTypes, model.EventType(fl.Field().String()))
+}
+
 func init() { //nolint:gochecknoinits
-	validate.RegisterValidation("decimal_gt_zero", decimalGreaterThanZero) //nolint:errcheck
+	validate.RegisterValidation("decimal_gt_zero", decimalGreaterThanZero)         //nolint:errcheck
+	validate.RegisterValidation("decimal_gte_zero", decimalGreaterThanOrEqualZero) //nolint:errcheck
+	validate.RegisterValidation("account_status_reason", accountStatusReason)      //nolint:errcheck
+	validate.RegisterValidation("webhook_event_type", webhookEventType)            //nolint:errcheck
+}
+
+// int64URLParam reads and parses a numeric path parameter.
+func int64URLParam(r *http.Request, key string) (int64, error) {
+	param := chi.URLParam(r, key)
+	if param == "" {
+		return 0, fmt.Errorf("%s is required", key)
+	}
+
+	parsed, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s format: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+// decimalQueryParam reads an optional decimal query parameter.
+func decimalQueryParam(query url.Values, key string) (*decimal.Decimal, error) {
+	param := query.Get(key)
+	if param == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	parsed, err := decimal.NewFromString(param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s format: %w", key, err)
+	}
+
+	return &parsed, nil
+}
+
+// timeQueryParam reads an optional RFC3339 query parameter.
+func timeQueryParam(query url.Values, key string) (*time.Time, error) {
+	param := query.Get(key)
+	if param == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s format: %w", key, err)
+	}
+
+	return &parsed, nil
+}
+
+// actorID is the client acting on the request, as authenticated by its service token or its signature. It is never
+// read from a header the client is free to set.
+func actorID(r *http.Request) string {
+	return ClientIDFromContext(r.Context())
 }
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// TrialBalanceRequest has no parameters, the trial balance sums every posting.
+type TrialBalanceRequest struct{}
+
+func (req *TrialBalanceRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+type TrialBalanceResponse struct {
+	Accounts     []TrialBalanceAccount `json:"accounts"`
+	TotalDebits  decimal.Decimal       `json:"total_debits"`
+	TotalCredits decimal.Decimal       `json:"total_credits"`
+	// Difference is the total debits minus the total credits, zero when the ledger is balanced.
+	Difference decimal.Decimal `json:"difference"`
+	Balanced   bool            `json:"balanced"`
+}
+
+// TrialBalanceAccount holds the posted totals of an account, the balance is the credits minus the debits.
+type TrialBalanceAccount struct {
+	AccountID int64           `json:"account_id"`
+	Debits    decimal.Decimal `json:"debits"`
+	Credits   decimal.Decimal `json:"credits"`
+	Balance   decimal.Decimal `json:"balance"`
+}
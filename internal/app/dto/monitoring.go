@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
+	"github.com/shopspring/decimal"
+)
+
+type ListMonitoringAlertsRequest struct {
+	Status    string `json:"status"     validate:"omitempty,oneof=open dismissed escalated"`
+	Detector  string `json:"detector"   validate:"max=50"`
+	AccountID int64  `json:"account_id" validate:"min=0"`
+	Limit     int    `json:"limit"      validate:"min=1,max=100"`
+	Cursor    string `json:"cursor"`
+}
+
+func (req *ListMonitoringAlertsRequest) Bind(r *http.Request) error {
+	var err error
+
+	query := r.URL.Query()
+
+	req.Status = query.Get("status")
+	req.Detector = query.Get("detector")
+	req.Cursor = query.Get("cursor")
+	req.Limit = pagination.DefaultLimit
+
+	if accountID := query.Get("account_id"); accountID != "" {
+		if req.AccountID, err = strconv.ParseInt(accountID, 10, 64); err != nil {
+			return fmt.Errorf("invalid account_id format: %w", err)
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		if req.Limit, err = strconv.Atoi(limit); err != nil {
+			return fmt.Errorf("invalid limit format: %w", err)
+		}
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate list monitoring alerts request: %w", err)
+	}
+
+	return nil
+}
+
+type MonitoringAlertIDRequest struct {
+	ID int64 `json:"id" validate:"required"`
+}
+
+func (req *MonitoringAlertIDRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate monitoring alert id request: %w", err)
+	}
+
+	return nil
+}
+
+// DispositionMonitoringAlertRequest closes an open alert as dismissed or escalated, the reviewer is the authenticated
+// client.
+type DispositionMonitoringAlertRequest struct {
+	ID         int64  `json:"-"      validate:"required"`
+	ReviewedBy string `json:"-"      validate:"required,max=100"`
+	Status     string `json:"status" validate:"required,oneof=dismissed escalated"`
+	Note       string `json:"note"   validate:"max=255"`
+}
+
+func (req *DispositionMonitoringAlertRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+	req.ReviewedBy = actorID(r)
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate monitoring alert disposition request: %w", err)
+	}
+
+	return nil
+}
+
+type MonitoringAlertResponse struct {
+	ID          int64           `json:"id"`
+	Detector    string          `json:"detector"`
+	AccountID   int64           `json:"account_id"`
+	Reason      string          `json:"reason"`
+	Amount      decimal.Decimal `json:"amount"`
+	EventIDs    []int64         `json:"event_ids"`
+	WindowStart time.Time       `json:"window_start"`
+	WindowEnd   time.Time       `json:"window_end"`
+	Status      string          `json:"status"`
+	Note        string          `json:"note,omitempty"`
+	ReviewedBy  *string         `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time      `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
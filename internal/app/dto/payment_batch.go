@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
+	"github.com/shopspring/decimal"
+)
+
+// maxPaymentFileSize is the size limit of a submitted payment file.
+const maxPaymentFileSize = 10 << 20
+
+// ErrInvalidPaymentFile is returned when the body is empty or above the size limit.
+var ErrInvalidPaymentFile = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_payment_file",
+		Message:   "file must be a pain.001 XML document of at most 10 MB",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+// SubmitPaymentBatchRequest is a pain.001 document sent as the request body.
+type SubmitPaymentBatchRequest struct {
+	Content []byte `json:"-"`
+}
+
+func (req *SubmitPaymentBatchRequest) Bind(r *http.Request) error {
+	content, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxPaymentFileSize))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPaymentFile, err)
+	}
+
+	if len(content) == 0 {
+		return ErrInvalidPaymentFile
+	}
+
+	req.Content = content
+
+	return nil
+}
+
+type PaymentBatchIDRequest struct {
+	ID int64 `json:"-"`
+}
+
+func (req *PaymentBatchIDRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	return nil
+}
+
+// PaymentStatusReportResponse is a pain.002 document.
+type PaymentStatusReportResponse struct {
+	Document []byte
+}
+
+// XMLDocument returns the document, the response is written as XML.
+func (resp PaymentStatusReportResponse) XMLDocument() []byte {
+	return resp.Document
+}
+
+type PaymentBatchResponse struct {
+	ID                   int64                    `json:"id"`
+	MessageID            string                   `json:"message_id"`
+	InitiatingParty      string                   `json:"initiating_party"`
+	NumberOfTransactions int                      `json:"number_of_transactions"`
+	ControlSum           *decimal.Decimal         `json:"control_sum,omitempty"`
+	Status               string                   `json:"status"`
+	ReasonCode           string                   `json:"reason_code,omitempty"`
+	Reason               string                   `json:"reason,omitempty"`
+	Summary              PaymentBatchSummary      `json:"summary"`
+	Instructions         []PaymentInstructionData `json:"instructions"`
+	CreatedAt            time.Time                `json:"created_at"`
+	UpdatedAt            time.Time                `json:"updated_at"`
+}
+
+type PaymentBatchSummary struct {
+	Pending         int `json:"pending"`
+	Accepted        int `json:"accepted"`
+	PendingApproval int `json:"pending_approval"`
+	Rejected        int `json:"rejected"`
+}
+
+// PaymentInstructionData is an instruction of a batch, the transaction id is the one of its transfer.
+type PaymentInstructionData struct {
+	Sequence             int             `json:"sequence"`
+	PaymentInformationID string          `json:"payment_information_id"`
+	InstructionID        string          `json:"instruction_id,omitempty"`
+	EndToEndID           string          `json:"end_to_end_id"`
+	TransactionID        string          `json:"transaction_id"`
+	SourceAccountID      int64           `json:"source_account_id"`
+	DestinationAccountID int64           `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Currency             string          `json:"currency"`
+	ExecuteAt            time.Time       `json:"execute_at"`
+	Status               string          `json:"status"`
+	ReasonCode           string          `json:"reason_code,omitempty"`
+	Reason               string          `json:"reason,omitempty"`
+}
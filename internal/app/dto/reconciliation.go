@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/pkg/bankstatement"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
+	"github.com/shopspring/decimal"
+)
+
+// maxStatementFileSize is the size limit of an uploaded bank statement.
+const maxStatementFileSize = 10 << 20
+
+// ErrInvalidStatementFile is returned when the upload has no file, a file above the size limit or an unknown format.
+var ErrInvalidStatementFile = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_statement_file",
+		Message:   "file must be a CSV, MT940 or CAMT.053 bank statement of at most 10 MB",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+type ImportReconciliationRequest struct {
+	FileName string               `json:"-"`
+	Format   bankstatement.Format `json:"-"`
+	Content  []byte               `json:"-"`
+}
+
+// Bind reads the file and the optional format of a multipart/form-data upload, the format is detected from the
+// file when it is not given.
+func (req *ImportReconciliationRequest) Bind(r *http.Request) error {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxStatementFileSize)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidStatementFile, err)
+	}
+
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidStatementFile, err)
+	}
+
+	req.FileName = header.Filename
+	req.Content = content
+	req.Format = bankstatement.DetectFormat(header.Filename, content)
+
+	if value := r.FormValue("format"); value != "" {
+		req.Format, err = bankstatement.ParseFormat(value)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidStatementFile, err)
+		}
+	}
+
+	return nil
+}
+
+type GetReconciliationRequest struct {
+	ID int64 `json:"-"`
+}
+
+func (req *GetReconciliationRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	return nil
+}
+
+type ReconciliationResponse struct {
+	ID            int64                    `json:"id"`
+	FileName      string                   `json:"file_name"`
+	Format        string                   `json:"format"`
+	StatementFrom string                   `json:"statement_from"`
+	StatementTo   string                   `json:"statement_to"`
+	Summary       ReconciliationSummary    `json:"summary"`
+	Items         []ReconciliationItemData `json:"items"`
+	CreatedAt     time.Time                `json:"created_at"`
+}
+
+type ReconciliationSummary struct {
+	Matched           int `json:"matched"`
+	UnmatchedInBank   int `json:"unmatched_in_bank"`
+	UnmatchedInLedger int `json:"unmatched_in_ledger"`
+}
+
+// ReconciliationItemData is a matched pair, a bank line without movement (unmatched_in_bank) or a movement
+// without bank line (unmatched_in_ledger).
+type ReconciliationItemData struct {
+	Status    string                `json:"status"`
+	MatchRule string                `json:"match_rule,omitempty"`
+	BankLine  *ReconciliationLine   `json:"bank_line"`
+	Movement  *ReconciliationLedger `json:"movement"`
+}
+
+// ReconciliationLine is a bank statement line, the amount is negative for money leaving the bank account.
+type ReconciliationLine struct {
+	BookingDate string          `json:"booking_date"`
+	Amount      decimal.Decimal `json:"amount"`
+	Reference   string          `json:"reference"`
+	Description string          `json:"description"`
+}
+
+// ReconciliationLedger is a deposit, or a withdrawal to the funding account with a negative amount.
+type ReconciliationLedger struct {
+	EventID       int64           `json:"event_id"`
+	TransactionID string          `json:"transaction_id"`
+	AccountID     int64           `json:"account_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
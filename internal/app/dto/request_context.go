@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
 )
@@ -47,12 +48,33 @@ func RequestWithContext(req *http.Request) (*http.Request, error) {
 	return req.WithContext(ctx), nil
 }
 
+// ContextWithRequestContext stores the request context in ctx. It is used by callers that do not
+// go through the HTTP transport, such as background workers.
+func ContextWithRequestContext(ctx context.Context, reqContext RequestContext) context.Context {
+	return context.WithValue(ctx, requestContextKey, reqContext)
+}
+
 func RequestFromContext(ctx context.Context) (RequestContext, bool) {
 	reqContext, ok := ctx.Value(requestContextKey).(RequestContext)
 
 	return reqContext, ok
 }
 
+// clientIDContextKey is the context.Context key to store the authenticated client.
+var clientIDContextKey = contextKey("client_id")
+
+// ContextWithClientID stores the client authenticated by the service token or the signature of the request in ctx.
+func ContextWithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDContextKey, clientID)
+}
+
+// ClientIDFromContext returns the authenticated client, it is empty when the request was not authenticated.
+func ClientIDFromContext(ctx context.Context) string {
+	clientID, _ := ctx.Value(clientIDContextKey).(string)
+
+	return clientID
+}
+
 func getLanguage(req *http.Request) string {
 	return req.Header.Get("Accept-Language")
 }
@@ -95,5 +117,17 @@ func getTransactionID(req *http.Request) (string, error) {
 		return "", err
 	}
 
+	// the transactions of the workers must not be claimed in advance by a client
+	if model.IsDerivedTransactionID(transactionID) {
+		err := exception.ApplicationError{
+			Localizable: lang.Localizable{
+				Message: "X-TRANSACTION-ID is reserved for the transactions of the application",
+			},
+			StatusCode: http.StatusBadRequest,
+		}
+
+		return "", err
+	}
+
 	return transactionID, nil
 }
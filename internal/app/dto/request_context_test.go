@@ -35,3 +35,33 @@ func TestRequestContext(t *testing.T) {
 	assert.Equal(t, timestamp, reqContext.Timestamp.Format(time.RFC3339))
 	assert.Equal(t, transactionID, reqContext.TransactionID)
 }
+
+func TestRequestContext_DerivedTransactionID(t *testing.T) {
+	for _, transactionID := range []string{"scheduled-transfer-12", "standing-order-5-3", "payment-batch-1-2"} {
+		req, err := http.NewRequestWithContext(context.Background(), "POST", "/transactions", nil)
+		assert.NoError(t, err)
+
+		req.Header.Add("X-TIMESTAMP", "2025-01-01T00:00:00Z")
+		req.Header.Add("X-TRANSACTION-ID", transactionID)
+
+		_, err = RequestWithContext(req)
+		assert.ErrorContains(t, err, "reserved", transactionID)
+	}
+}
+
+func TestClientIDFromContext(t *testing.T) {
+	req, err := http.NewRequestWithContext(ContextWithClientID(context.Background(), "payroll"), "POST",
+		"/transactions", nil)
+	assert.NoError(t, err)
+
+	req.Header.Add("X-TIMESTAMP", "2025-01-01T00:00:00Z")
+	req.Header.Add("X-TRANSACTION-ID", "1234567890")
+	req.Header.Add("X-Actor-Id", "alice")
+
+	out, err := RequestWithContext(req)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "payroll", ClientIDFromContext(out.Context()))
+	assert.Equal(t, "payroll", actorID(out))
+	assert.Empty(t, ClientIDFromContext(context.Background()))
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type CreateScheduledTransferRequest struct {
+	SourceAccountID      int64           `json:"source_account_id"      validate:"required"`
+	DestinationAccountID int64           `json:"destination_account_id" validate:"required"`
+	Amount               decimal.Decimal `json:"amount"                 validate:"required,decimal_gt_zero"`
+	ExecuteAt            time.Time       `json:"execute_at"             validate:"required"`
+}
+
+func (req *CreateScheduledTransferRequest) Bind(_ *http.Request) error {
+	err := validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate scheduled transfer create request: %w", err)
+	}
+
+	return nil
+}
+
+type ScheduledTransferIDRequest struct {
+	ID int64 `json:"id" validate:"required"`
+}
+
+func (req *ScheduledTransferIDRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate scheduled transfer id request: %w", err)
+	}
+
+	return nil
+}
+
+type ScheduledTransferResponse struct {
+	ID                     int64           `json:"id"`
+	SourceAccountID        int64           `json:"source_account_id"`
+	DestinationAccountID   int64           `json:"destination_account_id"`
+	Amount                 decimal.Decimal `json:"amount"`
+	ExecuteAt              time.Time       `json:"execute_at"`
+	Status                 string          `json:"status"`
+	ExecutionTransactionID string          `json:"execution_transaction_id"`
+	FailureReason          string          `json:"failure_reason,omitempty"`
+	ExecutedAt             *time.Time      `json:"executed_at,omitempty"`
+	CreatedAt              time.Time       `json:"created_at"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type CreateStandingOrderRequest struct {
+	SourceAccountID      int64           `json:"source_account_id"      validate:"required"`
+	DestinationAccountID int64           `json:"destination_account_id" validate:"required"`
+	Amount               decimal.Decimal `json:"amount"                 validate:"required,decimal_gt_zero"`
+	// Recurrence is an RRULE such as "FREQ=MONTHLY;BYMONTHDAY=1" or "FREQ=WEEKLY;BYDAY=FR".
+	Recurrence     string     `json:"recurrence"      validate:"required"`
+	StartAt        time.Time  `json:"start_at"        validate:"required"`
+	EndAt          *time.Time `json:"end_at"`
+	MaxOccurrences int        `json:"max_occurrences" validate:"gte=0"`
+}
+
+func (req *CreateStandingOrderRequest) Bind(_ *http.Request) error {
+	err := validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate standing order create request: %w", err)
+	}
+
+	return nil
+}
+
+type StandingOrderIDRequest struct {
+	ID int64 `json:"id" validate:"required"`
+}
+
+func (req *StandingOrderIDRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate standing order id request: %w", err)
+	}
+
+	return nil
+}
+
+type StandingOrderResponse struct {
+	ID                   int64           `json:"id"`
+	SourceAccountID      int64           `json:"source_account_id"`
+	DestinationAccountID int64           `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Recurrence           string          `json:"recurrence"`
+	StartAt              time.Time       `json:"start_at"`
+	EndAt                *time.Time      `json:"end_at,omitempty"`
+	MaxOccurrences       int             `json:"max_occurrences,omitempty"`
+	ExecutedCount        int             `json:"executed_count"`
+	NextRunAt            *time.Time      `json:"next_run_at,omitempty"`
+	Status               string          `json:"status"`
+	CreatedAt            time.Time       `json:"created_at"`
+}
@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidStatementPeriod is returned when from or to is missing, malformed or not in order.
+var ErrInvalidStatementPeriod = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_statement_period",
+		Message:   "from and to must be dates (YYYY-MM-DD) or RFC3339 timestamps and from must be before to",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+type StatementRequest struct {
+	ID int64 `json:"-"`
+	// From is inclusive and To is exclusive, a date covers the whole day (UTC).
+	From     time.Time `json:"-"`
+	To       time.Time `json:"-"`
+	Language string    `json:"-"`
+}
+
+func (req *StatementRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	from, _, err := parseStatementTime(r.URL.Query().Get("from"))
+	if err != nil {
+		return ErrInvalidStatementPeriod
+	}
+
+	to, isDate, err := parseStatementTime(r.URL.Query().Get("to"))
+	if err != nil {
+		return ErrInvalidStatementPeriod
+	}
+
+	// a date includes the whole day
+	if isDate {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) {
+		return ErrInvalidStatementPeriod
+	}
+
+	req.From = from
+	req.To = to
+	req.Language = getLanguage(r)
+
+	return nil
+}
+
+// parseStatementTime parses a date or an RFC3339 timestamp, it reports whether value is a date.
+func parseStatementTime(value string) (time.Time, bool, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, true, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, err //nolint:wrapcheck
+	}
+
+	return timestamp.UTC(), false, nil
+}
+
+type StatementResponse struct {
+	AccountID      int64               `json:"account_id"`
+	From           time.Time           `json:"from"`
+	To             time.Time           `json:"to"`
+	OpeningBalance decimal.Decimal     `json:"opening_balance"`
+	ClosingBalance decimal.Decimal     `json:"closing_balance"`
+	Movements      []StatementMovement `json:"movements"`
+}
+
+// StatementMovement is a balance change, the amount is negative for a debit.
+type StatementMovement struct {
+	TransactionID         string          `json:"transaction_id"`
+	Type                  string          `json:"type"`
+	Description           string          `json:"description"`
+	CounterpartyAccountID *int64          `json:"counterparty_account_id"`
+	Amount                decimal.Decimal `json:"amount"`
+	RunningBalance        decimal.Decimal `json:"running_balance"`
+	CreatedAt             time.Time       `json:"created_at"`
+}
+
+// CSVRecords returns the statement as CSV records, the opening and closing balances are the first and last rows.
+func (resp StatementResponse) CSVRecords() [][]string {
+	records := [][]string{
+		{"created_at", "transaction_id", "type", "description", "counterparty_account_id", "amount", "running_balance"},
+		{resp.From.Format(time.RFC3339), "", "opening_balance", "", "", "", resp.OpeningBalance.String()},
+	}
+
+	for _, movement := range resp.Movements {
+		counterparty := ""
+		if movement.CounterpartyAccountID != nil {
+			counterparty = strconv.FormatInt(*movement.CounterpartyAccountID, 10)
+		}
+
+		records = append(records, []string{
+			movement.CreatedAt.Format(time.RFC3339), movement.TransactionID, movement.Type, movement.Description,
+			counterparty, movement.Amount.String(), movement.RunningBalance.String(),
+		})
+	}
+
+	return append(records, []string{
+		resp.To.Format(time.RFC3339), "", "closing_balance", "", "", "", resp.ClosingBalance.String(),
+	})
+}
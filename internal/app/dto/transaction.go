@@ -1,9 +1,12 @@
 package dto
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/shopspring/decimal"
 )
 
@@ -11,9 +14,14 @@ type CreateTransferRequest struct {
 	SourceAccountID      int64           `json:"source_account_id"      validate:"required"`
 	DestinationAccountID int64           `json:"destination_account_id" validate:"required"`
 	Amount               decimal.Decimal `json:"amount"                 validate:"required,decimal_gt_zero"`
+	// InitiatedBy is the authenticated client requesting the transfer, it cannot approve the transfer when approval is
+	// required.
+	InitiatedBy string `json:"-" validate:"max=100"`
 }
 
-func (req *CreateTransferRequest) Bind(_ *http.Request) error {
+func (req *CreateTransferRequest) Bind(r *http.Request) error {
+	req.InitiatedBy = actorID(r)
+
 	err := validate.Struct(req)
 	if err != nil {
 		return fmt.Errorf("validate transaction create request: %w", err)
@@ -21,3 +29,76 @@ func (req *CreateTransferRequest) Bind(_ *http.Request) error {
 
 	return nil
 }
+
+// TransferResponse is the result of a transfer, the fee is charged on top of the amount.
+type TransferResponse struct {
+	TransactionID        string          `json:"transaction_id"`
+	SourceAccountID      int64           `json:"source_account_id"`
+	DestinationAccountID int64           `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Fee                  decimal.Decimal `json:"fee"`
+	FeeRule              string          `json:"fee_rule,omitempty"`
+	TotalDebited         decimal.Decimal `json:"total_debited"`
+	// Status is completed, or pending_approval when the transfer waits for a second person to approve it.
+	Status string `json:"status"`
+	// ExpiresAt is the time a pending transfer can be approved until.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ReviewTransferRequest approves or rejects a pending transfer, the reviewer is the authenticated client.
+type ReviewTransferRequest struct {
+	TransactionID string `json:"-"      validate:"required"`
+	ReviewedBy    string `json:"-"      validate:"required,max=100"`
+	Reason        string `json:"reason" validate:"max=255"`
+}
+
+func (req *ReviewTransferRequest) Bind(r *http.Request) error {
+	req.TransactionID = chi.URLParam(r, "transaction_id")
+	req.ReviewedBy = actorID(r)
+
+	err := validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate transfer review request: %w", err)
+	}
+
+	return nil
+}
+
+type GetTransactionRequest struct {
+	TransactionID string `json:"transaction_id" validate:"required"`
+}
+
+func (req *GetTransactionRequest) Bind(r *http.Request) error {
+	req.TransactionID = chi.URLParam(r, "transaction_id")
+
+	err := validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate transaction get request: %w", err)
+	}
+
+	return nil
+}
+
+// TransactionResponse is an operation reconstructed from the events recorded with its transaction id.
+type TransactionResponse struct {
+	TransactionID        string                     `json:"transaction_id"`
+	Operation            string                     `json:"operation"`
+	Status               string                     `json:"status"`
+	AccountIDs           []int64                    `json:"account_ids"`
+	SourceAccountID      *int64                     `json:"source_account_id,omitempty"`
+	DestinationAccountID *int64                     `json:"destination_account_id,omitempty"`
+	Amount               *decimal.Decimal           `json:"amount,omitempty"`
+	Fee                  *decimal.Decimal           `json:"fee,omitempty"`
+	Events               []TransactionEventResponse `json:"events"`
+	CreatedAt            time.Time                  `json:"created_at"`
+	CompletedAt          time.Time                  `json:"completed_at"`
+}
+
+type TransactionEventResponse struct {
+	AggregateType  string          `json:"aggregate_type"`
+	AggregateID    int64           `json:"aggregate_id"`
+	SequenceNumber int64           `json:"sequence_number"`
+	EventType      string          `json:"event_type"`
+	EventData      json.RawMessage `json:"event_data"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
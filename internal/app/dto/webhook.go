@@ -0,0 +1,168 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
+)
+
+type CreateWebhookRequest struct {
+	URL    string `json:"url"    validate:"required,http_url,max=2048"`
+	Secret string `json:"secret" validate:"required,min=16,max=255"`
+	// EventTypes and AccountIDs filter the events sent to the webhook, empty to receive all of them.
+	EventTypes []string `json:"event_types" validate:"dive,webhook_event_type"`
+	AccountIDs []int64  `json:"account_ids" validate:"dive,gt=0"`
+}
+
+func (req *CreateWebhookRequest) Bind(_ *http.Request) error {
+	err := validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate webhook create request: %w", err)
+	}
+
+	return nil
+}
+
+type ListWebhooksRequest struct {
+	Status string `json:"status" validate:"omitempty,oneof=active disabled"`
+}
+
+func (req *ListWebhooksRequest) Bind(r *http.Request) error {
+	req.Status = r.URL.Query().Get("status")
+
+	err := validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate list webhooks request: %w", err)
+	}
+
+	return nil
+}
+
+type WebhookIDRequest struct {
+	ID int64 `json:"id" validate:"required"`
+}
+
+func (req *WebhookIDRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate webhook id request: %w", err)
+	}
+
+	return nil
+}
+
+type ListWebhookDeliveriesRequest struct {
+	ID     int64  `json:"-"      validate:"required"`
+	Status string `json:"status" validate:"omitempty,oneof=pending succeeded failed"`
+	Limit  int    `json:"limit"  validate:"min=1,max=100"`
+	Cursor string `json:"cursor"`
+}
+
+func (req *ListWebhookDeliveriesRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	query := r.URL.Query()
+
+	req.ID = id
+	req.Status = query.Get("status")
+	req.Cursor = query.Get("cursor")
+	req.Limit = pagination.DefaultLimit
+
+	if limit := query.Get("limit"); limit != "" {
+		if req.Limit, err = strconv.Atoi(limit); err != nil {
+			return fmt.Errorf("invalid limit format: %w", err)
+		}
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate list webhook deliveries request: %w", err)
+	}
+
+	return nil
+}
+
+// RedeliverWebhookRequest requeues the deliveries of a webhook, the failed ones when DeliveryIDs is empty.
+type RedeliverWebhookRequest struct {
+	ID          int64   `json:"-"            validate:"required"`
+	DeliveryIDs []int64 `json:"delivery_ids" validate:"max=100,dive,gt=0"`
+}
+
+func (req *RedeliverWebhookRequest) Bind(r *http.Request) error {
+	id, err := int64URLParam(r, "id")
+	if err != nil {
+		return err
+	}
+
+	req.ID = id
+
+	err = validate.Struct(req)
+	if err != nil {
+		return fmt.Errorf("validate webhook redeliver request: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookResponse is a webhook without its secret.
+type WebhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	AccountIDs []int64   `json:"account_ids"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64                            `json:"id"`
+	EventID        int64                            `json:"event_id"`
+	EventType      string                           `json:"event_type"`
+	Payload        json.RawMessage                  `json:"payload"`
+	Status         string                           `json:"status"`
+	Attempts       int                              `json:"attempts"`
+	NextAttemptAt  *time.Time                       `json:"next_attempt_at,omitempty"`
+	LastStatusCode int                              `json:"last_status_code,omitempty"`
+	LastError      string                           `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time                       `json:"delivered_at,omitempty"`
+	AttemptLog     []WebhookDeliveryAttemptResponse `json:"attempt_log"`
+	CreatedAt      time.Time                        `json:"created_at"`
+}
+
+type WebhookDeliveryAttemptResponse struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RedeliverWebhookResponse struct {
+	Requeued int `json:"requeued"`
+}
+
+// WebhookEventPayload is the body of a callback, an event of an account.
+type WebhookEventPayload struct {
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	AccountID      int64           `json:"account_id"`
+	TransactionID  string          `json:"transaction_id"`
+	SequenceNumber int64           `json:"sequence_number"`
+	Data           json.RawMessage `json:"data"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
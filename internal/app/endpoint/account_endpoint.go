@@ -11,12 +11,25 @@ import (
 type AccountService interface {
 	CreateAccount(ctx context.Context, req dto.CreateAccountRequest) error
 	GetAccount(ctx context.Context, req dto.GetAccountRequest) (dto.AccountResponse, error)
+	ListAccounts(ctx context.Context, req dto.ListAccountsRequest) (dto.ListResponse[dto.AccountResponse], error)
+	FreezeAccount(ctx context.Context, req dto.AccountStatusRequest) error
+	UnfreezeAccount(ctx context.Context, req dto.AccountStatusRequest) error
+	CloseAccount(ctx context.Context, req dto.CloseAccountRequest) error
+	SetOverdraftLimit(ctx context.Context, req dto.SetOverdraftLimitRequest) error
+	UpdateAccountProfile(ctx context.Context, req dto.UpdateAccountProfileRequest) error
 }
 
 func NewAccountEndpoint(service AccountService) Account {
 	return Account{
-		Create: makeCreateAccountEndpoint(service),
-		Get:    makeGetAccountEndpoint(service),
+		Create:   makeCreateAccountEndpoint(service),
+		Get:      makeGetAccountEndpoint(service),
+		List:     makeListAccountsEndpoint(service),
+		Freeze:   makeFreezeAccountEndpoint(service),
+		Unfreeze: makeUnfreezeAccountEndpoint(service),
+		Close:    makeCloseAccountEndpoint(service),
+
+		SetOverdraftLimit: makeSetOverdraftLimitEndpoint(service),
+		UpdateProfile:     makeUpdateAccountProfileEndpoint(service),
 	}
 }
 
@@ -52,3 +65,101 @@ func makeGetAccountEndpoint(service AccountService) endpoint.Endpoint {
 		return account, nil
 	}
 }
+
+// makeFreezeAccountEndpoint is a helper function to freeze an account endpoint POST /admin/accounts/{id}/freeze.
+func makeFreezeAccountEndpoint(service AccountService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.AccountStatusRequest)
+		if !ok {
+			return nil, fmt.Errorf("account freeze request type: %w", ErrInvalidType)
+		}
+
+		if err := service.FreezeAccount(ctx, *req); err != nil {
+			return nil, fmt.Errorf("account service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+// makeUnfreezeAccountEndpoint is a helper function to unfreeze an account endpoint POST /admin/accounts/{id}/unfreeze.
+func makeUnfreezeAccountEndpoint(service AccountService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.AccountStatusRequest)
+		if !ok {
+			return nil, fmt.Errorf("account unfreeze request type: %w", ErrInvalidType)
+		}
+
+		if err := service.UnfreezeAccount(ctx, *req); err != nil {
+			return nil, fmt.Errorf("account service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+// makeCloseAccountEndpoint is a helper function to close an account endpoint POST /admin/accounts/{id}/close.
+func makeCloseAccountEndpoint(service AccountService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.CloseAccountRequest)
+		if !ok {
+			return nil, fmt.Errorf("account close request type: %w", ErrInvalidType)
+		}
+
+		if err := service.CloseAccount(ctx, *req); err != nil {
+			return nil, fmt.Errorf("account service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+// makeSetOverdraftLimitEndpoint is a helper function to set the overdraft limit endpoint
+// PUT /admin/accounts/{id}/overdraft-limit.
+func makeSetOverdraftLimitEndpoint(service AccountService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.SetOverdraftLimitRequest)
+		if !ok {
+			return nil, fmt.Errorf("set overdraft limit request type: %w", ErrInvalidType)
+		}
+
+		if err := service.SetOverdraftLimit(ctx, *req); err != nil {
+			return nil, fmt.Errorf("account service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+// makeListAccountsEndpoint is a helper function to create endpoint GET /accounts.
+func makeListAccountsEndpoint(service AccountService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ListAccountsRequest)
+		if !ok {
+			return nil, fmt.Errorf("account list request type: %w", ErrInvalidType)
+		}
+
+		accounts, err := service.ListAccounts(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("account service: %w", err)
+		}
+
+		return accounts, nil
+	}
+}
+
+// makeUpdateAccountProfileEndpoint is a helper function to create endpoint PATCH /accounts/{id}.
+func makeUpdateAccountProfileEndpoint(service AccountService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.UpdateAccountProfileRequest)
+		if !ok {
+			return nil, fmt.Errorf("account update profile request type: %w", ErrInvalidType)
+		}
+
+		if err := service.UpdateAccountProfile(ctx, *req); err != nil {
+			return nil, fmt.Errorf("account service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
@@ -15,15 +15,91 @@ var ErrInvalidType = exception.ApplicationError{
 }
 
 type Account struct {
-	Create endpoint.Endpoint
-	Get    endpoint.Endpoint
+	Create   endpoint.Endpoint
+	Get      endpoint.Endpoint
+	List     endpoint.Endpoint
+	Freeze   endpoint.Endpoint
+	Unfreeze endpoint.Endpoint
+	Close    endpoint.Endpoint
+
+	SetOverdraftLimit endpoint.Endpoint
+	UpdateProfile     endpoint.Endpoint
 }
 
 type Transaction struct {
 	Transfer endpoint.Endpoint
+	Get      endpoint.Endpoint
+	Approve  endpoint.Endpoint
+	Reject   endpoint.Endpoint
+}
+
+type ScheduledTransfer struct {
+	Create endpoint.Endpoint
+	Get    endpoint.Endpoint
+	Cancel endpoint.Endpoint
+}
+
+type StandingOrder struct {
+	Create endpoint.Endpoint
+	Get    endpoint.Endpoint
+	Cancel endpoint.Endpoint
+}
+
+type TransferLimit struct {
+	Get endpoint.Endpoint
+	Set endpoint.Endpoint
+}
+
+type Interest struct {
+	GetRate endpoint.Endpoint
+	SetRate endpoint.Endpoint
+}
+
+type Statement struct {
+	Get endpoint.Endpoint
+}
+
+type Ledger struct {
+	TrialBalance endpoint.Endpoint
+}
+
+type Reconciliation struct {
+	Import endpoint.Endpoint
+	Get    endpoint.Endpoint
+}
+
+type PaymentBatch struct {
+	Submit       endpoint.Endpoint
+	Get          endpoint.Endpoint
+	StatusReport endpoint.Endpoint
+}
+
+type Webhook struct {
+	Create     endpoint.Endpoint
+	Get        endpoint.Endpoint
+	List       endpoint.Endpoint
+	Delete     endpoint.Endpoint
+	Deliveries endpoint.Endpoint
+	Redeliver  endpoint.Endpoint
+}
+
+type Monitoring struct {
+	List        endpoint.Endpoint
+	Get         endpoint.Endpoint
+	Disposition endpoint.Endpoint
 }
 
 type Endpoint struct {
 	Account
 	Transaction
+	ScheduledTransfer
+	StandingOrder
+	TransferLimit
+	Interest
+	Statement
+	Ledger
+	Reconciliation
+	PaymentBatch
+	Webhook
+	Monitoring
 }
@@ -0,0 +1,54 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type InterestService interface {
+	GetInterestRate(ctx context.Context, req dto.GetAccountRequest) (dto.InterestRateResponse, error)
+	SetInterestRate(ctx context.Context, req dto.SetInterestRateRequest) error
+}
+
+func NewInterestEndpoint(service InterestService) Interest {
+	return Interest{
+		GetRate: makeGetInterestRateEndpoint(service),
+		SetRate: makeSetInterestRateEndpoint(service),
+	}
+}
+
+// makeGetInterestRateEndpoint is a helper function to create endpoint GET /admin/accounts/{id}/interest-rate.
+func makeGetInterestRateEndpoint(service InterestService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetAccountRequest)
+		if !ok {
+			return nil, fmt.Errorf("interest rate get request type: %w", ErrInvalidType)
+		}
+
+		rate, err := service.GetInterestRate(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("interest service: %w", err)
+		}
+
+		return rate, nil
+	}
+}
+
+// makeSetInterestRateEndpoint is a helper function to create endpoint PUT /admin/accounts/{id}/interest-rate.
+func makeSetInterestRateEndpoint(service InterestService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.SetInterestRateRequest)
+		if !ok {
+			return nil, fmt.Errorf("interest rate set request type: %w", ErrInvalidType)
+		}
+
+		if err := service.SetInterestRate(ctx, *req); err != nil {
+			return nil, fmt.Errorf("interest service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
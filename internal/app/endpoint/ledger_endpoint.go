@@ -0,0 +1,36 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type LedgerService interface {
+	GetTrialBalance(ctx context.Context, req dto.TrialBalanceRequest) (dto.TrialBalanceResponse, error)
+}
+
+func NewLedgerEndpoint(service LedgerService) Ledger {
+	return Ledger{
+		TrialBalance: makeTrialBalanceEndpoint(service),
+	}
+}
+
+// makeTrialBalanceEndpoint is a helper function to create endpoint GET /ledger/trial-balance.
+func makeTrialBalanceEndpoint(service LedgerService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.TrialBalanceRequest)
+		if !ok {
+			return nil, fmt.Errorf("trial balance request type: %w", ErrInvalidType)
+		}
+
+		trialBalance, err := service.GetTrialBalance(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("ledger service: %w", err)
+		}
+
+		return trialBalance, nil
+	}
+}
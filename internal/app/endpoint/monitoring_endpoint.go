@@ -0,0 +1,77 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type MonitoringService interface {
+	ListAlerts(ctx context.Context,
+		req dto.ListMonitoringAlertsRequest) (dto.ListResponse[dto.MonitoringAlertResponse], error)
+	GetAlert(ctx context.Context, req dto.MonitoringAlertIDRequest) (dto.MonitoringAlertResponse, error)
+	DispositionAlert(ctx context.Context,
+		req dto.DispositionMonitoringAlertRequest) (dto.MonitoringAlertResponse, error)
+}
+
+func NewMonitoringEndpoint(service MonitoringService) Monitoring {
+	return Monitoring{
+		List:        makeListMonitoringAlertsEndpoint(service),
+		Get:         makeGetMonitoringAlertEndpoint(service),
+		Disposition: makeDispositionMonitoringAlertEndpoint(service),
+	}
+}
+
+// makeListMonitoringAlertsEndpoint is a helper function to create endpoint GET /monitoring/alerts.
+func makeListMonitoringAlertsEndpoint(service MonitoringService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ListMonitoringAlertsRequest)
+		if !ok {
+			return nil, fmt.Errorf("monitoring alert list request type: %w", ErrInvalidType)
+		}
+
+		alerts, err := service.ListAlerts(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("monitoring service: %w", err)
+		}
+
+		return alerts, nil
+	}
+}
+
+// makeGetMonitoringAlertEndpoint is a helper function to create endpoint GET /monitoring/alerts/{id}.
+func makeGetMonitoringAlertEndpoint(service MonitoringService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.MonitoringAlertIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("monitoring alert get request type: %w", ErrInvalidType)
+		}
+
+		alert, err := service.GetAlert(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("monitoring service: %w", err)
+		}
+
+		return alert, nil
+	}
+}
+
+// makeDispositionMonitoringAlertEndpoint is a helper function to create endpoint
+// POST /monitoring/alerts/{id}/disposition.
+func makeDispositionMonitoringAlertEndpoint(service MonitoringService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.DispositionMonitoringAlertRequest)
+		if !ok {
+			return nil, fmt.Errorf("monitoring alert disposition request type: %w", ErrInvalidType)
+		}
+
+		alert, err := service.DispositionAlert(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("monitoring service: %w", err)
+		}
+
+		return alert, nil
+	}
+}
@@ -0,0 +1,74 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type PaymentBatchService interface {
+	SubmitPaymentBatch(ctx context.Context, req dto.SubmitPaymentBatchRequest) (dto.PaymentStatusReportResponse, error)
+	GetPaymentBatch(ctx context.Context, req dto.PaymentBatchIDRequest) (dto.PaymentBatchResponse, error)
+	GetPaymentStatusReport(ctx context.Context, req dto.PaymentBatchIDRequest) (dto.PaymentStatusReportResponse, error)
+}
+
+func NewPaymentBatchEndpoint(service PaymentBatchService) PaymentBatch {
+	return PaymentBatch{
+		Submit:       makeSubmitPaymentBatchEndpoint(service),
+		Get:          makeGetPaymentBatchEndpoint(service),
+		StatusReport: makeGetPaymentStatusReportEndpoint(service),
+	}
+}
+
+// makeSubmitPaymentBatchEndpoint is a helper function to create endpoint POST /payment-batches.
+func makeSubmitPaymentBatchEndpoint(service PaymentBatchService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.SubmitPaymentBatchRequest)
+		if !ok {
+			return nil, fmt.Errorf("payment batch submit request type: %w", ErrInvalidType)
+		}
+
+		report, err := service.SubmitPaymentBatch(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("payment batch service: %w", err)
+		}
+
+		return report, nil
+	}
+}
+
+// makeGetPaymentBatchEndpoint is a helper function to create endpoint GET /payment-batches/{id}.
+func makeGetPaymentBatchEndpoint(service PaymentBatchService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.PaymentBatchIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("payment batch get request type: %w", ErrInvalidType)
+		}
+
+		batch, err := service.GetPaymentBatch(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("payment batch service: %w", err)
+		}
+
+		return batch, nil
+	}
+}
+
+// makeGetPaymentStatusReportEndpoint is a helper function to create endpoint GET /payment-batches/{id}/status-report.
+func makeGetPaymentStatusReportEndpoint(service PaymentBatchService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.PaymentBatchIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("payment status report request type: %w", ErrInvalidType)
+		}
+
+		report, err := service.GetPaymentStatusReport(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("payment batch service: %w", err)
+		}
+
+		return report, nil
+	}
+}
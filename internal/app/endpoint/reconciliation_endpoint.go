@@ -0,0 +1,55 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type ReconciliationService interface {
+	ImportReconciliation(ctx context.Context, req dto.ImportReconciliationRequest) (dto.ReconciliationResponse, error)
+	GetReconciliation(ctx context.Context, req dto.GetReconciliationRequest) (dto.ReconciliationResponse, error)
+}
+
+func NewReconciliationEndpoint(service ReconciliationService) Reconciliation {
+	return Reconciliation{
+		Import: makeImportReconciliationEndpoint(service),
+		Get:    makeGetReconciliationEndpoint(service),
+	}
+}
+
+// makeImportReconciliationEndpoint is a helper function to create endpoint POST /reconciliations.
+func makeImportReconciliationEndpoint(service ReconciliationService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ImportReconciliationRequest)
+		if !ok {
+			return nil, fmt.Errorf("reconciliation import request type: %w", ErrInvalidType)
+		}
+
+		reconciliation, err := service.ImportReconciliation(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("reconciliation service: %w", err)
+		}
+
+		return reconciliation, nil
+	}
+}
+
+// makeGetReconciliationEndpoint is a helper function to create endpoint GET /reconciliations/{id}.
+func makeGetReconciliationEndpoint(service ReconciliationService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetReconciliationRequest)
+		if !ok {
+			return nil, fmt.Errorf("reconciliation get request type: %w", ErrInvalidType)
+		}
+
+		reconciliation, err := service.GetReconciliation(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("reconciliation service: %w", err)
+		}
+
+		return reconciliation, nil
+	}
+}
@@ -0,0 +1,74 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type ScheduledTransferService interface {
+	CreateScheduledTransfer(ctx context.Context,
+		req dto.CreateScheduledTransferRequest) (dto.ScheduledTransferResponse, error)
+	GetScheduledTransfer(ctx context.Context, req dto.ScheduledTransferIDRequest) (dto.ScheduledTransferResponse, error)
+	CancelScheduledTransfer(ctx context.Context, req dto.ScheduledTransferIDRequest) error
+}
+
+func NewScheduledTransferEndpoint(service ScheduledTransferService) ScheduledTransfer {
+	return ScheduledTransfer{
+		Create: makeCreateScheduledTransferEndpoint(service),
+		Get:    makeGetScheduledTransferEndpoint(service),
+		Cancel: makeCancelScheduledTransferEndpoint(service),
+	}
+}
+
+// makeCreateScheduledTransferEndpoint is a helper function to create endpoint POST /transactions/scheduled.
+func makeCreateScheduledTransferEndpoint(service ScheduledTransferService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.CreateScheduledTransferRequest)
+		if !ok {
+			return nil, fmt.Errorf("scheduled transfer create request type: %w", ErrInvalidType)
+		}
+
+		scheduledTransfer, err := service.CreateScheduledTransfer(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("scheduled transfer service: %w", err)
+		}
+
+		return scheduledTransfer, nil
+	}
+}
+
+// makeGetScheduledTransferEndpoint is a helper function to create endpoint GET /transactions/scheduled/{id}.
+func makeGetScheduledTransferEndpoint(service ScheduledTransferService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ScheduledTransferIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("scheduled transfer get request type: %w", ErrInvalidType)
+		}
+
+		scheduledTransfer, err := service.GetScheduledTransfer(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("scheduled transfer service: %w", err)
+		}
+
+		return scheduledTransfer, nil
+	}
+}
+
+// makeCancelScheduledTransferEndpoint is a helper function to create endpoint DELETE /transactions/scheduled/{id}.
+func makeCancelScheduledTransferEndpoint(service ScheduledTransferService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ScheduledTransferIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("scheduled transfer cancel request type: %w", ErrInvalidType)
+		}
+
+		if err := service.CancelScheduledTransfer(ctx, *req); err != nil {
+			return nil, fmt.Errorf("scheduled transfer service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
@@ -0,0 +1,73 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type StandingOrderService interface {
+	CreateStandingOrder(ctx context.Context, req dto.CreateStandingOrderRequest) (dto.StandingOrderResponse, error)
+	GetStandingOrder(ctx context.Context, req dto.StandingOrderIDRequest) (dto.StandingOrderResponse, error)
+	CancelStandingOrder(ctx context.Context, req dto.StandingOrderIDRequest) error
+}
+
+func NewStandingOrderEndpoint(service StandingOrderService) StandingOrder {
+	return StandingOrder{
+		Create: makeCreateStandingOrderEndpoint(service),
+		Get:    makeGetStandingOrderEndpoint(service),
+		Cancel: makeCancelStandingOrderEndpoint(service),
+	}
+}
+
+// makeCreateStandingOrderEndpoint is a helper function to create endpoint POST /transactions/standing-orders.
+func makeCreateStandingOrderEndpoint(service StandingOrderService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.CreateStandingOrderRequest)
+		if !ok {
+			return nil, fmt.Errorf("standing order create request type: %w", ErrInvalidType)
+		}
+
+		standingOrder, err := service.CreateStandingOrder(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("standing order service: %w", err)
+		}
+
+		return standingOrder, nil
+	}
+}
+
+// makeGetStandingOrderEndpoint is a helper function to create endpoint GET /transactions/standing-orders/{id}.
+func makeGetStandingOrderEndpoint(service StandingOrderService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.StandingOrderIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("standing order get request type: %w", ErrInvalidType)
+		}
+
+		standingOrder, err := service.GetStandingOrder(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("standing order service: %w", err)
+		}
+
+		return standingOrder, nil
+	}
+}
+
+// makeCancelStandingOrderEndpoint is a helper function to create endpoint DELETE /transactions/standing-orders/{id}.
+func makeCancelStandingOrderEndpoint(service StandingOrderService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.StandingOrderIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("standing order cancel request type: %w", ErrInvalidType)
+		}
+
+		if err := service.CancelStandingOrder(ctx, *req); err != nil {
+			return nil, fmt.Errorf("standing order service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
@@ -0,0 +1,36 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type StatementService interface {
+	GetStatement(ctx context.Context, req dto.StatementRequest) (dto.StatementResponse, error)
+}
+
+func NewStatementEndpoint(service StatementService) Statement {
+	return Statement{
+		Get: makeGetStatementEndpoint(service),
+	}
+}
+
+// makeGetStatementEndpoint is a helper function to create endpoint GET /accounts/{id}/statement.
+func makeGetStatementEndpoint(service StatementService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.StatementRequest)
+		if !ok {
+			return nil, fmt.Errorf("statement get request type: %w", ErrInvalidType)
+		}
+
+		statement, err := service.GetStatement(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("statement service: %w", err)
+		}
+
+		return statement, nil
+	}
+}
@@ -9,12 +9,18 @@ import (
 )
 
 type TransactionService interface {
-	Transfer(ctx context.Context, req dto.CreateTransferRequest) error
+	Transfer(ctx context.Context, req dto.CreateTransferRequest) (dto.TransferResponse, error)
+	GetTransaction(ctx context.Context, req dto.GetTransactionRequest) (dto.TransactionResponse, error)
+	ApproveTransfer(ctx context.Context, req dto.ReviewTransferRequest) (dto.TransferResponse, error)
+	RejectTransfer(ctx context.Context, req dto.ReviewTransferRequest) error
 }
 
 func NewTransactionEndpoint(service TransactionService) Transaction {
 	return Transaction{
 		Transfer: makeTransferEndpoint(service),
+		Get:      makeGetTransactionEndpoint(service),
+		Approve:  makeApproveTransferEndpoint(service),
+		Reject:   makeRejectTransferEndpoint(service),
 	}
 }
 
@@ -25,7 +31,58 @@ func makeTransferEndpoint(service TransactionService) endpoint.Endpoint {
 			return nil, fmt.Errorf("transaction transfer request type: %w", ErrInvalidType)
 		}
 
-		if err := service.Transfer(ctx, *req); err != nil {
+		resp, err := service.Transfer(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("transaction service: %w", err)
+		}
+
+		return resp, nil
+	}
+}
+
+// makeGetTransactionEndpoint is a helper function to create endpoint GET /transactions/{transaction_id}.
+func makeGetTransactionEndpoint(service TransactionService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetTransactionRequest)
+		if !ok {
+			return nil, fmt.Errorf("transaction get request type: %w", ErrInvalidType)
+		}
+
+		resp, err := service.GetTransaction(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("transaction service: %w", err)
+		}
+
+		return resp, nil
+	}
+}
+
+// makeApproveTransferEndpoint is a helper function to create endpoint POST /transactions/{transaction_id}/approve.
+func makeApproveTransferEndpoint(service TransactionService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ReviewTransferRequest)
+		if !ok {
+			return nil, fmt.Errorf("transaction approve request type: %w", ErrInvalidType)
+		}
+
+		resp, err := service.ApproveTransfer(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("transaction service: %w", err)
+		}
+
+		return resp, nil
+	}
+}
+
+// makeRejectTransferEndpoint is a helper function to create endpoint POST /transactions/{transaction_id}/reject.
+func makeRejectTransferEndpoint(service TransactionService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ReviewTransferRequest)
+		if !ok {
+			return nil, fmt.Errorf("transaction reject request type: %w", ErrInvalidType)
+		}
+
+		if err := service.RejectTransfer(ctx, *req); err != nil {
 			return nil, fmt.Errorf("transaction service: %w", err)
 		}
 
@@ -0,0 +1,54 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type TransferLimitService interface {
+	GetTransferLimits(ctx context.Context, req dto.GetAccountRequest) (dto.TransferLimitResponse, error)
+	SetTransferLimits(ctx context.Context, req dto.SetTransferLimitRequest) error
+}
+
+func NewTransferLimitEndpoint(service TransferLimitService) TransferLimit {
+	return TransferLimit{
+		Get: makeGetTransferLimitsEndpoint(service),
+		Set: makeSetTransferLimitsEndpoint(service),
+	}
+}
+
+// makeGetTransferLimitsEndpoint is a helper function to create endpoint GET /admin/accounts/{id}/limits.
+func makeGetTransferLimitsEndpoint(service TransferLimitService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.GetAccountRequest)
+		if !ok {
+			return nil, fmt.Errorf("transfer limit get request type: %w", ErrInvalidType)
+		}
+
+		limits, err := service.GetTransferLimits(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("transfer limit service: %w", err)
+		}
+
+		return limits, nil
+	}
+}
+
+// makeSetTransferLimitsEndpoint is a helper function to create endpoint PUT /admin/accounts/{id}/limits.
+func makeSetTransferLimitsEndpoint(service TransferLimitService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.SetTransferLimitRequest)
+		if !ok {
+			return nil, fmt.Errorf("transfer limit set request type: %w", ErrInvalidType)
+		}
+
+		if err := service.SetTransferLimits(ctx, *req); err != nil {
+			return nil, fmt.Errorf("transfer limit service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
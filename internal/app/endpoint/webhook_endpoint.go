@@ -0,0 +1,131 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req dto.CreateWebhookRequest) (dto.WebhookResponse, error)
+	GetWebhook(ctx context.Context, req dto.WebhookIDRequest) (dto.WebhookResponse, error)
+	ListWebhooks(ctx context.Context, req dto.ListWebhooksRequest) (dto.ListResponse[dto.WebhookResponse], error)
+	DeleteWebhook(ctx context.Context, req dto.WebhookIDRequest) error
+	ListDeliveries(ctx context.Context,
+		req dto.ListWebhookDeliveriesRequest) (dto.ListResponse[dto.WebhookDeliveryResponse], error)
+	Redeliver(ctx context.Context, req dto.RedeliverWebhookRequest) (dto.RedeliverWebhookResponse, error)
+}
+
+func NewWebhookEndpoint(service WebhookService) Webhook {
+	return Webhook{
+		Create:     makeCreateWebhookEndpoint(service),
+		Get:        makeGetWebhookEndpoint(service),
+		List:       makeListWebhooksEndpoint(service),
+		Delete:     makeDeleteWebhookEndpoint(service),
+		Deliveries: makeListWebhookDeliveriesEndpoint(service),
+		Redeliver:  makeRedeliverWebhookEndpoint(service),
+	}
+}
+
+// makeCreateWebhookEndpoint is a helper function to create endpoint POST /webhooks.
+func makeCreateWebhookEndpoint(service WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.CreateWebhookRequest)
+		if !ok {
+			return nil, fmt.Errorf("webhook create request type: %w", ErrInvalidType)
+		}
+
+		hook, err := service.CreateWebhook(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("webhook service: %w", err)
+		}
+
+		return hook, nil
+	}
+}
+
+// makeGetWebhookEndpoint is a helper function to create endpoint GET /webhooks/{id}.
+func makeGetWebhookEndpoint(service WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.WebhookIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("webhook get request type: %w", ErrInvalidType)
+		}
+
+		hook, err := service.GetWebhook(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("webhook service: %w", err)
+		}
+
+		return hook, nil
+	}
+}
+
+// makeListWebhooksEndpoint is a helper function to create endpoint GET /webhooks.
+func makeListWebhooksEndpoint(service WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ListWebhooksRequest)
+		if !ok {
+			return nil, fmt.Errorf("webhook list request type: %w", ErrInvalidType)
+		}
+
+		hooks, err := service.ListWebhooks(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("webhook service: %w", err)
+		}
+
+		return hooks, nil
+	}
+}
+
+// makeDeleteWebhookEndpoint is a helper function to create endpoint DELETE /webhooks/{id}.
+func makeDeleteWebhookEndpoint(service WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.WebhookIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("webhook delete request type: %w", ErrInvalidType)
+		}
+
+		if err := service.DeleteWebhook(ctx, *req); err != nil {
+			return nil, fmt.Errorf("webhook service: %w", err)
+		}
+
+		return nil, nil
+	}
+}
+
+// makeListWebhookDeliveriesEndpoint is a helper function to create endpoint GET /webhooks/{id}/deliveries.
+func makeListWebhookDeliveriesEndpoint(service WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.ListWebhookDeliveriesRequest)
+		if !ok {
+			return nil, fmt.Errorf("webhook deliveries request type: %w", ErrInvalidType)
+		}
+
+		deliveries, err := service.ListDeliveries(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("webhook service: %w", err)
+		}
+
+		return deliveries, nil
+	}
+}
+
+// makeRedeliverWebhookEndpoint is a helper function to create endpoint POST /webhooks/{id}/redeliver.
+func makeRedeliverWebhookEndpoint(service WebhookService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, ok := request.(*dto.RedeliverWebhookRequest)
+		if !ok {
+			return nil, fmt.Errorf("webhook redeliver request type: %w", ErrInvalidType)
+		}
+
+		resp, err := service.Redeliver(ctx, *req)
+		if err != nil {
+			return nil, fmt.Errorf("webhook service: %w", err)
+		}
+
+		return resp, nil
+	}
+}
@@ -1,14 +1,153 @@
 package model
 
 import (
+	"maps"
 	"time"
 
 	"github.com/shopspring/decimal"
 )
 
+type AccountType string
+
+const (
+	AccountTypePersonal AccountType = "personal"
+	AccountTypeBusiness AccountType = "business"
+	AccountTypeSavings  AccountType = "savings"
+	// AccountTypeSystem is an account operated by the bank itself, e.g. the fee revenue account.
+	AccountTypeSystem AccountType = "system"
+)
+
+// DefaultCurrency is the currency of an account opened without one, it matches the column default.
+const DefaultCurrency = "USD"
+
+type AccountStatus string
+
+const (
+	AccountStatusActive AccountStatus = "active"
+	AccountStatusFrozen AccountStatus = "frozen"
+	AccountStatusClosed AccountStatus = "closed"
+)
+
+// AccountStatusReason is the reason code recorded with a lifecycle change.
+type AccountStatusReason string
+
+const (
+	AccountStatusReasonCustomerRequest  AccountStatusReason = "customer_request"
+	AccountStatusReasonComplianceReview AccountStatusReason = "compliance_review"
+	AccountStatusReasonFraudSuspected   AccountStatusReason = "fraud_suspected"
+	AccountStatusReasonCourtOrder       AccountStatusReason = "court_order"
+	AccountStatusReasonReviewCleared    AccountStatusReason = "review_cleared"
+	AccountStatusReasonDormant          AccountStatusReason = "dormant"
+)
+
 type Account struct {
-	ID        int64           `json:"id"`
-	Balance   decimal.Decimal `json:"balance"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID       int64           `json:"id"`
+	Type     AccountType     `json:"type"`
+	Currency string          `json:"currency"`
+	Balance  decimal.Decimal `json:"balance"`
+	// DisplayName, OwnerReference and Labels are the profile of the account, they are informative only.
+	DisplayName    string            `json:"display_name"`
+	OwnerReference string            `json:"owner_reference"`
+	Labels         map[string]string `json:"labels"`
+	// OverdraftLimit is the approved credit line, the balance may go down to -OverdraftLimit.
+	OverdraftLimit decimal.Decimal     `json:"overdraft_limit"`
+	Status         AccountStatus       `json:"status"`
+	StatusReason   AccountStatusReason `json:"status_reason"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+func (a Account) IsFrozen() bool {
+	return a.Status == AccountStatusFrozen
+}
+
+func (a Account) IsClosed() bool {
+	return a.Status == AccountStatusClosed
+}
+
+// AvailableBalance is the amount that can be debited, including the unused overdraft.
+func (a Account) AvailableBalance() decimal.Decimal {
+	return decimal.Max(a.Balance.Add(a.OverdraftLimit), decimal.Zero)
+}
+
+// OverdraftUsed is the part of the overdraft currently drawn.
+func (a Account) OverdraftUsed() decimal.Decimal {
+	return decimal.Max(a.Balance.Neg(), decimal.Zero)
+}
+
+// OverdraftRemaining is the part of the overdraft that can still be drawn.
+func (a Account) OverdraftRemaining() decimal.Decimal {
+	return decimal.Max(a.OverdraftLimit.Sub(a.OverdraftUsed()), decimal.Zero)
+}
+
+// AccountProfileUpdate holds the profile fields to change, the nil fields are left unchanged.
+type AccountProfileUpdate struct {
+	DisplayName    *string
+	Type           *AccountType
+	OwnerReference *string
+	Labels         map[string]string
+}
+
+// UpdateProfile applies the update and returns the fields that changed with their new value.
+func (a *Account) UpdateProfile(update AccountProfileUpdate) map[string]interface{} {
+	changes := map[string]interface{}{}
+
+	if update.DisplayName != nil && *update.DisplayName != a.DisplayName {
+		a.DisplayName = *update.DisplayName
+		changes["display_name"] = a.DisplayName
+	}
+
+	if update.Type != nil && *update.Type != a.Type {
+		a.Type = *update.Type
+		changes["account_type"] = a.Type
+	}
+
+	if update.OwnerReference != nil && *update.OwnerReference != a.OwnerReference {
+		a.OwnerReference = *update.OwnerReference
+		changes["owner_reference"] = a.OwnerReference
+	}
+
+	if update.Labels != nil && !maps.Equal(update.Labels, a.Labels) {
+		a.Labels = update.Labels
+		changes["labels"] = a.Labels
+	}
+
+	return changes
+}
+
+type AccountSortField string
+
+const (
+	AccountSortFieldID        AccountSortField = "id"
+	AccountSortFieldBalance   AccountSortField = "balance"
+	AccountSortFieldCreatedAt AccountSortField = "created_at"
+)
+
+// AccountFilter selects a page of accounts, the nil and empty fields are not filtered on.
+type AccountFilter struct {
+	MinBalance  *decimal.Decimal
+	MaxBalance  *decimal.Decimal
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Status      AccountStatus
+	Currency    string
+	SortField   AccountSortField
+	Descending  bool
+	// AfterValue and AfterID are the keyset position of the previous page, AfterValue is the sort field value.
+	AfterValue *string
+	AfterID    *int64
+	Limit      int
+}
+
+// SortValue returns the value of the sort field, used as the keyset position of the next page. The id is
+// always part of the position, so it has no separate value.
+func (a Account) SortValue(field AccountSortField) string {
+	switch field {
+	case AccountSortFieldBalance:
+		return a.Balance.String()
+	case AccountSortFieldCreatedAt:
+		return a.CreatedAt.Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
 }
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AccountShard is a sub-balance of a hot account. The credits to a hot account are booked on one of its shards, so
+// they neither wait for each other on the account row nor share its event stream. The balance of the account is
+// its own balance plus the balance of its shards, the shards are consolidated into the account when it is debited.
+type AccountShard struct {
+	ID        int64           `json:"id"`
+	AccountID int64           `json:"account_id"`
+	ShardNo   int             `json:"shard_no"`
+	Balance   decimal.Decimal `json:"balance"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ShardsBalance returns the sum of the balance of the shards.
+func ShardsBalance(shards []AccountShard) decimal.Decimal {
+	balance := decimal.Zero
+
+	for _, shard := range shards {
+		balance = balance.Add(shard.Balance)
+	}
+
+	return balance
+}
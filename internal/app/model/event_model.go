@@ -7,7 +7,15 @@ import (
 type AggregateType string
 
 const (
-	AggregateTypeAccount AggregateType = "account"
+	AggregateTypeAccount       AggregateType = "account"
+	AggregateTypeStandingOrder AggregateType = "standing_order"
+	AggregateTypeTransfer      AggregateType = "transfer"
+	// AggregateTypeAccountShard is a sub-balance of a hot account, its events carry the id of the account.
+	AggregateTypeAccountShard AggregateType = "account_shard"
+	AggregateTypePaymentBatch AggregateType = "payment_batch"
+	AggregateTypeRiskDecision AggregateType = "risk_decision"
+	// AggregateTypeSanctionsScreening is a transfer whose parties matched the sanctions list.
+	AggregateTypeSanctionsScreening AggregateType = "sanctions_screening"
 )
 
 type EventType string
@@ -17,6 +25,45 @@ const (
 	EventTypeDepositReceived EventType = "deposit_received"
 	EventTypeDebitBalance    EventType = "balance_debited"
 	EventTypeCreditBalance   EventType = "balance_credited"
+	EventTypeAccountFrozen   EventType = "account_frozen"
+	EventTypeAccountUnfrozen EventType = "account_unfrozen"
+	EventTypeAccountClosed   EventType = "account_closed"
+
+	EventTypeShardsConsolidated EventType = "shards_consolidated"
+
+	EventTypeOverdraftLimitSet EventType = "overdraft_limit_set"
+
+	EventTypeAccountProfileUpdated EventType = "account_profile_updated"
+
+	EventTypeFeeCharged   EventType = "fee_charged"
+	EventTypeFeeCollected EventType = "fee_collected"
+
+	EventTypeInterestAccrued EventType = "interest_accrued"
+	EventTypeInterestPosted  EventType = "interest_posted"
+
+	EventTypeStandingOrderCreated  EventType = "standing_order_created"
+	EventTypeStandingOrderExecuted EventType = "standing_order_executed"
+	EventTypeStandingOrderSkipped  EventType = "standing_order_skipped"
+	// EventTypeStandingOrderAwaitingApproval is recorded when the transfer of an occurrence waits for a second
+	// person to approve it.
+	EventTypeStandingOrderAwaitingApproval EventType = "standing_order_awaiting_approval"
+	EventTypeStandingOrderCompleted        EventType = "standing_order_completed"
+	EventTypeStandingOrderCancelled        EventType = "standing_order_cancelled"
+
+	EventTypeTransferApprovalRequested EventType = "transfer_approval_requested"
+	EventTypeTransferApproved          EventType = "transfer_approved"
+	EventTypeTransferRejected          EventType = "transfer_rejected"
+	EventTypeTransferApprovalExpired   EventType = "transfer_approval_expired"
+
+	EventTypePaymentBatchReceived       EventType = "payment_batch_received"
+	EventTypePaymentBatchRejected       EventType = "payment_batch_rejected"
+	EventTypePaymentInstructionExecuted EventType = "payment_instruction_executed"
+	EventTypePaymentInstructionRejected EventType = "payment_instruction_rejected"
+	EventTypePaymentBatchCompleted      EventType = "payment_batch_completed"
+
+	EventTypeTransferRiskAssessed EventType = "transfer_risk_assessed"
+
+	EventTypeTransferSanctionsHit EventType = "transfer_sanctions_hit"
 )
 
 type Event struct {
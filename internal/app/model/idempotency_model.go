@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// IdempotencyKey is a transaction id claimed by the operation that used it first. The fingerprint and the response
+// of the request are recorded once it is answered, an identical retry is answered with the stored response.
+type IdempotencyKey struct {
+	TransactionID string
+	// Fingerprint is the SHA-256 hash of the method, the path and the body of the request.
+	Fingerprint  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
+
+// HasResponse reports whether the response of the request is recorded, a claimed key has none until the request
+// is answered.
+func (k IdempotencyKey) HasResponse() bool {
+	return k.StatusCode != 0
+}
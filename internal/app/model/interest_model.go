@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// InterestRates are the annual interest rates, in percent, of each account type.
+type InterestRates map[AccountType]decimal.Decimal
+
+// AccountInterestRate overrides the interest rate of the account type for one account.
+type AccountInterestRate struct {
+	AccountID int64           `json:"account_id"`
+	Rate      decimal.Decimal `json:"rate"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// InterestAccount is an account eligible for interest accrual.
+type InterestAccount struct {
+	AccountID       int64
+	Type            AccountType
+	CreatedAt       time.Time
+	RateOverride    *decimal.Decimal
+	LastAccrualDate *time.Time
+}
+
+// Rate returns the effective annual interest rate of the account.
+func (a InterestAccount) Rate(rates InterestRates) decimal.Decimal {
+	if a.RateOverride != nil {
+		return *a.RateOverride
+	}
+
+	return rates[a.Type]
+}
+
+// FirstAccrualDate returns the first date that has not been accrued yet.
+func (a InterestAccount) FirstAccrualDate() time.Time {
+	if a.LastAccrualDate != nil {
+		return a.LastAccrualDate.AddDate(0, 0, 1)
+	}
+
+	return AccrualDate(a.CreatedAt)
+}
+
+// InterestAccrual is the interest earned by an account on the end-of-day balance of a date.
+type InterestAccrual struct {
+	AccountID            int64
+	AccrualDate          time.Time
+	Balance              decimal.Decimal
+	Rate                 decimal.Decimal
+	Amount               decimal.Decimal
+	TransactionID        string
+	PostingTransactionID string
+	PostedAt             *time.Time
+	CreatedAt            time.Time
+}
+
+// NewInterestAccrual computes the daily interest of a balance, a negative balance does not earn interest.
+func NewInterestAccrual(accountID int64, accrualDate time.Time, balance decimal.Decimal,
+	rate decimal.Decimal, dayCount int,
+) InterestAccrual {
+	amount := decimal.Zero
+	if balance.IsPositive() {
+		amount = balance.Mul(rate).Div(hundred).Div(decimal.NewFromInt(int64(dayCount))).Round(5)
+	}
+
+	return InterestAccrual{
+		AccountID:     accountID,
+		AccrualDate:   accrualDate,
+		Balance:       balance,
+		Rate:          rate,
+		Amount:        amount,
+		TransactionID: AccrualTransactionID(accountID, accrualDate),
+		CreatedAt:     time.Now(),
+	}
+}
+
+// AccrualDate truncates t to its UTC date.
+func AccrualDate(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// AccrualTransactionID derives the transaction id of an accrual, so a date is never accrued twice.
+func AccrualTransactionID(accountID int64, accrualDate time.Time) string {
+	return fmt.Sprintf("interest-accrual-%d-%s", accountID, accrualDate.Format(time.DateOnly))
+}
+
+// PostingTransactionID derives the transaction id of the posting of the accruals before periodEnd.
+func PostingTransactionID(accountID int64, periodEnd time.Time) string {
+	return fmt.Sprintf("interest-posting-%d-%s", accountID, periodEnd.Format(time.DateOnly))
+}
+
+// InterestPostingPeriodEnd returns the start of the current posting period, accruals before it are posted.
+func InterestPostingPeriodEnd(now time.Time) time.Time {
+	year, month, _ := now.UTC().Date()
+
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrUnbalancedJournalEntry is returned when the debits of a journal entry do not equal its credits.
+var ErrUnbalancedJournalEntry = errors.New("unbalanced journal entry")
+
+type PostingDirection string
+
+const (
+	PostingDirectionDebit  PostingDirection = "debit"
+	PostingDirectionCredit PostingDirection = "credit"
+)
+
+// Posting is one side of a journal entry against an account, the amount is always positive.
+type Posting struct {
+	AccountID int64
+	Direction PostingDirection
+	Amount    decimal.Decimal
+}
+
+// JournalEntry records the balance movements of a transaction as postings whose debits equal their credits.
+type JournalEntry struct {
+	ID            int64
+	TransactionID string
+	Postings      []Posting
+	CreatedAt     time.Time
+}
+
+// NewJournalEntry builds the journal entry of the account events of a transaction. A debit event of an account is
+// a debit posting and a credit event is a credit posting, the events that do not move a balance are ignored.
+func NewJournalEntry(transactionID string, events []Event, createdAt time.Time) (JournalEntry, error) {
+	entry := JournalEntry{
+		TransactionID: transactionID,
+		CreatedAt:     createdAt,
+	}
+
+	for _, event := range events {
+		if !isAccountEvent(event) {
+			continue
+		}
+
+		var direction PostingDirection
+
+		switch {
+		case slices.Contains(DebitEventTypes(), event.EventType):
+			direction = PostingDirectionDebit
+		case slices.Contains(CreditEventTypes(), event.EventType):
+			direction = PostingDirectionCredit
+		default:
+			continue
+		}
+
+		var data transactionData
+
+		if err := unmarshalEventData(event.EventData, &data); err != nil {
+			return JournalEntry{}, fmt.Errorf("read %s event data: %w", event.EventType, err)
+		}
+
+		if data.Amount == nil || data.Amount.IsZero() {
+			continue
+		}
+
+		entry.Postings = append(entry.Postings, Posting{
+			AccountID: data.accountID(event),
+			Direction: direction,
+			Amount:    *data.Amount,
+		})
+	}
+
+	if !entry.IsBalanced() {
+		return JournalEntry{}, fmt.Errorf("%w: transaction %s debits %s and credits %s", ErrUnbalancedJournalEntry,
+			transactionID, entry.Total(PostingDirectionDebit), entry.Total(PostingDirectionCredit))
+	}
+
+	return entry, nil
+}
+
+// Total returns the sum of the postings in a direction.
+func (e JournalEntry) Total(direction PostingDirection) decimal.Decimal {
+	total := decimal.Zero
+
+	for _, posting := range e.Postings {
+		if posting.Direction == direction {
+			total = total.Add(posting.Amount)
+		}
+	}
+
+	return total
+}
+
+// IsBalanced reports whether the debits of the entry equal its credits.
+func (e JournalEntry) IsBalanced() bool {
+	return e.Total(PostingDirectionDebit).Equal(e.Total(PostingDirectionCredit))
+}
+
+// TrialBalanceAccount holds the sum of the postings of an account.
+type TrialBalanceAccount struct {
+	AccountID int64
+	Debits    decimal.Decimal
+	Credits   decimal.Decimal
+}
+
+// Balance returns the credits minus the debits, the same sign as the account balance.
+func (a TrialBalanceAccount) Balance() decimal.Decimal {
+	return a.Credits.Sub(a.Debits)
+}
+
+// TrialBalance lists the posted totals of every account, the ledger is balanced when the totals are equal.
+type TrialBalance struct {
+	Accounts     []TrialBalanceAccount
+	TotalDebits  decimal.Decimal
+	TotalCredits decimal.Decimal
+}
+
+func NewTrialBalance(accounts []TrialBalanceAccount) TrialBalance {
+	trialBalance := TrialBalance{
+		Accounts:     accounts,
+		TotalDebits:  decimal.Zero,
+		TotalCredits: decimal.Zero,
+	}
+
+	for _, account := range accounts {
+		trialBalance.TotalDebits = trialBalance.TotalDebits.Add(account.Debits)
+		trialBalance.TotalCredits = trialBalance.TotalCredits.Add(account.Credits)
+	}
+
+	return trialBalance
+}
+
+// Difference returns the total debits minus the total credits, zero for a balanced ledger.
+func (t TrialBalance) Difference() decimal.Decimal {
+	return t.TotalDebits.Sub(t.TotalCredits)
+}
+
+func (t TrialBalance) IsBalanced() bool {
+	return t.Difference().IsZero()
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type MonitoringAlertStatus string
+
+const (
+	MonitoringAlertStatusOpen MonitoringAlertStatus = "open"
+	// MonitoringAlertStatusDismissed is an alert reviewed as a false positive.
+	MonitoringAlertStatusDismissed MonitoringAlertStatus = "dismissed"
+	// MonitoringAlertStatusEscalated is an alert reviewed as suspicious, handed over for investigation or reporting.
+	MonitoringAlertStatusEscalated MonitoringAlertStatus = "escalated"
+)
+
+// MonitoringAlert is a suspicious pattern found by a detector in the movements of an account, EventIDs are the
+// balance events supporting it. It stays open until compliance reviews it.
+type MonitoringAlert struct {
+	ID          int64                 `json:"id"`
+	Detector    string                `json:"detector"`
+	AccountID   int64                 `json:"account_id"`
+	Reason      string                `json:"reason"`
+	Amount      decimal.Decimal       `json:"amount"`
+	EventIDs    []int64               `json:"event_ids"`
+	WindowStart time.Time             `json:"window_start"`
+	WindowEnd   time.Time             `json:"window_end"`
+	Status      MonitoringAlertStatus `json:"status"`
+	Note        string                `json:"note"`
+	ReviewedBy  *string               `json:"reviewed_by"`
+	ReviewedAt  *time.Time            `json:"reviewed_at"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// Covers reports whether the alert already reports a pattern of the same detector on the account built on any of
+// the movements, the scans look at overlapping periods and find the same pattern again.
+func (a MonitoringAlert) Covers(detector string, accountID int64, eventIDs []int64) bool {
+	if a.Detector != detector || a.AccountID != accountID {
+		return false
+	}
+
+	return slices.ContainsFunc(eventIDs, func(eventID int64) bool {
+		return slices.Contains(a.EventIDs, eventID)
+	})
+}
+
+// MonitoringAlertFilter selects a page of the alerts, newest first. Empty fields match every alert.
+type MonitoringAlertFilter struct {
+	Status    MonitoringAlertStatus
+	Detector  string
+	AccountID int64
+	// BeforeID is the id of the last alert of the previous page, zero for the first page.
+	BeforeID int64
+	Limit    int
+}
+
+// AccountMovement is a balance_debited or balance_credited event of an account, or of one of its shards.
+// CounterpartyAccountID is the account on the other side of the transfer.
+type AccountMovement struct {
+	EventID               int64           `json:"event_id"`
+	EventType             EventType       `json:"event_type"`
+	AccountID             int64           `json:"account_id"`
+	CounterpartyAccountID int64           `json:"counterparty_account_id"`
+	Amount                decimal.Decimal `json:"amount"`
+	CreatedAt             time.Time       `json:"created_at"`
+}
+
+// NewAccountMovement builds the movement of a balance_debited or balance_credited event.
+func NewAccountMovement(event Event) (AccountMovement, error) {
+	var data struct {
+		AccountID            int64           `json:"account_id"`
+		SourceAccountID      int64           `json:"source_account_id"`
+		DestinationAccountID int64           `json:"destination_account_id"`
+		Amount               decimal.Decimal `json:"amount"`
+	}
+
+	if err := unmarshalEventData(event.EventData, &data); err != nil {
+		return AccountMovement{}, fmt.Errorf("read %s event data: %w", event.EventType, err)
+	}
+
+	accountID := event.AggregateID
+	if event.AggregateType == AggregateTypeAccountShard {
+		accountID = data.AccountID
+	}
+
+	counterpartyAccountID := data.SourceAccountID
+	if event.EventType == EventTypeDebitBalance {
+		counterpartyAccountID = data.DestinationAccountID
+	}
+
+	return AccountMovement{
+		EventID:               event.ID,
+		EventType:             event.EventType,
+		AccountID:             accountID,
+		CounterpartyAccountID: counterpartyAccountID,
+		Amount:                data.Amount,
+		CreatedAt:             event.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PaymentBatchStatus string
+
+const (
+	// PaymentBatchStatusProcessing is a batch with instructions left to execute.
+	PaymentBatchStatusProcessing PaymentBatchStatus = "processing"
+	PaymentBatchStatusCompleted  PaymentBatchStatus = "completed"
+	// PaymentBatchStatusRejected is a batch rejected as a whole, none of its instructions is executed.
+	PaymentBatchStatusRejected PaymentBatchStatus = "rejected"
+)
+
+type PaymentInstructionStatus string
+
+const (
+	// PaymentInstructionStatusPending is an instruction waiting for its requested execution date.
+	PaymentInstructionStatusPending  PaymentInstructionStatus = "pending"
+	PaymentInstructionStatusAccepted PaymentInstructionStatus = "accepted"
+	// PaymentInstructionStatusPendingApproval is an instruction whose transfer waits for a second person to
+	// approve it.
+	PaymentInstructionStatusPendingApproval PaymentInstructionStatus = "pending_approval"
+	PaymentInstructionStatusRejected        PaymentInstructionStatus = "rejected"
+)
+
+// PaymentBatch is the projection of the payment batch aggregate, a pain.001 file submitted by a client.
+type PaymentBatch struct {
+	ID            int64  `json:"id"`
+	TransactionID string `json:"transaction_id"`
+	// MessageID is the message id of the file, a client cannot submit it twice.
+	MessageID            string               `json:"message_id"`
+	InitiatingParty      string               `json:"initiating_party"`
+	NumberOfTransactions int                  `json:"number_of_transactions"`
+	ControlSum           decimal.NullDecimal  `json:"control_sum"`
+	Status               PaymentBatchStatus   `json:"status"`
+	ReasonCode           string               `json:"reason_code"`
+	Reason               string               `json:"reason"`
+	Instructions         []PaymentInstruction `json:"instructions"`
+	CreatedAt            time.Time            `json:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at"`
+}
+
+// Count returns the number of instructions with the status.
+func (b PaymentBatch) Count(status PaymentInstructionStatus) int {
+	count := 0
+
+	for _, instruction := range b.Instructions {
+		if instruction.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// PaymentInstruction is a credit transfer transaction of a batch, numbered from 1 in file order.
+type PaymentInstruction struct {
+	ID                   int64  `json:"id"`
+	BatchID              int64  `json:"batch_id"`
+	Sequence             int    `json:"sequence"`
+	PaymentInformationID string `json:"payment_information_id"`
+	InstructionID        string `json:"instruction_id"`
+	EndToEndID           string `json:"end_to_end_id"`
+	// SourceAccountID and DestinationAccountID are zero when the file does not identify an account of the ledger.
+	SourceAccountID      int64                    `json:"source_account_id"`
+	DestinationAccountID int64                    `json:"destination_account_id"`
+	Amount               decimal.Decimal          `json:"amount"`
+	Currency             string                   `json:"currency"`
+	ExecuteAt            time.Time                `json:"execute_at"`
+	Status               PaymentInstructionStatus `json:"status"`
+	ReasonCode           string                   `json:"reason_code"`
+	Reason               string                   `json:"reason"`
+	CreatedAt            time.Time                `json:"created_at"`
+	UpdatedAt            time.Time                `json:"updated_at"`
+}
+
+// TransferTransactionID is the transaction id of the transfer of the instruction. Executing the instruction again
+// reuses it, so an instruction is never transferred twice.
+func (i PaymentInstruction) TransferTransactionID() string {
+	return fmt.Sprintf("payment-batch-%d-%d", i.BatchID, i.Sequence)
+}
+
+// Reject marks the instruction rejected for the reason.
+func (i *PaymentInstruction) Reject(code string, reason string) {
+	i.Status = PaymentInstructionStatusRejected
+	i.ReasonCode = code
+	i.Reason = reason
+}
@@ -0,0 +1,183 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ReconciliationItemStatus string
+
+const (
+	ReconciliationItemMatched           ReconciliationItemStatus = "matched"
+	ReconciliationItemUnmatchedInBank   ReconciliationItemStatus = "unmatched_in_bank"
+	ReconciliationItemUnmatchedInLedger ReconciliationItemStatus = "unmatched_in_ledger"
+)
+
+type ReconciliationMatchRule string
+
+const (
+	// ReconciliationMatchReference matches the reference of the bank line to the transaction id, with the same
+	// amount and a date within the tolerance.
+	ReconciliationMatchReference ReconciliationMatchRule = "reference"
+	// ReconciliationMatchAmountDate matches a line without a matching reference to the movement of the same
+	// amount closest to its date, within the tolerance.
+	ReconciliationMatchAmountDate ReconciliationMatchRule = "amount_date"
+)
+
+// Reconciliation is the result of matching a bank statement against the ledger, it is stored for review.
+type Reconciliation struct {
+	ID       int64  `json:"id"`
+	FileName string `json:"file_name"`
+	Format   string `json:"format"`
+	// StatementFrom and StatementTo are the first and last booking dates of the statement.
+	StatementFrom time.Time            `json:"statement_from"`
+	StatementTo   time.Time            `json:"statement_to"`
+	Items         []ReconciliationItem `json:"items"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
+
+// Count returns the number of items with the status.
+func (r Reconciliation) Count(status ReconciliationItemStatus) int {
+	count := 0
+
+	for _, item := range r.Items {
+		if item.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// ReconciliationItem is a matched pair, or a bank line or a ledger movement left unmatched.
+type ReconciliationItem struct {
+	ID        int64                    `json:"id"`
+	Status    ReconciliationItemStatus `json:"status"`
+	MatchRule ReconciliationMatchRule  `json:"match_rule"`
+	BankLine  *BankLine                `json:"bank_line"`
+	Movement  *BankMovement            `json:"movement"`
+}
+
+// BankLine is a booked line of the statement of the bank account holding the customer funds.
+type BankLine struct {
+	BookingDate time.Time `json:"booking_date"`
+	// Amount is negative for money leaving the bank account.
+	Amount      decimal.Decimal `json:"amount"`
+	Reference   string          `json:"reference"`
+	Description string          `json:"description"`
+}
+
+// BankMovement is a ledger movement expected on the bank statement: a deposit into an account, or a withdrawal,
+// a transfer from an account to the funding account.
+type BankMovement struct {
+	EventID       int64  `json:"event_id"`
+	TransactionID string `json:"transaction_id"`
+	AccountID     int64  `json:"account_id"`
+	// Amount is negative for a withdrawal.
+	Amount    decimal.Decimal `json:"amount"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewBankMovement builds the bank movement of a deposit_received or balance_debited account event.
+func NewBankMovement(event Event) (BankMovement, error) {
+	var data struct {
+		Amount decimal.Decimal `json:"amount"`
+	}
+
+	if err := unmarshalEventData(event.EventData, &data); err != nil {
+		return BankMovement{}, fmt.Errorf("read %s event data: %w", event.EventType, err)
+	}
+
+	amount := data.Amount
+	if event.EventType == EventTypeDebitBalance {
+		amount = amount.Neg()
+	}
+
+	return BankMovement{
+		EventID:       event.ID,
+		TransactionID: event.TransactionID,
+		AccountID:     event.AggregateID,
+		Amount:        amount,
+		CreatedAt:     event.CreatedAt,
+	}, nil
+}
+
+// Reconcile matches the bank lines to the movements, each movement is matched once. The lines are first matched
+// by reference, then by amount and date. The movements left unmatched are reported when they were recorded within
+// the statement period [from, to), the others are expected on the statements of the neighbouring periods.
+func Reconcile(lines []BankLine, movements []BankMovement, from, to time.Time,
+	tolerance time.Duration,
+) []ReconciliationItem {
+	items := make([]ReconciliationItem, len(lines))
+	matched := make([]bool, len(movements))
+
+	for i, line := range lines {
+		items[i] = ReconciliationItem{Status: ReconciliationItemUnmatchedInBank, BankLine: &lines[i]}
+
+		for j, movement := range movements {
+			if !matched[j] && line.Reference != "" && line.Reference == movement.TransactionID &&
+				line.Amount.Equal(movement.Amount) && dateDistance(line, movement) <= tolerance {
+				items[i] = matchedItem(line, movement, ReconciliationMatchReference)
+				matched[j] = true
+
+				break
+			}
+		}
+	}
+
+	for i, line := range lines {
+		if items[i].Status == ReconciliationItemMatched {
+			continue
+		}
+
+		closest := -1
+
+		for j, movement := range movements {
+			if matched[j] || !line.Amount.Equal(movement.Amount) || dateDistance(line, movement) > tolerance {
+				continue
+			}
+
+			if closest < 0 || dateDistance(line, movement) < dateDistance(line, movements[closest]) {
+				closest = j
+			}
+		}
+
+		if closest >= 0 {
+			items[i] = matchedItem(line, movements[closest], ReconciliationMatchAmountDate)
+			matched[closest] = true
+		}
+	}
+
+	for j, movement := range movements {
+		if !matched[j] && !movement.CreatedAt.Before(from) && movement.CreatedAt.Before(to) {
+			items = append(items, ReconciliationItem{
+				Status:   ReconciliationItemUnmatchedInLedger,
+				Movement: &movements[j],
+			})
+		}
+	}
+
+	return items
+}
+
+func matchedItem(line BankLine, movement BankMovement, rule ReconciliationMatchRule) ReconciliationItem {
+	return ReconciliationItem{
+		Status:    ReconciliationItemMatched,
+		MatchRule: rule,
+		BankLine:  &line,
+		Movement:  &movement,
+	}
+}
+
+// dateDistance is the number of days between the booking date of the line and the date (UTC) the movement was
+// recorded on.
+func dateDistance(line BankLine, movement BankMovement) time.Duration {
+	distance := movement.CreatedAt.UTC().Truncate(24 * time.Hour).Sub(line.BookingDate)
+	if distance < 0 {
+		return -distance
+	}
+
+	return distance
+}
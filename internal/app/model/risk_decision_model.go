@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type RiskAction string
+
+const (
+	RiskActionAllow  RiskAction = "allow"
+	RiskActionDeny   RiskAction = "deny"
+	RiskActionReview RiskAction = "review"
+)
+
+// RiskDecision is the projection of the risk decision aggregate, the outcome of the risk rules on a transfer. Rule
+// is empty when no rule matched the transfer.
+type RiskDecision struct {
+	ID                   int64           `json:"id"`
+	TransactionID        string          `json:"transaction_id"`
+	SourceAccountID      int64           `json:"source_account_id"`
+	DestinationAccountID int64           `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Action               RiskAction      `json:"action"`
+	Rule                 string          `json:"rule"`
+	CreatedAt            time.Time       `json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type SanctionsAction string
+
+const (
+	// SanctionsActionBlock rejects a transfer whose party is very likely listed.
+	SanctionsActionBlock SanctionsAction = "block"
+	// SanctionsActionHold holds a transfer whose party may be listed until another person approves it.
+	SanctionsActionHold SanctionsAction = "hold"
+)
+
+// SanctionsList is a version of the sanctions list loaded by the application.
+type SanctionsList struct {
+	ID       int64     `json:"id"`
+	Version  string    `json:"version"`
+	Checksum string    `json:"checksum"`
+	Format   string    `json:"format"`
+	Source   string    `json:"source"`
+	Entries  int       `json:"entries"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// SanctionsHit is the name of a party of a transfer that matched an entry of the sanctions list, or a party without
+// a name, which could not be screened and has no entry.
+type SanctionsHit struct {
+	AccountID   int64   `json:"account_id"`
+	AccountName string  `json:"account_name"`
+	EntryUID    string  `json:"entry_uid"`
+	EntryName   string  `json:"entry_name"`
+	Program     string  `json:"program"`
+	Score       float64 `json:"score"`
+}
+
+// SanctionsScreening is the projection of the sanctions screening aggregate, a transfer whose parties matched the
+// sanctions list and the action taken on it.
+type SanctionsScreening struct {
+	ID                   int64           `json:"id"`
+	TransactionID        string          `json:"transaction_id"`
+	SourceAccountID      int64           `json:"source_account_id"`
+	DestinationAccountID int64           `json:"destination_account_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	Action               SanctionsAction `json:"action"`
+	ListVersion          string          `json:"list_version"`
+	Hits                 []SanctionsHit  `json:"hits"`
+	CreatedAt            time.Time       `json:"created_at"`
+}
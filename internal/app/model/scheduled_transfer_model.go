@@ -0,0 +1,41 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type ScheduledTransferStatus string
+
+const (
+	ScheduledTransferStatusPending    ScheduledTransferStatus = "pending"
+	ScheduledTransferStatusProcessing ScheduledTransferStatus = "processing"
+	// ScheduledTransferStatusAwaitingApproval is an instruction whose transfer waits for a second person to
+	// approve it, it is executed once approved and failed once rejected or expired.
+	ScheduledTransferStatusAwaitingApproval ScheduledTransferStatus = "awaiting_approval"
+	ScheduledTransferStatusExecuted         ScheduledTransferStatus = "executed"
+	ScheduledTransferStatusFailed           ScheduledTransferStatus = "failed"
+	ScheduledTransferStatusCancelled        ScheduledTransferStatus = "cancelled"
+)
+
+type ScheduledTransfer struct {
+	ID                   int64                   `json:"id"`
+	TransactionID        string                  `json:"transaction_id"`
+	SourceAccountID      int64                   `json:"source_account_id"`
+	DestinationAccountID int64                   `json:"destination_account_id"`
+	Amount               decimal.Decimal         `json:"amount"`
+	ExecuteAt            time.Time               `json:"execute_at"`
+	Status               ScheduledTransferStatus `json:"status"`
+	FailureReason        string                  `json:"failure_reason"`
+	ExecutedAt           *time.Time              `json:"executed_at"`
+	CreatedAt            time.Time               `json:"created_at"`
+	UpdatedAt            time.Time               `json:"updated_at"`
+}
+
+// ExecutionTransactionID is the transaction id used when the scheduler executes the transfer.
+// It is derived from the instruction id so that re-executing after a restart hits the idempotency check.
+func (s ScheduledTransfer) ExecutionTransactionID() string {
+	return fmt.Sprintf("scheduled-transfer-%d", s.ID)
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type StandingOrderStatus string
+
+const (
+	StandingOrderStatusActive StandingOrderStatus = "active"
+	// StandingOrderStatusAwaitingApproval is a standing order whose current occurrence waits for a second person to
+	// approve its transfer, the order moves on to its next occurrence once the transfer is reviewed.
+	StandingOrderStatusAwaitingApproval StandingOrderStatus = "awaiting_approval"
+	StandingOrderStatusCompleted        StandingOrderStatus = "completed"
+	StandingOrderStatusCancelled        StandingOrderStatus = "cancelled"
+)
+
+// StandingOrder is the projection of the standing order aggregate.
+type StandingOrder struct {
+	ID                   int64               `json:"id"`
+	TransactionID        string              `json:"transaction_id"`
+	SourceAccountID      int64               `json:"source_account_id"`
+	DestinationAccountID int64               `json:"destination_account_id"`
+	Amount               decimal.Decimal     `json:"amount"`
+	Recurrence           string              `json:"recurrence"`
+	StartAt              time.Time           `json:"start_at"`
+	EndAt                *time.Time          `json:"end_at"`
+	MaxOccurrences       int                 `json:"max_occurrences"`
+	Occurrence           int                 `json:"occurrence"`
+	ExecutedCount        int                 `json:"executed_count"`
+	Attempt              int                 `json:"attempt"`
+	OccurrenceAt         time.Time           `json:"occurrence_at"`
+	NextRunAt            time.Time           `json:"next_run_at"`
+	Status               StandingOrderStatus `json:"status"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
+
+// OccurrenceTransactionID is the transaction id used to execute the current occurrence.
+// Retries of the same occurrence reuse it, so an occurrence is never executed twice.
+func (s StandingOrder) OccurrenceTransactionID() string {
+	return fmt.Sprintf("standing-order-%d-%d", s.ID, s.Occurrence)
+}
+
+// AttemptTransactionID is the transaction id of the events recorded for a skipped attempt. It differs
+// from OccurrenceTransactionID so that recording a skip does not block retrying the occurrence.
+func (s StandingOrder) AttemptTransactionID() string {
+	return fmt.Sprintf("%s-attempt-%d", s.OccurrenceTransactionID(), s.Attempt+1)
+}
+
+// HasEnded reports whether an occurrence at the given time is beyond the end date or the maximum count.
+func (s StandingOrder) HasEnded(occurrenceAt time.Time) bool {
+	if occurrenceAt.IsZero() {
+		return true
+	}
+
+	if s.EndAt != nil && occurrenceAt.After(*s.EndAt) {
+		return true
+	}
+
+	return s.MaxOccurrences > 0 && s.Occurrence > s.MaxOccurrences
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreditEventTypes are the account events increasing the balance. The initial balance is recorded by
+// deposit_received, init_balance carries the same amount and is not a movement.
+func CreditEventTypes() []EventType {
+	return []EventType{EventTypeDepositReceived, EventTypeCreditBalance, EventTypeFeeCollected, EventTypeInterestPosted}
+}
+
+// DebitEventTypes are the account events decreasing the balance.
+func DebitEventTypes() []EventType {
+	return []EventType{EventTypeDebitBalance, EventTypeFeeCharged}
+}
+
+// Movement is a balance change of an account recorded by one of its events.
+type Movement struct {
+	TransactionID  string
+	SequenceNumber int64
+	EventType      EventType
+	// Amount is negative for a debit.
+	Amount                decimal.Decimal
+	CounterpartyAccountID *int64
+	// Source is the external origin of a deposit.
+	Source    string
+	CreatedAt time.Time
+}
+
+type movementData struct {
+	Amount               decimal.Decimal `json:"amount"`
+	SourceAccountID      *int64          `json:"source_account_id"`
+	DestinationAccountID *int64          `json:"destination_account_id"`
+	RevenueAccountID     *int64          `json:"revenue_account_id"`
+	Source               string          `json:"source"`
+}
+
+// NewMovement builds the movement of a balance event from its event data.
+func NewMovement(transactionID string, sequenceNumber int64, eventType EventType, eventData []byte,
+	createdAt time.Time,
+) (Movement, error) {
+	var data movementData
+
+	if err := json.Unmarshal(eventData, &data); err != nil {
+		return Movement{}, fmt.Errorf("unmarshal event data: %w", err)
+	}
+
+	movement := Movement{
+		TransactionID:  transactionID,
+		SequenceNumber: sequenceNumber,
+		EventType:      eventType,
+		Amount:         data.Amount,
+		Source:         data.Source,
+		CreatedAt:      createdAt,
+	}
+
+	switch {
+	case data.DestinationAccountID != nil:
+		movement.CounterpartyAccountID = data.DestinationAccountID
+	case data.SourceAccountID != nil:
+		movement.CounterpartyAccountID = data.SourceAccountID
+	case data.RevenueAccountID != nil:
+		movement.CounterpartyAccountID = data.RevenueAccountID
+	}
+
+	if slices.Contains(DebitEventTypes(), eventType) {
+		movement.Amount = movement.Amount.Neg()
+	}
+
+	return movement, nil
+}
@@ -0,0 +1,249 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type TransactionOperation string
+
+const (
+	TransactionOperationAccountOpening            TransactionOperation = "account_opening"
+	TransactionOperationAccountClosure            TransactionOperation = "account_closure"
+	TransactionOperationTransfer                  TransactionOperation = "transfer"
+	TransactionOperationAccountFreeze             TransactionOperation = "account_freeze"
+	TransactionOperationAccountUnfreeze           TransactionOperation = "account_unfreeze"
+	TransactionOperationOverdraftLimitChange      TransactionOperation = "overdraft_limit_change"
+	TransactionOperationProfileUpdate             TransactionOperation = "profile_update"
+	TransactionOperationInterestAccrual           TransactionOperation = "interest_accrual"
+	TransactionOperationInterestPosting           TransactionOperation = "interest_posting"
+	TransactionOperationStandingOrderCreation     TransactionOperation = "standing_order_creation"
+	TransactionOperationStandingOrderExecution    TransactionOperation = "standing_order_execution"
+	TransactionOperationStandingOrderCancellation TransactionOperation = "standing_order_cancellation"
+	TransactionOperationStandingOrderCompletion   TransactionOperation = "standing_order_completion"
+	TransactionOperationPaymentBatch              TransactionOperation = "payment_batch"
+)
+
+type TransactionStatus string
+
+const (
+	// TransactionStatusCompleted is the status of every recorded operation, its events are placed atomically.
+	TransactionStatusCompleted TransactionStatus = "completed"
+	// TransactionStatusFailed is the status of a standing order attempt that was skipped.
+	TransactionStatusFailed TransactionStatus = "failed"
+	// TransactionStatusPendingApproval is the status of a transfer waiting for a second person to approve it.
+	TransactionStatusPendingApproval TransactionStatus = "pending_approval"
+	// TransactionStatusRejected is the status of a transfer whose approval was rejected, that was denied by the
+	// risk rules or blocked by the sanctions screening.
+	TransactionStatusRejected TransactionStatus = "rejected"
+	// TransactionStatusExpired is the status of a transfer that was not approved in time.
+	TransactionStatusExpired TransactionStatus = "expired"
+)
+
+// derivedTransactionIDPrefixes are the prefixes of the transaction ids the application derives for the operations it
+// runs on its own, e.g. the transfer of a scheduled transfer, a client cannot use them.
+var derivedTransactionIDPrefixes = []string{
+	"scheduled-transfer-", "standing-order-", "payment-batch-", "interest-accrual-", "interest-posting-",
+	"system-account-",
+}
+
+// IsDerivedTransactionID reports whether the transaction id belongs to the namespace of the transaction ids derived
+// by the application.
+func IsDerivedTransactionID(transactionID string) bool {
+	return slices.ContainsFunc(derivedTransactionIDPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(transactionID, prefix)
+	})
+}
+
+// transactionOperations maps the event types to the operation they identify, the first event type
+// found in a transaction wins, e.g. the balance events of a closing account belong to the closure and the
+// debit of the interest account belongs to the interest posting.
+var transactionOperations = []struct {
+	eventType EventType
+	operation TransactionOperation
+}{
+	{EventTypeInitBalance, TransactionOperationAccountOpening},
+	{EventTypeAccountClosed, TransactionOperationAccountClosure},
+	{EventTypeInterestPosted, TransactionOperationInterestPosting},
+	{EventTypeDebitBalance, TransactionOperationTransfer},
+	{EventTypeTransferApprovalRequested, TransactionOperationTransfer},
+	{EventTypeAccountFrozen, TransactionOperationAccountFreeze},
+	{EventTypeAccountUnfrozen, TransactionOperationAccountUnfreeze},
+	{EventTypeOverdraftLimitSet, TransactionOperationOverdraftLimitChange},
+	{EventTypeAccountProfileUpdated, TransactionOperationProfileUpdate},
+	{EventTypeInterestAccrued, TransactionOperationInterestAccrual},
+	{EventTypeStandingOrderCreated, TransactionOperationStandingOrderCreation},
+	{EventTypeStandingOrderExecuted, TransactionOperationStandingOrderExecution},
+	{EventTypeStandingOrderSkipped, TransactionOperationStandingOrderExecution},
+	{EventTypeStandingOrderAwaitingApproval, TransactionOperationStandingOrderExecution},
+	{EventTypeStandingOrderCancelled, TransactionOperationStandingOrderCancellation},
+	{EventTypeStandingOrderCompleted, TransactionOperationStandingOrderCompletion},
+	{EventTypePaymentBatchReceived, TransactionOperationPaymentBatch},
+	{EventTypeTransferRiskAssessed, TransactionOperationTransfer},
+	{EventTypeTransferSanctionsHit, TransactionOperationTransfer},
+}
+
+// Transaction is an operation reconstructed from the events sharing its transaction id.
+type Transaction struct {
+	ID        string
+	Operation TransactionOperation
+	Status    TransactionStatus
+	// AccountIDs are the accounts whose events are part of the transaction, in order of appearance.
+	AccountIDs           []int64
+	SourceAccountID      *int64
+	DestinationAccountID *int64
+	Amount               *decimal.Decimal
+	Fee                  *decimal.Decimal
+	Events               []Event
+	CreatedAt            time.Time
+	CompletedAt          time.Time
+}
+
+type transactionData struct {
+	AccountID            *int64           `json:"account_id"`
+	SourceAccountID      *int64           `json:"source_account_id"`
+	DestinationAccountID *int64           `json:"destination_account_id"`
+	Amount               *decimal.Decimal `json:"amount"`
+	Action               string           `json:"action"`
+}
+
+// accountID returns the account a balance event belongs to, the events of an account shard carry the id of the
+// account of the shard.
+func (d transactionData) accountID(event Event) int64 {
+	if event.AggregateType == AggregateTypeAccountShard && d.AccountID != nil {
+		return *d.AccountID
+	}
+
+	return event.AggregateID
+}
+
+// isAccountEvent reports whether the event belongs to the stream of an account or of one of its shards.
+func isAccountEvent(event Event) bool {
+	return event.AggregateType == AggregateTypeAccount || event.AggregateType == AggregateTypeAccountShard
+}
+
+// NewTransaction reconstructs a transaction from its events.
+func NewTransaction(transactionID string, events []Event) (Transaction, error) {
+	if len(events) == 0 {
+		return Transaction{}, fmt.Errorf("transaction %s has no events", transactionID)
+	}
+
+	events = slices.Clone(events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+
+	transaction := Transaction{
+		ID:          transactionID,
+		Status:      TransactionStatusCompleted,
+		Events:      events,
+		CreatedAt:   events[0].CreatedAt,
+		CompletedAt: events[len(events)-1].CreatedAt,
+	}
+
+	eventTypes := make([]EventType, 0, len(events))
+
+	for _, event := range events {
+		eventTypes = append(eventTypes, event.EventType)
+
+		if err := transaction.apply(event); err != nil {
+			return Transaction{}, err
+		}
+	}
+
+	for _, candidate := range transactionOperations {
+		if slices.Contains(eventTypes, candidate.eventType) {
+			transaction.Operation = candidate.operation
+
+			break
+		}
+	}
+
+	return transaction, nil
+}
+
+// apply reads the accounts and amounts of the money movements.
+func (t *Transaction) apply(event Event) error {
+	var data transactionData
+
+	if err := unmarshalEventData(event.EventData, &data); err != nil {
+		return fmt.Errorf("read %s event data: %w", event.EventType, err)
+	}
+
+	aggregateID := data.accountID(event)
+
+	if isAccountEvent(event) && !slices.Contains(t.AccountIDs, aggregateID) {
+		t.AccountIDs = append(t.AccountIDs, aggregateID)
+	}
+
+	switch event.EventType {
+	case EventTypeDebitBalance:
+		t.SourceAccountID = &aggregateID
+		t.Amount = data.Amount
+	case EventTypeCreditBalance:
+		t.DestinationAccountID = &aggregateID
+	case EventTypeDepositReceived, EventTypeInterestPosted, EventTypeInterestAccrued:
+		t.DestinationAccountID = &aggregateID
+		t.Amount = data.Amount
+	case EventTypeFeeCharged:
+		t.Fee = data.Amount
+	case EventTypeStandingOrderSkipped:
+		t.Status = TransactionStatusFailed
+	case EventTypeStandingOrderAwaitingApproval:
+		t.Status = TransactionStatusPendingApproval
+	case EventTypeTransferApprovalRequested:
+		t.SourceAccountID = data.SourceAccountID
+		t.DestinationAccountID = data.DestinationAccountID
+		t.Amount = data.Amount
+		t.Status = TransactionStatusPendingApproval
+	case EventTypeTransferApproved:
+		t.Status = TransactionStatusCompleted
+	case EventTypeTransferRejected:
+		t.Status = TransactionStatusRejected
+	case EventTypeTransferApprovalExpired:
+		t.Status = TransactionStatusExpired
+	case EventTypeTransferRiskAssessed:
+		t.SourceAccountID = data.SourceAccountID
+		t.DestinationAccountID = data.DestinationAccountID
+		t.Amount = data.Amount
+
+		if data.Action == string(RiskActionDeny) {
+			t.Status = TransactionStatusRejected
+		}
+	case EventTypeTransferSanctionsHit:
+		t.SourceAccountID = data.SourceAccountID
+		t.DestinationAccountID = data.DestinationAccountID
+		t.Amount = data.Amount
+
+		if data.Action == string(SanctionsActionBlock) {
+			t.Status = TransactionStatusRejected
+		}
+	}
+
+	return nil
+}
+
+// unmarshalEventData reads event data scanned from the database as JSON, or built by an event collector.
+func unmarshalEventData(eventData interface{}, v interface{}) error {
+	raw, ok := eventData.([]byte)
+	if !ok {
+		var err error
+
+		raw, err = json.Marshal(eventData)
+		if err != nil {
+			return fmt.Errorf("marshal event data: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("unmarshal event data: %w", err)
+	}
+
+	return nil
+}
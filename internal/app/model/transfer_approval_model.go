@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type TransferApprovalStatus string
+
+const (
+	TransferApprovalStatusPending  TransferApprovalStatus = "pending_approval"
+	TransferApprovalStatusApproved TransferApprovalStatus = "approved"
+	TransferApprovalStatusRejected TransferApprovalStatus = "rejected"
+	TransferApprovalStatusExpired  TransferApprovalStatus = "expired"
+)
+
+// TransferApproval is the projection of the transfer aggregate, a transfer above the approval threshold waits for
+// a second person to approve it before it is executed under its transaction id.
+type TransferApproval struct {
+	ID                   int64                  `json:"id"`
+	TransactionID        string                 `json:"transaction_id"`
+	SourceAccountID      int64                  `json:"source_account_id"`
+	DestinationAccountID int64                  `json:"destination_account_id"`
+	Amount               decimal.Decimal        `json:"amount"`
+	InitiatedBy          string                 `json:"initiated_by"`
+	ReviewedBy           *string                `json:"reviewed_by"`
+	RejectionReason      string                 `json:"rejection_reason"`
+	Status               TransferApprovalStatus `json:"status"`
+	ExpiresAt            time.Time              `json:"expires_at"`
+	ReviewedAt           *time.Time             `json:"reviewed_at"`
+	CreatedAt            time.Time              `json:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at"`
+}
+
+func (t TransferApproval) IsPending() bool {
+	return t.Status == TransferApprovalStatusPending
+}
+
+// IsExpired reports whether a pending transfer can no longer be approved.
+func (t TransferApproval) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// CanBeReviewedBy reports whether the reviewer is allowed to approve or reject the transfer, the initiator cannot
+// review their own transfer.
+func (t TransferApproval) CanBeReviewedBy(reviewer string) bool {
+	return reviewer != "" && reviewer != t.InitiatedBy
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransferLimits are the outgoing transfer limits of an account, a zero value disables the limit.
+type TransferLimits struct {
+	PerTransaction decimal.Decimal `json:"per_transaction"`
+	Daily          decimal.Decimal `json:"daily"`
+	Monthly        decimal.Decimal `json:"monthly"`
+	HourlyCount    int             `json:"hourly_count"`
+}
+
+// AccountTransferLimit overrides the global limits of one account, a nil field falls back to the global limit.
+type AccountTransferLimit struct {
+	AccountID      int64            `json:"account_id"`
+	PerTransaction *decimal.Decimal `json:"per_transaction"`
+	Daily          *decimal.Decimal `json:"daily"`
+	Monthly        *decimal.Decimal `json:"monthly"`
+	HourlyCount    *int             `json:"hourly_count"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// Apply returns the effective limits of the account.
+func (l AccountTransferLimit) Apply(limits TransferLimits) TransferLimits {
+	if l.PerTransaction != nil {
+		limits.PerTransaction = *l.PerTransaction
+	}
+
+	if l.Daily != nil {
+		limits.Daily = *l.Daily
+	}
+
+	if l.Monthly != nil {
+		limits.Monthly = *l.Monthly
+	}
+
+	if l.HourlyCount != nil {
+		limits.HourlyCount = *l.HourlyCount
+	}
+
+	return limits
+}
+
+// DebitUsageWindow holds the start of each limit window.
+type DebitUsageWindow struct {
+	DayStart   time.Time
+	MonthStart time.Time
+	HourStart  time.Time
+}
+
+// NewDebitUsageWindow returns the calendar day and month (UTC) and the rolling hour ending at now.
+func NewDebitUsageWindow(now time.Time) DebitUsageWindow {
+	now = now.UTC()
+
+	return DebitUsageWindow{
+		DayStart:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		MonthStart: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		HourStart:  now.Add(-time.Hour),
+	}
+}
+
+// DebitUsage is the outgoing usage of an account within the limit windows.
+type DebitUsage struct {
+	Daily       decimal.Decimal `json:"daily"`
+	Monthly     decimal.Decimal `json:"monthly"`
+	HourlyCount int             `json:"hourly_count"`
+}
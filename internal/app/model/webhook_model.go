@@ -0,0 +1,101 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+type WebhookStatus string
+
+const (
+	WebhookStatusActive WebhookStatus = "active"
+	// WebhookStatusDisabled is a deleted subscription, its pending deliveries are not sent.
+	WebhookStatusDisabled WebhookStatus = "disabled"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryStatusFailed is a delivery whose attempts are exhausted, it is only sent again on a redelivery.
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookEventTypes are the account events a subscription can receive.
+var WebhookEventTypes = []EventType{
+	EventTypeDepositReceived,
+	EventTypeDebitBalance,
+	EventTypeCreditBalance,
+	EventTypeAccountFrozen,
+	EventTypeAccountUnfrozen,
+	EventTypeAccountClosed,
+	EventTypeOverdraftLimitSet,
+	EventTypeAccountProfileUpdated,
+	EventTypeFeeCharged,
+	EventTypeInterestPosted,
+}
+
+// Webhook is a subscription to the events of the accounts, an empty filter matches every event or account.
+type Webhook struct {
+	ID         int64         `json:"id"`
+	URL        string        `json:"url"`
+	Secret     string        `json:"-"`
+	EventTypes []EventType   `json:"event_types"`
+	AccountIDs []int64       `json:"account_ids"`
+	Status     WebhookStatus `json:"status"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// Matches reports whether an event of an account is sent to the subscription.
+func (w Webhook) Matches(eventType EventType, accountID int64) bool {
+	if w.Status != WebhookStatusActive || !slices.Contains(WebhookEventTypes, eventType) {
+		return false
+	}
+
+	if len(w.EventTypes) > 0 && !slices.Contains(w.EventTypes, eventType) {
+		return false
+	}
+
+	return len(w.AccountIDs) == 0 || slices.Contains(w.AccountIDs, accountID)
+}
+
+// WebhookDelivery is an event to send to a subscription. The payload is built once, a redelivery sends the same
+// payload.
+type WebhookDelivery struct {
+	ID             int64                    `json:"id"`
+	WebhookID      int64                    `json:"webhook_id"`
+	EventID        int64                    `json:"event_id"`
+	EventType      EventType                `json:"event_type"`
+	Payload        []byte                   `json:"payload"`
+	Status         WebhookDeliveryStatus    `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  time.Time                `json:"next_attempt_at"`
+	LastStatusCode int                      `json:"last_status_code"`
+	LastError      string                   `json:"last_error"`
+	DeliveredAt    *time.Time               `json:"delivered_at"`
+	AttemptLog     []WebhookDeliveryAttempt `json:"attempt_log"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// WebhookDeliveryAttempt is an entry of the delivery log, StatusCode is zero when no response was received.
+type WebhookDeliveryAttempt struct {
+	ID         int64         `json:"id"`
+	DeliveryID int64         `json:"delivery_id"`
+	Attempt    int           `json:"attempt"`
+	StatusCode int           `json:"status_code"`
+	Error      string        `json:"error"`
+	Duration   time.Duration `json:"duration"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// WebhookDeliveryFilter selects a page of the deliveries of a subscription, newest first.
+type WebhookDeliveryFilter struct {
+	WebhookID int64
+	Status    WebhookDeliveryStatus
+	// BeforeID is the id of the last delivery of the previous page, zero for the first page.
+	BeforeID int64
+	Limit    int
+}
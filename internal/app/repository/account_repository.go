@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
 )
 
@@ -16,10 +19,10 @@ type AccountRepository struct {
 	errorMapper
 }
 
-func NewAccountRepository(db *sql.DB) *AccountRepository {
+func NewAccountRepository(db *sql.DB, txPolicy db.TransactionPolicy) *AccountRepository {
 	return &AccountRepository{
 		db:           db,
-		transactable: transactable{db: db},
+		transactable: transactable{db: db, policy: txPolicy},
 	}
 }
 
@@ -29,9 +32,11 @@ func (r *AccountRepository) UpsertTx(ctx context.Context, dbTx *sql.Tx, account
 	}
 
 	query := `
-		INSERT INTO accounts (id, balance, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (id) DO UPDATE SET balance = $2, updated_at = $4
+		INSERT INTO accounts (id, type, balance, overdraft_limit, status, status_reason, created_at, updated_at,
+			currency, display_name, owner_reference, labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (id) DO UPDATE SET type = $2, balance = $3, overdraft_limit = $4, status = $5,
+			status_reason = $6, updated_at = $8, display_name = $10, owner_reference = $11, labels = $12
 	`
 
 	stmt, err := dbTx.PrepareContext(ctx, query)
@@ -41,7 +46,19 @@ func (r *AccountRepository) UpsertTx(ctx context.Context, dbTx *sql.Tx, account
 
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, account.ID, account.Balance, account.CreatedAt, account.UpdatedAt)
+	labels, err := json.Marshal(account.Labels)
+	if err != nil {
+		return fmt.Errorf("failed to marshal labels: %w", err)
+	}
+
+	// an account without labels stores an empty object
+	if account.Labels == nil {
+		labels = []byte("{}")
+	}
+
+	_, err = stmt.ExecContext(ctx, account.ID, account.Type, account.Balance, account.OverdraftLimit, account.Status,
+		nullString(string(account.StatusReason)), account.CreatedAt, account.UpdatedAt, account.Currency,
+		nullString(account.DisplayName), nullString(account.OwnerReference), labels)
 	if err != nil {
 		err = r.mapError(err)
 
@@ -51,9 +68,12 @@ func (r *AccountRepository) UpsertTx(ctx context.Context, dbTx *sql.Tx, account
 	return nil
 }
 
+// FindByID returns an account with the balance of its shards added to its own balance.
 func (r *AccountRepository) FindByID(ctx context.Context, accountID int64) (model.Account, error) {
 	query := `
-		SELECT id, balance
+		SELECT id, type, currency,
+			balance + COALESCE((SELECT SUM(s.balance) FROM account_shards s WHERE s.account_id = accounts.id), 0),
+			overdraft_limit, status, status_reason, created_at, updated_at, display_name, owner_reference, labels
 		FROM accounts
 		WHERE id = $1
 	`
@@ -65,11 +85,7 @@ func (r *AccountRepository) FindByID(ctx context.Context, accountID int64) (mode
 
 	defer stmt.Close()
 
-	row := stmt.QueryRowContext(ctx, accountID)
-
-	var account model.Account
-
-	err = row.Scan(&account.ID, &account.Balance)
+	account, err := scanAccount(stmt.QueryRowContext(ctx, accountID))
 	if err != nil && errors.Is(err, sql.ErrNoRows) {
 		err := exception.ErrRecordNotFound
 		err.MessageVars = map[string]interface{}{
@@ -88,15 +104,30 @@ func (r *AccountRepository) FindByID(ctx context.Context, accountID int64) (mode
 	return account, nil
 }
 
+// FindByIDForUpdateTx locks an account to change it, the balance of its shards is not included.
 func (r *AccountRepository) FindByIDForUpdateTx(ctx context.Context,
 	dbTx *sql.Tx, accountID int64,
+) (model.Account, error) {
+	return r.findByIDTx(ctx, dbTx, accountID, "FOR UPDATE")
+}
+
+// FindByIDForShareTx locks an account against changes while a shard of it is credited, the credits to the account
+// do not wait for each other.
+func (r *AccountRepository) FindByIDForShareTx(ctx context.Context,
+	dbTx *sql.Tx, accountID int64,
+) (model.Account, error) {
+	return r.findByIDTx(ctx, dbTx, accountID, "FOR SHARE")
+}
+
+func (r *AccountRepository) findByIDTx(ctx context.Context, dbTx *sql.Tx, accountID int64,
+	lock string,
 ) (model.Account, error) {
 	query := `
-		SELECT id, balance
+		SELECT id, type, currency, balance, overdraft_limit, status, status_reason, created_at, updated_at,
+			display_name, owner_reference, labels
 		FROM accounts
 		WHERE id = $1
-		FOR UPDATE
-	`
+		` + lock
 
 	stmt, err := dbTx.PrepareContext(ctx, query)
 	if err != nil {
@@ -105,11 +136,7 @@ func (r *AccountRepository) FindByIDForUpdateTx(ctx context.Context,
 
 	defer stmt.Close()
 
-	row := stmt.QueryRowContext(ctx, accountID)
-
-	var account model.Account
-
-	err = row.Scan(&account.ID, &account.Balance)
+	account, err := scanAccount(stmt.QueryRowContext(ctx, accountID))
 	if err != nil && errors.Is(err, sql.ErrNoRows) {
 		err := exception.ErrRecordNotFound
 		err.MessageVars = map[string]interface{}{
@@ -127,3 +154,145 @@ func (r *AccountRepository) FindByIDForUpdateTx(ctx context.Context,
 
 	return account, nil
 }
+
+// accountSortKeys whitelists the sortable columns of the account listing with the type of their keyset value.
+var accountSortKeys = map[model.AccountSortField]struct {
+	column string
+	cast   string
+}{
+	model.AccountSortFieldID:        {column: "id", cast: "bigint"},
+	model.AccountSortFieldBalance:   {column: "balance", cast: "numeric"},
+	model.AccountSortFieldCreatedAt: {column: "created_at", cast: "timestamp"},
+}
+
+// FindAll returns a page of accounts matching the filter, in keyset order of the sort field and the id.
+func (r *AccountRepository) FindAll(ctx context.Context, filter model.AccountFilter) ([]model.Account, error) {
+	sortKey, ok := accountSortKeys[filter.SortField]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", filter.SortField)
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	// addCondition numbers the placeholder of the condition after the previous arguments
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.MinBalance != nil {
+		addCondition("balance >= $%d", *filter.MinBalance)
+	}
+
+	if filter.MaxBalance != nil {
+		addCondition("balance <= $%d", *filter.MaxBalance)
+	}
+
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= $%d", *filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		addCondition("created_at < $%d", *filter.CreatedTo)
+	}
+
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+
+	if filter.Currency != "" {
+		addCondition("currency = $%d", filter.Currency)
+	}
+
+	direction, operator := "ASC", ">"
+	if filter.Descending {
+		direction, operator = "DESC", "<"
+	}
+
+	orderBy := "id " + direction
+
+	if sortKey.column != "id" {
+		orderBy = sortKey.column + " " + direction + ", " + orderBy
+
+		if filter.AfterValue != nil && filter.AfterID != nil {
+			args = append(args, *filter.AfterValue, *filter.AfterID)
+			conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)",
+				sortKey.column, operator, len(args)-1, sortKey.cast, len(args)))
+		}
+	} else if filter.AfterID != nil {
+		addCondition("id "+operator+" $%d", *filter.AfterID)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT id, type, currency, balance, overdraft_limit, status, status_reason, created_at, updated_at,
+			display_name, owner_reference, labels
+		FROM accounts
+		%s
+		ORDER BY %s
+		LIMIT $%d
+	`, where, orderBy, len(args))
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var accounts []model.Account
+
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+func scanAccount(row rowScanner) (model.Account, error) {
+	var (
+		account        model.Account
+		statusReason   sql.NullString
+		displayName    sql.NullString
+		ownerReference sql.NullString
+		labels         []byte
+	)
+
+	err := row.Scan(&account.ID, &account.Type, &account.Currency, &account.Balance, &account.OverdraftLimit,
+		&account.Status, &statusReason, &account.CreatedAt, &account.UpdatedAt, &displayName, &ownerReference,
+		&labels)
+	if err != nil {
+		return model.Account{}, err //nolint:wrapcheck
+	}
+
+	if err := json.Unmarshal(labels, &account.Labels); err != nil {
+		return model.Account{}, fmt.Errorf("unmarshal labels: %w", err)
+	}
+
+	account.StatusReason = model.AccountStatusReason(statusReason.String)
+	account.DisplayName = displayName.String
+	account.OwnerReference = ownerReference.String
+
+	return account, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/shopspring/decimal"
+)
+
+type AccountShardRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewAccountShardRepository(db *sql.DB, txPolicy db.TransactionPolicy) *AccountShardRepository {
+	return &AccountShardRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+// CreditTx adds the amount to a shard of an account, the shard is created on its first credit. The shard stays
+// locked until the transaction ends, the credits to other shards of the account do not wait for it.
+func (r *AccountShardRepository) CreditTx(ctx context.Context, dbTx *sql.Tx, accountID int64, shardNo int,
+	amount decimal.Decimal, now time.Time,
+) (model.AccountShard, error) {
+	if dbTx == nil {
+		return model.AccountShard{}, errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO account_shards (account_id, shard_no, balance, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (account_id, shard_no) DO UPDATE
+			SET balance = account_shards.balance + EXCLUDED.balance, updated_at = EXCLUDED.updated_at
+		RETURNING id, account_id, shard_no, balance, created_at, updated_at
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.AccountShard{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	shard, err := scanAccountShard(stmt.QueryRowContext(ctx, accountID, shardNo, amount, now))
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.AccountShard{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return shard, nil
+}
+
+// FindAllByAccountIDForUpdateTx locks the shards of an account, in shard order. A credit to a shard waits until
+// the transaction ends.
+func (r *AccountShardRepository) FindAllByAccountIDForUpdateTx(ctx context.Context, dbTx *sql.Tx,
+	accountID int64,
+) ([]model.AccountShard, error) {
+	if dbTx == nil {
+		return nil, errors.New("transaction is nil")
+	}
+
+	query := `
+		SELECT id, account_id, shard_no, balance, created_at, updated_at
+		FROM account_shards
+		WHERE account_id = $1
+		ORDER BY shard_no
+		FOR UPDATE
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var shards []model.AccountShard
+
+	for rows.Next() {
+		shard, err := scanAccountShard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		shards = append(shards, shard)
+	}
+
+	return shards, nil
+}
+
+// ResetAllByAccountIDTx sets the balance of the shards of an account to zero, once it is consolidated into the
+// account.
+func (r *AccountShardRepository) ResetAllByAccountIDTx(ctx context.Context, dbTx *sql.Tx, accountID int64,
+	now time.Time,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `UPDATE account_shards SET balance = 0, updated_at = $2 WHERE account_id = $1`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, accountID, now)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func scanAccountShard(row rowScanner) (model.AccountShard, error) {
+	var shard model.AccountShard
+
+	err := row.Scan(&shard.ID, &shard.AccountID, &shard.ShardNo, &shard.Balance, &shard.CreatedAt, &shard.UpdatedAt)
+	if err != nil {
+		return model.AccountShard{}, err //nolint:wrapcheck
+	}
+
+	return shard, nil
+}
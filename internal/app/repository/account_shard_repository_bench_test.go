@@ -0,0 +1,163 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+const benchShards = 8
+
+// benchTransactionPolicy runs the transactions of the benchmark at the default isolation level without retries.
+var benchTransactionPolicy = db.TransactionPolicy{Isolation: sql.LevelDefault}
+
+// BenchmarkHotAccountCredit compares concurrent credits to one account booked on the account row with the credits
+// booked on its shards. Run it against a migrated database:
+//
+//	DB_DSN=... go test -tags integration -run '^$' -bench HotAccountCredit -cpu 16 ./internal/app/repository/
+func BenchmarkHotAccountCredit(b *testing.B) {
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		b.Skip("DB_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer db.Close()
+
+	db.SetMaxOpenConns(64)
+
+	b.Run("account_row", func(b *testing.B) {
+		accountID := createBenchAccount(b, db)
+		accountRepository := NewAccountRepository(db, benchTransactionPolicy)
+		eventRepository := NewEventRepository(db, benchTransactionPolicy)
+
+		var sequence atomic.Int64
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				err := accountRepository.WithTransaction(context.Background(),
+					func(ctx context.Context, dbTx *sql.Tx) error {
+						account, err := accountRepository.FindByIDForUpdateTx(ctx, dbTx, accountID)
+						if err != nil {
+							return err
+						}
+
+						account.Balance = account.Balance.Add(decimal.NewFromInt(1))
+						account.UpdatedAt = time.Now()
+
+						if err := accountRepository.UpsertTx(ctx, dbTx, &account); err != nil {
+							return err
+						}
+
+						// the row lock orders the stream
+						return eventRepository.CreateTx(ctx, dbTx, benchCreditEvent(model.AggregateTypeAccount,
+							accountID, sequence.Add(1)))
+					})
+				if err != nil {
+					b.Error(err)
+				}
+			}
+		})
+	})
+
+	b.Run("account_shards", func(b *testing.B) {
+		accountID := createBenchAccount(b, db)
+		accountRepository := NewAccountRepository(db, benchTransactionPolicy)
+		accountShardRepository := NewAccountShardRepository(db, benchTransactionPolicy)
+		eventRepository := NewEventRepository(db, benchTransactionPolicy)
+
+		var sequences [benchShards]atomic.Int64
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				err := accountRepository.WithTransaction(context.Background(),
+					func(ctx context.Context, dbTx *sql.Tx) error {
+						_, err := accountRepository.FindByIDForShareTx(ctx, dbTx, accountID)
+						if err != nil {
+							return err
+						}
+
+						shardNo := rand.N(benchShards) //nolint:gosec
+
+						shard, err := accountShardRepository.CreditTx(ctx, dbTx, accountID, shardNo,
+							decimal.NewFromInt(1), time.Now())
+						if err != nil {
+							return err
+						}
+
+						// the shard row lock orders the shard stream
+						return eventRepository.CreateTx(ctx, dbTx, benchCreditEvent(model.AggregateTypeAccountShard,
+							shard.ID, sequences[shardNo].Add(1)))
+					})
+				if err != nil {
+					b.Error(err)
+				}
+			}
+		})
+	})
+}
+
+func createBenchAccount(b *testing.B, db *sql.DB) int64 {
+	b.Helper()
+
+	accountID := time.Now().UnixNano() % 1_000_000_000
+
+	now := time.Now()
+	account := model.Account{
+		ID: accountID, Type: model.AccountTypeBusiness, Currency: "USD", Status: model.AccountStatusActive,
+		CreatedAt: now, UpdatedAt: now,
+	}
+
+	repository := NewAccountRepository(db, benchTransactionPolicy)
+
+	err := repository.WithTransaction(context.Background(), func(ctx context.Context, dbTx *sql.Tx) error {
+		return repository.UpsertTx(ctx, dbTx, &account)
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM events WHERE transaction_id LIKE 'bench-%'`); err != nil {
+			b.Error(err)
+		}
+
+		if _, err := db.Exec(`DELETE FROM account_shards WHERE account_id = $1`, accountID); err != nil {
+			b.Error(err)
+		}
+
+		if _, err := db.Exec(`DELETE FROM accounts WHERE id = $1`, accountID); err != nil {
+			b.Error(err)
+		}
+	})
+
+	return accountID
+}
+
+func benchCreditEvent(aggregateType model.AggregateType, aggregateID, sequence int64) *model.Event {
+	return &model.Event{
+		TransactionID:  fmt.Sprintf("bench-%d-%d", aggregateID, sequence),
+		SequenceNumber: sequence,
+		AggregateID:    aggregateID,
+		AggregateType:  aggregateType,
+		EventType:      model.EventTypeCreditBalance,
+		EventData:      map[string]any{"amount": "1"},
+		Version:        "bench",
+	}
+}
@@ -9,7 +9,7 @@ import (
 )
 
 var dbErrorMap = map[string]error{
-	"SQLSTATE 23505": exception.ErrRecordNotUnique,
+	"23505": exception.ErrRecordNotUnique, // unique_violation
 }
 
 type errorMapper struct{}
@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
 	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 )
 
 type EventRepository struct {
@@ -18,10 +21,10 @@ type EventRepository struct {
 	errorMapper
 }
 
-func NewEventRepository(db *sql.DB) *EventRepository {
+func NewEventRepository(db *sql.DB, txPolicy db.TransactionPolicy) *EventRepository {
 	return &EventRepository{
 		db:           db,
-		transactable: transactable{db: db},
+		transactable: transactable{db: db, policy: txPolicy},
 	}
 }
 
@@ -103,9 +106,11 @@ func (r *EventRepository) CreateBulkTx(ctx context.Context, dbTx *sql.Tx, events
 
 func (r *EventRepository) FindAllByTransactionID(ctx context.Context, transactionID string) ([]model.Event, error) {
 	query := `
-		SELECT id, aggregate_id, aggregate_type, event_type, sequence_number, event_data, version
+		SELECT id, transaction_id, aggregate_id, aggregate_type, event_type, sequence_number, event_data, version,
+			created_at
 		FROM events
 		WHERE transaction_id = $1
+		ORDER BY created_at, id
 	`
 
 	stmt, err := r.db.PrepareContext(ctx, query)
@@ -127,8 +132,8 @@ func (r *EventRepository) FindAllByTransactionID(ctx context.Context, transactio
 	for rows.Next() {
 		var event model.Event
 
-		err = rows.Scan(&event.ID, &event.AggregateID, &event.AggregateType,
-			&event.EventType, &event.SequenceNumber, &event.EventData, &event.Version)
+		err = rows.Scan(&event.ID, &event.TransactionID, &event.AggregateID, &event.AggregateType,
+			&event.EventType, &event.SequenceNumber, &event.EventData, &event.Version, &event.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
@@ -148,11 +153,13 @@ func (r *EventRepository) FindAllByTransactionID(ctx context.Context, transactio
 	return events, nil
 }
 
-func (r *EventRepository) FindLastByAggregateID(ctx context.Context, aggregateID int64) (model.Event, error) {
+func (r *EventRepository) FindLastByAggregateID(ctx context.Context,
+	aggregateType model.AggregateType, aggregateID int64,
+) (model.Event, error) {
 	query := `
 		SELECT id, aggregate_id, aggregate_type, event_type, sequence_number, event_data, version
 		FROM events
-		WHERE aggregate_id = $1
+		WHERE aggregate_id = $1 AND aggregate_type = $2
 		ORDER BY sequence_number DESC
 		LIMIT 1
 	`
@@ -164,7 +171,7 @@ func (r *EventRepository) FindLastByAggregateID(ctx context.Context, aggregateID
 
 	defer stmt.Close()
 
-	row := stmt.QueryRowContext(ctx, aggregateID)
+	row := stmt.QueryRowContext(ctx, aggregateID, aggregateType)
 
 	var event model.Event
 	err = row.Scan(&event.ID, &event.AggregateID, &event.AggregateType,
@@ -187,3 +194,246 @@ func (r *EventRepository) FindLastByAggregateID(ctx context.Context, aggregateID
 
 	return event, nil
 }
+
+// FindDebitUsageTx sums the balance_debited events of an account within the limit windows. Events of
+// excludeTransactionID are ignored, they belong to the transfer being evaluated.
+func (r *EventRepository) FindDebitUsageTx(ctx context.Context, dbTx *sql.Tx, accountID int64,
+	excludeTransactionID string, window model.DebitUsageWindow,
+) (model.DebitUsage, error) {
+	if dbTx == nil {
+		return model.DebitUsage{}, errors.New("transaction is nil")
+	}
+
+	query := `
+		SELECT
+			COALESCE(SUM((event_data->>'amount')::numeric) FILTER (WHERE created_at >= $5), 0),
+			COALESCE(SUM((event_data->>'amount')::numeric) FILTER (WHERE created_at >= $6), 0),
+			COUNT(*) FILTER (WHERE created_at >= $7)
+		FROM events
+		WHERE aggregate_id = $1 AND aggregate_type = $2 AND event_type = $3 AND transaction_id <> $4
+			AND created_at >= LEAST($6::timestamp, $7::timestamp)
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.DebitUsage{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var usage model.DebitUsage
+
+	err = stmt.QueryRowContext(ctx, accountID, model.AggregateTypeAccount, model.EventTypeDebitBalance,
+		excludeTransactionID, window.DayStart, window.MonthStart, window.HourStart).
+		Scan(&usage.Daily, &usage.Monthly, &usage.HourlyCount)
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.DebitUsage{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return usage, nil
+}
+
+// FindBalanceAt derives the balance of an account from its events and the events of its shards created before at.
+func (r *EventRepository) FindBalanceAt(ctx context.Context, accountID int64, at time.Time) (decimal.Decimal, error) {
+	query := `
+		SELECT COALESCE(SUM(CASE
+			WHEN event_type = ANY($3) THEN (event_data->>'amount')::numeric
+			WHEN event_type = ANY($4) THEN -(event_data->>'amount')::numeric
+			ELSE 0 END), 0)
+		FROM events
+		WHERE (aggregate_id = $1 AND aggregate_type = $2
+			OR aggregate_id IN (SELECT id FROM account_shards WHERE account_id = $1) AND aggregate_type = $6)
+			AND created_at < $5
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var balance decimal.Decimal
+
+	err = stmt.QueryRowContext(ctx, accountID, model.AggregateTypeAccount, eventTypeArray(model.CreditEventTypes()),
+		eventTypeArray(model.DebitEventTypes()), at, model.AggregateTypeAccountShard).Scan(&balance)
+	if err != nil {
+		err = r.mapError(err)
+
+		return decimal.Zero, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return balance, nil
+}
+
+// FindAllMovements returns the balance movements of an account and of its shards created within [from, to), in
+// the order they were recorded.
+func (r *EventRepository) FindAllMovements(ctx context.Context, accountID int64,
+	from time.Time, to time.Time,
+) ([]model.Movement, error) {
+	query := `
+		SELECT transaction_id, sequence_number, event_type, event_data, created_at
+		FROM events
+		WHERE (aggregate_id = $1 AND aggregate_type = $2
+			OR aggregate_id IN (SELECT id FROM account_shards WHERE account_id = $1) AND aggregate_type = $7)
+			AND (event_type = ANY($3) OR event_type = ANY($4)) AND created_at >= $5 AND created_at < $6
+		ORDER BY id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, accountID, model.AggregateTypeAccount,
+		eventTypeArray(model.CreditEventTypes()), eventTypeArray(model.DebitEventTypes()), from, to,
+		model.AggregateTypeAccountShard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var movements []model.Movement
+
+	for rows.Next() {
+		var (
+			transactionID  string
+			sequenceNumber int64
+			eventType      model.EventType
+			eventData      []byte
+			createdAt      time.Time
+		)
+
+		err = rows.Scan(&transactionID, &sequenceNumber, &eventType, &eventData, &createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		movement, err := model.NewMovement(transactionID, sequenceNumber, eventType, eventData, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read movement: %w", err)
+		}
+
+		movements = append(movements, movement)
+	}
+
+	return movements, nil
+}
+
+// FindAllBankMovements returns the deposits into accounts and the transfers from accounts to the funding account
+// recorded within [from, to), the movements crossing the bank account of the customer funds.
+func (r *EventRepository) FindAllBankMovements(ctx context.Context, fundingAccountID int64,
+	from time.Time, to time.Time,
+) ([]model.BankMovement, error) {
+	query := `
+		SELECT id, transaction_id, aggregate_id, aggregate_type, event_type, sequence_number, event_data, version,
+			created_at
+		FROM events
+		WHERE aggregate_type = $1 AND aggregate_id <> $2 AND created_at >= $3 AND created_at < $4
+			AND (event_type = $5 OR event_type = $6 AND (event_data->>'destination_account_id')::bigint = $2)
+		ORDER BY id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, model.AggregateTypeAccount, fundingAccountID, from, to,
+		model.EventTypeDepositReceived, model.EventTypeDebitBalance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var movements []model.BankMovement
+
+	for rows.Next() {
+		var event model.Event
+
+		err = rows.Scan(&event.ID, &event.TransactionID, &event.AggregateID, &event.AggregateType,
+			&event.EventType, &event.SequenceNumber, &event.EventData, &event.Version, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		movement, err := model.NewBankMovement(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bank movement: %w", err)
+		}
+
+		movements = append(movements, movement)
+	}
+
+	return movements, nil
+}
+
+// FindAllAccountMovements returns the debits and credits of the customer accounts and of their shards recorded
+// within [from, to), the movements of the system accounts are left out.
+func (r *EventRepository) FindAllAccountMovements(ctx context.Context,
+	from time.Time, to time.Time,
+) ([]model.AccountMovement, error) {
+	query := `
+		SELECT e.id, e.transaction_id, e.aggregate_id, e.aggregate_type, e.event_type, e.sequence_number,
+			e.event_data, e.version, e.created_at
+		FROM events e
+		JOIN accounts a ON a.id = CASE WHEN e.aggregate_type = $1
+			THEN (e.event_data->>'account_id')::bigint ELSE e.aggregate_id END
+		WHERE e.aggregate_type IN ($1, $2) AND e.event_type IN ($3, $4) AND e.created_at >= $5
+			AND e.created_at < $6 AND a.type <> $7
+		ORDER BY e.id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, model.AggregateTypeAccountShard, model.AggregateTypeAccount,
+		model.EventTypeDebitBalance, model.EventTypeCreditBalance, from, to, model.AccountTypeSystem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var movements []model.AccountMovement
+
+	for rows.Next() {
+		var event model.Event
+
+		err = rows.Scan(&event.ID, &event.TransactionID, &event.AggregateID, &event.AggregateType,
+			&event.EventType, &event.SequenceNumber, &event.EventData, &event.Version, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		movement, err := model.NewAccountMovement(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read account movement: %w", err)
+		}
+
+		movements = append(movements, movement)
+	}
+
+	return movements, nil
+}
+
+func eventTypeArray(eventTypes []model.EventType) interface{} {
+	values := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		values = append(values, string(eventType))
+	}
+
+	return pq.Array(values)
+}
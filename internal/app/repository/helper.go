@@ -4,18 +4,24 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
 )
 
+// transactable runs the transactions of a repository with the isolation level and the retries of its policy.
 type transactable struct {
-	db *sql.DB
+	db     *sql.DB
+	policy db.TransactionPolicy
 }
 
+// WithTransaction runs txFunc within a transaction, it is rolled back when txFunc fails. The commit error is
+// returned, e.g. a deferred constraint violated by the transaction.
 func (r *transactable) WithTransaction(ctx context.Context,
 	txFunc func(context.Context, *sql.Tx) error,
-) error {
-	var err error
-
-	dbTx, err := r.db.BeginTx(ctx, nil)
+) (err error) {
+	dbTx, err := r.db.BeginTx(ctx, r.policy.TxOptions())
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -35,3 +41,50 @@ func (r *transactable) WithTransaction(ctx context.Context,
 
 	return err
 }
+
+// WithRetryableTransaction runs txFunc within a transaction like WithTransaction, a transaction failing with a
+// serialization failure or a deadlock is run again after a backoff. txFunc must not depend on the state left by a
+// previous run, e.g. it creates its event collectors.
+func (r *transactable) WithRetryableTransaction(ctx context.Context,
+	txFunc func(context.Context, *sql.Tx) error,
+) error {
+	for retry := 0; ; retry++ {
+		err := r.WithTransaction(ctx, txFunc)
+		if err == nil && retry > 0 {
+			slog.InfoContext(ctx, "transaction committed after retries", slog.Int("retries", retry))
+		}
+
+		if err == nil || !db.IsRetryable(err) {
+			return err
+		}
+
+		if retry == r.policy.MaxRetries {
+			slog.WarnContext(ctx, "transaction retries exhausted", slog.Int("retries", retry),
+				slog.String("error", err.Error()))
+
+			return fmt.Errorf("transaction failed after %d retries: %w", retry, err)
+		}
+
+		delay := r.policy.Backoff(retry + 1)
+
+		slog.WarnContext(ctx, "retrying transaction", slog.Int("retry", retry+1),
+			slog.Int("max_retries", r.policy.MaxRetries), slog.Duration("delay", delay),
+			slog.String("error", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction retry cancelled: %w", err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// nullString stores an empty string as NULL.
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+)
+
+type IdempotencyKeyRepository struct {
+	db *sql.DB
+	errorMapper
+}
+
+func NewIdempotencyKeyRepository(db *sql.DB) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{
+		db: db,
+	}
+}
+
+// ClaimTx claims the transaction id within the transaction of the operation using it. A concurrent claim of the same
+// transaction id waits for this transaction and fails with exception.ErrRecordNotUnique once it commits.
+func (r *IdempotencyKeyRepository) ClaimTx(ctx context.Context, dbTx *sql.Tx, transactionID string,
+	now time.Time,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `INSERT INTO idempotency_keys (transaction_id, created_at) VALUES ($1, $2)`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, transactionID, now)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// Create records the response of a transaction id, the claim of the transaction id is completed when the operation
+// claimed it. The response recorded first is kept.
+func (r *IdempotencyKeyRepository) Create(ctx context.Context, idempotencyKey *model.IdempotencyKey) error {
+	query := `
+		INSERT INTO idempotency_keys (transaction_id, fingerprint, status_code, content_type, response_body,
+			created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (transaction_id) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = EXCLUDED.status_code,
+			content_type = EXCLUDED.content_type, response_body = EXCLUDED.response_body
+		WHERE idempotency_keys.status_code IS NULL
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, idempotencyKey.TransactionID, idempotencyKey.Fingerprint,
+		idempotencyKey.StatusCode, idempotencyKey.ContentType, idempotencyKey.ResponseBody, idempotencyKey.CreatedAt)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAllExpired deletes at most limit transaction ids claimed before the given time.
+func (r *IdempotencyKeyRepository) DeleteAllExpired(ctx context.Context, before time.Time,
+	limit int,
+) (int, error) {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE transaction_id IN (
+			SELECT transaction_id FROM idempotency_keys
+			WHERE created_at < $1
+			ORDER BY created_at
+			LIMIT $2
+		)
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return int(deleted), nil
+}
+
+func (r *IdempotencyKeyRepository) FindByTransactionID(ctx context.Context,
+	transactionID string,
+) (model.IdempotencyKey, error) {
+	query := `
+		SELECT transaction_id, fingerprint, status_code, content_type, response_body, created_at
+		FROM idempotency_keys
+		WHERE transaction_id = $1
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.IdempotencyKey{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var (
+		idempotencyKey model.IdempotencyKey
+		fingerprint    sql.NullString
+		statusCode     sql.NullInt64
+	)
+
+	err = stmt.QueryRowContext(ctx, transactionID).Scan(&idempotencyKey.TransactionID, &fingerprint,
+		&statusCode, &idempotencyKey.ContentType, &idempotencyKey.ResponseBody, &idempotencyKey.CreatedAt)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "idempotency key",
+		}
+
+		return model.IdempotencyKey{}, fmt.Errorf("idempotency key not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.IdempotencyKey{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	idempotencyKey.Fingerprint = fingerprint.String
+	idempotencyKey.StatusCode = int(statusCode.Int64)
+
+	return idempotencyKey, nil
+}
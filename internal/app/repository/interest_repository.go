@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+)
+
+type InterestRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewInterestRepository(db *sql.DB, txPolicy db.TransactionPolicy) *InterestRepository {
+	return &InterestRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+func (r *InterestRepository) UpsertRate(ctx context.Context, rate *model.AccountInterestRate) error {
+	query := `
+		INSERT INTO account_interest_rates (account_id, rate, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (account_id) DO UPDATE SET rate = $2, updated_at = $4
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, rate.AccountID, rate.Rate, rate.CreatedAt, rate.UpdatedAt)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *InterestRepository) DeleteRate(ctx context.Context, accountID int64) error {
+	query := `DELETE FROM account_interest_rates WHERE account_id = $1`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, accountID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *InterestRepository) FindRateByAccountID(ctx context.Context,
+	accountID int64,
+) (model.AccountInterestRate, error) {
+	query := `
+		SELECT account_id, rate, created_at, updated_at
+		FROM account_interest_rates
+		WHERE account_id = $1
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.AccountInterestRate{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var rate model.AccountInterestRate
+
+	err = stmt.QueryRowContext(ctx, accountID).Scan(&rate.AccountID, &rate.Rate, &rate.CreatedAt, &rate.UpdatedAt)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "interest rate",
+		}
+
+		return model.AccountInterestRate{}, fmt.Errorf("interest rate not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.AccountInterestRate{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return rate, nil
+}
+
+// FindAllAccruable returns the accounts that are not closed, ordered by id, with their rate override and
+// last accrual date.
+func (r *InterestRepository) FindAllAccruable(ctx context.Context, afterID int64,
+	limit int,
+) ([]model.InterestAccount, error) {
+	query := `
+		SELECT a.id, a.type, a.created_at, r.rate,
+			(SELECT MAX(i.accrual_date) FROM interest_accruals i WHERE i.account_id = a.id)
+		FROM accounts a
+		LEFT JOIN account_interest_rates r ON r.account_id = a.id
+		WHERE a.status <> $1 AND a.id > $2
+		ORDER BY a.id
+		LIMIT $3
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, model.AccountStatusClosed, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var accounts []model.InterestAccount
+
+	for rows.Next() {
+		var (
+			account         model.InterestAccount
+			rate            decimal.NullDecimal
+			lastAccrualDate sql.NullTime
+		)
+
+		err = rows.Scan(&account.AccountID, &account.Type, &account.CreatedAt, &rate, &lastAccrualDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		account.RateOverride = decimalPointer(rate)
+
+		if lastAccrualDate.Valid {
+			date := model.AccrualDate(lastAccrualDate.Time)
+			account.LastAccrualDate = &date
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// CreateAccrualTx stores an accrual, it returns false when the date was already accrued.
+func (r *InterestRepository) CreateAccrualTx(ctx context.Context, dbTx *sql.Tx,
+	accrual *model.InterestAccrual,
+) (bool, error) {
+	if dbTx == nil {
+		return false, errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO interest_accruals (account_id, accrual_date, balance, rate, amount, transaction_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (account_id, accrual_date) DO NOTHING
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, accrual.AccountID, accrual.AccrualDate, accrual.Balance, accrual.Rate,
+		accrual.Amount, accrual.TransactionID, accrual.CreatedAt)
+	if err != nil {
+		err = r.mapError(err)
+
+		return false, fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// FindAllUnpostedAccountIDs returns the accounts having accruals before periodEnd that were not posted yet.
+func (r *InterestRepository) FindAllUnpostedAccountIDs(ctx context.Context, periodEnd time.Time,
+	limit int,
+) ([]int64, error) {
+	query := `
+		SELECT DISTINCT i.account_id
+		FROM interest_accruals i
+		JOIN accounts a ON a.id = i.account_id
+		WHERE i.posted_at IS NULL AND i.accrual_date < $1 AND a.status <> $2
+		ORDER BY i.account_id
+		LIMIT $3
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, periodEnd, model.AccountStatusClosed, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var accountIDs []int64
+
+	for rows.Next() {
+		var accountID int64
+		if err := rows.Scan(&accountID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		accountIDs = append(accountIDs, accountID)
+	}
+
+	return accountIDs, nil
+}
+
+// PostAccrualsTx marks the unposted accruals of an account before periodEnd as posted and returns their total
+// and count.
+func (r *InterestRepository) PostAccrualsTx(ctx context.Context, dbTx *sql.Tx, accountID int64,
+	periodEnd time.Time, postingTransactionID string, postedAt time.Time,
+) (decimal.Decimal, int, error) {
+	if dbTx == nil {
+		return decimal.Zero, 0, errors.New("transaction is nil")
+	}
+
+	query := `
+		WITH posted AS (
+			UPDATE interest_accruals SET posting_transaction_id = $3, posted_at = $4
+			WHERE account_id = $1 AND accrual_date < $2 AND posted_at IS NULL
+			RETURNING amount
+		)
+		SELECT COALESCE(SUM(amount), 0), COUNT(*) FROM posted
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return decimal.Zero, 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var (
+		total decimal.Decimal
+		count int
+	)
+
+	err = stmt.QueryRowContext(ctx, accountID, periodEnd, postingTransactionID, postedAt).Scan(&total, &count)
+	if err != nil {
+		err = r.mapError(err)
+
+		return decimal.Zero, 0, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return total, count, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+)
+
+type LedgerRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewLedgerRepository(db *sql.DB, txPolicy db.TransactionPolicy) *LedgerRepository {
+	return &LedgerRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+// CreateJournalEntryTx inserts a journal entry and its postings, the database rejects an unbalanced entry when the
+// transaction commits.
+func (r *LedgerRepository) CreateJournalEntryTx(ctx context.Context, dbTx *sql.Tx, entry *model.JournalEntry) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO journal_entries (transaction_id, created_at)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, entry.TransactionID, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	query = `
+		INSERT INTO postings (journal_entry_id, account_id, direction, amount)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	postingStmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer postingStmt.Close()
+
+	for _, posting := range entry.Postings {
+		_, err = postingStmt.ExecContext(ctx, entry.ID, posting.AccountID, posting.Direction, posting.Amount)
+		if err != nil {
+			err = r.mapError(err)
+
+			return fmt.Errorf("failed to exec statement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindTrialBalance sums the debit and credit postings of every account.
+func (r *LedgerRepository) FindTrialBalance(ctx context.Context) ([]model.TrialBalanceAccount, error) {
+	query := `
+		SELECT account_id,
+			COALESCE(SUM(amount) FILTER (WHERE direction = 'debit'), 0),
+			COALESCE(SUM(amount) FILTER (WHERE direction = 'credit'), 0)
+		FROM postings
+		GROUP BY account_id
+		ORDER BY account_id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var accounts []model.TrialBalanceAccount
+
+	for rows.Next() {
+		var account model.TrialBalanceAccount
+
+		if err := rows.Scan(&account.AccountID, &account.Debits, &account.Credits); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
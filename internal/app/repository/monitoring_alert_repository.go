@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/lib/pq"
+)
+
+const monitoringAlertColumns = `id, detector, account_id, reason, amount, event_ids, window_start, window_end,
+	status, note, reviewed_by, reviewed_at, created_at, updated_at`
+
+type MonitoringAlertRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewMonitoringAlertRepository(db *sql.DB, txPolicy db.TransactionPolicy) *MonitoringAlertRepository {
+	return &MonitoringAlertRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+func (r *MonitoringAlertRepository) Create(ctx context.Context, alert *model.MonitoringAlert) error {
+	query := `
+		INSERT INTO monitoring_alerts (detector, account_id, reason, amount, event_ids, window_start, window_end,
+			status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, alert.Detector, alert.AccountID, alert.Reason, alert.Amount,
+		pq.Array(alert.EventIDs), alert.WindowStart, alert.WindowEnd, alert.Status, alert.CreatedAt,
+		alert.UpdatedAt).Scan(&alert.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTx records the review of an alert, the pattern it reports cannot be changed.
+func (r *MonitoringAlertRepository) UpdateTx(ctx context.Context, dbTx *sql.Tx,
+	alert *model.MonitoringAlert,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		UPDATE monitoring_alerts
+		SET status = $1, note = $2, reviewed_by = $3, reviewed_at = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, alert.Status, alert.Note, alert.ReviewedBy, alert.ReviewedAt, alert.UpdatedAt,
+		alert.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MonitoringAlertRepository) FindByID(ctx context.Context, id int64) (model.MonitoringAlert, error) {
+	query := `SELECT ` + monitoringAlertColumns + ` FROM monitoring_alerts WHERE id = $1`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.MonitoringAlert{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, id))
+}
+
+func (r *MonitoringAlertRepository) FindByIDForUpdateTx(ctx context.Context, dbTx *sql.Tx,
+	id int64,
+) (model.MonitoringAlert, error) {
+	if dbTx == nil {
+		return model.MonitoringAlert{}, errors.New("transaction is nil")
+	}
+
+	query := `SELECT ` + monitoringAlertColumns + ` FROM monitoring_alerts WHERE id = $1 FOR UPDATE`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.MonitoringAlert{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, id))
+}
+
+// FindAll returns a page of the alerts, newest first.
+func (r *MonitoringAlertRepository) FindAll(ctx context.Context,
+	filter model.MonitoringAlertFilter,
+) ([]model.MonitoringAlert, error) {
+	query := `SELECT ` + monitoringAlertColumns + ` FROM monitoring_alerts
+		WHERE ($1 = '' OR status = $1) AND ($2 = '' OR detector = $2) AND ($3 = 0 OR account_id = $3)
+			AND ($4 = 0 OR id < $4)
+		ORDER BY id DESC
+		LIMIT $5`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.queryAll(ctx, stmt, filter.Status, filter.Detector, filter.AccountID, filter.BeforeID, filter.Limit)
+}
+
+// FindAllSince returns the alerts whose window ends at or after since, whatever their status, the alerts a scan
+// starting at since can find again.
+func (r *MonitoringAlertRepository) FindAllSince(ctx context.Context,
+	since time.Time,
+) ([]model.MonitoringAlert, error) {
+	query := `SELECT ` + monitoringAlertColumns + ` FROM monitoring_alerts WHERE window_end >= $1 ORDER BY id`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.queryAll(ctx, stmt, since)
+}
+
+func (r *MonitoringAlertRepository) queryAll(ctx context.Context, stmt *sql.Stmt,
+	args ...interface{},
+) ([]model.MonitoringAlert, error) {
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var alerts []model.MonitoringAlert
+
+	for rows.Next() {
+		alert, err := scanMonitoringAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
+
+func (r *MonitoringAlertRepository) scanOne(row *sql.Row) (model.MonitoringAlert, error) {
+	alert, err := scanMonitoringAlert(row)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "monitoring alert",
+		}
+
+		return model.MonitoringAlert{}, fmt.Errorf("monitoring alert not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.MonitoringAlert{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return alert, nil
+}
+
+func scanMonitoringAlert(row rowScanner) (model.MonitoringAlert, error) {
+	var (
+		alert      model.MonitoringAlert
+		eventIDs   pq.Int64Array
+		reviewedBy sql.NullString
+		reviewedAt sql.NullTime
+	)
+
+	err := row.Scan(&alert.ID, &alert.Detector, &alert.AccountID, &alert.Reason, &alert.Amount, &eventIDs,
+		&alert.WindowStart, &alert.WindowEnd, &alert.Status, &alert.Note, &reviewedBy, &reviewedAt,
+		&alert.CreatedAt, &alert.UpdatedAt)
+	if err != nil {
+		return model.MonitoringAlert{}, err //nolint:wrapcheck
+	}
+
+	alert.EventIDs = eventIDs
+
+	if reviewedBy.Valid {
+		alert.ReviewedBy = &reviewedBy.String
+	}
+
+	if reviewedAt.Valid {
+		alert.ReviewedAt = &reviewedAt.Time
+	}
+
+	return alert, nil
+}
@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+)
+
+const paymentBatchColumns = `id, transaction_id, message_id, initiating_party, number_of_transactions, control_sum,
+	status, reason_code, reason, created_at, updated_at`
+
+const paymentInstructionColumns = `id, batch_id, sequence, payment_information_id, instruction_id, end_to_end_id,
+	source_account_id, destination_account_id, amount, currency, execute_at, status, reason_code, reason,
+	created_at, updated_at`
+
+type PaymentBatchRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewPaymentBatchRepository(db *sql.DB, txPolicy db.TransactionPolicy) *PaymentBatchRepository {
+	return &PaymentBatchRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+// CreateTx inserts a payment batch and its instructions.
+func (r *PaymentBatchRepository) CreateTx(ctx context.Context, dbTx *sql.Tx, batch *model.PaymentBatch) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO payment_batches (transaction_id, message_id, initiating_party, number_of_transactions,
+			control_sum, status, reason_code, reason, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, batch.TransactionID, batch.MessageID, batch.InitiatingParty,
+		batch.NumberOfTransactions, batch.ControlSum, batch.Status, batch.ReasonCode, batch.Reason,
+		batch.CreatedAt, batch.UpdatedAt).Scan(&batch.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	query = `
+		INSERT INTO payment_instructions (batch_id, sequence, payment_information_id, instruction_id, end_to_end_id,
+			source_account_id, destination_account_id, amount, currency, execute_at, status, reason_code, reason,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id
+	`
+
+	instructionStmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer instructionStmt.Close()
+
+	for i := range batch.Instructions {
+		instruction := &batch.Instructions[i]
+		instruction.BatchID = batch.ID
+
+		err = instructionStmt.QueryRowContext(ctx, instruction.BatchID, instruction.Sequence,
+			instruction.PaymentInformationID, instruction.InstructionID, instruction.EndToEndID,
+			instruction.SourceAccountID, instruction.DestinationAccountID, instruction.Amount, instruction.Currency,
+			instruction.ExecuteAt, instruction.Status, instruction.ReasonCode, instruction.Reason,
+			instruction.CreatedAt, instruction.UpdatedAt).Scan(&instruction.ID)
+		if err != nil {
+			err = r.mapError(err)
+
+			return fmt.Errorf("failed to exec statement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateTx updates the status of a payment batch, its instructions are updated by UpdateInstructionTx.
+func (r *PaymentBatchRepository) UpdateTx(ctx context.Context, dbTx *sql.Tx, batch *model.PaymentBatch) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		UPDATE payment_batches
+		SET status = $1, reason_code = $2, reason = $3, updated_at = $4
+		WHERE id = $5
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, batch.Status, batch.ReasonCode, batch.Reason, batch.UpdatedAt, batch.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PaymentBatchRepository) UpdateInstructionTx(ctx context.Context, dbTx *sql.Tx,
+	instruction *model.PaymentInstruction,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		UPDATE payment_instructions
+		SET status = $1, reason_code = $2, reason = $3, updated_at = $4
+		WHERE id = $5
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, instruction.Status, instruction.ReasonCode, instruction.Reason,
+		instruction.UpdatedAt, instruction.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID returns a payment batch with its instructions in sequence order.
+func (r *PaymentBatchRepository) FindByID(ctx context.Context, id int64) (model.PaymentBatch, error) {
+	query := `SELECT ` + paymentBatchColumns + ` FROM payment_batches WHERE id = $1`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.PaymentBatch{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	batch, err := r.scanOne(stmt.QueryRowContext(ctx, id))
+	if err != nil {
+		return model.PaymentBatch{}, err
+	}
+
+	batch.Instructions, err = r.findAllInstructions(ctx, id)
+	if err != nil {
+		return model.PaymentBatch{}, err
+	}
+
+	return batch, nil
+}
+
+// FindByMessageID returns a payment batch without its instructions.
+func (r *PaymentBatchRepository) FindByMessageID(ctx context.Context, messageID string) (model.PaymentBatch, error) {
+	query := `SELECT ` + paymentBatchColumns + ` FROM payment_batches WHERE message_id = $1`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.PaymentBatch{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, messageID))
+}
+
+// FindProcessingByIDForUpdateTx locks a payment batch that is processing, without its instructions. A batch
+// locked by another worker is skipped and reported as not found.
+func (r *PaymentBatchRepository) FindProcessingByIDForUpdateTx(ctx context.Context, dbTx *sql.Tx,
+	id int64,
+) (model.PaymentBatch, error) {
+	query := `SELECT ` + paymentBatchColumns + ` FROM payment_batches
+		WHERE id = $1 AND status = $2
+		FOR UPDATE SKIP LOCKED`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.PaymentBatch{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, id, model.PaymentBatchStatusProcessing))
+}
+
+// FindNextDueInstructionTx returns the pending instruction of a batch with the lowest sequence that is due.
+func (r *PaymentBatchRepository) FindNextDueInstructionTx(ctx context.Context, dbTx *sql.Tx, batchID int64,
+	now time.Time,
+) (model.PaymentInstruction, error) {
+	query := `SELECT ` + paymentInstructionColumns + ` FROM payment_instructions
+		WHERE batch_id = $1 AND status = $2 AND execute_at <= $3
+		ORDER BY sequence
+		LIMIT 1`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.PaymentInstruction{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	instruction, err := scanPaymentInstruction(stmt.QueryRowContext(ctx, batchID,
+		model.PaymentInstructionStatusPending, now))
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "payment instruction",
+		}
+
+		return model.PaymentInstruction{}, fmt.Errorf("payment instruction not found: %w", err)
+	}
+
+	if err != nil {
+		return model.PaymentInstruction{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return instruction, nil
+}
+
+// CountPendingInstructionsTx returns the number of instructions of a batch that are pending, due or not.
+func (r *PaymentBatchRepository) CountPendingInstructionsTx(ctx context.Context, dbTx *sql.Tx,
+	batchID int64,
+) (int, error) {
+	query := `SELECT COUNT(*) FROM payment_instructions WHERE batch_id = $1 AND status = $2`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var count int
+
+	err = stmt.QueryRowContext(ctx, batchID, model.PaymentInstructionStatusPending).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindAllDueBatchIDs returns the batches with a pending instruction that is due, the batch with the oldest due
+// instruction first.
+func (r *PaymentBatchRepository) FindAllDueBatchIDs(ctx context.Context, now time.Time,
+	limit int,
+) ([]int64, error) {
+	query := `
+		SELECT batch_id
+		FROM payment_instructions
+		WHERE status = $1 AND execute_at <= $2
+		GROUP BY batch_id
+		ORDER BY MIN(execute_at)
+		LIMIT $3
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, model.PaymentInstructionStatusPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var batchIDs []int64
+
+	for rows.Next() {
+		var batchID int64
+
+		if err := rows.Scan(&batchID); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		batchIDs = append(batchIDs, batchID)
+	}
+
+	return batchIDs, nil
+}
+
+func (r *PaymentBatchRepository) findAllInstructions(ctx context.Context,
+	batchID int64,
+) ([]model.PaymentInstruction, error) {
+	query := `SELECT ` + paymentInstructionColumns + ` FROM payment_instructions
+		WHERE batch_id = $1
+		ORDER BY sequence`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var instructions []model.PaymentInstruction
+
+	for rows.Next() {
+		instruction, err := scanPaymentInstruction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		instructions = append(instructions, instruction)
+	}
+
+	return instructions, nil
+}
+
+func (r *PaymentBatchRepository) scanOne(row *sql.Row) (model.PaymentBatch, error) {
+	var batch model.PaymentBatch
+
+	err := row.Scan(&batch.ID, &batch.TransactionID, &batch.MessageID, &batch.InitiatingParty,
+		&batch.NumberOfTransactions, &batch.ControlSum, &batch.Status, &batch.ReasonCode, &batch.Reason,
+		&batch.CreatedAt, &batch.UpdatedAt)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "payment batch",
+		}
+
+		return model.PaymentBatch{}, fmt.Errorf("payment batch not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.PaymentBatch{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return batch, nil
+}
+
+func scanPaymentInstruction(row rowScanner) (model.PaymentInstruction, error) {
+	var instruction model.PaymentInstruction
+
+	err := row.Scan(&instruction.ID, &instruction.BatchID, &instruction.Sequence,
+		&instruction.PaymentInformationID, &instruction.InstructionID, &instruction.EndToEndID,
+		&instruction.SourceAccountID, &instruction.DestinationAccountID, &instruction.Amount, &instruction.Currency,
+		&instruction.ExecuteAt, &instruction.Status, &instruction.ReasonCode, &instruction.Reason,
+		&instruction.CreatedAt, &instruction.UpdatedAt)
+	if err != nil {
+		return model.PaymentInstruction{}, err //nolint:wrapcheck
+	}
+
+	return instruction, nil
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+)
+
+type ReconciliationRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewReconciliationRepository(db *sql.DB, txPolicy db.TransactionPolicy) *ReconciliationRepository {
+	return &ReconciliationRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+// CreateTx inserts a reconciliation and its items.
+func (r *ReconciliationRepository) CreateTx(ctx context.Context, dbTx *sql.Tx,
+	reconciliation *model.Reconciliation,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO reconciliations (file_name, format, statement_from, statement_to, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, reconciliation.FileName, reconciliation.Format, reconciliation.StatementFrom,
+		reconciliation.StatementTo, reconciliation.CreatedAt).Scan(&reconciliation.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	query = `
+		INSERT INTO reconciliation_items (reconciliation_id, status, match_rule, bank_booking_date, bank_amount,
+			bank_reference, bank_description, event_id, transaction_id, account_id, ledger_amount, ledger_created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+
+	itemStmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer itemStmt.Close()
+
+	for i := range reconciliation.Items {
+		item := &reconciliation.Items[i]
+		line, movement := itemColumns(*item)
+
+		err = itemStmt.QueryRowContext(ctx, reconciliation.ID, item.Status, item.MatchRule, line.BookingDate,
+			line.Amount, line.Reference, line.Description, movement.EventID, movement.TransactionID,
+			movement.AccountID, movement.Amount, movement.CreatedAt).Scan(&item.ID)
+		if err != nil {
+			err = r.mapError(err)
+
+			return fmt.Errorf("failed to exec statement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// FindByID returns a reconciliation with its items, in the order they were reported.
+func (r *ReconciliationRepository) FindByID(ctx context.Context, id int64) (model.Reconciliation, error) {
+	query := `
+		SELECT id, file_name, format, statement_from, statement_to, created_at
+		FROM reconciliations
+		WHERE id = $1
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.Reconciliation{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var reconciliation model.Reconciliation
+
+	err = stmt.QueryRowContext(ctx, id).Scan(&reconciliation.ID, &reconciliation.FileName, &reconciliation.Format,
+		&reconciliation.StatementFrom, &reconciliation.StatementTo, &reconciliation.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "reconciliation",
+		}
+
+		return model.Reconciliation{}, fmt.Errorf("reconciliation not found: %w", err)
+	}
+
+	if err != nil {
+		return model.Reconciliation{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	reconciliation.Items, err = r.findAllItems(ctx, id)
+	if err != nil {
+		return model.Reconciliation{}, err
+	}
+
+	return reconciliation, nil
+}
+
+func (r *ReconciliationRepository) findAllItems(ctx context.Context,
+	reconciliationID int64,
+) ([]model.ReconciliationItem, error) {
+	query := `
+		SELECT id, status, match_rule, bank_booking_date, bank_amount, bank_reference, bank_description, event_id,
+			transaction_id, account_id, ledger_amount, ledger_created_at
+		FROM reconciliation_items
+		WHERE reconciliation_id = $1
+		ORDER BY id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, reconciliationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var items []model.ReconciliationItem
+
+	for rows.Next() {
+		var (
+			item     model.ReconciliationItem
+			line     bankLineColumns
+			movement bankMovementColumns
+		)
+
+		err = rows.Scan(&item.ID, &item.Status, &item.MatchRule, &line.BookingDate, &line.Amount,
+			&line.Reference, &line.Description, &movement.EventID, &movement.TransactionID, &movement.AccountID,
+			&movement.Amount, &movement.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		if line.BookingDate.Valid {
+			item.BankLine = &model.BankLine{
+				BookingDate: line.BookingDate.Time,
+				Amount:      line.Amount.Decimal,
+				Reference:   line.Reference.String,
+				Description: line.Description.String,
+			}
+		}
+
+		if movement.EventID.Valid {
+			item.Movement = &model.BankMovement{
+				EventID:       movement.EventID.Int64,
+				TransactionID: movement.TransactionID.String,
+				AccountID:     movement.AccountID.Int64,
+				Amount:        movement.Amount.Decimal,
+				CreatedAt:     movement.CreatedAt.Time,
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// bankLineColumns and bankMovementColumns are the nullable columns of an item, the bank line columns are null for
+// a movement left unmatched and the other way around.
+type bankLineColumns struct {
+	BookingDate sql.NullTime
+	Amount      decimal.NullDecimal
+	Reference   sql.NullString
+	Description sql.NullString
+}
+
+type bankMovementColumns struct {
+	EventID       sql.NullInt64
+	TransactionID sql.NullString
+	AccountID     sql.NullInt64
+	Amount        decimal.NullDecimal
+	CreatedAt     sql.NullTime
+}
+
+func itemColumns(item model.ReconciliationItem) (bankLineColumns, bankMovementColumns) {
+	var (
+		line     bankLineColumns
+		movement bankMovementColumns
+	)
+
+	if item.BankLine != nil {
+		line = bankLineColumns{
+			BookingDate: sql.NullTime{Time: item.BankLine.BookingDate, Valid: true},
+			Amount:      decimal.NewNullDecimal(item.BankLine.Amount),
+			Reference:   sql.NullString{String: item.BankLine.Reference, Valid: true},
+			Description: sql.NullString{String: item.BankLine.Description, Valid: true},
+		}
+	}
+
+	if item.Movement != nil {
+		movement = bankMovementColumns{
+			EventID:       sql.NullInt64{Int64: item.Movement.EventID, Valid: true},
+			TransactionID: sql.NullString{String: item.Movement.TransactionID, Valid: true},
+			AccountID:     sql.NullInt64{Int64: item.Movement.AccountID, Valid: true},
+			Amount:        decimal.NewNullDecimal(item.Movement.Amount),
+			CreatedAt:     sql.NullTime{Time: item.Movement.CreatedAt, Valid: true},
+		}
+	}
+
+	return line, movement
+}
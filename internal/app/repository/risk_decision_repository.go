@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+)
+
+type RiskDecisionRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewRiskDecisionRepository(db *sql.DB, txPolicy db.TransactionPolicy) *RiskDecisionRepository {
+	return &RiskDecisionRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+// UpsertTx stores the decision of the risk rules on a transfer. The decision of a transfer retried after it failed
+// replaces the decision of the earlier attempt, whose event is kept.
+func (r *RiskDecisionRepository) UpsertTx(ctx context.Context, dbTx *sql.Tx, riskDecision *model.RiskDecision) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO risk_decisions (transaction_id, source_account_id, destination_account_id, amount, action, rule,
+			created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (transaction_id) DO UPDATE SET source_account_id = EXCLUDED.source_account_id,
+			destination_account_id = EXCLUDED.destination_account_id, amount = EXCLUDED.amount,
+			action = EXCLUDED.action, rule = EXCLUDED.rule, created_at = EXCLUDED.created_at
+		RETURNING id
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, riskDecision.TransactionID, riskDecision.SourceAccountID,
+		riskDecision.DestinationAccountID, riskDecision.Amount, riskDecision.Action, riskDecision.Rule,
+		riskDecision.CreatedAt).Scan(&riskDecision.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+)
+
+type SanctionsRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewSanctionsRepository(db *sql.DB, txPolicy db.TransactionPolicy) *SanctionsRepository {
+	return &SanctionsRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+// CreateList records a loaded version of the sanctions list, a list loaded again, e.g. on a restart, is recorded
+// once.
+func (r *SanctionsRepository) CreateList(ctx context.Context, list *model.SanctionsList) error {
+	query := `
+		INSERT INTO sanctions_lists (version, checksum, format, source, entries, loaded_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (checksum) DO NOTHING
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, list.Version, list.Checksum, list.Format, list.Source, list.Entries,
+		list.LoadedAt)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertScreeningTx stores the hits of the sanctions screening of a transfer. The screening of a transfer screened
+// again when it is approved replaces the earlier screening, whose event is kept.
+func (r *SanctionsRepository) UpsertScreeningTx(ctx context.Context, dbTx *sql.Tx,
+	screening *model.SanctionsScreening,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO sanctions_screenings (transaction_id, source_account_id, destination_account_id, amount, action,
+			list_version, hits, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (transaction_id) DO UPDATE SET source_account_id = EXCLUDED.source_account_id,
+			destination_account_id = EXCLUDED.destination_account_id, amount = EXCLUDED.amount,
+			action = EXCLUDED.action, list_version = EXCLUDED.list_version, hits = EXCLUDED.hits,
+			created_at = EXCLUDED.created_at
+		RETURNING id
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	hits, err := json.Marshal(screening.Hits)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hits: %w", err)
+	}
+
+	err = stmt.QueryRowContext(ctx, screening.TransactionID, screening.SourceAccountID,
+		screening.DestinationAccountID, screening.Amount, screening.Action, screening.ListVersion, hits,
+		screening.CreatedAt).Scan(&screening.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
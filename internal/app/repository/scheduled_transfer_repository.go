@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+)
+
+type ScheduledTransferRepository struct {
+	db *sql.DB
+	errorMapper
+}
+
+func NewScheduledTransferRepository(db *sql.DB) *ScheduledTransferRepository {
+	return &ScheduledTransferRepository{
+		db: db,
+	}
+}
+
+func (r *ScheduledTransferRepository) Create(ctx context.Context, scheduledTransfer *model.ScheduledTransfer) error {
+	query := `
+		INSERT INTO scheduled_transfers (transaction_id, source_account_id, destination_account_id,
+			amount, execute_at, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx,
+		scheduledTransfer.TransactionID, scheduledTransfer.SourceAccountID, scheduledTransfer.DestinationAccountID,
+		scheduledTransfer.Amount, scheduledTransfer.ExecuteAt, scheduledTransfer.Status,
+		scheduledTransfer.CreatedAt, scheduledTransfer.UpdatedAt).Scan(&scheduledTransfer.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ScheduledTransferRepository) FindByID(ctx context.Context, id int64) (model.ScheduledTransfer, error) {
+	query := `
+		SELECT id, transaction_id, source_account_id, destination_account_id, amount, execute_at,
+			status, failure_reason, executed_at, created_at, updated_at
+		FROM scheduled_transfers
+		WHERE id = $1
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.ScheduledTransfer{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	scheduledTransfer, err := scanScheduledTransfer(stmt.QueryRowContext(ctx, id))
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "scheduled transfer",
+		}
+
+		return model.ScheduledTransfer{}, fmt.Errorf("scheduled transfer not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.ScheduledTransfer{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return scheduledTransfer, nil
+}
+
+// FindAllDue returns pending instructions whose execution time has passed, together with
+// instructions left in processing state by a scheduler that stopped before finishing them.
+func (r *ScheduledTransferRepository) FindAllDue(ctx context.Context, now time.Time,
+	staleBefore time.Time, limit int,
+) ([]model.ScheduledTransfer, error) {
+	query := `
+		SELECT id, transaction_id, source_account_id, destination_account_id, amount, execute_at,
+			status, failure_reason, executed_at, created_at, updated_at
+		FROM scheduled_transfers
+		WHERE (status = $1 AND execute_at <= $2) OR (status = $3 AND updated_at <= $4)
+		ORDER BY execute_at
+		LIMIT $5
+	`
+
+	return r.findAll(ctx, query, model.ScheduledTransferStatusPending, now,
+		model.ScheduledTransferStatusProcessing, staleBefore, limit)
+}
+
+// FindAllAwaitingApproval returns the instructions whose transfer waits for a second person to approve it.
+func (r *ScheduledTransferRepository) FindAllAwaitingApproval(ctx context.Context,
+	limit int,
+) ([]model.ScheduledTransfer, error) {
+	query := `
+		SELECT id, transaction_id, source_account_id, destination_account_id, amount, execute_at,
+			status, failure_reason, executed_at, created_at, updated_at
+		FROM scheduled_transfers
+		WHERE status = $1
+		ORDER BY updated_at
+		LIMIT $2
+	`
+
+	return r.findAll(ctx, query, model.ScheduledTransferStatusAwaitingApproval, limit)
+}
+
+func (r *ScheduledTransferRepository) findAll(ctx context.Context, query string,
+	args ...interface{},
+) ([]model.ScheduledTransfer, error) {
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var scheduledTransfers []model.ScheduledTransfer
+
+	for rows.Next() {
+		scheduledTransfer, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		scheduledTransfers = append(scheduledTransfers, scheduledTransfer)
+	}
+
+	return scheduledTransfers, nil
+}
+
+// Claim moves a due instruction into processing state. It returns false when another
+// scheduler or a cancellation changed the instruction in the meantime.
+func (r *ScheduledTransferRepository) Claim(ctx context.Context, id int64,
+	now time.Time, staleBefore time.Time,
+) (bool, error) {
+	query := `
+		UPDATE scheduled_transfers
+		SET status = $1, updated_at = $2
+		WHERE id = $3 AND (status = $4 OR (status = $1 AND updated_at <= $5))
+	`
+
+	return r.exec(ctx, query, model.ScheduledTransferStatusProcessing, now, id,
+		model.ScheduledTransferStatusPending, staleBefore)
+}
+
+// Complete records the outcome of an execution for a claimed instruction.
+func (r *ScheduledTransferRepository) Complete(ctx context.Context, scheduledTransfer *model.ScheduledTransfer) error {
+	query := `
+		UPDATE scheduled_transfers
+		SET status = $1, failure_reason = $2, executed_at = $3, updated_at = $4
+		WHERE id = $5 AND status = $6
+	`
+
+	_, err := r.exec(ctx, query, scheduledTransfer.Status, scheduledTransfer.FailureReason,
+		scheduledTransfer.ExecutedAt, scheduledTransfer.UpdatedAt, scheduledTransfer.ID,
+		model.ScheduledTransferStatusProcessing)
+
+	return err
+}
+
+// SettleApproval records the outcome of an instruction whose transfer was approved, rejected or expired.
+func (r *ScheduledTransferRepository) SettleApproval(ctx context.Context,
+	scheduledTransfer *model.ScheduledTransfer,
+) error {
+	query := `
+		UPDATE scheduled_transfers
+		SET status = $1, failure_reason = $2, executed_at = $3, updated_at = $4
+		WHERE id = $5 AND status = $6
+	`
+
+	_, err := r.exec(ctx, query, scheduledTransfer.Status, scheduledTransfer.FailureReason,
+		scheduledTransfer.ExecutedAt, scheduledTransfer.UpdatedAt, scheduledTransfer.ID,
+		model.ScheduledTransferStatusAwaitingApproval)
+
+	return err
+}
+
+// Cancel cancels a pending instruction. It returns false when the instruction is not pending anymore.
+func (r *ScheduledTransferRepository) Cancel(ctx context.Context, id int64, now time.Time) (bool, error) {
+	query := `
+		UPDATE scheduled_transfers
+		SET status = $1, updated_at = $2
+		WHERE id = $3 AND status = $4
+	`
+
+	return r.exec(ctx, query, model.ScheduledTransferStatusCancelled, now, id,
+		model.ScheduledTransferStatusPending)
+}
+
+func (r *ScheduledTransferRepository) exec(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return false, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		err = r.mapError(err)
+
+		return false, fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func scanScheduledTransfer(row rowScanner) (model.ScheduledTransfer, error) {
+	var (
+		scheduledTransfer model.ScheduledTransfer
+		executedAt        sql.NullTime
+	)
+
+	err := row.Scan(&scheduledTransfer.ID, &scheduledTransfer.TransactionID,
+		&scheduledTransfer.SourceAccountID, &scheduledTransfer.DestinationAccountID,
+		&scheduledTransfer.Amount, &scheduledTransfer.ExecuteAt, &scheduledTransfer.Status,
+		&scheduledTransfer.FailureReason, &executedAt, &scheduledTransfer.CreatedAt, &scheduledTransfer.UpdatedAt)
+	if err != nil {
+		return model.ScheduledTransfer{}, err //nolint:wrapcheck
+	}
+
+	if executedAt.Valid {
+		scheduledTransfer.ExecutedAt = &executedAt.Time
+	}
+
+	return scheduledTransfer, nil
+}
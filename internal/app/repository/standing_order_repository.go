@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+)
+
+const standingOrderColumns = `id, transaction_id, source_account_id, destination_account_id, amount, recurrence,
+	start_at, end_at, max_occurrences, occurrence, executed_count, attempt, occurrence_at, next_run_at,
+	status, created_at, updated_at`
+
+type StandingOrderRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewStandingOrderRepository(db *sql.DB, txPolicy db.TransactionPolicy) *StandingOrderRepository {
+	return &StandingOrderRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+func (r *StandingOrderRepository) CreateTx(ctx context.Context, dbTx *sql.Tx, standingOrder *model.StandingOrder) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO standing_orders (transaction_id, source_account_id, destination_account_id, amount, recurrence,
+			start_at, end_at, max_occurrences, occurrence, executed_count, attempt, occurrence_at, next_run_at,
+			status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, standingOrder.TransactionID, standingOrder.SourceAccountID,
+		standingOrder.DestinationAccountID, standingOrder.Amount, standingOrder.Recurrence, standingOrder.StartAt,
+		standingOrder.EndAt, standingOrder.MaxOccurrences, standingOrder.Occurrence, standingOrder.ExecutedCount,
+		standingOrder.Attempt, standingOrder.OccurrenceAt, standingOrder.NextRunAt, standingOrder.Status,
+		standingOrder.CreatedAt, standingOrder.UpdatedAt).Scan(&standingOrder.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *StandingOrderRepository) UpdateTx(ctx context.Context, dbTx *sql.Tx, standingOrder *model.StandingOrder) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		UPDATE standing_orders
+		SET occurrence = $1, executed_count = $2, attempt = $3, occurrence_at = $4, next_run_at = $5,
+			status = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, standingOrder.Occurrence, standingOrder.ExecutedCount, standingOrder.Attempt,
+		standingOrder.OccurrenceAt, standingOrder.NextRunAt, standingOrder.Status, standingOrder.UpdatedAt,
+		standingOrder.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *StandingOrderRepository) FindByID(ctx context.Context, id int64) (model.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders WHERE id = $1`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.StandingOrder{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, id))
+}
+
+func (r *StandingOrderRepository) FindByIDForUpdateTx(ctx context.Context,
+	dbTx *sql.Tx, id int64,
+) (model.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders WHERE id = $1 FOR UPDATE`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.StandingOrder{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, id))
+}
+
+// FindDueByIDForUpdateTx locks an active standing order that is due. Orders locked by another
+// worker are skipped and reported as not found.
+func (r *StandingOrderRepository) FindDueByIDForUpdateTx(ctx context.Context,
+	dbTx *sql.Tx, id int64, now time.Time,
+) (model.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders
+		WHERE id = $1 AND status = $2 AND next_run_at <= $3
+		FOR UPDATE SKIP LOCKED`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.StandingOrder{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, id, model.StandingOrderStatusActive, now))
+}
+
+func (r *StandingOrderRepository) FindAllDue(ctx context.Context, now time.Time,
+	limit int,
+) ([]model.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at
+		LIMIT $3`
+
+	return r.findAll(ctx, query, model.StandingOrderStatusActive, now, limit)
+}
+
+// FindAllAwaitingApproval returns the standing orders whose current occurrence waits for the approval of its
+// transfer.
+func (r *StandingOrderRepository) FindAllAwaitingApproval(ctx context.Context,
+	limit int,
+) ([]model.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders
+		WHERE status = $1
+		ORDER BY updated_at
+		LIMIT $2`
+
+	return r.findAll(ctx, query, model.StandingOrderStatusAwaitingApproval, limit)
+}
+
+func (r *StandingOrderRepository) findAll(ctx context.Context, query string,
+	args ...interface{},
+) ([]model.StandingOrder, error) {
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var standingOrders []model.StandingOrder
+
+	for rows.Next() {
+		standingOrder, err := scanStandingOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		standingOrders = append(standingOrders, standingOrder)
+	}
+
+	return standingOrders, nil
+}
+
+func (r *StandingOrderRepository) scanOne(row *sql.Row) (model.StandingOrder, error) {
+	standingOrder, err := scanStandingOrder(row)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "standing order",
+		}
+
+		return model.StandingOrder{}, fmt.Errorf("standing order not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.StandingOrder{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return standingOrder, nil
+}
+
+func scanStandingOrder(row rowScanner) (model.StandingOrder, error) {
+	var (
+		standingOrder model.StandingOrder
+		endAt         sql.NullTime
+	)
+
+	err := row.Scan(&standingOrder.ID, &standingOrder.TransactionID, &standingOrder.SourceAccountID,
+		&standingOrder.DestinationAccountID, &standingOrder.Amount, &standingOrder.Recurrence,
+		&standingOrder.StartAt, &endAt, &standingOrder.MaxOccurrences, &standingOrder.Occurrence,
+		&standingOrder.ExecutedCount, &standingOrder.Attempt, &standingOrder.OccurrenceAt,
+		&standingOrder.NextRunAt, &standingOrder.Status, &standingOrder.CreatedAt, &standingOrder.UpdatedAt)
+	if err != nil {
+		return model.StandingOrder{}, err //nolint:wrapcheck
+	}
+
+	if endAt.Valid {
+		standingOrder.EndAt = &endAt.Time
+	}
+
+	return standingOrder, nil
+}
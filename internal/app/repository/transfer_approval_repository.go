@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+)
+
+const transferApprovalColumns = `id, transaction_id, source_account_id, destination_account_id, amount, initiated_by,
+	reviewed_by, rejection_reason, status, expires_at, reviewed_at, created_at, updated_at`
+
+type TransferApprovalRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewTransferApprovalRepository(db *sql.DB, txPolicy db.TransactionPolicy) *TransferApprovalRepository {
+	return &TransferApprovalRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+func (r *TransferApprovalRepository) CreateTx(ctx context.Context, dbTx *sql.Tx,
+	transferApproval *model.TransferApproval,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO transfer_approvals (transaction_id, source_account_id, destination_account_id, amount,
+			initiated_by, status, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, transferApproval.TransactionID, transferApproval.SourceAccountID,
+		transferApproval.DestinationAccountID, transferApproval.Amount, transferApproval.InitiatedBy,
+		transferApproval.Status, transferApproval.ExpiresAt, transferApproval.CreatedAt,
+		transferApproval.UpdatedAt).Scan(&transferApproval.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TransferApprovalRepository) UpdateTx(ctx context.Context, dbTx *sql.Tx,
+	transferApproval *model.TransferApproval,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		UPDATE transfer_approvals
+		SET reviewed_by = $1, rejection_reason = $2, status = $3, reviewed_at = $4, updated_at = $5
+		WHERE id = $6
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, transferApproval.ReviewedBy, transferApproval.RejectionReason,
+		transferApproval.Status, transferApproval.ReviewedAt, transferApproval.UpdatedAt, transferApproval.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TransferApprovalRepository) FindByTransactionID(ctx context.Context,
+	transactionID string,
+) (model.TransferApproval, error) {
+	query := `SELECT ` + transferApprovalColumns + ` FROM transfer_approvals WHERE transaction_id = $1`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.TransferApproval{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, transactionID))
+}
+
+func (r *TransferApprovalRepository) FindByTransactionIDForUpdateTx(ctx context.Context, dbTx *sql.Tx,
+	transactionID string,
+) (model.TransferApproval, error) {
+	query := `SELECT ` + transferApprovalColumns + ` FROM transfer_approvals WHERE transaction_id = $1 FOR UPDATE`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.TransferApproval{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	return r.scanOne(stmt.QueryRowContext(ctx, transactionID))
+}
+
+// FindAllExpired returns the pending transfers whose approval window has passed.
+func (r *TransferApprovalRepository) FindAllExpired(ctx context.Context, now time.Time,
+	limit int,
+) ([]model.TransferApproval, error) {
+	query := `SELECT ` + transferApprovalColumns + ` FROM transfer_approvals
+		WHERE status = $1 AND expires_at <= $2
+		ORDER BY expires_at
+		LIMIT $3`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, model.TransferApprovalStatusPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var transferApprovals []model.TransferApproval
+
+	for rows.Next() {
+		transferApproval, err := scanTransferApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		transferApprovals = append(transferApprovals, transferApproval)
+	}
+
+	return transferApprovals, nil
+}
+
+func (r *TransferApprovalRepository) scanOne(row *sql.Row) (model.TransferApproval, error) {
+	transferApproval, err := scanTransferApproval(row)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "pending transfer",
+		}
+
+		return model.TransferApproval{}, fmt.Errorf("transfer approval not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.TransferApproval{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return transferApproval, nil
+}
+
+func scanTransferApproval(row rowScanner) (model.TransferApproval, error) {
+	var (
+		transferApproval model.TransferApproval
+		reviewedBy       sql.NullString
+		reviewedAt       sql.NullTime
+	)
+
+	err := row.Scan(&transferApproval.ID, &transferApproval.TransactionID, &transferApproval.SourceAccountID,
+		&transferApproval.DestinationAccountID, &transferApproval.Amount, &transferApproval.InitiatedBy,
+		&reviewedBy, &transferApproval.RejectionReason, &transferApproval.Status, &transferApproval.ExpiresAt,
+		&reviewedAt, &transferApproval.CreatedAt, &transferApproval.UpdatedAt)
+	if err != nil {
+		return model.TransferApproval{}, err //nolint:wrapcheck
+	}
+
+	if reviewedBy.Valid {
+		transferApproval.ReviewedBy = &reviewedBy.String
+	}
+
+	if reviewedAt.Valid {
+		transferApproval.ReviewedAt = &reviewedAt.Time
+	}
+
+	return transferApproval, nil
+}
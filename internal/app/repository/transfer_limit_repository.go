@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+)
+
+type TransferLimitRepository struct {
+	db *sql.DB
+	errorMapper
+}
+
+func NewTransferLimitRepository(db *sql.DB) *TransferLimitRepository {
+	return &TransferLimitRepository{
+		db: db,
+	}
+}
+
+func (r *TransferLimitRepository) Upsert(ctx context.Context, limit *model.AccountTransferLimit) error {
+	query := `
+		INSERT INTO account_transfer_limits (account_id, per_transaction, daily, monthly, hourly_count,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (account_id) DO UPDATE SET per_transaction = $2, daily = $3, monthly = $4,
+			hourly_count = $5, updated_at = $7
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var hourlyCount sql.NullInt64
+	if limit.HourlyCount != nil {
+		hourlyCount = sql.NullInt64{Int64: int64(*limit.HourlyCount), Valid: true}
+	}
+
+	_, err = stmt.ExecContext(ctx, limit.AccountID, nullDecimal(limit.PerTransaction), nullDecimal(limit.Daily),
+		nullDecimal(limit.Monthly), hourlyCount, limit.CreatedAt, limit.UpdatedAt)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TransferLimitRepository) FindByAccountID(ctx context.Context,
+	accountID int64,
+) (model.AccountTransferLimit, error) {
+	query := `
+		SELECT account_id, per_transaction, daily, monthly, hourly_count, created_at, updated_at
+		FROM account_transfer_limits
+		WHERE account_id = $1
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.AccountTransferLimit{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	var (
+		limit                          model.AccountTransferLimit
+		perTransaction, daily, monthly decimal.NullDecimal
+		hourlyCount                    sql.NullInt64
+	)
+
+	err = stmt.QueryRowContext(ctx, accountID).Scan(&limit.AccountID, &perTransaction, &daily, &monthly,
+		&hourlyCount, &limit.CreatedAt, &limit.UpdatedAt)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "transfer limit",
+		}
+
+		return model.AccountTransferLimit{}, fmt.Errorf("transfer limit not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.AccountTransferLimit{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	limit.PerTransaction = decimalPointer(perTransaction)
+	limit.Daily = decimalPointer(daily)
+	limit.Monthly = decimalPointer(monthly)
+
+	if hourlyCount.Valid {
+		count := int(hourlyCount.Int64)
+		limit.HourlyCount = &count
+	}
+
+	return limit, nil
+}
+
+func nullDecimal(value *decimal.Decimal) decimal.NullDecimal {
+	if value == nil {
+		return decimal.NullDecimal{}
+	}
+
+	return decimal.NewNullDecimal(*value)
+}
+
+func decimalPointer(value decimal.NullDecimal) *decimal.Decimal {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Decimal
+}
@@ -0,0 +1,578 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/db"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/lib/pq"
+)
+
+const webhookColumns = `id, url, secret, event_types, account_ids, status, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, delivered_at, created_at, updated_at`
+
+type WebhookRepository struct {
+	db *sql.DB
+	transactable
+	errorMapper
+}
+
+func NewWebhookRepository(db *sql.DB, txPolicy db.TransactionPolicy) *WebhookRepository {
+	return &WebhookRepository{
+		db:           db,
+		transactable: transactable{db: db, policy: txPolicy},
+	}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	query := `
+		INSERT INTO webhooks (url, secret, event_types, account_ids, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, webhook.URL, webhook.Secret, eventTypeArray(webhook.EventTypes),
+		pq.Array(webhook.AccountIDs), webhook.Status, webhook.CreatedAt, webhook.UpdatedAt).Scan(&webhook.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates the status of a webhook, its url, secret and filters cannot be changed.
+func (r *WebhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	query := `UPDATE webhooks SET status = $1, updated_at = $2 WHERE id = $3`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, webhook.Status, webhook.UpdatedAt, webhook.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) FindByID(ctx context.Context, id int64) (model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	webhook, err := scanWebhook(stmt.QueryRowContext(ctx, id))
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "webhook",
+		}
+
+		return model.Webhook{}, fmt.Errorf("webhook not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.Webhook{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// FindAll returns the webhooks in creation order, the disabled ones are only returned when status is empty.
+func (r *WebhookRepository) FindAll(ctx context.Context, status model.WebhookStatus) ([]model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks
+		WHERE $1 = '' OR status = $1
+		ORDER BY id`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var webhooks []model.Webhook
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// FindOutboxEventsForUpdateTx locks the oldest events waiting to be fanned out. Events locked by another worker are
+// skipped.
+func (r *WebhookRepository) FindOutboxEventsForUpdateTx(ctx context.Context, dbTx *sql.Tx,
+	limit int,
+) ([]model.Event, error) {
+	if dbTx == nil {
+		return nil, errors.New("transaction is nil")
+	}
+
+	query := `
+		SELECT e.id, e.transaction_id, e.aggregate_id, e.aggregate_type, e.event_type, e.sequence_number,
+			e.event_data, e.version, e.created_at
+		FROM webhook_outbox o
+		JOIN events e ON e.id = o.event_id
+		ORDER BY o.event_id
+		LIMIT $1
+		FOR UPDATE OF o SKIP LOCKED
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var events []model.Event
+
+	for rows.Next() {
+		var event model.Event
+
+		err = rows.Scan(&event.ID, &event.TransactionID, &event.AggregateID, &event.AggregateType,
+			&event.EventType, &event.SequenceNumber, &event.EventData, &event.Version, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// DeleteOutboxTx removes fanned out events from the outbox.
+func (r *WebhookRepository) DeleteOutboxTx(ctx context.Context, dbTx *sql.Tx, eventIDs []int64) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	stmt, err := dbTx.PrepareContext(ctx, `DELETE FROM webhook_outbox WHERE event_id = ANY($1)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, pq.Array(eventIDs))
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// CreateDeliveryTx inserts a delivery, an event already delivered to the webhook is ignored.
+func (r *WebhookRepository) CreateDeliveryTx(ctx context.Context, dbTx *sql.Tx,
+	delivery *model.WebhookDelivery,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT ON CONSTRAINT webhook_deliveries_webhook_event_key DO NOTHING
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, delivery.WebhookID, delivery.EventID, delivery.EventType,
+		string(delivery.Payload), delivery.Status, delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// FindAllDueDeliveryIDs returns the pending deliveries of the active webhooks whose next attempt is due, the oldest
+// first.
+func (r *WebhookRepository) FindAllDueDeliveryIDs(ctx context.Context, now time.Time,
+	limit int,
+) ([]int64, error) {
+	query := `
+		SELECT d.id
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = $1 AND d.next_attempt_at <= $2 AND w.status = $3
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT $4
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, model.WebhookDeliveryStatusPending, now, model.WebhookStatusActive, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// FindDueDeliveryByIDForUpdateTx locks a delivery that is still pending and due. A delivery locked by another worker
+// is skipped and reported as not found.
+func (r *WebhookRepository) FindDueDeliveryByIDForUpdateTx(ctx context.Context, dbTx *sql.Tx, id int64,
+	now time.Time,
+) (model.WebhookDelivery, error) {
+	if dbTx == nil {
+		return model.WebhookDelivery{}, errors.New("transaction is nil")
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE id = $1 AND status = $2 AND next_attempt_at <= $3
+		FOR UPDATE SKIP LOCKED`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return model.WebhookDelivery{}, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	delivery, err := scanWebhookDelivery(stmt.QueryRowContext(ctx, id, model.WebhookDeliveryStatusPending, now))
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "webhook delivery",
+		}
+
+		return model.WebhookDelivery{}, fmt.Errorf("webhook delivery not found: %w", err)
+	}
+
+	if err != nil {
+		err = r.mapError(err)
+
+		return model.WebhookDelivery{}, fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// UpdateDeliveryTx stores the outcome of an attempt of a delivery.
+func (r *WebhookRepository) UpdateDeliveryTx(ctx context.Context, dbTx *sql.Tx,
+	delivery *model.WebhookDelivery,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_status_code = $4, last_error = $5,
+			delivered_at = $6, updated_at = $7
+		WHERE id = $8
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		nullableStatusCode(delivery.LastStatusCode), delivery.LastError, delivery.DeliveredAt, delivery.UpdatedAt,
+		delivery.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// CreateAttemptTx appends an attempt to the delivery log.
+func (r *WebhookRepository) CreateAttemptTx(ctx context.Context, dbTx *sql.Tx,
+	attempt *model.WebhookDeliveryAttempt,
+) error {
+	if dbTx == nil {
+		return errors.New("transaction is nil")
+	}
+
+	query := `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	stmt, err := dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, attempt.DeliveryID, attempt.Attempt, nullableStatusCode(attempt.StatusCode),
+		attempt.Error, attempt.Duration.Milliseconds(), attempt.CreatedAt).Scan(&attempt.ID)
+	if err != nil {
+		err = r.mapError(err)
+
+		return fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	return nil
+}
+
+// FindAllDeliveries returns a page of the deliveries of a webhook, newest first, with their attempts.
+func (r *WebhookRepository) FindAllDeliveries(ctx context.Context,
+	filter model.WebhookDeliveryFilter,
+) ([]model.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2) AND ($3 = 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, filter.WebhookID, filter.Status, filter.BeforeID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := r.loadAttempts(ctx, deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Requeue makes deliveries of a webhook pending again with a new series of attempts. Without delivery ids, the
+// failed deliveries are requeued. It returns the number of deliveries requeued, a pending delivery is left as is.
+func (r *WebhookRepository) Requeue(ctx context.Context, webhookID int64, deliveryIDs []int64,
+	now time.Time,
+) (int, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = 0, next_attempt_at = $2, updated_at = $2
+		WHERE webhook_id = $3 AND status <> $1
+			AND ((cardinality($4::bigint[]) = 0 AND status = $5) OR id = ANY($4))
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, model.WebhookDeliveryStatusPending, now, webhookID,
+		pq.Array(deliveryIDs), model.WebhookDeliveryStatusFailed)
+	if err != nil {
+		err = r.mapError(err)
+
+		return 0, fmt.Errorf("failed to exec statement: %w", err)
+	}
+
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(requeued), nil
+}
+
+func (r *WebhookRepository) loadAttempts(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(deliveries))
+	byID := make(map[int64]*model.WebhookDelivery, len(deliveries))
+
+	for i := range deliveries {
+		ids = append(ids, deliveries[i].ID)
+		byID[deliveries[i].ID] = &deliveries[i]
+	}
+
+	query := `
+		SELECT id, delivery_id, attempt, status_code, error, duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY id
+	`
+
+	stmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to query statement: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			attempt    model.WebhookDeliveryAttempt
+			statusCode sql.NullInt64
+			durationMS int64
+		)
+
+		err = rows.Scan(&attempt.ID, &attempt.DeliveryID, &attempt.Attempt, &statusCode, &attempt.Error,
+			&durationMS, &attempt.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		attempt.StatusCode = int(statusCode.Int64)
+		attempt.Duration = time.Duration(durationMS) * time.Millisecond
+
+		delivery := byID[attempt.DeliveryID]
+		delivery.AttemptLog = append(delivery.AttemptLog, attempt)
+	}
+
+	return nil
+}
+
+func scanWebhook(row rowScanner) (model.Webhook, error) {
+	var (
+		webhook    model.Webhook
+		eventTypes []string
+		accountIDs pq.Int64Array
+	)
+
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, pq.Array(&eventTypes), &accountIDs,
+		&webhook.Status, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return model.Webhook{}, err //nolint:wrapcheck
+	}
+
+	for _, eventType := range eventTypes {
+		webhook.EventTypes = append(webhook.EventTypes, model.EventType(eventType))
+	}
+
+	webhook.AccountIDs = accountIDs
+
+	return webhook, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (model.WebhookDelivery, error) {
+	var (
+		delivery       model.WebhookDelivery
+		lastStatusCode sql.NullInt64
+		deliveredAt    sql.NullTime
+	)
+
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &lastStatusCode, &delivery.LastError,
+		&deliveredAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+	if err != nil {
+		return model.WebhookDelivery{}, err //nolint:wrapcheck
+	}
+
+	delivery.LastStatusCode = int(lastStatusCode.Int64)
+
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return delivery, nil
+}
+
+// nullableStatusCode stores a missing response as NULL.
+func nullableStatusCode(statusCode int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(statusCode), Valid: statusCode != 0}
+}
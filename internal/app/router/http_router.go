@@ -16,41 +16,292 @@ import (
 func MakeHTTPRouter(
 	endpts endpoint.Endpoint,
 	cfg config.Config,
+	idempotencyStore httptransport.IdempotencyStore,
+	signatureVerifier httptransport.SignatureVerifier,
+	authenticator httptransport.Authenticator,
 ) *chi.Mux {
 	// Initialize Router
 	router := chi.NewRouter()
 
+	// the requests carrying a transaction id, a retry is answered with the response of the first request
+	headerMiddlewares := chi.Middlewares{
+		httptransport.HeaderMiddleware(),
+		httptransport.IdempotencyMiddleware(idempotencyStore),
+	}
+
+	// scope is the scope a route requires, it is checked before the header middlewares so that a client without the
+	// scope never gets the response of a retried request
+	scope := func(scope httptransport.Scope) chi.Middlewares {
+		if authenticator == nil {
+			return chi.Middlewares{}
+		}
+
+		return chi.Middlewares{httptransport.RequireScope(scope)}
+	}
+
 	router.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 	})
 
 	router.Route("/", func(router chi.Router) {
-		router.Use(
+		middlewares := chi.Middlewares{
 			httptransport.LoggingMiddleware(slog.Default()),
 			httptransport.CORSMiddleware(cfg.HTTP.AllowedOrigin),
 			httptransport.Recoverer(slog.Default()),
-			render.SetContentType(render.ContentTypeJSON),
-		)
+		}
+
+		// every request must be signed by a known client once the client keys are configured
+		if signatureVerifier != nil {
+			middlewares = append(middlewares,
+				httptransport.SignatureMiddleware(signatureVerifier, cfg.Signature.Tolerance))
+		}
+
+		// every request must carry the bearer token of a client once the service tokens are configured
+		if authenticator != nil {
+			middlewares = append(middlewares, httptransport.AuthMiddleware(authenticator))
+		}
+
+		router.Use(append(middlewares, render.SetContentType(render.ContentTypeJSON))...)
 
 		router.Route("/accounts", func(router chi.Router) {
-			routerWithHeader := router.With(httptransport.HeaderMiddleware())
-			routerWithHeader.Post("/", httptransport.MakeHandlerFunc(
+			reader := router.With(scope(httptransport.ScopeAccountsRead)...)
+			writer := router.With(scope(httptransport.ScopeAccountsWrite)...).With(headerMiddlewares...)
+			writer.Post("/", httptransport.MakeHandlerFunc(
 				endpts.Account.Create,
 				httptransport.DecodeRequest[dto.CreateAccountRequest],
 				httptransport.CreatedResponse,
 			))
-			router.Get("/{id}", httptransport.MakeHandlerFunc(
+			reader.Get("/", httptransport.MakeHandlerFunc(
+				endpts.Account.List,
+				httptransport.DecodeRequest[dto.ListAccountsRequest],
+				httptransport.ResponseWithBody,
+			))
+			reader.Get("/{id}", httptransport.MakeHandlerFunc(
 				endpts.Account.Get,
 				httptransport.DecodeRequest[dto.GetAccountRequest],
 				httptransport.ResponseWithBody,
 			))
+			writer.Patch("/{id}", httptransport.MakeHandlerFunc(
+				endpts.Account.UpdateProfile,
+				httptransport.DecodeRequest[dto.UpdateAccountProfileRequest],
+				httptransport.NoContentResponse,
+			))
+			reader.Get("/{id}/statement", httptransport.MakeHandlerFunc(
+				endpts.Statement.Get,
+				httptransport.DecodeRequest[dto.StatementRequest],
+				httptransport.NegotiatedResponse,
+			))
 		})
 
 		router.Route("/transactions", func(router chi.Router) {
-			routerWithHeader := router.With(httptransport.HeaderMiddleware())
-			routerWithHeader.Post("/", httptransport.MakeHandlerFunc(
+			reader := router.With(scope(httptransport.ScopeTransfersRead)...)
+			reviewer := router.With(scope(httptransport.ScopeTransfersWrite)...)
+			writer := reviewer.With(headerMiddlewares...)
+			writer.Post("/", httptransport.MakeHandlerFunc(
 				endpts.Transaction.Transfer,
 				httptransport.DecodeRequest[dto.CreateTransferRequest],
+				httptransport.ResponseWithBody,
+			))
+			reader.Get("/{transaction_id}", httptransport.MakeHandlerFunc(
+				endpts.Transaction.Get,
+				httptransport.DecodeRequest[dto.GetTransactionRequest],
+				httptransport.ResponseWithBody,
+			))
+			reviewer.Post("/{transaction_id}/approve", httptransport.MakeHandlerFunc(
+				endpts.Transaction.Approve,
+				httptransport.DecodeRequest[dto.ReviewTransferRequest],
+				httptransport.ResponseWithBody,
+			))
+			reviewer.Post("/{transaction_id}/reject", httptransport.MakeHandlerFunc(
+				endpts.Transaction.Reject,
+				httptransport.DecodeRequest[dto.ReviewTransferRequest],
+				httptransport.NoContentResponse,
+			))
+
+			router.Route("/scheduled", func(router chi.Router) {
+				reader := router.With(scope(httptransport.ScopeTransfersRead)...)
+				canceller := router.With(scope(httptransport.ScopeTransfersWrite)...)
+				writer := canceller.With(headerMiddlewares...)
+				writer.Post("/", httptransport.MakeHandlerFunc(
+					endpts.ScheduledTransfer.Create,
+					httptransport.DecodeRequest[dto.CreateScheduledTransferRequest],
+					httptransport.CreatedResponseWithBody,
+				))
+				reader.Get("/{id}", httptransport.MakeHandlerFunc(
+					endpts.ScheduledTransfer.Get,
+					httptransport.DecodeRequest[dto.ScheduledTransferIDRequest],
+					httptransport.ResponseWithBody,
+				))
+				canceller.Delete("/{id}", httptransport.MakeHandlerFunc(
+					endpts.ScheduledTransfer.Cancel,
+					httptransport.DecodeRequest[dto.ScheduledTransferIDRequest],
+					httptransport.NoContentResponse,
+				))
+			})
+
+			router.Route("/standing-orders", func(router chi.Router) {
+				reader := router.With(scope(httptransport.ScopeTransfersRead)...)
+				writer := router.With(scope(httptransport.ScopeTransfersWrite)...).With(headerMiddlewares...)
+				writer.Post("/", httptransport.MakeHandlerFunc(
+					endpts.StandingOrder.Create,
+					httptransport.DecodeRequest[dto.CreateStandingOrderRequest],
+					httptransport.CreatedResponseWithBody,
+				))
+				reader.Get("/{id}", httptransport.MakeHandlerFunc(
+					endpts.StandingOrder.Get,
+					httptransport.DecodeRequest[dto.StandingOrderIDRequest],
+					httptransport.ResponseWithBody,
+				))
+				writer.Delete("/{id}", httptransport.MakeHandlerFunc(
+					endpts.StandingOrder.Cancel,
+					httptransport.DecodeRequest[dto.StandingOrderIDRequest],
+					httptransport.NoContentResponse,
+				))
+			})
+		})
+
+		router.With(scope(httptransport.ScopeAdmin)...).Get("/ledger/trial-balance", httptransport.MakeHandlerFunc(
+			endpts.Ledger.TrialBalance,
+			httptransport.DecodeRequest[dto.TrialBalanceRequest],
+			httptransport.ResponseWithBody,
+		))
+
+		router.Route("/reconciliations", func(router chi.Router) {
+			router.Use(scope(httptransport.ScopeAdmin)...)
+			router.Post("/", httptransport.MakeHandlerFunc(
+				endpts.Reconciliation.Import,
+				httptransport.DecodeRequest[dto.ImportReconciliationRequest],
+				httptransport.CreatedResponseWithBody,
+			))
+			router.Get("/{id}", httptransport.MakeHandlerFunc(
+				endpts.Reconciliation.Get,
+				httptransport.DecodeRequest[dto.GetReconciliationRequest],
+				httptransport.ResponseWithBody,
+			))
+		})
+
+		router.Route("/payment-batches", func(router chi.Router) {
+			reader := router.With(scope(httptransport.ScopeTransfersRead)...)
+			writer := router.With(scope(httptransport.ScopeTransfersWrite)...).With(headerMiddlewares...)
+			writer.Post("/", httptransport.MakeHandlerFunc(
+				endpts.PaymentBatch.Submit,
+				httptransport.DecodeRequest[dto.SubmitPaymentBatchRequest],
+				httptransport.CreatedXMLResponse,
+			))
+			reader.Get("/{id}", httptransport.MakeHandlerFunc(
+				endpts.PaymentBatch.Get,
+				httptransport.DecodeRequest[dto.PaymentBatchIDRequest],
+				httptransport.ResponseWithBody,
+			))
+			reader.Get("/{id}/status-report", httptransport.MakeHandlerFunc(
+				endpts.PaymentBatch.StatusReport,
+				httptransport.DecodeRequest[dto.PaymentBatchIDRequest],
+				httptransport.XMLResponse,
+			))
+		})
+
+		router.Route("/webhooks", func(router chi.Router) {
+			router.Use(scope(httptransport.ScopeAdmin)...)
+			router.Post("/", httptransport.MakeHandlerFunc(
+				endpts.Webhook.Create,
+				httptransport.DecodeRequest[dto.CreateWebhookRequest],
+				httptransport.CreatedResponseWithBody,
+			))
+			router.Get("/", httptransport.MakeHandlerFunc(
+				endpts.Webhook.List,
+				httptransport.DecodeRequest[dto.ListWebhooksRequest],
+				httptransport.ResponseWithBody,
+			))
+			router.Get("/{id}", httptransport.MakeHandlerFunc(
+				endpts.Webhook.Get,
+				httptransport.DecodeRequest[dto.WebhookIDRequest],
+				httptransport.ResponseWithBody,
+			))
+			router.Delete("/{id}", httptransport.MakeHandlerFunc(
+				endpts.Webhook.Delete,
+				httptransport.DecodeRequest[dto.WebhookIDRequest],
+				httptransport.NoContentResponse,
+			))
+			router.Get("/{id}/deliveries", httptransport.MakeHandlerFunc(
+				endpts.Webhook.Deliveries,
+				httptransport.DecodeRequest[dto.ListWebhookDeliveriesRequest],
+				httptransport.ResponseWithBody,
+			))
+			router.Post("/{id}/redeliver", httptransport.MakeHandlerFunc(
+				endpts.Webhook.Redeliver,
+				httptransport.DecodeRequest[dto.RedeliverWebhookRequest],
+				httptransport.ResponseWithBody,
+			))
+		})
+
+		router.Route("/monitoring/alerts", func(router chi.Router) {
+			router.Use(scope(httptransport.ScopeAdmin)...)
+			router.Get("/", httptransport.MakeHandlerFunc(
+				endpts.Monitoring.List,
+				httptransport.DecodeRequest[dto.ListMonitoringAlertsRequest],
+				httptransport.ResponseWithBody,
+			))
+			router.Get("/{id}", httptransport.MakeHandlerFunc(
+				endpts.Monitoring.Get,
+				httptransport.DecodeRequest[dto.MonitoringAlertIDRequest],
+				httptransport.ResponseWithBody,
+			))
+			router.Post("/{id}/disposition", httptransport.MakeHandlerFunc(
+				endpts.Monitoring.Disposition,
+				httptransport.DecodeRequest[dto.DispositionMonitoringAlertRequest],
+				httptransport.ResponseWithBody,
+			))
+		})
+
+		router.Route("/admin/accounts/{id}", func(router chi.Router) {
+			router.Use(scope(httptransport.ScopeAdmin)...)
+			router.Use(headerMiddlewares...)
+			router.Post("/freeze", httptransport.MakeHandlerFunc(
+				endpts.Account.Freeze,
+				httptransport.DecodeRequest[dto.AccountStatusRequest],
+				httptransport.NoContentResponse,
+			))
+			router.Post("/unfreeze", httptransport.MakeHandlerFunc(
+				endpts.Account.Unfreeze,
+				httptransport.DecodeRequest[dto.AccountStatusRequest],
+				httptransport.NoContentResponse,
+			))
+			router.Post("/close", httptransport.MakeHandlerFunc(
+				endpts.Account.Close,
+				httptransport.DecodeRequest[dto.CloseAccountRequest],
+				httptransport.NoContentResponse,
+			))
+			router.Put("/overdraft-limit", httptransport.MakeHandlerFunc(
+				endpts.Account.SetOverdraftLimit,
+				httptransport.DecodeRequest[dto.SetOverdraftLimitRequest],
+				httptransport.NoContentResponse,
+			))
+		})
+
+		router.Route("/admin/accounts/{id}/limits", func(router chi.Router) {
+			router.Use(scope(httptransport.ScopeAdmin)...)
+			router.Get("/", httptransport.MakeHandlerFunc(
+				endpts.TransferLimit.Get,
+				httptransport.DecodeRequest[dto.GetAccountRequest],
+				httptransport.ResponseWithBody,
+			))
+			router.Put("/", httptransport.MakeHandlerFunc(
+				endpts.TransferLimit.Set,
+				httptransport.DecodeRequest[dto.SetTransferLimitRequest],
+				httptransport.NoContentResponse,
+			))
+		})
+
+		router.Route("/admin/accounts/{id}/interest-rate", func(router chi.Router) {
+			router.Use(scope(httptransport.ScopeAdmin)...)
+			router.Get("/", httptransport.MakeHandlerFunc(
+				endpts.Interest.GetRate,
+				httptransport.DecodeRequest[dto.GetAccountRequest],
+				httptransport.ResponseWithBody,
+			))
+			router.Put("/", httptransport.MakeHandlerFunc(
+				endpts.Interest.SetRate,
+				httptransport.DecodeRequest[dto.SetInterestRateRequest],
 				httptransport.NoContentResponse,
 			))
 		})
@@ -4,11 +4,13 @@ package router
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/ijalalfrz/go-event-source/internal/app/config"
 	"github.com/ijalalfrz/go-event-source/internal/app/endpoint"
+	httptransport "github.com/ijalalfrz/go-event-source/internal/pkg/transport/http"
 )
 
 func TestConfigRoute(t *testing.T) {
@@ -28,6 +30,9 @@ func TestConfigRoute(t *testing.T) {
 			Transaction: endpoint.Transaction{},
 		},
 		cfg,
+		nil,
+		nil,
+		nil,
 	)
 
 	testCases := []struct {
@@ -48,18 +53,228 @@ func TestConfigRoute(t *testing.T) {
 			path:        "/accounts",
 			shouldMatch: true,
 		},
+		{
+			name:        "List Accounts",
+			method:      http.MethodGet,
+			path:        "/accounts",
+			shouldMatch: true,
+		},
 		{
 			name:        "Get Account",
 			method:      http.MethodGet,
 			path:        "/accounts/1",
 			shouldMatch: true,
 		},
+		{
+			name:        "Update Account Profile",
+			method:      http.MethodPatch,
+			path:        "/accounts/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Account Statement",
+			method:      http.MethodGet,
+			path:        "/accounts/1/statement",
+			shouldMatch: true,
+		},
 		{
 			name:        "Create Transfer",
 			method:      http.MethodPost,
 			path:        "/transactions",
 			shouldMatch: true,
 		},
+		{
+			name:        "Get Transaction",
+			method:      http.MethodGet,
+			path:        "/transactions/abc-123",
+			shouldMatch: true,
+		},
+		{
+			name:        "Approve Transfer",
+			method:      http.MethodPost,
+			path:        "/transactions/abc-123/approve",
+			shouldMatch: true,
+		},
+		{
+			name:        "Reject Transfer",
+			method:      http.MethodPost,
+			path:        "/transactions/abc-123/reject",
+			shouldMatch: true,
+		},
+		{
+			name:        "Create Scheduled Transfer",
+			method:      http.MethodPost,
+			path:        "/transactions/scheduled",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Scheduled Transfer",
+			method:      http.MethodGet,
+			path:        "/transactions/scheduled/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Cancel Scheduled Transfer",
+			method:      http.MethodDelete,
+			path:        "/transactions/scheduled/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Create Standing Order",
+			method:      http.MethodPost,
+			path:        "/transactions/standing-orders",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Standing Order",
+			method:      http.MethodGet,
+			path:        "/transactions/standing-orders/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Cancel Standing Order",
+			method:      http.MethodDelete,
+			path:        "/transactions/standing-orders/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Trial Balance",
+			method:      http.MethodGet,
+			path:        "/ledger/trial-balance",
+			shouldMatch: true,
+		},
+		{
+			name:        "Import Reconciliation",
+			method:      http.MethodPost,
+			path:        "/reconciliations",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Reconciliation",
+			method:      http.MethodGet,
+			path:        "/reconciliations/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Submit Payment Batch",
+			method:      http.MethodPost,
+			path:        "/payment-batches",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Payment Batch",
+			method:      http.MethodGet,
+			path:        "/payment-batches/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Payment Status Report",
+			method:      http.MethodGet,
+			path:        "/payment-batches/1/status-report",
+			shouldMatch: true,
+		},
+		{
+			name:        "Create Webhook",
+			method:      http.MethodPost,
+			path:        "/webhooks",
+			shouldMatch: true,
+		},
+		{
+			name:        "List Webhooks",
+			method:      http.MethodGet,
+			path:        "/webhooks",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Webhook",
+			method:      http.MethodGet,
+			path:        "/webhooks/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Delete Webhook",
+			method:      http.MethodDelete,
+			path:        "/webhooks/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "List Webhook Deliveries",
+			method:      http.MethodGet,
+			path:        "/webhooks/1/deliveries",
+			shouldMatch: true,
+		},
+		{
+			name:        "Redeliver Webhook",
+			method:      http.MethodPost,
+			path:        "/webhooks/1/redeliver",
+			shouldMatch: true,
+		},
+		{
+			name:        "List Monitoring Alerts",
+			method:      http.MethodGet,
+			path:        "/monitoring/alerts",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Monitoring Alert",
+			method:      http.MethodGet,
+			path:        "/monitoring/alerts/1",
+			shouldMatch: true,
+		},
+		{
+			name:        "Disposition Monitoring Alert",
+			method:      http.MethodPost,
+			path:        "/monitoring/alerts/1/disposition",
+			shouldMatch: true,
+		},
+		{
+			name:        "Freeze Account",
+			method:      http.MethodPost,
+			path:        "/admin/accounts/1/freeze",
+			shouldMatch: true,
+		},
+		{
+			name:        "Unfreeze Account",
+			method:      http.MethodPost,
+			path:        "/admin/accounts/1/unfreeze",
+			shouldMatch: true,
+		},
+		{
+			name:        "Close Account",
+			method:      http.MethodPost,
+			path:        "/admin/accounts/1/close",
+			shouldMatch: true,
+		},
+		{
+			name:        "Set Overdraft Limit",
+			method:      http.MethodPut,
+			path:        "/admin/accounts/1/overdraft-limit",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Transfer Limits",
+			method:      http.MethodGet,
+			path:        "/admin/accounts/1/limits",
+			shouldMatch: true,
+		},
+		{
+			name:        "Set Transfer Limits",
+			method:      http.MethodPut,
+			path:        "/admin/accounts/1/limits",
+			shouldMatch: true,
+		},
+		{
+			name:        "Get Interest Rate",
+			method:      http.MethodGet,
+			path:        "/admin/accounts/1/interest-rate",
+			shouldMatch: true,
+		},
+		{
+			name:        "Set Interest Rate",
+			method:      http.MethodPut,
+			path:        "/admin/accounts/1/interest-rate",
+			shouldMatch: true,
+		},
 	}
 
 	chiCtx := chi.NewRouteContext()
@@ -76,3 +291,78 @@ func TestConfigRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestRouteScopes(t *testing.T) {
+	serviceTokens, err := httptransport.ParseServiceTokens([]string{"payroll|payroll-token|accounts:read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := MakeHTTPRouter(endpoint.Endpoint{}, config.Config{}, nil, nil, serviceTokens)
+
+	testCases := []struct {
+		name               string
+		method             string
+		path               string
+		token              string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Healthcheck without token",
+			method:             http.MethodGet,
+			path:               "/health",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "List Accounts without token",
+			method:             http.MethodGet,
+			path:               "/accounts",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "Create Account without accounts:write",
+			method:             http.MethodPost,
+			path:               "/accounts",
+			token:              "payroll-token",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Create Transfer without transfers:write",
+			method:             http.MethodPost,
+			path:               "/transactions",
+			token:              "payroll-token",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Freeze Account without admin",
+			method:             http.MethodPost,
+			path:               "/admin/accounts/1/freeze",
+			token:              "payroll-token",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Get Trial Balance without admin",
+			method:             http.MethodGet,
+			path:               "/ledger/trial-balance",
+			token:              "payroll-token",
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req := httptest.NewRequest(testCase.method, testCase.path, nil)
+			if testCase.token != "" {
+				req.Header.Set("Authorization", "Bearer "+testCase.token)
+			}
+
+			respRecorder := httptest.NewRecorder()
+
+			router.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != testCase.expectedStatusCode {
+				t.Errorf("expected status %d, got %d", testCase.expectedStatusCode, respRecorder.Code)
+			}
+		})
+	}
+}
@@ -10,38 +10,58 @@ import (
 	"github.com/ijalalfrz/go-event-source/internal/app/dto"
 	"github.com/ijalalfrz/go-event-source/internal/app/model"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
+	"github.com/shopspring/decimal"
 )
 
 type AccountRepository interface {
 	UpsertTx(ctx context.Context, tx *sql.Tx, account *model.Account) error
 	WithTransaction(ctx context.Context, txFunc func(context.Context, *sql.Tx) error) error
+	WithRetryableTransaction(ctx context.Context, txFunc func(context.Context, *sql.Tx) error) error
 	FindByID(ctx context.Context, id int64) (model.Account, error)
 	FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (model.Account, error)
+	FindByIDForShareTx(ctx context.Context, tx *sql.Tx, id int64) (model.Account, error)
+	FindAll(ctx context.Context, filter model.AccountFilter) ([]model.Account, error)
+}
+
+type AccountShardRepository interface {
+	CreditTx(ctx context.Context, tx *sql.Tx, accountID int64, shardNo int, amount decimal.Decimal,
+		now time.Time) (model.AccountShard, error)
+	FindAllByAccountIDForUpdateTx(ctx context.Context, tx *sql.Tx, accountID int64) ([]model.AccountShard, error)
+	ResetAllByAccountIDTx(ctx context.Context, tx *sql.Tx, accountID int64, now time.Time) error
 }
 
 type EventRepository interface {
 	CreateTx(ctx context.Context, tx *sql.Tx, event *model.Event) error
 	CreateBulkTx(ctx context.Context, tx *sql.Tx, events []model.Event) error
 	FindAllByTransactionID(ctx context.Context, transactionID string) ([]model.Event, error)
-	FindLastByAggregateID(ctx context.Context, aggregateID int64) (model.Event, error)
+	FindLastByAggregateID(ctx context.Context, aggregateType model.AggregateType, aggregateID int64) (model.Event, error)
 }
 
 type AccountService struct {
-	accountRepository    AccountRepository
-	eventRepository      EventRepository
-	requestTimeThreshold time.Duration
-	eventVersion         string
+	accountRepository      AccountRepository
+	accountShardRepository AccountShardRepository
+	eventRepository        EventRepository
+	journalRepository      JournalRepository
+	idempotencyKeyClaimer  IdempotencyKeyClaimer
+	ledgerAccounts         LedgerAccounts
+	requestTimeThreshold   time.Duration
+	eventVersion           string
 }
 
-func NewAccountService(accountRepository AccountRepository,
-	eventRepository EventRepository, requestTimeThreshold time.Duration,
-	eventVersion string,
+func NewAccountService(accountRepository AccountRepository, accountShardRepository AccountShardRepository,
+	eventRepository EventRepository, journalRepository JournalRepository, idempotencyKeyClaimer IdempotencyKeyClaimer,
+	ledgerAccounts LedgerAccounts, requestTimeThreshold time.Duration, eventVersion string,
 ) *AccountService {
 	return &AccountService{
-		accountRepository:    accountRepository,
-		eventRepository:      eventRepository,
-		requestTimeThreshold: requestTimeThreshold,
-		eventVersion:         eventVersion,
+		accountRepository:      accountRepository,
+		accountShardRepository: accountShardRepository,
+		eventRepository:        eventRepository,
+		journalRepository:      journalRepository,
+		idempotencyKeyClaimer:  idempotencyKeyClaimer,
+		ledgerAccounts:         ledgerAccounts,
+		requestTimeThreshold:   requestTimeThreshold,
+		eventVersion:           eventVersion,
 	}
 }
 
@@ -67,13 +87,17 @@ func (s *AccountService) CreateAccount(ctx context.Context, req dto.CreateAccoun
 		return fmt.Errorf("failed to get request context: %w", err)
 	}
 
-	// check if account already exists
-	_, err = s.accountRepository.FindByID(ctx, req.AccountID)
+	// check if account already exists, a frozen or closed account id cannot be reused
+	existingAccount, err := s.accountRepository.FindByID(ctx, req.AccountID)
 	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
 		return fmt.Errorf("failed to find account: %w", err)
 	}
 
 	if err == nil {
+		if err := checkAccountOperable(existingAccount); err != nil {
+			return err
+		}
+
 		return ErrAccountAlreadyExists
 	}
 
@@ -87,27 +111,61 @@ func (s *AccountService) CreateAccount(ctx context.Context, req dto.CreateAccoun
 		return ErrIdempotency
 	}
 
-	// set event collector
-	eventCollector, err := NewAccountEventCollector(ctx, s.eventRepository,
-		req.AccountID, reqContext.TransactionID, s.eventVersion)
-	if err != nil {
-		return fmt.Errorf("failed to create account event collector: %w", err)
+	accountType := model.AccountType(req.AccountType)
+	if accountType == "" {
+		accountType = model.AccountTypePersonal
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = model.DefaultCurrency
 	}
 
 	account := &model.Account{
-		ID:        req.AccountID,
-		Balance:   req.InitialBalance,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:             req.AccountID,
+		Type:           accountType,
+		Currency:       currency,
+		Balance:        req.InitialBalance,
+		DisplayName:    req.DisplayName,
+		OwnerReference: req.OwnerReference,
+		Labels:         req.Labels,
+		Status:         model.AccountStatusActive,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
-	// create account within transaction
-	err = s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
-		eventCollector.OnInitBalanceEvent(req.InitialBalance)
-		eventCollector.OnDepositReceivedEvent("SYSTEM", req.InitialBalance)
+	// create account within transaction, it is run again on a deadlock
+	err = s.accountRepository.WithRetryableTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := claimTransactionID(ctx, dbTx, s.idempotencyKeyClaimer, reqContext.TransactionID); err != nil {
+			return err
+		}
+
+		eventCollectors, err := s.newCreateAccountEventCollectors(ctx, req, reqContext.TransactionID)
+		if err != nil {
+			return err
+		}
+
+		eventCollector := eventCollectors[0]
+
+		eventCollector.OnInitBalanceEvent(*account)
+		eventCollector.OnDepositReceivedEvent(s.ledgerAccounts.FundingAccountID, req.InitialBalance)
+
+		if len(eventCollectors) > 1 {
+			err := s.fundDeposit(ctx, dbTx, eventCollectors[1], req.AccountID, req.InitialBalance)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = recordJournalEntry(ctx, dbTx, s.journalRepository, reqContext.TransactionID, eventCollectors...)
+		if err != nil {
+			return err
+		}
 
-		if err := eventCollector.Place(ctx, dbTx); err != nil {
-			return fmt.Errorf("failed to place events: %w", err)
+		for _, collector := range eventCollectors {
+			if err := collector.Place(ctx, dbTx); err != nil {
+				return fmt.Errorf("failed to place events: %w", err)
+			}
 		}
 
 		if err := s.accountRepository.UpsertTx(ctx, dbTx, account); err != nil {
@@ -123,6 +181,32 @@ func (s *AccountService) CreateAccount(ctx context.Context, req dto.CreateAccoun
 	return nil
 }
 
+// newCreateAccountEventCollectors returns the event collector of the new account, followed by the one of the funding
+// account paying the initial deposit.
+func (s *AccountService) newCreateAccountEventCollectors(ctx context.Context, req dto.CreateAccountRequest,
+	transactionID string,
+) ([]*AccountEventCollector, error) {
+	eventCollector, err := NewAccountEventCollector(ctx, s.eventRepository,
+		req.AccountID, transactionID, s.eventVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account event collector: %w", err)
+	}
+
+	eventCollectors := []*AccountEventCollector{eventCollector}
+
+	if req.InitialBalance.IsPositive() {
+		fundingEventCollector, err := NewAccountEventCollector(ctx, s.eventRepository,
+			s.ledgerAccounts.FundingAccountID, transactionID, s.eventVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create account event collector: %w", err)
+		}
+
+		eventCollectors = append(eventCollectors, fundingEventCollector)
+	}
+
+	return eventCollectors, nil
+}
+
 // GetAccount godoc
 // @Summary      Get Account
 // @Description  Get an Account by ID
@@ -141,8 +225,565 @@ func (s *AccountService) GetAccount(ctx context.Context, req dto.GetAccountReque
 		return dto.AccountResponse{}, fmt.Errorf("failed to get account: %w", err)
 	}
 
+	return newAccountResponse(account), nil
+}
+
+// ListAccounts godoc
+// @Summary      List Accounts
+// @Description  List the Accounts matching the filters, one page at a time. The next page is requested with the
+// @Description  next_cursor of the previous page and the same filters and sort
+// @Tags         Account
+// @ID           listAccounts
+// @Produce      json
+// @Param        min_balance	query		string	false	"Minimum balance"
+// @Param        max_balance	query		string	false	"Maximum balance"
+// @Param        created_from	query		string	false	"Created at or after (RFC3339)"
+// @Param        created_to	query		string	false	"Created before (RFC3339)"
+// @Param        status	query		string	false	"Status"	Enums(active, frozen, closed)
+// @Param        currency	query		string	false	"ISO 4217 currency"
+// @Param        sort	query		string	false	"Sort field, prefixed with - for the descending order"	Enums(id, -id, balance, -balance, created_at, -created_at)
+// @Param        limit	query		int	false	"Page size, up to 100"
+// @Param        cursor	query		string	false	"Cursor of the next page"
+// @Success      200  {object}  dto.ListResponse[dto.AccountResponse]	"Accounts"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /accounts [get].
+func (s *AccountService) ListAccounts(ctx context.Context,
+	req dto.ListAccountsRequest,
+) (dto.ListResponse[dto.AccountResponse], error) {
+	sortField, descending := req.SortField()
+
+	filter := model.AccountFilter{
+		MinBalance:  req.MinBalance,
+		MaxBalance:  req.MaxBalance,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Status:      model.AccountStatus(req.Status),
+		Currency:    req.Currency,
+		SortField:   sortField,
+		Descending:  descending,
+		// one more account tells whether there is a next page
+		Limit: req.Limit + 1,
+	}
+
+	if req.Cursor != "" {
+		cursor, err := pagination.Decode(req.Cursor, req.Sort)
+		if err != nil {
+			return dto.ListResponse[dto.AccountResponse]{}, ErrInvalidCursor
+		}
+
+		filter.AfterValue = &cursor.Value
+		filter.AfterID = &cursor.ID
+	}
+
+	accounts, err := s.accountRepository.FindAll(ctx, filter)
+	if err != nil {
+		return dto.ListResponse[dto.AccountResponse]{}, fmt.Errorf("failed to find accounts: %w", err)
+	}
+
+	accounts, nextCursor := pagination.Page(accounts, req.Limit, func(account model.Account) pagination.Cursor {
+		return pagination.Cursor{Sort: req.Sort, Value: account.SortValue(sortField), ID: account.ID}
+	})
+
+	resp := dto.ListResponse[dto.AccountResponse]{
+		Data:       make([]dto.AccountResponse, 0, len(accounts)),
+		NextCursor: nextCursor,
+	}
+
+	for _, account := range accounts {
+		resp.Data = append(resp.Data, newAccountResponse(account))
+	}
+
+	return resp, nil
+}
+
+func newAccountResponse(account model.Account) dto.AccountResponse {
 	return dto.AccountResponse{
-		AccountID: account.ID,
-		Balance:   account.Balance,
-	}, nil
+		AccountID:          account.ID,
+		AccountType:        string(account.Type),
+		Currency:           account.Currency,
+		DisplayName:        account.DisplayName,
+		OwnerReference:     account.OwnerReference,
+		Labels:             account.Labels,
+		Balance:            account.Balance,
+		AvailableBalance:   account.AvailableBalance(),
+		OverdraftLimit:     account.OverdraftLimit,
+		OverdraftUsed:      account.OverdraftUsed(),
+		OverdraftRemaining: account.OverdraftRemaining(),
+		Status:             string(account.Status),
+		CreatedAt:          account.CreatedAt,
+	}
+}
+
+// UpdateAccountProfile godoc
+// @Summary      Update Account Profile
+// @Description  Change the display name, type, owner reference or labels of an Account, the omitted fields are
+// @Description  left unchanged and labels replace the current labels
+// @Tags         Account
+// @ID           updateAccountProfile
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Param        req body update account profile	body		dto.UpdateAccountProfileRequest	true	"Profile"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      422  {object}  dto.ErrorResponse	"Account closed"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /accounts/{id} [patch].
+func (s *AccountService) UpdateAccountProfile(ctx context.Context, req dto.UpdateAccountProfileRequest) error {
+	transactionID, err := s.accountChangeTransactionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	update := model.AccountProfileUpdate{
+		DisplayName:    req.DisplayName,
+		OwnerReference: req.OwnerReference,
+		Labels:         req.Labels,
+	}
+
+	if req.AccountType != nil {
+		accountType := model.AccountType(*req.AccountType)
+		update.Type = &accountType
+	}
+
+	err = s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		account, err := s.accountRepository.FindByIDForUpdateTx(ctx, dbTx, req.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find account: %w", err)
+		}
+
+		// the sequence number of the events is read once the account is locked
+		eventCollector, err := s.newAccountChangeEventCollector(ctx, req.ID, transactionID)
+		if err != nil {
+			return err
+		}
+
+		if account.IsClosed() {
+			return checkAccountOperable(account)
+		}
+
+		changes := account.UpdateProfile(update)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		eventCollector.OnProfileUpdatedEvent(changes)
+
+		account.UpdatedAt = time.Now()
+
+		return s.placeAccountChange(ctx, dbTx, eventCollector, &account)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update account profile: %w", err)
+	}
+
+	return nil
+}
+
+// SetOverdraftLimit godoc
+// @Summary      Set Overdraft Limit
+// @Description  Set the approved overdraft limit of an Account, zero removes the overdraft
+// @Tags         Account
+// @ID           setOverdraftLimit
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Param        req body set overdraft limit	body		dto.SetOverdraftLimitRequest	true	"Limit"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      422  {object}  dto.ErrorResponse	"Account closed"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/accounts/{id}/overdraft-limit [put].
+func (s *AccountService) SetOverdraftLimit(ctx context.Context, req dto.SetOverdraftLimitRequest) error {
+	transactionID, err := s.accountChangeTransactionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		account, err := s.accountRepository.FindByIDForUpdateTx(ctx, dbTx, req.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find account: %w", err)
+		}
+
+		// the sequence number of the events is read once the account is locked
+		eventCollector, err := s.newAccountChangeEventCollector(ctx, req.ID, transactionID)
+		if err != nil {
+			return err
+		}
+
+		if account.IsClosed() {
+			return checkAccountOperable(account)
+		}
+
+		// lowering the limit below the drawn overdraft is allowed, further debits are rejected until repaid
+		eventCollector.OnOverdraftLimitSetEvent(account.OverdraftLimit, *req.Limit)
+
+		account.OverdraftLimit = *req.Limit
+		account.UpdatedAt = time.Now()
+
+		return s.placeAccountChange(ctx, dbTx, eventCollector, &account)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set overdraft limit: %w", err)
+	}
+
+	return nil
+}
+
+// FreezeAccount godoc
+// @Summary      Freeze Account
+// @Description  Freeze an Account, a frozen account cannot send or receive funds
+// @Tags         Account
+// @ID           freezeAccount
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Param        req body freeze account	body		dto.AccountStatusRequest	true	"Reason"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      422  {object}  dto.ErrorResponse	"Account frozen or closed"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/accounts/{id}/freeze [post].
+func (s *AccountService) FreezeAccount(ctx context.Context, req dto.AccountStatusRequest) error {
+	transactionID, err := s.accountChangeTransactionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	reason := model.AccountStatusReason(req.Reason)
+
+	err = s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		account, err := s.accountRepository.FindByIDForUpdateTx(ctx, dbTx, req.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find account: %w", err)
+		}
+
+		// the sequence number of the events is read once the account is locked
+		eventCollector, err := s.newAccountChangeEventCollector(ctx, req.ID, transactionID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkAccountOperable(account); err != nil {
+			return err
+		}
+
+		account.Status = model.AccountStatusFrozen
+		account.StatusReason = reason
+		account.UpdatedAt = time.Now()
+
+		eventCollector.OnFrozenEvent(reason)
+
+		return s.placeAccountChange(ctx, dbTx, eventCollector, &account)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to freeze account: %w", err)
+	}
+
+	return nil
+}
+
+// UnfreezeAccount godoc
+// @Summary      Unfreeze Account
+// @Description  Unfreeze a frozen Account
+// @Tags         Account
+// @ID           unfreezeAccount
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Param        req body unfreeze account	body		dto.AccountStatusRequest	true	"Reason"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      422  {object}  dto.ErrorResponse	"Account closed"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/accounts/{id}/unfreeze [post].
+func (s *AccountService) UnfreezeAccount(ctx context.Context, req dto.AccountStatusRequest) error {
+	transactionID, err := s.accountChangeTransactionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	reason := model.AccountStatusReason(req.Reason)
+
+	err = s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		account, err := s.accountRepository.FindByIDForUpdateTx(ctx, dbTx, req.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find account: %w", err)
+		}
+
+		// the sequence number of the events is read once the account is locked
+		eventCollector, err := s.newAccountChangeEventCollector(ctx, req.ID, transactionID)
+		if err != nil {
+			return err
+		}
+
+		if account.IsClosed() {
+			return checkAccountOperable(account)
+		}
+
+		if !account.IsFrozen() {
+			return ErrAccountNotFrozen
+		}
+
+		account.Status = model.AccountStatusActive
+		account.StatusReason = reason
+		account.UpdatedAt = time.Now()
+
+		eventCollector.OnUnfrozenEvent(reason)
+
+		return s.placeAccountChange(ctx, dbTx, eventCollector, &account)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unfreeze account: %w", err)
+	}
+
+	return nil
+}
+
+// CloseAccount godoc
+// @Summary      Close Account
+// @Description  Close an Account, a remaining balance is swept to the sweep account
+// @Tags         Account
+// @ID           closeAccount
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Param        req body close account	body		dto.CloseAccountRequest	true	"Reason and sweep account"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      422  {object}  dto.ErrorResponse	"Account frozen, closed or balance not zero"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/accounts/{id}/close [post].
+func (s *AccountService) CloseAccount(ctx context.Context, req dto.CloseAccountRequest) error {
+	if req.SweepAccountID != nil && *req.SweepAccountID == req.ID {
+		return ErrSourceAndDestinationAccountSame
+	}
+
+	transactionID, err := s.accountChangeTransactionID(ctx)
+	if err != nil {
+		return err
+	}
+
+	reason := model.AccountStatusReason(req.Reason)
+
+	// the balance is swept within a transaction, it is run again on a deadlock
+	err = s.accountRepository.WithRetryableTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		accounts, err := s.lockCloseAccounts(ctx, dbTx, req)
+		if err != nil {
+			return err
+		}
+
+		account := accounts[req.ID]
+
+		eventCollector, sweepEventCollector, err := s.newCloseAccountEventCollectors(ctx, req, transactionID)
+		if err != nil {
+			return err
+		}
+
+		// a frozen account has to be unfrozen first, closing must not move funds out of a freeze
+		if err := checkAccountOperable(account); err != nil {
+			return err
+		}
+
+		// the balance of a hot account is partly held by its shards
+		shards, err := consolidateShardsTx(ctx, dbTx, s.accountShardRepository, &account)
+		if err != nil {
+			return err
+		}
+
+		if len(shards) > 0 {
+			eventCollector.OnShardsConsolidatedEvent(shards)
+		}
+
+		sweptAmount := decimal.Zero
+
+		var sweepAccountID *int64
+
+		if !account.Balance.IsZero() {
+			if req.SweepAccountID == nil || account.Balance.IsNegative() {
+				return ErrAccountBalanceNotZero
+			}
+
+			sweptAmount = account.Balance
+			sweepAccountID = req.SweepAccountID
+
+			err := s.sweepBalance(ctx, dbTx, eventCollector, sweepEventCollector, accounts[*req.SweepAccountID],
+				sweptAmount)
+			if err != nil {
+				return err
+			}
+		}
+
+		account.Balance = decimal.Zero
+		account.Status = model.AccountStatusClosed
+		account.StatusReason = reason
+		account.UpdatedAt = time.Now()
+
+		eventCollector.OnClosedEvent(reason, sweepAccountID, sweptAmount)
+
+		return s.placeAccountChange(ctx, dbTx, eventCollector, &account)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to close account: %w", err)
+	}
+
+	return nil
+}
+
+// lockCloseAccounts locks the closed account and the sweep account, when one is given, in the order of their ids
+// like the accounts of a transfer.
+func (s *AccountService) lockCloseAccounts(ctx context.Context, dbTx *sql.Tx,
+	req dto.CloseAccountRequest,
+) (map[int64]model.Account, error) {
+	accountIDs := []int64{req.ID}
+	if req.SweepAccountID != nil {
+		accountIDs = append(accountIDs, *req.SweepAccountID)
+	}
+
+	return lockAccountsTx(ctx, dbTx, accountIDs, s.accountRepository.FindByIDForUpdateTx,
+		func(accountID int64, err error) error {
+			switch {
+			case accountID == req.ID:
+				return fmt.Errorf("failed to find account: %w", err)
+			case errors.Is(err, exception.ErrRecordNotFound):
+				return fmt.Errorf("failed to find sweep account: %w", ErrSweepAccountNotFound)
+			default:
+				return fmt.Errorf("failed to find sweep account: %w", err)
+			}
+		})
+}
+
+// newAccountChangeEventCollector prepares the event collector of an account change, the account has to be locked
+// first so that no other event is placed in the meantime.
+func (s *AccountService) newAccountChangeEventCollector(ctx context.Context,
+	accountID int64, transactionID string,
+) (*AccountEventCollector, error) {
+	eventCollector, err := NewAccountEventCollector(ctx, s.eventRepository,
+		accountID, transactionID, s.eventVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account event collector: %w", err)
+	}
+
+	return eventCollector, nil
+}
+
+// accountChangeTransactionID validates the request of an account change and returns its transaction id.
+func (s *AccountService) accountChangeTransactionID(ctx context.Context) (string, error) {
+	reqContext, err := getRequestContext(ctx, s.requestTimeThreshold)
+	if err != nil {
+		return "", fmt.Errorf("failed to get request context: %w", err)
+	}
+
+	// if event already exists, return error for idempotency
+	_, err = s.eventRepository.FindAllByTransactionID(ctx, reqContext.TransactionID)
+	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to find events: %w", err)
+	}
+
+	if err == nil {
+		return "", ErrIdempotency
+	}
+
+	return reqContext.TransactionID, nil
+}
+
+// newCloseAccountEventCollectors returns the event collector of the closed account and the one of the sweep
+// account, nil when the balance is not swept. Both accounts have to be locked first.
+func (s *AccountService) newCloseAccountEventCollectors(ctx context.Context, req dto.CloseAccountRequest,
+	transactionID string,
+) (*AccountEventCollector, *AccountEventCollector, error) {
+	eventCollector, err := NewAccountEventCollector(ctx, s.eventRepository, req.ID, transactionID, s.eventVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create account event collector: %w", err)
+	}
+
+	if req.SweepAccountID == nil {
+		return eventCollector, nil, nil
+	}
+
+	sweepEventCollector, err := NewAccountEventCollector(ctx, s.eventRepository, *req.SweepAccountID,
+		transactionID, s.eventVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create account event collector: %w", err)
+	}
+
+	return eventCollector, sweepEventCollector, nil
+}
+
+// sweepBalance moves the remaining balance of a closing account to the sweep account, it is locked with the closing
+// account.
+func (s *AccountService) sweepBalance(ctx context.Context, dbTx *sql.Tx, eventCollector *AccountEventCollector,
+	sweepEventCollector *AccountEventCollector, sweepAccount model.Account, amount decimal.Decimal,
+) error {
+	if err := checkAccountOperable(sweepAccount); err != nil {
+		return err
+	}
+
+	sweepAccount.Balance = sweepAccount.Balance.Add(amount)
+	sweepAccount.UpdatedAt = time.Now()
+
+	eventCollector.OnSubBalanceEvent(sweepAccount.ID, amount)
+	sweepEventCollector.OnAddBalanceEvent(eventCollector.aggregateID, amount)
+
+	err := recordJournalEntry(ctx, dbTx, s.journalRepository, eventCollector.transactionID,
+		eventCollector, sweepEventCollector)
+	if err != nil {
+		return err
+	}
+
+	return s.placeAccountChange(ctx, dbTx, sweepEventCollector, &sweepAccount)
+}
+
+// fundDeposit debits the initial deposit of an account from the funding account, a system account may go
+// negative.
+func (s *AccountService) fundDeposit(ctx context.Context, dbTx *sql.Tx, fundingEventCollector *AccountEventCollector,
+	accountID int64, amount decimal.Decimal,
+) error {
+	fundingAccount, err := findSystemAccountForUpdateTx(ctx, dbTx, s.accountRepository,
+		s.ledgerAccounts.FundingAccountID)
+	if err != nil {
+		return err
+	}
+
+	fundingAccount.Balance = fundingAccount.Balance.Sub(amount)
+	fundingAccount.UpdatedAt = time.Now()
+
+	fundingEventCollector.OnSubBalanceEvent(accountID, amount)
+
+	if err := s.accountRepository.UpsertTx(ctx, dbTx, &fundingAccount); err != nil {
+		return fmt.Errorf("failed to upsert account: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AccountService) placeAccountChange(ctx context.Context, dbTx *sql.Tx,
+	eventCollector *AccountEventCollector, account *model.Account,
+) error {
+	if err := eventCollector.Place(ctx, dbTx); err != nil {
+		return fmt.Errorf("failed to place events: %w", err)
+	}
+
+	if err := s.accountRepository.UpsertTx(ctx, dbTx, account); err != nil {
+		return fmt.Errorf("failed to upsert account: %w", err)
+	}
+
+	return nil
 }
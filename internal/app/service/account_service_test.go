@@ -12,6 +12,7 @@ import (
 	"github.com/ijalalfrz/go-event-source/internal/app/dto"
 	"github.com/ijalalfrz/go-event-source/internal/app/model"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
@@ -63,6 +64,22 @@ func TestAccountService_CreateAccount(t *testing.T) {
 		eventVersion: "1.0.0",
 	}, ctx, ErrAccountAlreadyExists))
 
+	// error closed account id cannot be reused
+	t.Run("error_account_closed", testCreateAccount(dto.CreateAccountRequest{
+		AccountID:      1,
+		InitialBalance: decimal.NewFromInt(1000),
+	}, &AccountService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil},
+			account: model.Account{
+				ID:     1,
+				Status: model.AccountStatusClosed,
+			},
+		},
+		eventVersion: "1.0.0",
+	}, ctx, ErrAccountClosed))
+
 	// error find account
 	t.Run("error_find_account", testCreateAccount(dto.CreateAccountRequest{
 		AccountID:      1,
@@ -108,7 +125,8 @@ func TestAccountService_CreateAccount(t *testing.T) {
 		AccountID:      1,
 		InitialBalance: decimal.NewFromInt(1000),
 	}, &AccountService{
-		requestTimeThreshold: 30 * time.Second,
+		requestTimeThreshold:  30 * time.Second,
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByID: []error{exception.ErrRecordNotFound},
 		},
@@ -131,11 +149,13 @@ func TestAccountService_CreateAccount(t *testing.T) {
 	}, &AccountService{
 		requestTimeThreshold: 30 * time.Second,
 		accountRepository: &accountRepositoryMock{
-			errFindByID: []error{exception.ErrRecordNotFound},
+			errFindByID:            []error{exception.ErrRecordNotFound},
+			errFindByIDForUpdateTx: []error{nil},
+			errUpsertTx:            []error{nil},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
-			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
 			errCreateBulkTx:           []error{errors.New("internal db error")},
 			events: []model.Event{
 				{
@@ -144,6 +164,8 @@ func TestAccountService_CreateAccount(t *testing.T) {
 				},
 			},
 		},
+		journalRepository:     &ledgerRepositoryMock{},
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
 	// failed upsert account
@@ -153,13 +175,14 @@ func TestAccountService_CreateAccount(t *testing.T) {
 	}, &AccountService{
 		requestTimeThreshold: 30 * time.Second,
 		accountRepository: &accountRepositoryMock{
-			errFindByID: []error{exception.ErrRecordNotFound},
-			errUpsertTx: []error{errors.New("internal db error")},
+			errFindByID:            []error{exception.ErrRecordNotFound},
+			errFindByIDForUpdateTx: []error{nil},
+			errUpsertTx:            []error{nil, errors.New("internal db error")},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
-			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
-			errCreateBulkTx:           []error{nil},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
 			events: []model.Event{
 				{
 					AggregateID:    1,
@@ -167,28 +190,677 @@ func TestAccountService_CreateAccount(t *testing.T) {
 				},
 			},
 		},
+		journalRepository:     &ledgerRepositoryMock{},
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
-	// success
-	t.Run("success", testCreateAccount(dto.CreateAccountRequest{
+	// funding account missing
+	t.Run("error_funding_account_not_found", testCreateAccount(dto.CreateAccountRequest{
 		AccountID:      1,
 		InitialBalance: decimal.NewFromInt(1000),
+	}, &AccountService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID:            []error{exception.ErrRecordNotFound},
+			errFindByIDForUpdateTx: []error{exception.ErrRecordNotFound},
+		},
+		eventRepository: &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			events:                    []model.Event{{}},
+		},
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
+		ledgerAccounts:        LedgerAccounts{FundingAccountID: 900001},
+	}, ctx, errors.New("system account 900001 not found")))
+
+	// a concurrent request claimed the transaction id first
+	t.Run("error_idempotency_claimed", testCreateAccount(dto.CreateAccountRequest{
+		AccountID:      1,
+		InitialBalance: decimal.Zero,
 	}, &AccountService{
 		requestTimeThreshold: 30 * time.Second,
 		accountRepository: &accountRepositoryMock{
 			errFindByID: []error{exception.ErrRecordNotFound},
-			errUpsertTx: []error{nil},
 		},
 		eventRepository: &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			events:                    []model.Event{{}},
+		},
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{
+			errClaimTx: fmt.Errorf("failed to exec statement: %w", exception.ErrRecordNotUnique),
+		},
+	}, ctx, ErrIdempotency))
+
+	// success
+	t.Run("success", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{exception.ErrRecordNotFound},
+			errFindByIDForUpdateTx: []error{nil},
+			errUpsertTx:            []error{nil, nil},
+			account:                model.Account{ID: 900001, Type: model.AccountTypeSystem},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		ledgerRepository := &ledgerRepositoryMock{}
+		idempotencyKeyRepository := &idempotencyKeyRepositoryMock{}
+		svc := &AccountService{
+			requestTimeThreshold:  30 * time.Second,
+			accountRepository:     accountRepository,
+			eventRepository:       eventRepository,
+			journalRepository:     ledgerRepository,
+			idempotencyKeyClaimer: idempotencyKeyRepository,
+			ledgerAccounts:        LedgerAccounts{FundingAccountID: 900001},
+		}
+
+		err := svc.CreateAccount(ctx, dto.CreateAccountRequest{
+			AccountID:      1,
+			InitialBalance: decimal.NewFromInt(1000),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"tx-12345"}, idempotencyKeyRepository.claimed)
+
+		// the funding account is debited by the initial deposit
+		assert.Equal(t, int64(900001), accountRepository.upserted[0].ID)
+		assert.True(t, decimal.NewFromInt(-1000).Equal(accountRepository.upserted[0].Balance))
+		assert.Equal(t, int64(1), accountRepository.upserted[1].ID)
+		assert.True(t, decimal.NewFromInt(1000).Equal(accountRepository.upserted[1].Balance))
+
+		assert.Len(t, eventRepository.placedEvents, 3)
+		assert.Equal(t, model.EventTypeDepositReceived, eventRepository.placedEvents[1].EventType)
+		assert.Equal(t, model.EventTypeDebitBalance, eventRepository.placedEvents[2].EventType)
+		assert.Equal(t, int64(900001), eventRepository.placedEvents[2].AggregateID)
+
+		assert.Len(t, ledgerRepository.journalEntries, 1)
+		assert.Equal(t, "tx-12345", ledgerRepository.journalEntries[0].TransactionID)
+		assert.Equal(t, []model.Posting{
+			{AccountID: 1, Direction: model.PostingDirectionCredit, Amount: decimal.NewFromInt(1000)},
+			{AccountID: 900001, Direction: model.PostingDirectionDebit, Amount: decimal.NewFromInt(1000)},
+		}, ledgerRepository.journalEntries[0].Postings)
+	})
+
+	// an account opened without a balance is not funded
+	t.Run("success_zero_initial_balance", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID: []error{exception.ErrRecordNotFound},
+			errUpsertTx: []error{nil},
+		}
+		eventRepository := &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
 			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
 			errCreateBulkTx:           []error{nil},
-			events: []model.Event{
-				{
-					AggregateID:    1,
-					SequenceNumber: 1,
+			events:                    []model.Event{{}},
+		}
+		ledgerRepository := &ledgerRepositoryMock{}
+		idempotencyKeyRepository := &idempotencyKeyRepositoryMock{}
+		svc := &AccountService{
+			requestTimeThreshold:  30 * time.Second,
+			accountRepository:     accountRepository,
+			eventRepository:       eventRepository,
+			journalRepository:     ledgerRepository,
+			idempotencyKeyClaimer: idempotencyKeyRepository,
+			ledgerAccounts:        LedgerAccounts{FundingAccountID: 900001},
+		}
+
+		err := svc.CreateAccount(ctx, dto.CreateAccountRequest{AccountID: 1, InitialBalance: decimal.Zero})
+		assert.NoError(t, err)
+
+		assert.Len(t, accountRepository.upserted, 1)
+		assert.Empty(t, ledgerRepository.journalEntries)
+	})
+}
+
+func TestAccountService_FreezeAccount(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	newService := func(account model.Account) (*AccountService, *accountRepositoryMock, *eventRepositoryMock) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil},
+			errUpsertTx:            []error{nil},
+			account:                account,
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{nil},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{SequenceNumber: 2}},
+		}
+
+		return &AccountService{
+			requestTimeThreshold: 30 * time.Second,
+			accountRepository:    accountRepository,
+			eventRepository:      eventRepository,
+		}, accountRepository, eventRepository
+	}
+
+	req := dto.AccountStatusRequest{ID: 1, Reason: "compliance_review"}
+
+	t.Run("error_already_frozen", func(t *testing.T) {
+		svc, _, _ := newService(model.Account{ID: 1, Status: model.AccountStatusFrozen})
+
+		err := svc.FreezeAccount(ctx, req)
+		assert.ErrorIs(t, err, ErrAccountFrozen)
+	})
+
+	t.Run("error_closed", func(t *testing.T) {
+		svc, _, _ := newService(model.Account{ID: 1, Status: model.AccountStatusClosed})
+
+		err := svc.FreezeAccount(ctx, req)
+		assert.ErrorIs(t, err, ErrAccountClosed)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		svc, accountRepository, eventRepository := newService(model.Account{ID: 1, Status: model.AccountStatusActive})
+
+		err := svc.FreezeAccount(ctx, req)
+		assert.NoError(t, err)
+
+		assert.Equal(t, model.AccountStatusFrozen, accountRepository.upserted[0].Status)
+		assert.Equal(t, model.AccountStatusReasonComplianceReview, accountRepository.upserted[0].StatusReason)
+		assert.Len(t, eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypeAccountFrozen, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, int64(3), eventRepository.placedEvents[0].SequenceNumber)
+	})
+
+	t.Run("error_lock_before_sequence_number", func(t *testing.T) {
+		svc, accountRepository, eventRepository := newService(model.Account{ID: 1})
+		accountRepository.errFindByIDForUpdateTx = []error{exception.ErrRecordNotFound}
+
+		err := svc.FreezeAccount(ctx, req)
+
+		// the last sequence number is read under the account lock, a concurrent transfer cannot reuse it
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+		assert.Equal(t, 0, eventRepository.findLastByAggregateIDCallCount)
+	})
+
+	t.Run("unfreeze_error_not_frozen", func(t *testing.T) {
+		svc, _, _ := newService(model.Account{ID: 1, Status: model.AccountStatusActive})
+
+		err := svc.UnfreezeAccount(ctx, dto.AccountStatusRequest{ID: 1, Reason: "review_cleared"})
+		assert.ErrorIs(t, err, ErrAccountNotFrozen)
+	})
+
+	t.Run("unfreeze_success", func(t *testing.T) {
+		svc, accountRepository, eventRepository := newService(model.Account{ID: 1, Status: model.AccountStatusFrozen})
+
+		err := svc.UnfreezeAccount(ctx, dto.AccountStatusRequest{ID: 1, Reason: "review_cleared"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, model.AccountStatusActive, accountRepository.upserted[0].Status)
+		assert.Equal(t, model.EventTypeAccountUnfrozen, eventRepository.placedEvents[0].EventType)
+	})
+}
+
+func TestAccountService_CloseAccount(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	sweepAccountID := int64(2)
+
+	t.Run("error_sweep_to_itself", func(t *testing.T) {
+		svc := &AccountService{requestTimeThreshold: 30 * time.Second}
+
+		err := svc.CloseAccount(ctx, dto.CloseAccountRequest{ID: 2, Reason: "customer_request", SweepAccountID: &sweepAccountID})
+		assert.ErrorIs(t, err, ErrSourceAndDestinationAccountSame)
+	})
+
+	t.Run("error_balance_not_zero", func(t *testing.T) {
+		svc := &AccountService{
+			requestTimeThreshold: 30 * time.Second,
+			accountRepository: &accountRepositoryMock{
+				errFindByIDForUpdateTx: []error{nil},
+				account: model.Account{
+					ID:      1,
+					Balance: decimal.NewFromInt(100),
+					Status:  model.AccountStatusActive,
+				},
+			},
+			accountShardRepository: &accountShardRepositoryMock{},
+			eventRepository: &eventRepositoryMock{
+				errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+				errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+				events:                    []model.Event{{}},
+			},
+		}
+
+		err := svc.CloseAccount(ctx, dto.CloseAccountRequest{ID: 1, Reason: "customer_request"})
+		assert.ErrorIs(t, err, ErrAccountBalanceNotZero)
+	})
+
+	t.Run("error_frozen", func(t *testing.T) {
+		svc := &AccountService{
+			requestTimeThreshold: 30 * time.Second,
+			accountRepository: &accountRepositoryMock{
+				errFindByIDForUpdateTx: []error{nil},
+				account: model.Account{
+					ID:     1,
+					Status: model.AccountStatusFrozen,
 				},
 			},
+			eventRepository: &eventRepositoryMock{
+				errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+				errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+				events:                    []model.Event{{}},
+			},
+		}
+
+		err := svc.CloseAccount(ctx, dto.CloseAccountRequest{ID: 1, Reason: "customer_request"})
+		assert.ErrorIs(t, err, ErrAccountFrozen)
+	})
+
+	t.Run("error_sweep_account_not_found", func(t *testing.T) {
+		svc := &AccountService{
+			requestTimeThreshold: 30 * time.Second,
+			accountRepository: &accountRepositoryMock{
+				errFindByIDForUpdateTx: []error{nil, exception.ErrRecordNotFound},
+				account: model.Account{
+					ID:      1,
+					Balance: decimal.NewFromInt(100),
+					Status:  model.AccountStatusActive,
+				},
+			},
+			accountShardRepository: &accountShardRepositoryMock{},
+			eventRepository: &eventRepositoryMock{
+				errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+				errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+				events:                    []model.Event{{}},
+			},
+		}
+
+		err := svc.CloseAccount(ctx, dto.CloseAccountRequest{ID: 1, Reason: "customer_request", SweepAccountID: &sweepAccountID})
+		assert.ErrorIs(t, err, ErrSweepAccountNotFound)
+	})
+
+	t.Run("success_with_sweep", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.NewFromInt(100),
+				Status:  model.AccountStatusActive,
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		ledgerRepository := &ledgerRepositoryMock{}
+		svc := &AccountService{
+			requestTimeThreshold:   30 * time.Second,
+			accountRepository:      accountRepository,
+			accountShardRepository: &accountShardRepositoryMock{},
+			eventRepository:        eventRepository,
+			journalRepository:      ledgerRepository,
+		}
+
+		err := svc.CloseAccount(ctx, dto.CloseAccountRequest{ID: 1, Reason: "customer_request", SweepAccountID: &sweepAccountID})
+		assert.NoError(t, err)
+
+		// sweep account is credited first, then the closed account is stored
+		assert.Len(t, accountRepository.upserted, 2)
+		assert.True(t, accountRepository.upserted[0].Balance.Equal(decimal.NewFromInt(200)))
+		assert.True(t, accountRepository.upserted[1].Balance.IsZero())
+		assert.Equal(t, model.AccountStatusClosed, accountRepository.upserted[1].Status)
+
+		var eventTypes []model.EventType
+		for _, event := range eventRepository.placedEvents {
+			eventTypes = append(eventTypes, event.EventType)
+		}
+
+		assert.Equal(t, []model.EventType{
+			model.EventTypeCreditBalance,
+			model.EventTypeDebitBalance,
+			model.EventTypeAccountClosed,
+		}, eventTypes)
+
+		assert.Len(t, ledgerRepository.journalEntries, 1)
+		assert.Equal(t, []model.Posting{
+			{AccountID: 1, Direction: model.PostingDirectionDebit, Amount: decimal.NewFromInt(100)},
+			{AccountID: 2, Direction: model.PostingDirectionCredit, Amount: decimal.NewFromInt(100)},
+		}, ledgerRepository.journalEntries[0].Postings)
+	})
+
+	t.Run("success_locks_in_id_order", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			accountsByID: map[int64]model.Account{
+				2: {ID: 2, Balance: decimal.NewFromInt(50), Status: model.AccountStatusActive},
+				3: {ID: 3, Balance: decimal.NewFromInt(100), Status: model.AccountStatusActive},
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		svc := &AccountService{
+			requestTimeThreshold:   30 * time.Second,
+			accountRepository:      accountRepository,
+			accountShardRepository: &accountShardRepositoryMock{},
+			eventRepository:        eventRepository,
+			journalRepository:      &ledgerRepositoryMock{},
+		}
+
+		err := svc.CloseAccount(ctx, dto.CloseAccountRequest{ID: 3, Reason: "customer_request", SweepAccountID: &sweepAccountID})
+		assert.NoError(t, err)
+
+		// the sweep account has the lower id, it is locked before the closed account like in a transfer
+		assert.Equal(t, []int64{2, 3}, accountRepository.lockedIDs)
+		assert.Equal(t, int64(2), accountRepository.upserted[0].ID)
+		assert.True(t, accountRepository.upserted[0].Balance.Equal(decimal.NewFromInt(150)))
+		assert.Equal(t, int64(3), accountRepository.upserted[1].ID)
+		assert.True(t, accountRepository.upserted[1].Balance.IsZero())
+	})
+
+	t.Run("success_sweeps_consolidated_shards", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.Zero,
+				Status:  model.AccountStatusActive,
+			},
+		}
+		accountShardRepository := &accountShardRepositoryMock{
+			shards: []model.AccountShard{
+				{ID: 10, AccountID: 1, ShardNo: 0, Balance: decimal.NewFromInt(60)},
+				{ID: 11, AccountID: 1, ShardNo: 3, Balance: decimal.NewFromInt(40)},
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		svc := &AccountService{
+			requestTimeThreshold:   30 * time.Second,
+			accountRepository:      accountRepository,
+			accountShardRepository: accountShardRepository,
+			eventRepository:        eventRepository,
+			journalRepository:      &ledgerRepositoryMock{},
+		}
+
+		err := svc.CloseAccount(ctx, dto.CloseAccountRequest{ID: 1, Reason: "customer_request", SweepAccountID: &sweepAccountID})
+		assert.NoError(t, err)
+
+		// the balance held by the shards is swept with the account
+		assert.Equal(t, []int64{1}, accountShardRepository.resetAccountIDs)
+		assert.True(t, accountRepository.upserted[0].Balance.Equal(decimal.NewFromInt(100)))
+		assert.True(t, accountRepository.upserted[1].Balance.IsZero())
+
+		var eventTypes []model.EventType
+		for _, event := range eventRepository.placedEvents {
+			eventTypes = append(eventTypes, event.EventType)
+		}
+
+		assert.Equal(t, []model.EventType{
+			model.EventTypeCreditBalance,
+			model.EventTypeShardsConsolidated,
+			model.EventTypeDebitBalance,
+			model.EventTypeAccountClosed,
+		}, eventTypes)
+	})
+}
+
+func TestAccountService_GetAccount(t *testing.T) {
+	svc := &AccountService{
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil},
+			account: model.Account{
+				ID:             1,
+				Balance:        decimal.NewFromInt(-200),
+				OverdraftLimit: decimal.NewFromInt(500),
+				Status:         model.AccountStatusActive,
+			},
 		},
-	}, ctx, nil))
+	}
+
+	got, err := svc.GetAccount(context.Background(), dto.GetAccountRequest{ID: 1})
+	assert.NoError(t, err)
+
+	assert.True(t, got.OverdraftUsed.Equal(decimal.NewFromInt(200)))
+	assert.True(t, got.OverdraftRemaining.Equal(decimal.NewFromInt(300)))
+	assert.True(t, got.AvailableBalance.Equal(decimal.NewFromInt(300)))
+	assert.Equal(t, "active", got.Status)
+}
+
+func TestAccountService_ListAccounts(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	accounts := []model.Account{
+		{ID: 3, Currency: "USD", Balance: decimal.NewFromInt(900), Status: model.AccountStatusActive, CreatedAt: createdAt},
+		{ID: 1, Currency: "USD", Balance: decimal.NewFromInt(500), Status: model.AccountStatusActive, CreatedAt: createdAt},
+		{ID: 2, Currency: "USD", Balance: decimal.NewFromInt(100), Status: model.AccountStatusActive, CreatedAt: createdAt},
+	}
+
+	t.Run("success_next_page", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{accounts: accounts}
+		svc := &AccountService{accountRepository: accountRepository}
+		minBalance := decimal.NewFromInt(100)
+
+		got, err := svc.ListAccounts(context.Background(), dto.ListAccountsRequest{
+			MinBalance: &minBalance,
+			Currency:   "USD",
+			Sort:       "-balance",
+			Limit:      2,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, got.Data, 2)
+		assert.Equal(t, int64(1), got.Data[1].AccountID)
+		assert.Equal(t, "USD", got.Data[1].Currency)
+
+		filter := accountRepository.filters[0]
+		assert.Equal(t, model.AccountSortFieldBalance, filter.SortField)
+		assert.True(t, filter.Descending)
+		assert.Equal(t, 3, filter.Limit)
+		assert.Equal(t, "USD", filter.Currency)
+		assert.Nil(t, filter.AfterID)
+
+		// the next page continues after the last account of the page
+		assert.NotNil(t, got.NextCursor)
+
+		_, err = svc.ListAccounts(context.Background(), dto.ListAccountsRequest{
+			Sort:   "-balance",
+			Limit:  2,
+			Cursor: *got.NextCursor,
+		})
+
+		assert.NoError(t, err)
+
+		filter = accountRepository.filters[1]
+		assert.Equal(t, int64(1), *filter.AfterID)
+		assert.Equal(t, "500", *filter.AfterValue)
+	})
+
+	t.Run("success_last_page", func(t *testing.T) {
+		svc := &AccountService{accountRepository: &accountRepositoryMock{accounts: accounts}}
+
+		got, err := svc.ListAccounts(context.Background(), dto.ListAccountsRequest{Sort: "id", Limit: 20})
+
+		assert.NoError(t, err)
+		assert.Len(t, got.Data, 3)
+		assert.Nil(t, got.NextCursor)
+	})
+
+	t.Run("error_cursor_of_another_sort", func(t *testing.T) {
+		svc := &AccountService{accountRepository: &accountRepositoryMock{}}
+		cursor := pagination.Cursor{Sort: "balance", Value: "500", ID: 1}.Encode()
+
+		_, err := svc.ListAccounts(context.Background(), dto.ListAccountsRequest{
+			Sort:   "created_at",
+			Limit:  20,
+			Cursor: cursor,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("error_find_accounts", func(t *testing.T) {
+		svc := &AccountService{accountRepository: &accountRepositoryMock{errFindAll: errors.New("db error")}}
+
+		_, err := svc.ListAccounts(context.Background(), dto.ListAccountsRequest{Sort: "id", Limit: 20})
+
+		assert.ErrorContains(t, err, "failed to find accounts")
+	})
+}
+
+func TestAccountService_SetOverdraftLimit(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	limit := decimal.NewFromInt(1000)
+
+	newService := func(account model.Account) (*AccountService, *accountRepositoryMock, *eventRepositoryMock) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil},
+			errUpsertTx:            []error{nil},
+			account:                account,
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{}},
+		}
+
+		return &AccountService{
+			requestTimeThreshold: 30 * time.Second,
+			accountRepository:    accountRepository,
+			eventRepository:      eventRepository,
+		}, accountRepository, eventRepository
+	}
+
+	t.Run("error_closed", func(t *testing.T) {
+		svc, _, _ := newService(model.Account{ID: 1, Status: model.AccountStatusClosed})
+
+		err := svc.SetOverdraftLimit(ctx, dto.SetOverdraftLimitRequest{ID: 1, Limit: &limit})
+		assert.ErrorIs(t, err, ErrAccountClosed)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		svc, accountRepository, eventRepository := newService(model.Account{
+			ID:             1,
+			OverdraftLimit: decimal.NewFromInt(200),
+			Status:         model.AccountStatusActive,
+		})
+
+		err := svc.SetOverdraftLimit(ctx, dto.SetOverdraftLimitRequest{ID: 1, Limit: &limit})
+		assert.NoError(t, err)
+
+		assert.True(t, accountRepository.upserted[0].OverdraftLimit.Equal(limit))
+		assert.Equal(t, model.EventTypeOverdraftLimitSet, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, map[string]interface{}{
+			"previous_limit": decimal.NewFromInt(200),
+			"limit":          limit,
+		}, eventRepository.placedEvents[0].EventData)
+	})
+}
+
+func TestAccountService_UpdateAccountProfile(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	newService := func(account model.Account) (*AccountService, *accountRepositoryMock, *eventRepositoryMock) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil},
+			errUpsertTx:            []error{nil},
+			account:                account,
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{}},
+		}
+
+		return &AccountService{
+			requestTimeThreshold: 30 * time.Second,
+			accountRepository:    accountRepository,
+			eventRepository:      eventRepository,
+		}, accountRepository, eventRepository
+	}
+
+	displayName := "Operating account"
+	accountType := string(model.AccountTypeBusiness)
+
+	t.Run("success", func(t *testing.T) {
+		svc, accountRepository, eventRepository := newService(model.Account{
+			ID:          1,
+			Type:        model.AccountTypePersonal,
+			DisplayName: "Main",
+			Labels:      map[string]string{"segment": "retail"},
+			Status:      model.AccountStatusActive,
+		})
+
+		err := svc.UpdateAccountProfile(ctx, dto.UpdateAccountProfileRequest{
+			ID:          1,
+			DisplayName: &displayName,
+			AccountType: &accountType,
+			Labels:      map[string]string{"segment": "retail"},
+		})
+		assert.NoError(t, err)
+
+		upserted := accountRepository.upserted[0]
+		assert.Equal(t, displayName, upserted.DisplayName)
+		assert.Equal(t, model.AccountTypeBusiness, upserted.Type)
+		assert.Equal(t, model.EventTypeAccountProfileUpdated, eventRepository.placedEvents[0].EventType)
+		// the labels did not change
+		assert.Equal(t, map[string]interface{}{
+			"display_name": displayName,
+			"account_type": model.AccountTypeBusiness,
+		}, eventRepository.placedEvents[0].EventData)
+	})
+
+	t.Run("success_unchanged", func(t *testing.T) {
+		svc, accountRepository, eventRepository := newService(model.Account{
+			ID:          1,
+			DisplayName: displayName,
+			Status:      model.AccountStatusActive,
+		})
+
+		err := svc.UpdateAccountProfile(ctx, dto.UpdateAccountProfileRequest{ID: 1, DisplayName: &displayName})
+		assert.NoError(t, err)
+
+		assert.Empty(t, accountRepository.upserted)
+		assert.Empty(t, eventRepository.placedEvents)
+	})
+
+	t.Run("error_closed", func(t *testing.T) {
+		svc, _, _ := newService(model.Account{ID: 1, Status: model.AccountStatusClosed})
+
+		err := svc.UpdateAccountProfile(ctx, dto.UpdateAccountProfileRequest{ID: 1, DisplayName: &displayName})
+		assert.ErrorIs(t, err, ErrAccountClosed)
+	})
+
+	t.Run("error_idempotency", func(t *testing.T) {
+		svc, _, eventRepository := newService(model.Account{ID: 1, Status: model.AccountStatusActive})
+		eventRepository.errFindAllByTransactionID = []error{nil}
+
+		err := svc.UpdateAccountProfile(ctx, dto.UpdateAccountProfileRequest{ID: 1, DisplayName: &displayName})
+		assert.ErrorIs(t, err, ErrIdempotency)
+	})
 }
@@ -63,3 +63,243 @@ var ErrAccountAlreadyExists = exception.ApplicationError{
 	},
 	StatusCode: http.StatusConflict,
 }
+
+var ErrInvalidExecuteAt = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_execute_at",
+		Message:   "execute at must be in the future",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrScheduledTransferNotPending = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.scheduled_transfer_not_pending",
+		Message:   "scheduled transfer is not pending anymore",
+	},
+	StatusCode: http.StatusConflict,
+}
+
+var ErrInvalidRecurrence = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_recurrence",
+		Message:   "invalid recurrence rule",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrInvalidStartAt = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_start_at",
+		Message:   "start at must be in the future",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrInvalidEndAt = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_end_at",
+		Message:   "end at must be after start at",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrStandingOrderNotActive = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.standing_order_not_active",
+		Message:   "standing order is not active anymore",
+	},
+	StatusCode: http.StatusConflict,
+}
+
+var ErrAccountFrozen = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.account_frozen",
+		Message:   "account is frozen",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrAccountClosed = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.account_closed",
+		Message:   "account is closed",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrAccountNotFrozen = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.account_not_frozen",
+		Message:   "account is not frozen",
+	},
+	StatusCode: http.StatusConflict,
+}
+
+var ErrAccountBalanceNotZero = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.account_balance_not_zero",
+		Message:   "account balance must be zero or a sweep account must be provided",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrSweepAccountNotFound = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.sweep_account_not_found",
+		Message:   "sweep account not found",
+	},
+	StatusCode: http.StatusNotFound,
+}
+
+var ErrPerTransactionLimitExceeded = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.per_transaction_limit_exceeded",
+		Message:   "amount exceeds the per transaction limit",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrDailyLimitExceeded = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.daily_limit_exceeded",
+		Message:   "daily outgoing limit exceeded",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrMonthlyLimitExceeded = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.monthly_limit_exceeded",
+		Message:   "monthly outgoing limit exceeded",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrHourlyCountLimitExceeded = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.hourly_count_limit_exceeded",
+		Message:   "maximum number of transfers per hour reached",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrInvalidCursor = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_cursor",
+		Message:   "cursor is invalid or was created for another sort",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrCurrencyMismatch = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.currency_mismatch",
+		Message:   "source and destination accounts have different currencies",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrTransferNotPendingApproval = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.transfer_not_pending_approval",
+		Message:   "transfer is not pending approval",
+	},
+	StatusCode: http.StatusConflict,
+}
+
+var ErrTransferApprovalExpired = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.transfer_approval_expired",
+		Message:   "transfer approval has expired",
+	},
+	StatusCode: http.StatusConflict,
+}
+
+var ErrTransferSelfReview = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.transfer_self_review",
+		Message:   "the initiator of a transfer cannot approve or reject it",
+	},
+	StatusCode: http.StatusForbidden,
+}
+
+var ErrTransferInitiatorRequired = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.transfer_initiator_required",
+		Message:   "a transfer that requires approval must be initiated by an authenticated client",
+	},
+	StatusCode: http.StatusForbidden,
+}
+
+var ErrInvalidBankStatement = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_bank_statement",
+		Message:   "bank statement could not be read",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrEmptyBankStatement = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.empty_bank_statement",
+		Message:   "bank statement has no booked lines",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrInvalidPaymentInitiation = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.invalid_payment_initiation",
+		Message:   "file is not a valid pain.001.001.09 customer credit transfer initiation",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrPaymentBatchTooLarge = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.payment_batch_too_large",
+		Message:   "payment batch has more transactions than allowed",
+	},
+	StatusCode: http.StatusBadRequest,
+}
+
+var ErrDuplicatePaymentBatch = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.duplicate_payment_batch",
+		Message:   "a payment batch with the same message id was already received",
+	},
+	StatusCode: http.StatusConflict,
+}
+
+var ErrWebhookDisabled = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.webhook_disabled",
+		Message:   "webhook is disabled",
+	},
+	StatusCode: http.StatusConflict,
+}
+
+var ErrTransferDenied = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.transfer_denied",
+		Message:   "transfer was declined by the risk rules",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrTransferBlocked = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.transfer_blocked",
+		Message:   "transfer was blocked by the sanctions screening",
+	},
+	StatusCode: http.StatusUnprocessableEntity,
+}
+
+var ErrMonitoringAlertReviewed = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.monitoring_alert_reviewed",
+		Message:   "monitoring alert was already reviewed",
+	},
+	StatusCode: http.StatusConflict,
+}
@@ -5,13 +5,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ijalalfrz/go-event-source/internal/app/model"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/fee"
 	"github.com/shopspring/decimal"
 )
 
-type AccountEventCollector struct {
+// eventCollector collects the events of a single aggregate and places them within a DB transaction.
+type eventCollector struct {
 	eventRepository EventRepository
 	sequenceNumber  int64
 	eventVersion    string
@@ -21,13 +24,13 @@ type AccountEventCollector struct {
 	events          []model.Event
 }
 
-func NewAccountEventCollector(ctx context.Context, eventRepository EventRepository, aggregateID int64,
-	transactionID string, eventVersion string,
-) (*AccountEventCollector, error) {
+func newEventCollector(ctx context.Context, eventRepository EventRepository, aggregateType model.AggregateType,
+	aggregateID int64, transactionID string, eventVersion string,
+) (eventCollector, error) {
 	// get last sequence
-	event, err := eventRepository.FindLastByAggregateID(ctx, aggregateID)
+	event, err := eventRepository.FindLastByAggregateID(ctx, aggregateType, aggregateID)
 	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
-		return nil, fmt.Errorf("failed to find last event: %w", err)
+		return eventCollector{}, fmt.Errorf("failed to find last event: %w", err)
 	}
 
 	sequenceNumber := int64(0)
@@ -35,19 +38,67 @@ func NewAccountEventCollector(ctx context.Context, eventRepository EventReposito
 		sequenceNumber = event.SequenceNumber
 	}
 
-	return &AccountEventCollector{
+	return eventCollector{
 		eventRepository: eventRepository,
 		aggregateID:     aggregateID,
-		aggregateType:   model.AggregateTypeAccount,
+		aggregateType:   aggregateType,
 		transactionID:   transactionID,
 		sequenceNumber:  sequenceNumber,
 		eventVersion:    eventVersion,
 	}, nil
 }
 
-func (e *AccountEventCollector) OnInitBalanceEvent(amount decimal.Decimal) {
+type AccountEventCollector struct {
+	eventCollector
+	// shard is set when the events belong to a shard of a hot account.
+	shard *model.AccountShard
+}
+
+func NewAccountEventCollector(ctx context.Context, eventRepository EventRepository, aggregateID int64,
+	transactionID string, eventVersion string,
+) (*AccountEventCollector, error) {
+	collector, err := newEventCollector(ctx, eventRepository, model.AggregateTypeAccount,
+		aggregateID, transactionID, eventVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountEventCollector{eventCollector: collector}, nil
+}
+
+// NewAccountShardEventCollector creates the event collector of a shard of a hot account, each shard has its own
+// event stream so the credits to the account do not compete for the next sequence number.
+func NewAccountShardEventCollector(ctx context.Context, eventRepository EventRepository, shard model.AccountShard,
+	transactionID string, eventVersion string,
+) (*AccountEventCollector, error) {
+	collector, err := newEventCollector(ctx, eventRepository, model.AggregateTypeAccountShard,
+		shard.ID, transactionID, eventVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountEventCollector{eventCollector: collector, shard: &shard}, nil
+}
+
+// apply adds the account and the number of the shard to the events of a shard, they are part of the history of the
+// account.
+func (e *AccountEventCollector) apply(event model.Event) {
+	if payload, ok := event.EventData.(map[string]interface{}); ok && e.shard != nil {
+		payload["account_id"] = e.shard.AccountID
+		payload["shard_no"] = e.shard.ShardNo
+	}
+
+	e.eventCollector.apply(event)
+}
+
+func (e *AccountEventCollector) OnInitBalanceEvent(account model.Account) {
 	payload := map[string]interface{}{
-		"initial_balance": amount,
+		"account_type":    account.Type,
+		"currency":        account.Currency,
+		"display_name":    account.DisplayName,
+		"owner_reference": account.OwnerReference,
+		"labels":          account.Labels,
+		"initial_balance": account.Balance,
 	}
 	event := model.Event{
 		Version:   e.eventVersion,
@@ -57,10 +108,11 @@ func (e *AccountEventCollector) OnInitBalanceEvent(amount decimal.Decimal) {
 	e.apply(event)
 }
 
-func (e *AccountEventCollector) OnDepositReceivedEvent(source string, amount decimal.Decimal) {
+// OnDepositReceivedEvent records the initial deposit, it is funded by the funding system account.
+func (e *AccountEventCollector) OnDepositReceivedEvent(sourceAccountID int64, amount decimal.Decimal) {
 	payload := map[string]interface{}{
-		"source": source,
-		"amount": amount,
+		"source_account_id": sourceAccountID,
+		"amount":            amount,
 	}
 	event := model.Event{
 		Version:   e.eventVersion,
@@ -98,7 +150,340 @@ func (e *AccountEventCollector) OnAddBalanceEvent(sourceAccountID int64, amount
 	e.apply(event)
 }
 
-func (e *AccountEventCollector) apply(event model.Event) {
+func (e *AccountEventCollector) OnFrozenEvent(reason model.AccountStatusReason) {
+	payload := map[string]interface{}{
+		"reason": reason,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeAccountFrozen,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+func (e *AccountEventCollector) OnUnfrozenEvent(reason model.AccountStatusReason) {
+	payload := map[string]interface{}{
+		"reason": reason,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeAccountUnfrozen,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+func (e *AccountEventCollector) OnClosedEvent(reason model.AccountStatusReason, sweepAccountID *int64,
+	sweptAmount decimal.Decimal,
+) {
+	payload := map[string]interface{}{
+		"reason":           reason,
+		"sweep_account_id": sweepAccountID,
+		"swept_amount":     sweptAmount,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeAccountClosed,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+// OnShardsConsolidatedEvent records the balance of the shards moved into the account, the balance of the account
+// does not change.
+func (e *AccountEventCollector) OnShardsConsolidatedEvent(shards []model.AccountShard) {
+	consolidated := make([]map[string]interface{}, 0, len(shards))
+
+	for _, shard := range shards {
+		consolidated = append(consolidated, map[string]interface{}{
+			"shard_no": shard.ShardNo,
+			"amount":   shard.Balance,
+		})
+	}
+
+	payload := map[string]interface{}{
+		"amount": model.ShardsBalance(shards),
+		"shards": consolidated,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeShardsConsolidated,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+func (e *AccountEventCollector) OnOverdraftLimitSetEvent(previousLimit decimal.Decimal, limit decimal.Decimal) {
+	payload := map[string]interface{}{
+		"previous_limit": previousLimit,
+		"limit":          limit,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeOverdraftLimitSet,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+// OnProfileUpdatedEvent records the profile fields that changed with their new value.
+func (e *AccountEventCollector) OnProfileUpdatedEvent(changes map[string]interface{}) {
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeAccountProfileUpdated,
+		EventData: changes,
+	}
+	e.apply(event)
+}
+
+func (e *AccountEventCollector) OnFeeChargedEvent(revenueAccountID int64, transferFee fee.Fee) {
+	payload := map[string]interface{}{
+		"revenue_account_id": revenueAccountID,
+		"amount":             transferFee.Amount,
+		"rule":               transferFee.Rule,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeFeeCharged,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+func (e *AccountEventCollector) OnFeeCollectedEvent(sourceAccountID int64, transferFee fee.Fee) {
+	payload := map[string]interface{}{
+		"source_account_id": sourceAccountID,
+		"amount":            transferFee.Amount,
+		"rule":              transferFee.Rule,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeFeeCollected,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+func (e *AccountEventCollector) OnInterestAccruedEvent(accrual model.InterestAccrual) {
+	payload := map[string]interface{}{
+		"accrual_date": accrual.AccrualDate.Format(time.DateOnly),
+		"balance":      accrual.Balance,
+		"rate":         accrual.Rate,
+		"amount":       accrual.Amount,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeInterestAccrued,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+func (e *AccountEventCollector) OnInterestPostedEvent(periodEnd time.Time, amount decimal.Decimal,
+	accrualCount int,
+) {
+	payload := map[string]interface{}{
+		"period_end":    periodEnd.Format(time.DateOnly),
+		"accrual_count": accrualCount,
+		"amount":        amount,
+	}
+
+	event := model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeInterestPosted,
+		EventData: payload,
+	}
+	e.apply(event)
+}
+
+type StandingOrderEventCollector struct {
+	eventCollector
+}
+
+func NewStandingOrderEventCollector(ctx context.Context, eventRepository EventRepository, aggregateID int64,
+	transactionID string, eventVersion string,
+) (*StandingOrderEventCollector, error) {
+	collector, err := newEventCollector(ctx, eventRepository, model.AggregateTypeStandingOrder,
+		aggregateID, transactionID, eventVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StandingOrderEventCollector{eventCollector: collector}, nil
+}
+
+func (e *StandingOrderEventCollector) OnCreatedEvent(standingOrder model.StandingOrder) {
+	payload := map[string]interface{}{
+		"source_account_id":      standingOrder.SourceAccountID,
+		"destination_account_id": standingOrder.DestinationAccountID,
+		"amount":                 standingOrder.Amount,
+		"recurrence":             standingOrder.Recurrence,
+		"start_at":               standingOrder.StartAt,
+		"end_at":                 standingOrder.EndAt,
+		"max_occurrences":        standingOrder.MaxOccurrences,
+		"first_occurrence_at":    standingOrder.OccurrenceAt,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeStandingOrderCreated,
+		EventData: payload,
+	})
+}
+
+func (e *StandingOrderEventCollector) OnExecutedEvent(standingOrder model.StandingOrder) {
+	payload := map[string]interface{}{
+		"occurrence":     standingOrder.Occurrence,
+		"occurrence_at":  standingOrder.OccurrenceAt,
+		"attempt":        standingOrder.Attempt + 1,
+		"transaction_id": standingOrder.OccurrenceTransactionID(),
+		"amount":         standingOrder.Amount,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeStandingOrderExecuted,
+		EventData: payload,
+	})
+}
+
+func (e *StandingOrderEventCollector) OnSkippedEvent(standingOrder model.StandingOrder, reason string,
+	retryAt *time.Time,
+) {
+	payload := map[string]interface{}{
+		"occurrence":    standingOrder.Occurrence,
+		"occurrence_at": standingOrder.OccurrenceAt,
+		"attempt":       standingOrder.Attempt + 1,
+		"reason":        reason,
+		"retry_at":      retryAt,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeStandingOrderSkipped,
+		EventData: payload,
+	})
+}
+
+func (e *StandingOrderEventCollector) OnAwaitingApprovalEvent(standingOrder model.StandingOrder) {
+	payload := map[string]interface{}{
+		"occurrence":     standingOrder.Occurrence,
+		"occurrence_at":  standingOrder.OccurrenceAt,
+		"attempt":        standingOrder.Attempt + 1,
+		"transaction_id": standingOrder.OccurrenceTransactionID(),
+		"amount":         standingOrder.Amount,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeStandingOrderAwaitingApproval,
+		EventData: payload,
+	})
+}
+
+func (e *StandingOrderEventCollector) OnCompletedEvent(standingOrder model.StandingOrder) {
+	payload := map[string]interface{}{
+		"executed_count": standingOrder.ExecutedCount,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeStandingOrderCompleted,
+		EventData: payload,
+	})
+}
+
+func (e *StandingOrderEventCollector) OnCancelledEvent(standingOrder model.StandingOrder) {
+	payload := map[string]interface{}{
+		"executed_count": standingOrder.ExecutedCount,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeStandingOrderCancelled,
+		EventData: payload,
+	})
+}
+
+type TransferEventCollector struct {
+	eventCollector
+}
+
+func NewTransferEventCollector(ctx context.Context, eventRepository EventRepository, aggregateID int64,
+	transactionID string, eventVersion string,
+) (*TransferEventCollector, error) {
+	collector, err := newEventCollector(ctx, eventRepository, model.AggregateTypeTransfer,
+		aggregateID, transactionID, eventVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransferEventCollector{eventCollector: collector}, nil
+}
+
+func (e *TransferEventCollector) OnApprovalRequestedEvent(transferApproval model.TransferApproval) {
+	payload := map[string]interface{}{
+		"source_account_id":      transferApproval.SourceAccountID,
+		"destination_account_id": transferApproval.DestinationAccountID,
+		"amount":                 transferApproval.Amount,
+		"initiated_by":           transferApproval.InitiatedBy,
+		"expires_at":             transferApproval.ExpiresAt,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeTransferApprovalRequested,
+		EventData: payload,
+	})
+}
+
+func (e *TransferEventCollector) OnApprovedEvent(approvedBy string) {
+	payload := map[string]interface{}{
+		"approved_by": approvedBy,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeTransferApproved,
+		EventData: payload,
+	})
+}
+
+func (e *TransferEventCollector) OnRejectedEvent(rejectedBy string, reason string) {
+	payload := map[string]interface{}{
+		"rejected_by": rejectedBy,
+		"reason":      reason,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeTransferRejected,
+		EventData: payload,
+	})
+}
+
+func (e *TransferEventCollector) OnApprovalExpiredEvent(transferApproval model.TransferApproval) {
+	payload := map[string]interface{}{
+		"expires_at": transferApproval.ExpiresAt,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeTransferApprovalExpired,
+		EventData: payload,
+	})
+}
+
+func (e *eventCollector) apply(event model.Event) {
 	e.sequenceNumber++
 	event.SequenceNumber = e.sequenceNumber
 	event.AggregateID = e.aggregateID
@@ -108,7 +493,12 @@ func (e *AccountEventCollector) apply(event model.Event) {
 	e.events = append(e.events, event)
 }
 
-func (e *AccountEventCollector) Place(ctx context.Context, tx *sql.Tx) error {
+// Events returns the events collected since the last placement.
+func (e *eventCollector) Events() []model.Event {
+	return e.events
+}
+
+func (e *eventCollector) Place(ctx context.Context, tx *sql.Tx) error {
 	err := e.eventRepository.CreateBulkTx(ctx, tx, e.events)
 	if err != nil {
 		return fmt.Errorf("failed to create bulk events: %w", err)
@@ -120,3 +510,153 @@ func (e *AccountEventCollector) Place(ctx context.Context, tx *sql.Tx) error {
 
 	return nil
 }
+
+type PaymentBatchEventCollector struct {
+	eventCollector
+}
+
+func NewPaymentBatchEventCollector(ctx context.Context, eventRepository EventRepository, aggregateID int64,
+	transactionID string, eventVersion string,
+) (*PaymentBatchEventCollector, error) {
+	collector, err := newEventCollector(ctx, eventRepository, model.AggregateTypePaymentBatch,
+		aggregateID, transactionID, eventVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentBatchEventCollector{eventCollector: collector}, nil
+}
+
+func (e *PaymentBatchEventCollector) OnReceivedEvent(batch model.PaymentBatch) {
+	payload := map[string]interface{}{
+		"message_id":             batch.MessageID,
+		"initiating_party":       batch.InitiatingParty,
+		"number_of_transactions": batch.NumberOfTransactions,
+		"control_sum":            batch.ControlSum,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypePaymentBatchReceived,
+		EventData: payload,
+	})
+}
+
+func (e *PaymentBatchEventCollector) OnRejectedEvent(batch model.PaymentBatch) {
+	payload := map[string]interface{}{
+		"reason_code": batch.ReasonCode,
+		"reason":      batch.Reason,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypePaymentBatchRejected,
+		EventData: payload,
+	})
+}
+
+func (e *PaymentBatchEventCollector) OnInstructionExecutedEvent(instruction model.PaymentInstruction) {
+	payload := map[string]interface{}{
+		"sequence":               instruction.Sequence,
+		"end_to_end_id":          instruction.EndToEndID,
+		"transaction_id":         instruction.TransferTransactionID(),
+		"source_account_id":      instruction.SourceAccountID,
+		"destination_account_id": instruction.DestinationAccountID,
+		"amount":                 instruction.Amount,
+		"status":                 instruction.Status,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypePaymentInstructionExecuted,
+		EventData: payload,
+	})
+}
+
+func (e *PaymentBatchEventCollector) OnInstructionRejectedEvent(instruction model.PaymentInstruction) {
+	payload := map[string]interface{}{
+		"sequence":      instruction.Sequence,
+		"end_to_end_id": instruction.EndToEndID,
+		"reason_code":   instruction.ReasonCode,
+		"reason":        instruction.Reason,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypePaymentInstructionRejected,
+		EventData: payload,
+	})
+}
+
+func (e *PaymentBatchEventCollector) OnCompletedEvent() {
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypePaymentBatchCompleted,
+		EventData: map[string]interface{}{},
+	})
+}
+
+type RiskDecisionEventCollector struct {
+	eventCollector
+}
+
+func NewRiskDecisionEventCollector(ctx context.Context, eventRepository EventRepository, aggregateID int64,
+	transactionID string, eventVersion string,
+) (*RiskDecisionEventCollector, error) {
+	collector, err := newEventCollector(ctx, eventRepository, model.AggregateTypeRiskDecision,
+		aggregateID, transactionID, eventVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RiskDecisionEventCollector{eventCollector: collector}, nil
+}
+
+func (e *RiskDecisionEventCollector) OnAssessedEvent(riskDecision model.RiskDecision) {
+	payload := map[string]interface{}{
+		"source_account_id":      riskDecision.SourceAccountID,
+		"destination_account_id": riskDecision.DestinationAccountID,
+		"amount":                 riskDecision.Amount,
+		"action":                 riskDecision.Action,
+		"rule":                   riskDecision.Rule,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeTransferRiskAssessed,
+		EventData: payload,
+	})
+}
+
+type SanctionsScreeningEventCollector struct {
+	eventCollector
+}
+
+func NewSanctionsScreeningEventCollector(ctx context.Context, eventRepository EventRepository, aggregateID int64,
+	transactionID string, eventVersion string,
+) (*SanctionsScreeningEventCollector, error) {
+	collector, err := newEventCollector(ctx, eventRepository, model.AggregateTypeSanctionsScreening,
+		aggregateID, transactionID, eventVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SanctionsScreeningEventCollector{eventCollector: collector}, nil
+}
+
+func (e *SanctionsScreeningEventCollector) OnHitEvent(screening model.SanctionsScreening) {
+	payload := map[string]interface{}{
+		"source_account_id":      screening.SourceAccountID,
+		"destination_account_id": screening.DestinationAccountID,
+		"amount":                 screening.Amount,
+		"action":                 screening.Action,
+		"list_version":           screening.ListVersion,
+		"hits":                   screening.Hits,
+	}
+
+	e.apply(model.Event{
+		Version:   e.eventVersion,
+		EventType: model.EventTypeTransferSanctionsHit,
+		EventData: payload,
+	})
+}
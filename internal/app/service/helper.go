@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
 )
 
 func getRequestContext(ctx context.Context, requestTimeThreshold time.Duration) (dto.RequestContext, error) {
@@ -23,3 +27,51 @@ func getRequestContext(ctx context.Context, requestTimeThreshold time.Duration)
 
 	return reqContext, nil
 }
+
+// checkAccountOperable rejects any balance movement on a frozen or closed account.
+func checkAccountOperable(account model.Account) error {
+	var err exception.ApplicationError
+
+	switch {
+	case account.IsFrozen():
+		err = ErrAccountFrozen
+	case account.IsClosed():
+		err = ErrAccountClosed
+	default:
+		return nil
+	}
+
+	err.MessageVars = map[string]interface{}{
+		"id": account.ID,
+	}
+
+	return err
+}
+
+// consolidateShardsTx moves the balance of the shards of an account into the account, the shards are locked so a
+// credit to them waits until the transaction ends. It returns the consolidated shards, none when the shards have no
+// balance.
+func consolidateShardsTx(ctx context.Context, dbTx *sql.Tx, accountShardRepository AccountShardRepository,
+	account *model.Account,
+) ([]model.AccountShard, error) {
+	shards, err := accountShardRepository.FindAllByAccountIDForUpdateTx(ctx, dbTx, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account shards: %w", err)
+	}
+
+	balance := model.ShardsBalance(shards)
+	if balance.IsZero() {
+		return nil, nil
+	}
+
+	now := time.Now()
+
+	if err := accountShardRepository.ResetAllByAccountIDTx(ctx, dbTx, account.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to reset account shards: %w", err)
+	}
+
+	account.Balance = account.Balance.Add(balance)
+	account.UpdatedAt = now
+
+	return shards, nil
+}
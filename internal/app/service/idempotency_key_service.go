@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+)
+
+type IdempotencyKeyClaimer interface {
+	ClaimTx(ctx context.Context, tx *sql.Tx, transactionID string, now time.Time) error
+}
+
+type IdempotencyKeyRepository interface {
+	IdempotencyKeyClaimer
+	DeleteAllExpired(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type IdempotencyKeyService struct {
+	idempotencyKeyRepository IdempotencyKeyRepository
+	retention                time.Duration
+	batchSize                int
+}
+
+func NewIdempotencyKeyService(idempotencyKeyRepository IdempotencyKeyRepository, retention time.Duration,
+	batchSize int,
+) *IdempotencyKeyService {
+	return &IdempotencyKeyService{
+		idempotencyKeyRepository: idempotencyKeyRepository,
+		retention:                retention,
+		batchSize:                batchSize,
+	}
+}
+
+// ExpireDue deletes the transaction ids claimed longer than the retention period ago, a batch at a time until none
+// is left. A retry of an expired transaction id is no longer replayed, its events still reject it.
+func (s *IdempotencyKeyService) ExpireDue(ctx context.Context) (int, error) {
+	before := time.Now().Add(-s.retention)
+	expired := 0
+
+	for {
+		deleted, err := s.idempotencyKeyRepository.DeleteAllExpired(ctx, before, s.batchSize)
+		if err != nil {
+			return expired, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+		}
+
+		expired += deleted
+
+		if deleted == 0 || deleted < s.batchSize {
+			return expired, nil
+		}
+	}
+}
+
+// claimTransactionID claims the transaction id of an operation within its transaction, the loser of two concurrent
+// requests with the same transaction id waits for the winner and gets ErrIdempotency once it commits.
+func claimTransactionID(ctx context.Context, dbTx *sql.Tx, idempotencyKeyClaimer IdempotencyKeyClaimer,
+	transactionID string,
+) error {
+	err := idempotencyKeyClaimer.ClaimTx(ctx, dbTx, transactionID, time.Now())
+	if err != nil && errors.Is(err, exception.ErrRecordNotUnique) {
+		return ErrIdempotency
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to claim transaction id: %w", err)
+	}
+
+	return nil
+}
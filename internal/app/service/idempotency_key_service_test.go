@@ -0,0 +1,41 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeyService_ExpireDue(t *testing.T) {
+	t.Run("success_until_last_batch", func(t *testing.T) {
+		idempotencyKeyRepository := &idempotencyKeyRepositoryMock{deleted: []int{2, 2, 1}}
+		svc := NewIdempotencyKeyService(idempotencyKeyRepository, time.Hour, 2)
+
+		count, err := svc.ExpireDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 5, count)
+		assert.Equal(t, 3, idempotencyKeyRepository.deleteCalls)
+	})
+
+	t.Run("success_nothing_expired", func(t *testing.T) {
+		svc := NewIdempotencyKeyService(&idempotencyKeyRepositoryMock{}, time.Hour, 2)
+
+		count, err := svc.ExpireDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("error_delete", func(t *testing.T) {
+		svc := NewIdempotencyKeyService(&idempotencyKeyRepositoryMock{
+			errDeleteAllExpired: errors.New("internal db error"),
+		}, time.Hour, 2)
+
+		_, err := svc.ExpireDue(context.Background())
+		assert.ErrorContains(t, err, "internal db error")
+	})
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+)
+
+type InterestRepository interface {
+	WithTransaction(ctx context.Context, txFunc func(context.Context, *sql.Tx) error) error
+	UpsertRate(ctx context.Context, rate *model.AccountInterestRate) error
+	DeleteRate(ctx context.Context, accountID int64) error
+	FindRateByAccountID(ctx context.Context, accountID int64) (model.AccountInterestRate, error)
+	FindAllAccruable(ctx context.Context, afterID int64, limit int) ([]model.InterestAccount, error)
+	CreateAccrualTx(ctx context.Context, tx *sql.Tx, accrual *model.InterestAccrual) (bool, error)
+	FindAllUnpostedAccountIDs(ctx context.Context, periodEnd time.Time, limit int) ([]int64, error)
+	PostAccrualsTx(ctx context.Context, tx *sql.Tx, accountID int64, periodEnd time.Time,
+		postingTransactionID string, postedAt time.Time) (decimal.Decimal, int, error)
+}
+
+type BalanceRepository interface {
+	FindBalanceAt(ctx context.Context, accountID int64, at time.Time) (decimal.Decimal, error)
+}
+
+// InterestPolicy holds the product rates and how interest is accrued.
+type InterestPolicy struct {
+	Rates model.InterestRates
+	// DayCount is the number of days of the interest year.
+	DayCount int
+	// CatchUpDays bounds how many past dates are accrued for an account, e.g. after the job was down.
+	CatchUpDays int
+}
+
+type InterestService struct {
+	interestRepository InterestRepository
+	balanceRepository  BalanceRepository
+	accountRepository  AccountRepository
+	eventRepository    EventRepository
+	journalRepository  JournalRepository
+	ledgerAccounts     LedgerAccounts
+	policy             InterestPolicy
+	eventVersion       string
+	batchSize          int
+}
+
+func NewInterestService(interestRepository InterestRepository, balanceRepository BalanceRepository,
+	accountRepository AccountRepository, eventRepository EventRepository, journalRepository JournalRepository,
+	ledgerAccounts LedgerAccounts, policy InterestPolicy, eventVersion string, batchSize int,
+) *InterestService {
+	return &InterestService{
+		interestRepository: interestRepository,
+		balanceRepository:  balanceRepository,
+		accountRepository:  accountRepository,
+		eventRepository:    eventRepository,
+		journalRepository:  journalRepository,
+		ledgerAccounts:     ledgerAccounts,
+		policy:             policy,
+		eventVersion:       eventVersion,
+		batchSize:          batchSize,
+	}
+}
+
+// GetInterestRate godoc
+// @Summary      Get Interest Rate
+// @Description  Get the effective annual interest rate of an Account and its override
+// @Tags         Account
+// @ID           getInterestRate
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Success      200  {object}  dto.InterestRateResponse	"Interest rate"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/accounts/{id}/interest-rate [get].
+func (s *InterestService) GetInterestRate(ctx context.Context,
+	req dto.GetAccountRequest,
+) (dto.InterestRateResponse, error) {
+	account, err := s.accountRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return dto.InterestRateResponse{}, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	var override *decimal.Decimal
+
+	rate, err := s.interestRepository.FindRateByAccountID(ctx, req.ID)
+	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
+		return dto.InterestRateResponse{}, fmt.Errorf("failed to find interest rate: %w", err)
+	}
+
+	if err == nil {
+		override = &rate.Rate
+	}
+
+	interestAccount := model.InterestAccount{AccountID: account.ID, Type: account.Type, RateOverride: override}
+
+	return dto.InterestRateResponse{
+		AccountID:   account.ID,
+		AccountType: string(account.Type),
+		Rate:        interestAccount.Rate(s.policy.Rates),
+		Override:    override,
+	}, nil
+}
+
+// SetInterestRate godoc
+// @Summary      Set Interest Rate
+// @Description  Override the annual interest rate of the account type for an Account, a null rate uses the account type rate
+// @Tags         Account
+// @ID           setInterestRate
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Param        req body set interest rate	body		dto.SetInterestRateRequest	true	"Interest rate"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/accounts/{id}/interest-rate [put].
+func (s *InterestService) SetInterestRate(ctx context.Context, req dto.SetInterestRateRequest) error {
+	if _, err := s.accountRepository.FindByID(ctx, req.ID); err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	if req.Rate == nil {
+		if err := s.interestRepository.DeleteRate(ctx, req.ID); err != nil {
+			return fmt.Errorf("failed to delete interest rate: %w", err)
+		}
+
+		return nil
+	}
+
+	rate := &model.AccountInterestRate{
+		AccountID: req.ID,
+		Rate:      *req.Rate,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.interestRepository.UpsertRate(ctx, rate); err != nil {
+		return fmt.Errorf("failed to upsert interest rate: %w", err)
+	}
+
+	return nil
+}
+
+// AccrueDue accrues the interest of every past date that was not accrued yet, up to yesterday (UTC).
+// Each date is accrued in its own transaction, a date that was already accrued is skipped, so the job can be
+// re-run after a failure. It returns the number of accruals recorded.
+func (s *InterestService) AccrueDue(ctx context.Context) (int, error) {
+	today := model.AccrualDate(time.Now())
+	oldestDate := today.AddDate(0, 0, -s.policy.CatchUpDays)
+	accrued := 0
+
+	var afterID int64
+
+	for {
+		accounts, err := s.interestRepository.FindAllAccruable(ctx, afterID, s.batchSize)
+		if err != nil {
+			return accrued, fmt.Errorf("failed to find accruable accounts: %w", err)
+		}
+
+		for _, account := range accounts {
+			count, err := s.accrueAccount(ctx, account, oldestDate, today)
+			accrued += count
+
+			if err != nil {
+				// keep going, the remaining dates are accrued on the next run
+				slog.ErrorContext(ctx, "failed to accrue interest",
+					slog.Int64("account_id", account.AccountID),
+					slog.String("error", err.Error()))
+			}
+		}
+
+		if len(accounts) < s.batchSize {
+			return accrued, nil
+		}
+
+		afterID = accounts[len(accounts)-1].AccountID
+	}
+}
+
+func (s *InterestService) accrueAccount(ctx context.Context, account model.InterestAccount,
+	oldestDate time.Time, today time.Time,
+) (int, error) {
+	rate := account.Rate(s.policy.Rates)
+	if !rate.IsPositive() {
+		return 0, nil
+	}
+
+	accrued := 0
+
+	date := account.FirstAccrualDate()
+	if date.Before(oldestDate) {
+		date = oldestDate
+	}
+
+	for ; date.Before(today); date = date.AddDate(0, 0, 1) {
+		created, err := s.accrue(ctx, account.AccountID, date, rate)
+		if err != nil {
+			return accrued, err
+		}
+
+		if created {
+			accrued++
+		}
+	}
+
+	return accrued, nil
+}
+
+// accrue records the interest earned on the end-of-day balance of date.
+func (s *InterestService) accrue(ctx context.Context, accountID int64, date time.Time,
+	rate decimal.Decimal,
+) (bool, error) {
+	balance, err := s.balanceRepository.FindBalanceAt(ctx, accountID, date.AddDate(0, 0, 1))
+	if err != nil {
+		return false, fmt.Errorf("failed to find end of day balance: %w", err)
+	}
+
+	accrual := model.NewInterestAccrual(accountID, date, balance, rate, s.policy.DayCount)
+
+	eventCollector, err := NewAccountEventCollector(ctx, s.eventRepository, accountID,
+		accrual.TransactionID, s.eventVersion)
+	if err != nil {
+		return false, fmt.Errorf("failed to create account event collector: %w", err)
+	}
+
+	created := false
+
+	err = s.interestRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		created, err = s.interestRepository.CreateAccrualTx(ctx, dbTx, &accrual)
+		if err != nil {
+			return fmt.Errorf("failed to create accrual: %w", err)
+		}
+
+		if !created {
+			return nil
+		}
+
+		eventCollector.OnInterestAccruedEvent(accrual)
+
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to accrue interest: %w", err)
+	}
+
+	return created, nil
+}
+
+// PostDue credits the interest accrued during the previous months to the accounts. The accruals are marked as
+// posted within the same transaction, so re-running the job never credits them twice. It returns the number of
+// accounts that were credited.
+func (s *InterestService) PostDue(ctx context.Context) (int, error) {
+	periodEnd := model.InterestPostingPeriodEnd(time.Now())
+
+	accountIDs, err := s.interestRepository.FindAllUnpostedAccountIDs(ctx, periodEnd, s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unposted accruals: %w", err)
+	}
+
+	posted := 0
+
+	for _, accountID := range accountIDs {
+		done, err := s.post(ctx, accountID, periodEnd)
+		if err != nil {
+			// keep going, the accruals are still unposted and are posted on the next run
+			slog.ErrorContext(ctx, "failed to post interest",
+				slog.Int64("account_id", accountID),
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if done {
+			posted++
+		}
+	}
+
+	return posted, nil
+}
+
+// post credits the interest accrued until periodEnd to the account. A closed account is left out, a frozen account
+// is credited on purpose: the freeze stops the movements of the customer, not the interest the bank owes on the
+// balance, and the accruals left unposted would hold up the accounts posted after them.
+func (s *InterestService) post(ctx context.Context, accountID int64, periodEnd time.Time) (bool, error) {
+	transactionID := model.PostingTransactionID(accountID, periodEnd)
+
+	done := false
+
+	// the interest is posted within a transaction, it is run again on a deadlock
+	err := s.accountRepository.WithRetryableTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		done = false
+
+		// the balance is updated together with the accruals, the interest account paying them is locked with the
+		// account in the order of their ids
+		accounts, err := s.lockPostingAccounts(ctx, dbTx, accountID)
+		if err != nil {
+			return err
+		}
+
+		account := accounts[accountID]
+
+		if account.IsClosed() {
+			return nil
+		}
+
+		eventCollector, err := NewAccountEventCollector(ctx, s.eventRepository, accountID,
+			transactionID, s.eventVersion)
+		if err != nil {
+			return fmt.Errorf("failed to create account event collector: %w", err)
+		}
+
+		// the interest is paid by the interest account
+		interestEventCollector, err := NewAccountEventCollector(ctx, s.eventRepository,
+			s.ledgerAccounts.InterestAccountID, transactionID, s.eventVersion)
+		if err != nil {
+			return fmt.Errorf("failed to create account event collector: %w", err)
+		}
+
+		now := time.Now()
+
+		total, count, err := s.interestRepository.PostAccrualsTx(ctx, dbTx, accountID, periodEnd,
+			transactionID, now)
+		if err != nil {
+			return fmt.Errorf("failed to post accruals: %w", err)
+		}
+
+		if count == 0 {
+			return nil
+		}
+
+		amount := total.Round(2)
+
+		eventCollector.OnInterestPostedEvent(periodEnd, amount, count)
+
+		account.Balance = account.Balance.Add(amount)
+		account.UpdatedAt = now
+
+		eventCollectors := []*AccountEventCollector{eventCollector}
+
+		if amount.IsPositive() {
+			err := s.payInterest(ctx, dbTx, interestEventCollector, accounts[s.ledgerAccounts.InterestAccountID],
+				accountID, amount)
+			if err != nil {
+				return err
+			}
+
+			eventCollectors = append(eventCollectors, interestEventCollector)
+		}
+
+		err = recordJournalEntry(ctx, dbTx, s.journalRepository, transactionID, eventCollectors...)
+		if err != nil {
+			return err
+		}
+
+		for _, collector := range eventCollectors {
+			if err := collector.Place(ctx, dbTx); err != nil {
+				return fmt.Errorf("failed to place events: %w", err)
+			}
+		}
+
+		if err := s.accountRepository.UpsertTx(ctx, dbTx, &account); err != nil {
+			return fmt.Errorf("failed to upsert account: %w", err)
+		}
+
+		done = true
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to post interest: %w", err)
+	}
+
+	return done, nil
+}
+
+// lockPostingAccounts locks the credited account and the interest account in the order of their ids, like the
+// accounts of a transfer.
+func (s *InterestService) lockPostingAccounts(ctx context.Context, dbTx *sql.Tx,
+	accountID int64,
+) (map[int64]model.Account, error) {
+	accountIDs := []int64{accountID, s.ledgerAccounts.InterestAccountID}
+
+	return lockAccountsTx(ctx, dbTx, accountIDs, s.accountRepository.FindByIDForUpdateTx,
+		func(lockedID int64, err error) error {
+			if lockedID == accountID {
+				return fmt.Errorf("failed to find account: %w", err)
+			}
+
+			if errors.Is(err, exception.ErrRecordNotFound) {
+				return fmt.Errorf("system account %d not found", lockedID)
+			}
+
+			return fmt.Errorf("failed to find system account: %w", err)
+		})
+}
+
+// payInterest debits the posted interest from the interest account, it is locked with the credited account.
+func (s *InterestService) payInterest(ctx context.Context, dbTx *sql.Tx,
+	interestEventCollector *AccountEventCollector, interestAccount model.Account, accountID int64,
+	amount decimal.Decimal,
+) error {
+	interestAccount.Balance = interestAccount.Balance.Sub(amount)
+	interestAccount.UpdatedAt = time.Now()
+
+	interestEventCollector.OnSubBalanceEvent(accountID, amount)
+
+	if err := s.accountRepository.UpsertTx(ctx, dbTx, &interestAccount); err != nil {
+		return fmt.Errorf("failed to upsert account: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,347 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterestService_AccrueDue(t *testing.T) {
+	policy := InterestPolicy{
+		Rates: model.InterestRates{
+			model.AccountTypeSavings: decimal.RequireFromString("2.5"),
+		},
+		DayCount:    365,
+		CatchUpDays: 2,
+	}
+
+	today := model.AccrualDate(time.Now())
+	yesterday := today.AddDate(0, 0, -1)
+	lastAccrualDate := today.AddDate(0, 0, -3)
+	override := decimal.NewFromInt(1)
+
+	newEventRepository := func(calls int) *eventRepositoryMock {
+		errFindLast := make([]error, calls)
+		errCreateBulk := make([]error, calls)
+
+		for i := range errFindLast {
+			errFindLast[i] = exception.ErrRecordNotFound
+		}
+
+		return &eventRepositoryMock{
+			errFindLastByAggregateID: errFindLast,
+			errCreateBulkTx:          errCreateBulk,
+			events:                   []model.Event{{}},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			accounts: []model.InterestAccount{
+				{AccountID: 1, Type: model.AccountTypeSavings, LastAccrualDate: &lastAccrualDate},
+				{AccountID: 2, Type: model.AccountTypePersonal, CreatedAt: today.AddDate(-1, 0, 0)},
+				{AccountID: 3, Type: model.AccountTypeBusiness, CreatedAt: today.AddDate(-1, 0, 0), RateOverride: &override},
+			},
+			// the job failed after accruing the first catch up date of account 3
+			accrued: map[string]bool{
+				model.AccrualTransactionID(3, today.AddDate(0, 0, -2)): true,
+			},
+		}
+		eventRepository := newEventRepository(4)
+
+		svc := NewInterestService(interestRepository, &balanceRepositoryMock{balance: decimal.NewFromInt(3650)},
+			nil, eventRepository, nil, LedgerAccounts{}, policy, "1.0.0", 2)
+
+		accrued, err := svc.AccrueDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, accrued)
+		assert.Len(t, interestRepository.accruals, 3)
+
+		first := interestRepository.accruals[0]
+		assert.Equal(t, int64(1), first.AccountID)
+		assert.Equal(t, today.AddDate(0, 0, -2), first.AccrualDate)
+		assert.True(t, decimal.RequireFromString("0.25").Equal(first.Amount))
+		assert.Equal(t, model.AccrualTransactionID(1, first.AccrualDate), first.TransactionID)
+
+		last := interestRepository.accruals[2]
+		assert.Equal(t, int64(3), last.AccountID)
+		assert.Equal(t, yesterday, last.AccrualDate)
+		assert.True(t, decimal.NewFromInt(1).Equal(last.Rate))
+		assert.True(t, decimal.RequireFromString("0.1").Equal(last.Amount))
+
+		assert.Len(t, eventRepository.placedEvents, 3)
+
+		for _, event := range eventRepository.placedEvents {
+			assert.Equal(t, model.EventTypeInterestAccrued, event.EventType)
+		}
+	})
+
+	t.Run("success_up_to_date", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			accounts: []model.InterestAccount{
+				{AccountID: 1, Type: model.AccountTypeSavings, LastAccrualDate: &yesterday},
+			},
+		}
+
+		svc := NewInterestService(interestRepository, &balanceRepositoryMock{balance: decimal.NewFromInt(100)},
+			nil, newEventRepository(0), nil, LedgerAccounts{}, policy, "1.0.0", 2)
+
+		accrued, err := svc.AccrueDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, accrued)
+	})
+
+	t.Run("success_zero_interest_on_negative_balance", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			accounts: []model.InterestAccount{
+				{AccountID: 1, Type: model.AccountTypeSavings, LastAccrualDate: &lastAccrualDate},
+			},
+		}
+
+		svc := NewInterestService(interestRepository, &balanceRepositoryMock{balance: decimal.NewFromInt(-100)},
+			nil, newEventRepository(2), nil, LedgerAccounts{}, policy, "1.0.0", 2)
+
+		accrued, err := svc.AccrueDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, accrued)
+		assert.True(t, interestRepository.accruals[0].Amount.IsZero())
+	})
+
+	t.Run("error_find_balance", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			accounts: []model.InterestAccount{
+				{AccountID: 1, Type: model.AccountTypeSavings, LastAccrualDate: &lastAccrualDate},
+			},
+		}
+
+		svc := NewInterestService(interestRepository,
+			&balanceRepositoryMock{errFindBalanceAt: errors.New("internal db error")},
+			nil, newEventRepository(0), nil, LedgerAccounts{}, policy, "1.0.0", 2)
+
+		accrued, err := svc.AccrueDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, accrued)
+		assert.Empty(t, interestRepository.accruals)
+	})
+
+	t.Run("error_find_accounts", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{errFindAllAccruable: errors.New("internal db error")}
+
+		svc := NewInterestService(interestRepository, &balanceRepositoryMock{},
+			nil, newEventRepository(0), nil, LedgerAccounts{}, policy, "1.0.0", 2)
+
+		_, err := svc.AccrueDue(context.Background())
+
+		assert.ErrorContains(t, err, "internal db error")
+	})
+}
+
+func TestInterestService_PostDue(t *testing.T) {
+	periodEnd := model.InterestPostingPeriodEnd(time.Now())
+
+	newService := func(account model.Account,
+		interestRepository *interestRepositoryMock,
+	) (*InterestService, *accountRepositoryMock, *eventRepositoryMock, *ledgerRepositoryMock) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account:                account,
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:          []error{nil, nil},
+			events:                   []model.Event{{}},
+		}
+		ledgerRepository := &ledgerRepositoryMock{}
+
+		svc := NewInterestService(interestRepository, &balanceRepositoryMock{}, accountRepository,
+			eventRepository, ledgerRepository, LedgerAccounts{InterestAccountID: 900002}, InterestPolicy{},
+			"1.0.0", 10)
+
+		return svc, accountRepository, eventRepository, ledgerRepository
+	}
+
+	t.Run("success", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			unpostedAccountIDs: []int64{1},
+			postedTotal:        decimal.RequireFromString("12.34567"),
+			postedCount:        30,
+		}
+		svc, accountRepository, eventRepository, ledgerRepository := newService(model.Account{
+			ID:      1,
+			Balance: decimal.NewFromInt(1000),
+		}, interestRepository)
+
+		posted, err := svc.PostDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, posted)
+		assert.Equal(t, []string{model.PostingTransactionID(1, periodEnd)}, interestRepository.postingTransactionIDs)
+		// the interest account is debited first, the mock returns the same balance for both accounts
+		assert.True(t, decimal.RequireFromString("987.65").Equal(accountRepository.upserted[0].Balance))
+		assert.True(t, decimal.RequireFromString("1012.35").Equal(accountRepository.upserted[1].Balance))
+		assert.Len(t, eventRepository.placedEvents, 2)
+		assert.Equal(t, model.EventTypeInterestPosted, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.PostingTransactionID(1, periodEnd), eventRepository.placedEvents[0].TransactionID)
+		assert.Equal(t, model.EventTypeDebitBalance, eventRepository.placedEvents[1].EventType)
+		assert.Equal(t, int64(900002), eventRepository.placedEvents[1].AggregateID)
+
+		assert.Len(t, ledgerRepository.journalEntries, 1)
+		assert.Equal(t, []model.Posting{
+			{AccountID: 1, Direction: model.PostingDirectionCredit, Amount: decimal.RequireFromString("12.35")},
+			{AccountID: 900002, Direction: model.PostingDirectionDebit, Amount: decimal.RequireFromString("12.35")},
+		}, ledgerRepository.journalEntries[0].Postings)
+	})
+
+	t.Run("success_locks_in_id_order", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			unpostedAccountIDs: []int64{900005},
+			postedTotal:        decimal.NewFromInt(1),
+			postedCount:        1,
+		}
+		svc, accountRepository, _, _ := newService(model.Account{
+			ID:      900005,
+			Balance: decimal.NewFromInt(1000),
+		}, interestRepository)
+
+		posted, err := svc.PostDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, posted)
+		// the interest account has the lower id, it is locked before the credited account like in a transfer
+		assert.Equal(t, []int64{900002, 900005}, accountRepository.lockedIDs)
+	})
+
+	t.Run("success_already_posted", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{unpostedAccountIDs: []int64{1}}
+		svc, accountRepository, eventRepository, _ := newService(model.Account{
+			ID:      1,
+			Balance: decimal.NewFromInt(1000),
+		}, interestRepository)
+
+		posted, err := svc.PostDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, posted)
+		assert.Empty(t, accountRepository.upserted)
+		assert.Empty(t, eventRepository.placedEvents)
+	})
+
+	t.Run("success_skip_closed_account", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			unpostedAccountIDs: []int64{1},
+			postedTotal:        decimal.NewFromInt(1),
+			postedCount:        1,
+		}
+		svc, accountRepository, _, _ := newService(model.Account{
+			ID:     1,
+			Status: model.AccountStatusClosed,
+		}, interestRepository)
+
+		posted, err := svc.PostDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, posted)
+		assert.Empty(t, interestRepository.postingTransactionIDs)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("error_post_accruals", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			unpostedAccountIDs: []int64{1},
+			errPostAccrualsTx:  errors.New("internal db error"),
+		}
+		svc, accountRepository, _, _ := newService(model.Account{ID: 1}, interestRepository)
+
+		posted, err := svc.PostDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, posted)
+		assert.Empty(t, accountRepository.upserted)
+	})
+}
+
+func TestInterestService_InterestRate(t *testing.T) {
+	policy := InterestPolicy{
+		Rates: model.InterestRates{
+			model.AccountTypeSavings: decimal.RequireFromString("2.5"),
+		},
+	}
+	account := model.Account{ID: 1, Type: model.AccountTypeSavings}
+
+	t.Run("get_product_rate", func(t *testing.T) {
+		svc := NewInterestService(&interestRepositoryMock{errFindRateByAccountID: exception.ErrRecordNotFound},
+			nil, &accountRepositoryMock{errFindByID: []error{nil}, account: account}, nil, nil,
+			LedgerAccounts{}, policy, "1.0.0", 10)
+
+		resp, err := svc.GetInterestRate(context.Background(), dto.GetAccountRequest{ID: 1})
+
+		assert.NoError(t, err)
+		assert.True(t, decimal.RequireFromString("2.5").Equal(resp.Rate))
+		assert.Nil(t, resp.Override)
+	})
+
+	t.Run("get_override_rate", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{
+			rate: model.AccountInterestRate{AccountID: 1, Rate: decimal.NewFromInt(4)},
+		}
+		svc := NewInterestService(interestRepository, nil,
+			&accountRepositoryMock{errFindByID: []error{nil}, account: account}, nil, nil,
+			LedgerAccounts{}, policy, "1.0.0", 10)
+
+		resp, err := svc.GetInterestRate(context.Background(), dto.GetAccountRequest{ID: 1})
+
+		assert.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(4).Equal(resp.Rate))
+		assert.True(t, decimal.NewFromInt(4).Equal(*resp.Override))
+	})
+
+	t.Run("error_account_not_found", func(t *testing.T) {
+		svc := NewInterestService(&interestRepositoryMock{}, nil,
+			&accountRepositoryMock{errFindByID: []error{exception.ErrRecordNotFound}}, nil, nil,
+			LedgerAccounts{}, policy, "1.0.0", 10)
+
+		_, err := svc.GetInterestRate(context.Background(), dto.GetAccountRequest{ID: 1})
+
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+
+	t.Run("set_override_rate", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{}
+		svc := NewInterestService(interestRepository, nil,
+			&accountRepositoryMock{errFindByID: []error{nil}, account: account}, nil, nil,
+			LedgerAccounts{}, policy, "1.0.0", 10)
+
+		rate := decimal.NewFromInt(3)
+		err := svc.SetInterestRate(context.Background(), dto.SetInterestRateRequest{ID: 1, Rate: &rate})
+
+		assert.NoError(t, err)
+		assert.True(t, rate.Equal(interestRepository.upsertedRates[0].Rate))
+	})
+
+	t.Run("clear_override_rate", func(t *testing.T) {
+		interestRepository := &interestRepositoryMock{}
+		svc := NewInterestService(interestRepository, nil,
+			&accountRepositoryMock{errFindByID: []error{nil}, account: account}, nil, nil,
+			LedgerAccounts{}, policy, "1.0.0", 10)
+
+		err := svc.SetInterestRate(context.Background(), dto.SetInterestRateRequest{ID: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1}, interestRepository.deletedRateIDs)
+		assert.Empty(t, interestRepository.upsertedRates)
+	})
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+)
+
+type JournalRepository interface {
+	CreateJournalEntryTx(ctx context.Context, tx *sql.Tx, entry *model.JournalEntry) error
+}
+
+type LedgerRepository interface {
+	JournalRepository
+	FindTrialBalance(ctx context.Context) ([]model.TrialBalanceAccount, error)
+}
+
+// LedgerAccounts are the system accounts on the other side of the movements that do not involve two customer
+// accounts, e.g. the funding account pays the initial deposits.
+type LedgerAccounts struct {
+	FundingAccountID  int64
+	InterestAccountID int64
+	FeeAccountID      int64
+}
+
+// IDs returns the configured system account ids.
+func (a LedgerAccounts) IDs() []int64 {
+	var ids []int64
+
+	for _, id := range []int64{a.FundingAccountID, a.InterestAccountID, a.FeeAccountID} {
+		if id > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+type LedgerService struct {
+	ledgerRepository  LedgerRepository
+	accountRepository AccountRepository
+	eventRepository   EventRepository
+	accounts          LedgerAccounts
+	eventVersion      string
+}
+
+func NewLedgerService(ledgerRepository LedgerRepository, accountRepository AccountRepository,
+	eventRepository EventRepository, accounts LedgerAccounts, eventVersion string,
+) *LedgerService {
+	return &LedgerService{
+		ledgerRepository:  ledgerRepository,
+		accountRepository: accountRepository,
+		eventRepository:   eventRepository,
+		accounts:          accounts,
+		eventVersion:      eventVersion,
+	}
+}
+
+// GetTrialBalance godoc
+// @Summary      Get Trial Balance
+// @Description  Get the debit and credit totals of the postings of every account, the ledger is balanced when the
+// @Description  total debits equal the total credits
+// @Tags         Ledger
+// @ID           getTrialBalance
+// @Produce      json
+// @Success      200  {object}  dto.TrialBalanceResponse	"Trial balance"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /ledger/trial-balance [get].
+func (s *LedgerService) GetTrialBalance(ctx context.Context,
+	_ dto.TrialBalanceRequest,
+) (dto.TrialBalanceResponse, error) {
+	accounts, err := s.ledgerRepository.FindTrialBalance(ctx)
+	if err != nil {
+		return dto.TrialBalanceResponse{}, fmt.Errorf("failed to find trial balance: %w", err)
+	}
+
+	trialBalance := model.NewTrialBalance(accounts)
+
+	resp := dto.TrialBalanceResponse{
+		Accounts:     make([]dto.TrialBalanceAccount, 0, len(accounts)),
+		TotalDebits:  trialBalance.TotalDebits,
+		TotalCredits: trialBalance.TotalCredits,
+		Difference:   trialBalance.Difference(),
+		Balanced:     trialBalance.IsBalanced(),
+	}
+
+	for _, account := range trialBalance.Accounts {
+		resp.Accounts = append(resp.Accounts, dto.TrialBalanceAccount{
+			AccountID: account.AccountID,
+			Debits:    account.Debits,
+			Credits:   account.Credits,
+			Balance:   account.Balance(),
+		})
+	}
+
+	return resp, nil
+}
+
+// EnsureSystemAccounts creates the missing system accounts with a zero balance, it runs on start.
+func (s *LedgerService) EnsureSystemAccounts(ctx context.Context) error {
+	for _, accountID := range s.accounts.IDs() {
+		_, err := s.accountRepository.FindByID(ctx, accountID)
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, exception.ErrRecordNotFound) {
+			return fmt.Errorf("failed to find system account %d: %w", accountID, err)
+		}
+
+		if err := s.createSystemAccount(ctx, accountID); err != nil {
+			// another process may have created it in the meantime
+			if _, findErr := s.accountRepository.FindByID(ctx, accountID); findErr == nil {
+				continue
+			}
+
+			return err
+		}
+
+		slog.InfoContext(ctx, "system account created", slog.Int64("account_id", accountID))
+	}
+
+	return nil
+}
+
+func (s *LedgerService) createSystemAccount(ctx context.Context, accountID int64) error {
+	eventCollector, err := NewAccountEventCollector(ctx, s.eventRepository, accountID,
+		fmt.Sprintf("system-account-%d", accountID), s.eventVersion)
+	if err != nil {
+		return fmt.Errorf("failed to create account event collector: %w", err)
+	}
+
+	now := time.Now()
+
+	account := &model.Account{
+		ID:        accountID,
+		Type:      model.AccountTypeSystem,
+		Currency:  model.DefaultCurrency,
+		Balance:   decimal.Zero,
+		Status:    model.AccountStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err = s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		eventCollector.OnInitBalanceEvent(*account)
+
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		if err := s.accountRepository.UpsertTx(ctx, dbTx, account); err != nil {
+			return fmt.Errorf("failed to upsert account: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create system account %d: %w", accountID, err)
+	}
+
+	return nil
+}
+
+// recordJournalEntry posts the balance movements of the events collected for a transaction, it must be called
+// before the events are placed. A transaction without balance movements has no journal entry.
+func recordJournalEntry(ctx context.Context, dbTx *sql.Tx, journalRepository JournalRepository,
+	transactionID string, eventCollectors ...*AccountEventCollector,
+) error {
+	var events []model.Event
+
+	for _, eventCollector := range eventCollectors {
+		events = append(events, eventCollector.Events()...)
+	}
+
+	entry, err := model.NewJournalEntry(transactionID, events, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build journal entry: %w", err)
+	}
+
+	if len(entry.Postings) == 0 {
+		return nil
+	}
+
+	if err := journalRepository.CreateJournalEntryTx(ctx, dbTx, &entry); err != nil {
+		return fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	return nil
+}
+
+// findSystemAccountForUpdateTx locks a system account, a missing system account is a configuration error.
+func findSystemAccountForUpdateTx(ctx context.Context, dbTx *sql.Tx, accountRepository AccountRepository,
+	accountID int64,
+) (model.Account, error) {
+	account, err := accountRepository.FindByIDForUpdateTx(ctx, dbTx, accountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return model.Account{}, fmt.Errorf("system account %d not found", accountID)
+	}
+
+	if err != nil {
+		return model.Account{}, fmt.Errorf("failed to find system account: %w", err)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,177 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerService_GetTrialBalance(t *testing.T) {
+	t.Run("success_balanced", func(t *testing.T) {
+		svc := NewLedgerService(&ledgerRepositoryMock{
+			trialBalance: []model.TrialBalanceAccount{
+				{AccountID: 1, Debits: decimal.NewFromInt(200), Credits: decimal.NewFromInt(1000)},
+				{AccountID: 2, Debits: decimal.Zero, Credits: decimal.NewFromInt(200)},
+				{AccountID: 900001, Debits: decimal.NewFromInt(1000), Credits: decimal.Zero},
+			},
+		}, nil, nil, LedgerAccounts{}, "1.0.0")
+
+		resp, err := svc.GetTrialBalance(context.Background(), dto.TrialBalanceRequest{})
+
+		assert.NoError(t, err)
+		assert.True(t, resp.Balanced)
+		assert.True(t, resp.Difference.IsZero())
+		assert.True(t, decimal.NewFromInt(1200).Equal(resp.TotalDebits))
+		assert.True(t, decimal.NewFromInt(1200).Equal(resp.TotalCredits))
+		assert.Len(t, resp.Accounts, 3)
+		assert.True(t, decimal.NewFromInt(800).Equal(resp.Accounts[0].Balance))
+		assert.True(t, decimal.NewFromInt(-1000).Equal(resp.Accounts[2].Balance))
+	})
+
+	t.Run("success_unbalanced", func(t *testing.T) {
+		svc := NewLedgerService(&ledgerRepositoryMock{
+			trialBalance: []model.TrialBalanceAccount{
+				{AccountID: 1, Debits: decimal.NewFromInt(100), Credits: decimal.Zero},
+			},
+		}, nil, nil, LedgerAccounts{}, "1.0.0")
+
+		resp, err := svc.GetTrialBalance(context.Background(), dto.TrialBalanceRequest{})
+
+		assert.NoError(t, err)
+		assert.False(t, resp.Balanced)
+		assert.True(t, decimal.NewFromInt(100).Equal(resp.Difference))
+	})
+
+	t.Run("success_empty", func(t *testing.T) {
+		svc := NewLedgerService(&ledgerRepositoryMock{}, nil, nil, LedgerAccounts{}, "1.0.0")
+
+		resp, err := svc.GetTrialBalance(context.Background(), dto.TrialBalanceRequest{})
+
+		assert.NoError(t, err)
+		assert.True(t, resp.Balanced)
+		assert.NotNil(t, resp.Accounts)
+	})
+
+	t.Run("error_find_trial_balance", func(t *testing.T) {
+		svc := NewLedgerService(&ledgerRepositoryMock{errFindTrialBalance: errors.New("internal db error")},
+			nil, nil, LedgerAccounts{}, "1.0.0")
+
+		_, err := svc.GetTrialBalance(context.Background(), dto.TrialBalanceRequest{})
+
+		assert.ErrorContains(t, err, "internal db error")
+	})
+}
+
+func TestLedgerService_EnsureSystemAccounts(t *testing.T) {
+	accounts := LedgerAccounts{FundingAccountID: 900001, InterestAccountID: 900002}
+
+	t.Run("success_create_missing", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID: []error{nil, exception.ErrRecordNotFound},
+			errUpsertTx: []error{nil},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:          []error{nil},
+			events:                   []model.Event{{}},
+		}
+		svc := NewLedgerService(&ledgerRepositoryMock{}, accountRepository, eventRepository, accounts, "1.0.0")
+
+		err := svc.EnsureSystemAccounts(context.Background())
+
+		assert.NoError(t, err)
+		assert.Len(t, accountRepository.upserted, 1)
+		assert.Equal(t, int64(900002), accountRepository.upserted[0].ID)
+		assert.Equal(t, model.AccountTypeSystem, accountRepository.upserted[0].Type)
+		assert.True(t, accountRepository.upserted[0].Balance.IsZero())
+		assert.Len(t, eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypeInitBalance, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, "system-account-900002", eventRepository.placedEvents[0].TransactionID)
+	})
+
+	t.Run("success_created_concurrently", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID: []error{nil, exception.ErrRecordNotFound, nil},
+			errUpsertTx: []error{nil},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:          []error{errors.New("duplicate key")},
+			events:                   []model.Event{{}},
+		}
+		svc := NewLedgerService(&ledgerRepositoryMock{}, accountRepository, eventRepository, accounts, "1.0.0")
+
+		err := svc.EnsureSystemAccounts(context.Background())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("error_find_account", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID: []error{errors.New("internal db error")},
+		}
+		svc := NewLedgerService(&ledgerRepositoryMock{}, accountRepository, nil, accounts, "1.0.0")
+
+		err := svc.EnsureSystemAccounts(context.Background())
+
+		assert.ErrorContains(t, err, "internal db error")
+	})
+}
+
+func TestRecordJournalEntry(t *testing.T) {
+	newCollector := func(accountID int64) *AccountEventCollector {
+		collector, _ := NewAccountEventCollector(context.Background(), &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound},
+			events:                   []model.Event{{}},
+		}, accountID, "tx-1", "1.0.0")
+
+		return collector
+	}
+
+	t.Run("success", func(t *testing.T) {
+		source, destination := newCollector(1), newCollector(2)
+		source.OnSubBalanceEvent(2, decimal.NewFromInt(100))
+		destination.OnAddBalanceEvent(1, decimal.NewFromInt(100))
+
+		ledgerRepository := &ledgerRepositoryMock{}
+
+		err := recordJournalEntry(context.Background(), nil, ledgerRepository, "tx-1", source, destination)
+
+		assert.NoError(t, err)
+		assert.Len(t, ledgerRepository.journalEntries, 1)
+		assert.True(t, ledgerRepository.journalEntries[0].IsBalanced())
+	})
+
+	t.Run("success_no_movement", func(t *testing.T) {
+		collector := newCollector(1)
+		collector.OnFrozenEvent(model.AccountStatusReasonComplianceReview)
+
+		ledgerRepository := &ledgerRepositoryMock{}
+
+		err := recordJournalEntry(context.Background(), nil, ledgerRepository, "tx-1", collector)
+
+		assert.NoError(t, err)
+		assert.Empty(t, ledgerRepository.journalEntries)
+	})
+
+	t.Run("error_unbalanced", func(t *testing.T) {
+		source, destination := newCollector(1), newCollector(2)
+		source.OnSubBalanceEvent(2, decimal.NewFromInt(100))
+		destination.OnAddBalanceEvent(1, decimal.NewFromInt(90))
+
+		ledgerRepository := &ledgerRepositoryMock{}
+
+		err := recordJournalEntry(context.Background(), nil, ledgerRepository, "tx-1", source, destination)
+
+		assert.ErrorIs(t, err, model.ErrUnbalancedJournalEntry)
+		assert.Empty(t, ledgerRepository.journalEntries)
+	})
+}
@@ -5,8 +5,14 @@ package service
 import (
 	"context"
 	"database/sql"
+	"time"
 
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
 	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/risk"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/sanctions"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/webhook"
+	"github.com/shopspring/decimal"
 )
 
 type accountRepositoryMock struct {
@@ -17,27 +23,58 @@ type accountRepositoryMock struct {
 	findByIDForUpdateTxCallCount int
 	upsertTxCallCount            int
 	account                      model.Account
+	upserted                     []model.Account
+	errFindAll                   error
+	accounts                     []model.Account
+	filters                      []model.AccountFilter
+	lockedIDs                    []int64
+	sharedIDs                    []int64
+	// accountsByID overrides account for the locked accounts it holds.
+	accountsByID map[int64]model.Account
+}
+
+func (m *accountRepositoryMock) FindAll(ctx context.Context, filter model.AccountFilter) ([]model.Account, error) {
+	m.filters = append(m.filters, filter)
+	return m.accounts, m.errFindAll
 }
 
 func (m *accountRepositoryMock) UpsertTx(ctx context.Context, tx *sql.Tx, account *model.Account) error {
 	m.upsertTxCallCount++
+	m.upserted = append(m.upserted, *account)
 	return m.errUpsertTx[m.upsertTxCallCount-1]
 }
 
 func (m *accountRepositoryMock) FindByID(ctx context.Context, accountID int64) (model.Account, error) {
 	m.findByIDCallCount++
+	if account, ok := m.accountsByID[accountID]; ok {
+		return account, m.errFindByID[m.findByIDCallCount-1]
+	}
 	return m.account, m.errFindByID[m.findByIDCallCount-1]
 }
 
 func (m *accountRepositoryMock) FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, accountID int64) (model.Account, error) {
 	m.findByIDForUpdateTxCallCount++
+	m.lockedIDs = append(m.lockedIDs, accountID)
+	if account, ok := m.accountsByID[accountID]; ok {
+		return account, m.errFindByIDForUpdateTx[m.findByIDForUpdateTxCallCount-1]
+	}
 	return m.account, m.errFindByIDForUpdateTx[m.findByIDForUpdateTxCallCount-1]
 }
 
+// FindByIDForShareTx counts as a FindByIDForUpdateTx call, the error slice covers both locks in call order.
+func (m *accountRepositoryMock) FindByIDForShareTx(ctx context.Context, tx *sql.Tx, accountID int64) (model.Account, error) {
+	m.sharedIDs = append(m.sharedIDs, accountID)
+	return m.FindByIDForUpdateTx(ctx, tx, accountID)
+}
+
 func (m *accountRepositoryMock) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
 	return fn(ctx, nil)
 }
 
+func (m *accountRepositoryMock) WithRetryableTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return fn(ctx, nil)
+}
+
 type eventRepositoryMock struct {
 	errCreateTx                     []error
 	errCreateBulkTx                 []error
@@ -48,6 +85,7 @@ type eventRepositoryMock struct {
 	findAllByTransactionIDCallCount int
 	findLastByAggregateIDCallCount  int
 	events                          []model.Event
+	placedEvents                    []model.Event
 }
 
 func (m *eventRepositoryMock) CreateTx(ctx context.Context, tx *sql.Tx, event *model.Event) error {
@@ -57,6 +95,7 @@ func (m *eventRepositoryMock) CreateTx(ctx context.Context, tx *sql.Tx, event *m
 
 func (m *eventRepositoryMock) CreateBulkTx(ctx context.Context, tx *sql.Tx, events []model.Event) error {
 	m.createBulkTxCallCount++
+	m.placedEvents = append(m.placedEvents, events...)
 	return m.errCreateBulkTx[m.createBulkTxCallCount-1]
 }
 
@@ -65,7 +104,717 @@ func (m *eventRepositoryMock) FindAllByTransactionID(ctx context.Context, transa
 	return m.events, m.errFindAllByTransactionID[m.findAllByTransactionIDCallCount-1]
 }
 
-func (m *eventRepositoryMock) FindLastByAggregateID(ctx context.Context, aggregateID int64) (model.Event, error) {
+func (m *eventRepositoryMock) FindLastByAggregateID(ctx context.Context, aggregateType model.AggregateType, aggregateID int64) (model.Event, error) {
 	m.findLastByAggregateIDCallCount++
 	return m.events[0], m.errFindLastByAggregateID[m.findLastByAggregateIDCallCount-1]
 }
+
+type scheduledTransferRepositoryMock struct {
+	errCreate           []error
+	errFindByID         []error
+	errFindAllDue       []error
+	errClaim            []error
+	errComplete         []error
+	errCancel           []error
+	claimed             []bool
+	cancelled           []bool
+	createCallCount     int
+	findByIDCallCount   int
+	findAllDueCallCount int
+	claimCallCount      int
+	completeCallCount   int
+	cancelCallCount     int
+	scheduledTransfer   model.ScheduledTransfer
+	scheduledTransfers  []model.ScheduledTransfer
+	completed           []model.ScheduledTransfer
+	// awaitingApproval is returned by FindAllAwaitingApproval, settled collects the instructions passed to
+	// SettleApproval.
+	awaitingApproval           []model.ScheduledTransfer
+	errFindAllAwaitingApproval error
+	errSettleApproval          error
+	settled                    []model.ScheduledTransfer
+}
+
+func (m *scheduledTransferRepositoryMock) Create(ctx context.Context, scheduledTransfer *model.ScheduledTransfer) error {
+	m.createCallCount++
+	scheduledTransfer.ID = 1
+	return m.errCreate[m.createCallCount-1]
+}
+
+func (m *scheduledTransferRepositoryMock) FindByID(ctx context.Context, id int64) (model.ScheduledTransfer, error) {
+	m.findByIDCallCount++
+	return m.scheduledTransfer, m.errFindByID[m.findByIDCallCount-1]
+}
+
+func (m *scheduledTransferRepositoryMock) FindAllDue(ctx context.Context, now time.Time, staleBefore time.Time, limit int) ([]model.ScheduledTransfer, error) {
+	m.findAllDueCallCount++
+	return m.scheduledTransfers, m.errFindAllDue[m.findAllDueCallCount-1]
+}
+
+func (m *scheduledTransferRepositoryMock) Claim(ctx context.Context, id int64, now time.Time, staleBefore time.Time) (bool, error) {
+	m.claimCallCount++
+	return m.claimed[m.claimCallCount-1], m.errClaim[m.claimCallCount-1]
+}
+
+func (m *scheduledTransferRepositoryMock) Complete(ctx context.Context, scheduledTransfer *model.ScheduledTransfer) error {
+	m.completeCallCount++
+	m.completed = append(m.completed, *scheduledTransfer)
+	return m.errComplete[m.completeCallCount-1]
+}
+
+func (m *scheduledTransferRepositoryMock) FindAllAwaitingApproval(ctx context.Context, limit int) ([]model.ScheduledTransfer, error) {
+	return m.awaitingApproval, m.errFindAllAwaitingApproval
+}
+
+func (m *scheduledTransferRepositoryMock) SettleApproval(ctx context.Context, scheduledTransfer *model.ScheduledTransfer) error {
+	m.settled = append(m.settled, *scheduledTransfer)
+	return m.errSettleApproval
+}
+
+func (m *scheduledTransferRepositoryMock) Cancel(ctx context.Context, id int64, now time.Time) (bool, error) {
+	m.cancelCallCount++
+	return m.cancelled[m.cancelCallCount-1], m.errCancel[m.cancelCallCount-1]
+}
+
+type transfererMock struct {
+	errTransfer       []error
+	transferCallCount int
+	transactionIDs    []string
+	initiators        []string
+	// responses holds the response of each call, an empty response is returned for the calls it does not cover.
+	responses []dto.TransferResponse
+}
+
+func (m *transfererMock) Transfer(ctx context.Context, req dto.CreateTransferRequest) (dto.TransferResponse, error) {
+	m.transferCallCount++
+	reqContext, _ := dto.RequestFromContext(ctx)
+	m.transactionIDs = append(m.transactionIDs, reqContext.TransactionID)
+	m.initiators = append(m.initiators, req.InitiatedBy)
+	if m.transferCallCount <= len(m.responses) {
+		return m.responses[m.transferCallCount-1], m.errTransfer[m.transferCallCount-1]
+	}
+	return dto.TransferResponse{}, m.errTransfer[m.transferCallCount-1]
+}
+
+type standingOrderRepositoryMock struct {
+	errCreateTx                     []error
+	errUpdateTx                     []error
+	errFindByID                     []error
+	errFindByIDForUpdateTx          []error
+	errFindDueByIDForUpdateTx       []error
+	errFindAllDue                   []error
+	createTxCallCount               int
+	updateTxCallCount               int
+	findByIDCallCount               int
+	findByIDForUpdateTxCallCount    int
+	findDueByIDForUpdateTxCallCount int
+	findAllDueCallCount             int
+	standingOrder                   model.StandingOrder
+	standingOrders                  []model.StandingOrder
+	updated                         []model.StandingOrder
+	awaitingApproval                []model.StandingOrder
+	errFindAllAwaitingApproval      error
+}
+
+func (m *standingOrderRepositoryMock) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return fn(ctx, nil)
+}
+
+func (m *standingOrderRepositoryMock) CreateTx(ctx context.Context, tx *sql.Tx, standingOrder *model.StandingOrder) error {
+	m.createTxCallCount++
+	standingOrder.ID = 1
+	return m.errCreateTx[m.createTxCallCount-1]
+}
+
+func (m *standingOrderRepositoryMock) UpdateTx(ctx context.Context, tx *sql.Tx, standingOrder *model.StandingOrder) error {
+	m.updateTxCallCount++
+	m.updated = append(m.updated, *standingOrder)
+	return m.errUpdateTx[m.updateTxCallCount-1]
+}
+
+func (m *standingOrderRepositoryMock) FindByID(ctx context.Context, id int64) (model.StandingOrder, error) {
+	m.findByIDCallCount++
+	return m.standingOrder, m.errFindByID[m.findByIDCallCount-1]
+}
+
+func (m *standingOrderRepositoryMock) FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (model.StandingOrder, error) {
+	m.findByIDForUpdateTxCallCount++
+	return m.standingOrder, m.errFindByIDForUpdateTx[m.findByIDForUpdateTxCallCount-1]
+}
+
+func (m *standingOrderRepositoryMock) FindDueByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64, now time.Time) (model.StandingOrder, error) {
+	m.findDueByIDForUpdateTxCallCount++
+	return m.standingOrder, m.errFindDueByIDForUpdateTx[m.findDueByIDForUpdateTxCallCount-1]
+}
+
+func (m *standingOrderRepositoryMock) FindAllDue(ctx context.Context, now time.Time, limit int) ([]model.StandingOrder, error) {
+	m.findAllDueCallCount++
+	return m.standingOrders, m.errFindAllDue[m.findAllDueCallCount-1]
+}
+
+func (m *standingOrderRepositoryMock) FindAllAwaitingApproval(ctx context.Context, limit int) ([]model.StandingOrder, error) {
+	return m.awaitingApproval, m.errFindAllAwaitingApproval
+}
+
+type transferLimiterMock struct {
+	errCheckTx       error
+	checkTxCallCount int
+}
+
+func (m *transferLimiterMock) CheckTx(ctx context.Context, tx *sql.Tx, accountID int64, amount decimal.Decimal, transactionID string) error {
+	m.checkTxCallCount++
+	return m.errCheckTx
+}
+
+type transferLimitRepositoryMock struct {
+	errFindByAccountID []error
+	errUpsert          []error
+	findCallCount      int
+	upsertCallCount    int
+	limit              model.AccountTransferLimit
+	upserted           []model.AccountTransferLimit
+}
+
+func (m *transferLimitRepositoryMock) FindByAccountID(ctx context.Context, accountID int64) (model.AccountTransferLimit, error) {
+	m.findCallCount++
+	return m.limit, m.errFindByAccountID[m.findCallCount-1]
+}
+
+func (m *transferLimitRepositoryMock) Upsert(ctx context.Context, limit *model.AccountTransferLimit) error {
+	m.upsertCallCount++
+	m.upserted = append(m.upserted, *limit)
+	return m.errUpsert[m.upsertCallCount-1]
+}
+
+type debitUsageRepositoryMock struct {
+	errFindDebitUsageTx error
+	findCallCount       int
+	usage               model.DebitUsage
+	excludedTxID        string
+}
+
+func (m *debitUsageRepositoryMock) FindDebitUsageTx(ctx context.Context, tx *sql.Tx, accountID int64, excludeTransactionID string, window model.DebitUsageWindow) (model.DebitUsage, error) {
+	m.findCallCount++
+	m.excludedTxID = excludeTransactionID
+	return m.usage, m.errFindDebitUsageTx
+}
+
+type interestRepositoryMock struct {
+	errFindRateByAccountID error
+	errFindAllAccruable    error
+	errPostAccrualsTx      error
+	rate                   model.AccountInterestRate
+	accounts               []model.InterestAccount
+	accrued                map[string]bool
+	accruals               []model.InterestAccrual
+	unpostedAccountIDs     []int64
+	postedTotal            decimal.Decimal
+	postedCount            int
+	postingTransactionIDs  []string
+	upsertedRates          []model.AccountInterestRate
+	deletedRateIDs         []int64
+}
+
+func (m *interestRepositoryMock) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return fn(ctx, nil)
+}
+
+func (m *interestRepositoryMock) UpsertRate(ctx context.Context, rate *model.AccountInterestRate) error {
+	m.upsertedRates = append(m.upsertedRates, *rate)
+	return nil
+}
+
+func (m *interestRepositoryMock) DeleteRate(ctx context.Context, accountID int64) error {
+	m.deletedRateIDs = append(m.deletedRateIDs, accountID)
+	return nil
+}
+
+func (m *interestRepositoryMock) FindRateByAccountID(ctx context.Context, accountID int64) (model.AccountInterestRate, error) {
+	return m.rate, m.errFindRateByAccountID
+}
+
+func (m *interestRepositoryMock) FindAllAccruable(ctx context.Context, afterID int64, limit int) ([]model.InterestAccount, error) {
+	var accounts []model.InterestAccount
+
+	for _, account := range m.accounts {
+		if account.AccountID > afterID && len(accounts) < limit {
+			accounts = append(accounts, account)
+		}
+	}
+
+	return accounts, m.errFindAllAccruable
+}
+
+func (m *interestRepositoryMock) CreateAccrualTx(ctx context.Context, tx *sql.Tx, accrual *model.InterestAccrual) (bool, error) {
+	if m.accrued[accrual.TransactionID] {
+		return false, nil
+	}
+
+	m.accruals = append(m.accruals, *accrual)
+	return true, nil
+}
+
+func (m *interestRepositoryMock) FindAllUnpostedAccountIDs(ctx context.Context, periodEnd time.Time, limit int) ([]int64, error) {
+	return m.unpostedAccountIDs, nil
+}
+
+func (m *interestRepositoryMock) PostAccrualsTx(ctx context.Context, tx *sql.Tx, accountID int64, periodEnd time.Time, postingTransactionID string, postedAt time.Time) (decimal.Decimal, int, error) {
+	m.postingTransactionIDs = append(m.postingTransactionIDs, postingTransactionID)
+	return m.postedTotal, m.postedCount, m.errPostAccrualsTx
+}
+
+type balanceRepositoryMock struct {
+	errFindBalanceAt error
+	balance          decimal.Decimal
+	findAts          []time.Time
+}
+
+func (m *balanceRepositoryMock) FindBalanceAt(ctx context.Context, accountID int64, at time.Time) (decimal.Decimal, error) {
+	m.findAts = append(m.findAts, at)
+	return m.balance, m.errFindBalanceAt
+}
+
+type statementRepositoryMock struct {
+	balanceRepositoryMock
+	errFindAllMovements error
+	movements           []model.Movement
+}
+
+func (m *statementRepositoryMock) FindAllMovements(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Movement, error) {
+	return m.movements, m.errFindAllMovements
+}
+
+type ledgerRepositoryMock struct {
+	errCreateJournalEntryTx error
+	errFindTrialBalance     error
+	journalEntries          []model.JournalEntry
+	trialBalance            []model.TrialBalanceAccount
+}
+
+func (m *ledgerRepositoryMock) CreateJournalEntryTx(ctx context.Context, tx *sql.Tx, entry *model.JournalEntry) error {
+	m.journalEntries = append(m.journalEntries, *entry)
+	return m.errCreateJournalEntryTx
+}
+
+func (m *ledgerRepositoryMock) FindTrialBalance(ctx context.Context) ([]model.TrialBalanceAccount, error) {
+	return m.trialBalance, m.errFindTrialBalance
+}
+
+type transferApprovalRepositoryMock struct {
+	errCreateTx                 error
+	errUpdateTx                 error
+	errFindByTransactionID      error
+	errFindForUpdateTx          error
+	errFindAllExpired           error
+	transferApproval            model.TransferApproval
+	transferApprovalForUpdateTx *model.TransferApproval
+	transferApprovals           []model.TransferApproval
+	created                     []model.TransferApproval
+	updated                     []model.TransferApproval
+}
+
+func (m *transferApprovalRepositoryMock) CreateTx(ctx context.Context, tx *sql.Tx, transferApproval *model.TransferApproval) error {
+	transferApproval.ID = 1
+	m.created = append(m.created, *transferApproval)
+	return m.errCreateTx
+}
+
+func (m *transferApprovalRepositoryMock) UpdateTx(ctx context.Context, tx *sql.Tx, transferApproval *model.TransferApproval) error {
+	m.updated = append(m.updated, *transferApproval)
+	return m.errUpdateTx
+}
+
+func (m *transferApprovalRepositoryMock) FindByTransactionID(ctx context.Context, transactionID string) (model.TransferApproval, error) {
+	return m.transferApproval, m.errFindByTransactionID
+}
+
+// FindByTransactionIDForUpdateTx returns transferApprovalForUpdateTx when set, to simulate a concurrent review.
+func (m *transferApprovalRepositoryMock) FindByTransactionIDForUpdateTx(ctx context.Context, tx *sql.Tx, transactionID string) (model.TransferApproval, error) {
+	if m.transferApprovalForUpdateTx != nil {
+		return *m.transferApprovalForUpdateTx, m.errFindForUpdateTx
+	}
+
+	return m.transferApproval, m.errFindForUpdateTx
+}
+
+func (m *transferApprovalRepositoryMock) FindAllExpired(ctx context.Context, now time.Time, limit int) ([]model.TransferApproval, error) {
+	return m.transferApprovals, m.errFindAllExpired
+}
+
+type riskDecisionRepositoryMock struct {
+	errUpsertTx error
+	upserted    []model.RiskDecision
+}
+
+func (m *riskDecisionRepositoryMock) UpsertTx(ctx context.Context, tx *sql.Tx, riskDecision *model.RiskDecision) error {
+	riskDecision.ID = int64(len(m.upserted) + 1)
+	m.upserted = append(m.upserted, *riskDecision)
+	return m.errUpsertTx
+}
+
+type riskEvaluatorMock struct {
+	decision  risk.Decision
+	transfers []risk.Transfer
+}
+
+func (m *riskEvaluatorMock) Evaluate(transfer risk.Transfer) risk.Decision {
+	m.transfers = append(m.transfers, transfer)
+	return m.decision
+}
+
+type sanctionsRepositoryMock struct {
+	errUpsertScreeningTx error
+	upserted             []model.SanctionsScreening
+}
+
+func (m *sanctionsRepositoryMock) UpsertScreeningTx(ctx context.Context, tx *sql.Tx, screening *model.SanctionsScreening) error {
+	screening.ID = int64(len(m.upserted) + 1)
+	m.upserted = append(m.upserted, *screening)
+	return m.errUpsertScreeningTx
+}
+
+type sanctionsScreenerMock struct {
+	list *sanctions.List
+}
+
+func (m *sanctionsScreenerMock) List() *sanctions.List {
+	return m.list
+}
+
+type idempotencyKeyRepositoryMock struct {
+	errClaimTx          error
+	errDeleteAllExpired error
+	deleted             []int
+	claimed             []string
+	deleteCalls         int
+}
+
+func (m *idempotencyKeyRepositoryMock) ClaimTx(ctx context.Context, tx *sql.Tx, transactionID string, now time.Time) error {
+	if m.errClaimTx == nil {
+		m.claimed = append(m.claimed, transactionID)
+	}
+	return m.errClaimTx
+}
+
+func (m *idempotencyKeyRepositoryMock) DeleteAllExpired(ctx context.Context, before time.Time, limit int) (int, error) {
+	if m.errDeleteAllExpired != nil {
+		return 0, m.errDeleteAllExpired
+	}
+	if m.deleteCalls >= len(m.deleted) {
+		return 0, nil
+	}
+	m.deleteCalls++
+	return m.deleted[m.deleteCalls-1], nil
+}
+
+type accountShardRepositoryMock struct {
+	errCreditTx     error
+	errFindAllTx    error
+	errResetAllTx   error
+	shards          []model.AccountShard
+	credited        []model.AccountShard
+	resetAccountIDs []int64
+}
+
+func (m *accountShardRepositoryMock) CreditTx(ctx context.Context, tx *sql.Tx, accountID int64, shardNo int, amount decimal.Decimal, now time.Time) (model.AccountShard, error) {
+	if m.errCreditTx != nil {
+		return model.AccountShard{}, m.errCreditTx
+	}
+	shard := model.AccountShard{ID: accountID*100 + int64(shardNo), AccountID: accountID, ShardNo: shardNo, Balance: amount}
+	m.credited = append(m.credited, shard)
+	return shard, nil
+}
+
+func (m *accountShardRepositoryMock) FindAllByAccountIDForUpdateTx(ctx context.Context, tx *sql.Tx, accountID int64) ([]model.AccountShard, error) {
+	return m.shards, m.errFindAllTx
+}
+
+func (m *accountShardRepositoryMock) ResetAllByAccountIDTx(ctx context.Context, tx *sql.Tx, accountID int64, now time.Time) error {
+	m.resetAccountIDs = append(m.resetAccountIDs, accountID)
+	return m.errResetAllTx
+}
+
+type reconciliationRepositoryMock struct {
+	errCreateTx     error
+	errFindByID     error
+	reconciliation  model.Reconciliation
+	reconciliations []model.Reconciliation
+}
+
+func (m *reconciliationRepositoryMock) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return fn(ctx, nil)
+}
+
+func (m *reconciliationRepositoryMock) CreateTx(ctx context.Context, tx *sql.Tx, reconciliation *model.Reconciliation) error {
+	if m.errCreateTx != nil {
+		return m.errCreateTx
+	}
+	reconciliation.ID = int64(len(m.reconciliations) + 1)
+	m.reconciliations = append(m.reconciliations, *reconciliation)
+	return nil
+}
+
+func (m *reconciliationRepositoryMock) FindByID(ctx context.Context, id int64) (model.Reconciliation, error) {
+	return m.reconciliation, m.errFindByID
+}
+
+type bankMovementRepositoryMock struct {
+	errFindAll error
+	movements  []model.BankMovement
+	from       time.Time
+	to         time.Time
+}
+
+func (m *bankMovementRepositoryMock) FindAllBankMovements(ctx context.Context, fundingAccountID int64, from time.Time, to time.Time) ([]model.BankMovement, error) {
+	m.from, m.to = from, to
+	return m.movements, m.errFindAll
+}
+
+type paymentBatchRepositoryMock struct {
+	errCreateTx                            []error
+	errFindByID                            []error
+	errFindByMessageID                     []error
+	errFindProcessingByIDForUpdateTx       []error
+	errFindNextDueInstructionTx            []error
+	errFindAllDueBatchIDs                  []error
+	createTxCallCount                      int
+	findByIDCallCount                      int
+	findByMessageIDCallCount               int
+	findProcessingByIDForUpdateTxCallCount int
+	findNextDueInstructionTxCallCount      int
+	countPendingInstructionsTxCallCount    int
+	findAllDueBatchIDsCallCount            int
+	batch                                  model.PaymentBatch
+	batchIDs                               []int64
+	// instructions holds the instruction returned by each FindNextDueInstructionTx call.
+	instructions        []model.PaymentInstruction
+	pending             []int
+	created             []model.PaymentBatch
+	updated             []model.PaymentBatch
+	updatedInstructions []model.PaymentInstruction
+}
+
+func (m *paymentBatchRepositoryMock) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return fn(ctx, nil)
+}
+
+func (m *paymentBatchRepositoryMock) CreateTx(ctx context.Context, tx *sql.Tx, batch *model.PaymentBatch) error {
+	m.createTxCallCount++
+	batch.ID = 1
+	for i := range batch.Instructions {
+		batch.Instructions[i].ID = int64(i + 1)
+		batch.Instructions[i].BatchID = batch.ID
+	}
+	m.created = append(m.created, *batch)
+	return m.errCreateTx[m.createTxCallCount-1]
+}
+
+func (m *paymentBatchRepositoryMock) UpdateTx(ctx context.Context, tx *sql.Tx, batch *model.PaymentBatch) error {
+	m.updated = append(m.updated, *batch)
+	return nil
+}
+
+func (m *paymentBatchRepositoryMock) UpdateInstructionTx(ctx context.Context, tx *sql.Tx, instruction *model.PaymentInstruction) error {
+	m.updatedInstructions = append(m.updatedInstructions, *instruction)
+	return nil
+}
+
+func (m *paymentBatchRepositoryMock) FindByID(ctx context.Context, id int64) (model.PaymentBatch, error) {
+	m.findByIDCallCount++
+	return m.batch, m.errFindByID[m.findByIDCallCount-1]
+}
+
+func (m *paymentBatchRepositoryMock) FindByMessageID(ctx context.Context, messageID string) (model.PaymentBatch, error) {
+	m.findByMessageIDCallCount++
+	return m.batch, m.errFindByMessageID[m.findByMessageIDCallCount-1]
+}
+
+func (m *paymentBatchRepositoryMock) FindProcessingByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (model.PaymentBatch, error) {
+	m.findProcessingByIDForUpdateTxCallCount++
+	return m.batch, m.errFindProcessingByIDForUpdateTx[m.findProcessingByIDForUpdateTxCallCount-1]
+}
+
+func (m *paymentBatchRepositoryMock) FindNextDueInstructionTx(ctx context.Context, tx *sql.Tx, batchID int64, now time.Time) (model.PaymentInstruction, error) {
+	m.findNextDueInstructionTxCallCount++
+	if m.findNextDueInstructionTxCallCount <= len(m.instructions) {
+		return m.instructions[m.findNextDueInstructionTxCallCount-1], m.errFindNextDueInstructionTx[m.findNextDueInstructionTxCallCount-1]
+	}
+	return model.PaymentInstruction{}, m.errFindNextDueInstructionTx[m.findNextDueInstructionTxCallCount-1]
+}
+
+func (m *paymentBatchRepositoryMock) CountPendingInstructionsTx(ctx context.Context, tx *sql.Tx, batchID int64) (int, error) {
+	m.countPendingInstructionsTxCallCount++
+	return m.pending[m.countPendingInstructionsTxCallCount-1], nil
+}
+
+func (m *paymentBatchRepositoryMock) FindAllDueBatchIDs(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	m.findAllDueBatchIDsCallCount++
+	return m.batchIDs, m.errFindAllDueBatchIDs[m.findAllDueBatchIDsCallCount-1]
+}
+
+type webhookRepositoryMock struct {
+	errFindByID                       []error
+	errFindDueDeliveryByIDForUpdateTx []error
+	findByIDCallCount                 int
+	findDueDeliveryCallCount          int
+	webhook                           model.Webhook
+	webhooks                          []model.Webhook
+	// outbox holds the events returned by each FindOutboxEventsForUpdateTx call.
+	outbox            [][]model.Event
+	outboxCallCount   int
+	deliveryIDs       []int64
+	delivery          model.WebhookDelivery
+	deliveries        []model.WebhookDelivery
+	requeued          int
+	created           []model.Webhook
+	updated           []model.Webhook
+	createdDeliveries []model.WebhookDelivery
+	updatedDeliveries []model.WebhookDelivery
+	attempts          []model.WebhookDeliveryAttempt
+	deletedOutbox     []int64
+	filters           []model.WebhookDeliveryFilter
+	requeuedIDs       []int64
+}
+
+func (m *webhookRepositoryMock) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return fn(ctx, nil)
+}
+
+func (m *webhookRepositoryMock) Create(ctx context.Context, webhook *model.Webhook) error {
+	webhook.ID = 1
+	m.created = append(m.created, *webhook)
+	return nil
+}
+
+func (m *webhookRepositoryMock) Update(ctx context.Context, webhook *model.Webhook) error {
+	m.updated = append(m.updated, *webhook)
+	return nil
+}
+
+func (m *webhookRepositoryMock) FindByID(ctx context.Context, id int64) (model.Webhook, error) {
+	m.findByIDCallCount++
+	return m.webhook, m.errFindByID[m.findByIDCallCount-1]
+}
+
+func (m *webhookRepositoryMock) FindAll(ctx context.Context, status model.WebhookStatus) ([]model.Webhook, error) {
+	return m.webhooks, nil
+}
+
+func (m *webhookRepositoryMock) FindOutboxEventsForUpdateTx(ctx context.Context, tx *sql.Tx, limit int) ([]model.Event, error) {
+	m.outboxCallCount++
+	if m.outboxCallCount > len(m.outbox) {
+		return nil, nil
+	}
+	return m.outbox[m.outboxCallCount-1], nil
+}
+
+func (m *webhookRepositoryMock) DeleteOutboxTx(ctx context.Context, tx *sql.Tx, eventIDs []int64) error {
+	m.deletedOutbox = append(m.deletedOutbox, eventIDs...)
+	return nil
+}
+
+func (m *webhookRepositoryMock) CreateDeliveryTx(ctx context.Context, tx *sql.Tx, delivery *model.WebhookDelivery) error {
+	m.createdDeliveries = append(m.createdDeliveries, *delivery)
+	return nil
+}
+
+func (m *webhookRepositoryMock) FindAllDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	return m.deliveryIDs, nil
+}
+
+func (m *webhookRepositoryMock) FindDueDeliveryByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64, now time.Time) (model.WebhookDelivery, error) {
+	m.findDueDeliveryCallCount++
+	return m.delivery, m.errFindDueDeliveryByIDForUpdateTx[m.findDueDeliveryCallCount-1]
+}
+
+func (m *webhookRepositoryMock) UpdateDeliveryTx(ctx context.Context, tx *sql.Tx, delivery *model.WebhookDelivery) error {
+	m.updatedDeliveries = append(m.updatedDeliveries, *delivery)
+	return nil
+}
+
+func (m *webhookRepositoryMock) CreateAttemptTx(ctx context.Context, tx *sql.Tx, attempt *model.WebhookDeliveryAttempt) error {
+	m.attempts = append(m.attempts, *attempt)
+	return nil
+}
+
+func (m *webhookRepositoryMock) FindAllDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	m.filters = append(m.filters, filter)
+	return m.deliveries, nil
+}
+
+func (m *webhookRepositoryMock) Requeue(ctx context.Context, webhookID int64, deliveryIDs []int64, now time.Time) (int, error) {
+	m.requeuedIDs = deliveryIDs
+	return m.requeued, nil
+}
+
+type webhookSenderMock struct {
+	result   webhook.Result
+	errSend  error
+	requests []webhook.Request
+}
+
+func (m *webhookSenderMock) Send(ctx context.Context, req webhook.Request) (webhook.Result, error) {
+	m.requests = append(m.requests, req)
+	return m.result, m.errSend
+}
+
+type monitoringAlertRepositoryMock struct {
+	alert         model.MonitoringAlert
+	alerts        []model.MonitoringAlert
+	errCreate     []error
+	createCount   int
+	errFindByID   error
+	created       []model.MonitoringAlert
+	updated       []model.MonitoringAlert
+	filters       []model.MonitoringAlertFilter
+	sinceArgument time.Time
+}
+
+func (m *monitoringAlertRepositoryMock) WithTransaction(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return fn(ctx, nil)
+}
+
+func (m *monitoringAlertRepositoryMock) Create(ctx context.Context, alert *model.MonitoringAlert) error {
+	m.createCount++
+	if m.createCount <= len(m.errCreate) && m.errCreate[m.createCount-1] != nil {
+		return m.errCreate[m.createCount-1]
+	}
+	alert.ID = int64(m.createCount)
+	m.created = append(m.created, *alert)
+	return nil
+}
+
+func (m *monitoringAlertRepositoryMock) UpdateTx(ctx context.Context, tx *sql.Tx, alert *model.MonitoringAlert) error {
+	m.updated = append(m.updated, *alert)
+	return nil
+}
+
+func (m *monitoringAlertRepositoryMock) FindByID(ctx context.Context, id int64) (model.MonitoringAlert, error) {
+	return m.alert, m.errFindByID
+}
+
+func (m *monitoringAlertRepositoryMock) FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (model.MonitoringAlert, error) {
+	return m.alert, m.errFindByID
+}
+
+func (m *monitoringAlertRepositoryMock) FindAll(ctx context.Context, filter model.MonitoringAlertFilter) ([]model.MonitoringAlert, error) {
+	m.filters = append(m.filters, filter)
+	return m.alerts, nil
+}
+
+func (m *monitoringAlertRepositoryMock) FindAllSince(ctx context.Context, since time.Time) ([]model.MonitoringAlert, error) {
+	m.sinceArgument = since
+	return m.alerts, nil
+}
+
+type accountMovementRepositoryMock struct {
+	movements []model.AccountMovement
+	from      time.Time
+	to        time.Time
+}
+
+func (m *accountMovementRepositoryMock) FindAllAccountMovements(ctx context.Context, from time.Time, to time.Time) ([]model.AccountMovement, error) {
+	m.from = from
+	m.to = to
+	return m.movements, nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/monitoring"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
+)
+
+// monitoringAlertSort is the only sort of the alerts, the cursor is the id of the last alert of a page.
+const monitoringAlertSort = "-id"
+
+type MonitoringAlertRepository interface {
+	WithTransaction(ctx context.Context, txFunc func(context.Context, *sql.Tx) error) error
+	Create(ctx context.Context, alert *model.MonitoringAlert) error
+	UpdateTx(ctx context.Context, tx *sql.Tx, alert *model.MonitoringAlert) error
+	FindByID(ctx context.Context, id int64) (model.MonitoringAlert, error)
+	FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (model.MonitoringAlert, error)
+	FindAll(ctx context.Context, filter model.MonitoringAlertFilter) ([]model.MonitoringAlert, error)
+	FindAllSince(ctx context.Context, since time.Time) ([]model.MonitoringAlert, error)
+}
+
+type AccountMovementRepository interface {
+	FindAllAccountMovements(ctx context.Context, from time.Time, to time.Time) ([]model.AccountMovement, error)
+}
+
+type MonitoringService struct {
+	alertRepository    MonitoringAlertRepository
+	movementRepository AccountMovementRepository
+	detectors          []monitoring.Detector
+}
+
+func NewMonitoringService(alertRepository MonitoringAlertRepository, movementRepository AccountMovementRepository,
+	detectors []monitoring.Detector,
+) *MonitoringService {
+	return &MonitoringService{
+		alertRepository:    alertRepository,
+		movementRepository: movementRepository,
+		detectors:          detectors,
+	}
+}
+
+// Scan runs the detectors over the account movements of the longest detector window up to now and raises an alert
+// for each new pattern found. A pattern sharing movements with an alert of the same detector and account was
+// reported by an earlier scan and is skipped.
+// It returns the number of alerts raised.
+func (s *MonitoringService) Scan(ctx context.Context) (int, error) {
+	if len(s.detectors) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	from := now.Add(-monitoring.Lookback(s.detectors))
+
+	accountMovements, err := s.movementRepository.FindAllAccountMovements(ctx, from, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find account movements: %w", err)
+	}
+
+	alerts, err := s.alertRepository.FindAllSince(ctx, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find monitoring alerts: %w", err)
+	}
+
+	movements := make([]monitoring.Movement, 0, len(accountMovements))
+	for _, accountMovement := range accountMovements {
+		movements = append(movements, newMonitoringMovement(accountMovement))
+	}
+
+	raised := 0
+
+	for _, finding := range monitoring.Scan(s.detectors, movements) {
+		reported := slices.ContainsFunc(alerts, func(alert model.MonitoringAlert) bool {
+			return alert.Covers(finding.Detector, finding.AccountID, finding.EventIDs)
+		})
+		if reported {
+			continue
+		}
+
+		alert := newMonitoringAlert(finding, now)
+
+		err := s.alertRepository.Create(ctx, &alert)
+		if err != nil && errors.Is(err, exception.ErrRecordNotUnique) {
+			// raised by a scan running at the same time
+			continue
+		}
+
+		if err != nil {
+			return raised, fmt.Errorf("failed to create monitoring alert: %w", err)
+		}
+
+		alerts = append(alerts, alert)
+		raised++
+	}
+
+	return raised, nil
+}
+
+// ListAlerts godoc
+// @Summary      List Monitoring Alerts
+// @Description  List the alerts raised by the transaction monitoring, newest first, one page at a time
+// @Tags         Monitoring
+// @ID           listMonitoringAlerts
+// @Produce      json
+// @Param        status	query		string	false	"Status"	Enums(open, dismissed, escalated)
+// @Param        detector	query		string	false	"Detector"	Enums(structuring, rapid_movement, fan_in, fan_out)
+// @Param        account_id	query		int	false	"Account ID"
+// @Param        limit	query		int	false	"Page size, up to 100"
+// @Param        cursor	query		string	false	"Cursor of the next page"
+// @Success      200  {object}  dto.ListResponse[dto.MonitoringAlertResponse]	"Alerts"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /monitoring/alerts [get].
+func (s *MonitoringService) ListAlerts(ctx context.Context,
+	req dto.ListMonitoringAlertsRequest,
+) (dto.ListResponse[dto.MonitoringAlertResponse], error) {
+	filter := model.MonitoringAlertFilter{
+		Status:    model.MonitoringAlertStatus(req.Status),
+		Detector:  req.Detector,
+		AccountID: req.AccountID,
+		// one more alert tells whether there is a next page
+		Limit: req.Limit + 1,
+	}
+
+	if req.Cursor != "" {
+		cursor, err := pagination.Decode(req.Cursor, monitoringAlertSort)
+		if err != nil {
+			return dto.ListResponse[dto.MonitoringAlertResponse]{}, ErrInvalidCursor
+		}
+
+		filter.BeforeID = cursor.ID
+	}
+
+	alerts, err := s.alertRepository.FindAll(ctx, filter)
+	if err != nil {
+		return dto.ListResponse[dto.MonitoringAlertResponse]{}, fmt.Errorf("failed to find monitoring alerts: %w", err)
+	}
+
+	alerts, nextCursor := pagination.Page(alerts, req.Limit, func(alert model.MonitoringAlert) pagination.Cursor {
+		return pagination.Cursor{Sort: monitoringAlertSort, ID: alert.ID}
+	})
+
+	resp := dto.ListResponse[dto.MonitoringAlertResponse]{
+		Data:       make([]dto.MonitoringAlertResponse, 0, len(alerts)),
+		NextCursor: nextCursor,
+	}
+
+	for _, alert := range alerts {
+		resp.Data = append(resp.Data, newMonitoringAlertResponse(alert))
+	}
+
+	return resp, nil
+}
+
+// GetAlert godoc
+// @Summary      Get Monitoring Alert
+// @Description  Get a monitoring alert by ID with the ids of the events supporting it
+// @Tags         Monitoring
+// @ID           getMonitoringAlert
+// @Produce      json
+// @Param        id	path		int	true	"Alert ID"
+// @Success      200  {object}  dto.MonitoringAlertResponse	"Alert"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Not Found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /monitoring/alerts/{id} [get].
+func (s *MonitoringService) GetAlert(ctx context.Context,
+	req dto.MonitoringAlertIDRequest,
+) (dto.MonitoringAlertResponse, error) {
+	alert, err := s.alertRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return dto.MonitoringAlertResponse{}, fmt.Errorf("failed to find monitoring alert: %w", err)
+	}
+
+	return newMonitoringAlertResponse(alert), nil
+}
+
+// DispositionAlert godoc
+// @Summary      Disposition Monitoring Alert
+// @Description  Close an open alert as dismissed, a false positive, or escalated for investigation. The reviewer is
+// @Description  the authenticated client
+// @Tags         Monitoring
+// @ID           dispositionMonitoringAlert
+// @Accept       json
+// @Produce      json
+// @Param        id	path		int	true	"Alert ID"
+// @Param        req body disposition monitoring alert	body		dto.DispositionMonitoringAlertRequest	true	"Disposition"
+// @Success      200  {object}  dto.MonitoringAlertResponse	"Alert"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Not Found"
+// @Failure      409  {object}  dto.ErrorResponse	"Already Reviewed"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /monitoring/alerts/{id}/disposition [post].
+func (s *MonitoringService) DispositionAlert(ctx context.Context,
+	req dto.DispositionMonitoringAlertRequest,
+) (dto.MonitoringAlertResponse, error) {
+	var alert model.MonitoringAlert
+
+	err := s.alertRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		var err error
+
+		alert, err = s.alertRepository.FindByIDForUpdateTx(ctx, dbTx, req.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find monitoring alert: %w", err)
+		}
+
+		if alert.Status != model.MonitoringAlertStatusOpen {
+			return ErrMonitoringAlertReviewed
+		}
+
+		now := time.Now()
+
+		alert.Status = model.MonitoringAlertStatus(req.Status)
+		alert.Note = req.Note
+		alert.ReviewedBy = &req.ReviewedBy
+		alert.ReviewedAt = &now
+		alert.UpdatedAt = now
+
+		if err := s.alertRepository.UpdateTx(ctx, dbTx, &alert); err != nil {
+			return fmt.Errorf("failed to update monitoring alert: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.MonitoringAlertResponse{}, fmt.Errorf("failed to disposition monitoring alert: %w", err)
+	}
+
+	return newMonitoringAlertResponse(alert), nil
+}
+
+func newMonitoringMovement(movement model.AccountMovement) monitoring.Movement {
+	direction := monitoring.DirectionIn
+	if movement.EventType == model.EventTypeDebitBalance {
+		direction = monitoring.DirectionOut
+	}
+
+	return monitoring.Movement{
+		EventID:        movement.EventID,
+		AccountID:      movement.AccountID,
+		CounterpartyID: movement.CounterpartyAccountID,
+		Direction:      direction,
+		Amount:         movement.Amount,
+		At:             movement.CreatedAt,
+	}
+}
+
+func newMonitoringAlert(finding monitoring.Finding, now time.Time) model.MonitoringAlert {
+	return model.MonitoringAlert{
+		Detector:    finding.Detector,
+		AccountID:   finding.AccountID,
+		Reason:      finding.Reason,
+		Amount:      finding.Amount,
+		EventIDs:    finding.EventIDs,
+		WindowStart: finding.From,
+		WindowEnd:   finding.To,
+		Status:      model.MonitoringAlertStatusOpen,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func newMonitoringAlertResponse(alert model.MonitoringAlert) dto.MonitoringAlertResponse {
+	return dto.MonitoringAlertResponse{
+		ID:          alert.ID,
+		Detector:    alert.Detector,
+		AccountID:   alert.AccountID,
+		Reason:      alert.Reason,
+		Amount:      alert.Amount,
+		EventIDs:    alert.EventIDs,
+		WindowStart: alert.WindowStart,
+		WindowEnd:   alert.WindowEnd,
+		Status:      string(alert.Status),
+		Note:        alert.Note,
+		ReviewedBy:  alert.ReviewedBy,
+		ReviewedAt:  alert.ReviewedAt,
+		CreatedAt:   alert.CreatedAt,
+	}
+}
@@ -0,0 +1,165 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/monitoring"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitoringService_Scan(t *testing.T) {
+	detectors := []monitoring.Detector{
+		monitoring.Fan{Direction: monitoring.DirectionIn, MinCounterparties: 2, Period: 6 * time.Hour},
+		monitoring.RapidMovement{MinAmount: decimal.NewFromInt(100), Ratio: decimal.NewFromInt(1), Period: time.Hour},
+	}
+
+	now := time.Now()
+	movements := []model.AccountMovement{
+		{EventID: 1, EventType: model.EventTypeCreditBalance, AccountID: 1, CounterpartyAccountID: 2,
+			Amount: decimal.NewFromInt(100), CreatedAt: now.Add(-2 * time.Hour)},
+		{EventID: 2, EventType: model.EventTypeCreditBalance, AccountID: 1, CounterpartyAccountID: 3,
+			Amount: decimal.NewFromInt(50), CreatedAt: now.Add(-90 * time.Minute)},
+		{EventID: 3, EventType: model.EventTypeDebitBalance, AccountID: 1, CounterpartyAccountID: 4,
+			Amount: decimal.NewFromInt(150), CreatedAt: now.Add(-time.Hour)},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		alertRepo := &monitoringAlertRepositoryMock{}
+		movementRepo := &accountMovementRepositoryMock{movements: movements}
+		svc := NewMonitoringService(alertRepo, movementRepo, detectors)
+
+		raised, err := svc.Scan(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, raised)
+		assert.Equal(t, 6*time.Hour, movementRepo.to.Sub(movementRepo.from))
+		assert.Equal(t, movementRepo.from, alertRepo.sinceArgument)
+
+		if assert.Len(t, alertRepo.created, 2) {
+			assert.Equal(t, "fan_in", alertRepo.created[0].Detector)
+			assert.Equal(t, []int64{1, 2}, alertRepo.created[0].EventIDs)
+			assert.Equal(t, model.MonitoringAlertStatusOpen, alertRepo.created[0].Status)
+			assert.Equal(t, "rapid_movement", alertRepo.created[1].Detector)
+			assert.Equal(t, []int64{1, 2, 3}, alertRepo.created[1].EventIDs)
+			assert.True(t, decimal.NewFromInt(300).Equal(alertRepo.created[1].Amount))
+			assert.Equal(t, now.Add(-2*time.Hour), alertRepo.created[1].WindowStart)
+			assert.Equal(t, now.Add(-time.Hour), alertRepo.created[1].WindowEnd)
+		}
+	})
+
+	t.Run("success_skip_reported", func(t *testing.T) {
+		alertRepo := &monitoringAlertRepositoryMock{
+			alerts: []model.MonitoringAlert{
+				{Detector: "fan_in", AccountID: 1, EventIDs: []int64{2}},
+				{Detector: "fan_in", AccountID: 2, EventIDs: []int64{2, 3}},
+			},
+			// a concurrent scan raised the same pattern
+			errCreate: []error{exception.ErrRecordNotUnique},
+		}
+		svc := NewMonitoringService(alertRepo, &accountMovementRepositoryMock{movements: movements}, detectors)
+
+		raised, err := svc.Scan(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, raised)
+		assert.Empty(t, alertRepo.created)
+		assert.Equal(t, 1, alertRepo.createCount)
+	})
+
+	t.Run("success_no_detectors", func(t *testing.T) {
+		alertRepo := &monitoringAlertRepositoryMock{}
+		svc := NewMonitoringService(alertRepo, &accountMovementRepositoryMock{movements: movements}, nil)
+
+		raised, err := svc.Scan(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, raised)
+		assert.Equal(t, 0, alertRepo.createCount)
+	})
+}
+
+func TestMonitoringService_ListAlerts(t *testing.T) {
+	alertRepo := &monitoringAlertRepositoryMock{
+		alerts: []model.MonitoringAlert{{ID: 9}, {ID: 8}, {ID: 7}},
+	}
+	svc := NewMonitoringService(alertRepo, &accountMovementRepositoryMock{}, nil)
+
+	resp, err := svc.ListAlerts(context.Background(), dto.ListMonitoringAlertsRequest{
+		Status:    "open",
+		AccountID: 1,
+		Limit:     2,
+		Cursor:    pagination.Cursor{Sort: monitoringAlertSort, ID: 10}.Encode(),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data, 2)
+	assert.NotEmpty(t, resp.NextCursor)
+
+	if assert.Len(t, alertRepo.filters, 1) {
+		assert.Equal(t, model.MonitoringAlertFilter{
+			Status:    model.MonitoringAlertStatusOpen,
+			AccountID: 1,
+			BeforeID:  10,
+			Limit:     3,
+		}, alertRepo.filters[0])
+	}
+
+	_, err = svc.ListAlerts(context.Background(), dto.ListMonitoringAlertsRequest{Limit: 2, Cursor: "invalid"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestMonitoringService_DispositionAlert(t *testing.T) {
+	req := dto.DispositionMonitoringAlertRequest{
+		ID:         1,
+		ReviewedBy: "carol",
+		Status:     "escalated",
+		Note:       "reported to the FIU",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		alertRepo := &monitoringAlertRepositoryMock{
+			alert: model.MonitoringAlert{ID: 1, Status: model.MonitoringAlertStatusOpen},
+		}
+		svc := NewMonitoringService(alertRepo, &accountMovementRepositoryMock{}, nil)
+
+		resp, err := svc.DispositionAlert(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "escalated", resp.Status)
+		assert.Equal(t, "reported to the FIU", resp.Note)
+
+		if assert.Len(t, alertRepo.updated, 1) {
+			assert.Equal(t, model.MonitoringAlertStatusEscalated, alertRepo.updated[0].Status)
+			assert.Equal(t, "carol", *alertRepo.updated[0].ReviewedBy)
+			assert.NotNil(t, alertRepo.updated[0].ReviewedAt)
+		}
+	})
+
+	t.Run("error_already_reviewed", func(t *testing.T) {
+		alertRepo := &monitoringAlertRepositoryMock{
+			alert: model.MonitoringAlert{ID: 1, Status: model.MonitoringAlertStatusDismissed},
+		}
+		svc := NewMonitoringService(alertRepo, &accountMovementRepositoryMock{}, nil)
+
+		_, err := svc.DispositionAlert(context.Background(), req)
+
+		var appErr exception.ApplicationError
+		if assert.ErrorAs(t, err, &appErr) {
+			assert.Equal(t, ErrMonitoringAlertReviewed.MessageID, appErr.MessageID)
+		}
+
+		assert.Empty(t, alertRepo.updated)
+	})
+
+	t.Run("error_not_found", func(t *testing.T) {
+		alertRepo := &monitoringAlertRepositoryMock{errFindByID: exception.ErrRecordNotFound}
+		svc := NewMonitoringService(alertRepo, &accountMovementRepositoryMock{}, nil)
+
+		_, err := svc.DispositionAlert(context.Background(), req)
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+}
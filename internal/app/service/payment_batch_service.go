@@ -0,0 +1,640 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pain"
+)
+
+type PaymentBatchRepository interface {
+	WithTransaction(ctx context.Context, txFunc func(context.Context, *sql.Tx) error) error
+	CreateTx(ctx context.Context, tx *sql.Tx, batch *model.PaymentBatch) error
+	UpdateTx(ctx context.Context, tx *sql.Tx, batch *model.PaymentBatch) error
+	UpdateInstructionTx(ctx context.Context, tx *sql.Tx, instruction *model.PaymentInstruction) error
+	FindByID(ctx context.Context, id int64) (model.PaymentBatch, error)
+	FindByMessageID(ctx context.Context, messageID string) (model.PaymentBatch, error)
+	FindProcessingByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (model.PaymentBatch, error)
+	FindNextDueInstructionTx(ctx context.Context, tx *sql.Tx, batchID int64,
+		now time.Time) (model.PaymentInstruction, error)
+	CountPendingInstructionsTx(ctx context.Context, tx *sql.Tx, batchID int64) (int, error)
+	FindAllDueBatchIDs(ctx context.Context, now time.Time, limit int) ([]int64, error)
+}
+
+// paymentBatchInitiator initiates the transfers of the payment instructions, a transfer held for approval is reviewed
+// by any authenticated client.
+const paymentBatchInitiator = "payment_batch"
+
+// transferRejectionReasons maps the errors rejecting a transfer to the status reason reported for the instruction,
+// the other errors are reported as a narrative.
+var transferRejectionReasons = map[string]string{
+	ErrSourceAccountNotFound.MessageID:       pain.ReasonInvalidDebtorAccount,
+	ErrDestinationAccountNotFound.MessageID:  pain.ReasonInvalidCreditorAccount,
+	ErrInsufficientBalance.MessageID:         pain.ReasonInsufficientFunds,
+	ErrAccountFrozen.MessageID:               pain.ReasonBlockedAccount,
+	ErrAccountClosed.MessageID:               pain.ReasonClosedAccount,
+	ErrCurrencyMismatch.MessageID:            pain.ReasonIncorrectCurrency,
+	ErrPerTransactionLimitExceeded.MessageID: pain.ReasonNotAllowedAmount,
+	ErrDailyLimitExceeded.MessageID:          pain.ReasonNotAllowedAmount,
+	ErrMonthlyLimitExceeded.MessageID:        pain.ReasonNotAllowedAmount,
+	ErrHourlyCountLimitExceeded.MessageID:    pain.ReasonNotAllowedAmount,
+}
+
+type PaymentBatchService struct {
+	paymentBatchRepository PaymentBatchRepository
+	accountRepository      AccountRepository
+	eventRepository        EventRepository
+	transferer             Transferer
+	requestTimeThreshold   time.Duration
+	eventVersion           string
+	maxTransactions        int
+	batchSize              int
+}
+
+func NewPaymentBatchService(paymentBatchRepository PaymentBatchRepository, accountRepository AccountRepository,
+	eventRepository EventRepository, transferer Transferer, requestTimeThreshold time.Duration,
+	eventVersion string, maxTransactions int, batchSize int,
+) *PaymentBatchService {
+	return &PaymentBatchService{
+		paymentBatchRepository: paymentBatchRepository,
+		accountRepository:      accountRepository,
+		eventRepository:        eventRepository,
+		transferer:             transferer,
+		requestTimeThreshold:   requestTimeThreshold,
+		eventVersion:           eventVersion,
+		maxTransactions:        maxTransactions,
+		batchSize:              batchSize,
+	}
+}
+
+// SubmitPaymentBatch godoc
+// @Summary      Submit Payment Batch
+// @Description  Submit a pain.001.001.09 customer credit transfer initiation. Every transaction is a transfer from
+// @Description  the debtor account to the creditor account, identified by their account id, executed on its
+// @Description  requested execution date. The pain.002.001.10 status report of the batch is returned
+// @Tags         Transfer
+// @ID           submitPaymentBatch
+// @Accept       xml
+// @Produce      xml
+// @Param        req body pain.001	body		string	true	"pain.001.001.09 document"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      201  {string}  string	"pain.002.001.10 status report"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /payment-batches [post].
+func (s *PaymentBatchService) SubmitPaymentBatch(ctx context.Context,
+	req dto.SubmitPaymentBatchRequest,
+) (dto.PaymentStatusReportResponse, error) {
+	reqContext, err := getRequestContext(ctx, s.requestTimeThreshold)
+	if err != nil {
+		return dto.PaymentStatusReportResponse{}, fmt.Errorf("failed to get request context: %w", err)
+	}
+
+	initiation, err := pain.Parse(bytes.NewReader(req.Content))
+	if err != nil {
+		appErr := ErrInvalidPaymentInitiation
+		appErr.Cause = err
+
+		return dto.PaymentStatusReportResponse{}, appErr
+	}
+
+	if err := s.checkSubmittable(ctx, initiation); err != nil {
+		return dto.PaymentStatusReportResponse{}, err
+	}
+
+	batch := newPaymentBatch(initiation, reqContext.TransactionID, time.Now())
+
+	err = s.paymentBatchRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := s.paymentBatchRepository.CreateTx(ctx, dbTx, &batch); err != nil {
+			return fmt.Errorf("failed to create payment batch: %w", err)
+		}
+
+		eventCollector, err := NewPaymentBatchEventCollector(ctx, s.eventRepository, batch.ID,
+			batch.TransactionID, s.eventVersion)
+		if err != nil {
+			return fmt.Errorf("failed to create payment batch event collector: %w", err)
+		}
+
+		collectReceivedEvents(eventCollector, batch)
+
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil && errors.Is(err, exception.ErrRecordNotUnique) {
+		return dto.PaymentStatusReportResponse{}, ErrIdempotency
+	}
+
+	if err != nil {
+		return dto.PaymentStatusReportResponse{}, fmt.Errorf("failed to submit payment batch: %w", err)
+	}
+
+	// the instructions left pending by a failure are executed by the worker
+	if _, err := s.executeBatch(ctx, batch.ID); err != nil {
+		slog.ErrorContext(ctx, "failed to execute payment batch",
+			slog.Int64("payment_batch_id", batch.ID),
+			slog.String("error", err.Error()))
+	}
+
+	return s.statusReport(ctx, batch.ID)
+}
+
+// GetPaymentBatch godoc
+// @Summary      Get Payment Batch
+// @Description  Get a Payment Batch by ID with the status of each of its instructions
+// @Tags         Transfer
+// @ID           getPaymentBatch
+// @Produce      json
+// @Param        id	path		string	true	"Payment Batch ID"
+// @Success      200  {object}  dto.PaymentBatchResponse	"Payment Batch"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /payment-batches/{id} [get].
+func (s *PaymentBatchService) GetPaymentBatch(ctx context.Context,
+	req dto.PaymentBatchIDRequest,
+) (dto.PaymentBatchResponse, error) {
+	batch, err := s.paymentBatchRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return dto.PaymentBatchResponse{}, fmt.Errorf("failed to get payment batch: %w", err)
+	}
+
+	return toPaymentBatchResponse(batch), nil
+}
+
+// GetPaymentStatusReport godoc
+// @Summary      Get Payment Status Report
+// @Description  Get the current pain.002.001.10 status report of a Payment Batch
+// @Tags         Transfer
+// @ID           getPaymentStatusReport
+// @Produce      xml
+// @Param        id	path		string	true	"Payment Batch ID"
+// @Success      200  {string}  string	"pain.002.001.10 status report"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /payment-batches/{id}/status-report [get].
+func (s *PaymentBatchService) GetPaymentStatusReport(ctx context.Context,
+	req dto.PaymentBatchIDRequest,
+) (dto.PaymentStatusReportResponse, error) {
+	return s.statusReport(ctx, req.ID)
+}
+
+// ExecuteDue executes the due instructions of the payment batches, those with a later requested execution date and
+// those left pending by a failure. It returns the number of instructions that were executed or rejected.
+func (s *PaymentBatchService) ExecuteDue(ctx context.Context) (int, error) {
+	batchIDs, err := s.paymentBatchRepository.FindAllDueBatchIDs(ctx, time.Now(), s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due payment batches: %w", err)
+	}
+
+	processed := 0
+
+	for _, batchID := range batchIDs {
+		executed, err := s.executeBatch(ctx, batchID)
+		processed += executed
+
+		if err != nil {
+			// keep going, the instruction is still due and is retried on the next run
+			slog.ErrorContext(ctx, "failed to execute payment batch",
+				slog.Int64("payment_batch_id", batchID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	return processed, nil
+}
+
+// checkSubmittable rejects an initiation above the transaction limit or with the message id of a received batch.
+func (s *PaymentBatchService) checkSubmittable(ctx context.Context, initiation pain.Initiation) error {
+	count := 0
+	for _, payment := range initiation.Payments {
+		count += len(payment.Transactions)
+	}
+
+	if s.maxTransactions > 0 && max(count, initiation.NumberOfTransactions) > s.maxTransactions {
+		err := ErrPaymentBatchTooLarge
+		err.MessageVars = map[string]interface{}{
+			"max": s.maxTransactions,
+		}
+
+		return err
+	}
+
+	_, err := s.paymentBatchRepository.FindByMessageID(ctx, initiation.MessageID)
+	if err == nil {
+		duplicateErr := ErrDuplicatePaymentBatch
+		duplicateErr.MessageVars = map[string]interface{}{
+			"message_id": initiation.MessageID,
+		}
+
+		return duplicateErr
+	}
+
+	if !errors.Is(err, exception.ErrRecordNotFound) {
+		return fmt.Errorf("failed to find payment batch: %w", err)
+	}
+
+	return nil
+}
+
+// executeBatch executes the due instructions of a batch one by one, it returns the number of instructions that
+// were executed or rejected.
+func (s *PaymentBatchService) executeBatch(ctx context.Context, batchID int64) (int, error) {
+	executed := 0
+
+	for {
+		done, err := s.executeNextInstruction(ctx, batchID)
+		if err != nil {
+			return executed, err
+		}
+
+		if !done {
+			return executed, nil
+		}
+
+		executed++
+	}
+}
+
+func (s *PaymentBatchService) executeNextInstruction(ctx context.Context, batchID int64) (bool, error) {
+	done := false
+
+	err := s.paymentBatchRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		now := time.Now()
+
+		// the batch lock is held while transferring, so the instructions of a batch are executed by one worker
+		batch, err := s.paymentBatchRepository.FindProcessingByIDForUpdateTx(ctx, dbTx, batchID)
+		if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to find payment batch: %w", err)
+		}
+
+		instruction, err := s.paymentBatchRepository.FindNextDueInstructionTx(ctx, dbTx, batch.ID, now)
+		if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to find payment instruction: %w", err)
+		}
+
+		if err := s.executeInstruction(ctx, &instruction, now); err != nil {
+			return err
+		}
+
+		if err := s.recordOutcomeTx(ctx, dbTx, &batch, &instruction, now); err != nil {
+			return err
+		}
+
+		done = true
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to execute payment instruction: %w", err)
+	}
+
+	return done, nil
+}
+
+// executeInstruction transfers the amount of an instruction and sets its status. The instruction is rejected for
+// a business error, a system error is returned and the instruction stays pending.
+func (s *PaymentBatchService) executeInstruction(ctx context.Context, instruction *model.PaymentInstruction,
+	now time.Time,
+) error {
+	account, err := s.accountRepository.FindByID(ctx, instruction.SourceAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		instruction.Reject(pain.ReasonInvalidDebtorAccount, ErrSourceAccountNotFound.Message)
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to find account: %w", err)
+	}
+
+	if account.Currency != instruction.Currency {
+		instruction.Reject(pain.ReasonIncorrectCurrency, fmt.Sprintf("currency %s differs from the currency %s of "+
+			"the debtor account", instruction.Currency, account.Currency))
+
+		return nil
+	}
+
+	transferCtx := dto.ContextWithRequestContext(ctx, dto.RequestContext{
+		TransactionID: instruction.TransferTransactionID(),
+		Timestamp:     now,
+	})
+
+	resp, transferErr := s.transferer.Transfer(transferCtx, dto.CreateTransferRequest{
+		SourceAccountID:      instruction.SourceAccountID,
+		DestinationAccountID: instruction.DestinationAccountID,
+		Amount:               instruction.Amount,
+		InitiatedBy:          paymentBatchInitiator,
+	})
+
+	var appErr exception.ApplicationError
+
+	switch {
+	case transferErr == nil && resp.Status == string(model.TransactionStatusPendingApproval):
+		instruction.Status = model.PaymentInstructionStatusPendingApproval
+	case transferErr == nil, errors.Is(transferErr, ErrIdempotency):
+		// an idempotency error means the transfer was made before a restart
+		instruction.Status = model.PaymentInstructionStatusAccepted
+	case errors.As(transferErr, &appErr):
+		reasonCode, ok := transferRejectionReasons[appErr.MessageID]
+		if !ok {
+			reasonCode = pain.ReasonNarrative
+		}
+
+		instruction.Reject(reasonCode, appErr.Message)
+	default:
+		return fmt.Errorf("failed to transfer: %w", transferErr)
+	}
+
+	return nil
+}
+
+// recordOutcomeTx stores the status of an executed instruction and its event, the batch is completed with its last
+// pending instruction.
+func (s *PaymentBatchService) recordOutcomeTx(ctx context.Context, dbTx *sql.Tx, batch *model.PaymentBatch,
+	instruction *model.PaymentInstruction, now time.Time,
+) error {
+	eventCollector, err := NewPaymentBatchEventCollector(ctx, s.eventRepository, batch.ID,
+		batch.TransactionID, s.eventVersion)
+	if err != nil {
+		return fmt.Errorf("failed to create payment batch event collector: %w", err)
+	}
+
+	if instruction.Status == model.PaymentInstructionStatusRejected {
+		eventCollector.OnInstructionRejectedEvent(*instruction)
+	} else {
+		eventCollector.OnInstructionExecutedEvent(*instruction)
+	}
+
+	instruction.UpdatedAt = now
+
+	if err := s.paymentBatchRepository.UpdateInstructionTx(ctx, dbTx, instruction); err != nil {
+		return fmt.Errorf("failed to update payment instruction: %w", err)
+	}
+
+	pending, err := s.paymentBatchRepository.CountPendingInstructionsTx(ctx, dbTx, batch.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count pending payment instructions: %w", err)
+	}
+
+	if pending == 0 {
+		batch.Status = model.PaymentBatchStatusCompleted
+		eventCollector.OnCompletedEvent()
+	}
+
+	batch.UpdatedAt = now
+
+	if err := s.paymentBatchRepository.UpdateTx(ctx, dbTx, batch); err != nil {
+		return fmt.Errorf("failed to update payment batch: %w", err)
+	}
+
+	if err := eventCollector.Place(ctx, dbTx); err != nil {
+		return fmt.Errorf("failed to place events: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PaymentBatchService) statusReport(ctx context.Context,
+	batchID int64,
+) (dto.PaymentStatusReportResponse, error) {
+	batch, err := s.paymentBatchRepository.FindByID(ctx, batchID)
+	if err != nil {
+		return dto.PaymentStatusReportResponse{}, fmt.Errorf("failed to get payment batch: %w", err)
+	}
+
+	document, err := newStatusReport(batch, time.Now()).Marshal()
+	if err != nil {
+		return dto.PaymentStatusReportResponse{}, fmt.Errorf("failed to create status report: %w", err)
+	}
+
+	return dto.PaymentStatusReportResponse{Document: document}, nil
+}
+
+// newPaymentBatch builds the batch of an initiation, numbering its transactions in file order. The transactions
+// are all rejected when the initiation is invalid, otherwise those that cannot be transferred are rejected. A batch
+// without instruction left to execute is completed.
+func newPaymentBatch(initiation pain.Initiation, transactionID string, now time.Time) model.PaymentBatch {
+	batch := model.PaymentBatch{
+		TransactionID:        transactionID,
+		MessageID:            initiation.MessageID,
+		InitiatingParty:      initiation.InitiatingParty,
+		NumberOfTransactions: initiation.NumberOfTransactions,
+		ControlSum:           initiation.ControlSum,
+		Status:               model.PaymentBatchStatusProcessing,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	batchReason := initiation.Validate()
+	if batchReason != nil {
+		batch.Status = model.PaymentBatchStatusRejected
+		batch.ReasonCode = batchReason.Code
+		batch.Reason = batchReason.Info
+	}
+
+	for _, payment := range initiation.Payments {
+		for _, transaction := range payment.Transactions {
+			instruction := newPaymentInstruction(payment, transaction, len(batch.Instructions)+1, now)
+
+			if batchReason != nil {
+				instruction.Reject(batchReason.Code, batchReason.Info)
+			}
+
+			batch.Instructions = append(batch.Instructions, instruction)
+		}
+	}
+
+	if batch.Status == model.PaymentBatchStatusProcessing && batch.Count(model.PaymentInstructionStatusPending) == 0 {
+		batch.Status = model.PaymentBatchStatusCompleted
+	}
+
+	return batch
+}
+
+func newPaymentInstruction(payment pain.Payment, transaction pain.Transaction, sequence int,
+	now time.Time,
+) model.PaymentInstruction {
+	instruction := model.PaymentInstruction{
+		Sequence:             sequence,
+		PaymentInformationID: payment.ID,
+		InstructionID:        transaction.InstructionID,
+		EndToEndID:           transaction.EndToEndID,
+		Amount:               transaction.Amount,
+		Currency:             transaction.Currency,
+		ExecuteAt:            payment.RequestedExecutionDate,
+		Status:               model.PaymentInstructionStatusPending,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	var sourceOK, destinationOK bool
+
+	instruction.SourceAccountID, sourceOK = parseAccountID(payment.DebtorAccount)
+	instruction.DestinationAccountID, destinationOK = parseAccountID(transaction.CreditorAccount)
+
+	switch reason := transaction.Validate(); {
+	case reason != nil:
+		instruction.Reject(reason.Code, reason.Info)
+	case !sourceOK:
+		instruction.Reject(pain.ReasonInvalidDebtorAccount,
+			fmt.Sprintf("debtor account %q is not an account id", payment.DebtorAccount))
+	case !destinationOK:
+		instruction.Reject(pain.ReasonInvalidCreditorAccount,
+			fmt.Sprintf("creditor account %q is not an account id", transaction.CreditorAccount))
+	}
+
+	return instruction
+}
+
+// parseAccountID reads the account id of an account identification, the accounts are identified by their id.
+func parseAccountID(identification string) (int64, bool) {
+	id, err := strconv.ParseInt(identification, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// collectReceivedEvents collects the events of a new batch: its reception, its rejection or the rejection of the
+// instructions that cannot be executed, and its completion.
+func collectReceivedEvents(eventCollector *PaymentBatchEventCollector, batch model.PaymentBatch) {
+	eventCollector.OnReceivedEvent(batch)
+
+	if batch.Status == model.PaymentBatchStatusRejected {
+		eventCollector.OnRejectedEvent(batch)
+
+		return
+	}
+
+	for _, instruction := range batch.Instructions {
+		if instruction.Status == model.PaymentInstructionStatusRejected {
+			eventCollector.OnInstructionRejectedEvent(instruction)
+		}
+	}
+
+	if batch.Status == model.PaymentBatchStatusCompleted {
+		eventCollector.OnCompletedEvent()
+	}
+}
+
+// newStatusReport builds the pain.002 report of a batch, the transactions are grouped by payment information in
+// file order.
+func newStatusReport(batch model.PaymentBatch, now time.Time) pain.StatusReport {
+	report := pain.StatusReport{
+		MessageID:                    fmt.Sprintf("PB-%d-%s", batch.ID, now.UTC().Format("20060102150405")),
+		CreatedAt:                    now,
+		OriginalMessageID:            batch.MessageID,
+		OriginalNumberOfTransactions: batch.NumberOfTransactions,
+		OriginalControlSum:           batch.ControlSum,
+	}
+
+	if batch.Status == model.PaymentBatchStatusRejected {
+		report.Reason = &pain.Reason{Code: batch.ReasonCode, Info: batch.Reason}
+	}
+
+	for _, instruction := range batch.Instructions {
+		last := len(report.Payments) - 1
+		if last < 0 || report.Payments[last].OriginalPaymentID != instruction.PaymentInformationID {
+			report.Payments = append(report.Payments, pain.PaymentStatus{
+				OriginalPaymentID: instruction.PaymentInformationID,
+			})
+			last++
+		}
+
+		transactionStatus := pain.TransactionStatus{
+			StatusID:              fmt.Sprintf("PB-%d-%d", batch.ID, instruction.Sequence),
+			OriginalInstructionID: instruction.InstructionID,
+			OriginalEndToEndID:    instruction.EndToEndID,
+			Amount:                instruction.Amount,
+			Status:                transactionStatus(instruction.Status),
+		}
+
+		if instruction.Status == model.PaymentInstructionStatusRejected {
+			transactionStatus.Reason = &pain.Reason{Code: instruction.ReasonCode, Info: instruction.Reason}
+		}
+
+		report.Payments[last].Transactions = append(report.Payments[last].Transactions, transactionStatus)
+	}
+
+	return report
+}
+
+func transactionStatus(status model.PaymentInstructionStatus) pain.Status {
+	switch status {
+	case model.PaymentInstructionStatusAccepted:
+		return pain.StatusAccepted
+	case model.PaymentInstructionStatusRejected:
+		return pain.StatusRejected
+	default:
+		return pain.StatusPending
+	}
+}
+
+func toPaymentBatchResponse(batch model.PaymentBatch) dto.PaymentBatchResponse {
+	resp := dto.PaymentBatchResponse{
+		ID:                   batch.ID,
+		MessageID:            batch.MessageID,
+		InitiatingParty:      batch.InitiatingParty,
+		NumberOfTransactions: batch.NumberOfTransactions,
+		Status:               string(batch.Status),
+		ReasonCode:           batch.ReasonCode,
+		Reason:               batch.Reason,
+		Summary: dto.PaymentBatchSummary{
+			Pending:         batch.Count(model.PaymentInstructionStatusPending),
+			Accepted:        batch.Count(model.PaymentInstructionStatusAccepted),
+			PendingApproval: batch.Count(model.PaymentInstructionStatusPendingApproval),
+			Rejected:        batch.Count(model.PaymentInstructionStatusRejected),
+		},
+		Instructions: make([]dto.PaymentInstructionData, 0, len(batch.Instructions)),
+		CreatedAt:    batch.CreatedAt,
+		UpdatedAt:    batch.UpdatedAt,
+	}
+
+	if batch.ControlSum.Valid {
+		resp.ControlSum = &batch.ControlSum.Decimal
+	}
+
+	for _, instruction := range batch.Instructions {
+		resp.Instructions = append(resp.Instructions, dto.PaymentInstructionData{
+			Sequence:             instruction.Sequence,
+			PaymentInformationID: instruction.PaymentInformationID,
+			InstructionID:        instruction.InstructionID,
+			EndToEndID:           instruction.EndToEndID,
+			TransactionID:        instruction.TransferTransactionID(),
+			SourceAccountID:      instruction.SourceAccountID,
+			DestinationAccountID: instruction.DestinationAccountID,
+			Amount:               instruction.Amount,
+			Currency:             instruction.Currency,
+			ExecuteAt:            instruction.ExecuteAt,
+			Status:               string(instruction.Status),
+			ReasonCode:           instruction.ReasonCode,
+			Reason:               instruction.Reason,
+		})
+	}
+
+	return resp
+}
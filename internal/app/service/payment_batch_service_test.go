@@ -0,0 +1,419 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pain"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPaymentInitiation = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-1</MsgId>
+      <CreDtTm>2026-10-18T09:30:00Z</CreDtTm>
+      <NbOfTxs>2</NbOfTxs>
+      <CtrlSum>150</CtrlSum>
+      <InitgPty><Nm>ACME Corp</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-1</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt><Dt>2026-10-18</Dt></ReqdExctnDt>
+      <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-1</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">100</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-2</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">50</InstdAmt></Amt>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func TestPaymentBatchService_SubmitPaymentBatch(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+	validReq := dto.SubmitPaymentBatchRequest{Content: []byte(testPaymentInitiation)}
+
+	testError := func(ctx context.Context, req dto.SubmitPaymentBatchRequest, svc *PaymentBatchService,
+		wantMessageID string,
+	) func(t *testing.T) {
+		return func(t *testing.T) {
+			_, err := svc.SubmitPaymentBatch(ctx, req)
+
+			var appErr exception.ApplicationError
+			if assert.ErrorAs(t, err, &appErr) {
+				assert.Equal(t, wantMessageID, appErr.MessageID)
+			}
+		}
+	}
+
+	t.Run("error_request_context", func(t *testing.T) {
+		svc := &PaymentBatchService{paymentBatchRepository: &paymentBatchRepositoryMock{}}
+
+		_, err := svc.SubmitPaymentBatch(context.Background(), validReq)
+		assert.ErrorContains(t, err, "request context not found")
+	})
+
+	t.Run("error_invalid_initiation", testError(ctx, dto.SubmitPaymentBatchRequest{Content: []byte("not xml")},
+		&PaymentBatchService{
+			requestTimeThreshold:   30 * time.Second,
+			paymentBatchRepository: &paymentBatchRepositoryMock{},
+		}, ErrInvalidPaymentInitiation.MessageID))
+
+	t.Run("error_too_large", testError(ctx, validReq, &PaymentBatchService{
+		requestTimeThreshold:   30 * time.Second,
+		maxTransactions:        1,
+		paymentBatchRepository: &paymentBatchRepositoryMock{},
+	}, ErrPaymentBatchTooLarge.MessageID))
+
+	t.Run("error_duplicate", testError(ctx, validReq, &PaymentBatchService{
+		requestTimeThreshold: 30 * time.Second,
+		paymentBatchRepository: &paymentBatchRepositoryMock{
+			errFindByMessageID: []error{nil},
+		},
+	}, ErrDuplicatePaymentBatch.MessageID))
+
+	t.Run("error_idempotency", testError(ctx, validReq, &PaymentBatchService{
+		requestTimeThreshold: 30 * time.Second,
+		paymentBatchRepository: &paymentBatchRepositoryMock{
+			errFindByMessageID: []error{exception.ErrRecordNotFound},
+			errCreateTx:        []error{fmt.Errorf("failed to exec statement: %w", exception.ErrRecordNotUnique)},
+		},
+	}, ErrIdempotency.MessageID))
+
+	t.Run("rejected_batch", func(t *testing.T) {
+		repo := &paymentBatchRepositoryMock{
+			errFindByMessageID:               []error{exception.ErrRecordNotFound},
+			errCreateTx:                      []error{nil},
+			errFindProcessingByIDForUpdateTx: []error{exception.ErrRecordNotFound},
+			errFindByID:                      []error{nil},
+			batch: model.PaymentBatch{
+				ID:         1,
+				MessageID:  "MSG-1",
+				Status:     model.PaymentBatchStatusRejected,
+				ReasonCode: pain.ReasonInvalidControlSum,
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:          []error{nil},
+			events:                   []model.Event{{}},
+		}
+		transferer := &transfererMock{}
+		svc := &PaymentBatchService{
+			requestTimeThreshold:   30 * time.Second,
+			paymentBatchRepository: repo,
+			eventRepository:        eventRepository,
+			transferer:             transferer,
+		}
+
+		req := dto.SubmitPaymentBatchRequest{
+			Content: []byte(strings.Replace(testPaymentInitiation, "<CtrlSum>150</CtrlSum>",
+				"<CtrlSum>99</CtrlSum>", 1)),
+		}
+
+		got, err := svc.SubmitPaymentBatch(ctx, req)
+		assert.NoError(t, err)
+		assert.Contains(t, string(got.Document), "<GrpSts>RJCT</GrpSts>")
+
+		created := repo.created[0]
+		assert.Equal(t, model.PaymentBatchStatusRejected, created.Status)
+		assert.Equal(t, pain.ReasonInvalidControlSum, created.ReasonCode)
+
+		for _, instruction := range created.Instructions {
+			assert.Equal(t, model.PaymentInstructionStatusRejected, instruction.Status)
+			assert.Equal(t, pain.ReasonInvalidControlSum, instruction.ReasonCode)
+		}
+
+		assert.Equal(t, model.EventTypePaymentBatchReceived, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.EventTypePaymentBatchRejected, eventRepository.placedEvents[1].EventType)
+		assert.Equal(t, 0, transferer.transferCallCount)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo := &paymentBatchRepositoryMock{
+			errFindByMessageID:               []error{exception.ErrRecordNotFound},
+			errCreateTx:                      []error{nil},
+			errFindProcessingByIDForUpdateTx: []error{nil, exception.ErrRecordNotFound},
+			errFindNextDueInstructionTx:      []error{nil},
+			errFindByID:                      []error{nil},
+			pending:                          []int{0},
+			batch: model.PaymentBatch{
+				ID:                   1,
+				TransactionID:        "tx-12345",
+				MessageID:            "MSG-1",
+				NumberOfTransactions: 2,
+				Status:               model.PaymentBatchStatusProcessing,
+				Instructions: []model.PaymentInstruction{
+					{BatchID: 1, Sequence: 1, PaymentInformationID: "PMT-1", EndToEndID: "E2E-1",
+						Amount: decimal.NewFromInt(100), Status: model.PaymentInstructionStatusAccepted},
+					{BatchID: 1, Sequence: 2, PaymentInformationID: "PMT-1", EndToEndID: "E2E-2",
+						Amount: decimal.NewFromInt(50), Status: model.PaymentInstructionStatusRejected,
+						ReasonCode: pain.ReasonInvalidCreditorAccount},
+				},
+			},
+			instructions: []model.PaymentInstruction{
+				{BatchID: 1, Sequence: 1, SourceAccountID: 1, DestinationAccountID: 2,
+					Amount: decimal.NewFromInt(100), Currency: "USD", Status: model.PaymentInstructionStatusPending},
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound, nil},
+			errCreateBulkTx:          []error{nil, nil},
+			events:                   []model.Event{{SequenceNumber: 2}},
+		}
+		transferer := &transfererMock{errTransfer: []error{nil}}
+		svc := &PaymentBatchService{
+			requestTimeThreshold:   30 * time.Second,
+			paymentBatchRepository: repo,
+			accountRepository: &accountRepositoryMock{
+				errFindByID: []error{nil},
+				account:     model.Account{ID: 1, Currency: "USD"},
+			},
+			eventRepository: eventRepository,
+			transferer:      transferer,
+		}
+
+		got, err := svc.SubmitPaymentBatch(ctx, validReq)
+		assert.NoError(t, err)
+
+		created := repo.created[0]
+		assert.Equal(t, "tx-12345", created.TransactionID)
+		assert.Equal(t, model.PaymentBatchStatusProcessing, created.Status)
+		assert.Equal(t, model.PaymentInstructionStatusPending, created.Instructions[0].Status)
+		assert.Equal(t, int64(1), created.Instructions[0].SourceAccountID)
+		assert.Equal(t, int64(2), created.Instructions[0].DestinationAccountID)
+		assert.Equal(t, model.PaymentInstructionStatusRejected, created.Instructions[1].Status)
+		assert.Equal(t, pain.ReasonInvalidCreditorAccount, created.Instructions[1].ReasonCode)
+
+		assert.Equal(t, []string{"payment-batch-1-1"}, transferer.transactionIDs)
+		assert.Equal(t, []string{paymentBatchInitiator}, transferer.initiators)
+		assert.Equal(t, model.PaymentBatchStatusCompleted, repo.updated[0].Status)
+
+		eventTypes := make([]model.EventType, 0, len(eventRepository.placedEvents))
+		for _, event := range eventRepository.placedEvents {
+			eventTypes = append(eventTypes, event.EventType)
+			assert.Equal(t, "tx-12345", event.TransactionID)
+		}
+
+		assert.Equal(t, []model.EventType{
+			model.EventTypePaymentBatchReceived,
+			model.EventTypePaymentInstructionRejected,
+			model.EventTypePaymentInstructionExecuted,
+			model.EventTypePaymentBatchCompleted,
+		}, eventTypes)
+
+		document := string(got.Document)
+		assert.Contains(t, document, "<OrgnlMsgId>MSG-1</OrgnlMsgId>")
+		assert.Contains(t, document, "<GrpSts>PART</GrpSts>")
+		assert.Contains(t, document, "<StsId>PB-1-2</StsId>")
+	})
+}
+
+func TestPaymentBatchService_GetPaymentBatch(t *testing.T) {
+	svc := &PaymentBatchService{
+		paymentBatchRepository: &paymentBatchRepositoryMock{
+			errFindByID: []error{nil},
+			batch: model.PaymentBatch{
+				ID:         1,
+				MessageID:  "MSG-1",
+				Status:     model.PaymentBatchStatusProcessing,
+				ControlSum: decimal.NewNullDecimal(decimal.NewFromInt(150)),
+				Instructions: []model.PaymentInstruction{
+					{BatchID: 1, Sequence: 1, Status: model.PaymentInstructionStatusAccepted},
+					{BatchID: 1, Sequence: 2, Status: model.PaymentInstructionStatusPending},
+					{BatchID: 1, Sequence: 3, Status: model.PaymentInstructionStatusPending},
+				},
+			},
+		},
+	}
+
+	got, err := svc.GetPaymentBatch(context.Background(), dto.PaymentBatchIDRequest{ID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, dto.PaymentBatchSummary{Accepted: 1, Pending: 2}, got.Summary)
+	assert.True(t, got.ControlSum.Equal(decimal.NewFromInt(150)))
+	assert.Equal(t, "payment-batch-1-2", got.Instructions[1].TransactionID)
+
+	_, err = (&PaymentBatchService{
+		paymentBatchRepository: &paymentBatchRepositoryMock{errFindByID: []error{exception.ErrRecordNotFound}},
+	}).GetPaymentBatch(context.Background(), dto.PaymentBatchIDRequest{ID: 1})
+	assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+}
+
+func TestPaymentBatchService_ExecuteDue(t *testing.T) {
+	dueInstruction := model.PaymentInstruction{
+		ID:                   1,
+		BatchID:              7,
+		Sequence:             3,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		Currency:             "USD",
+		Status:               model.PaymentInstructionStatusPending,
+	}
+
+	type result struct {
+		repo            *paymentBatchRepositoryMock
+		eventRepository *eventRepositoryMock
+		transferer      *transfererMock
+		processed       int
+	}
+
+	execute := func(t *testing.T, currency string, pending int, transferResp dto.TransferResponse,
+		transferErr error,
+	) result {
+		repo := &paymentBatchRepositoryMock{
+			errFindAllDueBatchIDs:            []error{nil},
+			batchIDs:                         []int64{7},
+			errFindProcessingByIDForUpdateTx: []error{nil, nil},
+			errFindNextDueInstructionTx:      []error{nil, exception.ErrRecordNotFound},
+			batch: model.PaymentBatch{
+				ID:            7,
+				TransactionID: "tx-batch",
+				Status:        model.PaymentBatchStatusProcessing,
+			},
+			instructions: []model.PaymentInstruction{dueInstruction},
+			pending:      []int{pending},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{nil},
+			errCreateBulkTx:          []error{nil},
+			events:                   []model.Event{{SequenceNumber: 1}},
+		}
+		transferer := &transfererMock{
+			errTransfer: []error{transferErr},
+			responses:   []dto.TransferResponse{transferResp},
+		}
+		svc := &PaymentBatchService{
+			paymentBatchRepository: repo,
+			accountRepository: &accountRepositoryMock{
+				errFindByID: []error{nil},
+				account:     model.Account{ID: 1, Currency: currency},
+			},
+			eventRepository: eventRepository,
+			transferer:      transferer,
+		}
+
+		processed, err := svc.ExecuteDue(context.Background())
+		assert.NoError(t, err)
+
+		return result{repo: repo, eventRepository: eventRepository, transferer: transferer, processed: processed}
+	}
+
+	t.Run("error_find_due", func(t *testing.T) {
+		svc := &PaymentBatchService{
+			paymentBatchRepository: &paymentBatchRepositoryMock{
+				errFindAllDueBatchIDs: []error{errors.New("internal db error")},
+			},
+		}
+
+		_, err := svc.ExecuteDue(context.Background())
+		assert.ErrorContains(t, err, "internal db error")
+	})
+
+	t.Run("skip_locked_by_another_worker", func(t *testing.T) {
+		transferer := &transfererMock{}
+		svc := &PaymentBatchService{
+			paymentBatchRepository: &paymentBatchRepositoryMock{
+				errFindAllDueBatchIDs:            []error{nil},
+				batchIDs:                         []int64{7},
+				errFindProcessingByIDForUpdateTx: []error{exception.ErrRecordNotFound},
+			},
+			transferer: transferer,
+		}
+
+		processed, err := svc.ExecuteDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, processed)
+		assert.Equal(t, 0, transferer.transferCallCount)
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		got := execute(t, "USD", 1, dto.TransferResponse{Status: string(model.TransactionStatusCompleted)}, nil)
+
+		assert.Equal(t, 1, got.processed)
+		assert.Equal(t, []string{"payment-batch-7-3"}, got.transferer.transactionIDs)
+		assert.Equal(t, model.PaymentInstructionStatusAccepted, got.repo.updatedInstructions[0].Status)
+		assert.Equal(t, model.PaymentBatchStatusProcessing, got.repo.updated[0].Status)
+		assert.Len(t, got.eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypePaymentInstructionExecuted, got.eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, "tx-batch", got.eventRepository.placedEvents[0].TransactionID)
+	})
+
+	t.Run("pending_approval", func(t *testing.T) {
+		got := execute(t, "USD", 1,
+			dto.TransferResponse{Status: string(model.TransactionStatusPendingApproval)}, nil)
+
+		assert.Equal(t, model.PaymentInstructionStatusPendingApproval, got.repo.updatedInstructions[0].Status)
+		assert.Equal(t, model.EventTypePaymentInstructionExecuted, got.eventRepository.placedEvents[0].EventType)
+	})
+
+	t.Run("accepted_already_executed_before_restart", func(t *testing.T) {
+		got := execute(t, "USD", 1, dto.TransferResponse{}, fmt.Errorf("transaction service: %w", ErrIdempotency))
+
+		assert.Equal(t, model.PaymentInstructionStatusAccepted, got.repo.updatedInstructions[0].Status)
+	})
+
+	t.Run("rejected_insufficient_balance", func(t *testing.T) {
+		got := execute(t, "USD", 1, dto.TransferResponse{},
+			fmt.Errorf("failed to process transfer: %w", ErrInsufficientBalance))
+
+		updated := got.repo.updatedInstructions[0]
+		assert.Equal(t, model.PaymentInstructionStatusRejected, updated.Status)
+		assert.Equal(t, pain.ReasonInsufficientFunds, updated.ReasonCode)
+		assert.Equal(t, ErrInsufficientBalance.Message, updated.Reason)
+		assert.Equal(t, model.EventTypePaymentInstructionRejected, got.eventRepository.placedEvents[0].EventType)
+	})
+
+	t.Run("rejected_narrative", func(t *testing.T) {
+		got := execute(t, "USD", 1, dto.TransferResponse{},
+			fmt.Errorf("failed to process transfer: %w", ErrSourceAndDestinationAccountSame))
+
+		assert.Equal(t, pain.ReasonNarrative, got.repo.updatedInstructions[0].ReasonCode)
+	})
+
+	t.Run("rejected_currency_mismatch", func(t *testing.T) {
+		got := execute(t, "EUR", 1, dto.TransferResponse{}, nil)
+
+		assert.Equal(t, 0, got.transferer.transferCallCount)
+		assert.Equal(t, pain.ReasonIncorrectCurrency, got.repo.updatedInstructions[0].ReasonCode)
+	})
+
+	t.Run("completed_with_last_instruction", func(t *testing.T) {
+		got := execute(t, "USD", 0, dto.TransferResponse{}, nil)
+
+		assert.Equal(t, model.PaymentBatchStatusCompleted, got.repo.updated[0].Status)
+		assert.Len(t, got.eventRepository.placedEvents, 2)
+		assert.Equal(t, model.EventTypePaymentBatchCompleted, got.eventRepository.placedEvents[1].EventType)
+	})
+
+	t.Run("retry_on_internal_error", func(t *testing.T) {
+		got := execute(t, "USD", 1, dto.TransferResponse{}, errors.New("internal db error"))
+
+		assert.Equal(t, 0, got.processed)
+		assert.Empty(t, got.repo.updatedInstructions)
+		assert.Empty(t, got.repo.updated)
+		assert.Empty(t, got.eventRepository.placedEvents)
+	})
+}
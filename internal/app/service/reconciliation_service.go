@@ -0,0 +1,190 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/bankstatement"
+)
+
+type ReconciliationRepository interface {
+	WithTransaction(ctx context.Context, txFunc func(context.Context, *sql.Tx) error) error
+	CreateTx(ctx context.Context, tx *sql.Tx, reconciliation *model.Reconciliation) error
+	FindByID(ctx context.Context, id int64) (model.Reconciliation, error)
+}
+
+type BankMovementRepository interface {
+	FindAllBankMovements(ctx context.Context, fundingAccountID int64, from time.Time,
+		to time.Time) ([]model.BankMovement, error)
+}
+
+type ReconciliationService struct {
+	reconciliationRepository ReconciliationRepository
+	bankMovementRepository   BankMovementRepository
+	fundingAccountID         int64
+	dateTolerance            time.Duration
+}
+
+func NewReconciliationService(reconciliationRepository ReconciliationRepository,
+	bankMovementRepository BankMovementRepository, fundingAccountID int64, dateTolerance time.Duration,
+) *ReconciliationService {
+	return &ReconciliationService{
+		reconciliationRepository: reconciliationRepository,
+		bankMovementRepository:   bankMovementRepository,
+		fundingAccountID:         fundingAccountID,
+		dateTolerance:            dateTolerance,
+	}
+}
+
+// ImportReconciliation godoc
+// @Summary      Import Bank Statement
+// @Description  Match the lines of a CSV, MT940 or CAMT.053 bank statement against the deposits and the withdrawals
+// @Description  to the funding account, by reference, amount and date, and store the result for review
+// @Tags         Reconciliation
+// @ID           importReconciliation
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file	formData	file	true	"Bank statement"
+// @Param        format	formData	string	false	"csv, mt940 or camt053, detected from the file when omitted"
+// @Success      201  {object}  dto.ReconciliationResponse	"Reconciliation"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /reconciliations [post].
+func (s *ReconciliationService) ImportReconciliation(ctx context.Context,
+	req dto.ImportReconciliationRequest,
+) (dto.ReconciliationResponse, error) {
+	statementLines, err := bankstatement.Parse(req.Format, bytes.NewReader(req.Content))
+	if err != nil {
+		appErr := ErrInvalidBankStatement
+		appErr.Cause = err
+
+		return dto.ReconciliationResponse{}, appErr
+	}
+
+	if len(statementLines) == 0 {
+		return dto.ReconciliationResponse{}, ErrEmptyBankStatement
+	}
+
+	reconciliation := model.Reconciliation{
+		FileName:      req.FileName,
+		Format:        string(req.Format),
+		StatementFrom: statementLines[0].BookingDate,
+		StatementTo:   statementLines[0].BookingDate,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	lines := make([]model.BankLine, 0, len(statementLines))
+
+	for _, line := range statementLines {
+		lines = append(lines, model.BankLine{
+			BookingDate: line.BookingDate,
+			Amount:      line.Amount,
+			Reference:   line.Reference,
+			Description: line.Description,
+		})
+
+		if line.BookingDate.Before(reconciliation.StatementFrom) {
+			reconciliation.StatementFrom = line.BookingDate
+		}
+
+		if line.BookingDate.After(reconciliation.StatementTo) {
+			reconciliation.StatementTo = line.BookingDate
+		}
+	}
+
+	// the movements booked by the bank within the tolerance of the statement period are candidates
+	periodEnd := reconciliation.StatementTo.AddDate(0, 0, 1)
+
+	movements, err := s.bankMovementRepository.FindAllBankMovements(ctx, s.fundingAccountID,
+		reconciliation.StatementFrom.Add(-s.dateTolerance), periodEnd.Add(s.dateTolerance))
+	if err != nil {
+		return dto.ReconciliationResponse{}, fmt.Errorf("failed to find bank movements: %w", err)
+	}
+
+	reconciliation.Items = model.Reconcile(lines, movements, reconciliation.StatementFrom, periodEnd,
+		s.dateTolerance)
+
+	err = s.reconciliationRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		return s.reconciliationRepository.CreateTx(ctx, dbTx, &reconciliation)
+	})
+	if err != nil {
+		return dto.ReconciliationResponse{}, fmt.Errorf("failed to create reconciliation: %w", err)
+	}
+
+	return newReconciliationResponse(reconciliation), nil
+}
+
+// GetReconciliation godoc
+// @Summary      Get Reconciliation
+// @Description  Get the matched, unmatched-in-bank and unmatched-in-ledger items of an imported bank statement
+// @Tags         Reconciliation
+// @ID           getReconciliation
+// @Produce      json
+// @Param        id	path		string	true	"Reconciliation ID"
+// @Success      200  {object}  dto.ReconciliationResponse	"Reconciliation"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /reconciliations/{id} [get].
+func (s *ReconciliationService) GetReconciliation(ctx context.Context,
+	req dto.GetReconciliationRequest,
+) (dto.ReconciliationResponse, error) {
+	reconciliation, err := s.reconciliationRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return dto.ReconciliationResponse{}, fmt.Errorf("failed to get reconciliation: %w", err)
+	}
+
+	return newReconciliationResponse(reconciliation), nil
+}
+
+func newReconciliationResponse(reconciliation model.Reconciliation) dto.ReconciliationResponse {
+	resp := dto.ReconciliationResponse{
+		ID:            reconciliation.ID,
+		FileName:      reconciliation.FileName,
+		Format:        reconciliation.Format,
+		StatementFrom: reconciliation.StatementFrom.Format(time.DateOnly),
+		StatementTo:   reconciliation.StatementTo.Format(time.DateOnly),
+		Summary: dto.ReconciliationSummary{
+			Matched:           reconciliation.Count(model.ReconciliationItemMatched),
+			UnmatchedInBank:   reconciliation.Count(model.ReconciliationItemUnmatchedInBank),
+			UnmatchedInLedger: reconciliation.Count(model.ReconciliationItemUnmatchedInLedger),
+		},
+		Items:     make([]dto.ReconciliationItemData, 0, len(reconciliation.Items)),
+		CreatedAt: reconciliation.CreatedAt,
+	}
+
+	for _, item := range reconciliation.Items {
+		data := dto.ReconciliationItemData{
+			Status:    string(item.Status),
+			MatchRule: string(item.MatchRule),
+		}
+
+		if item.BankLine != nil {
+			data.BankLine = &dto.ReconciliationLine{
+				BookingDate: item.BankLine.BookingDate.Format(time.DateOnly),
+				Amount:      item.BankLine.Amount,
+				Reference:   item.BankLine.Reference,
+				Description: item.BankLine.Description,
+			}
+		}
+
+		if item.Movement != nil {
+			data.Movement = &dto.ReconciliationLedger{
+				EventID:       item.Movement.EventID,
+				TransactionID: item.Movement.TransactionID,
+				AccountID:     item.Movement.AccountID,
+				Amount:        item.Movement.Amount,
+				CreatedAt:     item.Movement.CreatedAt,
+			}
+		}
+
+		resp.Items = append(resp.Items, data)
+	}
+
+	return resp
+}
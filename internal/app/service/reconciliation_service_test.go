@@ -0,0 +1,173 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/bankstatement"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const testBankStatement = `date,amount,reference,description
+2026-10-01,250.00,tx-deposit-1,Deposit from ACME
+2026-10-02,-40.00,PAYOUT-77,Payout
+2026-10-03,99.00,tx-unknown,Unknown credit
+2026-10-03,75.00,tx-deposit-3,Deposit amount differs
+`
+
+func TestReconciliationService_ImportReconciliation(t *testing.T) {
+	day := func(value string, hour int) time.Time {
+		date, _ := time.Parse(time.DateOnly, value)
+
+		return date.Add(time.Duration(hour) * time.Hour)
+	}
+	req := dto.ImportReconciliationRequest{
+		FileName: "october.csv",
+		Format:   bankstatement.FormatCSV,
+		Content:  []byte(testBankStatement),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		reconciliationRepository := &reconciliationRepositoryMock{}
+		bankMovementRepository := &bankMovementRepositoryMock{
+			movements: []model.BankMovement{
+				// recorded a day before the bank booked it
+				{EventID: 11, TransactionID: "tx-deposit-1", AccountID: 1, Amount: decimal.NewFromInt(250),
+					CreatedAt: day("2026-09-30", 22)},
+				{EventID: 12, TransactionID: "tx-withdrawal-2", AccountID: 2, Amount: decimal.NewFromInt(-40),
+					CreatedAt: day("2026-10-02", 9)},
+				{EventID: 13, TransactionID: "tx-deposit-3", AccountID: 3, Amount: decimal.NewFromInt(70),
+					CreatedAt: day("2026-10-03", 9)},
+				// before the statement period, expected on the previous statement
+				{EventID: 10, TransactionID: "tx-deposit-0", AccountID: 4, Amount: decimal.NewFromInt(5),
+					CreatedAt: day("2026-09-30", 8)},
+			},
+		}
+		svc := NewReconciliationService(reconciliationRepository, bankMovementRepository, 900001, 48*time.Hour)
+
+		resp, err := svc.ImportReconciliation(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), resp.ID)
+		assert.Equal(t, "2026-10-01", resp.StatementFrom)
+		assert.Equal(t, "2026-10-03", resp.StatementTo)
+		assert.Equal(t, dto.ReconciliationSummary{Matched: 2, UnmatchedInBank: 2, UnmatchedInLedger: 1}, resp.Summary)
+		assert.Len(t, resp.Items, 5)
+		assert.Equal(t, "reference", resp.Items[0].MatchRule)
+		assert.Equal(t, int64(11), resp.Items[0].Movement.EventID)
+		assert.Equal(t, "amount_date", resp.Items[1].MatchRule)
+		assert.Equal(t, int64(12), resp.Items[1].Movement.EventID)
+		assert.Equal(t, "unmatched_in_bank", resp.Items[2].Status)
+		assert.Nil(t, resp.Items[2].Movement)
+		assert.Equal(t, "unmatched_in_bank", resp.Items[3].Status)
+		assert.Equal(t, "unmatched_in_ledger", resp.Items[4].Status)
+		assert.Equal(t, int64(13), resp.Items[4].Movement.EventID)
+		assert.Nil(t, resp.Items[4].BankLine)
+
+		// the candidates cover the statement period widened by the tolerance
+		assert.Equal(t, day("2026-09-29", 0), bankMovementRepository.from)
+		assert.Equal(t, day("2026-10-06", 0), bankMovementRepository.to)
+		assert.Len(t, reconciliationRepository.reconciliations, 1)
+		assert.Equal(t, "october.csv", reconciliationRepository.reconciliations[0].FileName)
+	})
+
+	t.Run("success_outside_tolerance", func(t *testing.T) {
+		bankMovementRepository := &bankMovementRepositoryMock{
+			movements: []model.BankMovement{
+				{EventID: 11, TransactionID: "tx-deposit-1", AccountID: 1, Amount: decimal.NewFromInt(250),
+					CreatedAt: day("2026-10-03", 8)},
+			},
+		}
+		svc := NewReconciliationService(&reconciliationRepositoryMock{}, bankMovementRepository, 900001, 24*time.Hour)
+
+		resp, err := svc.ImportReconciliation(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, dto.ReconciliationSummary{UnmatchedInBank: 4, UnmatchedInLedger: 1}, resp.Summary)
+	})
+
+	t.Run("error_invalid_statement", func(t *testing.T) {
+		svc := NewReconciliationService(&reconciliationRepositoryMock{}, &bankMovementRepositoryMock{}, 900001,
+			48*time.Hour)
+
+		_, err := svc.ImportReconciliation(context.Background(), dto.ImportReconciliationRequest{
+			Format: bankstatement.FormatMT940, Content: []byte("not a statement"),
+		})
+
+		var appErr exception.ApplicationError
+		assert.ErrorAs(t, err, &appErr)
+		assert.Equal(t, ErrInvalidBankStatement.MessageID, appErr.MessageID)
+		assert.ErrorContains(t, err, "unexpected row")
+	})
+
+	t.Run("error_empty_statement", func(t *testing.T) {
+		svc := NewReconciliationService(&reconciliationRepositoryMock{}, &bankMovementRepositoryMock{}, 900001,
+			48*time.Hour)
+
+		_, err := svc.ImportReconciliation(context.Background(), dto.ImportReconciliationRequest{
+			Format: bankstatement.FormatCSV, Content: []byte("date,amount\n"),
+		})
+
+		assert.ErrorIs(t, err, ErrEmptyBankStatement)
+	})
+
+	t.Run("error_find_bank_movements", func(t *testing.T) {
+		svc := NewReconciliationService(&reconciliationRepositoryMock{},
+			&bankMovementRepositoryMock{errFindAll: errors.New("internal db error")}, 900001, 48*time.Hour)
+
+		_, err := svc.ImportReconciliation(context.Background(), req)
+
+		assert.ErrorContains(t, err, "internal db error")
+	})
+
+	t.Run("error_create", func(t *testing.T) {
+		svc := NewReconciliationService(&reconciliationRepositoryMock{errCreateTx: errors.New("internal db error")},
+			&bankMovementRepositoryMock{}, 900001, 48*time.Hour)
+
+		_, err := svc.ImportReconciliation(context.Background(), req)
+
+		assert.ErrorContains(t, err, "internal db error")
+	})
+}
+
+func TestReconciliationService_GetReconciliation(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		bookingDate, _ := time.Parse(time.DateOnly, "2026-10-01")
+		reconciliationRepository := &reconciliationRepositoryMock{
+			reconciliation: model.Reconciliation{
+				ID: 7, FileName: "october.xml", Format: "camt053", StatementFrom: bookingDate, StatementTo: bookingDate,
+				Items: []model.ReconciliationItem{
+					{ID: 1, Status: model.ReconciliationItemUnmatchedInBank, BankLine: &model.BankLine{
+						BookingDate: bookingDate, Amount: decimal.NewFromInt(-10), Reference: "FEE",
+					}},
+				},
+			},
+		}
+		svc := NewReconciliationService(reconciliationRepository, nil, 900001, 48*time.Hour)
+
+		resp, err := svc.GetReconciliation(context.Background(), dto.GetReconciliationRequest{ID: 7})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), resp.ID)
+		assert.Equal(t, dto.ReconciliationSummary{UnmatchedInBank: 1}, resp.Summary)
+		assert.Equal(t, "2026-10-01", resp.Items[0].BankLine.BookingDate)
+		assert.Empty(t, resp.Items[0].MatchRule)
+	})
+
+	t.Run("error_not_found", func(t *testing.T) {
+		svc := NewReconciliationService(&reconciliationRepositoryMock{errFindByID: exception.ErrRecordNotFound},
+			nil, 900001, 48*time.Hour)
+
+		_, err := svc.GetReconciliation(context.Background(), dto.GetReconciliationRequest{ID: 7})
+
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+}
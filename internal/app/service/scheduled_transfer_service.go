@@ -0,0 +1,449 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+)
+
+type ScheduledTransferRepository interface {
+	Create(ctx context.Context, scheduledTransfer *model.ScheduledTransfer) error
+	FindByID(ctx context.Context, id int64) (model.ScheduledTransfer, error)
+	FindAllDue(ctx context.Context, now time.Time, staleBefore time.Time, limit int) ([]model.ScheduledTransfer, error)
+	Claim(ctx context.Context, id int64, now time.Time, staleBefore time.Time) (bool, error)
+	Complete(ctx context.Context, scheduledTransfer *model.ScheduledTransfer) error
+	FindAllAwaitingApproval(ctx context.Context, limit int) ([]model.ScheduledTransfer, error)
+	SettleApproval(ctx context.Context, scheduledTransfer *model.ScheduledTransfer) error
+	Cancel(ctx context.Context, id int64, now time.Time) (bool, error)
+}
+
+// scheduledTransferInitiator initiates the transfers of the scheduled transfers, a transfer held for approval is
+// reviewed by any authenticated client.
+const scheduledTransferInitiator = "scheduled_transfer"
+
+// Transferer executes a transfer between two accounts, it is implemented by TransactionService.
+type Transferer interface {
+	Transfer(ctx context.Context, req dto.CreateTransferRequest) (dto.TransferResponse, error)
+}
+
+// TransferApprovalFinder finds the approval of a transfer held for a second person, it is implemented by
+// TransferApprovalRepository.
+type TransferApprovalFinder interface {
+	FindByTransactionID(ctx context.Context, transactionID string) (model.TransferApproval, error)
+}
+
+// TransactionEventFinder finds the events recorded under a transaction id, it is implemented by EventRepository.
+type TransactionEventFinder interface {
+	FindAllByTransactionID(ctx context.Context, transactionID string) ([]model.Event, error)
+}
+
+// transferStatus returns the status of a transfer made by a worker. A transfer whose idempotency check failed was
+// attempted before, e.g. before a restart, its outcome is read from the events recorded under its transaction id.
+func transferStatus(ctx context.Context, eventFinder TransactionEventFinder, transactionID string,
+	req dto.CreateTransferRequest, resp dto.TransferResponse, transferErr error,
+) (model.TransactionStatus, error) {
+	switch {
+	case transferErr == nil && resp.Status == string(model.TransactionStatusPendingApproval):
+		return model.TransactionStatusPendingApproval, nil
+	case transferErr == nil:
+		return model.TransactionStatusCompleted, nil
+	case !errors.Is(transferErr, ErrIdempotency):
+		return "", transferErr
+	}
+
+	events, err := eventFinder.FindAllByTransactionID(ctx, transactionID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return "", transferErr
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to find events: %w", err)
+	}
+
+	return recordedTransferStatus(transactionID, req, events)
+}
+
+// recordedTransferStatus returns the status of the transfer recorded by the events of its transaction id: a transfer
+// held for approval is settled once it is reviewed, a denied or blocked transfer fails with the error of the denial
+// and a transfer is completed only when its source account was debited. Events of another operation fail the
+// transfer with an idempotency error, the transaction id was used by someone else.
+func recordedTransferStatus(transactionID string, req dto.CreateTransferRequest,
+	events []model.Event,
+) (model.TransactionStatus, error) {
+	transaction, err := model.NewTransaction(transactionID, events)
+	if err != nil {
+		return "", fmt.Errorf("failed to read transaction: %w", err)
+	}
+
+	if transaction.Operation != model.TransactionOperationTransfer || transaction.SourceAccountID == nil ||
+		*transaction.SourceAccountID != req.SourceAccountID {
+		return "", ErrIdempotency
+	}
+
+	switch {
+	case hasEventType(events, model.EventTypeTransferApprovalRequested):
+		return model.TransactionStatusPendingApproval, nil
+	case transaction.Status == model.TransactionStatusRejected &&
+		hasEventType(events, model.EventTypeTransferSanctionsHit):
+		return "", fmt.Errorf("%w: %w", ErrTransferBlocked, errTransferRefused)
+	case transaction.Status == model.TransactionStatusRejected:
+		return "", fmt.Errorf("%w: %w", ErrTransferDenied, errTransferRefused)
+	case hasEventType(events, model.EventTypeDebitBalance):
+		return model.TransactionStatusCompleted, nil
+	default:
+		return "", ErrIdempotency
+	}
+}
+
+// hasEventType reports whether one of the events is of the event type.
+func hasEventType(events []model.Event, eventType model.EventType) bool {
+	return slices.ContainsFunc(events, func(event model.Event) bool {
+		return event.EventType == eventType
+	})
+}
+
+// rejectionReason is why a transfer held for approval was not executed.
+func rejectionReason(transferApproval model.TransferApproval) string {
+	if transferApproval.Status == model.TransferApprovalStatusExpired {
+		return ErrTransferApprovalExpired.Message
+	}
+
+	if transferApproval.RejectionReason != "" {
+		return "transfer was rejected: " + transferApproval.RejectionReason
+	}
+
+	return "transfer was rejected"
+}
+
+type ScheduledTransferService struct {
+	scheduledTransferRepository ScheduledTransferRepository
+	accountRepository           AccountRepository
+	transferer                  Transferer
+	transferApprovalFinder      TransferApprovalFinder
+	eventFinder                 TransactionEventFinder
+	requestTimeThreshold        time.Duration
+	claimTimeout                time.Duration
+	batchSize                   int
+}
+
+func NewScheduledTransferService(scheduledTransferRepository ScheduledTransferRepository,
+	accountRepository AccountRepository, transferer Transferer, transferApprovalFinder TransferApprovalFinder,
+	eventFinder TransactionEventFinder, requestTimeThreshold time.Duration, claimTimeout time.Duration, batchSize int,
+) *ScheduledTransferService {
+	return &ScheduledTransferService{
+		scheduledTransferRepository: scheduledTransferRepository,
+		accountRepository:           accountRepository,
+		transferer:                  transferer,
+		transferApprovalFinder:      transferApprovalFinder,
+		eventFinder:                 eventFinder,
+		requestTimeThreshold:        requestTimeThreshold,
+		claimTimeout:                claimTimeout,
+		batchSize:                   batchSize,
+	}
+}
+
+// CreateScheduledTransfer godoc
+// @Summary      Create Scheduled Transfer
+// @Description  Schedule a transfer between two accounts for a future date
+// @Tags         Transfer
+// @ID           createScheduledTransfer
+// @Produce      json
+// @Param        req body create scheduled transfer	body		dto.CreateScheduledTransferRequest	true	"Scheduled Transfer"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      201  {object}  dto.ScheduledTransferResponse	"Scheduled Transfer"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/scheduled [post].
+func (s *ScheduledTransferService) CreateScheduledTransfer(ctx context.Context,
+	req dto.CreateScheduledTransferRequest,
+) (dto.ScheduledTransferResponse, error) {
+	reqContext, err := getRequestContext(ctx, s.requestTimeThreshold)
+	if err != nil {
+		return dto.ScheduledTransferResponse{}, fmt.Errorf("failed to get request context: %w", err)
+	}
+
+	if req.SourceAccountID == req.DestinationAccountID {
+		return dto.ScheduledTransferResponse{}, ErrSourceAndDestinationAccountSame
+	}
+
+	if !req.ExecuteAt.After(time.Now()) {
+		return dto.ScheduledTransferResponse{}, ErrInvalidExecuteAt
+	}
+
+	// validate source and destination account exist at scheduling time,
+	// balance is only checked when the transfer is executed
+	_, err = s.accountRepository.FindByID(ctx, req.SourceAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return dto.ScheduledTransferResponse{}, fmt.Errorf("failed to find account: %w", ErrSourceAccountNotFound)
+	}
+
+	if err != nil {
+		return dto.ScheduledTransferResponse{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	_, err = s.accountRepository.FindByID(ctx, req.DestinationAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return dto.ScheduledTransferResponse{}, fmt.Errorf("failed to find account: %w", ErrDestinationAccountNotFound)
+	}
+
+	if err != nil {
+		return dto.ScheduledTransferResponse{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	now := time.Now()
+	scheduledTransfer := &model.ScheduledTransfer{
+		TransactionID:        reqContext.TransactionID,
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		ExecuteAt:            req.ExecuteAt,
+		Status:               model.ScheduledTransferStatusPending,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	err = s.scheduledTransferRepository.Create(ctx, scheduledTransfer)
+	if err != nil && errors.Is(err, exception.ErrRecordNotUnique) {
+		return dto.ScheduledTransferResponse{}, ErrIdempotency
+	}
+
+	if err != nil {
+		return dto.ScheduledTransferResponse{}, fmt.Errorf("failed to create scheduled transfer: %w", err)
+	}
+
+	return toScheduledTransferResponse(*scheduledTransfer), nil
+}
+
+// GetScheduledTransfer godoc
+// @Summary      Get Scheduled Transfer
+// @Description  Get a Scheduled Transfer by ID
+// @Tags         Transfer
+// @ID           getScheduledTransfer
+// @Produce      json
+// @Param        id	path		string	true	"Scheduled Transfer ID"
+// @Success      200  {object}  dto.ScheduledTransferResponse	"Scheduled Transfer"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/scheduled/{id} [get].
+func (s *ScheduledTransferService) GetScheduledTransfer(ctx context.Context,
+	req dto.ScheduledTransferIDRequest,
+) (dto.ScheduledTransferResponse, error) {
+	scheduledTransfer, err := s.scheduledTransferRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return dto.ScheduledTransferResponse{}, fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+
+	return toScheduledTransferResponse(scheduledTransfer), nil
+}
+
+// CancelScheduledTransfer godoc
+// @Summary      Cancel Scheduled Transfer
+// @Description  Cancel a pending Scheduled Transfer by ID
+// @Tags         Transfer
+// @ID           cancelScheduledTransfer
+// @Produce      json
+// @Param        id	path		string	true	"Scheduled Transfer ID"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/scheduled/{id} [delete].
+func (s *ScheduledTransferService) CancelScheduledTransfer(ctx context.Context,
+	req dto.ScheduledTransferIDRequest,
+) error {
+	cancelled, err := s.scheduledTransferRepository.Cancel(ctx, req.ID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to cancel scheduled transfer: %w", err)
+	}
+
+	if cancelled {
+		return nil
+	}
+
+	// nothing was cancelled, either the instruction does not exist or it is not pending anymore
+	_, err = s.scheduledTransferRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get scheduled transfer: %w", err)
+	}
+
+	return ErrScheduledTransferNotPending
+}
+
+// ExecuteDue settles the scheduled transfers whose transfer was reviewed and executes those whose execution time has
+// passed. It returns the number of instructions that reached a final state.
+func (s *ScheduledTransferService) ExecuteDue(ctx context.Context) (int, error) {
+	executed, err := s.settleApprovals(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	staleBefore := now.Add(-s.claimTimeout)
+
+	scheduledTransfers, err := s.scheduledTransferRepository.FindAllDue(ctx, now, staleBefore, s.batchSize)
+	if err != nil {
+		return executed, fmt.Errorf("failed to find due scheduled transfers: %w", err)
+	}
+
+	for _, scheduledTransfer := range scheduledTransfers {
+		done, err := s.execute(ctx, scheduledTransfer, staleBefore)
+		if err != nil {
+			// keep going, the instruction stays claimed and is retried after the claim timeout
+			slog.ErrorContext(ctx, "failed to execute scheduled transfer",
+				slog.Int64("scheduled_transfer_id", scheduledTransfer.ID),
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if done {
+			executed++
+		}
+	}
+
+	return executed, nil
+}
+
+func (s *ScheduledTransferService) execute(ctx context.Context,
+	scheduledTransfer model.ScheduledTransfer, staleBefore time.Time,
+) (bool, error) {
+	claimed, err := s.scheduledTransferRepository.Claim(ctx, scheduledTransfer.ID, time.Now(), staleBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim scheduled transfer: %w", err)
+	}
+
+	if !claimed {
+		return false, nil
+	}
+
+	// the derived transaction id makes a re-execution after a restart fail the idempotency check
+	transferCtx := dto.ContextWithRequestContext(ctx, dto.RequestContext{
+		TransactionID: scheduledTransfer.ExecutionTransactionID(),
+		Timestamp:     time.Now(),
+	})
+
+	transferReq := dto.CreateTransferRequest{
+		SourceAccountID:      scheduledTransfer.SourceAccountID,
+		DestinationAccountID: scheduledTransfer.DestinationAccountID,
+		Amount:               scheduledTransfer.Amount,
+		InitiatedBy:          scheduledTransferInitiator,
+	}
+
+	resp, err := s.transferer.Transfer(transferCtx, transferReq)
+
+	status, err := transferStatus(ctx, s.eventFinder, scheduledTransfer.ExecutionTransactionID(), transferReq, resp,
+		err)
+
+	now := time.Now()
+
+	var appErr exception.ApplicationError
+
+	switch {
+	case err == nil && status == model.TransactionStatusPendingApproval:
+		// no money moved yet, the instruction is settled once the transfer is reviewed
+		scheduledTransfer.Status = model.ScheduledTransferStatusAwaitingApproval
+	case err == nil:
+		scheduledTransfer.Status = model.ScheduledTransferStatusExecuted
+		scheduledTransfer.ExecutedAt = &now
+	case errors.As(err, &appErr):
+		scheduledTransfer.Status = model.ScheduledTransferStatusFailed
+		scheduledTransfer.FailureReason = appErr.Message
+	default:
+		return false, fmt.Errorf("failed to transfer: %w", err)
+	}
+
+	scheduledTransfer.UpdatedAt = now
+
+	if err := s.scheduledTransferRepository.Complete(ctx, &scheduledTransfer); err != nil {
+		return false, fmt.Errorf("failed to complete scheduled transfer: %w", err)
+	}
+
+	return scheduledTransfer.Status != model.ScheduledTransferStatusAwaitingApproval, nil
+}
+
+// settleApprovals executes or fails the instructions awaiting approval whose transfer was approved, rejected or
+// expired. It returns the number of instructions that reached a final state.
+func (s *ScheduledTransferService) settleApprovals(ctx context.Context) (int, error) {
+	scheduledTransfers, err := s.scheduledTransferRepository.FindAllAwaitingApproval(ctx, s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find scheduled transfers awaiting approval: %w", err)
+	}
+
+	settled := 0
+
+	for _, scheduledTransfer := range scheduledTransfers {
+		done, err := s.settleApproval(ctx, scheduledTransfer)
+		if err != nil {
+			// keep going, the instruction stays awaiting approval and is settled on the next run
+			slog.ErrorContext(ctx, "failed to settle scheduled transfer",
+				slog.Int64("scheduled_transfer_id", scheduledTransfer.ID),
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if done {
+			settled++
+		}
+	}
+
+	return settled, nil
+}
+
+func (s *ScheduledTransferService) settleApproval(ctx context.Context,
+	scheduledTransfer model.ScheduledTransfer,
+) (bool, error) {
+	transferApproval, err := s.transferApprovalFinder.FindByTransactionID(ctx,
+		scheduledTransfer.ExecutionTransactionID())
+	if err != nil {
+		return false, fmt.Errorf("failed to find transfer approval: %w", err)
+	}
+
+	now := time.Now()
+
+	switch transferApproval.Status {
+	case model.TransferApprovalStatusApproved:
+		scheduledTransfer.Status = model.ScheduledTransferStatusExecuted
+		scheduledTransfer.ExecutedAt = transferApproval.ReviewedAt
+	case model.TransferApprovalStatusRejected, model.TransferApprovalStatusExpired:
+		scheduledTransfer.Status = model.ScheduledTransferStatusFailed
+		scheduledTransfer.FailureReason = rejectionReason(transferApproval)
+	default:
+		return false, nil
+	}
+
+	scheduledTransfer.UpdatedAt = now
+
+	if err := s.scheduledTransferRepository.SettleApproval(ctx, &scheduledTransfer); err != nil {
+		return false, fmt.Errorf("failed to settle scheduled transfer: %w", err)
+	}
+
+	return true, nil
+}
+
+func toScheduledTransferResponse(scheduledTransfer model.ScheduledTransfer) dto.ScheduledTransferResponse {
+	return dto.ScheduledTransferResponse{
+		ID:                     scheduledTransfer.ID,
+		SourceAccountID:        scheduledTransfer.SourceAccountID,
+		DestinationAccountID:   scheduledTransfer.DestinationAccountID,
+		Amount:                 scheduledTransfer.Amount,
+		ExecuteAt:              scheduledTransfer.ExecuteAt,
+		Status:                 string(scheduledTransfer.Status),
+		ExecutionTransactionID: scheduledTransfer.ExecutionTransactionID(),
+		FailureReason:          scheduledTransfer.FailureReason,
+		ExecutedAt:             scheduledTransfer.ExecutedAt,
+		CreatedAt:              scheduledTransfer.CreatedAt,
+	}
+}
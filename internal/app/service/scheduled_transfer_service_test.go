@@ -0,0 +1,415 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledTransferService_CreateScheduledTransfer(t *testing.T) {
+
+	testCreate := func(
+		req dto.CreateScheduledTransferRequest,
+		svc *ScheduledTransferService,
+		ctx context.Context,
+		wantErr error,
+	) func(t *testing.T) {
+		return func(t *testing.T) {
+			got, err := svc.CreateScheduledTransfer(ctx, req)
+
+			if wantErr != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "pending", got.Status)
+				assert.Equal(t, "scheduled-transfer-1", got.ExecutionTransactionID)
+			}
+		}
+	}
+
+	validReq := dto.CreateScheduledTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		ExecuteAt:            time.Now().Add(24 * time.Hour),
+	}
+
+	// error request context
+	t.Run("error_request_context", testCreate(validReq, &ScheduledTransferService{
+		accountRepository:           &accountRepositoryMock{},
+		scheduledTransferRepository: &scheduledTransferRepositoryMock{},
+	}, context.Background(), fmt.Errorf("request context not found")))
+
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	// error source and destination account same
+	t.Run("error_source_and_destination_same", testCreate(dto.CreateScheduledTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 1,
+		Amount:               decimal.NewFromInt(100),
+		ExecuteAt:            time.Now().Add(24 * time.Hour),
+	}, &ScheduledTransferService{
+		requestTimeThreshold:        30 * time.Second,
+		accountRepository:           &accountRepositoryMock{},
+		scheduledTransferRepository: &scheduledTransferRepositoryMock{},
+	}, ctx, ErrSourceAndDestinationAccountSame))
+
+	// error execute at in the past
+	t.Run("error_execute_at_in_past", testCreate(dto.CreateScheduledTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		ExecuteAt:            time.Now().Add(-time.Minute),
+	}, &ScheduledTransferService{
+		requestTimeThreshold:        30 * time.Second,
+		accountRepository:           &accountRepositoryMock{},
+		scheduledTransferRepository: &scheduledTransferRepositoryMock{},
+	}, ctx, ErrInvalidExecuteAt))
+
+	// error source account not found
+	t.Run("error_source_account_not_found", testCreate(validReq, &ScheduledTransferService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{exception.ErrRecordNotFound},
+		},
+		scheduledTransferRepository: &scheduledTransferRepositoryMock{},
+	}, ctx, ErrSourceAccountNotFound))
+
+	// error destination account not found
+	t.Run("error_destination_account_not_found", testCreate(validReq, &ScheduledTransferService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil, exception.ErrRecordNotFound},
+		},
+		scheduledTransferRepository: &scheduledTransferRepositoryMock{},
+	}, ctx, ErrDestinationAccountNotFound))
+
+	// error idempotency - transaction id already used to schedule a transfer
+	t.Run("error_idempotency", testCreate(validReq, &ScheduledTransferService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+		},
+		scheduledTransferRepository: &scheduledTransferRepositoryMock{
+			errCreate: []error{fmt.Errorf("failed to exec statement: %w", exception.ErrRecordNotUnique)},
+		},
+	}, ctx, ErrIdempotency))
+
+	// error create
+	t.Run("error_create", testCreate(validReq, &ScheduledTransferService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+		},
+		scheduledTransferRepository: &scheduledTransferRepositoryMock{
+			errCreate: []error{errors.New("internal db error")},
+		},
+	}, ctx, errors.New("internal db error")))
+
+	// success
+	t.Run("success", testCreate(validReq, &ScheduledTransferService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+		},
+		scheduledTransferRepository: &scheduledTransferRepositoryMock{
+			errCreate: []error{nil},
+		},
+	}, ctx, nil))
+}
+
+func TestScheduledTransferService_GetScheduledTransfer(t *testing.T) {
+	t.Run("error_not_found", func(t *testing.T) {
+		svc := &ScheduledTransferService{
+			scheduledTransferRepository: &scheduledTransferRepositoryMock{
+				errFindByID: []error{exception.ErrRecordNotFound},
+			},
+		}
+
+		_, err := svc.GetScheduledTransfer(context.Background(), dto.ScheduledTransferIDRequest{ID: 1})
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		svc := &ScheduledTransferService{
+			scheduledTransferRepository: &scheduledTransferRepositoryMock{
+				errFindByID: []error{nil},
+				scheduledTransfer: model.ScheduledTransfer{
+					ID:     7,
+					Status: model.ScheduledTransferStatusExecuted,
+				},
+			},
+		}
+
+		got, err := svc.GetScheduledTransfer(context.Background(), dto.ScheduledTransferIDRequest{ID: 7})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), got.ID)
+		assert.Equal(t, "executed", got.Status)
+		assert.Equal(t, "scheduled-transfer-7", got.ExecutionTransactionID)
+	})
+}
+
+func TestScheduledTransferService_CancelScheduledTransfer(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svc := &ScheduledTransferService{
+			scheduledTransferRepository: &scheduledTransferRepositoryMock{
+				errCancel: []error{nil},
+				cancelled: []bool{true},
+			},
+		}
+
+		err := svc.CancelScheduledTransfer(context.Background(), dto.ScheduledTransferIDRequest{ID: 1})
+		assert.NoError(t, err)
+	})
+
+	t.Run("error_not_pending", func(t *testing.T) {
+		svc := &ScheduledTransferService{
+			scheduledTransferRepository: &scheduledTransferRepositoryMock{
+				errCancel:   []error{nil},
+				cancelled:   []bool{false},
+				errFindByID: []error{nil},
+			},
+		}
+
+		err := svc.CancelScheduledTransfer(context.Background(), dto.ScheduledTransferIDRequest{ID: 1})
+		assert.ErrorIs(t, err, ErrScheduledTransferNotPending)
+	})
+
+	t.Run("error_not_found", func(t *testing.T) {
+		svc := &ScheduledTransferService{
+			scheduledTransferRepository: &scheduledTransferRepositoryMock{
+				errCancel:   []error{nil},
+				cancelled:   []bool{false},
+				errFindByID: []error{exception.ErrRecordNotFound},
+			},
+		}
+
+		err := svc.CancelScheduledTransfer(context.Background(), dto.ScheduledTransferIDRequest{ID: 1})
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+}
+
+func TestScheduledTransferService_ExecuteDue(t *testing.T) {
+	due := []model.ScheduledTransfer{
+		{
+			ID:                   1,
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(100),
+			Status:               model.ScheduledTransferStatusPending,
+		},
+	}
+
+	t.Run("error_find_due", func(t *testing.T) {
+		svc := &ScheduledTransferService{
+			scheduledTransferRepository: &scheduledTransferRepositoryMock{
+				errFindAllDue: []error{errors.New("internal db error")},
+			},
+		}
+
+		_, err := svc.ExecuteDue(context.Background())
+		assert.ErrorContains(t, err, "internal db error")
+	})
+
+	t.Run("skip_not_claimed", func(t *testing.T) {
+		transferer := &transfererMock{}
+		svc := &ScheduledTransferService{
+			transferer: transferer,
+			scheduledTransferRepository: &scheduledTransferRepositoryMock{
+				errFindAllDue:      []error{nil},
+				scheduledTransfers: due,
+				errClaim:           []error{nil},
+				claimed:            []bool{false},
+			},
+		}
+
+		executed, err := svc.ExecuteDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, executed)
+		assert.Equal(t, 0, transferer.transferCallCount)
+	})
+
+	testExecute := func(transferErr error, events []model.Event, wantStatus model.ScheduledTransferStatus,
+		wantExecuted int,
+	) func(t *testing.T) {
+		return func(t *testing.T) {
+			transferer := &transfererMock{errTransfer: []error{transferErr}}
+			repo := &scheduledTransferRepositoryMock{
+				errFindAllDue:      []error{nil},
+				scheduledTransfers: due,
+				errClaim:           []error{nil},
+				claimed:            []bool{true},
+				errComplete:        []error{nil},
+			}
+			svc := &ScheduledTransferService{
+				transferer:                  transferer,
+				scheduledTransferRepository: repo,
+				eventFinder: &eventRepositoryMock{
+					errFindAllByTransactionID: []error{nil},
+					events:                    events,
+				},
+			}
+
+			executed, err := svc.ExecuteDue(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, wantExecuted, executed)
+			assert.Equal(t, []string{"scheduled-transfer-1"}, transferer.transactionIDs)
+			assert.Equal(t, []string{scheduledTransferInitiator}, transferer.initiators)
+
+			if wantExecuted == 0 {
+				assert.Equal(t, 0, repo.completeCallCount)
+
+				return
+			}
+
+			assert.Equal(t, wantStatus, repo.completed[0].Status)
+			assert.Equal(t, wantStatus == model.ScheduledTransferStatusExecuted, repo.completed[0].ExecutedAt != nil)
+		}
+	}
+
+	idempotencyErr := fmt.Errorf("transaction service: %w", ErrIdempotency)
+
+	t.Run("success_executed", testExecute(nil, nil, model.ScheduledTransferStatusExecuted, 1))
+	t.Run("success_already_executed", testExecute(idempotencyErr, []model.Event{
+		{
+			AggregateID:   1,
+			AggregateType: model.AggregateTypeAccount,
+			EventType:     model.EventTypeDebitBalance,
+			EventData:     []byte(`{"amount":"100"}`),
+		},
+		{
+			AggregateID:   2,
+			AggregateType: model.AggregateTypeAccount,
+			EventType:     model.EventTypeCreditBalance,
+			EventData:     []byte(`{"amount":"100"}`),
+		},
+	}, model.ScheduledTransferStatusExecuted, 1))
+	t.Run("failed_already_denied", testExecute(idempotencyErr, []model.Event{{
+		AggregateType: model.AggregateTypeRiskDecision,
+		EventType:     model.EventTypeTransferRiskAssessed,
+		EventData:     []byte(`{"source_account_id":1,"destination_account_id":2,"amount":"100","action":"deny"}`),
+	}}, model.ScheduledTransferStatusFailed, 1))
+	t.Run("failed_already_blocked", testExecute(idempotencyErr, []model.Event{{
+		AggregateType: model.AggregateTypeSanctionsScreening,
+		EventType:     model.EventTypeTransferSanctionsHit,
+		EventData:     []byte(`{"source_account_id":1,"destination_account_id":2,"amount":"100","action":"block"}`),
+	}}, model.ScheduledTransferStatusFailed, 1))
+	t.Run("failed_transaction_id_used_by_another_operation", testExecute(idempotencyErr, []model.Event{{
+		AggregateID:   7,
+		AggregateType: model.AggregateTypeAccount,
+		EventType:     model.EventTypeInitBalance,
+		EventData:     []byte(`{"amount":"100"}`),
+	}}, model.ScheduledTransferStatusFailed, 1))
+	t.Run("failed_insufficient_balance", testExecute(
+		fmt.Errorf("failed to process transfer: %w", ErrInsufficientBalance), nil, model.ScheduledTransferStatusFailed,
+		1))
+	t.Run("retry_on_internal_error", testExecute(errors.New("internal db error"), nil, "", 0))
+
+	testAwaitingApproval := func(resp dto.TransferResponse, transferErr error) func(t *testing.T) {
+		return func(t *testing.T) {
+			repo := &scheduledTransferRepositoryMock{
+				errFindAllDue:      []error{nil},
+				scheduledTransfers: due,
+				errClaim:           []error{nil},
+				claimed:            []bool{true},
+				errComplete:        []error{nil},
+			}
+			svc := &ScheduledTransferService{
+				transferer: &transfererMock{
+					errTransfer: []error{transferErr},
+					responses:   []dto.TransferResponse{resp},
+				},
+				scheduledTransferRepository: repo,
+				eventFinder: &eventRepositoryMock{
+					errFindAllByTransactionID: []error{nil},
+					events: []model.Event{{
+						AggregateType: model.AggregateTypeTransfer,
+						EventType:     model.EventTypeTransferApprovalRequested,
+						EventData:     []byte(`{"source_account_id":1,"destination_account_id":2,"amount":"100"}`),
+					}},
+				},
+			}
+
+			executed, err := svc.ExecuteDue(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 0, executed)
+			assert.Equal(t, model.ScheduledTransferStatusAwaitingApproval, repo.completed[0].Status)
+			assert.Nil(t, repo.completed[0].ExecutedAt)
+		}
+	}
+
+	t.Run("awaiting_approval", testAwaitingApproval(
+		dto.TransferResponse{Status: string(model.TransactionStatusPendingApproval)}, nil))
+	t.Run("awaiting_approval_already_requested", testAwaitingApproval(
+		dto.TransferResponse{}, fmt.Errorf("transaction service: %w", ErrIdempotency)))
+}
+
+func TestScheduledTransferService_ExecuteDueSettleApproval(t *testing.T) {
+	reviewedAt := time.Now()
+	awaitingApproval := []model.ScheduledTransfer{
+		{
+			ID:                   1,
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(100),
+			Status:               model.ScheduledTransferStatusAwaitingApproval,
+		},
+	}
+
+	testSettle := func(transferApproval model.TransferApproval, wantStatus model.ScheduledTransferStatus,
+		wantReason string,
+	) func(t *testing.T) {
+		return func(t *testing.T) {
+			repo := &scheduledTransferRepositoryMock{
+				awaitingApproval: awaitingApproval,
+				errFindAllDue:    []error{nil},
+			}
+			svc := &ScheduledTransferService{
+				transferer:                  &transfererMock{},
+				scheduledTransferRepository: repo,
+				transferApprovalFinder:      &transferApprovalRepositoryMock{transferApproval: transferApproval},
+			}
+
+			executed, err := svc.ExecuteDue(context.Background())
+			assert.NoError(t, err)
+
+			if wantStatus == "" {
+				assert.Equal(t, 0, executed)
+				assert.Empty(t, repo.settled)
+
+				return
+			}
+
+			assert.Equal(t, 1, executed)
+			assert.Equal(t, wantStatus, repo.settled[0].Status)
+			assert.Equal(t, wantReason, repo.settled[0].FailureReason)
+		}
+	}
+
+	t.Run("still_pending", testSettle(
+		model.TransferApproval{Status: model.TransferApprovalStatusPending}, "", ""))
+	t.Run("approved", testSettle(
+		model.TransferApproval{Status: model.TransferApprovalStatusApproved, ReviewedAt: &reviewedAt},
+		model.ScheduledTransferStatusExecuted, ""))
+	t.Run("rejected", testSettle(
+		model.TransferApproval{Status: model.TransferApprovalStatusRejected, RejectionReason: "unknown payee"},
+		model.ScheduledTransferStatusFailed, "transfer was rejected: unknown payee"))
+	t.Run("expired", testSettle(
+		model.TransferApproval{Status: model.TransferApprovalStatusExpired},
+		model.ScheduledTransferStatusFailed, ErrTransferApprovalExpired.Message))
+}
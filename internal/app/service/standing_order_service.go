@@ -0,0 +1,598 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/recurrence"
+)
+
+type StandingOrderRepository interface {
+	WithTransaction(ctx context.Context, txFunc func(context.Context, *sql.Tx) error) error
+	CreateTx(ctx context.Context, tx *sql.Tx, standingOrder *model.StandingOrder) error
+	UpdateTx(ctx context.Context, tx *sql.Tx, standingOrder *model.StandingOrder) error
+	FindByID(ctx context.Context, id int64) (model.StandingOrder, error)
+	FindByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64) (model.StandingOrder, error)
+	FindDueByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64, now time.Time) (model.StandingOrder, error)
+	FindAllDue(ctx context.Context, now time.Time, limit int) ([]model.StandingOrder, error)
+	FindAllAwaitingApproval(ctx context.Context, limit int) ([]model.StandingOrder, error)
+}
+
+// standingOrderInitiator initiates the transfers of the standing orders, a transfer held for approval is reviewed by
+// any authenticated client.
+const standingOrderInitiator = "standing_order"
+
+// RetryPolicy controls how a skipped occurrence is retried before moving on to the next occurrence.
+type RetryPolicy struct {
+	MaxRetries int
+	Interval   time.Duration
+}
+
+type StandingOrderService struct {
+	standingOrderRepository StandingOrderRepository
+	accountRepository       AccountRepository
+	eventRepository         EventRepository
+	transferer              Transferer
+	transferApprovalFinder  TransferApprovalFinder
+	retryPolicy             RetryPolicy
+	requestTimeThreshold    time.Duration
+	eventVersion            string
+	batchSize               int
+}
+
+func NewStandingOrderService(standingOrderRepository StandingOrderRepository,
+	accountRepository AccountRepository, eventRepository EventRepository, transferer Transferer,
+	transferApprovalFinder TransferApprovalFinder, retryPolicy RetryPolicy, requestTimeThreshold time.Duration,
+	eventVersion string, batchSize int,
+) *StandingOrderService {
+	return &StandingOrderService{
+		standingOrderRepository: standingOrderRepository,
+		accountRepository:       accountRepository,
+		eventRepository:         eventRepository,
+		transferer:              transferer,
+		transferApprovalFinder:  transferApprovalFinder,
+		retryPolicy:             retryPolicy,
+		requestTimeThreshold:    requestTimeThreshold,
+		eventVersion:            eventVersion,
+		batchSize:               batchSize,
+	}
+}
+
+// CreateStandingOrder godoc
+// @Summary      Create Standing Order
+// @Description  Create a recurring transfer between two accounts
+// @Tags         Transfer
+// @ID           createStandingOrder
+// @Produce      json
+// @Param        req body create standing order	body		dto.CreateStandingOrderRequest	true	"Standing Order"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      201  {object}  dto.StandingOrderResponse	"Standing Order"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/standing-orders [post].
+func (s *StandingOrderService) CreateStandingOrder(ctx context.Context,
+	req dto.CreateStandingOrderRequest,
+) (dto.StandingOrderResponse, error) {
+	reqContext, err := getRequestContext(ctx, s.requestTimeThreshold)
+	if err != nil {
+		return dto.StandingOrderResponse{}, fmt.Errorf("failed to get request context: %w", err)
+	}
+
+	standingOrder, err := s.newStandingOrder(req, reqContext.TransactionID)
+	if err != nil {
+		return dto.StandingOrderResponse{}, err
+	}
+
+	_, err = s.accountRepository.FindByID(ctx, req.SourceAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return dto.StandingOrderResponse{}, fmt.Errorf("failed to find account: %w", ErrSourceAccountNotFound)
+	}
+
+	if err != nil {
+		return dto.StandingOrderResponse{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	_, err = s.accountRepository.FindByID(ctx, req.DestinationAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return dto.StandingOrderResponse{}, fmt.Errorf("failed to find account: %w", ErrDestinationAccountNotFound)
+	}
+
+	if err != nil {
+		return dto.StandingOrderResponse{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	err = s.standingOrderRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := s.standingOrderRepository.CreateTx(ctx, dbTx, &standingOrder); err != nil {
+			return fmt.Errorf("failed to create standing order: %w", err)
+		}
+
+		eventCollector, err := NewStandingOrderEventCollector(ctx, s.eventRepository,
+			standingOrder.ID, reqContext.TransactionID, s.eventVersion)
+		if err != nil {
+			return fmt.Errorf("failed to create standing order event collector: %w", err)
+		}
+
+		eventCollector.OnCreatedEvent(standingOrder)
+
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil && errors.Is(err, exception.ErrRecordNotUnique) {
+		return dto.StandingOrderResponse{}, ErrIdempotency
+	}
+
+	if err != nil {
+		return dto.StandingOrderResponse{}, fmt.Errorf("failed to create standing order: %w", err)
+	}
+
+	return toStandingOrderResponse(standingOrder), nil
+}
+
+// GetStandingOrder godoc
+// @Summary      Get Standing Order
+// @Description  Get a Standing Order by ID
+// @Tags         Transfer
+// @ID           getStandingOrder
+// @Produce      json
+// @Param        id	path		string	true	"Standing Order ID"
+// @Success      200  {object}  dto.StandingOrderResponse	"Standing Order"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/standing-orders/{id} [get].
+func (s *StandingOrderService) GetStandingOrder(ctx context.Context,
+	req dto.StandingOrderIDRequest,
+) (dto.StandingOrderResponse, error) {
+	standingOrder, err := s.standingOrderRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return dto.StandingOrderResponse{}, fmt.Errorf("failed to get standing order: %w", err)
+	}
+
+	return toStandingOrderResponse(standingOrder), nil
+}
+
+// CancelStandingOrder godoc
+// @Summary      Cancel Standing Order
+// @Description  Cancel an active Standing Order by ID
+// @Tags         Transfer
+// @ID           cancelStandingOrder
+// @Produce      json
+// @Param        id	path		string	true	"Standing Order ID"
+// @Param        x-timestamp	header		string	true	"Request timestamp"
+// @Param        x-transaction-id	header		string	true	"Transaction ID"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/standing-orders/{id} [delete].
+func (s *StandingOrderService) CancelStandingOrder(ctx context.Context, req dto.StandingOrderIDRequest) error {
+	reqContext, err := getRequestContext(ctx, s.requestTimeThreshold)
+	if err != nil {
+		return fmt.Errorf("failed to get request context: %w", err)
+	}
+
+	err = s.standingOrderRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		// lock the order so the worker cannot execute an occurrence while it is being cancelled
+		standingOrder, err := s.standingOrderRepository.FindByIDForUpdateTx(ctx, dbTx, req.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find standing order: %w", err)
+		}
+
+		if standingOrder.Status != model.StandingOrderStatusActive {
+			return ErrStandingOrderNotActive
+		}
+
+		eventCollector, err := NewStandingOrderEventCollector(ctx, s.eventRepository,
+			standingOrder.ID, reqContext.TransactionID, s.eventVersion)
+		if err != nil {
+			return fmt.Errorf("failed to create standing order event collector: %w", err)
+		}
+
+		eventCollector.OnCancelledEvent(standingOrder)
+
+		standingOrder.Status = model.StandingOrderStatusCancelled
+		standingOrder.UpdatedAt = time.Now()
+
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		if err := s.standingOrderRepository.UpdateTx(ctx, dbTx, &standingOrder); err != nil {
+			return fmt.Errorf("failed to update standing order: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel standing order: %w", err)
+	}
+
+	return nil
+}
+
+// ExecuteDue settles the occurrences whose transfer was reviewed and materializes the due occurrence of every active
+// standing order as a transfer. It returns the number of standing orders that were processed.
+func (s *StandingOrderService) ExecuteDue(ctx context.Context) (int, error) {
+	processed, err := s.settleApprovals(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	standingOrders, err := s.standingOrderRepository.FindAllDue(ctx, time.Now(), s.batchSize)
+	if err != nil {
+		return processed, fmt.Errorf("failed to find due standing orders: %w", err)
+	}
+
+	for _, standingOrder := range standingOrders {
+		done, err := s.executeOccurrence(ctx, standingOrder.ID)
+		if err != nil {
+			// keep going, the occurrence is still due and is retried on the next run
+			slog.ErrorContext(ctx, "failed to execute standing order",
+				slog.Int64("standing_order_id", standingOrder.ID),
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if done {
+			processed++
+		}
+	}
+
+	return processed, nil
+}
+
+func (s *StandingOrderService) executeOccurrence(ctx context.Context, id int64) (bool, error) {
+	done := false
+
+	err := s.standingOrderRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		now := time.Now()
+
+		// the row lock is held while transferring, so a cancellation or another worker has to wait
+		standingOrder, err := s.standingOrderRepository.FindDueByIDForUpdateTx(ctx, dbTx, id, now)
+		if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to find standing order: %w", err)
+		}
+
+		rule, err := recurrence.Parse(standingOrder.Recurrence)
+		if err != nil {
+			return fmt.Errorf("failed to parse recurrence: %w", err)
+		}
+
+		transferCtx := dto.ContextWithRequestContext(ctx, dto.RequestContext{
+			TransactionID: standingOrder.OccurrenceTransactionID(),
+			Timestamp:     now,
+		})
+
+		transferReq := dto.CreateTransferRequest{
+			SourceAccountID:      standingOrder.SourceAccountID,
+			DestinationAccountID: standingOrder.DestinationAccountID,
+			Amount:               standingOrder.Amount,
+			InitiatedBy:          standingOrderInitiator,
+		}
+
+		resp, transferErr := s.transferer.Transfer(transferCtx, transferReq)
+
+		status, transferErr := transferStatus(ctx, s.eventRepository, standingOrder.OccurrenceTransactionID(),
+			transferReq, resp, transferErr)
+
+		eventCollector, err := s.applyOutcome(ctx, &standingOrder, rule, status, transferErr, now)
+		if err != nil {
+			return err
+		}
+
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		if err := s.standingOrderRepository.UpdateTx(ctx, dbTx, &standingOrder); err != nil {
+			return fmt.Errorf("failed to update standing order: %w", err)
+		}
+
+		done = true
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to execute occurrence: %w", err)
+	}
+
+	return done, nil
+}
+
+// applyOutcome records the result of an occurrence transfer on the standing order and its events.
+func (s *StandingOrderService) applyOutcome(ctx context.Context, standingOrder *model.StandingOrder,
+	rule recurrence.Rule, status model.TransactionStatus, transferErr error, now time.Time,
+) (*StandingOrderEventCollector, error) {
+	var appErr exception.ApplicationError
+
+	switch {
+	case transferErr == nil && status == model.TransactionStatusPendingApproval:
+		eventCollector, err := NewStandingOrderEventCollector(ctx, s.eventRepository,
+			standingOrder.ID, standingOrder.OccurrenceTransactionID(), s.eventVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create standing order event collector: %w", err)
+		}
+
+		// no money moved yet, the occurrence is settled once the transfer is reviewed
+		eventCollector.OnAwaitingApprovalEvent(*standingOrder)
+		standingOrder.Status = model.StandingOrderStatusAwaitingApproval
+		standingOrder.UpdatedAt = now
+
+		return eventCollector, nil
+	case transferErr == nil:
+		eventCollector, err := NewStandingOrderEventCollector(ctx, s.eventRepository,
+			standingOrder.ID, standingOrder.OccurrenceTransactionID(), s.eventVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create standing order event collector: %w", err)
+		}
+
+		eventCollector.OnExecutedEvent(*standingOrder)
+		standingOrder.ExecutedCount++
+		s.advance(standingOrder, rule, eventCollector)
+
+		standingOrder.UpdatedAt = now
+
+		return eventCollector, nil
+	case errors.As(transferErr, &appErr):
+		eventCollector, err := NewStandingOrderEventCollector(ctx, s.eventRepository,
+			standingOrder.ID, standingOrder.AttemptTransactionID(), s.eventVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create standing order event collector: %w", err)
+		}
+
+		// retry the occurrence unless the retries are exhausted or the next occurrence is already due, a denied or
+		// blocked transfer is recorded under the occurrence transaction id and is final
+		retryAt := now.Add(s.retryPolicy.Interval)
+		nextOccurrenceAt := rule.Next(standingOrder.StartAt, standingOrder.OccurrenceAt)
+		willRetry := !errors.Is(transferErr, errTransferRefused) &&
+			standingOrder.Attempt < s.retryPolicy.MaxRetries &&
+			(nextOccurrenceAt.IsZero() || retryAt.Before(nextOccurrenceAt))
+
+		if willRetry {
+			eventCollector.OnSkippedEvent(*standingOrder, appErr.Message, &retryAt)
+			standingOrder.Attempt++
+			standingOrder.NextRunAt = retryAt
+		} else {
+			eventCollector.OnSkippedEvent(*standingOrder, appErr.Message, nil)
+			s.advance(standingOrder, rule, eventCollector)
+		}
+
+		standingOrder.UpdatedAt = now
+
+		return eventCollector, nil
+	default:
+		return nil, fmt.Errorf("failed to transfer: %w", transferErr)
+	}
+}
+
+// settleApprovals moves on the standing orders whose occurrence transfer was approved, rejected or expired. It
+// returns the number of standing orders that were settled.
+func (s *StandingOrderService) settleApprovals(ctx context.Context) (int, error) {
+	standingOrders, err := s.standingOrderRepository.FindAllAwaitingApproval(ctx, s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find standing orders awaiting approval: %w", err)
+	}
+
+	settled := 0
+
+	for _, standingOrder := range standingOrders {
+		done, err := s.settleApproval(ctx, standingOrder.ID)
+		if err != nil {
+			// keep going, the occurrence stays awaiting approval and is settled on the next run
+			slog.ErrorContext(ctx, "failed to settle standing order",
+				slog.Int64("standing_order_id", standingOrder.ID),
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if done {
+			settled++
+		}
+	}
+
+	return settled, nil
+}
+
+func (s *StandingOrderService) settleApproval(ctx context.Context, id int64) (bool, error) {
+	done := false
+
+	err := s.standingOrderRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		standingOrder, err := s.standingOrderRepository.FindByIDForUpdateTx(ctx, dbTx, id)
+		if err != nil {
+			return fmt.Errorf("failed to find standing order: %w", err)
+		}
+
+		if standingOrder.Status != model.StandingOrderStatusAwaitingApproval {
+			return nil
+		}
+
+		transferApproval, err := s.transferApprovalFinder.FindByTransactionID(ctx,
+			standingOrder.OccurrenceTransactionID())
+		if err != nil {
+			return fmt.Errorf("failed to find transfer approval: %w", err)
+		}
+
+		if transferApproval.IsPending() {
+			return nil
+		}
+
+		eventCollector, err := s.applyApproval(ctx, &standingOrder, transferApproval)
+		if err != nil {
+			return err
+		}
+
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		if err := s.standingOrderRepository.UpdateTx(ctx, dbTx, &standingOrder); err != nil {
+			return fmt.Errorf("failed to update standing order: %w", err)
+		}
+
+		done = true
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to settle occurrence: %w", err)
+	}
+
+	return done, nil
+}
+
+// applyApproval records the outcome of an occurrence transfer that was reviewed and moves the standing order on to
+// its next occurrence.
+func (s *StandingOrderService) applyApproval(ctx context.Context, standingOrder *model.StandingOrder,
+	transferApproval model.TransferApproval,
+) (*StandingOrderEventCollector, error) {
+	rule, err := recurrence.Parse(standingOrder.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse recurrence: %w", err)
+	}
+
+	approved := transferApproval.Status == model.TransferApprovalStatusApproved
+
+	transactionID := standingOrder.OccurrenceTransactionID()
+	if !approved {
+		transactionID = standingOrder.AttemptTransactionID()
+	}
+
+	eventCollector, err := NewStandingOrderEventCollector(ctx, s.eventRepository,
+		standingOrder.ID, transactionID, s.eventVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create standing order event collector: %w", err)
+	}
+
+	if approved {
+		eventCollector.OnExecutedEvent(*standingOrder)
+		standingOrder.ExecutedCount++
+	} else {
+		// a reviewer decided against the transfer, the occurrence is not retried
+		eventCollector.OnSkippedEvent(*standingOrder, rejectionReason(transferApproval), nil)
+	}
+
+	standingOrder.Status = model.StandingOrderStatusActive
+	s.advance(standingOrder, rule, eventCollector)
+	standingOrder.UpdatedAt = time.Now()
+
+	return eventCollector, nil
+}
+
+// advance moves the standing order to its next occurrence, completing it when it has ended.
+func (s *StandingOrderService) advance(standingOrder *model.StandingOrder, rule recurrence.Rule,
+	eventCollector *StandingOrderEventCollector,
+) {
+	nextOccurrenceAt := rule.Next(standingOrder.StartAt, standingOrder.OccurrenceAt)
+
+	standingOrder.Occurrence++
+	standingOrder.Attempt = 0
+	standingOrder.OccurrenceAt = nextOccurrenceAt
+	standingOrder.NextRunAt = nextOccurrenceAt
+
+	if standingOrder.HasEnded(nextOccurrenceAt) {
+		standingOrder.Status = model.StandingOrderStatusCompleted
+		eventCollector.OnCompletedEvent(*standingOrder)
+	}
+}
+
+func (s *StandingOrderService) newStandingOrder(req dto.CreateStandingOrderRequest,
+	transactionID string,
+) (model.StandingOrder, error) {
+	if req.SourceAccountID == req.DestinationAccountID {
+		return model.StandingOrder{}, ErrSourceAndDestinationAccountSame
+	}
+
+	rule, err := recurrence.Parse(req.Recurrence)
+	if err != nil {
+		invalidErr := ErrInvalidRecurrence
+		invalidErr.Cause = err
+
+		return model.StandingOrder{}, invalidErr
+	}
+
+	now := time.Now()
+
+	// recurrences are evaluated in UTC as the projection stores timestamps without time zone
+	startAt := req.StartAt.UTC()
+	if !startAt.After(now) {
+		return model.StandingOrder{}, ErrInvalidStartAt
+	}
+
+	var endAt *time.Time
+
+	if req.EndAt != nil {
+		utcEndAt := req.EndAt.UTC()
+		if !utcEndAt.After(startAt) {
+			return model.StandingOrder{}, ErrInvalidEndAt
+		}
+
+		endAt = &utcEndAt
+	}
+
+	standingOrder := model.StandingOrder{
+		TransactionID:        transactionID,
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Recurrence:           rule.String(),
+		StartAt:              startAt,
+		EndAt:                endAt,
+		MaxOccurrences:       req.MaxOccurrences,
+		Occurrence:           1,
+		Status:               model.StandingOrderStatusActive,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	// the first occurrence is the first match at or after start
+	standingOrder.OccurrenceAt = rule.Next(startAt, startAt.Add(-time.Nanosecond))
+	standingOrder.NextRunAt = standingOrder.OccurrenceAt
+
+	if standingOrder.HasEnded(standingOrder.OccurrenceAt) {
+		return model.StandingOrder{}, ErrInvalidRecurrence
+	}
+
+	return standingOrder, nil
+}
+
+func toStandingOrderResponse(standingOrder model.StandingOrder) dto.StandingOrderResponse {
+	resp := dto.StandingOrderResponse{
+		ID:                   standingOrder.ID,
+		SourceAccountID:      standingOrder.SourceAccountID,
+		DestinationAccountID: standingOrder.DestinationAccountID,
+		Amount:               standingOrder.Amount,
+		Recurrence:           standingOrder.Recurrence,
+		StartAt:              standingOrder.StartAt,
+		EndAt:                standingOrder.EndAt,
+		MaxOccurrences:       standingOrder.MaxOccurrences,
+		ExecutedCount:        standingOrder.ExecutedCount,
+		Status:               string(standingOrder.Status),
+		CreatedAt:            standingOrder.CreatedAt,
+	}
+
+	if standingOrder.Status == model.StandingOrderStatusActive {
+		resp.NextRunAt = &standingOrder.NextRunAt
+	}
+
+	return resp
+}
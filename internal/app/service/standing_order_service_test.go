@@ -0,0 +1,590 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandingOrderService_CreateStandingOrder(t *testing.T) {
+
+	testCreate := func(
+		req dto.CreateStandingOrderRequest,
+		svc *StandingOrderService,
+		ctx context.Context,
+		wantErr error,
+	) func(t *testing.T) {
+		return func(t *testing.T) {
+			got, err := svc.CreateStandingOrder(ctx, req)
+
+			if wantErr != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), wantErr.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "active", got.Status)
+				assert.NotNil(t, got.NextRunAt)
+			}
+		}
+	}
+
+	startAt := time.Now().Add(24 * time.Hour)
+	validReq := dto.CreateStandingOrderRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		Recurrence:           "FREQ=MONTHLY;BYMONTHDAY=1",
+		StartAt:              startAt,
+		MaxOccurrences:       12,
+	}
+
+	// error request context
+	t.Run("error_request_context", testCreate(validReq, &StandingOrderService{
+		accountRepository:       &accountRepositoryMock{},
+		standingOrderRepository: &standingOrderRepositoryMock{},
+	}, context.Background(), fmt.Errorf("request context not found")))
+
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	// error invalid recurrence
+	t.Run("error_invalid_recurrence", testCreate(dto.CreateStandingOrderRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		Recurrence:           "FREQ=HOURLY",
+		StartAt:              startAt,
+	}, &StandingOrderService{
+		requestTimeThreshold:    30 * time.Second,
+		accountRepository:       &accountRepositoryMock{},
+		standingOrderRepository: &standingOrderRepositoryMock{},
+	}, ctx, ErrInvalidRecurrence))
+
+	// error start at in the past
+	t.Run("error_start_at_in_past", testCreate(dto.CreateStandingOrderRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		Recurrence:           "FREQ=DAILY",
+		StartAt:              time.Now().Add(-time.Hour),
+	}, &StandingOrderService{
+		requestTimeThreshold:    30 * time.Second,
+		accountRepository:       &accountRepositoryMock{},
+		standingOrderRepository: &standingOrderRepositoryMock{},
+	}, ctx, ErrInvalidStartAt))
+
+	// error end at before start at
+	endAt := startAt.Add(-time.Minute)
+	t.Run("error_end_at_before_start_at", testCreate(dto.CreateStandingOrderRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		Recurrence:           "FREQ=DAILY",
+		StartAt:              startAt,
+		EndAt:                &endAt,
+	}, &StandingOrderService{
+		requestTimeThreshold:    30 * time.Second,
+		accountRepository:       &accountRepositoryMock{},
+		standingOrderRepository: &standingOrderRepositoryMock{},
+	}, ctx, ErrInvalidEndAt))
+
+	// error source and destination account same
+	t.Run("error_source_and_destination_same", testCreate(dto.CreateStandingOrderRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 1,
+		Amount:               decimal.NewFromInt(100),
+		Recurrence:           "FREQ=DAILY",
+		StartAt:              startAt,
+	}, &StandingOrderService{
+		requestTimeThreshold:    30 * time.Second,
+		accountRepository:       &accountRepositoryMock{},
+		standingOrderRepository: &standingOrderRepositoryMock{},
+	}, ctx, ErrSourceAndDestinationAccountSame))
+
+	// error destination account not found
+	t.Run("error_destination_account_not_found", testCreate(validReq, &StandingOrderService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil, exception.ErrRecordNotFound},
+		},
+		standingOrderRepository: &standingOrderRepositoryMock{},
+	}, ctx, ErrDestinationAccountNotFound))
+
+	// error idempotency
+	t.Run("error_idempotency", testCreate(validReq, &StandingOrderService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+		},
+		standingOrderRepository: &standingOrderRepositoryMock{
+			errCreateTx: []error{fmt.Errorf("failed to exec statement: %w", exception.ErrRecordNotUnique)},
+		},
+	}, ctx, ErrIdempotency))
+
+	// error place events
+	t.Run("error_place_events", testCreate(validReq, &StandingOrderService{
+		requestTimeThreshold: 30 * time.Second,
+		accountRepository: &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+		},
+		standingOrderRepository: &standingOrderRepositoryMock{
+			errCreateTx: []error{nil},
+		},
+		eventRepository: &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:          []error{errors.New("internal db error")},
+			events:                   []model.Event{{}},
+		},
+	}, ctx, errors.New("internal db error")))
+
+	// success
+	t.Run("success", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:          []error{nil},
+			events:                   []model.Event{{}},
+		}
+
+		testCreate(validReq, &StandingOrderService{
+			requestTimeThreshold: 30 * time.Second,
+			accountRepository: &accountRepositoryMock{
+				errFindByID: []error{nil, nil},
+			},
+			standingOrderRepository: &standingOrderRepositoryMock{
+				errCreateTx: []error{nil},
+			},
+			eventRepository: eventRepository,
+		}, ctx, nil)(t)
+
+		assert.Len(t, eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypeStandingOrderCreated, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.AggregateTypeStandingOrder, eventRepository.placedEvents[0].AggregateType)
+		assert.Equal(t, "tx-12345", eventRepository.placedEvents[0].TransactionID)
+	})
+}
+
+func TestStandingOrderService_CancelStandingOrder(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	t.Run("error_not_active", func(t *testing.T) {
+		svc := &StandingOrderService{
+			requestTimeThreshold: 30 * time.Second,
+			standingOrderRepository: &standingOrderRepositoryMock{
+				errFindByIDForUpdateTx: []error{nil},
+				standingOrder: model.StandingOrder{
+					ID:     1,
+					Status: model.StandingOrderStatusCompleted,
+				},
+			},
+		}
+
+		err := svc.CancelStandingOrder(ctx, dto.StandingOrderIDRequest{ID: 1})
+		assert.ErrorIs(t, err, ErrStandingOrderNotActive)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo := &standingOrderRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil},
+			errUpdateTx:            []error{nil},
+			standingOrder: model.StandingOrder{
+				ID:     1,
+				Status: model.StandingOrderStatusActive,
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{nil},
+			errCreateBulkTx:          []error{nil},
+			events:                   []model.Event{{SequenceNumber: 1}},
+		}
+		svc := &StandingOrderService{
+			requestTimeThreshold:    30 * time.Second,
+			standingOrderRepository: repo,
+			eventRepository:         eventRepository,
+		}
+
+		err := svc.CancelStandingOrder(ctx, dto.StandingOrderIDRequest{ID: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, model.StandingOrderStatusCancelled, repo.updated[0].Status)
+		assert.Equal(t, model.EventTypeStandingOrderCancelled, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, int64(2), eventRepository.placedEvents[0].SequenceNumber)
+	})
+}
+
+func TestStandingOrderService_ExecuteDue(t *testing.T) {
+	// every day at 09:00 starting 2025-01-01, the first occurrence is due
+	startAt := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	dueOrder := model.StandingOrder{
+		ID:                   5,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		Recurrence:           "FREQ=DAILY",
+		StartAt:              startAt,
+		Occurrence:           1,
+		OccurrenceAt:         startAt,
+		NextRunAt:            startAt,
+		Status:               model.StandingOrderStatusActive,
+	}
+
+	type result struct {
+		repo            *standingOrderRepositoryMock
+		eventRepository *eventRepositoryMock
+		transferer      *transfererMock
+		processed       int
+	}
+
+	// executeWithResponse executes the due standing order, recorded are the events of an earlier attempt at the
+	// occurrence transfer
+	executeWithResponse := func(t *testing.T, standingOrder model.StandingOrder, retryPolicy RetryPolicy,
+		resp dto.TransferResponse, transferErr error, recorded []model.Event,
+	) result {
+		repo := &standingOrderRepositoryMock{
+			errFindAllDue:             []error{nil},
+			standingOrders:            []model.StandingOrder{standingOrder},
+			errFindDueByIDForUpdateTx: []error{nil},
+			standingOrder:             standingOrder,
+			errUpdateTx:               []error{nil},
+		}
+		events := []model.Event{{SequenceNumber: 1}}
+		if recorded != nil {
+			events = recorded
+		}
+
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{nil},
+			errFindLastByAggregateID:  []error{nil},
+			errCreateBulkTx:           []error{nil},
+			events:                    events,
+		}
+		transferer := &transfererMock{errTransfer: []error{transferErr}, responses: []dto.TransferResponse{resp}}
+		svc := &StandingOrderService{
+			standingOrderRepository: repo,
+			eventRepository:         eventRepository,
+			transferer:              transferer,
+			retryPolicy:             retryPolicy,
+		}
+
+		processed, err := svc.ExecuteDue(context.Background())
+		assert.NoError(t, err)
+
+		return result{repo: repo, eventRepository: eventRepository, transferer: transferer, processed: processed}
+	}
+
+	execute := func(t *testing.T, standingOrder model.StandingOrder, retryPolicy RetryPolicy, transferErr error) result {
+		return executeWithResponse(t, standingOrder, retryPolicy, dto.TransferResponse{}, transferErr, nil)
+	}
+
+	t.Run("error_find_due", func(t *testing.T) {
+		svc := &StandingOrderService{
+			standingOrderRepository: &standingOrderRepositoryMock{
+				errFindAllDue: []error{errors.New("internal db error")},
+			},
+		}
+
+		_, err := svc.ExecuteDue(context.Background())
+		assert.ErrorContains(t, err, "internal db error")
+	})
+
+	t.Run("skip_locked_by_another_worker", func(t *testing.T) {
+		transferer := &transfererMock{}
+		svc := &StandingOrderService{
+			standingOrderRepository: &standingOrderRepositoryMock{
+				errFindAllDue:             []error{nil},
+				standingOrders:            []model.StandingOrder{dueOrder},
+				errFindDueByIDForUpdateTx: []error{exception.ErrRecordNotFound},
+			},
+			transferer: transferer,
+		}
+
+		processed, err := svc.ExecuteDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, processed)
+		assert.Equal(t, 0, transferer.transferCallCount)
+	})
+
+	t.Run("success_executed", func(t *testing.T) {
+		got := execute(t, dueOrder, RetryPolicy{MaxRetries: 3, Interval: time.Hour}, nil)
+
+		assert.Equal(t, 1, got.processed)
+		assert.Equal(t, []string{"standing-order-5-1"}, got.transferer.transactionIDs)
+		assert.Equal(t, []string{standingOrderInitiator}, got.transferer.initiators)
+		assert.Equal(t, model.EventTypeStandingOrderExecuted, got.eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, "standing-order-5-1", got.eventRepository.placedEvents[0].TransactionID)
+
+		updated := got.repo.updated[0]
+		assert.Equal(t, 2, updated.Occurrence)
+		assert.Equal(t, 1, updated.ExecutedCount)
+		assert.Equal(t, startAt.AddDate(0, 0, 1), updated.NextRunAt)
+		assert.Equal(t, model.StandingOrderStatusActive, updated.Status)
+	})
+
+	t.Run("success_already_executed_before_restart", func(t *testing.T) {
+		got := executeWithResponse(t, dueOrder, RetryPolicy{}, dto.TransferResponse{},
+			fmt.Errorf("transaction service: %w", ErrIdempotency), []model.Event{{
+				AggregateID:   1,
+				AggregateType: model.AggregateTypeAccount,
+				EventType:     model.EventTypeDebitBalance,
+				EventData:     []byte(`{"amount":"100"}`),
+			}})
+
+		assert.Equal(t, 1, got.processed)
+		assert.Equal(t, 1, got.repo.updated[0].ExecutedCount)
+	})
+
+	t.Run("skipped_transaction_id_used_by_another_operation", func(t *testing.T) {
+		got := executeWithResponse(t, dueOrder, RetryPolicy{}, dto.TransferResponse{},
+			fmt.Errorf("transaction service: %w", ErrIdempotency), []model.Event{{
+				AggregateID:   9,
+				AggregateType: model.AggregateTypeAccount,
+				EventType:     model.EventTypeInitBalance,
+				EventData:     []byte(`{"amount":"100"}`),
+			}})
+
+		// no money moved under the id, the occurrence is not executed
+		assert.Equal(t, model.EventTypeStandingOrderSkipped, got.eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, 0, got.repo.updated[0].ExecutedCount)
+	})
+
+	t.Run("awaiting_approval", func(t *testing.T) {
+		got := executeWithResponse(t, dueOrder, RetryPolicy{},
+			dto.TransferResponse{Status: string(model.TransactionStatusPendingApproval)}, nil, nil)
+
+		assert.Equal(t, model.EventTypeStandingOrderAwaitingApproval, got.eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, "standing-order-5-1", got.eventRepository.placedEvents[0].TransactionID)
+
+		updated := got.repo.updated[0]
+		assert.Equal(t, model.StandingOrderStatusAwaitingApproval, updated.Status)
+		assert.Equal(t, 1, updated.Occurrence)
+		assert.Equal(t, 0, updated.ExecutedCount)
+	})
+
+	t.Run("awaiting_approval_requested_before_restart", func(t *testing.T) {
+		got := executeWithResponse(t, dueOrder, RetryPolicy{}, dto.TransferResponse{},
+			fmt.Errorf("transaction service: %w", ErrIdempotency), []model.Event{{
+				AggregateType: model.AggregateTypeTransfer,
+				EventType:     model.EventTypeTransferApprovalRequested,
+				EventData:     []byte(`{"source_account_id":1,"destination_account_id":2,"amount":"100"}`),
+			}})
+
+		assert.Equal(t, model.StandingOrderStatusAwaitingApproval, got.repo.updated[0].Status)
+		assert.Equal(t, 0, got.repo.updated[0].ExecutedCount)
+	})
+
+	t.Run("skipped_with_retry", func(t *testing.T) {
+		// weekly order whose occurrence just became due, a retry fits before the next occurrence
+		recentStartAt := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+		weekly := dueOrder
+		weekly.Recurrence = "FREQ=WEEKLY"
+		weekly.StartAt = recentStartAt
+		weekly.OccurrenceAt = recentStartAt
+		weekly.NextRunAt = recentStartAt
+
+		got := execute(t, weekly, RetryPolicy{MaxRetries: 3, Interval: time.Hour},
+			fmt.Errorf("failed to process transfer: %w", ErrInsufficientBalance))
+
+		assert.Equal(t, model.EventTypeStandingOrderSkipped, got.eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, "standing-order-5-1-attempt-1", got.eventRepository.placedEvents[0].TransactionID)
+
+		updated := got.repo.updated[0]
+		assert.Equal(t, 1, updated.Occurrence)
+		assert.Equal(t, 1, updated.Attempt)
+		assert.Equal(t, recentStartAt, updated.OccurrenceAt)
+		assert.True(t, updated.NextRunAt.After(time.Now()))
+	})
+
+	t.Run("skipped_without_retry_when_next_occurrence_is_due", func(t *testing.T) {
+		got := execute(t, dueOrder, RetryPolicy{MaxRetries: 3, Interval: time.Hour},
+			fmt.Errorf("failed to process transfer: %w", ErrInsufficientBalance))
+
+		updated := got.repo.updated[0]
+		assert.Equal(t, 2, updated.Occurrence)
+		assert.Equal(t, 0, updated.Attempt)
+		assert.Equal(t, startAt.AddDate(0, 0, 1), updated.NextRunAt)
+	})
+
+	t.Run("skipped_without_retry_when_denied", func(t *testing.T) {
+		recentStartAt := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+		weekly := dueOrder
+		weekly.Recurrence = "FREQ=WEEKLY"
+		weekly.StartAt = recentStartAt
+		weekly.OccurrenceAt = recentStartAt
+		weekly.NextRunAt = recentStartAt
+
+		got := execute(t, weekly, RetryPolicy{MaxRetries: 3, Interval: time.Hour},
+			fmt.Errorf("%w: %w", ErrTransferDenied, errTransferRefused))
+
+		// the denial is recorded under the occurrence transaction id, a retry could not move any money
+		updated := got.repo.updated[0]
+		assert.Equal(t, 2, updated.Occurrence)
+		assert.Equal(t, 0, updated.Attempt)
+		assert.Equal(t, 0, updated.ExecutedCount)
+		assert.Equal(t, recentStartAt.AddDate(0, 0, 7), updated.NextRunAt)
+	})
+
+	t.Run("skipped_denied_before_restart", func(t *testing.T) {
+		got := executeWithResponse(t, dueOrder, RetryPolicy{MaxRetries: 3, Interval: time.Hour},
+			dto.TransferResponse{}, fmt.Errorf("transaction service: %w", ErrIdempotency), []model.Event{{
+				AggregateType: model.AggregateTypeRiskDecision,
+				EventType:     model.EventTypeTransferRiskAssessed,
+				EventData:     []byte(`{"source_account_id":1,"destination_account_id":2,"amount":"100","action":"deny"}`),
+			}})
+
+		assert.Equal(t, model.EventTypeStandingOrderSkipped, got.eventRepository.placedEvents[0].EventType)
+
+		updated := got.repo.updated[0]
+		assert.Equal(t, 2, updated.Occurrence)
+		assert.Equal(t, 0, updated.ExecutedCount)
+	})
+
+	t.Run("skipped_retries_exhausted", func(t *testing.T) {
+		retried := dueOrder
+		retried.Attempt = 3
+
+		got := execute(t, retried, RetryPolicy{MaxRetries: 3, Interval: time.Hour},
+			fmt.Errorf("failed to process transfer: %w", ErrInsufficientBalance))
+
+		updated := got.repo.updated[0]
+		assert.Equal(t, 2, updated.Occurrence)
+		assert.Equal(t, 0, updated.Attempt)
+		assert.Equal(t, 0, updated.ExecutedCount)
+		assert.Equal(t, startAt.AddDate(0, 0, 1), updated.NextRunAt)
+	})
+
+	t.Run("completed_on_max_occurrences", func(t *testing.T) {
+		last := dueOrder
+		last.MaxOccurrences = 1
+
+		got := execute(t, last, RetryPolicy{}, nil)
+
+		assert.Len(t, got.eventRepository.placedEvents, 2)
+		assert.Equal(t, model.EventTypeStandingOrderCompleted, got.eventRepository.placedEvents[1].EventType)
+		assert.Equal(t, model.StandingOrderStatusCompleted, got.repo.updated[0].Status)
+	})
+
+	t.Run("completed_on_end_at", func(t *testing.T) {
+		endAt := startAt.Add(12 * time.Hour)
+		last := dueOrder
+		last.EndAt = &endAt
+
+		got := execute(t, last, RetryPolicy{}, nil)
+
+		assert.Equal(t, model.StandingOrderStatusCompleted, got.repo.updated[0].Status)
+	})
+
+	t.Run("retry_on_internal_error", func(t *testing.T) {
+		got := execute(t, dueOrder, RetryPolicy{}, errors.New("internal db error"))
+
+		assert.Equal(t, 0, got.processed)
+		assert.Empty(t, got.repo.updated)
+		assert.Empty(t, got.eventRepository.placedEvents)
+	})
+}
+
+func TestStandingOrderService_ExecuteDueSettleApproval(t *testing.T) {
+	startAt := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	awaitingOrder := model.StandingOrder{
+		ID:                   5,
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		Recurrence:           "FREQ=DAILY",
+		StartAt:              startAt,
+		Occurrence:           1,
+		OccurrenceAt:         startAt,
+		NextRunAt:            startAt,
+		Status:               model.StandingOrderStatusAwaitingApproval,
+	}
+
+	settle := func(t *testing.T, transferApproval model.TransferApproval) (*standingOrderRepositoryMock,
+		*eventRepositoryMock, int,
+	) {
+		repo := &standingOrderRepositoryMock{
+			awaitingApproval:       []model.StandingOrder{awaitingOrder},
+			errFindByIDForUpdateTx: []error{nil},
+			standingOrder:          awaitingOrder,
+			errUpdateTx:            []error{nil},
+			errFindAllDue:          []error{nil},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{nil},
+			errCreateBulkTx:          []error{nil},
+			events:                   []model.Event{{SequenceNumber: 2}},
+		}
+		svc := &StandingOrderService{
+			standingOrderRepository: repo,
+			eventRepository:         eventRepository,
+			transferer:              &transfererMock{},
+			transferApprovalFinder:  &transferApprovalRepositoryMock{transferApproval: transferApproval},
+		}
+
+		processed, err := svc.ExecuteDue(context.Background())
+		assert.NoError(t, err)
+
+		return repo, eventRepository, processed
+	}
+
+	t.Run("still_pending", func(t *testing.T) {
+		repo, eventRepository, processed := settle(t,
+			model.TransferApproval{Status: model.TransferApprovalStatusPending})
+
+		assert.Equal(t, 0, processed)
+		assert.Empty(t, repo.updated)
+		assert.Empty(t, eventRepository.placedEvents)
+	})
+
+	t.Run("approved", func(t *testing.T) {
+		repo, eventRepository, processed := settle(t,
+			model.TransferApproval{Status: model.TransferApprovalStatusApproved})
+
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, model.EventTypeStandingOrderExecuted, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, "standing-order-5-1", eventRepository.placedEvents[0].TransactionID)
+
+		updated := repo.updated[0]
+		assert.Equal(t, model.StandingOrderStatusActive, updated.Status)
+		assert.Equal(t, 1, updated.ExecutedCount)
+		assert.Equal(t, 2, updated.Occurrence)
+		assert.Equal(t, startAt.AddDate(0, 0, 1), updated.NextRunAt)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		repo, eventRepository, processed := settle(t, model.TransferApproval{
+			Status:          model.TransferApprovalStatusRejected,
+			RejectionReason: "unknown payee",
+		})
+
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, model.EventTypeStandingOrderSkipped, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, "standing-order-5-1-attempt-1", eventRepository.placedEvents[0].TransactionID)
+
+		updated := repo.updated[0]
+		assert.Equal(t, model.StandingOrderStatusActive, updated.Status)
+		assert.Equal(t, 0, updated.ExecutedCount)
+		assert.Equal(t, 2, updated.Occurrence)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		repo, _, processed := settle(t, model.TransferApproval{Status: model.TransferApprovalStatusExpired})
+
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, 0, repo.updated[0].ExecutedCount)
+		assert.Equal(t, 2, repo.updated[0].Occurrence)
+	})
+}
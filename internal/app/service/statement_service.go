@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
+	"github.com/shopspring/decimal"
+)
+
+type StatementRepository interface {
+	FindBalanceAt(ctx context.Context, accountID int64, at time.Time) (decimal.Decimal, error)
+	FindAllMovements(ctx context.Context, accountID int64, from time.Time, to time.Time) ([]model.Movement, error)
+}
+
+// movementDescriptions are the default descriptions of the movements, the translations are in the statement
+// section of the locale files.
+var movementDescriptions = map[model.EventType]string{
+	model.EventTypeDepositReceived: "Initial deposit",
+	model.EventTypeCreditBalance:   "Transfer from account {{.counterparty}}",
+	model.EventTypeDebitBalance:    "Transfer to account {{.counterparty}}",
+	model.EventTypeFeeCharged:      "Transfer fee",
+	model.EventTypeFeeCollected:    "Fee collected from account {{.counterparty}}",
+	model.EventTypeInterestPosted:  "Interest",
+}
+
+type StatementService struct {
+	statementRepository StatementRepository
+	accountRepository   AccountRepository
+}
+
+func NewStatementService(statementRepository StatementRepository,
+	accountRepository AccountRepository,
+) *StatementService {
+	return &StatementService{
+		statementRepository: statementRepository,
+		accountRepository:   accountRepository,
+	}
+}
+
+// GetStatement godoc
+// @Summary      Get Account Statement
+// @Description  Get the opening balance, movements and closing balance of an Account within a period,
+// @Description  as JSON or as CSV when text/csv is accepted
+// @Tags         Account
+// @ID           getStatement
+// @Produce      json
+// @Produce      text/csv
+// @Param        id	path		string	true	"Account ID"
+// @Param        from	query		string	true	"Start of the period, a date (YYYY-MM-DD) or an RFC3339 timestamp"
+// @Param        to	query		string	true	"End of the period, a date (YYYY-MM-DD, inclusive) or an RFC3339 timestamp"
+// @Param        Accept-Language	header		string	false	"Language of the descriptions"
+// @Success      200  {object}  dto.StatementResponse	"Statement"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /accounts/{id}/statement [get].
+func (s *StatementService) GetStatement(ctx context.Context,
+	req dto.StatementRequest,
+) (dto.StatementResponse, error) {
+	if _, err := s.accountRepository.FindByID(ctx, req.ID); err != nil {
+		return dto.StatementResponse{}, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	openingBalance, err := s.statementRepository.FindBalanceAt(ctx, req.ID, req.From)
+	if err != nil {
+		return dto.StatementResponse{}, fmt.Errorf("failed to find opening balance: %w", err)
+	}
+
+	movements, err := s.statementRepository.FindAllMovements(ctx, req.ID, req.From, req.To)
+	if err != nil {
+		return dto.StatementResponse{}, fmt.Errorf("failed to find movements: %w", err)
+	}
+
+	statement := dto.StatementResponse{
+		AccountID:      req.ID,
+		From:           req.From,
+		To:             req.To,
+		OpeningBalance: openingBalance,
+		Movements:      make([]dto.StatementMovement, 0, len(movements)),
+	}
+
+	balance := openingBalance
+
+	for _, movement := range movements {
+		balance = balance.Add(movement.Amount)
+
+		statement.Movements = append(statement.Movements, dto.StatementMovement{
+			TransactionID:         movement.TransactionID,
+			Type:                  string(movement.EventType),
+			Description:           describeMovement(movement, req.Language),
+			CounterpartyAccountID: movement.CounterpartyAccountID,
+			Amount:                movement.Amount,
+			RunningBalance:        balance,
+			CreatedAt:             movement.CreatedAt,
+		})
+	}
+
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
+
+func describeMovement(movement model.Movement, language string) string {
+	vars := map[string]interface{}{
+		"source": movement.Source,
+	}
+
+	if movement.CounterpartyAccountID != nil {
+		vars["counterparty"] = *movement.CounterpartyAccountID
+	}
+
+	return lang.Localizable{
+		MessageID:   "statement." + string(movement.EventType),
+		Message:     movementDescriptions[movement.EventType],
+		MessageVars: vars,
+	}.Localize(language)
+}
@@ -0,0 +1,107 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementService_GetStatement(t *testing.T) {
+	lang.SetBasePath("../../../resources/locales")
+	lang.SetSupportedLanguages("en,id")
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	req := dto.StatementRequest{ID: 1, From: from, To: from.AddDate(0, 1, 0)}
+	counterparty := int64(2)
+	revenue := int64(99)
+
+	movements := []model.Movement{
+		{
+			TransactionID: "tx-1", EventType: model.EventTypeCreditBalance, Amount: decimal.NewFromInt(50),
+			CounterpartyAccountID: &counterparty, CreatedAt: from.Add(time.Hour),
+		},
+		{
+			TransactionID: "tx-2", EventType: model.EventTypeDebitBalance, Amount: decimal.NewFromInt(-30),
+			CounterpartyAccountID: &counterparty, CreatedAt: from.Add(2 * time.Hour),
+		},
+		{
+			TransactionID: "tx-2", EventType: model.EventTypeFeeCharged, Amount: decimal.RequireFromString("-1.5"),
+			CounterpartyAccountID: &revenue, CreatedAt: from.Add(2 * time.Hour),
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		statementRepository := &statementRepositoryMock{
+			balanceRepositoryMock: balanceRepositoryMock{balance: decimal.NewFromInt(100)},
+			movements:             movements,
+		}
+		svc := NewStatementService(statementRepository, &accountRepositoryMock{errFindByID: []error{nil}})
+
+		statement, err := svc.GetStatement(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []time.Time{from}, statementRepository.findAts)
+		assert.True(t, decimal.NewFromInt(100).Equal(statement.OpeningBalance))
+		assert.True(t, decimal.RequireFromString("118.5").Equal(statement.ClosingBalance))
+		assert.Len(t, statement.Movements, 3)
+		assert.True(t, decimal.NewFromInt(150).Equal(statement.Movements[0].RunningBalance))
+		assert.True(t, decimal.NewFromInt(120).Equal(statement.Movements[1].RunningBalance))
+		assert.Equal(t, "Transfer from account 2", statement.Movements[0].Description)
+		assert.Equal(t, "Transfer to account 2", statement.Movements[1].Description)
+		assert.Equal(t, "Transfer fee", statement.Movements[2].Description)
+	})
+
+	t.Run("success_localized", func(t *testing.T) {
+		statementRepository := &statementRepositoryMock{movements: movements[:1]}
+		svc := NewStatementService(statementRepository, &accountRepositoryMock{errFindByID: []error{nil}})
+
+		localizedReq := req
+		localizedReq.Language = "id"
+
+		statement, err := svc.GetStatement(context.Background(), localizedReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Transfer dari rekening 2", statement.Movements[0].Description)
+	})
+
+	t.Run("success_no_movements", func(t *testing.T) {
+		statementRepository := &statementRepositoryMock{
+			balanceRepositoryMock: balanceRepositoryMock{balance: decimal.NewFromInt(100)},
+		}
+		svc := NewStatementService(statementRepository, &accountRepositoryMock{errFindByID: []error{nil}})
+
+		statement, err := svc.GetStatement(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Empty(t, statement.Movements)
+		assert.True(t, decimal.NewFromInt(100).Equal(statement.ClosingBalance))
+	})
+
+	t.Run("error_account_not_found", func(t *testing.T) {
+		svc := NewStatementService(&statementRepositoryMock{},
+			&accountRepositoryMock{errFindByID: []error{exception.ErrRecordNotFound}})
+
+		_, err := svc.GetStatement(context.Background(), req)
+
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+
+	t.Run("error_find_movements", func(t *testing.T) {
+		statementRepository := &statementRepositoryMock{errFindAllMovements: errors.New("db error")}
+		svc := NewStatementService(statementRepository, &accountRepositoryMock{errFindByID: []error{nil}})
+
+		_, err := svc.GetStatement(context.Background(), req)
+
+		assert.ErrorContains(t, err, "failed to find movements")
+	})
+}
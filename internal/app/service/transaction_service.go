@@ -3,156 +3,1362 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
 	"time"
 
 	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/fee"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/risk"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/sanctions"
+	"github.com/shopspring/decimal"
 )
 
+// the rejection reasons of a transfer blocked by the sanctions screening or denied by the risk rules when it is
+// approved.
+const (
+	sanctionsBlockedReason = "blocked by the sanctions screening"
+	riskDeniedReason       = "denied by the risk rules"
+)
+
+// errTransferRefused is wrapped by the error of a transfer denied by the risk rules or blocked by the sanctions
+// screening, the refusal is recorded under the transaction id so that the transfer cannot be retried under it.
+var errTransferRefused = errors.New("transfer refused")
+
+type TransferApprovalRepository interface {
+	CreateTx(ctx context.Context, tx *sql.Tx, transferApproval *model.TransferApproval) error
+	UpdateTx(ctx context.Context, tx *sql.Tx, transferApproval *model.TransferApproval) error
+	FindByTransactionID(ctx context.Context, transactionID string) (model.TransferApproval, error)
+	FindByTransactionIDForUpdateTx(ctx context.Context, tx *sql.Tx, transactionID string) (model.TransferApproval, error)
+	FindAllExpired(ctx context.Context, now time.Time, limit int) ([]model.TransferApproval, error)
+}
+
+type RiskDecisionRepository interface {
+	UpsertTx(ctx context.Context, tx *sql.Tx, riskDecision *model.RiskDecision) error
+}
+
+// RiskEvaluator decides from the risk rules whether a transfer is allowed, denied or held for review.
+type RiskEvaluator interface {
+	Evaluate(transfer risk.Transfer) risk.Decision
+}
+
+type SanctionsScreeningRepository interface {
+	UpsertScreeningTx(ctx context.Context, tx *sql.Tx, screening *model.SanctionsScreening) error
+}
+
+// SanctionsScreener returns the sanctions list the parties of a transfer are screened against.
+type SanctionsScreener interface {
+	List() *sanctions.List
+}
+
+// SanctionsPolicy controls the action taken on a transfer whose party matched the sanctions list, a match scoring
+// at least BlockScore blocks the transfer and a match scoring at least HoldScore holds it for approval. The
+// ExemptAccountIDs are the system accounts of the bank, they are not screened.
+type SanctionsPolicy struct {
+	HoldScore        float64
+	BlockScore       float64
+	ExemptAccountIDs []int64
+}
+
+// Exempts reports whether the account is a system account of the bank, which is not screened.
+func (p SanctionsPolicy) Exempts(accountID int64) bool {
+	return slices.Contains(p.ExemptAccountIDs, accountID)
+}
+
+// Threshold returns the lowest score of a match acted upon.
+func (p SanctionsPolicy) Threshold() float64 {
+	return min(p.HoldScore, p.BlockScore)
+}
+
+// Action returns the action taken on a transfer whose best match has the score.
+func (p SanctionsPolicy) Action(score float64) model.SanctionsAction {
+	if score >= p.BlockScore {
+		return model.SanctionsActionBlock
+	}
+
+	return model.SanctionsActionHold
+}
+
+// TransferApprovalPolicy controls which transfers wait for a second person to approve them, a zero threshold
+// disables the approval.
+type TransferApprovalPolicy struct {
+	Threshold decimal.Decimal
+	TTL       time.Duration
+}
+
+// Requires reports whether a transfer of the amount must be approved before it is executed.
+func (p TransferApprovalPolicy) Requires(amount decimal.Decimal) bool {
+	return p.Threshold.IsPositive() && amount.GreaterThan(p.Threshold)
+}
+
+// HotAccountPolicy lists the hot accounts, e.g. the fee revenue account or a popular merchant account, whose
+// credits are spread over shards instead of waiting for each other on the account row.
+type HotAccountPolicy struct {
+	AccountIDs []int64
+	Shards     int
+}
+
+// IsHot reports whether the credits to the account are booked on its shards.
+func (p HotAccountPolicy) IsHot(accountID int64) bool {
+	return p.Shards > 0 && slices.Contains(p.AccountIDs, accountID)
+}
+
+// PickShard returns the shard of a credit, picked at random so that concurrent credits rarely share a shard.
+func (p HotAccountPolicy) PickShard() int {
+	return rand.N(p.Shards) //nolint:gosec // the shard does not need a secure random
+}
+
+// transferBooking holds the shards a transfer was booked on, the shards credited for a hot destination or revenue
+// account and the shards consolidated into the source account to cover the debit.
+type transferBooking struct {
+	destinationShard   *model.AccountShard
+	revenueShard       *model.AccountShard
+	consolidatedShards []model.AccountShard
+}
+
 type TransactionService struct {
-	eventRepository      EventRepository
-	accountRepository    AccountRepository
-	requestTimeThreshold time.Duration
-	eventVersion         string
+	eventRepository            EventRepository
+	accountRepository          AccountRepository
+	accountShardRepository     AccountShardRepository
+	journalRepository          JournalRepository
+	transferApprovalRepository TransferApprovalRepository
+	riskDecisionRepository     RiskDecisionRepository
+	sanctionsRepository        SanctionsScreeningRepository
+	idempotencyKeyClaimer      IdempotencyKeyClaimer
+	transferLimiter            TransferLimiter
+	feeSchedule                *fee.Schedule
+	approvalPolicy             TransferApprovalPolicy
+	hotAccountPolicy           HotAccountPolicy
+	riskEvaluator              RiskEvaluator
+	sanctionsScreener          SanctionsScreener
+	sanctionsPolicy            SanctionsPolicy
+	requestTimeThreshold       time.Duration
+	eventVersion               string
+	batchSize                  int
 }
 
-func NewTransactionService(accountRepository AccountRepository,
-	eventRepository EventRepository, requestTimeThreshold time.Duration,
-	eventVersion string,
+// NewTransactionService creates the transaction service, a nil fee schedule disables transfer fees, a nil risk
+// evaluator disables the risk rules and a nil sanctions screener disables the sanctions screening.
+func NewTransactionService(accountRepository AccountRepository, accountShardRepository AccountShardRepository,
+	eventRepository EventRepository, journalRepository JournalRepository,
+	transferApprovalRepository TransferApprovalRepository, riskDecisionRepository RiskDecisionRepository,
+	sanctionsRepository SanctionsScreeningRepository, idempotencyKeyClaimer IdempotencyKeyClaimer,
+	transferLimiter TransferLimiter, feeSchedule *fee.Schedule, approvalPolicy TransferApprovalPolicy,
+	hotAccountPolicy HotAccountPolicy, riskEvaluator RiskEvaluator, sanctionsScreener SanctionsScreener,
+	sanctionsPolicy SanctionsPolicy, requestTimeThreshold time.Duration, eventVersion string, batchSize int,
 ) *TransactionService {
 	return &TransactionService{
-		accountRepository:    accountRepository,
-		eventRepository:      eventRepository,
-		requestTimeThreshold: requestTimeThreshold,
-		eventVersion:         eventVersion,
+		accountRepository:          accountRepository,
+		accountShardRepository:     accountShardRepository,
+		eventRepository:            eventRepository,
+		journalRepository:          journalRepository,
+		transferApprovalRepository: transferApprovalRepository,
+		riskDecisionRepository:     riskDecisionRepository,
+		sanctionsRepository:        sanctionsRepository,
+		idempotencyKeyClaimer:      idempotencyKeyClaimer,
+		transferLimiter:            transferLimiter,
+		feeSchedule:                feeSchedule,
+		approvalPolicy:             approvalPolicy,
+		hotAccountPolicy:           hotAccountPolicy,
+		riskEvaluator:              riskEvaluator,
+		sanctionsScreener:          sanctionsScreener,
+		sanctionsPolicy:            sanctionsPolicy,
+		requestTimeThreshold:       requestTimeThreshold,
+		eventVersion:               eventVersion,
+		batchSize:                  batchSize,
 	}
 }
 
 // Transfer godoc
 // @Summary      Transfer
-// @Description  Transfer between two accounts
+// @Description  Transfer between two accounts, the fee of the transfer, if any, is charged to the source account.
+// @Description  A transfer above the approval threshold is pending approval until another person approves it.
+// @Description  The risk rules may deny the transfer or hold it for approval, every decision is recorded.
+// @Description  A transfer whose party matches the sanctions list is blocked or held for approval
 // @Tags         Transfer
 // @ID           transfer
 // @Produce      json
 // @Param        req body create transfer	body		dto.CreateTransferRequest	true	"Transfer"
 // @Param        x-timestamp	header		string	true	"Request timestamp"
 // @Param        x-transaction-id	header		string	true	"Transaction ID"
-// @Success      204  "No content"
+// @Success      200  {object}  dto.TransferResponse	"Transfer"
 // @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      403  {object}  dto.ErrorResponse	"Approval required but the client is not authenticated"
 // @Failure      404  {object}  dto.ErrorResponse	"Record not found"
 // @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      422  {object}  dto.ErrorResponse	"Denied by the risk rules or blocked by the sanctions screening"
 // @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
 // @Router       /transfers [post].
-func (s *TransactionService) Transfer(ctx context.Context, req dto.CreateTransferRequest) error {
+func (s *TransactionService) Transfer(ctx context.Context, req dto.CreateTransferRequest) (dto.TransferResponse, error) {
 	reqContext, err := getRequestContext(ctx, s.requestTimeThreshold)
 	if err != nil {
-		return fmt.Errorf("failed to get request context: %w", err)
+		return dto.TransferResponse{}, fmt.Errorf("failed to get request context: %w", err)
 	}
 
 	// if event already exists, return err for idempotency
-	_, err = s.eventRepository.FindAllByTransactionID(ctx, reqContext.TransactionID)
+	events, err := s.eventRepository.FindAllByTransactionID(ctx, reqContext.TransactionID)
 	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
-		return fmt.Errorf("failed to find events: %w", err)
+		return dto.TransferResponse{}, fmt.Errorf("failed to find events: %w", err)
 	}
 
-	if err == nil {
-		return ErrIdempotency
+	if err == nil && !isTransferRetryable(events) {
+		return dto.TransferResponse{}, ErrIdempotency
 	}
 
 	// validate source and destination account
 	if req.SourceAccountID == req.DestinationAccountID {
-		return ErrSourceAndDestinationAccountSame
+		return dto.TransferResponse{}, ErrSourceAndDestinationAccountSame
 	}
 
-	// set event collector source and destination account
-	sourceAccountEventCollector, err := NewAccountEventCollector(ctx, s.eventRepository, req.SourceAccountID,
-		reqContext.TransactionID, s.eventVersion)
+	transferFee, err := s.calculateFee(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to create account event collector: %w", err)
+		return dto.TransferResponse{}, err
 	}
 
-	destinationAccountEventCollector, err := NewAccountEventCollector(ctx, s.eventRepository, req.DestinationAccountID,
-		reqContext.TransactionID, s.eventVersion)
+	screening, riskDecision, err := s.checkTransfer(ctx, req, reqContext.TransactionID)
 	if err != nil {
-		return fmt.Errorf("failed to create account event collector: %w", err)
+		return dto.TransferResponse{}, err
+	}
+
+	if err := s.recordRiskDecision(ctx, riskDecision); err != nil {
+		return dto.TransferResponse{}, err
+	}
+
+	if screening.isBlocked() || riskDecision.isDenied() {
+		return dto.TransferResponse{}, s.denyTransfer(ctx, reqContext.TransactionID, screening, riskDecision)
+	}
+
+	if s.approvalPolicy.Requires(req.Amount) || riskDecision.isReview() || screening.isHeld() {
+		return s.requestApproval(ctx, req, transferFee, reqContext.TransactionID, screening, riskDecision)
+	}
+
+	// process transfer within transaction, it is run again on a deadlock
+	err = s.accountRepository.WithRetryableTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := claimTransactionID(ctx, dbTx, s.idempotencyKeyClaimer, reqContext.TransactionID); err != nil {
+			return err
+		}
+
+		return s.transferTx(ctx, dbTx, req, transferFee, reqContext.TransactionID)
+	})
+	if err != nil {
+		return dto.TransferResponse{}, fmt.Errorf("failed to process transfer: %w", err)
+	}
+
+	return toTransferResponse(reqContext.TransactionID, req, transferFee, model.TransactionStatusCompleted), nil
+}
+
+// ApproveTransfer godoc
+// @Summary      Approve Transfer
+// @Description  Approve a transfer pending approval and execute it under its transaction ID, the approver
+// @Description  cannot be the initiator of the transfer
+// @Tags         Transfer
+// @ID           approveTransfer
+// @Produce      json
+// @Param        transaction_id	path		string	true	"Transaction ID"
+// @Success      200  {object}  dto.TransferResponse	"Transfer"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      403  {object}  dto.ErrorResponse	"Forbidden"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      422  {object}  dto.ErrorResponse	"Denied by the risk rules or blocked by the sanctions screening"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/{transaction_id}/approve [post].
+func (s *TransactionService) ApproveTransfer(ctx context.Context,
+	req dto.ReviewTransferRequest,
+) (dto.TransferResponse, error) {
+	transferApproval, err := s.transferApprovalRepository.FindByTransactionID(ctx, req.TransactionID)
+	if err != nil {
+		return dto.TransferResponse{}, fmt.Errorf("failed to find transfer approval: %w", err)
+	}
+
+	if err := checkTransferReviewable(transferApproval, req.ReviewedBy, time.Now()); err != nil {
+		return dto.TransferResponse{}, err
+	}
+
+	transferReq := dto.CreateTransferRequest{
+		SourceAccountID:      transferApproval.SourceAccountID,
+		DestinationAccountID: transferApproval.DestinationAccountID,
+		Amount:               transferApproval.Amount,
+		InitiatedBy:          transferApproval.InitiatedBy,
+	}
+
+	transferFee, err := s.calculateFee(ctx, transferReq)
+	if err != nil {
+		return dto.TransferResponse{}, err
+	}
+
+	// the sanctions list, the names of the parties and the state the risk rules look at may have changed since the
+	// transfer was held, a decision to hold it again is the review being made
+	screening, riskDecision, err := s.checkTransfer(ctx, transferReq, transferApproval.TransactionID)
+	if err != nil {
+		return dto.TransferResponse{}, err
+	}
+
+	if err := s.recordRiskDecision(ctx, riskDecision); err != nil {
+		return dto.TransferResponse{}, err
+	}
+
+	if screening.isBlocked() || riskDecision.isDenied() {
+		return dto.TransferResponse{}, s.refuseApproval(ctx, req, transferApproval, screening, riskDecision)
+	}
+
+	err = s.accountRepository.WithRetryableTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := s.recordSanctionsScreeningTx(ctx, dbTx, screening); err != nil {
+			return err
+		}
+
+		transferEventCollector, err := NewTransferEventCollector(ctx, s.eventRepository, transferApproval.ID,
+			transferApproval.TransactionID, s.eventVersion)
+		if err != nil {
+			return fmt.Errorf("failed to create transfer event collector: %w", err)
+		}
+
+		// the transfer is locked before the accounts, a concurrent review waits for this one
+		err = s.reviewTransferTx(ctx, dbTx, req, func(transferApproval *model.TransferApproval) {
+			transferApproval.Status = model.TransferApprovalStatusApproved
+			transferEventCollector.OnApprovedEvent(req.ReviewedBy)
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := transferEventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		return s.transferTx(ctx, dbTx, transferReq, transferFee, transferApproval.TransactionID)
+	})
+	if err != nil {
+		return dto.TransferResponse{}, fmt.Errorf("failed to approve transfer: %w", err)
+	}
+
+	return toTransferResponse(transferApproval.TransactionID, transferReq, transferFee,
+		model.TransactionStatusCompleted), nil
+}
+
+// refuseApproval rejects a transfer pending approval that is blocked by the sanctions screening or denied by the risk
+// rules when it is approved, the hits are recorded and the error of the refusal is returned.
+func (s *TransactionService) refuseApproval(ctx context.Context, req dto.ReviewTransferRequest,
+	transferApproval model.TransferApproval, screening *sanctionsScreening, riskDecision *riskAssessment,
+) error {
+	reason := riskDeniedReason
+	if screening.isBlocked() {
+		reason = sanctionsBlockedReason
+	}
+
+	err := s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := s.recordSanctionsScreeningTx(ctx, dbTx, screening); err != nil {
+			return err
+		}
+
+		transferEventCollector, err := NewTransferEventCollector(ctx, s.eventRepository, transferApproval.ID,
+			transferApproval.TransactionID, s.eventVersion)
+		if err != nil {
+			return fmt.Errorf("failed to create transfer event collector: %w", err)
+		}
+
+		err = s.reviewTransferTx(ctx, dbTx, req, func(transferApproval *model.TransferApproval) {
+			transferApproval.Status = model.TransferApprovalStatusRejected
+			transferApproval.RejectionReason = reason
+			transferEventCollector.OnRejectedEvent(req.ReviewedBy, reason)
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := transferEventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refuse transfer: %w", err)
+	}
+
+	return refusalError(screening, riskDecision)
+}
+
+// RejectTransfer godoc
+// @Summary      Reject Transfer
+// @Description  Reject a transfer pending approval, the transfer is not executed. The reviewer cannot be the
+// @Description  initiator of the transfer
+// @Tags         Transfer
+// @ID           rejectTransfer
+// @Produce      json
+// @Param        transaction_id	path		string	true	"Transaction ID"
+// @Param        req body reject transfer	body		dto.ReviewTransferRequest	false	"Rejection"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      403  {object}  dto.ErrorResponse	"Forbidden"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/{transaction_id}/reject [post].
+func (s *TransactionService) RejectTransfer(ctx context.Context, req dto.ReviewTransferRequest) error {
+	transferApproval, err := s.transferApprovalRepository.FindByTransactionID(ctx, req.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to find transfer approval: %w", err)
+	}
+
+	if err := checkTransferReviewable(transferApproval, req.ReviewedBy, time.Now()); err != nil {
+		return err
+	}
+
+	transferEventCollector, err := NewTransferEventCollector(ctx, s.eventRepository, transferApproval.ID,
+		transferApproval.TransactionID, s.eventVersion)
+	if err != nil {
+		return fmt.Errorf("failed to create transfer event collector: %w", err)
 	}
 
-	// process transfer within transaction
 	err = s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
-		// add event
-		sourceAccountEventCollector.OnSubBalanceEvent(req.SourceAccountID, req.Amount)
-		destinationAccountEventCollector.OnAddBalanceEvent(req.DestinationAccountID, req.Amount)
+		err := s.reviewTransferTx(ctx, dbTx, req, func(transferApproval *model.TransferApproval) {
+			transferApproval.Status = model.TransferApprovalStatusRejected
+			transferApproval.RejectionReason = req.Reason
+			transferEventCollector.OnRejectedEvent(req.ReviewedBy, req.Reason)
+		})
+		if err != nil {
+			return err
+		}
 
-		if err := sourceAccountEventCollector.Place(ctx, dbTx); err != nil {
+		if err := transferEventCollector.Place(ctx, dbTx); err != nil {
 			return fmt.Errorf("failed to place events: %w", err)
 		}
 
-		if err := destinationAccountEventCollector.Place(ctx, dbTx); err != nil {
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reject transfer: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireDue expires the transfers that were not approved in time.
+// It returns the number of transfers that were expired.
+func (s *TransactionService) ExpireDue(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	transferApprovals, err := s.transferApprovalRepository.FindAllExpired(ctx, now, s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired transfer approvals: %w", err)
+	}
+
+	expired := 0
+
+	for _, transferApproval := range transferApprovals {
+		done, err := s.expire(ctx, transferApproval, now)
+		if err != nil {
+			// keep going, the transfer is still expired and is retried on the next run
+			slog.ErrorContext(ctx, "failed to expire transfer approval",
+				slog.String("transaction_id", transferApproval.TransactionID),
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if done {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+func (s *TransactionService) expire(ctx context.Context, transferApproval model.TransferApproval,
+	now time.Time,
+) (bool, error) {
+	done := false
+
+	transferEventCollector, err := NewTransferEventCollector(ctx, s.eventRepository, transferApproval.ID,
+		transferApproval.TransactionID, s.eventVersion)
+	if err != nil {
+		return false, fmt.Errorf("failed to create transfer event collector: %w", err)
+	}
+
+	err = s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		transferApproval, err := s.transferApprovalRepository.FindByTransactionIDForUpdateTx(ctx, dbTx,
+			transferApproval.TransactionID)
+		if err != nil {
+			return fmt.Errorf("failed to find transfer approval: %w", err)
+		}
+
+		// reviewed in the meantime
+		if !transferApproval.IsPending() || !transferApproval.IsExpired(now) {
+			return nil
+		}
+
+		transferEventCollector.OnApprovalExpiredEvent(transferApproval)
+
+		transferApproval.Status = model.TransferApprovalStatusExpired
+		transferApproval.UpdatedAt = time.Now()
+
+		if err := transferEventCollector.Place(ctx, dbTx); err != nil {
 			return fmt.Errorf("failed to place events: %w", err)
 		}
 
-		// update projection
-		err = s.processTransfer(ctx, dbTx, req)
+		if err := s.transferApprovalRepository.UpdateTx(ctx, dbTx, &transferApproval); err != nil {
+			return fmt.Errorf("failed to update transfer approval: %w", err)
+		}
+
+		done = true
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to expire transfer approval: %w", err)
+	}
+
+	return done, nil
+}
+
+// requestApproval records a transfer pending approval, it is executed when another person approves it. The
+// initiator must be known, otherwise nobody could tell the approver apart from the initiator.
+func (s *TransactionService) requestApproval(ctx context.Context, req dto.CreateTransferRequest,
+	transferFee fee.Fee, transactionID string, screening *sanctionsScreening, riskDecision *riskAssessment,
+) (dto.TransferResponse, error) {
+	if req.InitiatedBy == "" {
+		return dto.TransferResponse{}, ErrTransferInitiatorRequired
+	}
+
+	_, err := s.accountRepository.FindByID(ctx, req.SourceAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return dto.TransferResponse{}, fmt.Errorf("failed to find account: %w", ErrSourceAccountNotFound)
+	}
+
+	if err != nil {
+		return dto.TransferResponse{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	_, err = s.accountRepository.FindByID(ctx, req.DestinationAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return dto.TransferResponse{}, fmt.Errorf("failed to find account: %w", ErrDestinationAccountNotFound)
+	}
+
+	if err != nil {
+		return dto.TransferResponse{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	now := time.Now()
+
+	transferApproval := model.TransferApproval{
+		TransactionID:        transactionID,
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		InitiatedBy:          req.InitiatedBy,
+		Status:               model.TransferApprovalStatusPending,
+		ExpiresAt:            now.Add(s.approvalPolicy.TTL),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	err = s.accountRepository.WithRetryableTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := claimTransactionID(ctx, dbTx, s.idempotencyKeyClaimer, transactionID); err != nil {
+			return err
+		}
+
+		if err := s.recordSanctionsScreeningTx(ctx, dbTx, screening); err != nil {
+			return err
+		}
+
+		if err := s.transferApprovalRepository.CreateTx(ctx, dbTx, &transferApproval); err != nil {
+			return fmt.Errorf("failed to create transfer approval: %w", err)
+		}
+
+		transferEventCollector, err := NewTransferEventCollector(ctx, s.eventRepository, transferApproval.ID,
+			transactionID, s.eventVersion)
 		if err != nil {
-			return fmt.Errorf("failed to process transfer: %w", err)
+			return fmt.Errorf("failed to create transfer event collector: %w", err)
+		}
+
+		transferEventCollector.OnApprovalRequestedEvent(transferApproval)
+
+		if err := transferEventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
 		}
 
 		return nil
 	})
+	if err != nil && errors.Is(err, exception.ErrRecordNotUnique) {
+		return dto.TransferResponse{}, ErrIdempotency
+	}
+
+	if err != nil {
+		return dto.TransferResponse{}, fmt.Errorf("failed to request transfer approval: %w", err)
+	}
+
+	resp := toTransferResponse(transactionID, req, transferFee, model.TransactionStatusPendingApproval)
+	resp.ExpiresAt = &transferApproval.ExpiresAt
+
+	return resp, nil
+}
+
+// reviewTransferTx locks a pending transfer, checks it can still be reviewed by the reviewer and records the
+// review applied by the review func.
+func (s *TransactionService) reviewTransferTx(ctx context.Context, dbTx *sql.Tx, req dto.ReviewTransferRequest,
+	review func(transferApproval *model.TransferApproval),
+) error {
+	transferApproval, err := s.transferApprovalRepository.FindByTransactionIDForUpdateTx(ctx, dbTx,
+		req.TransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to find transfer approval: %w", err)
+	}
+
+	now := time.Now()
+
+	if err := checkTransferReviewable(transferApproval, req.ReviewedBy, now); err != nil {
+		return err
+	}
+
+	review(&transferApproval)
+
+	transferApproval.ReviewedBy = &req.ReviewedBy
+	transferApproval.ReviewedAt = &now
+	transferApproval.UpdatedAt = now
+
+	if err := s.transferApprovalRepository.UpdateTx(ctx, dbTx, &transferApproval); err != nil {
+		return fmt.Errorf("failed to update transfer approval: %w", err)
+	}
+
+	return nil
+}
+
+// newTransferEventCollectors creates the event collectors of the source and destination accounts and, when a fee
+// is collected by another account, of the revenue account. The credits booked on a shard are recorded in the event
+// stream of the shard.
+func (s *TransactionService) newTransferEventCollectors(ctx context.Context, req dto.CreateTransferRequest,
+	transferFee fee.Fee, transactionID string, booking transferBooking,
+) ([]*AccountEventCollector, error) {
+	// set event collector source and destination account
+	sourceAccountEventCollector, err := NewAccountEventCollector(ctx, s.eventRepository, req.SourceAccountID,
+		transactionID, s.eventVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account event collector: %w", err)
+	}
+
+	destinationAccountEventCollector, err := s.newCreditEventCollector(ctx, req.DestinationAccountID,
+		booking.destinationShard, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventCollectors := []*AccountEventCollector{sourceAccountEventCollector, destinationAccountEventCollector}
+
+	// the fee is collected by the revenue account under the same transaction id
+	if transferFee.Amount.IsPositive() && s.feeSchedule.RevenueAccountID != req.DestinationAccountID {
+		revenueAccountEventCollector, err := s.newCreditEventCollector(ctx, s.feeSchedule.RevenueAccountID,
+			booking.revenueShard, transactionID)
+		if err != nil {
+			return nil, err
+		}
+
+		eventCollectors = append(eventCollectors, revenueAccountEventCollector)
+	}
+
+	return eventCollectors, nil
+}
+
+// newCreditEventCollector creates the event collector of a credited account, or of its shard when the credit was
+// booked on one.
+func (s *TransactionService) newCreditEventCollector(ctx context.Context, accountID int64,
+	shard *model.AccountShard, transactionID string,
+) (*AccountEventCollector, error) {
+	var (
+		eventCollector *AccountEventCollector
+		err            error
+	)
+
+	if shard != nil {
+		eventCollector, err = NewAccountShardEventCollector(ctx, s.eventRepository, *shard, transactionID,
+			s.eventVersion)
+	} else {
+		eventCollector, err = NewAccountEventCollector(ctx, s.eventRepository, accountID, transactionID,
+			s.eventVersion)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account event collector: %w", err)
+	}
+
+	return eventCollector, nil
+}
+
+// transferTx updates the projection and records the events of a transfer. The accounts are locked first, so the
+// events are numbered after the ones committed by the transfers that held the locks before.
+func (s *TransactionService) transferTx(ctx context.Context, dbTx *sql.Tx, req dto.CreateTransferRequest,
+	transferFee fee.Fee, transactionID string,
+) error {
+	// update projection
+	booking, err := s.processTransfer(ctx, dbTx, req, transferFee.Amount, transactionID)
 	if err != nil {
 		return fmt.Errorf("failed to process transfer: %w", err)
 	}
 
+	// the first two event collectors are the source and destination accounts and the last one collects the fee
+	eventCollectors, err := s.newTransferEventCollectors(ctx, req, transferFee, transactionID, booking)
+	if err != nil {
+		return err
+	}
+
+	sourceAccountEventCollector, destinationAccountEventCollector := eventCollectors[0], eventCollectors[1]
+
+	// add event
+	if len(booking.consolidatedShards) > 0 {
+		sourceAccountEventCollector.OnShardsConsolidatedEvent(booking.consolidatedShards)
+	}
+
+	sourceAccountEventCollector.OnSubBalanceEvent(req.DestinationAccountID, req.Amount)
+	destinationAccountEventCollector.OnAddBalanceEvent(req.SourceAccountID, req.Amount)
+
+	if transferFee.Amount.IsPositive() {
+		sourceAccountEventCollector.OnFeeChargedEvent(s.feeSchedule.RevenueAccountID, transferFee)
+		eventCollectors[len(eventCollectors)-1].OnFeeCollectedEvent(req.SourceAccountID, transferFee)
+	}
+
+	err = recordJournalEntry(ctx, dbTx, s.journalRepository, transactionID, eventCollectors...)
+	if err != nil {
+		return err
+	}
+
+	for _, eventCollector := range eventCollectors {
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func (s *TransactionService) processTransfer(ctx context.Context, dbTx *sql.Tx, req dto.CreateTransferRequest) error {
-	// find source account
-	sourceAccount, err := s.accountRepository.FindByIDForUpdateTx(ctx, dbTx, req.SourceAccountID)
+// GetTransaction godoc
+// @Summary      Get Transaction
+// @Description  Get the operation, accounts, amounts, status and timestamps recorded under a transaction ID
+// @Tags         Transfer
+// @ID           getTransaction
+// @Produce      json
+// @Param        transaction_id	path		string	true	"Transaction ID"
+// @Success      200  {object}  dto.TransactionResponse	"Transaction"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /transactions/{transaction_id} [get].
+func (s *TransactionService) GetTransaction(ctx context.Context,
+	req dto.GetTransactionRequest,
+) (dto.TransactionResponse, error) {
+	events, err := s.eventRepository.FindAllByTransactionID(ctx, req.TransactionID)
 	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
-		err = ErrSourceAccountNotFound
+		err := exception.ErrRecordNotFound
+		err.MessageVars = map[string]interface{}{
+			"name": "transaction",
+		}
 
-		return fmt.Errorf("failed to find account: %w", err)
+		return dto.TransactionResponse{}, fmt.Errorf("transaction not found: %w", err)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to find account: %w", err)
+		return dto.TransactionResponse{}, fmt.Errorf("failed to find events: %w", err)
+	}
+
+	transaction, err := model.NewTransaction(req.TransactionID, events)
+	if err != nil {
+		return dto.TransactionResponse{}, fmt.Errorf("failed to reconstruct transaction: %w", err)
+	}
+
+	resp := dto.TransactionResponse{
+		TransactionID:        transaction.ID,
+		Operation:            string(transaction.Operation),
+		Status:               string(transaction.Status),
+		AccountIDs:           transaction.AccountIDs,
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount,
+		Fee:                  transaction.Fee,
+		Events:               make([]dto.TransactionEventResponse, 0, len(transaction.Events)),
+		CreatedAt:            transaction.CreatedAt,
+		CompletedAt:          transaction.CompletedAt,
+	}
+
+	for _, event := range transaction.Events {
+		eventData, _ := event.EventData.([]byte)
+
+		resp.Events = append(resp.Events, dto.TransactionEventResponse{
+			AggregateType:  string(event.AggregateType),
+			AggregateID:    event.AggregateID,
+			SequenceNumber: event.SequenceNumber,
+			EventType:      string(event.EventType),
+			EventData:      eventData,
+			CreatedAt:      event.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// riskAssessment is the decision of the risk rules on a transfer and the rule that made it.
+type riskAssessment struct {
+	model.RiskDecision
+	decision risk.Decision
+}
+
+func (a *riskAssessment) isDenied() bool {
+	return a != nil && a.Action == model.RiskActionDeny
+}
+
+func (a *riskAssessment) isReview() bool {
+	return a != nil && a.Action == model.RiskActionReview
+}
+
+// denialError returns the error of a denied transfer, the error of the rule when it has one.
+func (a *riskAssessment) denialError() error {
+	err := ErrTransferDenied
+	err.MessageVars = map[string]interface{}{
+		"rule": a.decision.Rule,
+	}
+
+	if a.decision.MessageID != "" {
+		err.MessageID = a.decision.MessageID
+	}
+
+	if a.decision.Message != "" {
+		err.Message = a.decision.Message
+	}
+
+	return err
+}
+
+// assessRisk evaluates the risk rules on the transfer, it returns nil when the risk rules are disabled.
+func (s *TransactionService) assessRisk(ctx context.Context, req dto.CreateTransferRequest,
+	transactionID string,
+) (*riskAssessment, error) {
+	if s.riskEvaluator == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	sourceAccount, destinationAccount, err := s.findTransferAccounts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	decision := s.riskEvaluator.Evaluate(risk.Transfer{
+		Amount:      req.Amount,
+		InitiatedBy: req.InitiatedBy,
+		Source:      toRiskAccount(sourceAccount),
+		Destination: toRiskAccount(destinationAccount),
+		At:          now,
+	})
+
+	return &riskAssessment{
+		RiskDecision: model.RiskDecision{
+			TransactionID:        transactionID,
+			SourceAccountID:      req.SourceAccountID,
+			DestinationAccountID: req.DestinationAccountID,
+			Amount:               req.Amount,
+			Action:               model.RiskAction(decision.Action),
+			Rule:                 decision.Rule,
+			CreatedAt:            now,
+		},
+		decision: decision,
+	}, nil
+}
+
+// denyTransfer records the denial of a transfer blocked by the sanctions screening or denied by the risk rules under
+// its transaction id and returns the error of the denial.
+func (s *TransactionService) denyTransfer(ctx context.Context, transactionID string, screening *sanctionsScreening,
+	riskDecision *riskAssessment,
+) error {
+	err := s.accountRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := claimTransactionID(ctx, dbTx, s.idempotencyKeyClaimer, transactionID); err != nil {
+			return err
+		}
+
+		return s.recordSanctionsScreeningTx(ctx, dbTx, screening)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to deny transfer: %w", err)
+	}
+
+	return refusalError(screening, riskDecision)
+}
+
+// refusalError returns the error of a transfer blocked by the sanctions screening or denied by the risk rules.
+func refusalError(screening *sanctionsScreening, riskDecision *riskAssessment) error {
+	if screening.isBlocked() {
+		return fmt.Errorf("%w: %w", ErrTransferBlocked, errTransferRefused)
+	}
+
+	return fmt.Errorf("%w: %w", riskDecision.denialError(), errTransferRefused)
+}
+
+// recordRiskDecision stores the decision of the risk rules and its event in a transaction of its own, before the
+// transfer is denied, held or executed, so that the decision is kept when the transfer fails afterwards. Nothing is
+// recorded when the risk rules are disabled.
+func (s *TransactionService) recordRiskDecision(ctx context.Context, riskDecision *riskAssessment) error {
+	if riskDecision == nil {
+		return nil
+	}
+
+	err := s.accountRepository.WithRetryableTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		if err := s.riskDecisionRepository.UpsertTx(ctx, dbTx, &riskDecision.RiskDecision); err != nil {
+			return fmt.Errorf("failed to upsert risk decision: %w", err)
+		}
+
+		eventCollector, err := NewRiskDecisionEventCollector(ctx, s.eventRepository, riskDecision.ID,
+			riskDecision.TransactionID, s.eventVersion)
+		if err != nil {
+			return fmt.Errorf("failed to create risk decision event collector: %w", err)
+		}
+
+		eventCollector.OnAssessedEvent(riskDecision.RiskDecision)
+
+		if err := eventCollector.Place(ctx, dbTx); err != nil {
+			return fmt.Errorf("failed to place events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record risk decision: %w", err)
+	}
+
+	return nil
+}
+
+// isTransferRetryable reports whether a transfer can run under a transaction id that already has events: only the
+// risk rules allowed or held the transfer, which then failed before it was recorded.
+func isTransferRetryable(events []model.Event) bool {
+	for _, event := range events {
+		if event.AggregateType != model.AggregateTypeRiskDecision {
+			return false
+		}
+
+		var assessment struct {
+			Action model.RiskAction `json:"action"`
+		}
+
+		eventData, _ := event.EventData.([]byte)
+		if err := json.Unmarshal(eventData, &assessment); err != nil || assessment.Action == model.RiskActionDeny {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkTransfer screens the parties of the transfer against the sanctions list then, unless the transfer is
+// blocked, assesses it with the risk rules.
+func (s *TransactionService) checkTransfer(ctx context.Context, req dto.CreateTransferRequest,
+	transactionID string,
+) (*sanctionsScreening, *riskAssessment, error) {
+	screening, err := s.screenSanctions(ctx, req, transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if screening.isBlocked() {
+		return screening, nil, nil
+	}
+
+	riskDecision, err := s.assessRisk(ctx, req, transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return screening, riskDecision, nil
+}
+
+// sanctionsScreening is a transfer whose parties matched the sanctions list, it is nil when no party matched.
+type sanctionsScreening struct {
+	model.SanctionsScreening
+}
+
+func (s *sanctionsScreening) isBlocked() bool {
+	return s != nil && s.Action == model.SanctionsActionBlock
+}
+
+func (s *sanctionsScreening) isHeld() bool {
+	return s != nil && s.Action == model.SanctionsActionHold
+}
+
+// screenSanctions screens the names of the parties of the transfer against the sanctions list, it returns nil when
+// no party matched or the screening is disabled. The system accounts of the bank are not screened, a party without
+// a name cannot be screened and holds the transfer for a person to check it.
+func (s *TransactionService) screenSanctions(ctx context.Context, req dto.CreateTransferRequest,
+	transactionID string,
+) (*sanctionsScreening, error) {
+	if s.sanctionsScreener == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	sourceAccount, destinationAccount, err := s.findTransferAccounts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	list := s.sanctionsScreener.List()
+	action := model.SanctionsActionHold
+
+	var hits []model.SanctionsHit
+
+	for _, account := range []model.Account{sourceAccount, destinationAccount} {
+		if s.sanctionsPolicy.Exempts(account.ID) {
+			continue
+		}
+
+		if account.DisplayName == "" {
+			hits = append(hits, model.SanctionsHit{AccountID: account.ID})
+
+			continue
+		}
+
+		match, ok := list.Screen(account.DisplayName, s.sanctionsPolicy.Threshold())
+		if !ok {
+			continue
+		}
+
+		hits = append(hits, model.SanctionsHit{
+			AccountID:   account.ID,
+			AccountName: account.DisplayName,
+			EntryUID:    match.Entry.UID,
+			EntryName:   match.Name,
+			Program:     match.Entry.Program,
+			Score:       match.Score,
+		})
+
+		if s.sanctionsPolicy.Action(match.Score) == model.SanctionsActionBlock {
+			action = model.SanctionsActionBlock
+		}
+	}
+
+	if len(hits) == 0 {
+		return nil, nil //nolint:nilnil
 	}
 
-	// find destination account
-	destinationAccount, err := s.accountRepository.FindByIDForUpdateTx(ctx, dbTx, req.DestinationAccountID)
+	return &sanctionsScreening{
+		SanctionsScreening: model.SanctionsScreening{
+			TransactionID:        transactionID,
+			SourceAccountID:      req.SourceAccountID,
+			DestinationAccountID: req.DestinationAccountID,
+			Amount:               req.Amount,
+			Action:               action,
+			ListVersion:          list.Version,
+			Hits:                 hits,
+			CreatedAt:            time.Now(),
+		},
+	}, nil
+}
+
+// recordSanctionsScreeningTx stores the hits of the sanctions screening and their event, nothing is recorded when
+// no party matched.
+func (s *TransactionService) recordSanctionsScreeningTx(ctx context.Context, dbTx *sql.Tx,
+	screening *sanctionsScreening,
+) error {
+	if screening == nil {
+		return nil
+	}
+
+	if err := s.sanctionsRepository.UpsertScreeningTx(ctx, dbTx, &screening.SanctionsScreening); err != nil {
+		return fmt.Errorf("failed to upsert sanctions screening: %w", err)
+	}
+
+	eventCollector, err := NewSanctionsScreeningEventCollector(ctx, s.eventRepository, screening.ID,
+		screening.TransactionID, s.eventVersion)
+	if err != nil {
+		return fmt.Errorf("failed to create sanctions screening event collector: %w", err)
+	}
+
+	eventCollector.OnHitEvent(screening.SanctionsScreening)
+
+	if err := eventCollector.Place(ctx, dbTx); err != nil {
+		return fmt.Errorf("failed to place events: %w", err)
+	}
+
+	return nil
+}
+
+// findTransferAccounts returns the source and destination accounts of a transfer.
+func (s *TransactionService) findTransferAccounts(ctx context.Context, req dto.CreateTransferRequest,
+) (model.Account, model.Account, error) {
+	sourceAccount, err := s.accountRepository.FindByID(ctx, req.SourceAccountID)
 	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
-		err = ErrDestinationAccountNotFound
+		return model.Account{}, model.Account{}, fmt.Errorf("failed to find account: %w", ErrSourceAccountNotFound)
+	}
 
-		return fmt.Errorf("failed to find account: %w", err)
+	if err != nil {
+		return model.Account{}, model.Account{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	destinationAccount, err := s.accountRepository.FindByID(ctx, req.DestinationAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		return model.Account{}, model.Account{}, fmt.Errorf("failed to find account: %w",
+			ErrDestinationAccountNotFound)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to find account: %w", err)
+		return model.Account{}, model.Account{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	return sourceAccount, destinationAccount, nil
+}
+
+func toRiskAccount(account model.Account) risk.Account {
+	return risk.Account{
+		ID:        account.ID,
+		Type:      string(account.Type),
+		Status:    string(account.Status),
+		Currency:  account.Currency,
+		Balance:   account.Balance,
+		CreatedAt: account.CreatedAt,
+	}
+}
+
+// calculateFee returns the fee charged to the source account, it depends on the source account type. The revenue
+// account is never charged a fee, so a fee is never collected from the account it is credited to.
+func (s *TransactionService) calculateFee(ctx context.Context, req dto.CreateTransferRequest) (fee.Fee, error) {
+	if s.feeSchedule == nil || req.SourceAccountID == s.feeSchedule.RevenueAccountID {
+		return fee.Fee{Amount: decimal.Zero}, nil
+	}
+
+	sourceAccount, err := s.accountRepository.FindByID(ctx, req.SourceAccountID)
+	if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+		err = ErrSourceAccountNotFound
+
+		return fee.Fee{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	if err != nil {
+		return fee.Fee{}, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	return s.feeSchedule.Calculate(req.Amount, string(sourceAccount.Type)), nil
+}
+
+func (s *TransactionService) processTransfer(ctx context.Context, dbTx *sql.Tx, req dto.CreateTransferRequest,
+	feeAmount decimal.Decimal, transactionID string,
+) (transferBooking, error) {
+	var booking transferBooking
+
+	revenueAccountID, collectsFee := s.feeRevenueAccountID(req, feeAmount)
+
+	accounts, err := s.lockTransferAccounts(ctx, dbTx, req, revenueAccountID, collectsFee)
+	if err != nil {
+		return booking, err
+	}
+
+	sourceAccount, destinationAccount := accounts[req.SourceAccountID], accounts[req.DestinationAccountID]
+
+	// frozen and closed accounts can neither send nor receive funds
+	if err := checkAccountOperable(sourceAccount); err != nil {
+		return booking, fmt.Errorf("source account: %w", err)
+	}
+
+	if err := checkAccountOperable(destinationAccount); err != nil {
+		return booking, fmt.Errorf("destination account: %w", err)
+	}
+
+	if sourceAccount.Currency != destinationAccount.Currency {
+		return booking, ErrCurrencyMismatch
+	}
+
+	// velocity and amount limits, evaluated while the source account is locked
+	err = s.transferLimiter.CheckTx(ctx, dbTx, req.SourceAccountID, req.Amount, transactionID)
+	if err != nil {
+		return booking, fmt.Errorf("transfer limit: %w", err)
+	}
+
+	// the credits of a hot account wait in its shards until its own balance does not cover a debit
+	totalDebited := req.Amount.Add(feeAmount)
+	if sourceAccount.AvailableBalance().LessThan(totalDebited) {
+		booking.consolidatedShards, err = consolidateShardsTx(ctx, dbTx, s.accountShardRepository, &sourceAccount)
+		if err != nil {
+			return booking, err
+		}
 	}
 
-	// validate balance
-	if sourceAccount.Balance.LessThan(req.Amount) {
+	// validate balance, the approved overdraft can be drawn
+	if sourceAccount.AvailableBalance().LessThan(totalDebited) {
 		err = ErrInsufficientBalance
 
-		return fmt.Errorf("insufficient balance: %w", err)
+		return booking, fmt.Errorf("insufficient balance: %w", err)
 	}
 
 	// update balance
-	sourceAccount.Balance = sourceAccount.Balance.Sub(req.Amount)
-	destinationAccount.Balance = destinationAccount.Balance.Add(req.Amount)
+	sourceAccount.Balance = sourceAccount.Balance.Sub(totalDebited)
 	sourceAccount.UpdatedAt = time.Now()
-	destinationAccount.UpdatedAt = time.Now()
 
 	if err := s.accountRepository.UpsertTx(ctx, dbTx, &sourceAccount); err != nil {
-		return fmt.Errorf("failed to upsert account: %w", err)
+		return booking, fmt.Errorf("failed to upsert account: %w", err)
 	}
 
-	if err := s.accountRepository.UpsertTx(ctx, dbTx, &destinationAccount); err != nil {
-		return fmt.Errorf("failed to upsert account: %w", err)
+	credited := req.Amount
+	if feeAmount.IsPositive() && s.feeSchedule.RevenueAccountID == destinationAccount.ID {
+		credited = credited.Add(feeAmount)
 	}
 
-	return nil
+	booking.destinationShard, err = s.creditAccountTx(ctx, dbTx, destinationAccount, credited)
+	if err != nil {
+		return booking, err
+	}
+
+	if collectsFee {
+		booking.revenueShard, err = s.collectFee(ctx, dbTx, accounts[revenueAccountID], feeAmount)
+		if err != nil {
+			return booking, err
+		}
+	}
+
+	return booking, nil
+}
+
+// feeRevenueAccountID returns the revenue account the fee is credited to apart from the transfer, a fee paid to the
+// destination account is credited with the amount.
+func (s *TransactionService) feeRevenueAccountID(req dto.CreateTransferRequest,
+	feeAmount decimal.Decimal,
+) (int64, bool) {
+	if !feeAmount.IsPositive() || s.feeSchedule.RevenueAccountID == req.DestinationAccountID {
+		return 0, false
+	}
+
+	return s.feeSchedule.RevenueAccountID, true
+}
+
+// lockTransferAccounts locks the source and destination accounts, and the revenue account when the transfer collects
+// a fee, in the order of their ids, so transfers in opposite directions or sharing the revenue account wait for each
+// other instead of deadlocking. A hot account that is only credited is locked for share, the transfers to it only
+// wait for the shard they credit.
+func (s *TransactionService) lockTransferAccounts(ctx context.Context, dbTx *sql.Tx,
+	req dto.CreateTransferRequest, revenueAccountID int64, collectsFee bool,
+) (map[int64]model.Account, error) {
+	accountIDs := []int64{req.SourceAccountID, req.DestinationAccountID}
+	if collectsFee {
+		accountIDs = append(accountIDs, revenueAccountID)
+	}
+
+	lock := func(ctx context.Context, dbTx *sql.Tx, accountID int64) (model.Account, error) {
+		if accountID != req.SourceAccountID && s.hotAccountPolicy.IsHot(accountID) {
+			return s.accountRepository.FindByIDForShareTx(ctx, dbTx, accountID)
+		}
+
+		return s.accountRepository.FindByIDForUpdateTx(ctx, dbTx, accountID)
+	}
+
+	return lockAccountsTx(ctx, dbTx, accountIDs, lock, func(accountID int64, err error) error {
+		return lockError(req, accountID, err)
+	})
+}
+
+// accountLock locks an account row within a database transaction.
+type accountLock func(ctx context.Context, dbTx *sql.Tx, accountID int64) (model.Account, error)
+
+// lockAccountsTx locks the accounts of a movement in the order of their ids, every movement between several
+// accounts locks them this way so that concurrent movements wait for each other instead of deadlocking. lockErr
+// names the account that could not be locked.
+func lockAccountsTx(ctx context.Context, dbTx *sql.Tx, accountIDs []int64, lock accountLock,
+	lockErr func(accountID int64, err error) error,
+) (map[int64]model.Account, error) {
+	lockOrder := slices.Clone(accountIDs)
+
+	slices.Sort(lockOrder)
+	lockOrder = slices.Compact(lockOrder)
+
+	accounts := make(map[int64]model.Account, len(lockOrder))
+
+	for _, accountID := range lockOrder {
+		account, err := lock(ctx, dbTx, accountID)
+		if err != nil {
+			return nil, lockErr(accountID, err)
+		}
+
+		accounts[accountID] = account
+	}
+
+	return accounts, nil
+}
+
+// lockError names the account of the transfer that could not be locked.
+func lockError(req dto.CreateTransferRequest, accountID int64, err error) error {
+	switch {
+	case !errors.Is(err, exception.ErrRecordNotFound):
+		return fmt.Errorf("failed to find account: %w", err)
+	case accountID == req.SourceAccountID:
+		return fmt.Errorf("failed to find account: %w", ErrSourceAccountNotFound)
+	case accountID == req.DestinationAccountID:
+		return fmt.Errorf("failed to find account: %w", ErrDestinationAccountNotFound)
+	default:
+		return fmt.Errorf("failed to find revenue account: %w", err)
+	}
+}
+
+// collectFee credits the fee to the revenue account, it is locked by lockTransferAccounts with the parties of the
+// transfer.
+func (s *TransactionService) collectFee(ctx context.Context, dbTx *sql.Tx, revenueAccount model.Account,
+	feeAmount decimal.Decimal,
+) (*model.AccountShard, error) {
+	if err := checkAccountOperable(revenueAccount); err != nil {
+		return nil, fmt.Errorf("revenue account: %w", err)
+	}
+
+	return s.creditAccountTx(ctx, dbTx, revenueAccount, feeAmount)
+}
+
+// creditAccountTx adds the amount to the balance of an account, or to a shard picked at random when the account is
+// hot. It returns the credited shard, nil when the account itself was credited.
+func (s *TransactionService) creditAccountTx(ctx context.Context, dbTx *sql.Tx, account model.Account,
+	amount decimal.Decimal,
+) (*model.AccountShard, error) {
+	if s.hotAccountPolicy.IsHot(account.ID) {
+		shard, err := s.accountShardRepository.CreditTx(ctx, dbTx, account.ID, s.hotAccountPolicy.PickShard(),
+			amount, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to credit account shard: %w", err)
+		}
+
+		return &shard, nil
+	}
+
+	account.Balance = account.Balance.Add(amount)
+	account.UpdatedAt = time.Now()
+
+	if err := s.accountRepository.UpsertTx(ctx, dbTx, &account); err != nil {
+		return nil, fmt.Errorf("failed to upsert account: %w", err)
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+// checkTransferReviewable rejects the review of a transfer that is not pending anymore, that has expired or whose
+// reviewer is the initiator.
+func checkTransferReviewable(transferApproval model.TransferApproval, reviewer string, now time.Time) error {
+	switch {
+	case !transferApproval.IsPending():
+		return ErrTransferNotPendingApproval
+	case transferApproval.IsExpired(now):
+		return ErrTransferApprovalExpired
+	case !transferApproval.CanBeReviewedBy(reviewer):
+		return ErrTransferSelfReview
+	default:
+		return nil
+	}
+}
+
+func toTransferResponse(transactionID string, req dto.CreateTransferRequest, transferFee fee.Fee,
+	status model.TransactionStatus,
+) dto.TransferResponse {
+	return dto.TransferResponse{
+		TransactionID:        transactionID,
+		SourceAccountID:      req.SourceAccountID,
+		DestinationAccountID: req.DestinationAccountID,
+		Amount:               req.Amount,
+		Fee:                  transferFee.Amount,
+		FeeRule:              transferFee.Rule,
+		TotalDebited:         req.Amount.Add(transferFee.Amount),
+		Status:               string(status),
+	}
 }
@@ -12,6 +12,9 @@ import (
 	"github.com/ijalalfrz/go-event-source/internal/app/dto"
 	"github.com/ijalalfrz/go-event-source/internal/app/model"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/fee"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/risk"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/sanctions"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
@@ -25,7 +28,7 @@ func TestTransactionService_Transfer(t *testing.T) {
 		wantErr error,
 	) func(t *testing.T) {
 		return func(t *testing.T) {
-			got := svc.Transfer(ctx, req)
+			_, got := svc.Transfer(ctx, req)
 
 			if wantErr != nil {
 				assert.Error(t, got)
@@ -42,8 +45,10 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
-		accountRepository: &accountRepositoryMock{},
-		eventRepository:   &eventRepositoryMock{},
+		journalRepository:     &ledgerRepositoryMock{},
+		accountRepository:     &accountRepositoryMock{},
+		eventRepository:       &eventRepositoryMock{},
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, context.Background(), fmt.Errorf("request context not found")))
 
 	ctx := createContextWithRequestContext(dto.RequestContext{
@@ -59,7 +64,9 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil},
 		},
@@ -71,7 +78,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, ErrIdempotency))
 
 	// error source and destination account same
@@ -80,14 +88,17 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 1, // Same account
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, ErrSourceAndDestinationAccountSame))
 
 	// error find events for idempotency check
@@ -96,14 +107,17 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{errors.New("internal db error")},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
 	// error same source and destination account
@@ -112,13 +126,16 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 1,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
 		},
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, ErrSourceAndDestinationAccountSame))
 
 	// error create source account event collector
@@ -127,9 +144,16 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
-			errFindByIDForUpdateTx: []error{nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.NewFromInt(1000),
+			},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
@@ -141,7 +165,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
 	// error create destination account event collector
@@ -150,9 +175,16 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
-			errFindByIDForUpdateTx: []error{nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.NewFromInt(1000),
+			},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
@@ -164,7 +196,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
 	// error place source account events
@@ -173,9 +206,16 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
-			errFindByIDForUpdateTx: []error{nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.NewFromInt(1000),
+			},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
@@ -192,7 +232,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
 	// error place destination account events
@@ -201,9 +242,16 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
-			errFindByIDForUpdateTx: []error{nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.NewFromInt(1000),
+			},
 		},
 		eventRepository: &eventRepositoryMock{
 			errFindAllByTransactionID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
@@ -220,7 +268,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
 	// error source account not found
@@ -229,7 +278,9 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{exception.ErrRecordNotFound},
 		},
@@ -248,7 +299,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, ErrSourceAccountNotFound))
 
 	// error destination account not found
@@ -257,7 +309,9 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil, exception.ErrRecordNotFound},
 		},
@@ -276,7 +330,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, ErrDestinationAccountNotFound))
 
 	// error insufficient balance
@@ -285,7 +340,9 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(1000), // More than available balance
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil, nil},
 			account: model.Account{
@@ -308,16 +365,181 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:           "1.0.0",
+		idempotencyKeyClaimer:  &idempotencyKeyRepositoryMock{},
+		accountShardRepository: &accountShardRepositoryMock{},
+	}, ctx, ErrInsufficientBalance))
+
+	// error amount above the overdraft limit
+	t.Run("error_overdraft_limit_exceeded", testTransfer(dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(801),
+	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
+		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
+		accountRepository: &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			account: model.Account{
+				ID:             1,
+				Balance:        decimal.NewFromInt(500),
+				OverdraftLimit: decimal.NewFromInt(300),
+			},
+		},
+		eventRepository: &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil, nil},
+			events: []model.Event{
+				{
+					AggregateID:    1,
+					SequenceNumber: 1,
+				},
+			},
+		},
+		eventVersion:           "1.0.0",
+		idempotencyKeyClaimer:  &idempotencyKeyRepositoryMock{},
+		accountShardRepository: &accountShardRepositoryMock{},
 	}, ctx, ErrInsufficientBalance))
 
+	// success drawing the overdraft
+	t.Run("success_within_overdraft_limit", testTransfer(dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(800),
+	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
+		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
+		accountRepository: &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:             1,
+				Balance:        decimal.NewFromInt(500),
+				OverdraftLimit: decimal.NewFromInt(300),
+			},
+		},
+		eventRepository: &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil, nil},
+			events: []model.Event{
+				{
+					AggregateID:    1,
+					SequenceNumber: 1,
+				},
+			},
+		},
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
+	}, ctx, nil))
+
+	// error transfer limit exceeded
+	t.Run("error_transfer_limit_exceeded", testTransfer(dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
+		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{errCheckTx: ErrDailyLimitExceeded},
+		accountRepository: &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.NewFromInt(500),
+			},
+		},
+		eventRepository: &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil, nil},
+			events: []model.Event{
+				{
+					AggregateID:    1,
+					SequenceNumber: 1,
+				},
+			},
+		},
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
+	}, ctx, ErrDailyLimitExceeded))
+
+	// error frozen account
+	t.Run("error_account_frozen", testTransfer(dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
+		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
+		accountRepository: &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.NewFromInt(500),
+				Status:  model.AccountStatusFrozen,
+			},
+		},
+		eventRepository: &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil, nil},
+			events: []model.Event{
+				{
+					AggregateID:    1,
+					SequenceNumber: 1,
+				},
+			},
+		},
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
+	}, ctx, ErrAccountFrozen))
+
+	// error closed account
+	t.Run("error_account_closed", testTransfer(dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
+		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
+		accountRepository: &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			account: model.Account{
+				ID:      1,
+				Balance: decimal.NewFromInt(500),
+				Status:  model.AccountStatusClosed,
+			},
+		},
+		eventRepository: &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil, nil},
+			events: []model.Event{
+				{
+					AggregateID:    1,
+					SequenceNumber: 1,
+				},
+			},
+		},
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
+	}, ctx, ErrAccountClosed))
+
 	// error upsert source account
 	t.Run("error_upsert_source_account", testTransfer(dto.CreateTransferRequest{
 		SourceAccountID:      1,
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil, nil},
 			errUpsertTx:            []error{errors.New("internal db error"), nil},
@@ -341,7 +563,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
 	// error upsert destination account
@@ -350,7 +573,9 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil, nil},
 			errUpsertTx:            []error{nil, errors.New("internal db error")},
@@ -374,7 +599,8 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, errors.New("internal db error")))
 
 	// success
@@ -383,7 +609,9 @@ func TestTransactionService_Transfer(t *testing.T) {
 		DestinationAccountID: 2,
 		Amount:               decimal.NewFromInt(100),
 	}, &TransactionService{
+		journalRepository:    &ledgerRepositoryMock{},
 		requestTimeThreshold: 30 * time.Second,
+		transferLimiter:      &transferLimiterMock{},
 		accountRepository: &accountRepositoryMock{
 			errFindByIDForUpdateTx: []error{nil, nil},
 			errUpsertTx:            []error{nil, nil},
@@ -407,6 +635,1533 @@ func TestTransactionService_Transfer(t *testing.T) {
 				},
 			},
 		},
-		eventVersion: "1.0.0",
+		eventVersion:          "1.0.0",
+		idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
 	}, ctx, nil))
+
+	// the accounts are locked in the order of their ids, whatever the direction of the transfer
+	t.Run("success_locks_lower_account_id_first", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:      2,
+				Balance: decimal.NewFromInt(1000),
+			},
+		}
+		svc := &TransactionService{
+			journalRepository:    &ledgerRepositoryMock{},
+			requestTimeThreshold: 30 * time.Second,
+			transferLimiter:      &transferLimiterMock{},
+			accountRepository:    accountRepository,
+			eventRepository: &eventRepositoryMock{
+				errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+				errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+				errCreateBulkTx:           []error{nil, nil},
+				events:                    []model.Event{{}},
+			},
+			eventVersion:          "1.0.0",
+			idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{},
+		}
+
+		_, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      2,
+			DestinationAccountID: 1,
+			Amount:               decimal.NewFromInt(100),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, accountRepository.lockedIDs)
+	})
+
+	// a concurrent request claimed the transaction id first, nothing is written
+	t.Run("error_idempotency_claimed", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			events:                    []model.Event{{}},
+		}
+		svc := &TransactionService{
+			journalRepository:    &ledgerRepositoryMock{},
+			requestTimeThreshold: 30 * time.Second,
+			transferLimiter:      &transferLimiterMock{},
+			accountRepository:    accountRepository,
+			eventRepository:      eventRepository,
+			eventVersion:         "1.0.0",
+			idempotencyKeyClaimer: &idempotencyKeyRepositoryMock{
+				errClaimTx: fmt.Errorf("failed to exec statement: %w", exception.ErrRecordNotUnique),
+			},
+		}
+
+		_, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(100),
+		})
+		assert.ErrorIs(t, err, ErrIdempotency)
+		assert.Empty(t, eventRepository.placedEvents)
+		assert.Empty(t, accountRepository.upserted)
+	})
+}
+
+func TestTransactionService_TransferWithFee(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Signature:     "test-signature",
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	schedule := &fee.Schedule{
+		RevenueAccountID: 9,
+		Rules: []fee.Rule{
+			{
+				Name:         "business_percentage",
+				Kind:         fee.KindPercentage,
+				AccountTypes: []string{"business"},
+				Rate:         decimal.NewFromInt(1),
+				MinFee:       decimal.NewFromInt(2),
+			},
+		},
+	}
+
+	newService := func(account model.Account,
+	) (*TransactionService, *accountRepositoryMock, *eventRepositoryMock, *ledgerRepositoryMock) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{nil},
+			errFindByIDForUpdateTx: []error{nil, nil, nil},
+			errUpsertTx:            []error{nil, nil, nil},
+			account:                account,
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil, nil},
+			events:                    []model.Event{{}},
+		}
+		ledgerRepository := &ledgerRepositoryMock{}
+
+		return &TransactionService{
+			requestTimeThreshold:   30 * time.Second,
+			transferLimiter:        &transferLimiterMock{},
+			feeSchedule:            schedule,
+			accountRepository:      accountRepository,
+			eventRepository:        eventRepository,
+			journalRepository:      ledgerRepository,
+			eventVersion:           "1.0.0",
+			idempotencyKeyClaimer:  &idempotencyKeyRepositoryMock{},
+			accountShardRepository: &accountShardRepositoryMock{},
+		}, accountRepository, eventRepository, ledgerRepository
+	}
+
+	t.Run("success_revenue_account_locked_in_id_order", func(t *testing.T) {
+		svc, accountRepository, _, _ := newService(model.Account{
+			ID:      12,
+			Type:    model.AccountTypeBusiness,
+			Balance: decimal.NewFromInt(1000),
+		})
+
+		_, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      12,
+			DestinationAccountID: 10,
+			Amount:               decimal.NewFromInt(500),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{9, 10, 12}, accountRepository.lockedIDs)
+	})
+
+	t.Run("success_fee_charged", func(t *testing.T) {
+		svc, accountRepository, eventRepository, ledgerRepository := newService(model.Account{
+			ID:      1,
+			Type:    model.AccountTypeBusiness,
+			Balance: decimal.NewFromInt(1000),
+		})
+
+		resp, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(500),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "tx-12345", resp.TransactionID)
+		assert.True(t, decimal.NewFromInt(5).Equal(resp.Fee))
+		assert.Equal(t, "business_percentage", resp.FeeRule)
+		assert.True(t, decimal.NewFromInt(505).Equal(resp.TotalDebited))
+
+		// source, destination and revenue account
+		assert.Len(t, accountRepository.upserted, 3)
+		assert.True(t, decimal.NewFromInt(495).Equal(accountRepository.upserted[0].Balance))
+		assert.True(t, decimal.NewFromInt(1500).Equal(accountRepository.upserted[1].Balance))
+		assert.True(t, decimal.NewFromInt(1005).Equal(accountRepository.upserted[2].Balance))
+
+		eventTypes := make([]model.EventType, 0, len(eventRepository.placedEvents))
+		for _, event := range eventRepository.placedEvents {
+			assert.Equal(t, "tx-12345", event.TransactionID)
+			eventTypes = append(eventTypes, event.EventType)
+		}
+
+		assert.Equal(t, []model.EventType{
+			model.EventTypeDebitBalance, model.EventTypeFeeCharged,
+			model.EventTypeCreditBalance, model.EventTypeFeeCollected,
+		}, eventTypes)
+		assert.Equal(t, int64(9), eventRepository.placedEvents[3].AggregateID)
+
+		// the fee is posted with the transfer in one balanced journal entry
+		assert.Len(t, ledgerRepository.journalEntries, 1)
+		assert.Equal(t, []model.Posting{
+			{AccountID: 1, Direction: model.PostingDirectionDebit, Amount: decimal.NewFromInt(500)},
+			{AccountID: 1, Direction: model.PostingDirectionDebit, Amount: decimal.NewFromInt(5)},
+			{AccountID: 2, Direction: model.PostingDirectionCredit, Amount: decimal.NewFromInt(500)},
+			{AccountID: 9, Direction: model.PostingDirectionCredit, Amount: decimal.NewFromInt(5)},
+		}, ledgerRepository.journalEntries[0].Postings)
+	})
+
+	t.Run("success_no_matching_rule", func(t *testing.T) {
+		svc, accountRepository, eventRepository, _ := newService(model.Account{
+			ID:      1,
+			Type:    model.AccountTypePersonal,
+			Balance: decimal.NewFromInt(1000),
+		})
+
+		resp, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(500),
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, resp.Fee.IsZero())
+		assert.True(t, decimal.NewFromInt(500).Equal(resp.TotalDebited))
+		assert.Len(t, accountRepository.upserted, 2)
+		assert.Len(t, eventRepository.placedEvents, 2)
+	})
+
+	t.Run("error_insufficient_balance_for_fee", func(t *testing.T) {
+		svc, _, _, _ := newService(model.Account{
+			ID:      1,
+			Type:    model.AccountTypeBusiness,
+			Balance: decimal.NewFromInt(500),
+		})
+
+		_, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(500),
+		})
+
+		assert.ErrorIs(t, err, ErrInsufficientBalance)
+	})
+
+	t.Run("error_revenue_account_not_found", func(t *testing.T) {
+		svc, accountRepository, _, _ := newService(model.Account{
+			ID:      1,
+			Type:    model.AccountTypeBusiness,
+			Balance: decimal.NewFromInt(1000),
+		})
+		accountRepository.errFindByIDForUpdateTx = []error{nil, nil, exception.ErrRecordNotFound}
+
+		_, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(500),
+		})
+
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+
+	t.Run("error_source_account_not_found", func(t *testing.T) {
+		svc, accountRepository, _, _ := newService(model.Account{})
+		accountRepository.errFindByID = []error{exception.ErrRecordNotFound}
+
+		_, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(500),
+		})
+
+		assert.ErrorIs(t, err, ErrSourceAccountNotFound)
+	})
+}
+
+func TestTransactionService_TransferHotAccount(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Signature:     "test-signature",
+		Language:      "en",
+		Timestamp:     time.Now(),
+		TransactionID: "tx-12345",
+	})
+
+	newService := func(account model.Account, accountShardRepository *accountShardRepositoryMock,
+		policy HotAccountPolicy,
+	) (*TransactionService, *accountRepositoryMock, *eventRepositoryMock, *ledgerRepositoryMock) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			accountsByID: map[int64]model.Account{
+				1: account,
+				2: {ID: 2, Balance: decimal.NewFromInt(500)},
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		ledgerRepository := &ledgerRepositoryMock{}
+
+		return &TransactionService{
+			requestTimeThreshold:   30 * time.Second,
+			transferLimiter:        &transferLimiterMock{},
+			accountRepository:      accountRepository,
+			accountShardRepository: accountShardRepository,
+			eventRepository:        eventRepository,
+			journalRepository:      ledgerRepository,
+			idempotencyKeyClaimer:  &idempotencyKeyRepositoryMock{},
+			hotAccountPolicy:       policy,
+			eventVersion:           "1.0.0",
+		}, accountRepository, eventRepository, ledgerRepository
+	}
+
+	req := dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+	}
+
+	t.Run("success_credits_destination_shard", func(t *testing.T) {
+		accountShardRepository := &accountShardRepositoryMock{}
+		svc, accountRepository, eventRepository, ledgerRepository := newService(model.Account{
+			ID:      1,
+			Balance: decimal.NewFromInt(1000),
+		}, accountShardRepository, HotAccountPolicy{AccountIDs: []int64{2}, Shards: 4})
+
+		_, err := svc.Transfer(ctx, req)
+		assert.NoError(t, err)
+
+		// the destination is locked for share and only the source account row is written
+		assert.Equal(t, []int64{2}, accountRepository.sharedIDs)
+		assert.Len(t, accountRepository.upserted, 1)
+		assert.True(t, accountRepository.upserted[0].Balance.Equal(decimal.NewFromInt(900)))
+
+		assert.Len(t, accountShardRepository.credited, 1)
+		shard := accountShardRepository.credited[0]
+		assert.Equal(t, int64(2), shard.AccountID)
+		assert.True(t, shard.Balance.Equal(decimal.NewFromInt(100)))
+
+		// the credit is recorded in the event stream of the shard
+		assert.Len(t, eventRepository.placedEvents, 2)
+		credit := eventRepository.placedEvents[1]
+		assert.Equal(t, model.AggregateTypeAccountShard, credit.AggregateType)
+		assert.Equal(t, shard.ID, credit.AggregateID)
+		assert.Equal(t, model.EventTypeCreditBalance, credit.EventType)
+		assert.Equal(t, int64(2), credit.EventData.(map[string]interface{})["account_id"])
+
+		// the ledger posts the credit to the account of the shard
+		assert.Equal(t, []model.Posting{
+			{AccountID: 1, Direction: model.PostingDirectionDebit, Amount: decimal.NewFromInt(100)},
+			{AccountID: 2, Direction: model.PostingDirectionCredit, Amount: decimal.NewFromInt(100)},
+		}, ledgerRepository.journalEntries[0].Postings)
+	})
+
+	t.Run("success_consolidates_source_shards", func(t *testing.T) {
+		accountShardRepository := &accountShardRepositoryMock{
+			shards: []model.AccountShard{
+				{ID: 10, AccountID: 1, ShardNo: 0, Balance: decimal.NewFromInt(30)},
+				{ID: 11, AccountID: 1, ShardNo: 1, Balance: decimal.NewFromInt(50)},
+			},
+		}
+		svc, accountRepository, eventRepository, _ := newService(model.Account{
+			ID:      1,
+			Balance: decimal.NewFromInt(40),
+		}, accountShardRepository, HotAccountPolicy{AccountIDs: []int64{1}, Shards: 4})
+
+		_, err := svc.Transfer(ctx, req)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []int64{1}, accountShardRepository.resetAccountIDs)
+		assert.True(t, accountRepository.upserted[0].Balance.Equal(decimal.NewFromInt(20)))
+
+		var eventTypes []model.EventType
+		for _, event := range eventRepository.placedEvents {
+			eventTypes = append(eventTypes, event.EventType)
+		}
+
+		assert.Equal(t, []model.EventType{
+			model.EventTypeShardsConsolidated,
+			model.EventTypeDebitBalance,
+			model.EventTypeCreditBalance,
+		}, eventTypes)
+	})
+
+	t.Run("success_does_not_consolidate_covered_debit", func(t *testing.T) {
+		accountShardRepository := &accountShardRepositoryMock{
+			shards: []model.AccountShard{{ID: 10, AccountID: 1, ShardNo: 0, Balance: decimal.NewFromInt(30)}},
+		}
+		svc, _, _, _ := newService(model.Account{
+			ID:      1,
+			Balance: decimal.NewFromInt(1000),
+		}, accountShardRepository, HotAccountPolicy{AccountIDs: []int64{1}, Shards: 4})
+
+		_, err := svc.Transfer(ctx, req)
+		assert.NoError(t, err)
+		assert.Empty(t, accountShardRepository.resetAccountIDs)
+	})
+
+	t.Run("error_insufficient_balance_after_consolidation", func(t *testing.T) {
+		accountShardRepository := &accountShardRepositoryMock{
+			shards: []model.AccountShard{{ID: 10, AccountID: 1, ShardNo: 0, Balance: decimal.NewFromInt(30)}},
+		}
+		svc, _, _, _ := newService(model.Account{
+			ID:      1,
+			Balance: decimal.NewFromInt(40),
+		}, accountShardRepository, HotAccountPolicy{AccountIDs: []int64{1}, Shards: 4})
+
+		_, err := svc.Transfer(ctx, req)
+		assert.ErrorIs(t, err, ErrInsufficientBalance)
+	})
+
+	t.Run("error_credit_shard", func(t *testing.T) {
+		accountShardRepository := &accountShardRepositoryMock{errCreditTx: errors.New("internal db error")}
+		svc, _, _, _ := newService(model.Account{
+			ID:      1,
+			Balance: decimal.NewFromInt(1000),
+		}, accountShardRepository, HotAccountPolicy{AccountIDs: []int64{2}, Shards: 4})
+
+		_, err := svc.Transfer(ctx, req)
+		assert.ErrorContains(t, err, "failed to credit account shard")
+	})
+}
+
+func TestHotAccountPolicy(t *testing.T) {
+	policy := HotAccountPolicy{AccountIDs: []int64{9}, Shards: 4}
+
+	assert.True(t, policy.IsHot(9))
+	assert.False(t, policy.IsHot(1))
+	assert.False(t, HotAccountPolicy{AccountIDs: []int64{9}}.IsHot(9))
+
+	for range 100 {
+		shardNo := policy.PickShard()
+		assert.True(t, shardNo >= 0 && shardNo < 4)
+	}
+}
+
+func TestTransactionService_GetTransaction(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	req := dto.GetTransactionRequest{TransactionID: "tx-1"}
+
+	t.Run("success_transfer", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{nil},
+			events: []model.Event{
+				{
+					AggregateType: model.AggregateTypeAccount, AggregateID: 1, SequenceNumber: 3,
+					EventType: model.EventTypeDebitBalance, CreatedAt: createdAt,
+					EventData: []byte(`{"destination_account_id": 2, "amount": "100"}`),
+				},
+				{
+					AggregateType: model.AggregateTypeAccount, AggregateID: 1, SequenceNumber: 4,
+					EventType: model.EventTypeFeeCharged, CreatedAt: createdAt,
+					EventData: []byte(`{"revenue_account_id": 99, "amount": "1.5"}`),
+				},
+				{
+					AggregateType: model.AggregateTypeAccount, AggregateID: 2, SequenceNumber: 1,
+					EventType: model.EventTypeCreditBalance, CreatedAt: createdAt.Add(time.Millisecond),
+					EventData: []byte(`{"source_account_id": 1, "amount": "100"}`),
+				},
+			},
+		}
+		svc := NewTransactionService(nil, nil, eventRepository, nil, nil, nil, nil, nil, nil, nil, TransferApprovalPolicy{},
+			HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		resp, err := svc.GetTransaction(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "transfer", resp.Operation)
+		assert.Equal(t, "completed", resp.Status)
+		assert.Equal(t, []int64{1, 2}, resp.AccountIDs)
+		assert.Equal(t, int64(1), *resp.SourceAccountID)
+		assert.Equal(t, int64(2), *resp.DestinationAccountID)
+		assert.True(t, decimal.NewFromInt(100).Equal(*resp.Amount))
+		assert.True(t, decimal.RequireFromString("1.5").Equal(*resp.Fee))
+		assert.Len(t, resp.Events, 3)
+		assert.Equal(t, createdAt, resp.CreatedAt)
+		assert.Equal(t, createdAt.Add(time.Millisecond), resp.CompletedAt)
+	})
+
+	t.Run("success_transfer_to_shard", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{nil},
+			events: []model.Event{
+				{
+					AggregateType: model.AggregateTypeAccount, AggregateID: 1, SequenceNumber: 3,
+					EventType: model.EventTypeDebitBalance, CreatedAt: createdAt,
+					EventData: []byte(`{"destination_account_id": 9, "amount": "100"}`),
+				},
+				{
+					AggregateType: model.AggregateTypeAccountShard, AggregateID: 902, SequenceNumber: 7,
+					EventType: model.EventTypeCreditBalance, CreatedAt: createdAt,
+					EventData: []byte(`{"source_account_id": 1, "amount": "100", "account_id": 9, "shard_no": 2}`),
+				},
+			},
+		}
+		svc := NewTransactionService(nil, nil, eventRepository, nil, nil, nil, nil, nil, nil, nil, TransferApprovalPolicy{},
+			HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		resp, err := svc.GetTransaction(context.Background(), req)
+
+		// the shard is reported as its account
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{1, 9}, resp.AccountIDs)
+		assert.Equal(t, int64(9), *resp.DestinationAccountID)
+		assert.Equal(t, "account_shard", resp.Events[1].AggregateType)
+	})
+
+	t.Run("success_interest_posting", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{nil},
+			events: []model.Event{
+				{
+					AggregateType: model.AggregateTypeAccount, AggregateID: 1, SequenceNumber: 9,
+					EventType: model.EventTypeInterestPosted, CreatedAt: createdAt,
+					EventData: []byte(`{"period_end": "2026-09-30", "accrual_count": 30, "amount": "12.35"}`),
+				},
+				{
+					AggregateType: model.AggregateTypeAccount, AggregateID: 900002, SequenceNumber: 4,
+					EventType: model.EventTypeDebitBalance, CreatedAt: createdAt,
+					EventData: []byte(`{"destination_account_id": 1, "amount": "12.35"}`),
+				},
+			},
+		}
+		svc := NewTransactionService(nil, nil, eventRepository, nil, nil, nil, nil, nil, nil, nil, TransferApprovalPolicy{},
+			HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		resp, err := svc.GetTransaction(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "interest_posting", resp.Operation)
+		assert.Equal(t, int64(900002), *resp.SourceAccountID)
+		assert.Equal(t, int64(1), *resp.DestinationAccountID)
+		assert.True(t, decimal.RequireFromString("12.35").Equal(*resp.Amount))
+	})
+
+	t.Run("success_failed_standing_order_attempt", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{nil},
+			events: []model.Event{
+				{
+					AggregateType: model.AggregateTypeStandingOrder, AggregateID: 7, SequenceNumber: 2,
+					EventType: model.EventTypeStandingOrderSkipped, CreatedAt: createdAt,
+					EventData: []byte(`{"occurrence": 1, "reason": "insufficient balance"}`),
+				},
+			},
+		}
+		svc := NewTransactionService(nil, nil, eventRepository, nil, nil, nil, nil, nil, nil, nil, TransferApprovalPolicy{},
+			HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		resp, err := svc.GetTransaction(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "standing_order_execution", resp.Operation)
+		assert.Equal(t, "failed", resp.Status)
+		assert.Empty(t, resp.AccountIDs)
+		assert.Nil(t, resp.Amount)
+	})
+
+	t.Run("success_pending_approval", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{nil},
+			events: []model.Event{
+				{
+					AggregateType: model.AggregateTypeTransfer, AggregateID: 7, SequenceNumber: 1,
+					EventType: model.EventTypeTransferApprovalRequested, CreatedAt: createdAt,
+					EventData: []byte(`{"source_account_id": 1, "destination_account_id": 2, "amount": "15000"}`),
+				},
+			},
+		}
+		svc := NewTransactionService(nil, nil, eventRepository, nil, nil, nil, nil, nil, nil, nil, TransferApprovalPolicy{},
+			HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		resp, err := svc.GetTransaction(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "transfer", resp.Operation)
+		assert.Equal(t, "pending_approval", resp.Status)
+		assert.Empty(t, resp.AccountIDs)
+		assert.Equal(t, int64(1), *resp.SourceAccountID)
+		assert.Equal(t, int64(2), *resp.DestinationAccountID)
+		assert.True(t, decimal.NewFromInt(15000).Equal(*resp.Amount))
+	})
+
+	t.Run("error_not_found", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+		}
+		svc := NewTransactionService(nil, nil, eventRepository, nil, nil, nil, nil, nil, nil, nil, TransferApprovalPolicy{},
+			HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		_, err := svc.GetTransaction(context.Background(), req)
+
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+
+	t.Run("error_find_events", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{errors.New("db error")},
+		}
+		svc := NewTransactionService(nil, nil, eventRepository, nil, nil, nil, nil, nil, nil, nil, TransferApprovalPolicy{},
+			HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		_, err := svc.GetTransaction(context.Background(), req)
+
+		assert.ErrorContains(t, err, "failed to find events")
+	})
+}
+
+func TestTransactionService_TransferApproval(t *testing.T) {
+	policy := TransferApprovalPolicy{Threshold: decimal.NewFromInt(10000), TTL: time.Hour}
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Timestamp:     time.Now(),
+		TransactionID: "tx-large",
+	})
+	req := dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(15000),
+		InitiatedBy:          "alice",
+	}
+
+	t.Run("success_pending_approval", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{errFindByID: []error{nil, nil}}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{}},
+		}
+		transferApprovalRepository := &transferApprovalRepositoryMock{}
+		svc := NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository,
+			&ledgerRepositoryMock{}, transferApprovalRepository, nil, nil, &idempotencyKeyRepositoryMock{},
+			&transferLimiterMock{}, nil, policy, HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		resp, err := svc.Transfer(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pending_approval", resp.Status)
+		assert.NotNil(t, resp.ExpiresAt)
+		assert.Len(t, transferApprovalRepository.created, 1)
+		assert.Equal(t, "alice", transferApprovalRepository.created[0].InitiatedBy)
+		assert.Equal(t, model.TransferApprovalStatusPending, transferApprovalRepository.created[0].Status)
+		assert.Len(t, eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypeTransferApprovalRequested, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.AggregateTypeTransfer, eventRepository.placedEvents[0].AggregateType)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("success_below_threshold", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account:                model.Account{ID: 1, Balance: decimal.NewFromInt(20000)},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		transferApprovalRepository := &transferApprovalRepositoryMock{}
+		svc := NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository,
+			&ledgerRepositoryMock{}, transferApprovalRepository, nil, nil, &idempotencyKeyRepositoryMock{},
+			&transferLimiterMock{}, nil, policy, HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		resp, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(10000),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", resp.Status)
+		assert.Empty(t, transferApprovalRepository.created)
+		assert.Len(t, accountRepository.upserted, 2)
+	})
+
+	t.Run("error_source_account_not_found", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{errFindByID: []error{exception.ErrRecordNotFound}}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+		}
+		svc := NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository,
+			&ledgerRepositoryMock{}, &transferApprovalRepositoryMock{}, nil, nil, &idempotencyKeyRepositoryMock{},
+			&transferLimiterMock{}, nil, policy, HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		_, err := svc.Transfer(ctx, req)
+
+		assert.ErrorIs(t, err, ErrSourceAccountNotFound)
+	})
+
+	t.Run("error_initiator_required", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+		}
+		transferApprovalRepository := &transferApprovalRepositoryMock{}
+		svc := NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository,
+			&ledgerRepositoryMock{}, transferApprovalRepository, nil, nil, &idempotencyKeyRepositoryMock{},
+			&transferLimiterMock{}, nil, policy, HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		_, err := svc.Transfer(ctx, dto.CreateTransferRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               decimal.NewFromInt(15000),
+		})
+
+		assert.ErrorIs(t, err, ErrTransferInitiatorRequired)
+		assert.Empty(t, transferApprovalRepository.created)
+		assert.Empty(t, eventRepository.placedEvents)
+	})
+}
+
+func TestTransactionService_TransferRisk(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Timestamp:     time.Now(),
+		TransactionID: "tx-risk",
+	})
+	req := dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(6000),
+		InitiatedBy:          "alice",
+	}
+	newService := func(accountRepository *accountRepositoryMock, eventRepository *eventRepositoryMock,
+		transferApprovalRepository *transferApprovalRepositoryMock, riskDecisionRepository *riskDecisionRepositoryMock,
+		idempotencyKeyRepository *idempotencyKeyRepositoryMock, riskEvaluator *riskEvaluatorMock,
+	) *TransactionService {
+		return NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository,
+			&ledgerRepositoryMock{}, transferApprovalRepository, riskDecisionRepository, nil, idempotencyKeyRepository,
+			&transferLimiterMock{}, nil, TransferApprovalPolicy{TTL: time.Hour}, HotAccountPolicy{}, riskEvaluator, nil,
+			SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+	}
+
+	t.Run("error_denied_by_rule", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{errFindByID: []error{nil, nil}}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{}},
+		}
+		riskDecisionRepository := &riskDecisionRepositoryMock{}
+		idempotencyKeyRepository := &idempotencyKeyRepositoryMock{}
+		riskEvaluator := &riskEvaluatorMock{decision: risk.Decision{
+			Action:    risk.ActionDeny,
+			Rule:      "new_destination_account",
+			MessageID: "errors.risk_new_destination_account",
+			Message:   "new destination account",
+		}}
+		svc := newService(accountRepository, eventRepository, &transferApprovalRepositoryMock{},
+			riskDecisionRepository, idempotencyKeyRepository, riskEvaluator)
+
+		_, err := svc.Transfer(ctx, req)
+
+		var appErr exception.ApplicationError
+		if assert.ErrorAs(t, err, &appErr) {
+			assert.Equal(t, "errors.risk_new_destination_account", appErr.MessageID)
+			assert.Equal(t, "new destination account", appErr.Message)
+			assert.Equal(t, 422, appErr.StatusCode)
+		}
+
+		assert.Equal(t, []string{"tx-risk"}, idempotencyKeyRepository.claimed)
+		assert.Len(t, riskDecisionRepository.upserted, 1)
+		assert.Equal(t, model.RiskActionDeny, riskDecisionRepository.upserted[0].Action)
+		assert.Equal(t, "new_destination_account", riskDecisionRepository.upserted[0].Rule)
+		assert.Len(t, eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypeTransferRiskAssessed, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.AggregateTypeRiskDecision, eventRepository.placedEvents[0].AggregateType)
+		assert.Equal(t, "tx-risk", eventRepository.placedEvents[0].TransactionID)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("error_denied_without_message", func(t *testing.T) {
+		svc := newService(&accountRepositoryMock{errFindByID: []error{nil, nil}}, &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{}},
+		}, &transferApprovalRepositoryMock{}, &riskDecisionRepositoryMock{}, &idempotencyKeyRepositoryMock{},
+			&riskEvaluatorMock{decision: risk.Decision{Action: risk.ActionDeny, Rule: "night_transfer"}})
+
+		_, err := svc.Transfer(ctx, req)
+
+		var appErr exception.ApplicationError
+		if assert.ErrorAs(t, err, &appErr) {
+			assert.Equal(t, ErrTransferDenied.MessageID, appErr.MessageID)
+			assert.Equal(t, "night_transfer", appErr.MessageVars["rule"])
+		}
+	})
+
+	t.Run("success_held_for_review", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		transferApprovalRepository := &transferApprovalRepositoryMock{}
+		riskDecisionRepository := &riskDecisionRepositoryMock{}
+		svc := newService(&accountRepositoryMock{errFindByID: []error{nil, nil, nil, nil}}, eventRepository,
+			transferApprovalRepository, riskDecisionRepository, &idempotencyKeyRepositoryMock{},
+			&riskEvaluatorMock{decision: risk.Decision{Action: risk.ActionReview, Rule: "large_transfer"}})
+
+		resp, err := svc.Transfer(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pending_approval", resp.Status)
+		assert.Len(t, transferApprovalRepository.created, 1)
+		assert.Len(t, riskDecisionRepository.upserted, 1)
+		assert.Equal(t, model.RiskActionReview, riskDecisionRepository.upserted[0].Action)
+		assert.Len(t, eventRepository.placedEvents, 2)
+		assert.Equal(t, model.EventTypeTransferRiskAssessed, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.EventTypeTransferApprovalRequested, eventRepository.placedEvents[1].EventType)
+	})
+
+	t.Run("success_allowed", func(t *testing.T) {
+		createdAt := time.Now().Add(-48 * time.Hour)
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{nil, nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:        1,
+				Type:      model.AccountTypePersonal,
+				Balance:   decimal.NewFromInt(20000),
+				CreatedAt: createdAt,
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound,
+				exception.ErrRecordNotFound},
+			errCreateBulkTx: []error{nil, nil, nil},
+			events:          []model.Event{{}},
+		}
+		riskDecisionRepository := &riskDecisionRepositoryMock{}
+		riskEvaluator := &riskEvaluatorMock{decision: risk.Decision{Action: risk.ActionAllow}}
+		svc := newService(accountRepository, eventRepository, &transferApprovalRepositoryMock{},
+			riskDecisionRepository, &idempotencyKeyRepositoryMock{}, riskEvaluator)
+
+		resp, err := svc.Transfer(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", resp.Status)
+		assert.Len(t, accountRepository.upserted, 2)
+		assert.Len(t, riskDecisionRepository.upserted, 1)
+		assert.Equal(t, model.RiskActionAllow, riskDecisionRepository.upserted[0].Action)
+		assert.Empty(t, riskDecisionRepository.upserted[0].Rule)
+		assert.Equal(t, model.EventTypeTransferRiskAssessed, eventRepository.placedEvents[0].EventType)
+
+		if assert.Len(t, riskEvaluator.transfers, 1) {
+			transfer := riskEvaluator.transfers[0]
+			assert.True(t, decimal.NewFromInt(6000).Equal(transfer.Amount))
+			assert.Equal(t, "alice", transfer.InitiatedBy)
+			assert.Equal(t, "personal", transfer.Destination.Type)
+			assert.Equal(t, createdAt, transfer.Destination.CreatedAt)
+		}
+	})
+
+	t.Run("error_transfer_failed_after_allowed", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{nil, nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			account:                model.Account{ID: 1, Type: model.AccountTypePersonal, Balance: decimal.NewFromInt(100)},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{}},
+		}
+		riskDecisionRepository := &riskDecisionRepositoryMock{}
+		svc := newService(accountRepository, eventRepository, &transferApprovalRepositoryMock{},
+			riskDecisionRepository, &idempotencyKeyRepositoryMock{},
+			&riskEvaluatorMock{decision: risk.Decision{Action: risk.ActionAllow}})
+
+		_, err := svc.Transfer(ctx, req)
+
+		// the decision is recorded on its own before the transfer, it is kept when the transfer fails
+		assert.ErrorIs(t, err, ErrInsufficientBalance)
+		assert.Len(t, riskDecisionRepository.upserted, 1)
+		assert.Equal(t, model.RiskActionAllow, riskDecisionRepository.upserted[0].Action)
+		assert.Len(t, eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypeTransferRiskAssessed, eventRepository.placedEvents[0].EventType)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("success_retried_after_allowed", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{nil, nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account:                model.Account{ID: 1, Type: model.AccountTypePersonal, Balance: decimal.NewFromInt(20000)},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{nil},
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound,
+				exception.ErrRecordNotFound},
+			errCreateBulkTx: []error{nil, nil, nil},
+			events: []model.Event{{
+				AggregateType: model.AggregateTypeRiskDecision,
+				EventType:     model.EventTypeTransferRiskAssessed,
+				EventData:     []byte(`{"action":"allow"}`),
+			}},
+		}
+		riskDecisionRepository := &riskDecisionRepositoryMock{}
+		svc := newService(accountRepository, eventRepository, &transferApprovalRepositoryMock{},
+			riskDecisionRepository, &idempotencyKeyRepositoryMock{},
+			&riskEvaluatorMock{decision: risk.Decision{Action: risk.ActionAllow}})
+
+		resp, err := svc.Transfer(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", resp.Status)
+		assert.Len(t, riskDecisionRepository.upserted, 1)
+		assert.Len(t, accountRepository.upserted, 2)
+	})
+
+	t.Run("error_idempotency_after_denied", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{nil},
+			events: []model.Event{{
+				AggregateType: model.AggregateTypeRiskDecision,
+				EventType:     model.EventTypeTransferRiskAssessed,
+				EventData:     []byte(`{"action":"deny"}`),
+			}},
+		}
+		riskDecisionRepository := &riskDecisionRepositoryMock{}
+		svc := newService(&accountRepositoryMock{}, eventRepository, &transferApprovalRepositoryMock{},
+			riskDecisionRepository, &idempotencyKeyRepositoryMock{},
+			&riskEvaluatorMock{decision: risk.Decision{Action: risk.ActionAllow}})
+
+		_, err := svc.Transfer(ctx, req)
+
+		assert.ErrorIs(t, err, ErrIdempotency)
+		assert.Empty(t, riskDecisionRepository.upserted)
+	})
+
+	t.Run("error_destination_account_not_found", func(t *testing.T) {
+		riskEvaluator := &riskEvaluatorMock{}
+		svc := newService(&accountRepositoryMock{errFindByID: []error{nil, exception.ErrRecordNotFound}},
+			&eventRepositoryMock{errFindAllByTransactionID: []error{exception.ErrRecordNotFound}},
+			&transferApprovalRepositoryMock{}, &riskDecisionRepositoryMock{}, &idempotencyKeyRepositoryMock{},
+			riskEvaluator)
+
+		_, err := svc.Transfer(ctx, req)
+
+		assert.ErrorIs(t, err, ErrDestinationAccountNotFound)
+		assert.Empty(t, riskEvaluator.transfers)
+	})
+}
+
+func TestTransactionService_TransferSanctions(t *testing.T) {
+	ctx := createContextWithRequestContext(dto.RequestContext{
+		Timestamp:     time.Now(),
+		TransactionID: "tx-sanctions",
+	})
+	req := dto.CreateTransferRequest{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(100),
+		InitiatedBy:          "alice",
+	}
+
+	list, err := sanctions.Parse([]byte("uid,name,program\n1001,\"BOUT, Viktor Anatolyevich\",SDGT\n"))
+	assert.NoError(t, err)
+
+	newService := func(accountRepository *accountRepositoryMock, eventRepository *eventRepositoryMock,
+		sanctionsRepository *sanctionsRepositoryMock, riskEvaluator RiskEvaluator,
+	) *TransactionService {
+		return NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository,
+			&ledgerRepositoryMock{}, &transferApprovalRepositoryMock{}, &riskDecisionRepositoryMock{},
+			sanctionsRepository, &idempotencyKeyRepositoryMock{}, &transferLimiterMock{}, nil,
+			TransferApprovalPolicy{TTL: time.Hour}, HotAccountPolicy{}, riskEvaluator,
+			&sanctionsScreenerMock{list: list}, SanctionsPolicy{HoldScore: 0.85, BlockScore: 0.97}, time.Minute,
+			"1.0.0", 100)
+	}
+
+	t.Run("error_blocked", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+			account:     model.Account{ID: 1, Type: model.AccountTypePersonal, DisplayName: "Viktor Bout"},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{}},
+		}
+		sanctionsRepository := &sanctionsRepositoryMock{}
+		riskEvaluator := &riskEvaluatorMock{}
+		svc := newService(accountRepository, eventRepository, sanctionsRepository, riskEvaluator)
+
+		_, err := svc.Transfer(ctx, req)
+
+		var appErr exception.ApplicationError
+		if assert.ErrorAs(t, err, &appErr) {
+			assert.Equal(t, ErrTransferBlocked.MessageID, appErr.MessageID)
+			assert.Equal(t, 422, appErr.StatusCode)
+		}
+
+		assert.ErrorIs(t, err, errTransferRefused)
+
+		if assert.Len(t, sanctionsRepository.upserted, 1) {
+			screening := sanctionsRepository.upserted[0]
+			assert.Equal(t, model.SanctionsActionBlock, screening.Action)
+			assert.Equal(t, list.Version, screening.ListVersion)
+			assert.Len(t, screening.Hits, 2)
+			assert.Equal(t, "1001", screening.Hits[0].EntryUID)
+			assert.Equal(t, "Viktor Bout", screening.Hits[0].AccountName)
+		}
+
+		assert.Len(t, eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypeTransferSanctionsHit, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.AggregateTypeSanctionsScreening, eventRepository.placedEvents[0].AggregateType)
+		assert.Empty(t, riskEvaluator.transfers)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("success_held", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		sanctionsRepository := &sanctionsRepositoryMock{}
+		svc := newService(&accountRepositoryMock{
+			errFindByID: []error{nil, nil, nil, nil},
+			account:     model.Account{ID: 1, Type: model.AccountTypePersonal, DisplayName: "Victor Bout"},
+		}, eventRepository, sanctionsRepository, nil)
+
+		resp, err := svc.Transfer(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pending_approval", resp.Status)
+
+		if assert.Len(t, sanctionsRepository.upserted, 1) {
+			assert.Equal(t, model.SanctionsActionHold, sanctionsRepository.upserted[0].Action)
+		}
+
+		assert.Len(t, eventRepository.placedEvents, 2)
+		assert.Equal(t, model.EventTypeTransferSanctionsHit, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.EventTypeTransferApprovalRequested, eventRepository.placedEvents[1].EventType)
+	})
+
+	t.Run("success_no_hit", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{nil, nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:          1,
+				Type:        model.AccountTypePersonal,
+				Balance:     decimal.NewFromInt(1000),
+				DisplayName: "Jane Doe",
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		sanctionsRepository := &sanctionsRepositoryMock{}
+		svc := newService(accountRepository, eventRepository, sanctionsRepository, nil)
+
+		resp, err := svc.Transfer(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", resp.Status)
+		assert.Len(t, accountRepository.upserted, 2)
+		assert.Empty(t, sanctionsRepository.upserted)
+	})
+
+	t.Run("error_blocked_system_type_not_exempt", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+			account:     model.Account{ID: 1, Type: model.AccountTypeSystem, DisplayName: "Viktor Bout"},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil},
+			events:                    []model.Event{{}},
+		}
+		sanctionsRepository := &sanctionsRepositoryMock{}
+		svc := newService(accountRepository, eventRepository, sanctionsRepository, nil)
+
+		_, err := svc.Transfer(ctx, req)
+
+		// only the configured system accounts are exempt, not any account of the system type
+		assert.ErrorIs(t, err, ErrTransferBlocked)
+		assert.Len(t, sanctionsRepository.upserted, 1)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("success_exempt_account", func(t *testing.T) {
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{nil, nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			accountsByID: map[int64]model.Account{
+				1: {ID: 1, Type: model.AccountTypeSystem, Balance: decimal.NewFromInt(1000)},
+				2: {ID: 2, Type: model.AccountTypePersonal, DisplayName: "Jane Doe"},
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		sanctionsRepository := &sanctionsRepositoryMock{}
+		svc := newService(accountRepository, eventRepository, sanctionsRepository, nil)
+		svc.sanctionsPolicy.ExemptAccountIDs = []int64{1}
+
+		resp, err := svc.Transfer(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", resp.Status)
+		assert.Empty(t, sanctionsRepository.upserted)
+	})
+
+	t.Run("success_held_unnamed_party", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindAllByTransactionID: []error{exception.ErrRecordNotFound},
+			errFindLastByAggregateID:  []error{exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:           []error{nil, nil},
+			events:                    []model.Event{{}},
+		}
+		sanctionsRepository := &sanctionsRepositoryMock{}
+		svc := newService(&accountRepositoryMock{
+			errFindByID: []error{nil, nil, nil, nil},
+			accountsByID: map[int64]model.Account{
+				1: {ID: 1, Type: model.AccountTypePersonal, DisplayName: "Jane Doe"},
+				2: {ID: 2, Type: model.AccountTypePersonal},
+			},
+		}, eventRepository, sanctionsRepository, nil)
+
+		resp, err := svc.Transfer(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pending_approval", resp.Status)
+
+		if assert.Len(t, sanctionsRepository.upserted, 1) {
+			screening := sanctionsRepository.upserted[0]
+			assert.Equal(t, model.SanctionsActionHold, screening.Action)
+			assert.Equal(t, []model.SanctionsHit{{AccountID: 2}}, screening.Hits)
+		}
+	})
+}
+
+func TestTransactionService_ApproveTransfer(t *testing.T) {
+	pending := model.TransferApproval{
+		ID:                   7,
+		TransactionID:        "tx-large",
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(15000),
+		InitiatedBy:          "alice",
+		Status:               model.TransferApprovalStatusPending,
+		ExpiresAt:            time.Now().Add(time.Hour),
+	}
+	req := dto.ReviewTransferRequest{TransactionID: "tx-large", ReviewedBy: "bob"}
+
+	newService := func(transferApprovalRepository *transferApprovalRepositoryMock,
+	) (*TransactionService, *accountRepositoryMock, *eventRepositoryMock, *ledgerRepositoryMock) {
+		accountRepository := &accountRepositoryMock{
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account:                model.Account{ID: 1, Balance: decimal.NewFromInt(20000)},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{nil, exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:          []error{nil, nil, nil},
+			events:                   []model.Event{{SequenceNumber: 1}},
+		}
+		ledgerRepository := &ledgerRepositoryMock{}
+		svc := NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository, ledgerRepository,
+			transferApprovalRepository, nil, nil, nil, &transferLimiterMock{}, nil, TransferApprovalPolicy{}, HotAccountPolicy{},
+			nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		return svc, accountRepository, eventRepository, ledgerRepository
+	}
+
+	t.Run("success", func(t *testing.T) {
+		transferApprovalRepository := &transferApprovalRepositoryMock{transferApproval: pending}
+		svc, accountRepository, eventRepository, ledgerRepository := newService(transferApprovalRepository)
+
+		resp, err := svc.ApproveTransfer(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", resp.Status)
+		assert.Equal(t, "tx-large", resp.TransactionID)
+		assert.Len(t, transferApprovalRepository.updated, 1)
+		assert.Equal(t, model.TransferApprovalStatusApproved, transferApprovalRepository.updated[0].Status)
+		assert.Equal(t, "bob", *transferApprovalRepository.updated[0].ReviewedBy)
+		assert.Len(t, eventRepository.placedEvents, 3)
+		assert.Equal(t, model.EventTypeTransferApproved, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, int64(2), eventRepository.placedEvents[0].SequenceNumber)
+		assert.Equal(t, model.EventTypeDebitBalance, eventRepository.placedEvents[1].EventType)
+		assert.Equal(t, "tx-large", eventRepository.placedEvents[1].TransactionID)
+		assert.Len(t, accountRepository.upserted, 2)
+		assert.Len(t, ledgerRepository.journalEntries, 1)
+	})
+
+	t.Run("error_self_review", func(t *testing.T) {
+		transferApprovalRepository := &transferApprovalRepositoryMock{transferApproval: pending}
+		svc, _, _, _ := newService(transferApprovalRepository)
+
+		_, err := svc.ApproveTransfer(context.Background(), dto.ReviewTransferRequest{
+			TransactionID: "tx-large",
+			ReviewedBy:    "alice",
+		})
+
+		assert.ErrorIs(t, err, ErrTransferSelfReview)
+		assert.Empty(t, transferApprovalRepository.updated)
+	})
+
+	t.Run("error_not_pending", func(t *testing.T) {
+		rejected := pending
+		rejected.Status = model.TransferApprovalStatusRejected
+		svc, _, _, _ := newService(&transferApprovalRepositoryMock{transferApproval: rejected})
+
+		_, err := svc.ApproveTransfer(context.Background(), req)
+
+		assert.ErrorIs(t, err, ErrTransferNotPendingApproval)
+	})
+
+	t.Run("error_expired", func(t *testing.T) {
+		expired := pending
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		svc, _, _, _ := newService(&transferApprovalRepositoryMock{transferApproval: expired})
+
+		_, err := svc.ApproveTransfer(context.Background(), req)
+
+		assert.ErrorIs(t, err, ErrTransferApprovalExpired)
+	})
+
+	t.Run("error_reviewed_concurrently", func(t *testing.T) {
+		approved := pending
+		approved.Status = model.TransferApprovalStatusApproved
+		transferApprovalRepository := &transferApprovalRepositoryMock{
+			transferApproval:            pending,
+			transferApprovalForUpdateTx: &approved,
+		}
+		svc, accountRepository, eventRepository, _ := newService(transferApprovalRepository)
+
+		_, err := svc.ApproveTransfer(context.Background(), req)
+
+		assert.ErrorIs(t, err, ErrTransferNotPendingApproval)
+		assert.Empty(t, eventRepository.placedEvents)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("error_not_found", func(t *testing.T) {
+		svc, _, _, _ := newService(&transferApprovalRepositoryMock{
+			errFindByTransactionID: exception.ErrRecordNotFound,
+		})
+
+		_, err := svc.ApproveTransfer(context.Background(), req)
+
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+
+	newRiskService := func(transferApprovalRepository *transferApprovalRepositoryMock,
+		accountRepository *accountRepositoryMock, eventRepository *eventRepositoryMock,
+		riskDecisionRepository *riskDecisionRepositoryMock, riskEvaluator *riskEvaluatorMock,
+	) *TransactionService {
+		return NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository,
+			&ledgerRepositoryMock{}, transferApprovalRepository, riskDecisionRepository, nil, nil,
+			&transferLimiterMock{}, nil, TransferApprovalPolicy{}, HotAccountPolicy{}, riskEvaluator, nil,
+			SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+	}
+
+	t.Run("error_denied_when_assessed_again", func(t *testing.T) {
+		transferApprovalRepository := &transferApprovalRepositoryMock{transferApproval: pending}
+		accountRepository := &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+			account:     model.Account{ID: 1, Balance: decimal.NewFromInt(20000)},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound, nil},
+			errCreateBulkTx:          []error{nil, nil},
+			events:                   []model.Event{{SequenceNumber: 1}},
+		}
+		riskDecisionRepository := &riskDecisionRepositoryMock{}
+		svc := newRiskService(transferApprovalRepository, accountRepository, eventRepository, riskDecisionRepository,
+			&riskEvaluatorMock{decision: risk.Decision{Action: risk.ActionDeny, Rule: "new_destination_account"}})
+
+		_, err := svc.ApproveTransfer(context.Background(), req)
+
+		// the risk state changed since the transfer was held, it is rejected instead of executed
+		assert.ErrorIs(t, err, errTransferRefused)
+
+		var appErr exception.ApplicationError
+		if assert.ErrorAs(t, err, &appErr) {
+			assert.Equal(t, ErrTransferDenied.MessageID, appErr.MessageID)
+			assert.Equal(t, "new_destination_account", appErr.MessageVars["rule"])
+		}
+
+		if assert.Len(t, riskDecisionRepository.upserted, 1) {
+			assert.Equal(t, model.RiskActionDeny, riskDecisionRepository.upserted[0].Action)
+			assert.Equal(t, "tx-large", riskDecisionRepository.upserted[0].TransactionID)
+		}
+
+		if assert.Len(t, transferApprovalRepository.updated, 1) {
+			assert.Equal(t, model.TransferApprovalStatusRejected, transferApprovalRepository.updated[0].Status)
+			assert.Equal(t, riskDeniedReason, transferApprovalRepository.updated[0].RejectionReason)
+		}
+
+		assert.Len(t, eventRepository.placedEvents, 2)
+		assert.Equal(t, model.EventTypeTransferRiskAssessed, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.EventTypeTransferRejected, eventRepository.placedEvents[1].EventType)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("success_review_assessed_again", func(t *testing.T) {
+		transferApprovalRepository := &transferApprovalRepositoryMock{transferApproval: pending}
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{nil, nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account:                model.Account{ID: 1, Balance: decimal.NewFromInt(20000)},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{
+				exception.ErrRecordNotFound, nil, exception.ErrRecordNotFound, exception.ErrRecordNotFound,
+			},
+			errCreateBulkTx: []error{nil, nil, nil, nil},
+			events:          []model.Event{{SequenceNumber: 1}},
+		}
+		riskDecisionRepository := &riskDecisionRepositoryMock{}
+		riskEvaluator := &riskEvaluatorMock{decision: risk.Decision{Action: risk.ActionReview, Rule: "large_transfer"}}
+		svc := newRiskService(transferApprovalRepository, accountRepository, eventRepository, riskDecisionRepository,
+			riskEvaluator)
+
+		resp, err := svc.ApproveTransfer(context.Background(), req)
+
+		// a review is what the approver makes, the transfer is executed and the new decision recorded
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", resp.Status)
+		assert.Len(t, riskEvaluator.transfers, 1)
+
+		if assert.Len(t, riskDecisionRepository.upserted, 1) {
+			assert.Equal(t, model.RiskActionReview, riskDecisionRepository.upserted[0].Action)
+		}
+
+		assert.Equal(t, model.EventTypeTransferRiskAssessed, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.EventTypeTransferApproved, eventRepository.placedEvents[1].EventType)
+		assert.Len(t, accountRepository.upserted, 2)
+	})
+
+	list, err := sanctions.Parse([]byte("uid,name,program\n1001,\"BOUT, Viktor Anatolyevich\",SDGT\n"))
+	assert.NoError(t, err)
+
+	newScreeningService := func(transferApprovalRepository *transferApprovalRepositoryMock,
+		accountRepository *accountRepositoryMock, eventRepository *eventRepositoryMock,
+		sanctionsRepository *sanctionsRepositoryMock,
+	) *TransactionService {
+		return NewTransactionService(accountRepository, &accountShardRepositoryMock{}, eventRepository,
+			&ledgerRepositoryMock{}, transferApprovalRepository, nil, sanctionsRepository, nil, &transferLimiterMock{},
+			nil, TransferApprovalPolicy{}, HotAccountPolicy{}, nil, &sanctionsScreenerMock{list: list},
+			SanctionsPolicy{HoldScore: 0.85, BlockScore: 0.97}, time.Minute, "1.0.0", 100)
+	}
+
+	t.Run("error_blocked_when_screened_again", func(t *testing.T) {
+		transferApprovalRepository := &transferApprovalRepositoryMock{transferApproval: pending}
+		accountRepository := &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+			account:     model.Account{ID: 1, Type: model.AccountTypePersonal, DisplayName: "Viktor Bout"},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{exception.ErrRecordNotFound, nil},
+			errCreateBulkTx:          []error{nil, nil},
+			events:                   []model.Event{{SequenceNumber: 1}},
+		}
+		sanctionsRepository := &sanctionsRepositoryMock{}
+		svc := newScreeningService(transferApprovalRepository, accountRepository, eventRepository,
+			sanctionsRepository)
+
+		_, err := svc.ApproveTransfer(context.Background(), req)
+
+		// the list changed since the transfer was held, it is rejected instead of executed
+		assert.ErrorIs(t, err, ErrTransferBlocked)
+
+		if assert.Len(t, sanctionsRepository.upserted, 1) {
+			assert.Equal(t, model.SanctionsActionBlock, sanctionsRepository.upserted[0].Action)
+		}
+
+		if assert.Len(t, transferApprovalRepository.updated, 1) {
+			assert.Equal(t, model.TransferApprovalStatusRejected, transferApprovalRepository.updated[0].Status)
+			assert.Equal(t, sanctionsBlockedReason, transferApprovalRepository.updated[0].RejectionReason)
+		}
+
+		assert.Len(t, eventRepository.placedEvents, 2)
+		assert.Equal(t, model.EventTypeTransferSanctionsHit, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.EventTypeTransferRejected, eventRepository.placedEvents[1].EventType)
+		assert.Empty(t, accountRepository.upserted)
+	})
+
+	t.Run("success_held_screened_again", func(t *testing.T) {
+		transferApprovalRepository := &transferApprovalRepositoryMock{transferApproval: pending}
+		accountRepository := &accountRepositoryMock{
+			errFindByID:            []error{nil, nil},
+			errFindByIDForUpdateTx: []error{nil, nil},
+			errUpsertTx:            []error{nil, nil},
+			account: model.Account{
+				ID:          1,
+				Type:        model.AccountTypePersonal,
+				Balance:     decimal.NewFromInt(20000),
+				DisplayName: "Victor Bout",
+			},
+		}
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{nil, nil, exception.ErrRecordNotFound, exception.ErrRecordNotFound},
+			errCreateBulkTx:          []error{nil, nil, nil, nil},
+			events:                   []model.Event{{SequenceNumber: 1}},
+		}
+		sanctionsRepository := &sanctionsRepositoryMock{}
+		svc := newScreeningService(transferApprovalRepository, accountRepository, eventRepository,
+			sanctionsRepository)
+
+		resp, err := svc.ApproveTransfer(context.Background(), req)
+
+		// a hold is what the approver reviews, the transfer is executed and the new screening recorded
+		assert.NoError(t, err)
+		assert.Equal(t, "completed", resp.Status)
+
+		if assert.Len(t, sanctionsRepository.upserted, 1) {
+			assert.Equal(t, model.SanctionsActionHold, sanctionsRepository.upserted[0].Action)
+		}
+
+		assert.Equal(t, model.EventTypeTransferSanctionsHit, eventRepository.placedEvents[0].EventType)
+		assert.Equal(t, model.EventTypeTransferApproved, eventRepository.placedEvents[1].EventType)
+		assert.Len(t, accountRepository.upserted, 2)
+	})
+}
+
+func TestTransactionService_RejectTransfer(t *testing.T) {
+	pending := model.TransferApproval{
+		ID:            7,
+		TransactionID: "tx-large",
+		InitiatedBy:   "alice",
+		Status:        model.TransferApprovalStatusPending,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{nil},
+			errCreateBulkTx:          []error{nil},
+			events:                   []model.Event{{SequenceNumber: 1}},
+		}
+		transferApprovalRepository := &transferApprovalRepositoryMock{transferApproval: pending}
+		svc := NewTransactionService(&accountRepositoryMock{}, nil, eventRepository, nil, transferApprovalRepository, nil,
+			nil, nil, nil, nil, TransferApprovalPolicy{}, HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0",
+			100)
+
+		err := svc.RejectTransfer(context.Background(), dto.ReviewTransferRequest{
+			TransactionID: "tx-large",
+			ReviewedBy:    "bob",
+			Reason:        "unknown beneficiary",
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, transferApprovalRepository.updated, 1)
+		assert.Equal(t, model.TransferApprovalStatusRejected, transferApprovalRepository.updated[0].Status)
+		assert.Equal(t, "unknown beneficiary", transferApprovalRepository.updated[0].RejectionReason)
+		assert.Len(t, eventRepository.placedEvents, 1)
+		assert.Equal(t, model.EventTypeTransferRejected, eventRepository.placedEvents[0].EventType)
+	})
+
+	t.Run("error_self_review", func(t *testing.T) {
+		svc := NewTransactionService(&accountRepositoryMock{}, nil, &eventRepositoryMock{}, nil,
+			&transferApprovalRepositoryMock{transferApproval: pending}, nil, nil, nil, nil, nil, TransferApprovalPolicy{},
+			HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		err := svc.RejectTransfer(context.Background(), dto.ReviewTransferRequest{
+			TransactionID: "tx-large",
+			ReviewedBy:    "alice",
+		})
+
+		assert.ErrorIs(t, err, ErrTransferSelfReview)
+	})
+}
+
+func TestTransactionService_ExpireDue(t *testing.T) {
+	expired := model.TransferApproval{
+		ID:            7,
+		TransactionID: "tx-large",
+		Status:        model.TransferApprovalStatusPending,
+		ExpiresAt:     time.Now().Add(-time.Minute),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{nil},
+			errCreateBulkTx:          []error{nil},
+			events:                   []model.Event{{SequenceNumber: 1}},
+		}
+		transferApprovalRepository := &transferApprovalRepositoryMock{
+			transferApproval:  expired,
+			transferApprovals: []model.TransferApproval{expired},
+		}
+		svc := NewTransactionService(&accountRepositoryMock{}, nil, eventRepository, nil, transferApprovalRepository, nil,
+			nil, nil, nil, nil, TransferApprovalPolicy{}, HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0",
+			100)
+
+		count, err := svc.ExpireDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, model.TransferApprovalStatusExpired, transferApprovalRepository.updated[0].Status)
+		assert.Equal(t, model.EventTypeTransferApprovalExpired, eventRepository.placedEvents[0].EventType)
+	})
+
+	t.Run("success_reviewed_in_the_meantime", func(t *testing.T) {
+		approved := expired
+		approved.Status = model.TransferApprovalStatusApproved
+		eventRepository := &eventRepositoryMock{
+			errFindLastByAggregateID: []error{nil},
+			events:                   []model.Event{{SequenceNumber: 2}},
+		}
+		transferApprovalRepository := &transferApprovalRepositoryMock{
+			transferApproval:  approved,
+			transferApprovals: []model.TransferApproval{expired},
+		}
+		svc := NewTransactionService(&accountRepositoryMock{}, nil, eventRepository, nil, transferApprovalRepository, nil,
+			nil, nil, nil, nil, TransferApprovalPolicy{}, HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0",
+			100)
+
+		count, err := svc.ExpireDue(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Empty(t, transferApprovalRepository.updated)
+	})
+
+	t.Run("error_find_expired", func(t *testing.T) {
+		svc := NewTransactionService(nil, nil, nil, nil,
+			&transferApprovalRepositoryMock{
+				errFindAllExpired: errors.New("internal db error"),
+			}, nil, nil, nil, nil, nil,
+			TransferApprovalPolicy{}, HotAccountPolicy{}, nil, nil, SanctionsPolicy{}, time.Minute, "1.0.0", 100)
+
+		_, err := svc.ExpireDue(context.Background())
+
+		assert.ErrorContains(t, err, "internal db error")
+	})
 }
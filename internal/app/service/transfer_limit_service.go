@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+)
+
+type TransferLimitRepository interface {
+	FindByAccountID(ctx context.Context, accountID int64) (model.AccountTransferLimit, error)
+	Upsert(ctx context.Context, limit *model.AccountTransferLimit) error
+}
+
+type DebitUsageRepository interface {
+	FindDebitUsageTx(ctx context.Context, tx *sql.Tx, accountID int64, excludeTransactionID string,
+		window model.DebitUsageWindow) (model.DebitUsage, error)
+}
+
+// TransferLimiter evaluates the outgoing limits of an account within the transfer transaction.
+type TransferLimiter interface {
+	CheckTx(ctx context.Context, tx *sql.Tx, accountID int64, amount decimal.Decimal, transactionID string) error
+}
+
+type TransferLimitService struct {
+	transferLimitRepository TransferLimitRepository
+	debitUsageRepository    DebitUsageRepository
+	accountRepository       AccountRepository
+	limits                  model.TransferLimits
+}
+
+func NewTransferLimitService(transferLimitRepository TransferLimitRepository,
+	debitUsageRepository DebitUsageRepository, accountRepository AccountRepository,
+	limits model.TransferLimits,
+) *TransferLimitService {
+	return &TransferLimitService{
+		transferLimitRepository: transferLimitRepository,
+		debitUsageRepository:    debitUsageRepository,
+		accountRepository:       accountRepository,
+		limits:                  limits,
+	}
+}
+
+// CheckTx rejects a debit that would exceed one of the account limits. Usage is computed from the
+// balance_debited events of the account, the caller must hold the account row lock so that concurrent
+// transfers of the same account are evaluated one after another.
+func (s *TransferLimitService) CheckTx(ctx context.Context, dbTx *sql.Tx, accountID int64,
+	amount decimal.Decimal, transactionID string,
+) error {
+	limits, _, err := s.effectiveLimits(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if limits.PerTransaction.IsPositive() && amount.GreaterThan(limits.PerTransaction) {
+		return limitExceeded(ErrPerTransactionLimitExceeded, limits.PerTransaction.String())
+	}
+
+	if !limits.Daily.IsPositive() && !limits.Monthly.IsPositive() && limits.HourlyCount <= 0 {
+		return nil
+	}
+
+	usage, err := s.debitUsageRepository.FindDebitUsageTx(ctx, dbTx, accountID, transactionID,
+		model.NewDebitUsageWindow(time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to find debit usage: %w", err)
+	}
+
+	if limits.Daily.IsPositive() && usage.Daily.Add(amount).GreaterThan(limits.Daily) {
+		return limitExceeded(ErrDailyLimitExceeded, limits.Daily.String())
+	}
+
+	if limits.Monthly.IsPositive() && usage.Monthly.Add(amount).GreaterThan(limits.Monthly) {
+		return limitExceeded(ErrMonthlyLimitExceeded, limits.Monthly.String())
+	}
+
+	if limits.HourlyCount > 0 && usage.HourlyCount >= limits.HourlyCount {
+		return limitExceeded(ErrHourlyCountLimitExceeded, limits.HourlyCount)
+	}
+
+	return nil
+}
+
+// GetTransferLimits godoc
+// @Summary      Get Transfer Limits
+// @Description  Get the effective outgoing transfer limits of an Account and its overrides
+// @Tags         Account
+// @ID           getTransferLimits
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Success      200  {object}  dto.TransferLimitResponse	"Transfer limits"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/accounts/{id}/limits [get].
+func (s *TransferLimitService) GetTransferLimits(ctx context.Context,
+	req dto.GetAccountRequest,
+) (dto.TransferLimitResponse, error) {
+	if _, err := s.accountRepository.FindByID(ctx, req.ID); err != nil {
+		return dto.TransferLimitResponse{}, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	limits, override, err := s.effectiveLimits(ctx, req.ID)
+	if err != nil {
+		return dto.TransferLimitResponse{}, err
+	}
+
+	return dto.TransferLimitResponse{
+		AccountID:      req.ID,
+		PerTransaction: limits.PerTransaction,
+		Daily:          limits.Daily,
+		Monthly:        limits.Monthly,
+		HourlyCount:    limits.HourlyCount,
+		Override: dto.TransferLimitOverride{
+			PerTransaction: override.PerTransaction,
+			Daily:          override.Daily,
+			Monthly:        override.Monthly,
+			HourlyCount:    override.HourlyCount,
+		},
+	}, nil
+}
+
+// SetTransferLimits godoc
+// @Summary      Set Transfer Limits
+// @Description  Override the global outgoing transfer limits of an Account, a null limit uses the global one
+// @Tags         Account
+// @ID           setTransferLimits
+// @Produce      json
+// @Param        id	path		string	true	"Account ID"
+// @Param        req body set transfer limits	body		dto.SetTransferLimitRequest	true	"Limits"
+// @Success      204  "No content"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Record not found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /admin/accounts/{id}/limits [put].
+func (s *TransferLimitService) SetTransferLimits(ctx context.Context, req dto.SetTransferLimitRequest) error {
+	if _, err := s.accountRepository.FindByID(ctx, req.ID); err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	limit := &model.AccountTransferLimit{
+		AccountID:      req.ID,
+		PerTransaction: req.PerTransaction,
+		Daily:          req.Daily,
+		Monthly:        req.Monthly,
+		HourlyCount:    req.HourlyCount,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := s.transferLimitRepository.Upsert(ctx, limit); err != nil {
+		return fmt.Errorf("failed to upsert transfer limit: %w", err)
+	}
+
+	return nil
+}
+
+// effectiveLimits applies the account override, if any, on top of the global limits.
+func (s *TransferLimitService) effectiveLimits(ctx context.Context,
+	accountID int64,
+) (model.TransferLimits, model.AccountTransferLimit, error) {
+	override, err := s.transferLimitRepository.FindByAccountID(ctx, accountID)
+	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
+		return model.TransferLimits{}, model.AccountTransferLimit{}, fmt.Errorf("failed to find transfer limit: %w", err)
+	}
+
+	return override.Apply(s.limits), override, nil
+}
+
+func limitExceeded(err exception.ApplicationError, limit interface{}) exception.ApplicationError {
+	err.MessageVars = map[string]interface{}{
+		"limit": limit,
+	}
+
+	return err
+}
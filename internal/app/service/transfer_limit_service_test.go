@@ -0,0 +1,147 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferLimitService_CheckTx(t *testing.T) {
+	globalLimits := model.TransferLimits{
+		PerTransaction: decimal.NewFromInt(1000),
+		Daily:          decimal.NewFromInt(2000),
+		Monthly:        decimal.NewFromInt(10000),
+		HourlyCount:    5,
+	}
+
+	testCheck := func(
+		override *model.AccountTransferLimit,
+		usage model.DebitUsage,
+		amount decimal.Decimal,
+		wantErr error,
+	) func(t *testing.T) {
+		return func(t *testing.T) {
+			transferLimitRepository := &transferLimitRepositoryMock{
+				errFindByAccountID: []error{exception.ErrRecordNotFound},
+			}
+			if override != nil {
+				transferLimitRepository.errFindByAccountID = []error{nil}
+				transferLimitRepository.limit = *override
+			}
+
+			debitUsageRepository := &debitUsageRepositoryMock{usage: usage}
+
+			svc := NewTransferLimitService(transferLimitRepository, debitUsageRepository, nil, globalLimits)
+
+			err := svc.CheckTx(context.Background(), nil, 1, amount, "tx-12345")
+			if wantErr != nil {
+				assert.ErrorIs(t, err, wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "tx-12345", debitUsageRepository.excludedTxID)
+			}
+		}
+	}
+
+	t.Run("success", testCheck(nil, model.DebitUsage{
+		Daily:       decimal.NewFromInt(500),
+		Monthly:     decimal.NewFromInt(500),
+		HourlyCount: 1,
+	}, decimal.NewFromInt(1000), nil))
+
+	t.Run("error_per_transaction", testCheck(nil, model.DebitUsage{}, decimal.NewFromInt(1001),
+		ErrPerTransactionLimitExceeded))
+
+	t.Run("error_daily", testCheck(nil, model.DebitUsage{
+		Daily:   decimal.NewFromInt(1500),
+		Monthly: decimal.NewFromInt(1500),
+	}, decimal.NewFromInt(501), ErrDailyLimitExceeded))
+
+	t.Run("error_monthly", testCheck(nil, model.DebitUsage{
+		Monthly: decimal.NewFromInt(9500),
+	}, decimal.NewFromInt(501), ErrMonthlyLimitExceeded))
+
+	t.Run("error_hourly_count", testCheck(nil, model.DebitUsage{
+		HourlyCount: 5,
+	}, decimal.NewFromInt(1), ErrHourlyCountLimitExceeded))
+
+	dailyOverride := decimal.NewFromInt(5000)
+	disabledPerTransaction := decimal.Zero
+
+	t.Run("success_account_override", testCheck(&model.AccountTransferLimit{
+		AccountID:      1,
+		PerTransaction: &disabledPerTransaction,
+		Daily:          &dailyOverride,
+	}, model.DebitUsage{
+		Daily:   decimal.NewFromInt(1500),
+		Monthly: decimal.NewFromInt(1500),
+	}, decimal.NewFromInt(3000), nil))
+
+	t.Run("error_find_usage", func(t *testing.T) {
+		svc := NewTransferLimitService(&transferLimitRepositoryMock{
+			errFindByAccountID: []error{exception.ErrRecordNotFound},
+		}, &debitUsageRepositoryMock{
+			errFindDebitUsageTx: errors.New("internal db error"),
+		}, nil, globalLimits)
+
+		err := svc.CheckTx(context.Background(), nil, 1, decimal.NewFromInt(1), "tx-12345")
+		assert.ErrorContains(t, err, "internal db error")
+	})
+
+	t.Run("no_limits_skip_usage", func(t *testing.T) {
+		debitUsageRepository := &debitUsageRepositoryMock{}
+		svc := NewTransferLimitService(&transferLimitRepositoryMock{
+			errFindByAccountID: []error{exception.ErrRecordNotFound},
+		}, debitUsageRepository, nil, model.TransferLimits{})
+
+		err := svc.CheckTx(context.Background(), nil, 1, decimal.NewFromInt(1000000), "tx-12345")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, debitUsageRepository.findCallCount)
+	})
+}
+
+func TestTransferLimitService_SetTransferLimits(t *testing.T) {
+	daily := decimal.NewFromInt(5000)
+
+	t.Run("error_account_not_found", func(t *testing.T) {
+		svc := NewTransferLimitService(&transferLimitRepositoryMock{}, nil, &accountRepositoryMock{
+			errFindByID: []error{exception.ErrRecordNotFound},
+		}, model.TransferLimits{})
+
+		err := svc.SetTransferLimits(context.Background(), dto.SetTransferLimitRequest{ID: 1, Daily: &daily})
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		transferLimitRepository := &transferLimitRepositoryMock{
+			errUpsert:          []error{nil},
+			errFindByAccountID: []error{nil},
+		}
+		svc := NewTransferLimitService(transferLimitRepository, nil, &accountRepositoryMock{
+			errFindByID: []error{nil, nil},
+		}, model.TransferLimits{Daily: decimal.NewFromInt(1000), HourlyCount: 10})
+
+		err := svc.SetTransferLimits(context.Background(), dto.SetTransferLimitRequest{ID: 1, Daily: &daily})
+		assert.NoError(t, err)
+		assert.Equal(t, &daily, transferLimitRepository.upserted[0].Daily)
+		assert.Nil(t, transferLimitRepository.upserted[0].HourlyCount)
+
+		transferLimitRepository.limit = transferLimitRepository.upserted[0]
+
+		got, err := svc.GetTransferLimits(context.Background(), dto.GetAccountRequest{ID: 1})
+		assert.NoError(t, err)
+		assert.True(t, got.Daily.Equal(daily))
+		assert.Equal(t, 10, got.HourlyCount)
+		assert.Equal(t, &daily, got.Override.Daily)
+		assert.WithinDuration(t, time.Now(), transferLimitRepository.upserted[0].UpdatedAt, time.Minute)
+	})
+}
@@ -0,0 +1,593 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/webhook"
+)
+
+const (
+	// webhookDeliverySort is the only sort of the deliveries, the cursor is the id of the last delivery of a page.
+	webhookDeliverySort = "-id"
+	// maxWebhookErrorLength is the size of the error columns of the delivery log.
+	maxWebhookErrorLength = 255
+)
+
+type WebhookRepository interface {
+	WithTransaction(ctx context.Context, txFunc func(context.Context, *sql.Tx) error) error
+	Create(ctx context.Context, webhook *model.Webhook) error
+	Update(ctx context.Context, webhook *model.Webhook) error
+	FindByID(ctx context.Context, id int64) (model.Webhook, error)
+	FindAll(ctx context.Context, status model.WebhookStatus) ([]model.Webhook, error)
+	FindOutboxEventsForUpdateTx(ctx context.Context, tx *sql.Tx, limit int) ([]model.Event, error)
+	DeleteOutboxTx(ctx context.Context, tx *sql.Tx, eventIDs []int64) error
+	CreateDeliveryTx(ctx context.Context, tx *sql.Tx, delivery *model.WebhookDelivery) error
+	FindAllDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]int64, error)
+	FindDueDeliveryByIDForUpdateTx(ctx context.Context, tx *sql.Tx, id int64,
+		now time.Time) (model.WebhookDelivery, error)
+	UpdateDeliveryTx(ctx context.Context, tx *sql.Tx, delivery *model.WebhookDelivery) error
+	CreateAttemptTx(ctx context.Context, tx *sql.Tx, attempt *model.WebhookDeliveryAttempt) error
+	FindAllDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error)
+	Requeue(ctx context.Context, webhookID int64, deliveryIDs []int64, now time.Time) (int, error)
+}
+
+type WebhookSender interface {
+	Send(ctx context.Context, req webhook.Request) (webhook.Result, error)
+}
+
+// WebhookRetryPolicy bounds the attempts of a delivery, the delay between two attempts doubles from BackoffBase up
+// to BackoffMax.
+type WebhookRetryPolicy struct {
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+type WebhookService struct {
+	webhookRepository WebhookRepository
+	sender            WebhookSender
+	retryPolicy       WebhookRetryPolicy
+	batchSize         int
+}
+
+func NewWebhookService(webhookRepository WebhookRepository, sender WebhookSender, retryPolicy WebhookRetryPolicy,
+	batchSize int,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepository: webhookRepository,
+		sender:            sender,
+		retryPolicy:       retryPolicy,
+		batchSize:         batchSize,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary      Create Webhook
+// @Description  Subscribe a URL to the events of the accounts. Every callback is signed with the secret, see the
+// @Description  X-Webhook-Signature header. Empty event_types or account_ids subscribe to all of them
+// @Tags         Webhook
+// @ID           createWebhook
+// @Accept       json
+// @Produce      json
+// @Param        req body dto.CreateWebhookRequest	body		dto.CreateWebhookRequest	true	"Webhook"
+// @Success      201  {object}  dto.WebhookResponse	"Webhook"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /webhooks [post].
+func (s *WebhookService) CreateWebhook(ctx context.Context,
+	req dto.CreateWebhookRequest,
+) (dto.WebhookResponse, error) {
+	now := time.Now()
+
+	hook := model.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: make([]model.EventType, 0, len(req.EventTypes)),
+		AccountIDs: req.AccountIDs,
+		Status:     model.WebhookStatusActive,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	for _, eventType := range req.EventTypes {
+		hook.EventTypes = append(hook.EventTypes, model.EventType(eventType))
+	}
+
+	if err := s.webhookRepository.Create(ctx, &hook); err != nil {
+		return dto.WebhookResponse{}, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return newWebhookResponse(hook), nil
+}
+
+// GetWebhook godoc
+// @Summary      Get Webhook
+// @Description  Get a webhook by ID, its secret is not returned
+// @Tags         Webhook
+// @ID           getWebhook
+// @Produce      json
+// @Param        id	path		int	true	"Webhook ID"
+// @Success      200  {object}  dto.WebhookResponse	"Webhook"
+// @Failure      404  {object}  dto.ErrorResponse	"Not Found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /webhooks/{id} [get].
+func (s *WebhookService) GetWebhook(ctx context.Context, req dto.WebhookIDRequest) (dto.WebhookResponse, error) {
+	hook, err := s.webhookRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return dto.WebhookResponse{}, fmt.Errorf("failed to find webhook: %w", err)
+	}
+
+	return newWebhookResponse(hook), nil
+}
+
+// ListWebhooks godoc
+// @Summary      List Webhooks
+// @Description  List the webhooks, optionally by status
+// @Tags         Webhook
+// @ID           listWebhooks
+// @Produce      json
+// @Param        status	query		string	false	"Status"	Enums(active, disabled)
+// @Success      200  {object}  dto.ListResponse[dto.WebhookResponse]	"Webhooks"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /webhooks [get].
+func (s *WebhookService) ListWebhooks(ctx context.Context,
+	req dto.ListWebhooksRequest,
+) (dto.ListResponse[dto.WebhookResponse], error) {
+	hooks, err := s.webhookRepository.FindAll(ctx, model.WebhookStatus(req.Status))
+	if err != nil {
+		return dto.ListResponse[dto.WebhookResponse]{}, fmt.Errorf("failed to find webhooks: %w", err)
+	}
+
+	resp := dto.ListResponse[dto.WebhookResponse]{
+		Data: make([]dto.WebhookResponse, 0, len(hooks)),
+	}
+
+	for _, hook := range hooks {
+		resp.Data = append(resp.Data, newWebhookResponse(hook))
+	}
+
+	return resp, nil
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete Webhook
+// @Description  Disable a webhook, no more callbacks are sent to it. Its deliveries are kept in the delivery log
+// @Tags         Webhook
+// @ID           deleteWebhook
+// @Param        id	path		int	true	"Webhook ID"
+// @Success      204  "No Content"
+// @Failure      404  {object}  dto.ErrorResponse	"Not Found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /webhooks/{id} [delete].
+func (s *WebhookService) DeleteWebhook(ctx context.Context, req dto.WebhookIDRequest) error {
+	hook, err := s.webhookRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find webhook: %w", err)
+	}
+
+	if hook.Status == model.WebhookStatusDisabled {
+		return nil
+	}
+
+	hook.Status = model.WebhookStatusDisabled
+	hook.UpdatedAt = time.Now()
+
+	if err := s.webhookRepository.Update(ctx, &hook); err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries godoc
+// @Summary      List Webhook Deliveries
+// @Description  List the deliveries of a webhook with their attempts, newest first, one page at a time
+// @Tags         Webhook
+// @ID           listWebhookDeliveries
+// @Produce      json
+// @Param        id	path		int	true	"Webhook ID"
+// @Param        status	query		string	false	"Status"	Enums(pending, succeeded, failed)
+// @Param        limit	query		int	false	"Page size, up to 100"
+// @Param        cursor	query		string	false	"Cursor of the next page"
+// @Success      200  {object}  dto.ListResponse[dto.WebhookDeliveryResponse]	"Deliveries"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Not Found"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /webhooks/{id}/deliveries [get].
+func (s *WebhookService) ListDeliveries(ctx context.Context,
+	req dto.ListWebhookDeliveriesRequest,
+) (dto.ListResponse[dto.WebhookDeliveryResponse], error) {
+	if _, err := s.webhookRepository.FindByID(ctx, req.ID); err != nil {
+		return dto.ListResponse[dto.WebhookDeliveryResponse]{}, fmt.Errorf("failed to find webhook: %w", err)
+	}
+
+	filter := model.WebhookDeliveryFilter{
+		WebhookID: req.ID,
+		Status:    model.WebhookDeliveryStatus(req.Status),
+		// one more delivery tells whether there is a next page
+		Limit: req.Limit + 1,
+	}
+
+	if req.Cursor != "" {
+		cursor, err := pagination.Decode(req.Cursor, webhookDeliverySort)
+		if err != nil {
+			return dto.ListResponse[dto.WebhookDeliveryResponse]{}, ErrInvalidCursor
+		}
+
+		filter.BeforeID = cursor.ID
+	}
+
+	deliveries, err := s.webhookRepository.FindAllDeliveries(ctx, filter)
+	if err != nil {
+		return dto.ListResponse[dto.WebhookDeliveryResponse]{}, fmt.Errorf("failed to find deliveries: %w", err)
+	}
+
+	deliveries, nextCursor := pagination.Page(deliveries, req.Limit,
+		func(delivery model.WebhookDelivery) pagination.Cursor {
+			return pagination.Cursor{Sort: webhookDeliverySort, ID: delivery.ID}
+		})
+
+	resp := dto.ListResponse[dto.WebhookDeliveryResponse]{
+		Data:       make([]dto.WebhookDeliveryResponse, 0, len(deliveries)),
+		NextCursor: nextCursor,
+	}
+
+	for _, delivery := range deliveries {
+		resp.Data = append(resp.Data, newWebhookDeliveryResponse(delivery))
+	}
+
+	return resp, nil
+}
+
+// Redeliver godoc
+// @Summary      Redeliver Webhook Events
+// @Description  Send deliveries of a webhook again with a new series of attempts, the failed deliveries when no
+// @Description  delivery_ids are given. Pending deliveries are left as is
+// @Tags         Webhook
+// @ID           redeliverWebhook
+// @Accept       json
+// @Produce      json
+// @Param        id	path		int	true	"Webhook ID"
+// @Param        req body dto.RedeliverWebhookRequest	body		dto.RedeliverWebhookRequest	false	"Deliveries"
+// @Success      200  {object}  dto.RedeliverWebhookResponse	"Requeued deliveries"
+// @Failure      400  {object}  dto.ErrorResponse	"Bad Request"
+// @Failure      404  {object}  dto.ErrorResponse	"Not Found"
+// @Failure      409  {object}  dto.ErrorResponse	"Conflict"
+// @Failure      500  {object}  dto.ErrorResponse	"Internal Server Error"
+// @Router       /webhooks/{id}/redeliver [post].
+func (s *WebhookService) Redeliver(ctx context.Context,
+	req dto.RedeliverWebhookRequest,
+) (dto.RedeliverWebhookResponse, error) {
+	hook, err := s.webhookRepository.FindByID(ctx, req.ID)
+	if err != nil {
+		return dto.RedeliverWebhookResponse{}, fmt.Errorf("failed to find webhook: %w", err)
+	}
+
+	if hook.Status != model.WebhookStatusActive {
+		return dto.RedeliverWebhookResponse{}, ErrWebhookDisabled
+	}
+
+	requeued, err := s.webhookRepository.Requeue(ctx, hook.ID, req.DeliveryIDs, time.Now())
+	if err != nil {
+		return dto.RedeliverWebhookResponse{}, fmt.Errorf("failed to requeue deliveries: %w", err)
+	}
+
+	return dto.RedeliverWebhookResponse{Requeued: requeued}, nil
+}
+
+// Dispatch fans the events of the outbox out to the deliveries of the matching webhooks, it returns the number of
+// deliveries created. An event is removed from the outbox in the transaction creating its deliveries, so that it is
+// dispatched exactly once.
+func (s *WebhookService) Dispatch(ctx context.Context) (int, error) {
+	dispatched := 0
+
+	for {
+		events := 0
+
+		err := s.webhookRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+			outbox, err := s.webhookRepository.FindOutboxEventsForUpdateTx(ctx, dbTx, s.batchSize)
+			if err != nil {
+				return fmt.Errorf("failed to find outbox events: %w", err)
+			}
+
+			events = len(outbox)
+			if events == 0 {
+				return nil
+			}
+
+			hooks, err := s.webhookRepository.FindAll(ctx, model.WebhookStatusActive)
+			if err != nil {
+				return fmt.Errorf("failed to find webhooks: %w", err)
+			}
+
+			created, err := s.dispatchEventsTx(ctx, dbTx, outbox, hooks)
+			if err != nil {
+				return err
+			}
+
+			dispatched += created
+
+			return nil
+		})
+		if err != nil {
+			return dispatched, err
+		}
+
+		if events < s.batchSize {
+			return dispatched, nil
+		}
+	}
+}
+
+// DeliverDue sends the due deliveries of the active webhooks, each in its own transaction. It returns the number of
+// deliveries attempted, a delivery that fails is retried with a backoff until its attempts are exhausted.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	deliveryIDs, err := s.webhookRepository.FindAllDueDeliveryIDs(ctx, time.Now(), s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find due webhook deliveries: %w", err)
+	}
+
+	attempted := 0
+
+	for _, deliveryID := range deliveryIDs {
+		sent, err := s.deliver(ctx, deliveryID)
+		if err != nil {
+			// keep going, the delivery is still due and is retried on the next run
+			slog.ErrorContext(ctx, "failed to deliver webhook event",
+				slog.Int64("webhook_delivery_id", deliveryID),
+				slog.String("error", err.Error()))
+
+			continue
+		}
+
+		if sent {
+			attempted++
+		}
+	}
+
+	return attempted, nil
+}
+
+// dispatchEventsTx creates the deliveries of the outbox events and removes them from the outbox.
+func (s *WebhookService) dispatchEventsTx(ctx context.Context, dbTx *sql.Tx, outbox []model.Event,
+	hooks []model.Webhook,
+) (int, error) {
+	created := 0
+	eventIDs := make([]int64, 0, len(outbox))
+	now := time.Now()
+
+	for _, event := range outbox {
+		eventIDs = append(eventIDs, event.ID)
+
+		payload := newWebhookEventPayload(event)
+
+		var body []byte
+
+		for _, hook := range hooks {
+			if !hook.Matches(event.EventType, payload.AccountID) {
+				continue
+			}
+
+			if body == nil {
+				var err error
+
+				if body, err = json.Marshal(payload); err != nil {
+					return 0, fmt.Errorf("failed to marshal webhook payload: %w", err)
+				}
+			}
+
+			delivery := model.WebhookDelivery{
+				WebhookID:     hook.ID,
+				EventID:       event.ID,
+				EventType:     event.EventType,
+				Payload:       body,
+				Status:        model.WebhookDeliveryStatusPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+
+			if err := s.webhookRepository.CreateDeliveryTx(ctx, dbTx, &delivery); err != nil {
+				return 0, fmt.Errorf("failed to create webhook delivery: %w", err)
+			}
+
+			created++
+		}
+	}
+
+	if err := s.webhookRepository.DeleteOutboxTx(ctx, dbTx, eventIDs); err != nil {
+		return 0, fmt.Errorf("failed to delete outbox events: %w", err)
+	}
+
+	return created, nil
+}
+
+// deliver makes an attempt of a delivery and records its outcome, it reports false when the delivery is no longer
+// due or is locked by another worker.
+func (s *WebhookService) deliver(ctx context.Context, deliveryID int64) (bool, error) {
+	sent := false
+
+	err := s.webhookRepository.WithTransaction(ctx, func(ctx context.Context, dbTx *sql.Tx) error {
+		delivery, err := s.webhookRepository.FindDueDeliveryByIDForUpdateTx(ctx, dbTx, deliveryID, time.Now())
+		if err != nil && errors.Is(err, exception.ErrRecordNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to find webhook delivery: %w", err)
+		}
+
+		hook, err := s.webhookRepository.FindByID(ctx, delivery.WebhookID)
+		if err != nil {
+			return fmt.Errorf("failed to find webhook: %w", err)
+		}
+
+		result, sendErr := s.sender.Send(ctx, webhook.Request{
+			URL:        hook.URL,
+			Secret:     hook.Secret,
+			DeliveryID: delivery.ID,
+			EventType:  string(delivery.EventType),
+			Payload:    delivery.Payload,
+		})
+
+		sent = true
+
+		return s.recordAttemptTx(ctx, dbTx, &delivery, result, sendErr)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return sent, nil
+}
+
+// recordAttemptTx appends the attempt to the delivery log and schedules the next attempt of a delivery that failed.
+func (s *WebhookService) recordAttemptTx(ctx context.Context, dbTx *sql.Tx, delivery *model.WebhookDelivery,
+	result webhook.Result, sendErr error,
+) error {
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.LastStatusCode = result.StatusCode
+	delivery.LastError = ""
+	delivery.UpdatedAt = now
+
+	attempt := model.WebhookDeliveryAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		StatusCode: result.StatusCode,
+		Duration:   result.Duration,
+		CreatedAt:  now,
+	}
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = model.WebhookDeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.retryPolicy.MaxAttempts:
+		delivery.Status = model.WebhookDeliveryStatusFailed
+	default:
+		delivery.NextAttemptAt = now.Add(webhook.Backoff(s.retryPolicy.BackoffBase, s.retryPolicy.BackoffMax,
+			delivery.Attempts))
+	}
+
+	if sendErr != nil {
+		attempt.Error = truncateWebhookError(sendErr.Error())
+		delivery.LastError = attempt.Error
+	}
+
+	if err := s.webhookRepository.CreateAttemptTx(ctx, dbTx, &attempt); err != nil {
+		return fmt.Errorf("failed to create webhook delivery attempt: %w", err)
+	}
+
+	if err := s.webhookRepository.UpdateDeliveryTx(ctx, dbTx, delivery); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// newWebhookEventPayload returns the body of the callbacks of an event, the events of a shard of a hot account are
+// sent as events of the account.
+func newWebhookEventPayload(event model.Event) dto.WebhookEventPayload {
+	payload := dto.WebhookEventPayload{
+		EventID:        event.ID,
+		EventType:      string(event.EventType),
+		AccountID:      event.AggregateID,
+		TransactionID:  event.TransactionID,
+		SequenceNumber: event.SequenceNumber,
+		Data:           json.RawMessage("{}"),
+		CreatedAt:      event.CreatedAt,
+	}
+
+	if data, ok := event.EventData.([]byte); ok && json.Valid(data) {
+		payload.Data = data
+	}
+
+	if event.AggregateType == model.AggregateTypeAccountShard {
+		var shardData struct {
+			AccountID *int64 `json:"account_id"`
+		}
+
+		if err := json.Unmarshal(payload.Data, &shardData); err == nil && shardData.AccountID != nil {
+			payload.AccountID = *shardData.AccountID
+		}
+	}
+
+	return payload
+}
+
+// truncateWebhookError cuts an error to the size of the delivery log, on a rune boundary.
+func truncateWebhookError(message string) string {
+	runes := []rune(message)
+	if len(runes) <= maxWebhookErrorLength {
+		return message
+	}
+
+	return string(runes[:maxWebhookErrorLength])
+}
+
+func newWebhookResponse(hook model.Webhook) dto.WebhookResponse {
+	resp := dto.WebhookResponse{
+		ID:         hook.ID,
+		URL:        hook.URL,
+		EventTypes: make([]string, 0, len(hook.EventTypes)),
+		AccountIDs: hook.AccountIDs,
+		Status:     string(hook.Status),
+		CreatedAt:  hook.CreatedAt,
+		UpdatedAt:  hook.UpdatedAt,
+	}
+
+	if resp.AccountIDs == nil {
+		resp.AccountIDs = []int64{}
+	}
+
+	for _, eventType := range hook.EventTypes {
+		resp.EventTypes = append(resp.EventTypes, string(eventType))
+	}
+
+	return resp
+}
+
+func newWebhookDeliveryResponse(delivery model.WebhookDelivery) dto.WebhookDeliveryResponse {
+	resp := dto.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      string(delivery.EventType),
+		Payload:        delivery.Payload,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		AttemptLog:     make([]dto.WebhookDeliveryAttemptResponse, 0, len(delivery.AttemptLog)),
+		CreatedAt:      delivery.CreatedAt,
+	}
+
+	if delivery.Status == model.WebhookDeliveryStatusPending {
+		resp.NextAttemptAt = &delivery.NextAttemptAt
+	}
+
+	for _, attempt := range delivery.AttemptLog {
+		resp.AttemptLog = append(resp.AttemptLog, dto.WebhookDeliveryAttemptResponse{
+			Attempt:    attempt.Attempt,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMS: attempt.Duration.Milliseconds(),
+			CreatedAt:  attempt.CreatedAt,
+		})
+	}
+
+	return resp
+}
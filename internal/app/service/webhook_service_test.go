@@ -0,0 +1,288 @@
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/pagination"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+var testWebhookRetryPolicy = WebhookRetryPolicy{
+	MaxAttempts: 3,
+	BackoffBase: 30 * time.Second,
+	BackoffMax:  time.Hour,
+}
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	repo := &webhookRepositoryMock{}
+	svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+
+	resp, err := svc.CreateWebhook(context.Background(), dto.CreateWebhookRequest{
+		URL:        "https://partner.example.com/hooks",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{"balance_debited", "balance_credited"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), resp.ID)
+	assert.Equal(t, "active", resp.Status)
+	assert.Equal(t, []string{"balance_debited", "balance_credited"}, resp.EventTypes)
+	assert.Empty(t, resp.AccountIDs)
+
+	if assert.Len(t, repo.created, 1) {
+		assert.Equal(t, "0123456789abcdef", repo.created[0].Secret)
+		assert.Equal(t, []model.EventType{model.EventTypeDebitBalance, model.EventTypeCreditBalance},
+			repo.created[0].EventTypes)
+	}
+}
+
+func TestWebhookService_DeleteWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := &webhookRepositoryMock{
+			webhook:     model.Webhook{ID: 1, Status: model.WebhookStatusActive},
+			errFindByID: []error{nil},
+		}
+		svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+
+		assert.NoError(t, svc.DeleteWebhook(context.Background(), dto.WebhookIDRequest{ID: 1}))
+		if assert.Len(t, repo.updated, 1) {
+			assert.Equal(t, model.WebhookStatusDisabled, repo.updated[0].Status)
+		}
+	})
+
+	t.Run("success_already_disabled", func(t *testing.T) {
+		repo := &webhookRepositoryMock{
+			webhook:     model.Webhook{ID: 1, Status: model.WebhookStatusDisabled},
+			errFindByID: []error{nil},
+		}
+		svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+
+		assert.NoError(t, svc.DeleteWebhook(context.Background(), dto.WebhookIDRequest{ID: 1}))
+		assert.Empty(t, repo.updated)
+	})
+}
+
+func TestWebhookService_ListDeliveries(t *testing.T) {
+	t.Run("success_next_page", func(t *testing.T) {
+		repo := &webhookRepositoryMock{
+			webhook:     model.Webhook{ID: 1, Status: model.WebhookStatusActive},
+			errFindByID: []error{nil},
+			deliveries: []model.WebhookDelivery{
+				{ID: 9, Status: model.WebhookDeliveryStatusSucceeded, Payload: []byte(`{"event_id":3}`)},
+				{ID: 8, Status: model.WebhookDeliveryStatusPending, Payload: []byte(`{"event_id":2}`)},
+				{ID: 7, Status: model.WebhookDeliveryStatusFailed, Payload: []byte(`{"event_id":1}`)},
+			},
+		}
+		svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+		cursor := pagination.Cursor{Sort: "-id", ID: 10}.Encode()
+
+		resp, err := svc.ListDeliveries(context.Background(),
+			dto.ListWebhookDeliveriesRequest{ID: 1, Limit: 2, Cursor: cursor})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Data, 2)
+		assert.Nil(t, resp.Data[0].NextAttemptAt)
+		assert.NotNil(t, resp.Data[1].NextAttemptAt)
+		assert.Equal(t, model.WebhookDeliveryFilter{WebhookID: 1, BeforeID: 10, Limit: 3}, repo.filters[0])
+
+		if assert.NotNil(t, resp.NextCursor) {
+			next, err := pagination.Decode(*resp.NextCursor, "-id")
+			assert.NoError(t, err)
+			assert.Equal(t, int64(8), next.ID)
+		}
+	})
+
+	t.Run("error_invalid_cursor", func(t *testing.T) {
+		repo := &webhookRepositoryMock{
+			webhook:     model.Webhook{ID: 1},
+			errFindByID: []error{nil},
+		}
+		svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+
+		_, err := svc.ListDeliveries(context.Background(),
+			dto.ListWebhookDeliveriesRequest{ID: 1, Limit: 2, Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		repo := &webhookRepositoryMock{
+			webhook:     model.Webhook{ID: 1, Status: model.WebhookStatusActive},
+			errFindByID: []error{nil},
+			requeued:    2,
+		}
+		svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+
+		resp, err := svc.Redeliver(context.Background(), dto.RedeliverWebhookRequest{ID: 1, DeliveryIDs: []int64{4, 5}})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, resp.Requeued)
+		assert.Equal(t, []int64{4, 5}, repo.requeuedIDs)
+	})
+
+	t.Run("error_disabled", func(t *testing.T) {
+		repo := &webhookRepositoryMock{
+			webhook:     model.Webhook{ID: 1, Status: model.WebhookStatusDisabled},
+			errFindByID: []error{nil},
+		}
+		svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+
+		_, err := svc.Redeliver(context.Background(), dto.RedeliverWebhookRequest{ID: 1})
+		assert.ErrorIs(t, err, ErrWebhookDisabled)
+	})
+
+	t.Run("error_not_found", func(t *testing.T) {
+		repo := &webhookRepositoryMock{errFindByID: []error{exception.ErrRecordNotFound}}
+		svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+
+		_, err := svc.Redeliver(context.Background(), dto.RedeliverWebhookRequest{ID: 1})
+		assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	})
+}
+
+func TestWebhookService_Dispatch(t *testing.T) {
+	createdAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	outbox := []model.Event{
+		{
+			ID: 1, AggregateID: 10, AggregateType: model.AggregateTypeAccount,
+			EventType: model.EventTypeDebitBalance, TransactionID: "tx-1", SequenceNumber: 4,
+			EventData: []byte(`{"amount":"25"}`), CreatedAt: createdAt,
+		},
+		{
+			ID: 2, AggregateID: 501, AggregateType: model.AggregateTypeAccountShard,
+			EventType: model.EventTypeCreditBalance, TransactionID: "tx-1", SequenceNumber: 1,
+			EventData: []byte(`{"amount":"25","account_id":20,"shard_no":1}`), CreatedAt: createdAt,
+		},
+		{
+			ID: 3, AggregateID: 10, AggregateType: model.AggregateTypeAccount,
+			EventType: model.EventTypeShardsConsolidated, CreatedAt: createdAt,
+		},
+	}
+
+	repo := &webhookRepositoryMock{
+		outbox: [][]model.Event{outbox},
+		webhooks: []model.Webhook{
+			{ID: 1, Status: model.WebhookStatusActive},
+			{ID: 2, Status: model.WebhookStatusActive, AccountIDs: []int64{20}},
+			{ID: 3, Status: model.WebhookStatusActive, EventTypes: []model.EventType{model.EventTypeDebitBalance}},
+		},
+	}
+	svc := NewWebhookService(repo, &webhookSenderMock{}, testWebhookRetryPolicy, 10)
+
+	dispatched, err := svc.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, dispatched)
+	assert.Equal(t, []int64{1, 2, 3}, repo.deletedOutbox)
+
+	deliveries := map[int64][]int64{}
+	for _, delivery := range repo.createdDeliveries {
+		assert.Equal(t, model.WebhookDeliveryStatusPending, delivery.Status)
+		deliveries[delivery.WebhookID] = append(deliveries[delivery.WebhookID], delivery.EventID)
+	}
+
+	assert.Equal(t, map[int64][]int64{1: {1, 2}, 2: {2}, 3: {1}}, deliveries)
+
+	var payload dto.WebhookEventPayload
+
+	assert.NoError(t, json.Unmarshal(repo.createdDeliveries[2].Payload, &payload))
+	assert.Equal(t, int64(20), payload.AccountID)
+	assert.Equal(t, "balance_credited", payload.EventType)
+	assert.JSONEq(t, `{"amount":"25","account_id":20,"shard_no":1}`, string(payload.Data))
+}
+
+func TestWebhookService_DeliverDue(t *testing.T) {
+	hook := model.Webhook{ID: 1, URL: "https://partner.example.com/hooks", Secret: "0123456789abcdef",
+		Status: model.WebhookStatusActive}
+
+	newRepo := func(attempts int) *webhookRepositoryMock {
+		return &webhookRepositoryMock{
+			deliveryIDs: []int64{7},
+			delivery: model.WebhookDelivery{ID: 7, WebhookID: 1, EventType: model.EventTypeDebitBalance,
+				Payload: []byte(`{"event_id":1}`), Status: model.WebhookDeliveryStatusPending, Attempts: attempts},
+			webhook:                           hook,
+			errFindByID:                       []error{nil},
+			errFindDueDeliveryByIDForUpdateTx: []error{nil},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		repo := newRepo(0)
+		sender := &webhookSenderMock{result: webhook.Result{StatusCode: http.StatusOK, Duration: time.Second}}
+		svc := NewWebhookService(repo, sender, testWebhookRetryPolicy, 10)
+
+		attempted, err := svc.DeliverDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+
+		if assert.Len(t, sender.requests, 1) {
+			assert.Equal(t, webhook.Request{URL: hook.URL, Secret: hook.Secret, DeliveryID: 7,
+				EventType: "balance_debited", Payload: []byte(`{"event_id":1}`)}, sender.requests[0])
+		}
+
+		delivery := repo.updatedDeliveries[0]
+		assert.Equal(t, model.WebhookDeliveryStatusSucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.NotNil(t, delivery.DeliveredAt)
+		assert.Equal(t, model.WebhookDeliveryAttempt{DeliveryID: 7, Attempt: 1, StatusCode: http.StatusOK,
+			Duration: time.Second, CreatedAt: repo.attempts[0].CreatedAt}, repo.attempts[0])
+	})
+
+	t.Run("success_retry_with_backoff", func(t *testing.T) {
+		repo := newRepo(1)
+		sender := &webhookSenderMock{
+			result:  webhook.Result{StatusCode: http.StatusServiceUnavailable},
+			errSend: webhook.ErrUnexpectedStatus,
+		}
+		svc := NewWebhookService(repo, sender, testWebhookRetryPolicy, 10)
+
+		before := time.Now()
+		_, err := svc.DeliverDue(context.Background())
+		assert.NoError(t, err)
+
+		delivery := repo.updatedDeliveries[0]
+		assert.Equal(t, model.WebhookDeliveryStatusPending, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+		assert.Equal(t, "unexpected status code", delivery.LastError)
+		assert.WithinRange(t, delivery.NextAttemptAt, before.Add(time.Minute), time.Now().Add(time.Minute))
+		assert.Equal(t, 2, repo.attempts[0].Attempt)
+	})
+
+	t.Run("success_failed_after_max_attempts", func(t *testing.T) {
+		repo := newRepo(2)
+		sender := &webhookSenderMock{errSend: errors.New(strings.Repeat("x", 300))}
+		svc := NewWebhookService(repo, sender, testWebhookRetryPolicy, 10)
+
+		_, err := svc.DeliverDue(context.Background())
+		assert.NoError(t, err)
+
+		delivery := repo.updatedDeliveries[0]
+		assert.Equal(t, model.WebhookDeliveryStatusFailed, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Zero(t, delivery.LastStatusCode)
+		assert.Len(t, delivery.LastError, maxWebhookErrorLength)
+	})
+
+	t.Run("success_skip_locked", func(t *testing.T) {
+		repo := newRepo(0)
+		repo.errFindDueDeliveryByIDForUpdateTx = []error{exception.ErrRecordNotFound}
+		sender := &webhookSenderMock{}
+		svc := NewWebhookService(repo, sender, testWebhookRetryPolicy, 10)
+
+		attempted, err := svc.DeliverDue(context.Background())
+		assert.NoError(t, err)
+		assert.Zero(t, attempted)
+		assert.Empty(t, sender.requests)
+		assert.Empty(t, repo.updatedDeliveries)
+	})
+}
@@ -0,0 +1,110 @@
+// Package bankstatement parses the bank statements of the bank account holding the customer funds.
+//
+// Three formats are supported: a CSV export with a header row, SWIFT MT940 and ISO 20022 CAMT.053. The booked
+// lines of every statement in a file are returned in file order, pending entries are skipped. An amount is
+// negative when the money left the bank account, and the booking date is a date (UTC).
+package bankstatement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatMT940   Format = "mt940"
+	FormatCAMT053 Format = "camt053"
+)
+
+var (
+	ErrUnknownFormat    = errors.New("unknown bank statement format")
+	ErrInvalidStatement = errors.New("invalid bank statement")
+)
+
+// Line is a booked line of a bank statement.
+type Line struct {
+	BookingDate time.Time
+	// Amount is negative for a debit of the bank account.
+	Amount      decimal.Decimal
+	Reference   string
+	Description string
+}
+
+// ParseFormat returns the format named by value, e.g. the format field of an upload.
+func ParseFormat(value string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(value)))
+
+	switch format {
+	case FormatCSV, FormatMT940, FormatCAMT053:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, value)
+	}
+}
+
+// DetectFormat guesses the format of a file from its extension, then from its content.
+func DetectFormat(fileName string, content []byte) Format {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV
+	case ".sta", ".mt940", ".940":
+		return FormatMT940
+	case ".xml":
+		return FormatCAMT053
+	}
+
+	trimmed := bytes.TrimSpace(content)
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatCAMT053
+	case bytes.Contains(trimmed, []byte(":20:")) && bytes.Contains(trimmed, []byte(":61:")):
+		return FormatMT940
+	default:
+		return FormatCSV
+	}
+}
+
+// Parse reads the booked lines of a statement file.
+func Parse(format Format, reader io.Reader) ([]Line, error) {
+	var (
+		lines []Line
+		err   error
+	)
+
+	switch format {
+	case FormatCSV:
+		lines, err = parseCSV(reader)
+	case FormatMT940:
+		lines, err = parseMT940(reader)
+	case FormatCAMT053:
+		lines, err = parseCAMT053(reader)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidStatement, format, err)
+	}
+
+	return lines, nil
+}
+
+// parseDate reads a date in layout as a date (UTC).
+func parseDate(layout, value string) (time.Time, error) {
+	date, err := time.Parse(layout, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	return date.UTC(), nil
+}
@@ -0,0 +1,191 @@
+//go:build unit
+
+package bankstatement
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCSV = `Date,Amount,Reference,Description
+2026-10-01,250.00,tx-deposit-1,Deposit from ACME
+2026-10-02,-40.5,,Payout
+`
+
+const testMT940 = `{1:F01BANKBEBBAXXX0000000000}{2:I940BANKBEBBXXXXN}{4:
+:20:STMT-20261002
+:25:BE68539007547034
+:28C:00001/001
+:60F:C261001EUR1000,00
+:61:2610011001C250,00NTRFtx-deposit-1//B1001
+:86:Deposit from ACME
+:61:2612310102D40,5NTRFNONREF
+:86:Payout
+ second row
+:61:2612311231RC10,NMSCtx-reversal
+:62F:C261231EUR1199,50
+-}`
+
+const testCAMT053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Ntry>
+        <NtryRef>ENTRY-1</NtryRef>
+        <Amt Ccy="EUR">250.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><Dt>2026-10-01</Dt></BookgDt>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>tx-deposit-1</EndToEndId></Refs>
+          <RmtInf><Ustrd>Deposit from ACME</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <NtryRef>ENTRY-2</NtryRef>
+        <Amt Ccy="EUR">10.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>PDNG</Cd></Sts>
+        <BookgDt><Dt>2026-10-02</Dt></BookgDt>
+      </Ntry>
+      <Ntry>
+        <NtryRef>ENTRY-3</NtryRef>
+        <Amt Ccy="EUR">70.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><DtTm>2026-10-02T23:30:00+02:00</DtTm></BookgDt>
+        <AddtlNtryInf>Payouts</AddtlNtryInf>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>tx-payout-1</EndToEndId></Refs>
+            <Amt Ccy="EUR">30.00</Amt>
+          </TxDtls>
+          <TxDtls>
+            <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+            <Amt Ccy="EUR">40.00</Amt>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+func date(value string) time.Time {
+	parsed, _ := time.Parse(time.DateOnly, value)
+
+	return parsed
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		format  Format
+		content string
+		want    []Line
+	}{
+		{
+			name:    "csv",
+			format:  FormatCSV,
+			content: testCSV,
+			want: []Line{
+				{BookingDate: date("2026-10-01"), Amount: decimal.RequireFromString("250"), Reference: "tx-deposit-1",
+					Description: "Deposit from ACME"},
+				{BookingDate: date("2026-10-02"), Amount: decimal.RequireFromString("-40.5"), Description: "Payout"},
+			},
+		},
+		{
+			name:    "mt940",
+			format:  FormatMT940,
+			content: testMT940,
+			want: []Line{
+				{BookingDate: date("2026-10-01"), Amount: decimal.RequireFromString("250"), Reference: "tx-deposit-1",
+					Description: "Deposit from ACME"},
+				// booked on the entry date, in the next year
+				{BookingDate: date("2027-01-02"), Amount: decimal.RequireFromString("-40.5"),
+					Description: "Payout second row"},
+				{BookingDate: date("2026-12-31"), Amount: decimal.RequireFromString("-10"), Reference: "tx-reversal"},
+			},
+		},
+		{
+			name:    "camt053",
+			format:  FormatCAMT053,
+			content: testCAMT053,
+			want: []Line{
+				{BookingDate: date("2026-10-01"), Amount: decimal.RequireFromString("250"), Reference: "tx-deposit-1",
+					Description: "Deposit from ACME"},
+				{BookingDate: date("2026-10-02"), Amount: decimal.RequireFromString("-30"), Reference: "tx-payout-1",
+					Description: "Payouts"},
+				{BookingDate: date("2026-10-02"), Amount: decimal.RequireFromString("-40"), Reference: "ENTRY-3",
+					Description: "Payouts"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			lines, err := Parse(testCase.format, strings.NewReader(testCase.content))
+
+			assert.NoError(t, err)
+			assert.Len(t, lines, len(testCase.want))
+
+			for i, line := range lines {
+				assert.True(t, testCase.want[i].BookingDate.Equal(line.BookingDate), "line %d date %s", i,
+					line.BookingDate)
+				assert.True(t, testCase.want[i].Amount.Equal(line.Amount), "line %d amount %s", i, line.Amount)
+				assert.Equal(t, testCase.want[i].Reference, line.Reference)
+				assert.Equal(t, testCase.want[i].Description, line.Description)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		format  Format
+		content string
+	}{
+		{name: "csv missing amount column", format: FormatCSV, content: "date,reference\n2026-10-01,a\n"},
+		{name: "csv malformed date", format: FormatCSV, content: "date,amount\n01/10/2026,1\n"},
+		{name: "csv malformed amount", format: FormatCSV, content: "date,amount\n2026-10-01,1.0.0\n"},
+		{name: "mt940 malformed line", format: FormatMT940, content: ":20:A\n:61:261001X100,NTRF\n"},
+		{name: "mt940 row outside field", format: FormatMT940, content: "hello\n"},
+		{name: "camt053 malformed", format: FormatCAMT053, content: "<Document><BkToCstmrStmt>"},
+		{name: "camt053 missing date", format: FormatCAMT053, content: `<Document><BkToCstmrStmt><Stmt><Ntry>
+			<Amt>1</Amt><CdtDbtInd>CRDT</CdtDbtInd></Ntry></Stmt></BkToCstmrStmt></Document>`},
+		{name: "camt053 missing indicator", format: FormatCAMT053, content: `<Document><BkToCstmrStmt><Stmt><Ntry>
+			<Amt>1</Amt><BookgDt><Dt>2026-10-01</Dt></BookgDt></Ntry></Stmt></BkToCstmrStmt></Document>`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Parse(testCase.format, strings.NewReader(testCase.content))
+			assert.ErrorIs(t, err, ErrInvalidStatement)
+		})
+	}
+
+	_, err := Parse("pdf", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat(" MT940 ")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatMT940, format)
+
+	_, err = ParseFormat("bai2")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, FormatCSV, DetectFormat("october.CSV", nil))
+	assert.Equal(t, FormatMT940, DetectFormat("october.sta", nil))
+	assert.Equal(t, FormatCAMT053, DetectFormat("october.xml", nil))
+	assert.Equal(t, FormatCAMT053, DetectFormat("upload", []byte(testCAMT053)))
+	assert.Equal(t, FormatMT940, DetectFormat("upload", []byte(testMT940)))
+	assert.Equal(t, FormatCSV, DetectFormat("upload", []byte(testCSV)))
+}
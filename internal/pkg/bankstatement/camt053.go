@@ -0,0 +1,185 @@
+package bankstatement
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	camtCredit = "CRDT"
+	camtDebit  = "DBIT"
+	camtBooked = "BOOK"
+	// camtNotProvided is the end to end id of a transaction without one.
+	camtNotProvided = "NOTPROVIDED"
+)
+
+// The tags have no namespace, so every version of camt.053 is matched.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	Reference      string            `xml:"NtryRef"`
+	Amount         string            `xml:"Amt"`
+	CreditDebit    string            `xml:"CdtDbtInd"`
+	Status         camtStatus        `xml:"Sts"`
+	BookingDate    camtDate          `xml:"BookgDt"`
+	ValueDate      camtDate          `xml:"ValDt"`
+	AdditionalInfo string            `xml:"AddtlNtryInf"`
+	Transactions   []camtTransaction `xml:"NtryDtls>TxDtls"`
+}
+
+// camtStatus is a code up to camt.053.001.04 and a Cd element afterwards.
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtTransaction struct {
+	EndToEndID   string   `xml:"Refs>EndToEndId"`
+	Amount       string   `xml:"Amt"`
+	CreditDebit  string   `xml:"CdtDbtInd"`
+	Unstructured []string `xml:"RmtInf>Ustrd"`
+}
+
+func parseCAMT053(reader io.Reader) ([]Line, error) {
+	var document camtDocument
+
+	if err := xml.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, fmt.Errorf("decode document: %w", err)
+	}
+
+	var lines []Line
+
+	for _, statement := range document.Statements {
+		for _, entry := range statement.Entries {
+			if status := strings.TrimSpace(entry.Status.Value + entry.Status.Code); status != "" &&
+				status != camtBooked {
+				continue
+			}
+
+			entryLines, err := camtEntryLines(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, entryLines...)
+		}
+	}
+
+	return lines, nil
+}
+
+// camtEntryLines returns a line per transaction of a batch entry whose transactions have an amount, otherwise a
+// line for the entry.
+func camtEntryLines(entry camtEntry) ([]Line, error) {
+	bookingDate, err := entry.BookingDate.date()
+	if err != nil {
+		bookingDate, err = entry.ValueDate.date()
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", entry.Reference, err)
+		}
+	}
+
+	batch := len(entry.Transactions) > 1
+
+	for _, transaction := range entry.Transactions {
+		batch = batch && transaction.Amount != ""
+	}
+
+	if !batch {
+		var transaction camtTransaction
+		if len(entry.Transactions) == 1 {
+			transaction = entry.Transactions[0]
+		}
+
+		line, err := camtLine(bookingDate, entry.Amount, entry.CreditDebit, entry, transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Line{line}, nil
+	}
+
+	lines := make([]Line, 0, len(entry.Transactions))
+
+	for _, transaction := range entry.Transactions {
+		creditDebit := transaction.CreditDebit
+		if creditDebit == "" {
+			creditDebit = entry.CreditDebit
+		}
+
+		line, err := camtLine(bookingDate, transaction.Amount, creditDebit, entry, transaction)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func camtLine(bookingDate time.Time, value, creditDebit string, entry camtEntry,
+	transaction camtTransaction,
+) (Line, error) {
+	amount, err := decimal.NewFromString(strings.TrimSpace(value))
+	if err != nil {
+		return Line{}, fmt.Errorf("entry %q: invalid amount %q", entry.Reference, value)
+	}
+
+	switch strings.TrimSpace(creditDebit) {
+	case camtCredit:
+	case camtDebit:
+		amount = amount.Neg()
+	default:
+		return Line{}, fmt.Errorf("entry %q: invalid credit debit indicator %q", entry.Reference, creditDebit)
+	}
+
+	// the end to end id is the reference of the payer, it is carried through to the statement
+	reference := strings.TrimSpace(transaction.EndToEndID)
+	if reference == "" || reference == camtNotProvided {
+		reference = strings.TrimSpace(entry.Reference)
+	}
+
+	description := strings.TrimSpace(strings.Join(transaction.Unstructured, " "))
+	if description == "" {
+		description = strings.TrimSpace(entry.AdditionalInfo)
+	}
+
+	return Line{
+		BookingDate: bookingDate,
+		Amount:      amount,
+		Reference:   reference,
+		Description: description,
+	}, nil
+}
+
+// date returns the date, a date time is read as the date the bank booked it on.
+func (d camtDate) date() (time.Time, error) {
+	value := strings.TrimSpace(d.Date)
+	if dateTime := strings.TrimSpace(d.DateTime); dateTime != "" {
+		value = dateTime[:min(len(dateTime), len(time.DateOnly))]
+	}
+
+	if value == "" {
+		return time.Time{}, errors.New("missing date")
+	}
+
+	return parseDate(time.DateOnly, value)
+}
@@ -0,0 +1,92 @@
+package bankstatement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// csvRequiredColumns are the columns a CSV statement must have, reference and description are optional. The
+// amount is signed and the date is YYYY-MM-DD.
+var csvRequiredColumns = []string{"date", "amount"}
+
+func parseCSV(reader io.Reader) ([]Line, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := map[string]int{}
+
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	for _, name := range csvRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing %s column", name)
+		}
+	}
+
+	var lines []Line
+
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("read record: %w", err)
+		}
+
+		line, err := csvLine(record, columns)
+		if err != nil {
+			row, _ := csvReader.FieldPos(0)
+
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+func csvLine(record []string, columns map[string]int) (Line, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	date, err := parseDate(time.DateOnly, field("date"))
+	if err != nil {
+		return Line{}, err
+	}
+
+	amount, err := decimal.NewFromString(field("amount"))
+	if err != nil {
+		return Line{}, fmt.Errorf("invalid amount %q", field("amount"))
+	}
+
+	return Line{
+		BookingDate: date,
+		Amount:      amount,
+		Reference:   field("reference"),
+		Description: field("description"),
+	}, nil
+}
@@ -0,0 +1,156 @@
+package bankstatement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// mt940NoReference is the customer reference of a line without one.
+const mt940NoReference = "NONREF"
+
+// mt940StatementLine matches the first row of a :61: field: value date, optional entry date, debit/credit mark,
+// optional funds code, amount, transaction type, customer reference and optional bank reference.
+var mt940StatementLine = regexp.MustCompile(
+	`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([NSF][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+// mt940Field is a tagged field, e.g. :61:, with its continuation rows.
+type mt940Field struct {
+	tag  string
+	rows []string
+}
+
+func parseMT940(reader io.Reader) ([]Line, error) {
+	fields, err := readMT940Fields(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []Line
+
+	for _, field := range fields {
+		switch field.tag {
+		case "61":
+			line, err := mt940Line(field.rows[0])
+			if err != nil {
+				return nil, err
+			}
+
+			lines = append(lines, line)
+		case "86":
+			// the information to account owner describes the preceding statement line
+			if len(lines) > 0 && lines[len(lines)-1].Description == "" {
+				lines[len(lines)-1].Description = mt940Text(field.rows)
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+// readMT940Fields splits the messages into fields, the SWIFT header and trailer blocks are skipped.
+func readMT940Fields(reader io.Reader) ([]mt940Field, error) {
+	var fields []mt940Field
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		row := strings.TrimRight(scanner.Text(), "\r ")
+
+		switch {
+		case row == "", row == "-", strings.HasPrefix(row, "{"), strings.HasPrefix(row, "-}"):
+			continue
+		case strings.HasPrefix(row, ":"):
+			tag, value, ok := strings.Cut(row[1:], ":")
+			if !ok {
+				return nil, fmt.Errorf("malformed field %q", row)
+			}
+
+			fields = append(fields, mt940Field{tag: tag, rows: []string{value}})
+		case len(fields) > 0:
+			fields[len(fields)-1].rows = append(fields[len(fields)-1].rows, row)
+		default:
+			return nil, fmt.Errorf("unexpected row %q", row)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read statement: %w", err)
+	}
+
+	return fields, nil
+}
+
+func mt940Line(row string) (Line, error) {
+	match := mt940StatementLine.FindStringSubmatch(row)
+	if match == nil {
+		return Line{}, fmt.Errorf("malformed statement line %q", row)
+	}
+
+	valueDate, err := parseDate("060102", match[1])
+	if err != nil {
+		return Line{}, err
+	}
+
+	bookingDate := valueDate
+	if match[2] != "" {
+		bookingDate, err = mt940EntryDate(valueDate, match[2])
+		if err != nil {
+			return Line{}, err
+		}
+	}
+
+	amount, err := decimal.NewFromString(strings.TrimSuffix(strings.Replace(match[5], ",", ".", 1), "."))
+	if err != nil {
+		return Line{}, fmt.Errorf("invalid amount %q", match[5])
+	}
+
+	// a reversal of a credit is a debit and the other way around
+	if match[3] == "D" || match[3] == "RC" {
+		amount = amount.Neg()
+	}
+
+	reference := strings.TrimSpace(match[7])
+	if reference == mt940NoReference {
+		reference = ""
+	}
+
+	return Line{
+		BookingDate: bookingDate,
+		Amount:      amount,
+		Reference:   reference,
+	}, nil
+}
+
+// mt940EntryDate reads the MMDD entry date, its year is the year of the value date unless the entry is booked
+// across the turn of the year.
+func mt940EntryDate(valueDate time.Time, value string) (time.Time, error) {
+	entryDate, err := parseDate("20060102", fmt.Sprintf("%04d%s", valueDate.Year(), value))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch {
+	case entryDate.Sub(valueDate) > 180*24*time.Hour:
+		return entryDate.AddDate(-1, 0, 0), nil
+	case valueDate.Sub(entryDate) > 180*24*time.Hour:
+		return entryDate.AddDate(1, 0, 0), nil
+	default:
+		return entryDate, nil
+	}
+}
+
+// mt940Text joins the rows of a free text field.
+func mt940Text(rows []string) string {
+	trimmed := make([]string, 0, len(rows))
+	for _, row := range rows {
+		trimmed = append(trimmed, strings.TrimSpace(row))
+	}
+
+	return strings.Join(trimmed, " ")
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/config"
+	"github.com/lib/pq"
+)
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+	maxRetryDelayFactor  = 32
+)
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	"":                 sql.LevelDefault,
+	"read_committed":   sql.LevelReadCommitted,
+	"repeatable_read":  sql.LevelRepeatableRead,
+	"serializable":     sql.LevelSerializable,
+	"read_uncommitted": sql.LevelReadUncommitted,
+}
+
+// TransactionPolicy holds the isolation level of the transactions and how a transaction failing with a
+// serialization failure or a deadlock is retried.
+type TransactionPolicy struct {
+	Isolation  sql.IsolationLevel
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// NewTransactionPolicy returns the transaction policy of the DB configuration.
+func NewTransactionPolicy(cfg config.Config) (TransactionPolicy, error) {
+	isolation, ok := isolationLevels[cfg.DB.IsolationLevel]
+	if !ok {
+		return TransactionPolicy{}, fmt.Errorf("unsupported isolation level %q", cfg.DB.IsolationLevel)
+	}
+
+	return TransactionPolicy{
+		Isolation:  isolation,
+		MaxRetries: cfg.DB.TxMaxRetries,
+		RetryDelay: cfg.DB.TxRetryDelay,
+	}, nil
+}
+
+func (p TransactionPolicy) TxOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: p.Isolation}
+}
+
+// Backoff returns the delay before the given retry, starting at 1. The delay doubles on every retry, up to 32 times
+// the retry delay, and half of it is random so that the transactions that conflicted do not retry at the same time.
+func (p TransactionPolicy) Backoff(retry int) time.Duration {
+	delay := p.RetryDelay
+
+	for i := 1; i < retry && delay < p.RetryDelay*maxRetryDelayFactor; i++ {
+		delay *= 2
+	}
+
+	if delay <= 1 {
+		return delay
+	}
+
+	return delay/2 + rand.N(delay/2) //nolint:gosec // the jitter does not need a secure random
+}
+
+// IsRetryable reports whether the transaction failed with a serialization failure or a deadlock, it succeeds
+// when run again.
+func IsRetryable(err error) bool {
+	var pgErr *pq.Error
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+}
@@ -0,0 +1,69 @@
+//go:build unit
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/config"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransactionPolicy(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		policy, err := NewTransactionPolicy(config.Config{
+			DB: config.DB{
+				IsolationLevel: "serializable",
+				TxMaxRetries:   3,
+				TxRetryDelay:   50 * time.Millisecond,
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, TransactionPolicy{
+			Isolation:  sql.LevelSerializable,
+			MaxRetries: 3,
+			RetryDelay: 50 * time.Millisecond,
+		}, policy)
+		assert.Equal(t, &sql.TxOptions{Isolation: sql.LevelSerializable}, policy.TxOptions())
+	})
+
+	t.Run("success_default_isolation", func(t *testing.T) {
+		policy, err := NewTransactionPolicy(config.Config{})
+		assert.NoError(t, err)
+		assert.Equal(t, sql.LevelDefault, policy.Isolation)
+	})
+
+	t.Run("error_unsupported_isolation", func(t *testing.T) {
+		_, err := NewTransactionPolicy(config.Config{DB: config.DB{IsolationLevel: "snapshot"}})
+		assert.ErrorContains(t, err, `unsupported isolation level "snapshot"`)
+	})
+}
+
+func TestTransactionPolicy_Backoff(t *testing.T) {
+	policy := TransactionPolicy{RetryDelay: 10 * time.Millisecond}
+
+	for retry, want := range map[int]time.Duration{
+		1:  10 * time.Millisecond,
+		2:  20 * time.Millisecond,
+		3:  40 * time.Millisecond,
+		10: 320 * time.Millisecond,
+	} {
+		delay := policy.Backoff(retry)
+		assert.GreaterOrEqual(t, delay, want/2, "retry %d", retry)
+		assert.Less(t, delay, want, "retry %d", retry)
+	}
+
+	assert.Zero(t, TransactionPolicy{}.Backoff(1))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(&pq.Error{Code: "40001"}))
+	assert.True(t, IsRetryable(fmt.Errorf("failed to upsert account: %w", &pq.Error{Code: "40P01"})))
+	assert.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+	assert.False(t, IsRetryable(errors.New("internal db error")))
+}
@@ -0,0 +1,212 @@
+// Package fee computes transfer fees from a schedule of rules loaded from a YAML file.
+//
+// Rules are evaluated in order and the first rule matching the transfer amount and the payer account
+// type applies. A rule charges a flat amount, a percentage of the transfer amount (plus an optional flat
+// amount) or, for tiered rules, the flat amount and percentage of the tier the transfer amount falls in.
+// The result is capped by the optional min_fee and max_fee of the rule and rounded to two decimals.
+package fee
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+type Kind string
+
+const (
+	KindFlat       Kind = "flat"
+	KindPercentage Kind = "percentage"
+	KindTiered     Kind = "tiered"
+)
+
+// minorUnits is the precision fees are rounded to.
+const minorUnits = 2
+
+var (
+	ErrInvalidSchedule = errors.New("invalid fee schedule")
+	hundred            = decimal.NewFromInt(100)
+)
+
+// Schedule is the set of fee rules and the account collecting the fees.
+type Schedule struct {
+	RevenueAccountID int64  `yaml:"revenue_account_id"`
+	Rules            []Rule `yaml:"rules"`
+}
+
+// Rule describes when and how much fee is charged. Zero amount bounds and caps are disabled.
+type Rule struct {
+	Name         string          `yaml:"name"`
+	Kind         Kind            `yaml:"type"`
+	AccountTypes []string        `yaml:"account_types"`
+	MinAmount    decimal.Decimal `yaml:"min_amount"`
+	MaxAmount    decimal.Decimal `yaml:"max_amount"`
+	Flat         decimal.Decimal `yaml:"flat"`
+	Rate         decimal.Decimal `yaml:"rate"`
+	Tiers        []Tier          `yaml:"tiers"`
+	MinFee       decimal.Decimal `yaml:"min_fee"`
+	MaxFee       decimal.Decimal `yaml:"max_fee"`
+}
+
+// Tier applies to transfer amounts up to and including UpTo, a zero UpTo is unbounded.
+// Rate is a percentage of the transfer amount.
+type Tier struct {
+	UpTo decimal.Decimal `yaml:"up_to"`
+	Flat decimal.Decimal `yaml:"flat"`
+	Rate decimal.Decimal `yaml:"rate"`
+}
+
+// Fee is the fee charged on a transfer.
+type Fee struct {
+	Amount decimal.Decimal
+	Rule   string
+}
+
+// Load reads and validates a schedule from a YAML file.
+func Load(path string) (*Schedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fee schedule: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes and validates a YAML schedule.
+func Parse(data []byte) (*Schedule, error) {
+	var schedule Schedule
+
+	if err := yaml.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+
+	if err := schedule.validate(); err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (s *Schedule) validate() error {
+	if len(s.Rules) > 0 && s.RevenueAccountID == 0 {
+		return fmt.Errorf("%w: revenue_account_id is required", ErrInvalidSchedule)
+	}
+
+	for i, rule := range s.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("%w: rule %d %q: %w", ErrInvalidSchedule, i, rule.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r Rule) validate() error {
+	switch r.Kind {
+	case KindFlat:
+		if !r.Flat.IsPositive() {
+			return errors.New("flat must be positive")
+		}
+	case KindPercentage:
+		if !r.Rate.IsPositive() {
+			return errors.New("rate must be positive")
+		}
+	case KindTiered:
+		if len(r.Tiers) == 0 {
+			return errors.New("tiers are required")
+		}
+
+		for i, tier := range r.Tiers {
+			if tier.Flat.IsNegative() || tier.Rate.IsNegative() {
+				return fmt.Errorf("tier %d must not be negative", i)
+			}
+
+			if i == len(r.Tiers)-1 {
+				continue
+			}
+
+			if tier.UpTo.IsZero() {
+				return errors.New("only the last tier may be unbounded")
+			}
+
+			if next := r.Tiers[i+1].UpTo; !next.IsZero() && !tier.UpTo.LessThan(next) {
+				return errors.New("tiers must be sorted by up_to")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown type %q", r.Kind)
+	}
+
+	if r.MinFee.IsNegative() || r.MaxFee.IsNegative() {
+		return errors.New("min_fee and max_fee must not be negative")
+	}
+
+	if r.MaxFee.IsPositive() && r.MinFee.GreaterThan(r.MaxFee) {
+		return errors.New("min_fee must not be greater than max_fee")
+	}
+
+	return nil
+}
+
+// Calculate returns the fee of the first matching rule, or a zero fee when no rule matches.
+func (s *Schedule) Calculate(amount decimal.Decimal, accountType string) Fee {
+	for _, rule := range s.Rules {
+		if !rule.matches(amount, accountType) {
+			continue
+		}
+
+		return Fee{
+			Amount: rule.calculate(amount),
+			Rule:   rule.Name,
+		}
+	}
+
+	return Fee{Amount: decimal.Zero}
+}
+
+func (r Rule) matches(amount decimal.Decimal, accountType string) bool {
+	if len(r.AccountTypes) > 0 && !slices.Contains(r.AccountTypes, accountType) {
+		return false
+	}
+
+	if amount.LessThan(r.MinAmount) {
+		return false
+	}
+
+	return r.MaxAmount.IsZero() || !amount.GreaterThan(r.MaxAmount)
+}
+
+func (r Rule) calculate(amount decimal.Decimal) decimal.Decimal {
+	flat, rate := r.Flat, r.Rate
+
+	if r.Kind == KindTiered {
+		flat, rate = decimal.Zero, decimal.Zero
+
+		for _, tier := range r.Tiers {
+			if tier.UpTo.IsZero() || !amount.GreaterThan(tier.UpTo) {
+				flat, rate = tier.Flat, tier.Rate
+
+				break
+			}
+		}
+	}
+
+	fee := flat
+	if r.Kind != KindFlat {
+		fee = fee.Add(amount.Mul(rate).Div(hundred))
+	}
+
+	if fee.LessThan(r.MinFee) {
+		fee = r.MinFee
+	}
+
+	if r.MaxFee.IsPositive() && fee.GreaterThan(r.MaxFee) {
+		fee = r.MaxFee
+	}
+
+	return fee.Round(minorUnits)
+}
@@ -0,0 +1,113 @@
+//go:build unit
+
+package fee
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchedule = `
+revenue_account_id: 9000
+rules:
+  - name: savings-free
+    type: flat
+    flat: 0.01
+    account_types: [savings]
+    max_fee: 0.01
+    min_fee: 0
+  - name: business-percentage
+    type: percentage
+    account_types: [business]
+    rate: 0.5
+    min_fee: 1
+    max_fee: 25
+  - name: large-transfer
+    type: flat
+    flat: 10
+    min_amount: 10000
+  - name: personal-tiered
+    type: tiered
+    tiers:
+      - up_to: 100
+        flat: 0
+      - up_to: 1000
+        flat: 0.5
+      - rate: 0.1
+        flat: 1
+`
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule string
+	}{
+		{name: "missing revenue account", schedule: "rules:\n  - name: a\n    type: flat\n    flat: 1\n"},
+		{name: "unknown type", schedule: "revenue_account_id: 1\nrules:\n  - name: a\n    type: magic\n"},
+		{name: "percentage without rate", schedule: "revenue_account_id: 1\nrules:\n  - name: a\n    type: percentage\n"},
+		{name: "unsorted tiers", schedule: "revenue_account_id: 1\nrules:\n  - name: a\n    type: tiered\n    tiers:\n      - up_to: 100\n      - up_to: 50\n"},
+		{name: "unbounded tier first", schedule: "revenue_account_id: 1\nrules:\n  - name: a\n    type: tiered\n    tiers:\n      - flat: 1\n      - up_to: 50\n"},
+		{name: "min above max", schedule: "revenue_account_id: 1\nrules:\n  - name: a\n    type: flat\n    flat: 1\n    min_fee: 5\n    max_fee: 2\n"},
+		{name: "malformed", schedule: "rules: ["},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Parse([]byte(testCase.schedule))
+			assert.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+
+	schedule, err := Parse([]byte(testSchedule))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9000), schedule.RevenueAccountID)
+	assert.Len(t, schedule.Rules, 4)
+}
+
+func TestLoad(t *testing.T) {
+	schedule, err := Load("../../../resources/fees/fee_schedule.yml")
+	assert.NoError(t, err)
+	assert.Len(t, schedule.Rules, 3)
+
+	_, err = Load("not-found.yml")
+	assert.Error(t, err)
+}
+
+func TestScheduleCalculate(t *testing.T) {
+	schedule, err := Parse([]byte(testSchedule))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		amount      string
+		accountType string
+		wantFee     string
+		wantRule    string
+	}{
+		{name: "flat capped", amount: "500", accountType: "savings", wantFee: "0.01", wantRule: "savings-free"},
+		{name: "percentage", amount: "1000", accountType: "business", wantFee: "5", wantRule: "business-percentage"},
+		{name: "percentage min fee", amount: "10", accountType: "business", wantFee: "1", wantRule: "business-percentage"},
+		{name: "percentage max fee", amount: "9000", accountType: "business", wantFee: "25", wantRule: "business-percentage"},
+		{name: "amount bound", amount: "20000", accountType: "personal", wantFee: "10", wantRule: "large-transfer"},
+		{name: "first tier", amount: "100", accountType: "personal", wantFee: "0", wantRule: "personal-tiered"},
+		{name: "second tier", amount: "100.01", accountType: "personal", wantFee: "0.5", wantRule: "personal-tiered"},
+		{name: "unbounded tier", amount: "2345", accountType: "personal", wantFee: "3.35", wantRule: "personal-tiered"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := schedule.Calculate(decimal.RequireFromString(testCase.amount), testCase.accountType)
+
+			assert.Equal(t, decimal.RequireFromString(testCase.wantFee).String(), got.Amount.String())
+			assert.Equal(t, testCase.wantRule, got.Rule)
+		})
+	}
+
+	t.Run("no rule matches", func(t *testing.T) {
+		got := (&Schedule{}).Calculate(decimal.NewFromInt(100), "personal")
+		assert.True(t, got.Amount.IsZero())
+		assert.Empty(t, got.Rule)
+	})
+}
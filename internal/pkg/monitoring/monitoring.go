@@ -0,0 +1,289 @@
+// Package monitoring looks for suspicious patterns in the money movements of the accounts, such as structuring or
+// money moved in and out again quickly, for compliance to review.
+//
+// A Detector looks at the movements of one account over a sliding window, Scan runs the detectors over the
+// movements of every account. A pattern is reported once per window, the movements of a reported window do not
+// count towards the next one.
+package monitoring
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Direction string
+
+const (
+	DirectionIn  Direction = "in"
+	DirectionOut Direction = "out"
+)
+
+// Movement is money leaving or entering an account, CounterpartyID is the account on the other side.
+type Movement struct {
+	EventID        int64
+	AccountID      int64
+	CounterpartyID int64
+	Direction      Direction
+	Amount         decimal.Decimal
+	At             time.Time
+}
+
+// Finding is a pattern found in the movements of an account, Amount is the total of the supporting movements and
+// From and To are the times of the first and last of them.
+type Finding struct {
+	Detector  string
+	AccountID int64
+	Reason    string
+	Amount    decimal.Decimal
+	EventIDs  []int64
+	From      time.Time
+	To        time.Time
+}
+
+// Detector finds a pattern in the movements of an account.
+type Detector interface {
+	Name() string
+	// Window is the longest period the movements of a pattern are spread over.
+	Window() time.Duration
+	// Detect returns the patterns found in the movements of one account, sorted by time.
+	Detect(movements []Movement) []Finding
+}
+
+// Lookback returns the longest window of the detectors, the period of movements a scan needs.
+func Lookback(detectors []Detector) time.Duration {
+	var lookback time.Duration
+
+	for _, detector := range detectors {
+		lookback = max(lookback, detector.Window())
+	}
+
+	return lookback
+}
+
+// Scan runs the detectors over the movements of each account, the findings are ordered by account.
+func Scan(detectors []Detector, movements []Movement) []Finding {
+	byAccount := make(map[int64][]Movement)
+
+	for _, movement := range movements {
+		byAccount[movement.AccountID] = append(byAccount[movement.AccountID], movement)
+	}
+
+	accountIDs := make([]int64, 0, len(byAccount))
+	for accountID := range byAccount {
+		accountIDs = append(accountIDs, accountID)
+	}
+
+	slices.Sort(accountIDs)
+
+	var findings []Finding
+
+	for _, accountID := range accountIDs {
+		accountMovements := byAccount[accountID]
+		slices.SortStableFunc(accountMovements, func(a, b Movement) int {
+			return cmp.Or(a.At.Compare(b.At), cmp.Compare(a.EventID, b.EventID))
+		})
+
+		for _, detector := range detectors {
+			findings = append(findings, detector.Detect(accountMovements)...)
+		}
+	}
+
+	return findings
+}
+
+// Structuring detects amounts split into several movements just below the reporting threshold: at least MinCount
+// movements in the same direction within Period, each within Margin (a fraction, e.g. 0.1) below Threshold and
+// together reaching it.
+type Structuring struct {
+	Threshold decimal.Decimal
+	Margin    decimal.Decimal
+	MinCount  int
+	Period    time.Duration
+}
+
+func (d Structuring) Name() string {
+	return "structuring"
+}
+
+func (d Structuring) Window() time.Duration {
+	return d.Period
+}
+
+func (d Structuring) Detect(movements []Movement) []Finding {
+	floor := d.Threshold.Mul(decimal.NewFromInt(1).Sub(d.Margin))
+
+	var findings []Finding
+
+	for _, direction := range []Direction{DirectionIn, DirectionOut} {
+		candidates := filter(movements, func(movement Movement) bool {
+			return movement.Direction == direction && movement.Amount.GreaterThanOrEqual(floor) &&
+				movement.Amount.LessThan(d.Threshold)
+		})
+
+		findings = append(findings, slide(candidates, d.Period, func(window []Movement) (Finding, bool) {
+			total := sum(window)
+			if len(window) < d.MinCount || total.LessThan(d.Threshold) {
+				return Finding{}, false
+			}
+
+			reason := fmt.Sprintf("%d movements %s just below %s totalling %s within %s", len(window), direction,
+				d.Threshold, total, window[len(window)-1].At.Sub(window[0].At))
+
+			return newFinding(d.Name(), reason, window), true
+		})...)
+	}
+
+	return findings
+}
+
+// RapidMovement detects money moved out again shortly after it came in: within Period, at least MinAmount in and
+// at least Ratio (a fraction, e.g. 0.9) of it out after the first movement in.
+type RapidMovement struct {
+	MinAmount decimal.Decimal
+	Ratio     decimal.Decimal
+	Period    time.Duration
+}
+
+func (d RapidMovement) Name() string {
+	return "rapid_movement"
+}
+
+func (d RapidMovement) Window() time.Duration {
+	return d.Period
+}
+
+func (d RapidMovement) Detect(movements []Movement) []Finding {
+	return slide(movements, d.Period, func(window []Movement) (Finding, bool) {
+		first := slices.IndexFunc(window, func(movement Movement) bool {
+			return movement.Direction == DirectionIn
+		})
+		if first < 0 {
+			return Finding{}, false
+		}
+
+		window = window[first:]
+		in := sum(filter(window, isDirection(DirectionIn)))
+		out := sum(filter(window, isDirection(DirectionOut)))
+
+		if in.LessThan(d.MinAmount) || out.LessThan(in.Mul(d.Ratio)) {
+			return Finding{}, false
+		}
+
+		reason := fmt.Sprintf("%s in and %s out within %s", in, out, window[len(window)-1].At.Sub(window[0].At))
+
+		return newFinding(d.Name(), reason, window), true
+	})
+}
+
+// Fan detects an account receiving from (fan-in) or sending to (fan-out) at least MinCounterparties different
+// accounts within Period.
+type Fan struct {
+	Direction         Direction
+	MinCounterparties int
+	Period            time.Duration
+}
+
+func (d Fan) Name() string {
+	if d.Direction == DirectionIn {
+		return "fan_in"
+	}
+
+	return "fan_out"
+}
+
+func (d Fan) Window() time.Duration {
+	return d.Period
+}
+
+func (d Fan) Detect(movements []Movement) []Finding {
+	candidates := filter(movements, isDirection(d.Direction))
+
+	return slide(candidates, d.Period, func(window []Movement) (Finding, bool) {
+		counterparties := make(map[int64]bool, len(window))
+		for _, movement := range window {
+			counterparties[movement.CounterpartyID] = true
+		}
+
+		if len(counterparties) < d.MinCounterparties {
+			return Finding{}, false
+		}
+
+		reason := fmt.Sprintf("%d counterparties %s totalling %s within %s", len(counterparties), d.Direction,
+			sum(window), window[len(window)-1].At.Sub(window[0].At))
+
+		return newFinding(d.Name(), reason, window), true
+	})
+}
+
+// slide calls match with the movements of the window ending at each movement. The movements of a matched window
+// are left out of the next windows, so that a pattern is reported once.
+func slide(movements []Movement, window time.Duration,
+	match func(window []Movement) (Finding, bool),
+) []Finding {
+	var findings []Finding
+
+	start := 0
+
+	for end := range movements {
+		for movements[end].At.Sub(movements[start].At) > window {
+			start++
+		}
+
+		finding, ok := match(movements[start : end+1])
+		if ok {
+			findings = append(findings, finding)
+			start = end + 1
+		}
+	}
+
+	return findings
+}
+
+func newFinding(detector string, reason string, window []Movement) Finding {
+	eventIDs := make([]int64, 0, len(window))
+	for _, movement := range window {
+		eventIDs = append(eventIDs, movement.EventID)
+	}
+
+	return Finding{
+		Detector:  detector,
+		AccountID: window[0].AccountID,
+		Reason:    reason,
+		Amount:    sum(window),
+		EventIDs:  eventIDs,
+		From:      window[0].At,
+		To:        window[len(window)-1].At,
+	}
+}
+
+func filter(movements []Movement, keep func(movement Movement) bool) []Movement {
+	var kept []Movement
+
+	for _, movement := range movements {
+		if keep(movement) {
+			kept = append(kept, movement)
+		}
+	}
+
+	return kept
+}
+
+func isDirection(direction Direction) func(movement Movement) bool {
+	return func(movement Movement) bool {
+		return movement.Direction == direction
+	}
+}
+
+func sum(movements []Movement) decimal.Decimal {
+	total := decimal.Zero
+
+	for _, movement := range movements {
+		total = total.Add(movement.Amount)
+	}
+
+	return total
+}
@@ -0,0 +1,217 @@
+//go:build unit
+
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+
+func movement(eventID int64, accountID int64, counterpartyID int64, direction Direction, amount string,
+	after time.Duration,
+) Movement {
+	return Movement{
+		EventID:        eventID,
+		AccountID:      accountID,
+		CounterpartyID: counterpartyID,
+		Direction:      direction,
+		Amount:         decimal.RequireFromString(amount),
+		At:             start.Add(after),
+	}
+}
+
+func TestStructuring(t *testing.T) {
+	detector := Structuring{
+		Threshold: decimal.NewFromInt(10000),
+		Margin:    decimal.RequireFromString("0.1"),
+		MinCount:  3,
+		Period:    24 * time.Hour,
+	}
+
+	testCases := []struct {
+		name         string
+		movements    []Movement
+		wantEventIDs [][]int64
+	}{
+		{
+			name: "split below the threshold",
+			movements: []Movement{
+				movement(1, 1, 2, DirectionOut, "9500", 0),
+				movement(2, 1, 3, DirectionOut, "9900", time.Hour),
+				movement(3, 1, 2, DirectionIn, "9800", 2*time.Hour),
+				movement(4, 1, 4, DirectionOut, "9000", 3*time.Hour),
+			},
+			wantEventIDs: [][]int64{{1, 2, 4}},
+		},
+		{
+			name: "at the threshold",
+			movements: []Movement{
+				movement(1, 1, 2, DirectionOut, "10000", 0),
+				movement(2, 1, 3, DirectionOut, "9900", time.Hour),
+				movement(3, 1, 4, DirectionOut, "9900", 2*time.Hour),
+			},
+		},
+		{
+			name: "below the margin",
+			movements: []Movement{
+				movement(1, 1, 2, DirectionIn, "8999.99", 0),
+				movement(2, 1, 3, DirectionIn, "9900", time.Hour),
+				movement(3, 1, 4, DirectionIn, "9900", 2*time.Hour),
+			},
+		},
+		{
+			name: "spread over more than the window",
+			movements: []Movement{
+				movement(1, 1, 2, DirectionIn, "9500", 0),
+				movement(2, 1, 3, DirectionIn, "9500", 12*time.Hour),
+				movement(3, 1, 4, DirectionIn, "9500", 25*time.Hour),
+				movement(4, 1, 4, DirectionIn, "9500", 30*time.Hour),
+			},
+			wantEventIDs: [][]int64{{2, 3, 4}},
+		},
+		{
+			name: "reported once per window",
+			movements: []Movement{
+				movement(1, 1, 2, DirectionIn, "9500", 0),
+				movement(2, 1, 3, DirectionIn, "9500", time.Hour),
+				movement(3, 1, 4, DirectionIn, "9500", 2*time.Hour),
+				movement(4, 1, 4, DirectionIn, "9500", 3*time.Hour),
+				movement(5, 1, 4, DirectionIn, "9500", 4*time.Hour),
+				movement(6, 1, 4, DirectionIn, "9500", 5*time.Hour),
+			},
+			wantEventIDs: [][]int64{{1, 2, 3}, {4, 5, 6}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			findings := detector.Detect(testCase.movements)
+
+			assert.Equal(t, testCase.wantEventIDs, eventIDs(findings))
+		})
+	}
+}
+
+func TestRapidMovement(t *testing.T) {
+	detector := RapidMovement{
+		MinAmount: decimal.NewFromInt(5000),
+		Ratio:     decimal.RequireFromString("0.9"),
+		Period:    24 * time.Hour,
+	}
+
+	testCases := []struct {
+		name         string
+		movements    []Movement
+		wantEventIDs [][]int64
+	}{
+		{
+			name: "in and out",
+			movements: []Movement{
+				movement(1, 1, 9, DirectionOut, "100", 0),
+				movement(2, 1, 2, DirectionIn, "6000", time.Hour),
+				movement(3, 1, 3, DirectionOut, "3000", 2*time.Hour),
+				movement(4, 1, 4, DirectionOut, "2400", 3*time.Hour),
+			},
+			wantEventIDs: [][]int64{{2, 3, 4}},
+		},
+		{
+			name: "most of it kept",
+			movements: []Movement{
+				movement(1, 1, 2, DirectionIn, "6000", 0),
+				movement(2, 1, 3, DirectionOut, "5000", time.Hour),
+			},
+		},
+		{
+			name: "small amount",
+			movements: []Movement{
+				movement(1, 1, 2, DirectionIn, "4999", 0),
+				movement(2, 1, 3, DirectionOut, "4999", time.Hour),
+			},
+		},
+		{
+			name: "out before in",
+			movements: []Movement{
+				movement(1, 1, 3, DirectionOut, "6000", 0),
+				movement(2, 1, 2, DirectionIn, "6000", time.Hour),
+			},
+		},
+		{
+			name: "out after the window",
+			movements: []Movement{
+				movement(1, 1, 2, DirectionIn, "6000", 0),
+				movement(2, 1, 3, DirectionOut, "6000", 25*time.Hour),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			findings := detector.Detect(testCase.movements)
+
+			assert.Equal(t, testCase.wantEventIDs, eventIDs(findings))
+		})
+	}
+}
+
+func TestFan(t *testing.T) {
+	movements := []Movement{
+		movement(1, 1, 2, DirectionIn, "10", 0),
+		movement(2, 1, 3, DirectionIn, "10", time.Hour),
+		movement(3, 1, 3, DirectionIn, "10", 2*time.Hour),
+		movement(4, 1, 5, DirectionOut, "10", 3*time.Hour),
+		movement(5, 1, 4, DirectionIn, "10", 4*time.Hour),
+	}
+
+	fanIn := Fan{Direction: DirectionIn, MinCounterparties: 3, Period: 24 * time.Hour}
+	findings := fanIn.Detect(movements)
+
+	assert.Equal(t, "fan_in", fanIn.Name())
+	assert.Equal(t, [][]int64{{1, 2, 3, 5}}, eventIDs(findings))
+	assert.Equal(t, "3 counterparties in totalling 40 within 4h0m0s", findings[0].Reason)
+	assert.True(t, decimal.NewFromInt(40).Equal(findings[0].Amount))
+	assert.Equal(t, start, findings[0].From)
+	assert.Equal(t, start.Add(4*time.Hour), findings[0].To)
+
+	fanOut := Fan{Direction: DirectionOut, MinCounterparties: 2, Period: 24 * time.Hour}
+
+	assert.Equal(t, "fan_out", fanOut.Name())
+	assert.Empty(t, fanOut.Detect(movements))
+}
+
+func TestScan(t *testing.T) {
+	detectors := []Detector{
+		Fan{Direction: DirectionIn, MinCounterparties: 2, Period: time.Hour},
+		RapidMovement{MinAmount: decimal.NewFromInt(100), Ratio: decimal.NewFromInt(1), Period: 6 * time.Hour},
+	}
+
+	findings := Scan(detectors, []Movement{
+		movement(5, 2, 3, DirectionOut, "100", 2*time.Hour),
+		movement(4, 2, 1, DirectionIn, "100", time.Hour),
+		movement(3, 1, 4, DirectionIn, "10", 30*time.Minute),
+		movement(1, 1, 3, DirectionIn, "10", 0),
+	})
+
+	assert.Equal(t, 6*time.Hour, Lookback(detectors))
+	assert.Len(t, findings, 2)
+	assert.Equal(t, "fan_in", findings[0].Detector)
+	assert.Equal(t, int64(1), findings[0].AccountID)
+	assert.Equal(t, []int64{1, 3}, findings[0].EventIDs)
+	assert.Equal(t, "rapid_movement", findings[1].Detector)
+	assert.Equal(t, int64(2), findings[1].AccountID)
+	assert.Equal(t, []int64{4, 5}, findings[1].EventIDs)
+}
+
+func eventIDs(findings []Finding) [][]int64 {
+	var ids [][]int64
+
+	for _, finding := range findings {
+		ids = append(ids, finding.EventIDs)
+	}
+
+	return ids
+}
@@ -0,0 +1,65 @@
+// Package pagination implements keyset (cursor) pagination for the list endpoints. A cursor is the opaque
+// position after the last item of a page: the sort key and value of that item and its id as a tie breaker.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the keyset position of the last item of a page.
+type Cursor struct {
+	// Sort is the sort the cursor was created for, a cursor cannot be reused with another sort.
+	Sort  string `json:"s"`
+	Value string `json:"v,omitempty"`
+	ID    int64  `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor.
+func (c Cursor) Encode() string {
+	//nolint:errchkjson
+	raw, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Decode reads an opaque cursor created for sort.
+func Decode(encoded string, sort string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var cursor Cursor
+
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if cursor.Sort != sort {
+		return Cursor{}, fmt.Errorf("%w: created for sort %q", ErrInvalidCursor, cursor.Sort)
+	}
+
+	return cursor, nil
+}
+
+// Page trims items fetched with limit+1 to limit and returns the cursor of the next page, nil on the last page.
+func Page[T any](items []T, limit int, cursorOf func(T) Cursor) ([]T, *string) {
+	if len(items) <= limit {
+		return items, nil
+	}
+
+	items = items[:limit]
+	next := cursorOf(items[limit-1]).Encode()
+
+	return items, &next
+}
@@ -0,0 +1,56 @@
+//go:build unit
+
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Sort: "-balance", Value: "100.50", ID: 7}
+
+	t.Run("round_trip", func(t *testing.T) {
+		decoded, err := Decode(cursor.Encode(), "-balance")
+
+		assert.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+	})
+
+	t.Run("other_sort", func(t *testing.T) {
+		_, err := Decode(cursor.Encode(), "balance")
+
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := Decode("not a cursor", "-balance")
+
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+func TestPage(t *testing.T) {
+	cursorOf := func(id int64) Cursor {
+		return Cursor{Sort: "id", ID: id}
+	}
+
+	t.Run("more_items", func(t *testing.T) {
+		items, next := Page([]int64{1, 2, 3}, 2, cursorOf)
+
+		assert.Equal(t, []int64{1, 2}, items)
+		assert.NotNil(t, next)
+
+		cursor, err := Decode(*next, "id")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), cursor.ID)
+	})
+
+	t.Run("last_page", func(t *testing.T) {
+		items, next := Page([]int64{1, 2}, 2, cursorOf)
+
+		assert.Equal(t, []int64{1, 2}, items)
+		assert.Nil(t, next)
+	})
+}
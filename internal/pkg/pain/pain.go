@@ -0,0 +1,101 @@
+// Package pain reads ISO 20022 customer credit transfer initiations (pain.001.001.09) and writes the matching
+// customer payment status reports (pain.002.001.10).
+//
+// An initiation holds one or more payment information blocks, each debiting one account, with the credit
+// transfers to make from it. Parse checks that a file is a well-formed pain.001.001.09 document, Validate checks the
+// counts and control sums a client declares against the transactions it sent.
+package pain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// NamespaceInitiation is the namespace of the pain.001.001.09 documents.
+	NamespaceInitiation = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"
+	// NamespaceStatusReport is the namespace of the pain.002.001.10 documents.
+	NamespaceStatusReport = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.10"
+	// MessageNameInitiation is the message name of the initiations, it is reported as the original message name.
+	MessageNameInitiation = "pain.001.001.09"
+
+	// PaymentMethodTransfer is the only supported payment method, a credit transfer.
+	PaymentMethodTransfer = "TRF"
+)
+
+// ErrInvalidDocument is returned for a file that is not a well-formed pain.001.001.09 document.
+var ErrInvalidDocument = errors.New("invalid pain.001.001.09 document")
+
+// Status is a group, payment information or transaction status code.
+type Status string
+
+const (
+	// StatusAccepted is a transaction settled on the debtor account.
+	StatusAccepted Status = "ACSC"
+	// StatusPending is a transaction waiting for an approval or its requested execution date.
+	StatusPending  Status = "PDNG"
+	StatusRejected Status = "RJCT"
+	// StatusPartial is a group or a payment information whose transactions do not all have the same status.
+	StatusPartial Status = "PART"
+)
+
+// Reason codes of the ExternalStatusReason1Code list.
+const (
+	ReasonInvalidDebtorAccount        = "AC02"
+	ReasonInvalidCreditorAccount      = "AC03"
+	ReasonClosedAccount               = "AC04"
+	ReasonBlockedAccount              = "AC06"
+	ReasonNotAllowedAmount            = "AM02"
+	ReasonInsufficientFunds           = "AM04"
+	ReasonInvalidControlSum           = "AM10"
+	ReasonInvalidAmount               = "AM12"
+	ReasonInvalidNumberOfTransactions = "AM18"
+	ReasonIncorrectCurrency           = "CURR"
+	ReasonNarrative                   = "NARR"
+)
+
+// maxAdditionalInformation is the length limit of the additional information of a reason.
+const maxAdditionalInformation = 105
+
+// Reason is the reason of a rejection, a code with an optional free text.
+type Reason struct {
+	Code string
+	Info string
+}
+
+func newReason(code string, format string, args ...interface{}) *Reason {
+	return &Reason{Code: code, Info: fmt.Sprintf(format, args...)}
+}
+
+// additionalInformation truncates the free text of a reason to the length the schema allows.
+func additionalInformation(info string) string {
+	if utf8.RuneCountInString(info) <= maxAdditionalInformation {
+		return info
+	}
+
+	return string([]rune(info)[:maxAdditionalInformation])
+}
+
+// parseISODate reads an ISO date as its start (UTC), or an ISO date time, a date time without offset is in UTC.
+func parseISODate(date string, dateTime string) (time.Time, error) {
+	if date != "" {
+		parsed, err := time.Parse(time.DateOnly, strings.TrimSpace(date))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", date)
+		}
+
+		return parsed, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+		parsed, err := time.Parse(layout, strings.TrimSpace(dateTime))
+		if err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date time %q", dateTime)
+}
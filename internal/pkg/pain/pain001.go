@@ -0,0 +1,333 @@
+package pain
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// currencyCode matches an ISO 4217 alphabetic code.
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Initiation is a customer credit transfer initiation.
+type Initiation struct {
+	MessageID string
+	CreatedAt time.Time
+	// NumberOfTransactions and ControlSum are declared by the client for the whole message, the control sum is
+	// optional.
+	NumberOfTransactions int
+	ControlSum           decimal.NullDecimal
+	InitiatingParty      string
+	Payments             []Payment
+}
+
+// Payment is a payment information block, the credit transfers debiting one account.
+type Payment struct {
+	ID     string
+	Method string
+	// NumberOfTransactions and ControlSum are optional, NumberOfTransactions is zero when not declared.
+	NumberOfTransactions int
+	ControlSum           decimal.NullDecimal
+	// RequestedExecutionDate is the start (UTC) of the requested date, or the requested time.
+	RequestedExecutionDate time.Time
+	DebtorName             string
+	// DebtorAccount is the IBAN of the account, or its other identification.
+	DebtorAccount string
+	Transactions  []Transaction
+}
+
+// Transaction is a credit transfer transaction.
+type Transaction struct {
+	InstructionID   string
+	EndToEndID      string
+	Amount          decimal.Decimal
+	Currency        string
+	CreditorName    string
+	CreditorAccount string
+	Remittance      string
+}
+
+// The document element carries the namespace, so a document of another version of pain.001 is not matched.
+type initiationDocument struct {
+	XMLName    xml.Name       `xml:"urn:iso:std:iso:20022:tech:xsd:pain.001.001.09 Document"`
+	Initiation initiationElem `xml:"CstmrCdtTrfInitn"`
+}
+
+type initiationElem struct {
+	MessageID            string            `xml:"GrpHdr>MsgId"`
+	CreatedAt            string            `xml:"GrpHdr>CreDtTm"`
+	NumberOfTransactions string            `xml:"GrpHdr>NbOfTxs"`
+	ControlSum           string            `xml:"GrpHdr>CtrlSum"`
+	InitiatingParty      string            `xml:"GrpHdr>InitgPty>Nm"`
+	Payments             []paymentInfoElem `xml:"PmtInf"`
+}
+
+type paymentInfoElem struct {
+	ID                   string               `xml:"PmtInfId"`
+	Method               string               `xml:"PmtMtd"`
+	NumberOfTransactions string               `xml:"NbOfTxs"`
+	ControlSum           string               `xml:"CtrlSum"`
+	ExecutionDate        string               `xml:"ReqdExctnDt>Dt"`
+	ExecutionDateTime    string               `xml:"ReqdExctnDt>DtTm"`
+	DebtorName           string               `xml:"Dbtr>Nm"`
+	DebtorAccount        accountElem          `xml:"DbtrAcct"`
+	Transactions         []creditTransferElem `xml:"CdtTrfTxInf"`
+}
+
+type accountElem struct {
+	IBAN  string `xml:"Id>IBAN"`
+	Other string `xml:"Id>Othr>Id"`
+}
+
+func (a accountElem) id() string {
+	if iban := strings.TrimSpace(a.IBAN); iban != "" {
+		return iban
+	}
+
+	return strings.TrimSpace(a.Other)
+}
+
+type creditTransferElem struct {
+	InstructionID   string           `xml:"PmtId>InstrId"`
+	EndToEndID      string           `xml:"PmtId>EndToEndId"`
+	Amount          instructedAmount `xml:"Amt>InstdAmt"`
+	CreditorName    string           `xml:"Cdtr>Nm"`
+	CreditorAccount accountElem      `xml:"CdtrAcct"`
+	Unstructured    []string         `xml:"RmtInf>Ustrd"`
+}
+
+type instructedAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// Parse reads a pain.001.001.09 document. It fails on a document of another message or version, or missing the
+// identifications, counts, dates and amounts the schema requires.
+func Parse(reader io.Reader) (Initiation, error) {
+	var document initiationDocument
+
+	if err := xml.NewDecoder(reader).Decode(&document); err != nil {
+		return Initiation{}, fmt.Errorf("%w: decode document: %w", ErrInvalidDocument, err)
+	}
+
+	initiation, err := newInitiation(document.Initiation)
+	if err != nil {
+		return Initiation{}, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+
+	return initiation, nil
+}
+
+func newInitiation(elem initiationElem) (Initiation, error) {
+	initiation := Initiation{
+		MessageID:       strings.TrimSpace(elem.MessageID),
+		InitiatingParty: strings.TrimSpace(elem.InitiatingParty),
+	}
+
+	if initiation.MessageID == "" {
+		return Initiation{}, errors.New("missing message id")
+	}
+
+	createdAt, err := parseISODate("", elem.CreatedAt)
+	if err != nil {
+		return Initiation{}, fmt.Errorf("creation date time: %w", err)
+	}
+
+	initiation.CreatedAt = createdAt
+
+	initiation.NumberOfTransactions, err = parseCount(elem.NumberOfTransactions)
+	if err != nil {
+		return Initiation{}, err
+	}
+
+	if initiation.NumberOfTransactions == 0 {
+		return Initiation{}, errors.New("missing number of transactions")
+	}
+
+	initiation.ControlSum, err = parseControlSum(elem.ControlSum)
+	if err != nil {
+		return Initiation{}, err
+	}
+
+	if len(elem.Payments) == 0 {
+		return Initiation{}, errors.New("missing payment information")
+	}
+
+	for _, paymentElem := range elem.Payments {
+		payment, err := newPayment(paymentElem)
+		if err != nil {
+			return Initiation{}, fmt.Errorf("payment information %q: %w", paymentElem.ID, err)
+		}
+
+		initiation.Payments = append(initiation.Payments, payment)
+	}
+
+	return initiation, nil
+}
+
+func newPayment(elem paymentInfoElem) (Payment, error) {
+	payment := Payment{
+		ID:            strings.TrimSpace(elem.ID),
+		Method:        strings.TrimSpace(elem.Method),
+		DebtorName:    strings.TrimSpace(elem.DebtorName),
+		DebtorAccount: elem.DebtorAccount.id(),
+	}
+
+	if payment.ID == "" {
+		return Payment{}, errors.New("missing payment information id")
+	}
+
+	var err error
+
+	payment.NumberOfTransactions, err = parseCount(elem.NumberOfTransactions)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	payment.ControlSum, err = parseControlSum(elem.ControlSum)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	payment.RequestedExecutionDate, err = parseISODate(elem.ExecutionDate, elem.ExecutionDateTime)
+	if err != nil {
+		return Payment{}, fmt.Errorf("requested execution date: %w", err)
+	}
+
+	if len(elem.Transactions) == 0 {
+		return Payment{}, errors.New("missing credit transfer transaction")
+	}
+
+	for _, transactionElem := range elem.Transactions {
+		transaction := Transaction{
+			InstructionID:   strings.TrimSpace(transactionElem.InstructionID),
+			EndToEndID:      strings.TrimSpace(transactionElem.EndToEndID),
+			Currency:        strings.TrimSpace(transactionElem.Amount.Currency),
+			CreditorName:    strings.TrimSpace(transactionElem.CreditorName),
+			CreditorAccount: transactionElem.CreditorAccount.id(),
+			Remittance:      strings.TrimSpace(strings.Join(transactionElem.Unstructured, " ")),
+		}
+
+		if transaction.EndToEndID == "" {
+			return Payment{}, errors.New("missing end to end id")
+		}
+
+		transaction.Amount, err = decimal.NewFromString(strings.TrimSpace(transactionElem.Amount.Value))
+		if err != nil {
+			return Payment{}, fmt.Errorf("transaction %q: invalid instructed amount %q", transaction.EndToEndID,
+				transactionElem.Amount.Value)
+		}
+
+		payment.Transactions = append(payment.Transactions, transaction)
+	}
+
+	return payment, nil
+}
+
+func parseCount(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid number of transactions %q", value)
+	}
+
+	return count, nil
+}
+
+func parseControlSum(value string) (decimal.NullDecimal, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return decimal.NullDecimal{}, nil
+	}
+
+	sum, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.NullDecimal{}, fmt.Errorf("invalid control sum %q", value)
+	}
+
+	return decimal.NewNullDecimal(sum), nil
+}
+
+// Validate checks the number of transactions and the control sum declared for the message and for each payment
+// information, and the payment methods. It returns the reason to reject the whole message, nil when it is valid.
+func (i Initiation) Validate() *Reason {
+	count := 0
+	sum := decimal.Zero
+
+	for _, payment := range i.Payments {
+		if reason := payment.validate(); reason != nil {
+			return reason
+		}
+
+		count += len(payment.Transactions)
+		sum = sum.Add(payment.sum())
+	}
+
+	if count != i.NumberOfTransactions {
+		return newReason(ReasonInvalidNumberOfTransactions,
+			"number of transactions %d does not match the %d transactions of the message", i.NumberOfTransactions,
+			count)
+	}
+
+	if i.ControlSum.Valid && !i.ControlSum.Decimal.Equal(sum) {
+		return newReason(ReasonInvalidControlSum, "control sum %s does not match the sum %s of the message",
+			i.ControlSum.Decimal, sum)
+	}
+
+	return nil
+}
+
+func (p Payment) validate() *Reason {
+	if p.Method != PaymentMethodTransfer {
+		return newReason(ReasonNarrative, "payment information %s: payment method %q is not supported", p.ID,
+			p.Method)
+	}
+
+	if p.NumberOfTransactions != 0 && p.NumberOfTransactions != len(p.Transactions) {
+		return newReason(ReasonInvalidNumberOfTransactions,
+			"payment information %s: number of transactions %d does not match the %d transactions", p.ID,
+			p.NumberOfTransactions, len(p.Transactions))
+	}
+
+	if p.ControlSum.Valid && !p.ControlSum.Decimal.Equal(p.sum()) {
+		return newReason(ReasonInvalidControlSum, "payment information %s: control sum %s does not match the sum %s",
+			p.ID, p.ControlSum.Decimal, p.sum())
+	}
+
+	return nil
+}
+
+func (p Payment) sum() decimal.Decimal {
+	sum := decimal.Zero
+
+	for _, transaction := range p.Transactions {
+		sum = sum.Add(transaction.Amount)
+	}
+
+	return sum
+}
+
+// Validate checks the amount and the currency of a transaction. It returns the reason to reject the transaction,
+// nil when it is valid.
+func (t Transaction) Validate() *Reason {
+	if !t.Amount.IsPositive() {
+		return newReason(ReasonInvalidAmount, "amount %s is not positive", t.Amount)
+	}
+
+	if !currencyCode.MatchString(t.Currency) {
+		return newReason(ReasonIncorrectCurrency, "currency %q is not an ISO 4217 code", t.Currency)
+	}
+
+	return nil
+}
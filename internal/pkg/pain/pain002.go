@@ -0,0 +1,204 @@
+package pain
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// StatusReport is the customer payment status report of an initiation.
+type StatusReport struct {
+	MessageID string
+	CreatedAt time.Time
+
+	OriginalMessageID            string
+	OriginalNumberOfTransactions int
+	OriginalControlSum           decimal.NullDecimal
+	// Reason is set when the whole message is rejected.
+	Reason   *Reason
+	Payments []PaymentStatus
+}
+
+// PaymentStatus is the status of the transactions of a payment information block.
+type PaymentStatus struct {
+	OriginalPaymentID string
+	Transactions      []TransactionStatus
+}
+
+// TransactionStatus is the status of a transaction, the reason is set for a rejected transaction.
+type TransactionStatus struct {
+	StatusID              string
+	OriginalInstructionID string
+	OriginalEndToEndID    string
+	Amount                decimal.Decimal
+	Status                Status
+	Reason                *Reason
+}
+
+// GroupStatus is the status of the whole message: rejected when the message is rejected, the status its
+// transactions share, or partial.
+func (r StatusReport) GroupStatus() Status {
+	if r.Reason != nil {
+		return StatusRejected
+	}
+
+	var statuses []Status
+
+	for _, payment := range r.Payments {
+		for _, transaction := range payment.Transactions {
+			statuses = append(statuses, transaction.Status)
+		}
+	}
+
+	return commonStatus(statuses)
+}
+
+// Status is the status of the payment information block, the status its transactions share, or partial.
+func (p PaymentStatus) Status() Status {
+	statuses := make([]Status, 0, len(p.Transactions))
+
+	for _, transaction := range p.Transactions {
+		statuses = append(statuses, transaction.Status)
+	}
+
+	return commonStatus(statuses)
+}
+
+func commonStatus(statuses []Status) Status {
+	if len(statuses) == 0 {
+		return StatusRejected
+	}
+
+	for _, status := range statuses[1:] {
+		if status != statuses[0] {
+			return StatusPartial
+		}
+	}
+
+	return statuses[0]
+}
+
+type statusReportDocument struct {
+	XMLName xml.Name         `xml:"Document"`
+	Xmlns   string           `xml:"xmlns,attr"`
+	Report  statusReportElem `xml:"CstmrPmtStsRpt"`
+}
+
+type statusReportElem struct {
+	MessageID string                 `xml:"GrpHdr>MsgId"`
+	CreatedAt string                 `xml:"GrpHdr>CreDtTm"`
+	Group     originalGroupElem      `xml:"OrgnlGrpInfAndSts"`
+	Payments  []originalPaymentsElem `xml:"OrgnlPmtInfAndSts"`
+}
+
+type originalGroupElem struct {
+	MessageID            string              `xml:"OrgnlMsgId"`
+	MessageName          string              `xml:"OrgnlMsgNmId"`
+	NumberOfTransactions int                 `xml:"OrgnlNbOfTxs"`
+	ControlSum           *decimal.Decimal    `xml:"OrgnlCtrlSum,omitempty"`
+	Status               Status              `xml:"GrpSts"`
+	Reason               *reasonElem         `xml:"StsRsnInf,omitempty"`
+	PerStatus            []statusSummaryElem `xml:"NbOfTxsPerSts"`
+}
+
+type statusSummaryElem struct {
+	NumberOfTransactions int             `xml:"DtldNbOfTxs"`
+	Status               Status          `xml:"DtldSts"`
+	ControlSum           decimal.Decimal `xml:"DtldCtrlSum"`
+}
+
+type originalPaymentsElem struct {
+	PaymentID    string            `xml:"OrgnlPmtInfId"`
+	Status       Status            `xml:"PmtInfSts"`
+	Transactions []transactionElem `xml:"TxInfAndSts"`
+}
+
+type transactionElem struct {
+	StatusID      string      `xml:"StsId"`
+	InstructionID string      `xml:"OrgnlInstrId,omitempty"`
+	EndToEndID    string      `xml:"OrgnlEndToEndId"`
+	Status        Status      `xml:"TxSts"`
+	Reason        *reasonElem `xml:"StsRsnInf,omitempty"`
+}
+
+type reasonElem struct {
+	Code string `xml:"Rsn>Cd"`
+	Info string `xml:"AddtlInf,omitempty"`
+}
+
+func newReasonElem(reason *Reason) *reasonElem {
+	if reason == nil {
+		return nil
+	}
+
+	return &reasonElem{Code: reason.Code, Info: additionalInformation(reason.Info)}
+}
+
+// Marshal writes the report as a pain.002.001.10 document.
+func (r StatusReport) Marshal() ([]byte, error) {
+	group := originalGroupElem{
+		MessageID:            r.OriginalMessageID,
+		MessageName:          MessageNameInitiation,
+		NumberOfTransactions: r.OriginalNumberOfTransactions,
+		Status:               r.GroupStatus(),
+		Reason:               newReasonElem(r.Reason),
+	}
+
+	if r.OriginalControlSum.Valid {
+		group.ControlSum = &r.OriginalControlSum.Decimal
+	}
+
+	payments := make([]originalPaymentsElem, 0, len(r.Payments))
+	summaries := map[Status]*statusSummaryElem{}
+
+	var order []Status
+
+	for _, payment := range r.Payments {
+		paymentElem := originalPaymentsElem{PaymentID: payment.OriginalPaymentID, Status: payment.Status()}
+
+		for _, transaction := range payment.Transactions {
+			paymentElem.Transactions = append(paymentElem.Transactions, transactionElem{
+				StatusID:      transaction.StatusID,
+				InstructionID: transaction.OriginalInstructionID,
+				EndToEndID:    transaction.OriginalEndToEndID,
+				Status:        transaction.Status,
+				Reason:        newReasonElem(transaction.Reason),
+			})
+
+			summary, ok := summaries[transaction.Status]
+			if !ok {
+				summary = &statusSummaryElem{Status: transaction.Status}
+				summaries[transaction.Status] = summary
+				order = append(order, transaction.Status)
+			}
+
+			summary.NumberOfTransactions++
+			summary.ControlSum = summary.ControlSum.Add(transaction.Amount)
+		}
+
+		payments = append(payments, paymentElem)
+	}
+
+	for _, status := range order {
+		group.PerStatus = append(group.PerStatus, *summaries[status])
+	}
+
+	document := statusReportDocument{
+		Xmlns: NamespaceStatusReport,
+		Report: statusReportElem{
+			MessageID: r.MessageID,
+			CreatedAt: r.CreatedAt.UTC().Format(time.RFC3339),
+			Group:     group,
+			Payments:  payments,
+		},
+	}
+
+	content, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode status report: %w", err)
+	}
+
+	return append([]byte(xml.Header), content...), nil
+}
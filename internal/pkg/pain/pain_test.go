@@ -0,0 +1,204 @@
+//go:build unit
+
+package pain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const testInitiation = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-20261018-1</MsgId>
+      <CreDtTm>2026-10-18T09:30:00+02:00</CreDtTm>
+      <NbOfTxs>3</NbOfTxs>
+      <CtrlSum>175.50</CtrlSum>
+      <InitgPty><Nm>ACME Corp</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-1</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <NbOfTxs>2</NbOfTxs>
+      <ReqdExctnDt><Dt>2026-10-18</Dt></ReqdExctnDt>
+      <Dbtr><Nm>ACME Corp</Nm></Dbtr>
+      <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><InstrId>INSTR-1</InstrId><EndToEndId>E2E-1</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">100.00</InstdAmt></Amt>
+        <Cdtr><Nm>Supplier One</Nm></Cdtr>
+        <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+        <RmtInf><Ustrd>Invoice 1</Ustrd><Ustrd>October</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-2</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">50.50</InstdAmt></Amt>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+    <PmtInf>
+      <PmtInfId>PMT-2</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt><DtTm>2026-10-20T08:00:00</DtTm></ReqdExctnDt>
+      <DbtrAcct><Id><Othr><Id>3</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-3</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">25</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func TestParse(t *testing.T) {
+	initiation, err := Parse(strings.NewReader(testInitiation))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "MSG-20261018-1", initiation.MessageID)
+	assert.Equal(t, time.Date(2026, 10, 18, 7, 30, 0, 0, time.UTC), initiation.CreatedAt)
+	assert.Equal(t, 3, initiation.NumberOfTransactions)
+	assert.True(t, initiation.ControlSum.Decimal.Equal(decimal.RequireFromString("175.50")))
+	assert.Equal(t, "ACME Corp", initiation.InitiatingParty)
+	assert.Len(t, initiation.Payments, 2)
+
+	payment := initiation.Payments[0]
+	assert.Equal(t, "PMT-1", payment.ID)
+	assert.Equal(t, PaymentMethodTransfer, payment.Method)
+	assert.Equal(t, 2, payment.NumberOfTransactions)
+	assert.False(t, payment.ControlSum.Valid)
+	assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), payment.RequestedExecutionDate)
+	assert.Equal(t, "1", payment.DebtorAccount)
+	assert.Equal(t, Transaction{
+		InstructionID:   "INSTR-1",
+		EndToEndID:      "E2E-1",
+		Amount:          decimal.RequireFromString("100.00"),
+		Currency:        "USD",
+		CreditorName:    "Supplier One",
+		CreditorAccount: "2",
+		Remittance:      "Invoice 1 October",
+	}, payment.Transactions[0])
+	assert.Equal(t, "DE89370400440532013000", payment.Transactions[1].CreditorAccount)
+
+	assert.Equal(t, time.Date(2026, 10, 20, 8, 0, 0, 0, time.UTC), initiation.Payments[1].RequestedExecutionDate)
+	assert.Nil(t, initiation.Validate())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"not_xml", "not a payment file"},
+		{"other_version", strings.Replace(testInitiation, "pain.001.001.09", "pain.001.001.03", 1)},
+		{"missing_message_id", strings.Replace(testInitiation, "<MsgId>MSG-20261018-1</MsgId>", "", 1)},
+		{"missing_number_of_transactions", strings.Replace(testInitiation, "<NbOfTxs>3</NbOfTxs>", "", 1)},
+		{"missing_execution_date", strings.Replace(testInitiation,
+			"<ReqdExctnDt><Dt>2026-10-18</Dt></ReqdExctnDt>", "", 1)},
+		{"missing_end_to_end_id", strings.Replace(testInitiation, "<EndToEndId>E2E-2</EndToEndId>", "", 1)},
+		{"invalid_amount", strings.Replace(testInitiation, "50.50", "fifty", 1)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(testCase.content))
+			assert.ErrorIs(t, err, ErrInvalidDocument)
+		})
+	}
+}
+
+func TestInitiation_Validate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		wantCode string
+	}{
+		{"number_of_transactions", strings.Replace(testInitiation, "<NbOfTxs>3</NbOfTxs>", "<NbOfTxs>4</NbOfTxs>",
+			1), ReasonInvalidNumberOfTransactions},
+		{"control_sum", strings.Replace(testInitiation, "<CtrlSum>175.50</CtrlSum>", "<CtrlSum>175</CtrlSum>", 1),
+			ReasonInvalidControlSum},
+		{"payment_number_of_transactions", strings.Replace(testInitiation, "<NbOfTxs>2</NbOfTxs>",
+			"<NbOfTxs>1</NbOfTxs>", 1), ReasonInvalidNumberOfTransactions},
+		{"payment_method", strings.Replace(testInitiation, "<PmtMtd>TRF</PmtMtd>", "<PmtMtd>CHK</PmtMtd>", 1),
+			ReasonNarrative},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			initiation, err := Parse(strings.NewReader(testCase.content))
+			assert.NoError(t, err)
+
+			reason := initiation.Validate()
+			if assert.NotNil(t, reason) {
+				assert.Equal(t, testCase.wantCode, reason.Code)
+			}
+		})
+	}
+}
+
+func TestTransaction_Validate(t *testing.T) {
+	valid := Transaction{EndToEndID: "E2E-1", Amount: decimal.NewFromInt(10), Currency: "USD"}
+	assert.Nil(t, valid.Validate())
+
+	zero := valid
+	zero.Amount = decimal.Zero
+	assert.Equal(t, ReasonInvalidAmount, zero.Validate().Code)
+
+	currency := valid
+	currency.Currency = "usd"
+	assert.Equal(t, ReasonIncorrectCurrency, currency.Validate().Code)
+}
+
+func TestStatusReport_Marshal(t *testing.T) {
+	report := StatusReport{
+		MessageID:                    "STS-1",
+		CreatedAt:                    time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC),
+		OriginalMessageID:            "MSG-20261018-1",
+		OriginalNumberOfTransactions: 3,
+		OriginalControlSum:           decimal.NewNullDecimal(decimal.RequireFromString("175.5")),
+		Payments: []PaymentStatus{
+			{
+				OriginalPaymentID: "PMT-1",
+				Transactions: []TransactionStatus{
+					{StatusID: "STS-1-1", OriginalInstructionID: "INSTR-1", OriginalEndToEndID: "E2E-1",
+						Amount: decimal.NewFromInt(100), Status: StatusAccepted},
+					{StatusID: "STS-1-2", OriginalEndToEndID: "E2E-2", Amount: decimal.RequireFromString("50.5"),
+						Status: StatusRejected, Reason: &Reason{Code: ReasonInvalidCreditorAccount,
+							Info: strings.Repeat("x", 120)}},
+				},
+			},
+			{
+				OriginalPaymentID: "PMT-2",
+				Transactions: []TransactionStatus{
+					{StatusID: "STS-1-3", OriginalEndToEndID: "E2E-3", Amount: decimal.NewFromInt(25),
+						Status: StatusPending},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, StatusPartial, report.GroupStatus())
+	assert.Equal(t, StatusPartial, report.Payments[0].Status())
+	assert.Equal(t, StatusPending, report.Payments[1].Status())
+
+	content, err := report.Marshal()
+	assert.NoError(t, err)
+
+	document := string(content)
+	assert.Contains(t, document, `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.10">`)
+	assert.Contains(t, document, "<OrgnlMsgNmId>pain.001.001.09</OrgnlMsgNmId>")
+	assert.Contains(t, document, "<OrgnlCtrlSum>175.5</OrgnlCtrlSum>")
+	assert.Contains(t, document, "<GrpSts>PART</GrpSts>")
+	assert.Contains(t, document, "<DtldNbOfTxs>1</DtldNbOfTxs>\n        <DtldSts>RJCT</DtldSts>")
+	assert.Contains(t, document, "<OrgnlInstrId>INSTR-1</OrgnlInstrId>")
+	assert.Contains(t, document, "<Cd>AC03</Cd>")
+	assert.Contains(t, document, "<AddtlInf>"+strings.Repeat("x", 105)+"</AddtlInf>")
+	assert.Contains(t, document, "<PmtInfSts>PDNG</PmtInfSts>")
+
+	report.Reason = &Reason{Code: ReasonInvalidControlSum}
+	assert.Equal(t, StatusRejected, report.GroupStatus())
+}
@@ -0,0 +1,260 @@
+// Package recurrence implements the subset of the iCalendar RRULE format (RFC 5545)
+// used by standing orders, e.g. "FREQ=MONTHLY;BYMONTHDAY=1" or "FREQ=WEEKLY;BYDAY=FR".
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+const (
+	hoursPerDay = 24
+	daysPerWeek = 7
+	// maxSearchDays bounds the search for the next occurrence per interval unit,
+	// it is large enough to find a BYMONTHDAY=31 occurrence in any month sequence.
+	maxSearchDays = 4 * 366
+	// maxInterval bounds INTERVAL, the search for the next occurrence grows with it.
+	maxInterval = 366
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Rule is a parsed recurrence rule.
+type Rule struct {
+	Frequency  Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+}
+
+// Parse parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR".
+// Supported parts are FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL, BYDAY (WEEKLY only)
+// and BYMONTHDAY (MONTHLY only, negative values count from the end of the month).
+func Parse(rule string) (Rule, error) {
+	parsed := Rule{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"), ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Rule{}, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+
+		var err error
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			parsed.Frequency = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			parsed.Interval, err = strconv.Atoi(value)
+			if err == nil && (parsed.Interval < 1 || parsed.Interval > maxInterval) {
+				err = fmt.Errorf("interval must be between 1 and %d", maxInterval)
+			}
+		case "BYDAY":
+			parsed.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			parsed.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			err = errors.New("unsupported part")
+		}
+
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %s: %w", ErrInvalidRule, key, err)
+		}
+	}
+
+	if err := parsed.validate(); err != nil {
+		return Rule{}, err
+	}
+
+	return parsed, nil
+}
+
+func (r Rule) validate() error {
+	switch r.Frequency {
+	case FrequencyDaily:
+		if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+			return fmt.Errorf("%w: DAILY does not support BYDAY or BYMONTHDAY", ErrInvalidRule)
+		}
+	case FrequencyWeekly:
+		if len(r.ByMonthDay) > 0 {
+			return fmt.Errorf("%w: WEEKLY does not support BYMONTHDAY", ErrInvalidRule)
+		}
+	case FrequencyMonthly:
+		if len(r.ByDay) > 0 {
+			return fmt.Errorf("%w: MONTHLY does not support BYDAY", ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported frequency %q", ErrInvalidRule, r.Frequency)
+	}
+
+	return nil
+}
+
+// String formats the rule back into its RRULE representation.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Frequency)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+
+		for _, weekday := range r.ByDay {
+			for code, day := range weekdays {
+				if day == weekday {
+					days = append(days, code)
+				}
+			}
+		}
+
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after the given time. Occurrences are anchored to
+// start: they happen at the clock time of start, and never before start itself.
+func (r Rule) Next(start time.Time, after time.Time) time.Time {
+	day := startOfDay(start)
+	if after.After(start) {
+		day = startOfDay(after.In(start.Location()))
+	}
+
+	for range maxSearchDays * r.Interval {
+		candidate := time.Date(day.Year(), day.Month(), day.Day(),
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+
+		if !candidate.Before(start) && candidate.After(after) && r.matches(start, candidate) {
+			return candidate
+		}
+
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return time.Time{}
+}
+
+func (r Rule) matches(start time.Time, candidate time.Time) bool {
+	switch r.Frequency {
+	case FrequencyDaily:
+		return daysBetween(start, candidate)%r.Interval == 0
+	case FrequencyWeekly:
+		byDay := r.ByDay
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{start.Weekday()}
+		}
+
+		weeks := daysBetween(weekStart(start), weekStart(candidate)) / daysPerWeek
+
+		return weeks%r.Interval == 0 && slices.Contains(byDay, candidate.Weekday())
+	case FrequencyMonthly:
+		byMonthDay := r.ByMonthDay
+		if len(byMonthDay) == 0 {
+			byMonthDay = []int{start.Day()}
+		}
+
+		months := (candidate.Year()-start.Year())*12 + int(candidate.Month()) - int(start.Month())
+
+		return months%r.Interval == 0 && matchesMonthDay(byMonthDay, candidate)
+	}
+
+	return false
+}
+
+func matchesMonthDay(byMonthDay []int, candidate time.Time) bool {
+	lastDay := time.Date(candidate.Year(), candidate.Month()+1, 0, 0, 0, 0, 0, candidate.Location()).Day()
+
+	for _, day := range byMonthDay {
+		if day == candidate.Day() || (day < 0 && lastDay+day+1 == candidate.Day()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseByDay(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+
+	for _, code := range strings.Split(value, ",") {
+		day, ok := weekdays[strings.ToUpper(code)]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", code)
+		}
+
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var days []int
+
+	for _, raw := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse month day: %w", err)
+		}
+
+		if day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("month day %d out of range", day)
+		}
+
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func weekStart(t time.Time) time.Time {
+	// weeks start on monday as in RFC 5545 default WKST
+	offset := (int(t.Weekday()) + daysPerWeek - 1) % daysPerWeek
+
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(to.Sub(from).Hours() / hoursPerDay)
+}
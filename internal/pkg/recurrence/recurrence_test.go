@@ -0,0 +1,122 @@
+//go:build unit
+
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{name: "daily", rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "weekly with days", rule: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"},
+		{name: "monthly last day", rule: "freq=monthly;bymonthday=-1", want: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{name: "unknown frequency", rule: "FREQ=YEARLY", wantErr: true},
+		{name: "unsupported part", rule: "FREQ=DAILY;COUNT=3", wantErr: true},
+		{name: "invalid interval", rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "interval too large", rule: "FREQ=DAILY;INTERVAL=100000000", wantErr: true},
+		{name: "largest interval", rule: "FREQ=DAILY;INTERVAL=366", want: "FREQ=DAILY;INTERVAL=366"},
+		{name: "invalid weekday", rule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{name: "month day out of range", rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{name: "byday on monthly", rule: "FREQ=MONTHLY;BYDAY=MO", wantErr: true},
+		{name: "malformed", rule: "FREQ", wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule, err := Parse(testCase.rule)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRule)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, rule.String())
+		})
+	}
+}
+
+func TestRuleNext(t *testing.T) {
+	// 2025-01-01 is a wednesday
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name  string
+		rule  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "first occurrence is start",
+			rule:  "FREQ=DAILY",
+			after: start.Add(-time.Hour),
+			want:  start,
+		},
+		{
+			name:  "every other day",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			after: start,
+			want:  time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "every friday",
+			rule:  "FREQ=WEEKLY;BYDAY=FR",
+			after: start,
+			want:  time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "weekly defaults to start weekday",
+			rule:  "FREQ=WEEKLY",
+			after: start,
+			want:  time.Date(2025, 1, 8, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "every second week on monday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			after: start,
+			want:  time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "monthly on the 1st",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1",
+			after: start,
+			want:  time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "monthly on the last day",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			after: time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			after: time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "same day after clock time",
+			rule:  "FREQ=DAILY",
+			after: time.Date(2025, 1, 5, 8, 59, 0, 0, time.UTC),
+			want:  time.Date(2025, 1, 5, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule, err := Parse(testCase.rule)
+			assert.NoError(t, err)
+
+			assert.Equal(t, testCase.want, rule.Next(start, testCase.after))
+		})
+	}
+}
@@ -0,0 +1,548 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// The condition of a rule is a boolean expression over the facts of a transfer:
+//
+//	condition  = or
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = operand ( ">" | ">=" | "<" | "<=" | "==" | "!=" ) operand | operand "in" "[" literal { "," literal } "]"
+//	operand    = field | literal
+//	literal    = number | duration | string
+//
+// A number is a decimal such as 5000 or -0.5, a duration is a Go duration or a number of days such as 90s, 24h or
+// 7d and a string is double quoted. Both sides of a comparison must be of the same type and strings only support
+// == and !=.
+
+var errSyntax = errors.New("syntax error")
+
+type valueType int
+
+const (
+	typeNumber valueType = iota + 1
+	typeDuration
+	typeString
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeNumber:
+		return "number"
+	case typeDuration:
+		return "duration"
+	case typeString:
+		return "string"
+	}
+
+	return "unknown"
+}
+
+type value struct {
+	typ      valueType
+	number   decimal.Decimal
+	duration time.Duration
+	str      string
+}
+
+func numberValue(number decimal.Decimal) value {
+	return value{typ: typeNumber, number: number}
+}
+
+func durationValue(duration time.Duration) value {
+	return value{typ: typeDuration, duration: duration}
+}
+
+func stringValue(str string) value {
+	return value{typ: typeString, str: str}
+}
+
+// compare returns -1, 0 or +1 as v is less than, equal to or greater than other, both of the same type.
+func (v value) compare(other value) int {
+	switch v.typ {
+	case typeNumber:
+		return v.number.Cmp(other.number)
+	case typeDuration:
+		return compareOrdered(v.duration, other.duration)
+	default:
+		return strings.Compare(v.str, other.str)
+	}
+}
+
+func compareOrdered[T ~int64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// condition is a parsed rule condition.
+type condition interface {
+	eval(transfer Transfer) bool
+}
+
+type andCondition struct {
+	left, right condition
+}
+
+func (c andCondition) eval(transfer Transfer) bool {
+	return c.left.eval(transfer) && c.right.eval(transfer)
+}
+
+type orCondition struct {
+	left, right condition
+}
+
+func (c orCondition) eval(transfer Transfer) bool {
+	return c.left.eval(transfer) || c.right.eval(transfer)
+}
+
+type notCondition struct {
+	operand condition
+}
+
+func (c notCondition) eval(transfer Transfer) bool {
+	return !c.operand.eval(transfer)
+}
+
+type comparison struct {
+	op          string
+	left, right operand
+}
+
+func (c comparison) eval(transfer Transfer) bool {
+	result := c.left.value(transfer).compare(c.right.value(transfer))
+
+	switch c.op {
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case "==":
+		return result == 0
+	default:
+		return result != 0
+	}
+}
+
+type inCondition struct {
+	operand operand
+	list    []value
+}
+
+func (c inCondition) eval(transfer Transfer) bool {
+	operand := c.operand.value(transfer)
+
+	for _, item := range c.list {
+		if operand.compare(item) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+type operand interface {
+	value(transfer Transfer) value
+	valueType() valueType
+}
+
+type fieldOperand struct {
+	field field
+}
+
+func (o fieldOperand) value(transfer Transfer) value {
+	return o.field.value(transfer)
+}
+
+func (o fieldOperand) valueType() valueType {
+	return o.field.typ
+}
+
+type literalOperand struct {
+	literal value
+}
+
+func (o literalOperand) value(_ Transfer) value {
+	return o.literal
+}
+
+func (o literalOperand) valueType() valueType {
+	return o.literal.typ
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenDuration
+	tokenString
+	tokenOperator
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenize splits a condition into tokens, the last token is tokenEOF.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(src)
+
+	for pos := 0; pos < len(runes); {
+		char := runes[pos]
+		start := pos
+
+		switch {
+		case unicode.IsSpace(char):
+			pos++
+
+			continue
+		case unicode.IsLetter(char) || char == '_':
+			for pos < len(runes) && isIdentRune(runes[pos]) {
+				pos++
+			}
+
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:pos]), pos: start})
+		case unicode.IsDigit(char) || (char == '-' && pos+1 < len(runes) && unicode.IsDigit(runes[pos+1])):
+			pos++
+			for pos < len(runes) && (unicode.IsDigit(runes[pos]) || runes[pos] == '.' || unicode.IsLetter(runes[pos])) {
+				pos++
+			}
+
+			text := string(runes[start:pos])
+			kind := tokenNumber
+
+			if strings.ContainsFunc(text, unicode.IsLetter) {
+				kind = tokenDuration
+			}
+
+			tokens = append(tokens, token{kind: kind, text: text, pos: start})
+		case char == '"':
+			pos++
+			for pos < len(runes) && runes[pos] != '"' {
+				pos++
+			}
+
+			if pos == len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string at %d", errSyntax, start)
+			}
+
+			pos++
+
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[start+1 : pos-1]), pos: start})
+		case strings.ContainsRune("<>=!", char):
+			pos++
+			if pos < len(runes) && runes[pos] == '=' {
+				pos++
+			}
+
+			text := string(runes[start:pos])
+			if text == "=" || text == "!" {
+				return nil, fmt.Errorf("%w: unknown operator %q at %d", errSyntax, text, start)
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: text, pos: start})
+		case strings.ContainsRune("()[],", char):
+			pos++
+
+			tokens = append(tokens, token{kind: tokenPunct, text: string(char), pos: start})
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at %d", errSyntax, char, start)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func isIdentRune(char rune) bool {
+	return unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' || char == '.'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseCondition parses and type checks the condition of a rule.
+func parseCondition(src string) (condition, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, p.unexpected(next)
+	}
+
+	return cond, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+
+	return tok.kind == tokenIdent && tok.text == keyword
+}
+
+func (p *parser) isPunct(punct string) bool {
+	tok := p.peek()
+
+	return tok.kind == tokenPunct && tok.text == punct
+}
+
+func (p *parser) expectPunct(punct string) error {
+	if !p.isPunct(punct) {
+		return p.unexpected(p.peek())
+	}
+
+	p.next()
+
+	return nil
+}
+
+func (p *parser) unexpected(tok token) error {
+	if tok.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of condition", errSyntax)
+	}
+
+	return fmt.Errorf("%w: unexpected %q at %d", errSyntax, tok.text, tok.pos)
+}
+
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("or") {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orCondition{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("and") {
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andCondition{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (condition, error) {
+	if p.isKeyword("not") {
+		p.next()
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notCondition{operand: operand}, nil
+	}
+
+	if p.isPunct("(") {
+		p.next()
+
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+
+		return cond, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (condition, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword("in") {
+		p.next()
+
+		return p.parseIn(left)
+	}
+
+	opToken := p.next()
+	if opToken.kind != tokenOperator {
+		return nil, p.unexpected(opToken)
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if left.valueType() != right.valueType() {
+		return nil, fmt.Errorf("%w: cannot compare %s with %s at %d", errSyntax, left.valueType(),
+			right.valueType(), opToken.pos)
+	}
+
+	if left.valueType() == typeString && opToken.text != "==" && opToken.text != "!=" {
+		return nil, fmt.Errorf("%w: strings only support == and != at %d", errSyntax, opToken.pos)
+	}
+
+	return comparison{op: opToken.text, left: left, right: right}, nil
+}
+
+func (p *parser) parseIn(left operand) (condition, error) {
+	if err := p.expectPunct("["); err != nil {
+		return nil, err
+	}
+
+	cond := inCondition{operand: left}
+
+	for {
+		tok := p.peek()
+
+		item, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		literal, ok := item.(literalOperand)
+		if !ok {
+			return nil, fmt.Errorf("%w: the list of in must hold literals at %d", errSyntax, tok.pos)
+		}
+
+		if literal.valueType() != left.valueType() {
+			return nil, fmt.Errorf("%w: cannot compare %s with %s at %d", errSyntax, left.valueType(),
+				literal.valueType(), tok.pos)
+		}
+
+		cond.list = append(cond.list, literal.literal)
+
+		if !p.isPunct(",") {
+			break
+		}
+
+		p.next()
+	}
+
+	if err := p.expectPunct("]"); err != nil {
+		return nil, err
+	}
+
+	return cond, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenIdent:
+		field, ok := fields[tok.text]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q at %d", errSyntax, tok.text, tok.pos)
+		}
+
+		return fieldOperand{field: field}, nil
+	case tokenNumber:
+		number, err := decimal.NewFromString(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q at %d", errSyntax, tok.text, tok.pos)
+		}
+
+		return literalOperand{literal: numberValue(number)}, nil
+	case tokenDuration:
+		duration, err := parseDuration(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid duration %q at %d", errSyntax, tok.text, tok.pos)
+		}
+
+		return literalOperand{literal: durationValue(duration)}, nil
+	case tokenString:
+		return literalOperand{literal: stringValue(tok.text)}, nil
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+// parseDuration reads a Go duration or a whole number of days, e.g. 7d.
+func parseDuration(text string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(text, "d"); ok {
+		number, err := decimal.NewFromString(days)
+		if err != nil || !number.IsInteger() {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+
+		return time.Duration(number.IntPart()) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("parse duration: %w", err)
+	}
+
+	return duration, nil
+}
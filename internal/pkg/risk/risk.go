@@ -0,0 +1,281 @@
+// Package risk decides whether a transfer may go ahead from a list of rules loaded from a YAML file.
+//
+// Rules are evaluated in order and the first rule whose condition matches the transfer decides: allow lets the
+// transfer go ahead without evaluating the next rules, deny refuses it and review holds it until a person approves
+// it. A transfer matching no rule is allowed. The conditions are written in a small expression language, e.g.
+// `amount > 5000 and destination.age < 24h`, see expr.go.
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+type Action string
+
+const (
+	ActionAllow  Action = "allow"
+	ActionDeny   Action = "deny"
+	ActionReview Action = "review"
+)
+
+var ErrInvalidRules = errors.New("invalid risk rules")
+
+// Account holds the facts of an account taking part in a transfer.
+type Account struct {
+	ID        int64
+	Type      string
+	Status    string
+	Currency  string
+	Balance   decimal.Decimal
+	CreatedAt time.Time
+}
+
+// Transfer holds the facts a rule condition can refer to, At is the time the transfer is evaluated.
+type Transfer struct {
+	Amount      decimal.Decimal
+	InitiatedBy string
+	Source      Account
+	Destination Account
+	At          time.Time
+}
+
+// field is a fact of a transfer a condition refers to by name.
+type field struct {
+	typ   valueType
+	value func(transfer Transfer) value
+}
+
+// fields are the facts of a transfer: amount, currency, initiated_by, hour (the UTC hour of the day) and the id,
+// type, status, currency, balance and age of the source and destination accounts.
+var fields = newFields()
+
+func newFields() map[string]field {
+	fields := map[string]field{
+		"amount":       {typeNumber, func(t Transfer) value { return numberValue(t.Amount) }},
+		"currency":     {typeString, func(t Transfer) value { return stringValue(t.Source.Currency) }},
+		"initiated_by": {typeString, func(t Transfer) value { return stringValue(t.InitiatedBy) }},
+		"hour": {typeNumber, func(t Transfer) value {
+			return numberValue(decimal.NewFromInt(int64(t.At.UTC().Hour())))
+		}},
+	}
+
+	for prefix, account := range map[string]func(Transfer) Account{
+		"source":      func(t Transfer) Account { return t.Source },
+		"destination": func(t Transfer) Account { return t.Destination },
+	} {
+		fields[prefix+".id"] = field{typeNumber, func(t Transfer) value {
+			return numberValue(decimal.NewFromInt(account(t).ID))
+		}}
+		fields[prefix+".type"] = field{typeString, func(t Transfer) value { return stringValue(account(t).Type) }}
+		fields[prefix+".status"] = field{typeString, func(t Transfer) value { return stringValue(account(t).Status) }}
+		fields[prefix+".currency"] = field{typeString, func(t Transfer) value {
+			return stringValue(account(t).Currency)
+		}}
+		fields[prefix+".balance"] = field{typeNumber, func(t Transfer) value { return numberValue(account(t).Balance) }}
+		fields[prefix+".age"] = field{typeDuration, func(t Transfer) value {
+			return durationValue(t.At.Sub(account(t).CreatedAt))
+		}}
+	}
+
+	return fields
+}
+
+// RuleSet is the ordered list of rules.
+type RuleSet struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule decides on the transfers matching its condition. MessageID and Message are the localized error of a denied
+// transfer, the default denial error is used when they are empty.
+type Rule struct {
+	Name      string `yaml:"name"`
+	When      string `yaml:"when"`
+	Action    Action `yaml:"action"`
+	MessageID string `yaml:"message_id"`
+	Message   string `yaml:"message"`
+
+	condition condition
+}
+
+// Decision is the outcome of the evaluation of a transfer, Rule is empty when no rule matched.
+type Decision struct {
+	Action    Action
+	Rule      string
+	MessageID string
+	Message   string
+}
+
+// Load reads and validates rules from a YAML file.
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read risk rules: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes the YAML rules and parses their conditions.
+func Parse(data []byte) (*RuleSet, error) {
+	var ruleSet RuleSet
+
+	if err := yaml.Unmarshal(data, &ruleSet); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRules, err)
+	}
+
+	names := make(map[string]bool, len(ruleSet.Rules))
+
+	for i := range ruleSet.Rules {
+		rule := &ruleSet.Rules[i]
+
+		if err := rule.parse(); err != nil {
+			return nil, fmt.Errorf("%w: rule %d %q: %w", ErrInvalidRules, i, rule.Name, err)
+		}
+
+		if names[rule.Name] {
+			return nil, fmt.Errorf("%w: rule %d: duplicate name %q", ErrInvalidRules, i, rule.Name)
+		}
+
+		names[rule.Name] = true
+	}
+
+	return &ruleSet, nil
+}
+
+func (r *Rule) parse() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	switch r.Action {
+	case ActionAllow, ActionReview:
+		if r.MessageID != "" || r.Message != "" {
+			return errors.New("only a deny rule has a message")
+		}
+	case ActionDeny:
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+
+	if r.When == "" {
+		return errors.New("when is required")
+	}
+
+	cond, err := parseCondition(r.When)
+	if err != nil {
+		return err
+	}
+
+	r.condition = cond
+
+	return nil
+}
+
+// Evaluate returns the decision of the first rule matching the transfer, a transfer matching no rule is allowed.
+func (s *RuleSet) Evaluate(transfer Transfer) Decision {
+	for _, rule := range s.Rules {
+		if !rule.condition.eval(transfer) {
+			continue
+		}
+
+		return Decision{
+			Action:    rule.Action,
+			Rule:      rule.Name,
+			MessageID: rule.MessageID,
+			Message:   rule.Message,
+		}
+	}
+
+	return Decision{Action: ActionAllow}
+}
+
+// Engine evaluates the transfers against the rules of a file. The file is read again when it changes, so the rules
+// are changed without a restart.
+type Engine struct {
+	path    string
+	ruleSet atomic.Pointer[RuleSet]
+
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+// NewEngine loads the rules of the file at path.
+func NewEngine(path string) (*Engine, error) {
+	engine := &Engine{path: path}
+
+	if _, err := engine.Reload(); err != nil {
+		return nil, err
+	}
+
+	return engine, nil
+}
+
+// Evaluate returns the decision of the rules in force on the transfer.
+func (e *Engine) Evaluate(transfer Transfer) Decision {
+	return e.ruleSet.Load().Evaluate(transfer)
+}
+
+// Reload reads the file again when it was modified since it was loaded and reports whether the rules were replaced.
+// The rules in force are kept when the file is invalid.
+func (e *Engine) Reload() (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return false, fmt.Errorf("stat risk rules: %w", err)
+	}
+
+	if e.ruleSet.Load() != nil && info.ModTime().Equal(e.modTime) {
+		return false, nil
+	}
+
+	ruleSet, err := Load(e.path)
+	if err != nil {
+		return false, err
+	}
+
+	e.ruleSet.Store(ruleSet)
+	e.modTime = info.ModTime()
+
+	return true, nil
+}
+
+// Watch reloads the rules every interval until the context is cancelled, an invalid file is logged and the rules in
+// force are kept.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := e.Reload()
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to reload risk rules",
+					slog.String("path", e.path),
+					slog.String("error", err.Error()))
+
+				continue
+			}
+
+			if reloaded {
+				slog.InfoContext(ctx, "risk rules reloaded",
+					slog.String("path", e.path),
+					slog.Int("rules", len(e.ruleSet.Load().Rules)))
+			}
+		}
+	}
+}
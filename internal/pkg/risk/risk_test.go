@@ -0,0 +1,219 @@
+//go:build unit
+
+package risk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRules = `
+rules:
+  - name: trusted
+    when: initiated_by in ["treasury", "payroll"]
+    action: allow
+  - name: new-destination
+    when: amount > 5000 and destination.age < 24h
+    action: deny
+    message_id: errors.risk_new_destination_account
+    message: new destination account
+  - name: night
+    when: not (hour >= 6 and hour < 22) and amount >= 1000
+    action: review
+  - name: business-euro
+    when: source.type == "business" and currency != "EUR" or source.balance < -100.5
+    action: deny
+`
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules string
+	}{
+		{name: "missing name", rules: "rules:\n  - when: amount > 1\n    action: deny\n"},
+		{name: "missing condition", rules: "rules:\n  - name: a\n    action: deny\n"},
+		{name: "unknown action", rules: "rules:\n  - name: a\n    when: amount > 1\n    action: block\n"},
+		{name: "message on review", rules: "rules:\n  - name: a\n    when: amount > 1\n    action: review\n    message: no\n"},
+		{name: "duplicate name", rules: "rules:\n  - name: a\n    when: amount > 1\n    action: deny\n  - name: a\n    when: amount > 2\n    action: deny\n"},
+		{name: "unknown field", rules: "rules:\n  - name: a\n    when: size > 1\n    action: deny\n"},
+		{name: "type mismatch", rules: "rules:\n  - name: a\n    when: amount > 24h\n    action: deny\n"},
+		{name: "string ordering", rules: "rules:\n  - name: a\n    when: currency > \"EUR\"\n    action: deny\n"},
+		{name: "in mismatch", rules: "rules:\n  - name: a\n    when: currency in [\"EUR\", 1]\n    action: deny\n"},
+		{name: "in field", rules: "rules:\n  - name: a\n    when: amount in [source.balance]\n    action: deny\n"},
+		{name: "unbalanced", rules: "rules:\n  - name: a\n    when: (amount > 1\n    action: deny\n"},
+		{name: "trailing", rules: "rules:\n  - name: a\n    when: amount > 1 amount\n    action: deny\n"},
+		{name: "unterminated string", rules: "rules:\n  - name: a\n    when: currency == \"EUR\n    action: deny\n"},
+		{name: "unknown operator", rules: "rules:\n  - name: a\n    when: amount = 1\n    action: deny\n"},
+		{name: "invalid duration", rules: "rules:\n  - name: a\n    when: destination.age < 1.5d\n    action: deny\n"},
+		{name: "malformed", rules: "rules: ["},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Parse([]byte(testCase.rules))
+			assert.ErrorIs(t, err, ErrInvalidRules)
+		})
+	}
+
+	ruleSet, err := Parse([]byte(testRules))
+	assert.NoError(t, err)
+	assert.Len(t, ruleSet.Rules, 4)
+}
+
+func TestLoad(t *testing.T) {
+	ruleSet, err := Load("../../../resources/risk/risk_rules.yml")
+	assert.NoError(t, err)
+	assert.Len(t, ruleSet.Rules, 4)
+
+	_, err = Load("not-found.yml")
+	assert.Error(t, err)
+}
+
+func TestRuleSetEvaluate(t *testing.T) {
+	ruleSet, err := Parse([]byte(testRules))
+	assert.NoError(t, err)
+
+	noon := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	transfer := func(amount string, modify func(transfer *Transfer)) Transfer {
+		transfer := Transfer{
+			Amount: decimal.RequireFromString(amount),
+			Source: Account{
+				ID: 1, Type: "personal", Currency: "EUR", Balance: decimal.NewFromInt(100),
+				CreatedAt: noon.AddDate(-1, 0, 0),
+			},
+			Destination: Account{ID: 2, Type: "personal", Currency: "EUR", CreatedAt: noon.AddDate(0, -1, 0)},
+			At:          noon,
+		}
+
+		if modify != nil {
+			modify(&transfer)
+		}
+
+		return transfer
+	}
+
+	testCases := []struct {
+		name       string
+		transfer   Transfer
+		wantAction Action
+		wantRule   string
+	}{
+		{name: "no rule matches", transfer: transfer("6000", nil), wantAction: ActionAllow},
+		{
+			name: "new destination",
+			transfer: transfer("5000.01", func(transfer *Transfer) {
+				transfer.Destination.CreatedAt = noon.Add(-23 * time.Hour)
+			}),
+			wantAction: ActionDeny,
+			wantRule:   "new-destination",
+		},
+		{
+			name: "amount at bound",
+			transfer: transfer("5000", func(transfer *Transfer) {
+				transfer.Destination.CreatedAt = noon.Add(-time.Hour)
+			}),
+			wantAction: ActionAllow,
+		},
+		{
+			name: "first rule wins",
+			transfer: transfer("6000", func(transfer *Transfer) {
+				transfer.InitiatedBy = "payroll"
+				transfer.Destination.CreatedAt = noon.Add(-time.Hour)
+			}),
+			wantAction: ActionAllow,
+			wantRule:   "trusted",
+		},
+		{
+			name: "night in UTC",
+			transfer: transfer("1000", func(transfer *Transfer) {
+				transfer.At = time.Date(2026, 10, 18, 23, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+			}),
+			wantAction: ActionAllow,
+		},
+		{
+			name: "night",
+			transfer: transfer("1000", func(transfer *Transfer) {
+				transfer.At = time.Date(2026, 10, 18, 5, 59, 0, 0, time.UTC)
+			}),
+			wantAction: ActionReview,
+			wantRule:   "night",
+		},
+		{
+			name: "and binds tighter than or",
+			transfer: transfer("10", func(transfer *Transfer) {
+				transfer.Source.Balance = decimal.RequireFromString("-100.51")
+			}),
+			wantAction: ActionDeny,
+			wantRule:   "business-euro",
+		},
+		{
+			name: "string comparison",
+			transfer: transfer("10", func(transfer *Transfer) {
+				transfer.Source.Type = "business"
+				transfer.Source.Currency = "USD"
+			}),
+			wantAction: ActionDeny,
+			wantRule:   "business-euro",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := ruleSet.Evaluate(testCase.transfer)
+
+			assert.Equal(t, testCase.wantAction, got.Action)
+			assert.Equal(t, testCase.wantRule, got.Rule)
+		})
+	}
+
+	t.Run("rule message", func(t *testing.T) {
+		got := ruleSet.Evaluate(transfer("6000", func(transfer *Transfer) {
+			transfer.Destination.CreatedAt = noon
+		}))
+
+		assert.Equal(t, "errors.risk_new_destination_account", got.MessageID)
+		assert.Equal(t, "new destination account", got.Message)
+	})
+}
+
+func TestEngineReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "risk_rules.yml")
+	denyAll := "rules:\n  - name: deny-all\n    when: amount > 0\n    action: deny\n"
+	assert.NoError(t, os.WriteFile(path, []byte(denyAll), 0o600))
+
+	engine, err := NewEngine(path)
+	assert.NoError(t, err)
+
+	transfer := Transfer{Amount: decimal.NewFromInt(1)}
+	assert.Equal(t, ActionDeny, engine.Evaluate(transfer).Action)
+
+	reloaded, err := engine.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// an invalid file keeps the rules in force
+	modTime := time.Now().Add(time.Minute)
+	assert.NoError(t, os.WriteFile(path, []byte("rules:\n  - name: a\n    when: amount >\n"), 0o600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	_, err = engine.Reload()
+	assert.ErrorIs(t, err, ErrInvalidRules)
+	assert.Equal(t, ActionDeny, engine.Evaluate(transfer).Action)
+
+	modTime = modTime.Add(time.Minute)
+	assert.NoError(t, os.WriteFile(path, []byte("rules: []\n"), 0o600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	reloaded, err = engine.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, ActionAllow, engine.Evaluate(transfer).Action)
+
+	_, err = NewEngine(filepath.Join(t.TempDir(), "not-found.yml"))
+	assert.Error(t, err)
+}
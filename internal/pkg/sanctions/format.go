@@ -0,0 +1,137 @@
+package sanctions
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// csvAliasSeparator separates the aliases of an entry in the aliases column of a CSV list.
+const csvAliasSeparator = ";"
+
+// parseCSV reads a CSV list with a header row. The name column is required, the uid, aliases (separated by ";") and
+// program columns are optional and other columns are ignored.
+func parseCSV(data []byte) (*List, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, byteOrderMark)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("name column is required")
+	}
+
+	list := &List{Format: FormatCSV}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("read line %d: %w", line, err)
+		}
+
+		field := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[i])
+		}
+
+		entry := Entry{UID: field("uid"), Name: field("name"), Program: field("program")}
+		if entry.Name == "" {
+			return nil, fmt.Errorf("line %d: name is required", line)
+		}
+
+		for _, alias := range strings.Split(field("aliases"), csvAliasSeparator) {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+
+		list.Entries = append(list.Entries, entry)
+	}
+
+	return list, nil
+}
+
+// sdnList is the SDN list of OFAC, the publish date is the version of the list.
+//
+//nolint:tagliatelle // the element names of the OFAC schema
+type sdnList struct {
+	PublishDate string     `xml:"publshInformation>Publish_Date"`
+	Entries     []sdnEntry `xml:"sdnEntry"`
+}
+
+type sdnEntry struct {
+	UID       string   `xml:"uid"`
+	FirstName string   `xml:"firstName"`
+	LastName  string   `xml:"lastName"`
+	Programs  []string `xml:"programList>program"`
+	Akas      []sdnAka `xml:"akaList>aka"`
+}
+
+type sdnAka struct {
+	FirstName string `xml:"firstName"`
+	LastName  string `xml:"lastName"`
+}
+
+// parseSDN reads an SDN list in the XML format published by OFAC.
+func parseSDN(data []byte) (*List, error) {
+	var sdn sdnList
+
+	if err := xml.Unmarshal(data, &sdn); err != nil {
+		return nil, fmt.Errorf("decode sdn list: %w", err)
+	}
+
+	list := &List{
+		Version: strings.TrimSpace(sdn.PublishDate),
+		Format:  FormatSDN,
+		Entries: make([]Entry, 0, len(sdn.Entries)),
+	}
+
+	for i, sdnEntry := range sdn.Entries {
+		entry := Entry{
+			UID:     strings.TrimSpace(sdnEntry.UID),
+			Name:    sdnName(sdnEntry.FirstName, sdnEntry.LastName),
+			Program: strings.Join(slices.Compact(sdnEntry.Programs), ","),
+		}
+
+		if entry.Name == "" {
+			return nil, fmt.Errorf("entry %d: name is required", i)
+		}
+
+		for _, aka := range sdnEntry.Akas {
+			if alias := sdnName(aka.FirstName, aka.LastName); alias != "" {
+				entry.Aliases = append(entry.Aliases, alias)
+			}
+		}
+
+		list.Entries = append(list.Entries, entry)
+	}
+
+	return list, nil
+}
+
+// sdnName joins the first name and the last name, an organisation only has a last name.
+func sdnName(firstName, lastName string) string {
+	return strings.TrimSpace(strings.TrimSpace(firstName) + " " + strings.TrimSpace(lastName))
+}
@@ -0,0 +1,150 @@
+package sanctions
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize folds a name to lower case words without accents or punctuation, e.g. "Pérez-Ñúñez, S.A." becomes
+// "perez nunez s a".
+func Normalize(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		folded = name
+	}
+
+	return strings.Join(tokens(folded), " ")
+}
+
+// Similarity scores how close two names are, from 0 for unrelated names to 1 for the same name. The order of the
+// words does not matter and a name made of at least two words matches a longer name containing them, e.g.
+// "Viktor Bout" matches "BOUT, Viktor Anatolyevich", while a single word only matches a single word.
+func Similarity(a, b string) float64 {
+	return similarity(tokens(Normalize(a)), tokens(Normalize(b)))
+}
+
+// similarity scores normalized names split in words.
+func similarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	score := 0.0
+	if len(a) == len(b) {
+		score = jaroWinkler(sortedName(a), sortedName(b))
+	}
+
+	shorter, longer := a, b
+	if len(b) < len(a) {
+		shorter, longer = b, a
+	}
+
+	// a single word would match every name containing it
+	if len(shorter) < 2 {
+		return score
+	}
+
+	total := 0.0
+
+	for _, word := range shorter {
+		best := 0.0
+		for _, candidate := range longer {
+			best = max(best, jaroWinkler(word, candidate))
+		}
+
+		total += best
+	}
+
+	return max(score, total/float64(len(shorter)))
+}
+
+func tokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func sortedName(words []string) string {
+	sorted := slices.Clone(words)
+	slices.Sort(sorted)
+
+	return strings.Join(sorted, " ")
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings, the Jaro similarity raised for a common prefix of
+// up to 4 characters.
+func jaroWinkler(a, b string) float64 {
+	const (
+		prefixScale = 0.1
+		maxPrefix   = 4
+	)
+
+	s, t := []rune(a), []rune(b)
+
+	jaro := jaroSimilarity(s, t)
+
+	prefix := 0
+	for prefix < min(len(s), len(t), maxPrefix) && s[prefix] == t[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*prefixScale*(1-jaro)
+}
+
+func jaroSimilarity(s, t []rune) float64 {
+	if len(s) == 0 && len(t) == 0 {
+		return 1
+	}
+
+	if len(s) == 0 || len(t) == 0 {
+		return 0
+	}
+
+	window := max(max(len(s), len(t))/2-1, 0)
+	sMatched := make([]bool, len(s))
+	tMatched := make([]bool, len(t))
+	matches := 0
+
+	for i := range s {
+		for j := max(0, i-window); j < min(len(t), i+window+1); j++ {
+			if !tMatched[j] && s[i] == t[j] {
+				sMatched[i], tMatched[j] = true, true
+				matches++
+
+				break
+			}
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+
+	for i := range s {
+		if !sMatched[i] {
+			continue
+		}
+
+		for !tMatched[j] {
+			j++
+		}
+
+		if s[i] != t[j] {
+			transpositions++
+		}
+
+		j++
+	}
+
+	m := float64(matches)
+
+	return (m/float64(len(s)) + m/float64(len(t)) + (m-float64(transpositions)/2)/m) / 3
+}
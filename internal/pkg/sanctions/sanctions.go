@@ -0,0 +1,230 @@
+// Package sanctions screens the names of account owners against a sanctions list loaded from a local file, either
+// a CSV file or the SDN list of OFAC in its XML format.
+//
+// Names are compared after folding case, accents and punctuation, with a fuzzy similarity so that transliterations,
+// typos and a different order of the words still match, see Similarity.
+package sanctions
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatSDN Format = "sdn_xml"
+)
+
+// versionLength is the number of hex digits of the checksum used as the version of a list without a publish date.
+const versionLength = 12
+
+var ErrInvalidList = errors.New("invalid sanctions list")
+
+// byteOrderMark starts files saved as UTF-8 by some spreadsheet editors.
+var byteOrderMark = []byte("\uFEFF")
+
+// Entry is a sanctioned person or organisation, Aliases are its other known names.
+type Entry struct {
+	UID     string
+	Name    string
+	Aliases []string
+	Program string
+}
+
+// List is a loaded sanctions list. Version identifies its content: the publish date of an SDN list, or the start of
+// the checksum of the file for a CSV list.
+type List struct {
+	Version  string
+	Checksum string
+	Format   Format
+	Entries  []Entry
+
+	names []listedName
+}
+
+// listedName is a name or an alias of an entry, split in normalized words.
+type listedName struct {
+	entry int
+	name  string
+	words []string
+}
+
+// Match is a listed name similar to a screened name, Name is the name or the alias of the entry that matched.
+type Match struct {
+	Entry Entry
+	Name  string
+	Score float64
+}
+
+// Load reads a sanctions list file, an SDN XML file or a CSV file.
+func Load(path string) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read sanctions list: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse decodes a sanctions list, the format is told from the content: an XML document is an SDN list, anything
+// else a CSV file.
+func Parse(data []byte) (*List, error) {
+	var (
+		list *List
+		err  error
+	)
+
+	if bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, byteOrderMark)), []byte("<")) {
+		list, err = parseSDN(data)
+	} else {
+		list, err = parseCSV(data)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidList, err)
+	}
+
+	checksum := sha256.Sum256(data)
+	list.Checksum = hex.EncodeToString(checksum[:])
+
+	if list.Version == "" {
+		list.Version = list.Checksum[:versionLength]
+	}
+
+	list.index()
+
+	return list, nil
+}
+
+func (l *List) index() {
+	l.names = nil
+
+	for i, entry := range l.Entries {
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			words := tokens(Normalize(name))
+			if len(words) > 0 {
+				l.names = append(l.names, listedName{entry: i, name: name, words: words})
+			}
+		}
+	}
+}
+
+// Screen returns the listed name most similar to the name when its score reaches threshold.
+func (l *List) Screen(name string, threshold float64) (Match, bool) {
+	words := tokens(Normalize(name))
+	if len(words) == 0 {
+		return Match{}, false
+	}
+
+	var (
+		best  Match
+		found bool
+	)
+
+	for _, listed := range l.names {
+		score := similarity(words, listed.words)
+		if score >= threshold && score > best.Score {
+			best = Match{Entry: l.Entries[listed.entry], Name: listed.name, Score: score}
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// Screener screens names against the list of a file. The file is read again when it changes, so the list is
+// updated without a restart.
+type Screener struct {
+	path string
+	list atomic.Pointer[List]
+
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+// NewScreener loads the list of the file at path.
+func NewScreener(path string) (*Screener, error) {
+	screener := &Screener{path: path}
+
+	if _, err := screener.Reload(); err != nil {
+		return nil, err
+	}
+
+	return screener, nil
+}
+
+// List returns the list in force.
+func (s *Screener) List() *List {
+	return s.list.Load()
+}
+
+// Reload reads the file again when it was modified since it was loaded and reports whether the list was replaced.
+// The list in force is kept when the file is invalid.
+func (s *Screener) Reload() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, fmt.Errorf("stat sanctions list: %w", err)
+	}
+
+	if s.list.Load() != nil && info.ModTime().Equal(s.modTime) {
+		return false, nil
+	}
+
+	list, err := Load(s.path)
+	if err != nil {
+		return false, err
+	}
+
+	s.list.Store(list)
+	s.modTime = info.ModTime()
+
+	return true, nil
+}
+
+// Watch reloads the list every interval until the context is cancelled and calls loaded with each new list, an
+// invalid file is logged and the list in force is kept.
+func (s *Screener) Watch(ctx context.Context, interval time.Duration, loaded func(ctx context.Context, list *List)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := s.Reload()
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to reload sanctions list",
+					slog.String("path", s.path),
+					slog.String("error", err.Error()))
+
+				continue
+			}
+
+			if reloaded {
+				list := s.List()
+
+				slog.InfoContext(ctx, "sanctions list reloaded",
+					slog.String("path", s.path),
+					slog.String("version", list.Version),
+					slog.Int("entries", len(list.Entries)))
+
+				loaded(ctx, list)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+//go:build unit
+
+package sanctions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCSV = "uid,name,aliases,program\n" +
+	"1001,\"BOUT, Viktor Anatolyevich\",Victor Butt;Viktor Budd,SDGT\n" +
+	"1002,Banco Nacional de Cuba,BNC,CUBA\n"
+
+const testSDN = `<?xml version="1.0" standalone="yes"?>
+<sdnList xmlns="http://tempuri.org/sdnList.xsd">
+  <publshInformation>
+    <Publish_Date>10/17/2026</Publish_Date>
+    <Record_Count>2</Record_Count>
+  </publshInformation>
+  <sdnEntry>
+    <uid>36</uid>
+    <lastName>AEROCARIBBEAN AIRLINES</lastName>
+    <sdnType>Entity</sdnType>
+    <programList>
+      <program>CUBA</program>
+    </programList>
+    <akaList>
+      <aka>
+        <uid>12</uid>
+        <type>a.k.a.</type>
+        <category>strong</category>
+        <lastName>AERO-CARIBBEAN</lastName>
+      </aka>
+    </akaList>
+  </sdnEntry>
+  <sdnEntry>
+    <uid>2674</uid>
+    <firstName>Abu</firstName>
+    <lastName>ABBAS</lastName>
+    <sdnType>Individual</sdnType>
+    <programList>
+      <program>SDGT</program>
+      <program>SDT</program>
+    </programList>
+  </sdnEntry>
+</sdnList>`
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "perez nunez s a", Normalize("Pérez-Ñúñez, S.A."))
+	assert.Equal(t, "bout viktor", Normalize("  BOUT,\tViktor "))
+	assert.Empty(t, Normalize("-- ."))
+}
+
+func TestSimilarity(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		min  float64
+		max  float64
+	}{
+		{name: "same", a: "Viktor Bout", b: "viktor bout", min: 1, max: 1},
+		{name: "word order", a: "Viktor Bout", b: "BOUT, Viktor", min: 1, max: 1},
+		{name: "subset", a: "Viktor Bout", b: "BOUT, Viktor Anatolyevich", min: 1, max: 1},
+		{name: "accents", a: "José Pérez", b: "Jose Perez", min: 1, max: 1},
+		{name: "typo", a: "Viktor Bout", b: "Victor Bout", min: 0.9, max: 0.99},
+		{name: "unrelated", a: "Jane Doe", b: "Viktor Bout", min: 0, max: 0.6},
+		{name: "single word", a: "Bout", b: "Viktor Bout", min: 0, max: 0},
+		{name: "empty", a: "", b: "Viktor Bout", min: 0, max: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			score := Similarity(testCase.a, testCase.b)
+			assert.GreaterOrEqual(t, score, testCase.min)
+			assert.LessOrEqual(t, score, testCase.max)
+			assert.InDelta(t, score, Similarity(testCase.b, testCase.a), 1e-9)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		list, err := Parse(append(byteOrderMark, testCSV...))
+		assert.NoError(t, err)
+		assert.Equal(t, FormatCSV, list.Format)
+		assert.Len(t, list.Checksum, 64)
+		assert.Equal(t, list.Checksum[:versionLength], list.Version)
+		assert.Equal(t, []Entry{
+			{UID: "1001", Name: "BOUT, Viktor Anatolyevich", Aliases: []string{"Victor Butt", "Viktor Budd"}, Program: "SDGT"},
+			{UID: "1002", Name: "Banco Nacional de Cuba", Aliases: []string{"BNC"}, Program: "CUBA"},
+		}, list.Entries)
+	})
+
+	t.Run("sdn", func(t *testing.T) {
+		list, err := Parse([]byte(testSDN))
+		assert.NoError(t, err)
+		assert.Equal(t, FormatSDN, list.Format)
+		assert.Equal(t, "10/17/2026", list.Version)
+		assert.Equal(t, []Entry{
+			{UID: "36", Name: "AEROCARIBBEAN AIRLINES", Aliases: []string{"AERO-CARIBBEAN"}, Program: "CUBA"},
+			{UID: "2674", Name: "Abu ABBAS", Program: "SDGT,SDT"},
+		}, list.Entries)
+	})
+
+	testCases := []struct {
+		name string
+		list string
+	}{
+		{name: "empty", list: ""},
+		{name: "missing name column", list: "uid,program\n1,CUBA\n"},
+		{name: "missing name", list: "uid,name\n1,\n"},
+		{name: "unterminated quote", list: "name\n\"Viktor Bout\n"},
+		{name: "malformed xml", list: "<sdnList><sdnEntry>"},
+		{name: "sdn missing name", list: "<sdnList><sdnEntry><uid>1</uid></sdnEntry></sdnList>"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Parse([]byte(testCase.list))
+			assert.ErrorIs(t, err, ErrInvalidList)
+		})
+	}
+}
+
+func TestListScreen(t *testing.T) {
+	list, err := Parse([]byte(testCSV))
+	assert.NoError(t, err)
+
+	match, ok := list.Screen("Viktor Bout", 0.85)
+	assert.True(t, ok)
+	assert.Equal(t, "1001", match.Entry.UID)
+	assert.Equal(t, "BOUT, Viktor Anatolyevich", match.Name)
+	assert.InDelta(t, 1, match.Score, 1e-9)
+
+	match, ok = list.Screen("Victor Butt", 0.85)
+	assert.True(t, ok)
+	assert.Equal(t, "Victor Butt", match.Name)
+
+	_, ok = list.Screen("Jane Doe", 0.85)
+	assert.False(t, ok)
+
+	_, ok = list.Screen("", 0)
+	assert.False(t, ok)
+}
+
+func TestScreenerReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sanctions_list.csv")
+	assert.NoError(t, os.WriteFile(path, []byte(testCSV), 0o600))
+
+	screener, err := NewScreener(path)
+	assert.NoError(t, err)
+
+	version := screener.List().Version
+
+	reloaded, err := screener.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// an invalid file keeps the list in force
+	modTime := time.Now().Add(time.Minute)
+	assert.NoError(t, os.WriteFile(path, []byte("uid\n1\n"), 0o600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	_, err = screener.Reload()
+	assert.ErrorIs(t, err, ErrInvalidList)
+	assert.Equal(t, version, screener.List().Version)
+
+	modTime = modTime.Add(time.Minute)
+	assert.NoError(t, os.WriteFile(path, []byte(testSDN), 0o600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	reloaded, err = screener.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "10/17/2026", screener.List().Version)
+
+	_, err = NewScreener(filepath.Join(t.TempDir(), "not-found.csv"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+// Package signature verifies the signature of the requests of the API clients. A client signs the message of a
+// request, made of its method, path and query, X-Timestamp and X-Transaction-Id headers and the SHA-256 of its body,
+// either with the HMAC-SHA256 of a secret shared with the server or with its Ed25519 private key.
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Algorithm string
+
+const (
+	AlgorithmHMACSHA256 Algorithm = "hmac-sha256"
+	AlgorithmEd25519    Algorithm = "ed25519"
+)
+
+// keyParts is the number of parts of a key, "client:algorithm:key".
+const keyParts = 3
+
+var (
+	ErrInvalidKey       = errors.New("invalid signature key")
+	ErrUnknownClient    = errors.New("unknown client")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Key is the key of a client, the shared secret of an HMAC-SHA256 key or the public key of an Ed25519 key.
+type Key struct {
+	ClientID  string
+	Algorithm Algorithm
+	secret    []byte
+	publicKey ed25519.PublicKey
+}
+
+// ParseKey reads a key written as "client:algorithm:key", the key being the base64 of the secret or of the public
+// key, e.g. "mobile:hmac-sha256:c2VjcmV0".
+func ParseKey(spec string) (Key, error) {
+	parts := strings.SplitN(strings.TrimSpace(spec), ":", keyParts)
+	if len(parts) != keyParts || parts[0] == "" || parts[2] == "" {
+		return Key{}, fmt.Errorf("%w: expected client:algorithm:key", ErrInvalidKey)
+	}
+
+	material, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Key{}, fmt.Errorf("%w: client %s: %w", ErrInvalidKey, parts[0], err)
+	}
+
+	key := Key{ClientID: parts[0], Algorithm: Algorithm(strings.ToLower(parts[1]))}
+
+	switch key.Algorithm {
+	case AlgorithmHMACSHA256:
+		key.secret = material
+	case AlgorithmEd25519:
+		if len(material) != ed25519.PublicKeySize {
+			return Key{}, fmt.Errorf("%w: client %s: ed25519 public key must be %d bytes", ErrInvalidKey, key.ClientID,
+				ed25519.PublicKeySize)
+		}
+
+		key.publicKey = ed25519.PublicKey(material)
+	default:
+		return Key{}, fmt.Errorf("%w: client %s: unknown algorithm %q", ErrInvalidKey, key.ClientID, parts[1])
+	}
+
+	return key, nil
+}
+
+// Verify reports whether signature, in base64, is the signature of the message under the key.
+func (k Key) Verify(message []byte, signature string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	switch k.Algorithm {
+	case AlgorithmHMACSHA256:
+		return hmac.Equal(decoded, hmacSHA256(k.secret, message))
+	case AlgorithmEd25519:
+		return ed25519.Verify(k.publicKey, message, decoded)
+	default:
+		return false
+	}
+}
+
+// Keyring holds the key of every client.
+type Keyring struct {
+	keys map[string]Key
+}
+
+// NewKeyring parses the keys of the clients, a client has a single key.
+func NewKeyring(specs []string) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]Key, len(specs))}
+
+	for _, spec := range specs {
+		key, err := ParseKey(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := keyring.keys[key.ClientID]; ok {
+			return nil, fmt.Errorf("%w: duplicate client %s", ErrInvalidKey, key.ClientID)
+		}
+
+		keyring.keys[key.ClientID] = key
+	}
+
+	return keyring, nil
+}
+
+// Verify checks the signature of the message under the key of the client.
+func (k *Keyring) Verify(clientID string, message []byte, signature string) error {
+	key, ok := k.keys[clientID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownClient, clientID)
+	}
+
+	if !key.Verify(message, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Message returns the message signed for a request, its lines are the method, the path and query, the timestamp,
+// the transaction id and the hex SHA-256 of the body.
+func Message(method, path, timestamp, transactionID string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+
+	return []byte(strings.Join([]string{
+		strings.ToUpper(method), path, timestamp, transactionID, hex.EncodeToString(bodyHash[:]),
+	}, "\n"))
+}
+
+// SignHMAC returns the base64 HMAC-SHA256 signature of the message under the secret.
+func SignHMAC(secret []byte, message []byte) string {
+	return base64.StdEncoding.EncodeToString(hmacSHA256(secret, message))
+}
+
+// SignEd25519 returns the base64 Ed25519 signature of the message under the private key.
+func SignEd25519(privateKey ed25519.PrivateKey, message []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, message))
+}
+
+func hmacSHA256(secret []byte, message []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(message)
+
+	return mac.Sum(nil)
+}
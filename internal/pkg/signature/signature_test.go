@@ -0,0 +1,64 @@
+//go:build unit
+
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey(" mobile:HMAC-SHA256:" + base64.StdEncoding.EncodeToString([]byte("secret")))
+	assert.NoError(t, err)
+	assert.Equal(t, "mobile", key.ClientID)
+	assert.Equal(t, AlgorithmHMACSHA256, key.Algorithm)
+
+	testCases := []struct {
+		name string
+		spec string
+	}{
+		{name: "missing key", spec: "mobile:hmac-sha256"},
+		{name: "empty client", spec: ":hmac-sha256:c2VjcmV0"},
+		{name: "invalid base64", spec: "mobile:hmac-sha256:not base64"},
+		{name: "unknown algorithm", spec: "mobile:rsa:c2VjcmV0"},
+		{name: "short ed25519 key", spec: "partner:ed25519:c2VjcmV0"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ParseKey(testCase.spec)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+		})
+	}
+}
+
+func TestKeyringVerify(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	secret := []byte("secret")
+	keyring, err := NewKeyring([]string{
+		"mobile:hmac-sha256:" + base64.StdEncoding.EncodeToString(secret),
+		"partner:ed25519:" + base64.StdEncoding.EncodeToString(publicKey),
+	})
+	assert.NoError(t, err)
+
+	message := Message("post", "/transactions", "2026-10-18T10:00:00Z", "tx-1", []byte(`{"amount":"10"}`))
+	tampered := Message("POST", "/transactions", "2026-10-18T10:00:00Z", "tx-1", []byte(`{"amount":"1000"}`))
+
+	assert.NoError(t, keyring.Verify("mobile", message, SignHMAC(secret, message)))
+	assert.NoError(t, keyring.Verify("partner", message, SignEd25519(privateKey, message)))
+
+	assert.ErrorIs(t, keyring.Verify("mobile", tampered, SignHMAC(secret, message)), ErrInvalidSignature)
+	assert.ErrorIs(t, keyring.Verify("partner", tampered, SignEd25519(privateKey, message)), ErrInvalidSignature)
+	assert.ErrorIs(t, keyring.Verify("mobile", message, SignEd25519(privateKey, message)), ErrInvalidSignature)
+	assert.ErrorIs(t, keyring.Verify("mobile", message, "%%%"), ErrInvalidSignature)
+	assert.ErrorIs(t, keyring.Verify("unknown", message, SignHMAC(secret, message)), ErrUnknownClient)
+
+	_, err = NewKeyring([]string{"mobile:hmac-sha256:c2VjcmV0", "mobile:hmac-sha256:b3RoZXI="})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
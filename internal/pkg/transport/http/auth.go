@@ -0,0 +1,240 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
+)
+
+type Scope string
+
+const (
+	ScopeAccountsRead   Scope = "accounts:read"
+	ScopeAccountsWrite  Scope = "accounts:write"
+	ScopeTransfersRead  Scope = "transfers:read"
+	ScopeTransfersWrite Scope = "transfers:write"
+	// ScopeAdmin grants every scope.
+	ScopeAdmin Scope = "admin"
+)
+
+const (
+	bearerPrefix = "Bearer "
+	// serviceTokenParts is the number of parts of a service token without and with an expiry,
+	// "client|token|scopes" or "client|token|scopes|expires_at".
+	serviceTokenParts           = 3
+	serviceTokenPartsWithExpiry = 4
+)
+
+var ErrInvalidServiceToken = errors.New("invalid service token")
+
+var errInsufficientScope = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.insufficient_scope",
+		Message:   "client is not allowed to access this resource",
+	},
+	StatusCode: http.StatusForbidden,
+}
+
+// Principal is the client authenticated by a service token and the scopes granted to it.
+type Principal struct {
+	ClientID string
+	Scopes   []Scope
+}
+
+// HasScope reports whether the client is granted the scope, directly or through the admin scope.
+func (p Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal stores the authenticated client in ctx.
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the client authenticated by AuthMiddleware.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+
+	return principal, ok
+}
+
+// serviceToken is a token of a client, only its hash is kept. A token with an expiry is accepted until then, so that
+// a rotated token keeps working while the clients move to its replacement.
+type serviceToken struct {
+	principal Principal
+	hash      [sha256.Size]byte
+	expiresAt time.Time
+}
+
+// ServiceTokens authenticates the bearer tokens of the clients of the API.
+type ServiceTokens struct {
+	tokens []serviceToken
+}
+
+// ParseServiceTokens reads the service tokens, each written as "client|token|scopes|expires_at" with the scopes
+// separated by spaces and an optional RFC 3339 expiry, e.g. "payroll|s3cr3t|accounts:read transfers:write". A client
+// may have several tokens, e.g. the old and the new token while a token is rotated.
+func ParseServiceTokens(specs []string) (*ServiceTokens, error) {
+	serviceTokens := &ServiceTokens{}
+
+	for _, spec := range specs {
+		token, err := parseServiceToken(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, existing := range serviceTokens.tokens {
+			if existing.hash == token.hash {
+				return nil, fmt.Errorf("%w: client %s: duplicate token", ErrInvalidServiceToken,
+					token.principal.ClientID)
+			}
+		}
+
+		serviceTokens.tokens = append(serviceTokens.tokens, token)
+	}
+
+	return serviceTokens, nil
+}
+
+func parseServiceToken(spec string) (serviceToken, error) {
+	parts := strings.Split(strings.TrimSpace(spec), "|")
+	if len(parts) != serviceTokenParts && len(parts) != serviceTokenPartsWithExpiry {
+		return serviceToken{}, fmt.Errorf("%w: expected client|token|scopes|expires_at", ErrInvalidServiceToken)
+	}
+
+	clientID, token := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if clientID == "" || token == "" {
+		return serviceToken{}, fmt.Errorf("%w: client and token are required", ErrInvalidServiceToken)
+	}
+
+	scopes := make([]Scope, 0)
+	for _, scope := range strings.Fields(parts[2]) {
+		scopes = append(scopes, Scope(scope))
+	}
+
+	if len(scopes) == 0 {
+		return serviceToken{}, fmt.Errorf("%w: client %s: scopes are required", ErrInvalidServiceToken, clientID)
+	}
+
+	parsed := serviceToken{
+		principal: Principal{ClientID: clientID, Scopes: scopes},
+		hash:      sha256.Sum256([]byte(token)),
+	}
+
+	if len(parts) == serviceTokenPartsWithExpiry && strings.TrimSpace(parts[3]) != "" {
+		expiresAt, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[3]))
+		if err != nil {
+			return serviceToken{}, fmt.Errorf("%w: client %s: %w", ErrInvalidServiceToken, clientID, err)
+		}
+
+		parsed.expiresAt = expiresAt
+	}
+
+	return parsed, nil
+}
+
+// Authenticate returns the client of a token, an unknown or expired token is refused.
+func (s *ServiceTokens) Authenticate(token string, now time.Time) (Principal, error) {
+	hash := sha256.Sum256([]byte(token))
+
+	for _, serviceToken := range s.tokens {
+		if subtle.ConstantTimeCompare(hash[:], serviceToken.hash[:]) != 1 {
+			continue
+		}
+
+		if !serviceToken.expiresAt.IsZero() && !now.Before(serviceToken.expiresAt) {
+			return Principal{}, fmt.Errorf("%w: token of client %s expired", ErrInvalidServiceToken,
+				serviceToken.principal.ClientID)
+		}
+
+		return serviceToken.principal, nil
+	}
+
+	return Principal{}, fmt.Errorf("%w: unknown token", ErrInvalidServiceToken)
+}
+
+// Authenticator returns the client of a bearer token.
+type Authenticator interface {
+	Authenticate(token string, now time.Time) (Principal, error)
+}
+
+// AuthMiddleware rejects a request without a valid bearer token in the Authorization header and stores the client
+// of the token in the request context for RequireScope. The client of the token is the actor of the request, a
+// request also signed by SignatureMiddleware is rejected when the signature is of another client.
+func AuthMiddleware(authenticator Authenticator) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+
+			authorization := req.Header.Get("Authorization")
+			if !strings.HasPrefix(authorization, bearerPrefix) {
+				respWriter.Header().Set("WWW-Authenticate", "Bearer")
+				ErrorResponse(ctx, exception.ErrUnauthorized, respWriter)
+
+				return
+			}
+
+			principal, err := authenticator.Authenticate(strings.TrimPrefix(authorization, bearerPrefix), time.Now())
+			if err != nil {
+				slog.WarnContext(ctx, "service token rejected",
+					slog.String("url", req.URL.String()),
+					slog.String("error", err.Error()))
+
+				respWriter.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				ErrorResponse(ctx, exception.ErrUnauthorized, respWriter)
+
+				return
+			}
+
+			if signedBy := dto.ClientIDFromContext(ctx); signedBy != "" && signedBy != principal.ClientID {
+				slog.WarnContext(ctx, "service token and signature of different clients",
+					slog.String("client_id", principal.ClientID),
+					slog.String("signature_client_id", signedBy),
+					slog.String("url", req.URL.String()))
+
+				ErrorResponse(ctx, exception.ErrUnauthorized, respWriter)
+
+				return
+			}
+
+			ctx = dto.ContextWithClientID(ContextWithPrincipal(ctx, principal), principal.ClientID)
+
+			next.ServeHTTP(respWriter, req.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope rejects a request whose client is not granted the scope, it must run after AuthMiddleware.
+func RequireScope(scope Scope) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+			principal, ok := PrincipalFromContext(req.Context())
+			if !ok {
+				ErrorResponse(req.Context(), exception.ErrUnauthorized, respWriter)
+
+				return
+			}
+
+			if !principal.HasScope(scope) {
+				ErrorResponse(req.Context(), errInsufficientScope, respWriter)
+
+				return
+			}
+
+			next.ServeHTTP(respWriter, req)
+		})
+	}
+}
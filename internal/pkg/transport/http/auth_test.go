@@ -0,0 +1,197 @@
+//go:build unit
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServiceTokens(t *testing.T) {
+	serviceTokens, err := ParseServiceTokens([]string{
+		" payroll|new-token|accounts:read transfers:write",
+		"payroll|old-token|accounts:read transfers:write|2026-10-18T12:00:00Z",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, serviceTokens.tokens, 2)
+
+	testCases := []struct {
+		name  string
+		specs []string
+	}{
+		{name: "missing scopes", specs: []string{"payroll|token"}},
+		{name: "empty client", specs: []string{"|token|admin"}},
+		{name: "empty token", specs: []string{"payroll| |admin"}},
+		{name: "empty scopes", specs: []string{"payroll|token| "}},
+		{name: "invalid expiry", specs: []string{"payroll|token|admin|tomorrow"}},
+		{name: "duplicate token", specs: []string{"payroll|token|admin", "billing|token|accounts:read"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ParseServiceTokens(testCase.specs)
+			assert.ErrorIs(t, err, ErrInvalidServiceToken)
+		})
+	}
+}
+
+func TestServiceTokensAuthenticate(t *testing.T) {
+	serviceTokens, err := ParseServiceTokens([]string{
+		"payroll|new-token|accounts:read transfers:write",
+		"payroll|old-token|accounts:read transfers:write|2026-10-18T12:00:00Z",
+	})
+	assert.NoError(t, err)
+
+	overlap := time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC)
+	expired := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	principal, err := serviceTokens.Authenticate("new-token", expired)
+	assert.NoError(t, err)
+	assert.Equal(t, "payroll", principal.ClientID)
+	assert.Equal(t, []Scope{ScopeAccountsRead, ScopeTransfersWrite}, principal.Scopes)
+
+	principal, err = serviceTokens.Authenticate("old-token", overlap)
+	assert.NoError(t, err)
+	assert.Equal(t, "payroll", principal.ClientID)
+
+	_, err = serviceTokens.Authenticate("old-token", expired)
+	assert.ErrorIs(t, err, ErrInvalidServiceToken)
+
+	_, err = serviceTokens.Authenticate("unknown-token", overlap)
+	assert.ErrorIs(t, err, ErrInvalidServiceToken)
+}
+
+func TestPrincipalHasScope(t *testing.T) {
+	principal := Principal{ClientID: "payroll", Scopes: []Scope{ScopeAccountsRead}}
+	assert.True(t, principal.HasScope(ScopeAccountsRead))
+	assert.False(t, principal.HasScope(ScopeAccountsWrite))
+
+	admin := Principal{ClientID: "backoffice", Scopes: []Scope{ScopeAdmin}}
+	assert.True(t, admin.HasScope(ScopeTransfersWrite))
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	serviceTokens, err := ParseServiceTokens([]string{
+		"payroll|payroll-token|accounts:read",
+		"backoffice|backoffice-token|admin",
+		"billing|billing-token|accounts:write|2020-01-01T00:00:00Z",
+	})
+	assert.NoError(t, err)
+
+	var clientID, actorID string
+
+	handler := AuthMiddleware(serviceTokens)(RequireScope(ScopeAccountsRead)(http.HandlerFunc(
+		func(respWriter http.ResponseWriter, req *http.Request) {
+			principal, _ := PrincipalFromContext(req.Context())
+			clientID = principal.ClientID
+			actorID = dto.ClientIDFromContext(req.Context())
+			respWriter.WriteHeader(http.StatusNoContent)
+		})))
+
+	testCases := []struct {
+		name               string
+		authorization      string
+		expectedStatusCode int
+		expectedClientID   string
+	}{
+		{
+			name:               "success",
+			authorization:      "Bearer payroll-token",
+			expectedStatusCode: http.StatusNoContent,
+			expectedClientID:   "payroll",
+		},
+		{
+			name:               "admin",
+			authorization:      "Bearer backoffice-token",
+			expectedStatusCode: http.StatusNoContent,
+			expectedClientID:   "backoffice",
+		},
+		{
+			name:               "missing_token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "not_bearer",
+			authorization:      "Basic payroll-token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "unknown_token",
+			authorization:      "Bearer other-token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "expired_token",
+			authorization:      "Bearer billing-token",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			clientID, actorID = "", ""
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/accounts", nil)
+			if testCase.authorization != "" {
+				req.Header.Set("Authorization", testCase.authorization)
+			}
+
+			respRecorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(respRecorder, req)
+
+			assert.Equal(t, testCase.expectedStatusCode, respRecorder.Code)
+			assert.Equal(t, testCase.expectedClientID, clientID)
+			assert.Equal(t, testCase.expectedClientID, actorID)
+
+			if testCase.expectedStatusCode == http.StatusUnauthorized {
+				assert.Contains(t, respRecorder.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+
+	t.Run("signed_by_same_client", func(t *testing.T) {
+		clientID, actorID = "", ""
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/accounts", nil)
+		req = req.WithContext(dto.ContextWithClientID(req.Context(), "payroll"))
+		req.Header.Set("Authorization", "Bearer payroll-token")
+		respRecorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(respRecorder, req)
+
+		assert.Equal(t, http.StatusNoContent, respRecorder.Code)
+		assert.Equal(t, "payroll", actorID)
+	})
+
+	t.Run("signed_by_another_client", func(t *testing.T) {
+		clientID, actorID = "", ""
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/accounts", nil)
+		req = req.WithContext(dto.ContextWithClientID(req.Context(), "mobile"))
+		req.Header.Set("Authorization", "Bearer payroll-token")
+		respRecorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(respRecorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, respRecorder.Code)
+		assert.Empty(t, clientID)
+	})
+
+	t.Run("insufficient_scope", func(t *testing.T) {
+		handler := AuthMiddleware(serviceTokens)(RequireScope(ScopeTransfersWrite)(http.HandlerFunc(
+			func(respWriter http.ResponseWriter, _ *http.Request) {
+				respWriter.WriteHeader(http.StatusNoContent)
+			})))
+
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/transactions", nil)
+		req.Header.Set("Authorization", "Bearer payroll-token")
+		respRecorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(respRecorder, req)
+
+		assert.Equal(t, http.StatusForbidden, respRecorder.Code)
+	})
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 
 	"github.com/go-chi/render"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
 )
 
 func init() { //nolint:gochecknoinits
@@ -31,6 +33,8 @@ func Decoder(req *http.Request, val interface{}) error {
 	return err
 }
 
+// DecodeRequest binds the request to a new T. A request that cannot be decoded or fails the validation of its binder
+// is a client error answered with 400 Bad Request, unless the binder returns an ApplicationError with its own status.
 func DecodeRequest[T any, PT interface {
 	render.Binder
 	*T
@@ -39,6 +43,19 @@ func DecodeRequest[T any, PT interface {
 
 	err := render.Bind(req, binder)
 	if err != nil {
+		var appErr exception.ApplicationError
+		if !errors.As(err, &appErr) {
+			// a malformed or invalid request is a client error
+			err = exception.ApplicationError{
+				Localizable: lang.Localizable{
+					MessageID: "errors.request_validation",
+					Message:   "invalid request",
+				},
+				StatusCode: exception.CodeBadRequest,
+				Cause:      err,
+			}
+		}
+
 		return nil, fmt.Errorf("http bind request: %w", err)
 	}
 
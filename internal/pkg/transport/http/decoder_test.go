@@ -4,10 +4,12 @@ package http
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"testing"
 
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,6 +52,52 @@ func TestBindInvalidRequest(t *testing.T) {
 
 	_, err = DecodeRequest[dummyRequest](ctx, request)
 	assert.Contains(t, err.Error(), "http bind request")
+	assert.Equal(t, http.StatusBadRequest, exception.GetHTTPStatusCodeByErr(err))
+}
+
+type validatedRequest struct {
+	Foo string `json:"foo"`
+}
+
+func (v *validatedRequest) Bind(_ *http.Request) error {
+	if v.Foo == "" {
+		return errors.New("foo is required")
+	}
+
+	if v.Foo == "missing" {
+		return exception.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func TestBindValidationFailure(t *testing.T) {
+	ctx := context.Background()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/dummy/url", strings.NewReader(`{"foo": ""}`))
+	assert.Nil(t, err)
+
+	request.Header.Add("Content-Type", "application/json")
+
+	_, err = DecodeRequest[validatedRequest](ctx, request)
+
+	var appErr exception.ApplicationError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, "errors.request_validation", appErr.MessageID)
+	assert.Equal(t, http.StatusBadRequest, exception.GetHTTPStatusCodeByErr(err))
+}
+
+func TestBindApplicationError(t *testing.T) {
+	ctx := context.Background()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/dummy/url", strings.NewReader(`{"foo": "missing"}`))
+	assert.Nil(t, err)
+
+	request.Header.Add("Content-Type", "application/json")
+
+	_, err = DecodeRequest[validatedRequest](ctx, request)
+	assert.ErrorIs(t, err, exception.ErrRecordNotFound)
+	assert.Equal(t, http.StatusNotFound, exception.GetHTTPStatusCodeByErr(err))
 }
 
 func TestBindEmptyRequestBody(t *testing.T) {
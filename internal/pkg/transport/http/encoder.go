@@ -2,16 +2,31 @@ package http
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/ijalalfrz/go-event-source/internal/app/dto"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
 )
 
+const contentTypeCSV = "text/csv"
+
+// CSVEncoder is implemented by the responses that have a CSV representation.
+type CSVEncoder interface {
+	CSVRecords() [][]string
+}
+
+// XMLEncoder is implemented by the responses that are an XML document.
+type XMLEncoder interface {
+	XMLDocument() []byte
+}
+
 // ResponseWithBody is the common method to encode all response types to the
 // client. I chose to do it this way because, since we're using JSON, there's no
 // reason to provide anything more specific. It's certainly possible to
@@ -26,6 +41,25 @@ func ResponseWithBody(_ context.Context, w http.ResponseWriter, response interfa
 	return nil
 }
 
+// NegotiatedResponse encodes the response as CSV when the client accepts text/csv and the response has a CSV
+// representation, otherwise as JSON.
+func NegotiatedResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	accept, _ := ctx.Value(kithttp.ContextKeyRequestAccept).(string)
+
+	csvResponse, ok := response.(CSVEncoder)
+	if !ok || !strings.Contains(accept, contentTypeCSV) {
+		return ResponseWithBody(ctx, w, response)
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+	if err := csv.NewWriter(w).WriteAll(csvResponse.CSVRecords()); err != nil {
+		return fmt.Errorf("encode csv response body: %w", err)
+	}
+
+	return nil
+}
+
 func NoContentResponse(_ context.Context, w http.ResponseWriter, _ interface{}) error {
 	w.WriteHeader(http.StatusNoContent)
 
@@ -38,6 +72,39 @@ func CreatedResponse(_ context.Context, w http.ResponseWriter, _ interface{}) er
 	return nil
 }
 
+func CreatedResponseWithBody(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+
+	return ResponseWithBody(ctx, w, response)
+}
+
+// XMLResponse writes the XML document of the response.
+func XMLResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	return writeXML(w, http.StatusOK, response)
+}
+
+// CreatedXMLResponse writes the XML document of the response with the status created.
+func CreatedXMLResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	return writeXML(w, http.StatusCreated, response)
+}
+
+func writeXML(w http.ResponseWriter, statusCode int, response interface{}) error {
+	xmlResponse, ok := response.(XMLEncoder)
+	if !ok {
+		return fmt.Errorf("encode xml response body: %T is not an XML document", response)
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(xmlResponse.XMLDocument()); err != nil {
+		return fmt.Errorf("encode xml response body: %w", err)
+	}
+
+	return nil
+}
+
 func ErrorResponse(ctx context.Context, err error, respWriter http.ResponseWriter) {
 	var (
 		appErr  exception.ApplicationError
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
 	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
 	"github.com/stretchr/testify/assert"
@@ -60,3 +61,96 @@ func TestCreatedResponse(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusCreated, resp.Code)
 }
+
+func TestCreatedResponseWithBody(t *testing.T) {
+	resp := httptest.NewRecorder()
+	err := CreatedResponseWithBody(context.Background(), resp, map[string]string{"foo": "bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Result().Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"foo": "bar"}`, resp.Body.String())
+}
+
+type csvResponse struct {
+	Foo string `json:"foo"`
+}
+
+func (r csvResponse) CSVRecords() [][]string {
+	return [][]string{{"foo"}, {r.Foo}}
+}
+
+func TestNegotiatedResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		response    interface{}
+		contentType string
+		body        string
+	}{
+		{
+			name:        "csv",
+			accept:      "text/csv",
+			response:    csvResponse{Foo: "bar"},
+			contentType: "text/csv; charset=utf-8",
+			body:        "foo\nbar\n",
+		},
+		{
+			name:        "json_by_default",
+			response:    csvResponse{Foo: "bar"},
+			contentType: "application/json; charset=utf-8",
+			body:        "{\"foo\":\"bar\"}\n",
+		},
+		{
+			name:        "json_without_csv_representation",
+			accept:      "text/csv",
+			response:    map[string]string{"foo": "bar"},
+			contentType: "application/json; charset=utf-8",
+			body:        "{\"foo\":\"bar\"}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), kithttp.ContextKeyRequestAccept, tt.accept)
+			resp := httptest.NewRecorder()
+
+			err := NegotiatedResponse(ctx, resp, tt.response)
+
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tt.contentType, resp.Result().Header.Get("Content-Type"))
+			assert.Equal(t, tt.body, resp.Body.String())
+		})
+	}
+}
+
+type xmlResponse struct {
+	Document string
+}
+
+func (r xmlResponse) XMLDocument() []byte {
+	return []byte(r.Document)
+}
+
+func TestXMLResponse(t *testing.T) {
+	resp := httptest.NewRecorder()
+	err := XMLResponse(context.Background(), resp, xmlResponse{Document: "<foo>bar</foo>"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", resp.Result().Header.Get("Content-Type"))
+	assert.Equal(t, "<foo>bar</foo>", resp.Body.String())
+}
+
+func TestCreatedXMLResponse(t *testing.T) {
+	resp := httptest.NewRecorder()
+	err := CreatedXMLResponse(context.Background(), resp, xmlResponse{Document: "<foo>bar</foo>"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", resp.Result().Header.Get("Content-Type"))
+	assert.Equal(t, "<foo>bar</foo>", resp.Body.String())
+
+	err = CreatedXMLResponse(context.Background(), httptest.NewRecorder(), map[string]string{"foo": "bar"})
+	assert.Error(t, err)
+}
@@ -9,6 +9,8 @@ import (
 
 var options = []kithttp.ServerOption{
 	kithttp.ServerErrorEncoder(ErrorResponse),
+	// makes the request headers, e.g. Accept, available to the response encoders
+	kithttp.ServerBefore(kithttp.PopulateRequestContext),
 }
 
 // creates a generic http.Handler with the a given endpoint and a decode function.
@@ -2,8 +2,12 @@ package http
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -12,6 +16,10 @@ import (
 
 	"github.com/go-chi/cors"
 	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/lang"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/signature"
 )
 
 type MiddlewareFunc func(http.Handler) http.Handler
@@ -122,7 +130,10 @@ func CORSMiddleware(allowedOrigins []string) func(next http.Handler) http.Handle
 	return cors.Handler(cors.Options{
 		AllowedOrigins: allowedOrigins, // allow swagger
 		AllowedMethods: []string{"GET", "POST", "PATCH", "PUT", "OPTIONS", "DELETE"},
-		AllowedHeaders: []string{"Authorization", "Origin", "Content-Type", "X-Timestamp", "X-Transaction-Id"},
+		AllowedHeaders: []string{
+			"Authorization", "Origin", "Content-Type", "X-Timestamp", "X-Transaction-Id",
+			"X-Client-Id", "X-Signature",
+		},
 	})
 }
 
@@ -140,3 +151,221 @@ func HeaderMiddleware() func(next http.Handler) http.Handler {
 		})
 	}
 }
+
+// SignatureVerifier checks the signature of a request message under the key of the client.
+type SignatureVerifier interface {
+	Verify(clientID string, message []byte, signature string) error
+}
+
+// SignatureMiddleware rejects a request that is not signed by a known client, the X-Client-Id header names the client
+// and X-Signature carries the signature of the message of the request, see signature.Message. A request whose
+// X-Timestamp is more than tolerance away from now is rejected as well, so that a signed request cannot be replayed
+// later. The client of a verified signature is stored in the request context as the actor of the request.
+func SignatureMiddleware(verifier SignatureVerifier, tolerance time.Duration) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+
+			if err := verifySignature(req, verifier, tolerance, time.Now()); err != nil {
+				slog.WarnContext(ctx, "request signature rejected",
+					slog.String("client_id", req.Header.Get("X-Client-Id")),
+					slog.String("url", req.URL.String()),
+					slog.String("error", err.Error()))
+
+				ErrorResponse(ctx, exception.ErrUnauthorized, respWriter)
+
+				return
+			}
+
+			next.ServeHTTP(respWriter, req.WithContext(dto.ContextWithClientID(ctx, req.Header.Get("X-Client-Id"))))
+		})
+	}
+}
+
+// verifySignature checks the signature and the timestamp of the request, the body is restored for the next handler.
+func verifySignature(req *http.Request, verifier SignatureVerifier, tolerance time.Duration, now time.Time) error {
+	timestamp := req.Header.Get("X-Timestamp")
+
+	signedAt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to parse timestamp: %w", err)
+	}
+
+	if signedAt.Before(now.Add(-tolerance)) || signedAt.After(now.Add(tolerance)) {
+		return fmt.Errorf("timestamp %s is outside of the tolerance", timestamp)
+	}
+
+	var body []byte
+
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	message := signature.Message(req.Method, req.URL.RequestURI(), timestamp, req.Header.Get("X-Transaction-Id"),
+		body)
+
+	if err := verifier.Verify(req.Header.Get("X-Client-Id"), message, req.Header.Get("X-Signature")); err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	return nil
+}
+
+// IdempotencyStore keeps the response of the first request made with a transaction id, the transaction id itself is
+// claimed by the operation using it.
+type IdempotencyStore interface {
+	FindByTransactionID(ctx context.Context, transactionID string) (model.IdempotencyKey, error)
+	Create(ctx context.Context, idempotencyKey *model.IdempotencyKey) error
+}
+
+var errTransactionIDReused = exception.ApplicationError{
+	Localizable: lang.Localizable{
+		MessageID: "errors.idempotency",
+		Message:   "transaction id already used by another operation",
+	},
+	StatusCode: http.StatusConflict,
+}
+
+// recordingResponseWriter captures the status code and the whole body of a response.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	body       bytes.Buffer
+	statusCode int
+}
+
+func (r *recordingResponseWriter) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b) //nolint:wrapcheck
+}
+
+func (r *recordingResponseWriter) WriteHeader(statusCode int) {
+	r.ResponseWriter.WriteHeader(statusCode)
+	r.statusCode = statusCode
+}
+
+// IdempotencyMiddleware answers a retry of a transaction id with the response of the first request, it must run
+// after HeaderMiddleware and the authentication middlewares. A request reusing the transaction id with another
+// method, path or body, or from another client, is a conflict.
+func IdempotencyMiddleware(store IdempotencyStore) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+			ctx := req.Context()
+
+			reqContext, ok := dto.RequestFromContext(ctx)
+			if !ok {
+				next.ServeHTTP(respWriter, req)
+
+				return
+			}
+
+			fingerprint, err := requestFingerprint(req)
+			if err != nil {
+				ErrorResponse(ctx, err, respWriter)
+
+				return
+			}
+
+			// a transaction id claimed without a response is answered by the operation, its claim conflicts
+			idempotencyKey, err := store.FindByTransactionID(ctx, reqContext.TransactionID)
+			if err == nil && idempotencyKey.HasResponse() && idempotencyKey.Fingerprint == fingerprint {
+				replayResponse(respWriter, idempotencyKey)
+
+				return
+			}
+
+			if err == nil && idempotencyKey.HasResponse() {
+				ErrorResponse(ctx, errTransactionIDReused, respWriter)
+
+				return
+			}
+
+			if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
+				ErrorResponse(ctx, fmt.Errorf("failed to find idempotency key: %w", err), respWriter)
+
+				return
+			}
+
+			recordingRespWriter := &recordingResponseWriter{ResponseWriter: respWriter}
+
+			next.ServeHTTP(recordingRespWriter, req)
+
+			if recordingRespWriter.statusCode == 0 {
+				recordingRespWriter.statusCode = http.StatusOK
+			}
+
+			if !isReplayable(recordingRespWriter.statusCode) {
+				return
+			}
+
+			err = store.Create(ctx, &model.IdempotencyKey{
+				TransactionID: reqContext.TransactionID,
+				Fingerprint:   fingerprint,
+				StatusCode:    recordingRespWriter.statusCode,
+				ContentType:   recordingRespWriter.Header().Get("Content-Type"),
+				ResponseBody:  recordingRespWriter.body.Bytes(),
+				CreatedAt:     time.Now(),
+			})
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to store idempotency key",
+					slog.String("transaction_id", reqContext.TransactionID),
+					slog.String("error", err.Error()))
+			}
+		})
+	}
+}
+
+// requestFingerprint hashes the method, the path, the authenticated client and the body of the request, the body is
+// restored for the next handler. The response of a client is never replayed to another client reusing its
+// transaction id.
+func requestFingerprint(req *http.Request) (string, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + "\n" + req.URL.RequestURI() + "\n"))
+
+	if clientID := dto.ClientIDFromContext(req.Context()); clientID != "" {
+		hash.Write([]byte("client " + clientID + "\n"))
+	}
+
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// isReplayable reports whether a response is the final result of the request. Only a success is stored: a client
+// error may depend on the state at the time of the request, e.g. an insufficient balance or a stale request time,
+// and a server error may succeed on retry, so the retry is processed again.
+func isReplayable(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
+
+func replayResponse(respWriter http.ResponseWriter, idempotencyKey model.IdempotencyKey) {
+	if idempotencyKey.ContentType != "" {
+		respWriter.Header().Set("Content-Type", idempotencyKey.ContentType)
+	}
+
+	respWriter.Header().Set("Idempotent-Replayed", "true")
+	respWriter.WriteHeader(idempotencyKey.StatusCode)
+	respWriter.Write(idempotencyKey.ResponseBody) //nolint:errcheck
+}
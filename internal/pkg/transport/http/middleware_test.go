@@ -5,13 +5,20 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ijalalfrz/go-event-source/internal/app/dto"
+	"github.com/ijalalfrz/go-event-source/internal/app/model"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/exception"
+	"github.com/ijalalfrz/go-event-source/internal/pkg/signature"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -144,6 +151,329 @@ func TestPanicRecovererMiddleware(t *testing.T) {
 	})
 }
 
+type idempotencyStoreMock struct {
+	idempotencyKeys map[string]model.IdempotencyKey
+}
+
+func (m *idempotencyStoreMock) FindByTransactionID(_ context.Context, transactionID string) (model.IdempotencyKey, error) {
+	idempotencyKey, ok := m.idempotencyKeys[transactionID]
+	if !ok {
+		return model.IdempotencyKey{}, exception.ErrRecordNotFound
+	}
+
+	return idempotencyKey, nil
+}
+
+func (m *idempotencyStoreMock) Create(_ context.Context, idempotencyKey *model.IdempotencyKey) error {
+	if m.idempotencyKeys[idempotencyKey.TransactionID].HasResponse() {
+		return nil
+	}
+
+	m.idempotencyKeys[idempotencyKey.TransactionID] = *idempotencyKey
+
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	newRequest := func(transactionID string, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/transactions", strings.NewReader(body))
+
+		return req.WithContext(dto.ContextWithRequestContext(req.Context(), dto.RequestContext{
+			TransactionID: transactionID,
+			Timestamp:     time.Now(),
+		}))
+	}
+
+	newHandler := func(statusCode int, calls *int) http.Handler {
+		return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+			*calls++
+			respWriter.Header().Set("Content-Type", "application/json")
+			respWriter.WriteHeader(statusCode)
+			respWriter.Write([]byte(`{"transaction_id":"tx-1"}`))
+		})
+	}
+
+	t.Run("replay_identical_retry", func(t *testing.T) {
+		calls := 0
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+		handler := IdempotencyMiddleware(store)(newHandler(http.StatusOK, &calls))
+
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, newRequest("tx-1", `{"amount":100}`))
+
+		retry := httptest.NewRecorder()
+		handler.ServeHTTP(retry, newRequest("tx-1", `{"amount":100}`))
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+		assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("conflict_different_payload", func(t *testing.T) {
+		calls := 0
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+		handler := IdempotencyMiddleware(store)(newHandler(http.StatusOK, &calls))
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("tx-1", `{"amount":100}`))
+
+		retry := httptest.NewRecorder()
+		handler.ServeHTTP(retry, newRequest("tx-1", `{"amount":200}`))
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusConflict, retry.Code)
+	})
+
+	t.Run("conflict_other_client", func(t *testing.T) {
+		calls := 0
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+		handler := IdempotencyMiddleware(store)(newHandler(http.StatusOK, &calls))
+
+		first := newRequest("tx-1", `{"amount":100}`)
+		handler.ServeHTTP(httptest.NewRecorder(), first.WithContext(dto.ContextWithClientID(first.Context(), "payroll")))
+
+		retry := newRequest("tx-1", `{"amount":100}`)
+		respRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(respRecorder, retry.WithContext(dto.ContextWithClientID(retry.Context(), "billing")))
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusConflict, respRecorder.Code)
+		assert.Empty(t, respRecorder.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("not_stored_server_error", func(t *testing.T) {
+		calls := 0
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+		handler := IdempotencyMiddleware(store)(newHandler(http.StatusInternalServerError, &calls))
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("tx-1", `{"amount":100}`))
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("tx-1", `{"amount":100}`))
+
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, store.idempotencyKeys)
+	})
+
+	t.Run("not_stored_conflict", func(t *testing.T) {
+		calls := 0
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+		handler := IdempotencyMiddleware(store)(newHandler(http.StatusConflict, &calls))
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("tx-1", `{"amount":100}`))
+
+		assert.Empty(t, store.idempotencyKeys)
+	})
+
+	t.Run("retry_after_stale_timestamp", func(t *testing.T) {
+		calls := 0
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+		handler := IdempotencyMiddleware(store)(http.HandlerFunc(
+			func(respWriter http.ResponseWriter, req *http.Request) {
+				calls++
+				reqContext, _ := dto.RequestFromContext(req.Context())
+
+				if time.Since(reqContext.Timestamp) > time.Minute {
+					respWriter.WriteHeader(http.StatusBadRequest)
+
+					return
+				}
+
+				respWriter.WriteHeader(http.StatusCreated)
+			}))
+
+		stale := newRequest("tx-1", `{"amount":100}`)
+		stale = stale.WithContext(dto.ContextWithRequestContext(stale.Context(), dto.RequestContext{
+			TransactionID: "tx-1",
+			Timestamp:     time.Now().Add(-time.Hour),
+		}))
+
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, stale)
+
+		retry := httptest.NewRecorder()
+		handler.ServeHTTP(retry, newRequest("tx-1", `{"amount":100}`))
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusBadRequest, first.Code)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Empty(t, retry.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, http.StatusCreated, store.idempotencyKeys["tx-1"].StatusCode)
+	})
+
+	t.Run("retry_after_top_up", func(t *testing.T) {
+		calls := 0
+		balance := 50
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+		handler := IdempotencyMiddleware(store)(http.HandlerFunc(
+			func(respWriter http.ResponseWriter, _ *http.Request) {
+				calls++
+
+				if balance < 100 {
+					respWriter.WriteHeader(http.StatusBadRequest)
+
+					return
+				}
+
+				respWriter.WriteHeader(http.StatusCreated)
+			}))
+
+		first := httptest.NewRecorder()
+		handler.ServeHTTP(first, newRequest("tx-1", `{"amount":100}`))
+
+		balance += 100
+
+		retry := httptest.NewRecorder()
+		handler.ServeHTTP(retry, newRequest("tx-1", `{"amount":100}`))
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusBadRequest, first.Code)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Empty(t, retry.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("not_stored_state_dependent_client_error", func(t *testing.T) {
+		for _, statusCode := range []int{
+			http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusUnprocessableEntity, http.StatusTooManyRequests,
+		} {
+			calls := 0
+			store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+			handler := IdempotencyMiddleware(store)(newHandler(statusCode, &calls))
+
+			handler.ServeHTTP(httptest.NewRecorder(), newRequest("tx-1", `{"amount":100}`))
+
+			retry := httptest.NewRecorder()
+			handler.ServeHTTP(retry, newRequest("tx-1", `{"amount":100}`))
+
+			assert.Equal(t, 2, calls, statusCode)
+			assert.Empty(t, store.idempotencyKeys, statusCode)
+			assert.Empty(t, retry.Header().Get("Idempotent-Replayed"), statusCode)
+		}
+	})
+
+	t.Run("claimed_without_response", func(t *testing.T) {
+		calls := 0
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{
+			"tx-1": {TransactionID: "tx-1"},
+		}}
+		handler := IdempotencyMiddleware(store)(newHandler(http.StatusOK, &calls))
+
+		handler.ServeHTTP(httptest.NewRecorder(), newRequest("tx-1", `{"amount":100}`))
+
+		assert.Equal(t, 1, calls)
+		assert.True(t, store.idempotencyKeys["tx-1"].HasResponse())
+	})
+
+	t.Run("skip_without_request_context", func(t *testing.T) {
+		calls := 0
+		store := &idempotencyStoreMock{idempotencyKeys: map[string]model.IdempotencyKey{}}
+		handler := IdempotencyMiddleware(store)(newHandler(http.StatusOK, &calls))
+
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/transactions", nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, store.idempotencyKeys)
+	})
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	secret := []byte("secret")
+	keyring, err := signature.NewKeyring([]string{"mobile:hmac-sha256:" + base64.StdEncoding.EncodeToString(secret)})
+	assert.NoError(t, err)
+
+	newRequest := func(timestamp time.Time, body string, sign func(message []byte) string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/transactions?dry_run=true",
+			strings.NewReader(body))
+		req.Header.Set("X-Timestamp", timestamp.Format(time.RFC3339))
+		req.Header.Set("X-Transaction-Id", "tx-1")
+		req.Header.Set("X-Client-Id", "mobile")
+		req.Header.Set("X-Signature", sign(signature.Message(http.MethodPost, "/transactions?dry_run=true",
+			timestamp.Format(time.RFC3339), "tx-1", []byte(`{"amount":100}`))))
+
+		return req
+	}
+
+	signHMAC := func(message []byte) string {
+		return signature.SignHMAC(secret, message)
+	}
+
+	var received, actorID string
+
+	handler := SignatureMiddleware(keyring, time.Minute)(http.HandlerFunc(
+		func(respWriter http.ResponseWriter, req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			received = string(body)
+			actorID = dto.ClientIDFromContext(req.Context())
+			respWriter.WriteHeader(http.StatusNoContent)
+		}))
+
+	testCases := []struct {
+		name               string
+		req                *http.Request
+		expectedStatusCode int
+	}{
+		{
+			name:               "success",
+			req:                newRequest(time.Now(), `{"amount":100}`, signHMAC),
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "tampered_body",
+			req:                newRequest(time.Now(), `{"amount":900}`, signHMAC),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong_secret",
+			req: newRequest(time.Now(), `{"amount":100}`, func(message []byte) string {
+				return signature.SignHMAC([]byte("other"), message)
+			}),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "expired_timestamp",
+			req:                newRequest(time.Now().Add(-2*time.Minute), `{"amount":100}`, signHMAC),
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			received, actorID = "", ""
+			respRecorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(respRecorder, testCase.req)
+
+			assert.Equal(t, testCase.expectedStatusCode, respRecorder.Code)
+
+			if testCase.expectedStatusCode == http.StatusNoContent {
+				assert.Equal(t, `{"amount":100}`, received)
+				assert.Equal(t, "mobile", actorID)
+			}
+		})
+	}
+
+	t.Run("unknown_client", func(t *testing.T) {
+		req := newRequest(time.Now(), `{"amount":100}`, signHMAC)
+		req.Header.Set("X-Client-Id", "partner")
+		respRecorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(respRecorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, respRecorder.Code)
+	})
+
+	t.Run("missing_timestamp", func(t *testing.T) {
+		req := newRequest(time.Now(), `{"amount":100}`, signHMAC)
+		req.Header.Del("X-Timestamp")
+		respRecorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(respRecorder, req)
+
+		assert.Equal(t, http.StatusUnauthorized, respRecorder.Code)
+	})
+}
+
 func unescapeUnquote(s string) string {
 	s = strings.Trim(s, "\"")
 	return strings.ReplaceAll(s, "\\", "")
@@ -0,0 +1,128 @@
+// Package webhook signs and sends the HTTP callbacks of the webhook subscriptions. A callback is a POST of a JSON
+// payload, signed with the HMAC-SHA256 of the timestamp and the payload under the secret of the subscription so that
+// the receiver can check where it comes from and reject a replayed callback.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+
+	signaturePrefix = "sha256="
+	// maxResponseBody is read from the response so that the connection can be reused, the body is not used.
+	maxResponseBody = 4 << 10
+)
+
+// ErrUnexpectedStatus is returned when the receiver does not answer with a 2xx status.
+var ErrUnexpectedStatus = errors.New("unexpected status code")
+
+// Sign returns the signature header of a payload sent at timestamp, the hex HMAC-SHA256 of "{timestamp}.{payload}".
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of a payload sent at timestamp.
+func Verify(secret string, timestamp int64, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
+
+// Backoff returns the delay before the retry following the given attempt, starting at 1. The delay doubles on every
+// attempt from base, up to maxDelay.
+func Backoff(base time.Duration, maxDelay time.Duration, attempt int) time.Duration {
+	delay := base
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxDelay)
+}
+
+// Request is a callback to send.
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID int64
+	EventType  string
+	Payload    []byte
+}
+
+// Result is the outcome of a callback, StatusCode is zero when no response was received.
+type Result struct {
+	StatusCode int
+	Duration   time.Duration
+}
+
+// Client sends the callbacks.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient returns a client whose callbacks time out after timeout. Redirects are not followed, a receiver must
+// answer on the registered URL.
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts the payload of the request. An error is returned when the callback fails or is not answered with a
+// 2xx status, the result holds the status code received.
+func (c *Client) Send(ctx context.Context, req Request) (Result, error) {
+	sentAt := time.Now()
+	timestamp := sentAt.Unix()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Payload))
+	if err != nil {
+		return Result{}, fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(HeaderEvent, req.EventType)
+	httpReq.Header.Set(HeaderDelivery, strconv.FormatInt(req.DeliveryID, 10))
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, timestamp, req.Payload))
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return Result{Duration: time.Since(sentAt)}, fmt.Errorf("send request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	//nolint:errcheck
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	result := Result{StatusCode: resp.StatusCode, Duration: time.Since(sentAt)}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return result, fmt.Errorf("%w: %d %s", ErrUnexpectedStatus, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	return result, nil
+}
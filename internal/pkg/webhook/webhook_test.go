@@ -0,0 +1,116 @@
+//go:build unit
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"event_type":"balance_debited"}`)
+	signature := Sign("secret", 1760000000, payload)
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.True(t, Verify("secret", 1760000000, payload, signature))
+	assert.False(t, Verify("other", 1760000000, payload, signature))
+	assert.False(t, Verify("secret", 1760000001, payload, signature))
+	assert.False(t, Verify("secret", 1760000000, []byte(`{}`), signature))
+}
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{20, time.Hour},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.want, Backoff(30*time.Second, time.Hour, testCase.attempt))
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	payload := []byte(`{"event_id":1}`)
+
+	t.Run("success", func(t *testing.T) {
+		var received *http.Request
+
+		var body []byte
+
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		result, err := NewClient(time.Second).Send(context.Background(), Request{
+			URL:        receiver.URL,
+			Secret:     "secret",
+			DeliveryID: 7,
+			EventType:  "balance_debited",
+			Payload:    payload,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, result.StatusCode)
+
+		assert.Equal(t, http.MethodPost, received.Method)
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, "balance_debited", received.Header.Get(HeaderEvent))
+		assert.Equal(t, "7", received.Header.Get(HeaderDelivery))
+		assert.Equal(t, payload, body)
+
+		timestamp, err := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+		assert.NoError(t, err)
+		assert.True(t, Verify("secret", timestamp, body, received.Header.Get(HeaderSignature)))
+	})
+
+	t.Run("error_status", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer receiver.Close()
+
+		result, err := NewClient(time.Second).Send(context.Background(), Request{URL: receiver.URL, Payload: payload})
+		assert.ErrorIs(t, err, ErrUnexpectedStatus)
+		assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+	})
+
+	t.Run("error_redirect_not_followed", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+		}))
+		defer receiver.Close()
+
+		result, err := NewClient(time.Second).Send(context.Background(), Request{URL: receiver.URL, Payload: payload})
+		assert.ErrorIs(t, err, ErrUnexpectedStatus)
+		assert.Equal(t, http.StatusFound, result.StatusCode)
+	})
+
+	t.Run("error_timeout", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer receiver.Close()
+
+		result, err := NewClient(50*time.Millisecond).Send(context.Background(),
+			Request{URL: receiver.URL, Payload: payload})
+		assert.Error(t, err)
+		assert.Zero(t, result.StatusCode)
+	})
+}
@@ -6,6 +6,8 @@
 export DB_DSN=postgres://postgres@postgres/transaction_test?sslmode=disable
 export LOG_LEVEL=debug
 export GOCOVERDIR=test/coverage
+# the clients of the features, a scenario acts as api-test unless it authenticates as another client
+export SERVICE_TOKENS="api-test|api-test-token|admin,alice|alice-token|admin,bob|bob-token|admin,carol|carol-token|admin"
 
 cd /app
 
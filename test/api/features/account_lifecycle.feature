@@ -0,0 +1,85 @@
+Feature: Account Lifecycle
+  Scenario: freeze account - success
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-freeze-1"
+    And I send a POST with path "/admin/accounts/3/freeze" with JSON:
+    """
+    {
+        "reason": "fraud_suspected"
+    }
+    """
+    Then the response code should be 204
+
+  Scenario: freeze account - invalid reason
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-freeze-2"
+    And I send a POST with path "/admin/accounts/3/freeze" with JSON:
+    """
+    {
+        "reason": "because"
+    }
+    """
+    Then the response code should be 400
+
+  Scenario: unfreeze account - not frozen
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-unfreeze-1"
+    And I send a POST with path "/admin/accounts/2/unfreeze" with JSON:
+    """
+    {
+        "reason": "review_cleared"
+    }
+    """
+    Then the response code should be 409
+    Then the response error message should contain "account is not frozen"
+
+  Scenario: transfer - source account frozen
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-frozen-transfer-1"
+    And I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 4,
+        "destination_account_id": 2,
+        "amount": 100.00
+    }
+    """
+    Then the response code should be 422
+    Then the response error message should contain "account 4 is frozen"
+
+  Scenario: transfer - destination account closed
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-closed-transfer-1"
+    And I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 5,
+        "amount": 100.00
+    }
+    """
+    Then the response code should be 422
+    Then the response error message should contain "account 5 is closed"
+
+  Scenario: close account - balance not zero
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-close-1"
+    And I send a POST with path "/admin/accounts/2/close" with JSON:
+    """
+    {
+        "reason": "customer_request"
+    }
+    """
+    Then the response code should be 422
+
+  Scenario: close account - sweep remaining balance
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-close-2"
+    And I send a POST with path "/admin/accounts/2/close" with JSON:
+    """
+    {
+        "reason": "customer_request",
+        "sweep_account_id": 1
+    }
+    """
+    Then the response code should be 204
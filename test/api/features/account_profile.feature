@@ -0,0 +1,24 @@
+Feature: Account Profile
+  Scenario: update account profile - success
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-profile-1"
+    And I send a PATCH with path "/accounts/1" with JSON:
+    """
+    {
+        "display_name": "Operating account",
+        "account_type": "business",
+        "labels": {"segment": "sme"}
+    }
+    """
+    Then the response code should be 204
+
+  Scenario: update account profile - closed account
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-profile-2"
+    And I send a PATCH with path "/accounts/5" with JSON:
+    """
+    {
+        "display_name": "Closed"
+    }
+    """
+    Then the response code should be 422
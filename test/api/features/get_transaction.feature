@@ -0,0 +1,7 @@
+Feature: Get Transaction by transaction id
+  Scenario: get transaction - success
+    Given I send a GET with path "/transactions/tx-1"
+    Then the response code should be 200
+  Scenario: get transaction - not found
+    Given I send a GET with path "/transactions/tx-unknown"
+    Then the response code should be 404
@@ -0,0 +1,21 @@
+Feature: Ledger
+  Scenario: get trial balance - success
+    Given I send a GET with path "/ledger/trial-balance"
+    Then the response code should be 200
+    And the response message should contain "total_debits"
+
+  Scenario: get trial balance - balanced after transfer
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-ledger-1"
+    And I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 25.00
+    }
+    """
+    Then the response code should be 200
+    When I send a GET with path "/ledger/trial-balance"
+    Then the response code should be 200
+    And the response message should contain "total_debits"
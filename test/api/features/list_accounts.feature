@@ -0,0 +1,7 @@
+Feature: List Accounts
+  Scenario: list accounts - success
+    Given I send a GET with path "/accounts?status=active&sort=-balance&limit=2"
+    Then the response code should be 200
+  Scenario: list accounts - invalid cursor
+    Given I send a GET with path "/accounts?sort=balance&cursor=invalid"
+    Then the response code should be 400
@@ -0,0 +1,47 @@
+Feature: Transaction Monitoring
+  Scenario: list monitoring alerts - by status
+    Given I send a GET with path "/monitoring/alerts?status=open"
+    Then the response code should be 200
+    And the number of object matching "data" should equal to 1
+    And the response message should contain "structuring"
+
+  Scenario: list monitoring alerts - invalid status
+    Given I send a GET with path "/monitoring/alerts?status=closed"
+    Then the response code should be 400
+
+  Scenario: get monitoring alert - not found
+    Given I send a GET with path "/monitoring/alerts/99"
+    Then the response code should be 404
+
+  Scenario: disposition monitoring alert - success
+    Given I authenticate as "carol"
+    And I send a POST with path "/monitoring/alerts/1/disposition" with JSON:
+    """
+    {
+        "status": "escalated",
+        "note": "split cash withdrawals"
+    }
+    """
+    Then the response code should be 200
+    And the response message should contain "escalated"
+
+  Scenario: disposition monitoring alert - not authenticated
+    Given I am not authenticated
+    And I send a POST with path "/monitoring/alerts/1/disposition" with JSON:
+    """
+    {
+        "status": "dismissed"
+    }
+    """
+    Then the response code should be 401
+
+  Scenario: disposition monitoring alert - already reviewed
+    Given I authenticate as "carol"
+    And I send a POST with path "/monitoring/alerts/2/disposition" with JSON:
+    """
+    {
+        "status": "escalated"
+    }
+    """
+    Then the response code should be 409
+    And the response error message should contain "monitoring alert was already reviewed"
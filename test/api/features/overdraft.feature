@@ -0,0 +1,38 @@
+Feature: Overdraft Limit
+  Scenario: set overdraft limit - success
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-overdraft-1"
+    And I send a PUT with path "/admin/accounts/3/overdraft-limit" with JSON:
+    """
+    {
+        "limit": 500.00
+    }
+    """
+    Then the response code should be 204
+
+  Scenario: set overdraft limit - negative limit
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-overdraft-2"
+    And I send a PUT with path "/admin/accounts/3/overdraft-limit" with JSON:
+    """
+    {
+        "limit": -1
+    }
+    """
+    Then the response code should be 400
+
+  Scenario: set overdraft limit - closed account
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-overdraft-3"
+    And I send a PUT with path "/admin/accounts/5/overdraft-limit" with JSON:
+    """
+    {
+        "limit": 100.00
+    }
+    """
+    Then the response code should be 422
+
+  Scenario: get account - reports overdraft
+    Given I send a GET with path "/accounts/1"
+    Then the response code should be 200
+    Then the response message should contain "overdraft_remaining"
@@ -0,0 +1,135 @@
+Feature: Payment Batch
+  Scenario: submit payment batch - partially accepted
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-payment-batch-1"
+    And I send a POST with path "/payment-batches" with XML:
+    """
+    <?xml version="1.0" encoding="UTF-8"?>
+    <Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+      <CstmrCdtTrfInitn>
+        <GrpHdr>
+          <MsgId>MSG-FEATURE-1</MsgId>
+          <CreDtTm>2026-01-01T09:00:00Z</CreDtTm>
+          <NbOfTxs>2</NbOfTxs>
+          <CtrlSum>150.00</CtrlSum>
+          <InitgPty><Nm>ACME Corp</Nm></InitgPty>
+        </GrpHdr>
+        <PmtInf>
+          <PmtInfId>PMT-1</PmtInfId>
+          <PmtMtd>TRF</PmtMtd>
+          <ReqdExctnDt><Dt>2026-01-01</Dt></ReqdExctnDt>
+          <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+          <CdtTrfTxInf>
+            <PmtId><EndToEndId>E2E-1</EndToEndId></PmtId>
+            <Amt><InstdAmt Ccy="USD">100.00</InstdAmt></Amt>
+            <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+          </CdtTrfTxInf>
+          <CdtTrfTxInf>
+            <PmtId><EndToEndId>E2E-2</EndToEndId></PmtId>
+            <Amt><InstdAmt Ccy="USD">50.00</InstdAmt></Amt>
+            <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+          </CdtTrfTxInf>
+        </PmtInf>
+      </CstmrCdtTrfInitn>
+    </Document>
+    """
+    Then the response code should be 201
+    And the response message should contain "<OrgnlMsgId>MSG-FEATURE-1</OrgnlMsgId>"
+    And the response message should contain "<GrpSts>PART</GrpSts>"
+    And the response message should contain "<TxSts>ACSC</TxSts>"
+    And the response message should contain "<Cd>AC03</Cd>"
+
+  Scenario: submit payment batch - rejected on control sum
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-payment-batch-2"
+    And I send a POST with path "/payment-batches" with XML:
+    """
+    <?xml version="1.0" encoding="UTF-8"?>
+    <Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+      <CstmrCdtTrfInitn>
+        <GrpHdr>
+          <MsgId>MSG-FEATURE-2</MsgId>
+          <CreDtTm>2026-01-01T09:00:00Z</CreDtTm>
+          <NbOfTxs>1</NbOfTxs>
+          <CtrlSum>99.00</CtrlSum>
+        </GrpHdr>
+        <PmtInf>
+          <PmtInfId>PMT-1</PmtInfId>
+          <PmtMtd>TRF</PmtMtd>
+          <ReqdExctnDt><Dt>2026-01-01</Dt></ReqdExctnDt>
+          <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+          <CdtTrfTxInf>
+            <PmtId><EndToEndId>E2E-1</EndToEndId></PmtId>
+            <Amt><InstdAmt Ccy="USD">100.00</InstdAmt></Amt>
+            <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+          </CdtTrfTxInf>
+        </PmtInf>
+      </CstmrCdtTrfInitn>
+    </Document>
+    """
+    Then the response code should be 201
+    And the response message should contain "<GrpSts>RJCT</GrpSts>"
+    And the response message should contain "<Cd>AM10</Cd>"
+
+  Scenario: submit payment batch - duplicate message id
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-payment-batch-3"
+    And I send a POST with path "/payment-batches" with XML:
+    """
+    <?xml version="1.0" encoding="UTF-8"?>
+    <Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+      <CstmrCdtTrfInitn>
+        <GrpHdr><MsgId>MSG-FEATURE-3</MsgId><CreDtTm>2026-01-01T09:00:00Z</CreDtTm><NbOfTxs>1</NbOfTxs></GrpHdr>
+        <PmtInf>
+          <PmtInfId>PMT-1</PmtInfId>
+          <PmtMtd>TRF</PmtMtd>
+          <ReqdExctnDt><Dt>2099-01-01</Dt></ReqdExctnDt>
+          <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+          <CdtTrfTxInf>
+            <PmtId><EndToEndId>E2E-1</EndToEndId></PmtId>
+            <Amt><InstdAmt Ccy="USD">10.00</InstdAmt></Amt>
+            <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+          </CdtTrfTxInf>
+        </PmtInf>
+      </CstmrCdtTrfInitn>
+    </Document>
+    """
+    Then the response code should be 201
+    And the response message should contain "<GrpSts>PDNG</GrpSts>"
+    Given I set a header key "x-transaction-id" with value "tx-payment-batch-4"
+    And I send a POST with path "/payment-batches" with XML:
+    """
+    <?xml version="1.0" encoding="UTF-8"?>
+    <Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+      <CstmrCdtTrfInitn>
+        <GrpHdr><MsgId>MSG-FEATURE-3</MsgId><CreDtTm>2026-01-01T09:00:00Z</CreDtTm><NbOfTxs>1</NbOfTxs></GrpHdr>
+        <PmtInf>
+          <PmtInfId>PMT-1</PmtInfId>
+          <PmtMtd>TRF</PmtMtd>
+          <ReqdExctnDt><Dt>2099-01-01</Dt></ReqdExctnDt>
+          <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+          <CdtTrfTxInf>
+            <PmtId><EndToEndId>E2E-1</EndToEndId></PmtId>
+            <Amt><InstdAmt Ccy="USD">10.00</InstdAmt></Amt>
+            <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+          </CdtTrfTxInf>
+        </PmtInf>
+      </CstmrCdtTrfInitn>
+    </Document>
+    """
+    Then the response code should be 409
+    And the response error message should contain "MSG-FEATURE-3"
+
+  Scenario: submit payment batch - invalid file
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-payment-batch-5"
+    And I send a POST with path "/payment-batches" with XML:
+    """
+    <Document>not a payment file</Document>
+    """
+    Then the response code should be 400
+    And the response error message should contain "not a valid pain.001.001.09"
+
+  Scenario: get payment batch - not found
+    Given I send a GET with path "/payment-batches/999999"
+    Then the response code should be 404
@@ -0,0 +1,23 @@
+Feature: Reconciliation
+  Scenario: import bank statement - unmatched in bank
+    Given I upload the file "statement.csv" to path "/reconciliations" with content:
+    """
+    date,amount,reference,description
+    2026-01-05,125.00,tx-not-in-ledger,Unknown deposit
+    """
+    Then the response code should be 201
+    And the number of object matching "items" should equal to 1
+    And the response message should contain "unmatched_in_bank"
+    And the response message should contain "tx-not-in-ledger"
+
+  Scenario: import bank statement - invalid file
+    Given I upload the file "statement.sta" to path "/reconciliations" with content:
+    """
+    not a statement
+    """
+    Then the response code should be 400
+    And the response error message should contain "bank statement could not be read"
+
+  Scenario: get reconciliation - not found
+    Given I send a GET with path "/reconciliations/999999"
+    Then the response code should be 404
@@ -0,0 +1,76 @@
+Feature: Scheduled Transfer
+  Scenario: schedule transfer - success
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-scheduled-10"
+    And I send a POST with path "/transactions/scheduled" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 100.00,
+        "execute_at": "2099-06-01T00:00:00Z"
+    }
+    """
+    Then the response code should be 201
+    Then the response message should contain "pending"
+
+  Scenario: schedule transfer - execute at in the past
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-scheduled-11"
+    And I send a POST with path "/transactions/scheduled" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 100.00,
+        "execute_at": "2020-01-01T00:00:00Z"
+    }
+    """
+    Then the response code should be 400
+    Then the response error message should contain "execute at must be in the future"
+
+  Scenario: schedule transfer - transaction id already used
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-scheduled-1"
+    And I send a POST with path "/transactions/scheduled" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 100.00,
+        "execute_at": "2099-06-01T00:00:00Z"
+    }
+    """
+    Then the response code should be 409
+
+  Scenario: transfer - transaction id of a scheduled transfer is reserved
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "scheduled-transfer-3"
+    And I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 100.00
+    }
+    """
+    Then the response code should be 400
+    Then the response error message should contain "reserved"
+
+  Scenario: get scheduled transfer - success
+    Given I send a GET with path "/transactions/scheduled/1"
+    Then the response code should be 200
+    Then the response message should contain "scheduled-transfer-1"
+
+  Scenario: get scheduled transfer - not found
+    Given I send a GET with path "/transactions/scheduled/999"
+    Then the response code should be 404
+
+  Scenario: cancel scheduled transfer - success
+    Given I send a DELETE with path "/transactions/scheduled/1"
+    Then the response code should be 204
+
+  Scenario: cancel scheduled transfer - already executed
+    Given I send a DELETE with path "/transactions/scheduled/2"
+    Then the response code should be 409
+    Then the response error message should contain "scheduled transfer is not pending anymore"
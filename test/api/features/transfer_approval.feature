@@ -0,0 +1,63 @@
+Feature: Transfer Approval
+  Scenario: transfer above the approval threshold - pending approval
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-approval-2"
+    And I authenticate as "alice"
+    And I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 15000.00
+    }
+    """
+    Then the response code should be 200
+    And the response message should contain "pending_approval"
+
+  Scenario: approve transfer - initiator cannot approve
+    Given I authenticate as "alice"
+    And I send a POST with path "/transactions/tx-approval-1/approve" with JSON:
+    """
+    {}
+    """
+    Then the response code should be 403
+
+  Scenario: approve transfer - initiator naming another actor cannot approve
+    Given I authenticate as "alice"
+    And I set a header key "x-actor-id" with value "bob"
+    And I send a POST with path "/transactions/tx-approval-1/approve" with JSON:
+    """
+    {}
+    """
+    Then the response code should be 403
+
+  Scenario: approve transfer - not authenticated
+    Given I am not authenticated
+    And I send a POST with path "/transactions/tx-approval-1/approve" with JSON:
+    """
+    {}
+    """
+    Then the response code should be 401
+
+  Scenario: reject transfer - success
+    Given I authenticate as "bob"
+    And I send a POST with path "/transactions/tx-approval-1/reject" with JSON:
+    """
+    {
+        "reason": "unknown beneficiary"
+    }
+    """
+    Then the response code should be 204
+
+  Scenario: approve transfer - not pending approval
+    Given I authenticate as "bob"
+    And I send a POST with path "/transactions/tx-approval-1/reject" with JSON:
+    """
+    {}
+    """
+    Then the response code should be 204
+    When I send a POST with path "/transactions/tx-approval-1/approve" with JSON:
+    """
+    {}
+    """
+    Then the response code should be 409
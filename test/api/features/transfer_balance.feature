@@ -10,7 +10,52 @@ Feature: Transfer Balance
         "amount": 100.00
     }
     """
-    Then the response code should be 204
+    Then the response code should be 200
+
+  Scenario: transfer balance - retry with the same transaction id is replayed
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-retry-1"
+    And I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 100.00
+    }
+    """
+    Then the response code should be 200
+    When I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 100.00
+    }
+    """
+    Then the response code should be 200
+
+  Scenario: transfer balance - transaction id reused with a different payload
+    Given I use default timestamp
+    And I set a header key "x-transaction-id" with value "tx-retry-2"
+    And I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 100.00
+    }
+    """
+    Then the response code should be 200
+    When I send a POST with path "/transactions" with JSON:
+    """
+    {
+        "source_account_id": 1,
+        "destination_account_id": 2,
+        "amount": 200.00
+    }
+    """
+    Then the response code should be 409
+    Then the response error message should contain "transaction id already used by another operation"
 
   Scenario: transfer balance - no x-transaction-id in header
     Given I use default timestamp
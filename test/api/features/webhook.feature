@@ -0,0 +1,75 @@
+Feature: Webhook
+  Scenario: create webhook - success
+    Given I send a POST with path "/webhooks" with JSON:
+    """
+    {
+        "url": "https://partner.example.com/hooks",
+        "secret": "a-long-enough-secret",
+        "event_types": ["balance_debited", "balance_credited"],
+        "account_ids": [1]
+    }
+    """
+    Then the response code should be 201
+    And the response message should contain "https://partner.example.com/hooks"
+    And the response message should contain "active"
+
+  Scenario: create webhook - invalid event type
+    Given I send a POST with path "/webhooks" with JSON:
+    """
+    {
+        "url": "https://partner.example.com/hooks",
+        "secret": "a-long-enough-secret",
+        "event_types": ["payment_batch_received"]
+    }
+    """
+    Then the response code should be 400
+
+  Scenario: create webhook - secret too short
+    Given I send a POST with path "/webhooks" with JSON:
+    """
+    {
+        "url": "https://partner.example.com/hooks",
+        "secret": "short"
+    }
+    """
+    Then the response code should be 400
+
+  Scenario: get webhook - not found
+    Given I send a GET with path "/webhooks/99"
+    Then the response code should be 404
+
+  Scenario: list webhooks - by status
+    Given I send a GET with path "/webhooks?status=active"
+    Then the response code should be 200
+    And the number of object matching "data" should equal to 1
+
+  Scenario: delete webhook - success
+    Given I send a DELETE with path "/webhooks/1"
+    Then the response code should be 204
+
+  Scenario: list webhook deliveries - success
+    Given I send a GET with path "/webhooks/1/deliveries"
+    Then the response code should be 200
+    And the number of object matching "data" should equal to 2
+    And the response message should contain "unexpected status code: 503 Service Unavailable"
+
+  Scenario: list webhook deliveries - by status
+    Given I send a GET with path "/webhooks/1/deliveries?status=failed"
+    Then the response code should be 200
+    And the number of object matching "data" should equal to 1
+
+  Scenario: redeliver webhook - failed deliveries
+    Given I send a POST with path "/webhooks/1/redeliver" with JSON:
+    """
+    {}
+    """
+    Then the response code should be 200
+    And the response message should contain "requeued"
+
+  Scenario: redeliver webhook - disabled
+    Given I send a POST with path "/webhooks/2/redeliver" with JSON:
+    """
+    {}
+    """
+    Then the response code should be 409
+    And the response error message should contain "webhook is disabled"
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"strings"
@@ -36,6 +37,16 @@ func (f *feature) iUseHeader(header, value string) {
 	}
 }
 
+// iAuthenticateAs sends the service token of the client, see SERVICE_TOKENS in scripts/integration_test.sh.
+func (f *feature) iAuthenticateAs(clientID string) {
+	f.headers["authorization"] = fmt.Sprintf("Bearer %s-token", clientID)
+}
+
+// iAmNotAuthenticated sends no service token.
+func (f *feature) iAmNotAuthenticated() {
+	delete(f.headers, "authorization")
+}
+
 func (f *feature) iUseDefaultTimestamp() {
 	timestamp := time.Now().Format(time.RFC3339)
 	f.headers["x-timestamp"] = timestamp
@@ -88,6 +99,65 @@ func (f *feature) iSendARequestToWithJSON(method, path string, payload *godog.Do
 	return nil
 }
 
+func (f *feature) iSendARequestToWithXML(method, path string, payload *godog.DocString) error {
+	req, err := http.NewRequestWithContext(context.Background(), method, fmt.Sprintf("http://%s%s", f.host, path), strings.NewReader(payload.Content))
+	if err != nil {
+		return fmt.Errorf("create request with XML: %w", err)
+	}
+
+	f.buildHeader(req)
+
+	req.Header.Add("Content-Type", "application/xml")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request with XML: %w", err)
+	}
+
+	f.response = resp
+	f.body = "" // reset
+
+	return nil
+}
+
+func (f *feature) iUploadTheFileToPath(fileName, path string, content *godog.DocString) error {
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		return fmt.Errorf("create form file: %w", err)
+	}
+
+	if _, err := part.Write([]byte(content.Content)); err != nil {
+		return fmt.Errorf("write form file: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("close multipart writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, fmt.Sprintf("http://%s%s", f.host, path), &body)
+	if err != nil {
+		return fmt.Errorf("create upload request: %w", err)
+	}
+
+	f.buildHeader(req)
+
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send upload request: %w", err)
+	}
+
+	f.response = resp
+	f.body = "" // reset
+
+	return nil
+}
+
 func (f *feature) theResponseCodeShouldBe(code int) error {
 	if code != f.response.StatusCode {
 		f.readBody()
@@ -210,6 +280,8 @@ func (tf *testFeatures) initializeScenario(ctx *godog.ScenarioContext) {
 		testingT: tf.testingT,
 	}
 
+	feat.iAuthenticateAs("api-test")
+
 	ctx.Before(func(ctx context.Context, sc *godog.Scenario) (context.Context, error) {
 		err := tf.fixtures.Load()
 		assert.Nil(tf.testingT, err)
@@ -218,7 +290,9 @@ func (tf *testFeatures) initializeScenario(ctx *godog.ScenarioContext) {
 	})
 
 	ctx.Step(`^I send a ([^"]*) with path "([^"]*)" with JSON:$`, feat.iSendARequestToWithJSON)
+	ctx.Step(`^I send a ([^"]*) with path "([^"]*)" with XML:$`, feat.iSendARequestToWithXML)
 	ctx.Step(`^I send a ([^"]*) with path "([^"]*)"$`, feat.iSendARequestTo)
+	ctx.Step(`^I upload the file "([^"]*)" to path "([^"]*)" with content:$`, feat.iUploadTheFileToPath)
 	ctx.Step(`^the response body should match JSON schema "([^"]*)"$`, feat.theResponseBodyShouldMatchJSONSchema)
 	ctx.Step(`^the response code should be (\d+)$`, feat.theResponseCodeShouldBe)
 	ctx.Step(`^the response error message should contain "([^"]*)"`, feat.theResponseErrorMessageShouldContain)
@@ -227,6 +301,8 @@ func (tf *testFeatures) initializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^the number of object matching "([^"]*)" should equal to (\d+)$`, feat.theNumberObjectMatchingPatternShouldEqualTo)
 	ctx.Step(`^I set a header key "([^"]*)" with value "([^"]*)"$`, feat.iUseHeader)
 	ctx.Step(`^I use default timestamp$`, feat.iUseDefaultTimestamp)
+	ctx.Step(`^I authenticate as "([^"]*)"$`, feat.iAuthenticateAs)
+	ctx.Step(`^I am not authenticated$`, feat.iAmNotAuthenticated)
 
 }
 